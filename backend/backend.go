@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/sahib/brig/backend/httpipfs"
+	"github.com/sahib/brig/backend/localfs"
 	"github.com/sahib/brig/backend/mock"
 	"github.com/sahib/brig/catfs"
 	eventsBackend "github.com/sahib/brig/events/backend"
@@ -38,7 +39,7 @@ type Backend interface {
 // ForwardLogByName will forward the logs of the backend `name` to `w`.
 func ForwardLogByName(name string, w io.Writer) error {
 	switch name {
-	case "httpipfs":
+	case "httpipfs", "localfs":
 		return nil
 	case "mock":
 		return nil
@@ -53,6 +54,8 @@ func FromName(name, path, fingerprint string) (Backend, error) {
 	switch name {
 	case "httpipfs":
 		return httpipfs.NewNode(path, fingerprint)
+	case "localfs":
+		return localfs.NewNode(path, fingerprint)
 	case "mock":
 		user := "alice"
 		if envUser := os.Getenv("BRIG_MOCK_USER"); envUser != "" {
@@ -83,6 +86,8 @@ func Version(name, path string) VersionInfo {
 
 		defer nd.Close()
 		return nd.Version()
+	case "localfs":
+		return localfs.Version()
 	default:
 		return nil
 	}
@@ -0,0 +1,208 @@
+package localfs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	h "github.com/sahib/brig/util/hashlib"
+	log "github.com/sirupsen/logrus"
+)
+
+// Blocks that are not stored locally are fetched from other localfs nodes on
+// the same machine. Every node serves its block store on a listener that is
+// announced in the rendezvous directory. A request is the base58 hash of the
+// wanted block followed by a newline; the answer is a single status byte,
+// followed by the block data if the block is available.
+
+const (
+	blockMissing   = byte(0)
+	blockAvailable = byte(1)
+)
+
+func buildBlockPeersDir() string {
+	return filepath.Join(rendezvousDir(), "blocks")
+}
+
+type blockServer struct {
+	lst      net.Listener
+	addrPath string
+}
+
+func (bs *blockServer) Close() error {
+	defer os.Remove(bs.addrPath)
+	return bs.lst.Close()
+}
+
+// serveBlocks starts a listener that answers block requests of other nodes.
+func (nd *Node) serveBlocks() (*blockServer, error) {
+	lst, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	peersDir := buildBlockPeersDir()
+	if err := os.MkdirAll(peersDir, 0700); err != nil {
+		lst.Close()
+		return nil, err
+	}
+
+	// The fingerprint is not necessarily unique (or set at all),
+	// so use a random name for the announcement.
+	fd, err := ioutil.TempFile(peersDir, "node-")
+	if err != nil {
+		lst.Close()
+		return nil, err
+	}
+
+	if _, err := fd.WriteString(lst.Addr().String()); err != nil {
+		fd.Close()
+		os.Remove(fd.Name())
+		lst.Close()
+		return nil, err
+	}
+
+	if err := fd.Close(); err != nil {
+		os.Remove(fd.Name())
+		lst.Close()
+		return nil, err
+	}
+
+	bs := &blockServer{
+		lst:      lst,
+		addrPath: fd.Name(),
+	}
+
+	go func() {
+		for {
+			conn, err := lst.Accept()
+			if err != nil {
+				// Listener was closed.
+				return
+			}
+
+			go nd.handleBlockRequest(conn)
+		}
+	}()
+
+	return bs, nil
+}
+
+func (nd *Node) handleBlockRequest(conn net.Conn) {
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		log.Debugf("localfs: bad block request: %v", err)
+		return
+	}
+
+	hash, err := h.FromB58String(strings.TrimSpace(line))
+	if err != nil {
+		log.Debugf("localfs: bad block request: %v", err)
+		return
+	}
+
+	var fd *os.File
+	if nd.isOnline() {
+		fd, err = os.Open(nd.blockPath(hash))
+	}
+
+	if fd == nil || err != nil {
+		conn.Write([]byte{blockMissing})
+		return
+	}
+
+	defer fd.Close()
+
+	// Sending a large block may take longer than the request.
+	conn.SetDeadline(time.Time{})
+	if _, err := conn.Write([]byte{blockAvailable}); err != nil {
+		return
+	}
+
+	if _, err := io.Copy(conn, fd); err != nil {
+		log.Debugf("localfs: failed to send block %s: %v", hash.B58String(), err)
+	}
+}
+
+// fetchBlock asks all other nodes on this machine for `hash` and adds
+// it to the local store. The block is not pinned.
+func (nd *Node) fetchBlock(hash h.Hash) error {
+	if !nd.isOnline() {
+		return ErrNoSuchBlock{hash: hash}
+	}
+
+	peersDir := buildBlockPeersDir()
+	entries, err := ioutil.ReadDir(peersDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	ownAddrPath := ""
+	if nd.blocks != nil {
+		ownAddrPath = nd.blocks.addrPath
+	}
+
+	for _, entry := range entries {
+		addrPath := filepath.Join(peersDir, entry.Name())
+		if addrPath == ownAddrPath {
+			continue
+		}
+
+		addr, err := ioutil.ReadFile(addrPath) // #nosec
+		if err != nil {
+			continue
+		}
+
+		if err := nd.fetchBlockFrom(string(addr), hash); err != nil {
+			log.Debugf("localfs: failed to fetch %s from %s: %v", hash.B58String(), addr, err)
+			continue
+		}
+
+		return nil
+	}
+
+	return ErrNoSuchBlock{hash: hash}
+}
+
+func (nd *Node) fetchBlockFrom(addr string, hash h.Hash) error {
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	if _, err := fmt.Fprintf(conn, "%s\n", hash.B58String()); err != nil {
+		return err
+	}
+
+	status := []byte{blockMissing}
+	if _, err := io.ReadFull(conn, status); err != nil {
+		return err
+	}
+
+	if status[0] != blockAvailable {
+		return ErrNoSuchBlock{hash: hash}
+	}
+
+	gotHash, err := nd.addBlock(conn)
+	if err != nil {
+		return err
+	}
+
+	if !gotHash.Equal(hash) {
+		// Nothing references this block, the gc will remove it.
+		return fmt.Errorf("peer sent wrong block: %s", gotHash.B58String())
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package localfs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	e "github.com/pkg/errors"
+	h "github.com/sahib/brig/util/hashlib"
+	log "github.com/sirupsen/logrus"
+)
+
+// GC removes all blocks from the store that are not pinned.
+// Cleaned up hashes will be returned as a list.
+func (nd *Node) GC() ([]h.Hash, error) {
+	nd.storeMu.Lock()
+	defer nd.storeMu.Unlock()
+
+	hs := []h.Hash{}
+	blocksRoot := filepath.Join(nd.path, blocksDir)
+	shards, err := ioutil.ReadDir(blocksRoot)
+	if err != nil {
+		return nil, e.Wrapf(err, "gc: read blocks")
+	}
+
+	for _, shard := range shards {
+		if !shard.IsDir() {
+			continue
+		}
+
+		shardPath := filepath.Join(blocksRoot, shard.Name())
+		blocks, err := ioutil.ReadDir(shardPath)
+		if err != nil {
+			return nil, e.Wrapf(err, "gc: read shard")
+		}
+
+		for _, block := range blocks {
+			hash, err := h.FromB58String(block.Name())
+			if err != nil {
+				log.Warningf("gc: ignoring unknown file in block store: %s", block.Name())
+				continue
+			}
+
+			isPinned, err := nd.IsPinned(hash)
+			if err != nil {
+				return nil, err
+			}
+
+			if isPinned {
+				continue
+			}
+
+			if err := os.Remove(filepath.Join(shardPath, block.Name())); err != nil {
+				return nil, e.Wrapf(err, "gc: remove block")
+			}
+
+			hs = append(hs, hash)
+		}
+	}
+
+	// Left overs from interrupted Add() calls can go too.
+	// Nobody else can use them, since we hold storeMu.
+	tmpRoot := filepath.Join(nd.path, tmpDir)
+	tmps, err := ioutil.ReadDir(tmpRoot)
+	if err != nil {
+		return nil, e.Wrapf(err, "gc: read tmp")
+	}
+
+	for _, tmp := range tmps {
+		if err := os.RemoveAll(filepath.Join(tmpRoot, tmp.Name())); err != nil {
+			log.Warningf("gc: failed to remove temp file: %v", err)
+		}
+	}
+
+	log.Debugf("GC returned %d hashes", len(hs))
+	return hs, nil
+}
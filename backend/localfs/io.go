@@ -0,0 +1,123 @@
+package localfs
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/sahib/brig/catfs/mio"
+	h "github.com/sahib/brig/util/hashlib"
+)
+
+// ErrNoSuchBlock is returned when a block is not available in the local store.
+type ErrNoSuchBlock struct {
+	hash h.Hash
+}
+
+func (eb ErrNoSuchBlock) Error() string {
+	return fmt.Sprintf("no such block: %s", eb.hash.B58String())
+}
+
+// IsNoSuchBlockError returns true if `err` is a ErrNoSuchBlock.
+func IsNoSuchBlockError(err error) bool {
+	_, ok := err.(ErrNoSuchBlock)
+	return ok
+}
+
+// blockPath returns the path of the block for `hash`.
+// Blocks are sharded into sub directories to avoid
+// having too many entries in a single directory.
+func (nd *Node) blockPath(hash h.Hash) string {
+	b58 := hash.B58String()
+	shard := b58[len(b58)-3 : len(b58)-1]
+	return filepath.Join(nd.path, blocksDir, shard, b58)
+}
+
+type blockStream struct {
+	*os.File
+}
+
+func (bs blockStream) WriteTo(w io.Writer) (int64, error) {
+	return io.Copy(w, bs.File)
+}
+
+// Cat returns a stream associated with `hash`.
+// If the block is not stored locally, it is fetched from other nodes.
+func (nd *Node) Cat(hash h.Hash) (mio.Stream, error) {
+	fd, err := os.Open(nd.blockPath(hash))
+	if os.IsNotExist(err) {
+		if err := nd.fetchBlock(hash); err != nil {
+			return nil, err
+		}
+
+		fd, err = os.Open(nd.blockPath(hash))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return blockStream{File: fd}, nil
+}
+
+// Add puts the contents of `r` into the block store and returns its hash.
+// Like with IPFS, newly added blocks are pinned by default.
+func (nd *Node) Add(r io.Reader) (h.Hash, error) {
+	hash, err := nd.addBlock(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := nd.writePin(hash); err != nil {
+		return nil, err
+	}
+
+	return hash, nil
+}
+
+// addBlock puts the contents of `r` into the block store without pinning it.
+func (nd *Node) addBlock(r io.Reader) (h.Hash, error) {
+	nd.storeMu.RLock()
+	defer nd.storeMu.RUnlock()
+
+	// Write the data to a temporary file first, since we do not
+	// know the hash (and thus the location) before reading everything.
+	tmpFd, err := ioutil.TempFile(filepath.Join(nd.path, tmpDir), "add-")
+	if err != nil {
+		return nil, err
+	}
+
+	tmpPath := tmpFd.Name()
+	defer os.Remove(tmpPath)
+
+	hw := h.NewBackendHashWriter()
+	if _, err := io.Copy(tmpFd, io.TeeReader(r, hw)); err != nil {
+		tmpFd.Close()
+		return nil, err
+	}
+
+	if err := tmpFd.Sync(); err != nil {
+		tmpFd.Close()
+		return nil, err
+	}
+
+	if err := tmpFd.Close(); err != nil {
+		return nil, err
+	}
+
+	hash := hw.Finalize()
+	blockPath := nd.blockPath(hash)
+	if err := os.MkdirAll(filepath.Dir(blockPath), 0700); err != nil {
+		return nil, err
+	}
+
+	// Same content is only stored once; a rename over
+	// an existing block with the same content does no harm.
+	if err := os.Rename(tmpPath, blockPath); err != nil {
+		return nil, err
+	}
+
+	return hash, nil
+}
@@ -0,0 +1,103 @@
+package localfs
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	h "github.com/sahib/brig/util/hashlib"
+	"github.com/sahib/brig/util/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddCatBasic(t *testing.T) {
+	WithNode(t, func(nd *Node) {
+		data := testutil.CreateDummyBuf(4096 * 1024)
+		hash, err := nd.Add(bytes.NewReader(data))
+		require.Nil(t, err)
+		require.Equal(t, h.SumWithBackendHash(data), hash)
+
+		stream, err := nd.Cat(hash)
+		require.Nil(t, err)
+
+		echoData, err := ioutil.ReadAll(stream)
+		require.Nil(t, err)
+		require.Equal(t, data, echoData)
+		require.Nil(t, stream.Close())
+	})
+}
+
+func TestAddCatSeek(t *testing.T) {
+	WithNode(t, func(nd *Node) {
+		data := testutil.CreateDummyBuf(4096)
+		hash, err := nd.Add(bytes.NewReader(data))
+		require.Nil(t, err)
+
+		stream, err := nd.Cat(hash)
+		require.Nil(t, err)
+		defer stream.Close()
+
+		size, err := stream.Seek(0, io.SeekEnd)
+		require.Nil(t, err)
+		require.Equal(t, int64(len(data)), size)
+
+		off, err := stream.Seek(1024, io.SeekStart)
+		require.Nil(t, err)
+		require.Equal(t, int64(1024), off)
+
+		buf := &bytes.Buffer{}
+		_, err = stream.WriteTo(buf)
+		require.Nil(t, err)
+		require.Equal(t, data[1024:], buf.Bytes())
+	})
+}
+
+func TestCatMissing(t *testing.T) {
+	WithNode(t, func(nd *Node) {
+		_, err := nd.Cat(h.SumWithBackendHash([]byte("nope")))
+		require.True(t, IsNoSuchBlockError(err))
+	})
+}
+
+func TestCatFromOtherNode(t *testing.T) {
+	WithNode(t, func(srcNd *Node) {
+		WithNode(t, func(dstNd *Node) {
+			data := testutil.CreateDummyBuf(64 * 1024)
+			hash, err := srcNd.Add(bytes.NewReader(data))
+			require.Nil(t, err)
+
+			stream, err := dstNd.Cat(hash)
+			require.Nil(t, err)
+
+			echoData, err := ioutil.ReadAll(stream)
+			require.Nil(t, err)
+			require.Equal(t, data, echoData)
+			require.Nil(t, stream.Close())
+
+			// Fetched blocks are cached, but not pinned.
+			isCached, err := dstNd.IsCached(hash)
+			require.Nil(t, err)
+			require.True(t, isCached)
+
+			isPinned, err := dstNd.IsPinned(hash)
+			require.Nil(t, err)
+			require.False(t, isPinned)
+
+			// Offline nodes do not hand out blocks:
+			otherHash, err := srcNd.Add(bytes.NewReader([]byte{1, 2, 3}))
+			require.Nil(t, err)
+			require.Nil(t, srcNd.Disconnect())
+
+			err = dstNd.Pin(otherHash)
+			require.True(t, IsNoSuchBlockError(err))
+
+			require.Nil(t, srcNd.Connect())
+			require.Nil(t, dstNd.Pin(otherHash))
+
+			isCached, err = dstNd.IsCached(otherHash)
+			require.Nil(t, err)
+			require.True(t, isCached)
+		})
+	})
+}
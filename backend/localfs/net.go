@@ -0,0 +1,265 @@
+package localfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	netBackend "github.com/sahib/brig/net/backend"
+	"github.com/sahib/brig/net/peer"
+	"github.com/sahib/brig/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// The network part of localfs is limited to brig instances running on the
+// same machine. Listeners announce their port in a shared directory, which
+// other instances read on Dial(). This is enough to sync repositories
+// locally (e.g. in tests) without any further infrastructure.
+
+func rendezvousDir() string {
+	return filepath.Join(os.TempDir(), "brig-localfs")
+}
+
+func buildListenAddrDir(id string) string {
+	return filepath.Join(rendezvousDir(), "addrs", url.PathEscape(id))
+}
+
+func buildListenAddrPath(id, protocol string) string {
+	return filepath.Join(buildListenAddrDir(id), url.PathEscape(protocol))
+}
+
+func buildNamePath(name, id string) string {
+	return filepath.Join(rendezvousDir(), "names", url.PathEscape(name), url.PathEscape(id))
+}
+
+// Identity returns our own identity.
+// The address of a localfs node is its fingerprint.
+func (nd *Node) Identity() (peer.Info, error) {
+	return peer.Info{
+		Name: "localfs",
+		Addr: nd.fingerprint,
+	}, nil
+}
+
+// PublishName will announce `name` to other nodes on this machine.
+func (nd *Node) PublishName(name string) error {
+	if !nd.isOnline() {
+		return ErrOffline
+	}
+
+	namePath := buildNamePath(name, nd.fingerprint)
+	if err := os.MkdirAll(filepath.Dir(namePath), 0700); err != nil {
+		return err
+	}
+
+	log.Debugf("published name: »%s«", name)
+	return util.Touch(namePath)
+}
+
+// ResolveName will return all peers that identify themselves as `name`.
+func (nd *Node) ResolveName(ctx context.Context, name string) ([]peer.Info, error) {
+	if !nd.isOnline() {
+		return nil, ErrOffline
+	}
+
+	entries, err := ioutil.ReadDir(filepath.Dir(buildNamePath(name, "")))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	infos := []peer.Info{}
+	for _, entry := range entries {
+		infos = append(infos, peer.Info{
+			Addr: entry.Name(),
+			Name: peer.Name(name),
+		})
+	}
+
+	return infos, nil
+}
+
+// Dial will open a connection to the peer identified by `peerAddr`,
+// running `protocol` over it.
+func (nd *Node) Dial(peerAddr, fingerprint, protocol string) (net.Conn, error) {
+	if !nd.isOnline() {
+		return nil, ErrOffline
+	}
+
+	data, err := ioutil.ReadFile(buildListenAddrPath(peerAddr, protocol)) // #nosec
+	if err != nil {
+		return nil, fmt.Errorf("no route to »%s«: %v", peerAddr, err)
+	}
+
+	log.Debugf("dial to »%s« over %s", peerAddr, data)
+	return net.Dial("tcp", string(data))
+}
+
+type listenerWrapper struct {
+	net.Listener
+	addrPath string
+}
+
+func (lw *listenerWrapper) Close() error {
+	defer os.Remove(lw.addrPath)
+	return lw.Listener.Close()
+}
+
+// Listen will listen to the protocol
+func (nd *Node) Listen(protocol string) (net.Listener, error) {
+	if !nd.isOnline() {
+		return nil, ErrOffline
+	}
+
+	lst, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	addrPath := buildListenAddrPath(nd.fingerprint, protocol)
+	if err := os.MkdirAll(filepath.Dir(addrPath), 0700); err != nil {
+		lst.Close()
+		return nil, err
+	}
+
+	localAddr := lst.Addr().String()
+	if err := ioutil.WriteFile(addrPath, []byte(localAddr), 0600); err != nil {
+		lst.Close()
+		return nil, err
+	}
+
+	log.Debugf("backend: listening for %s on %s", protocol, localAddr)
+	return &listenerWrapper{
+		Listener: lst,
+		addrPath: addrPath,
+	}, nil
+}
+
+/////////////////////////////////
+
+// ErrWaiting is the initial error state of a pinger.
+// The error will be unset once a successful ping was made.
+var ErrWaiting = errors.New("waiting for route")
+
+type pinger struct {
+	lastSeen  time.Time
+	roundtrip time.Duration
+	err       error
+
+	mu     sync.Mutex
+	cancel func()
+}
+
+// LastSeen returns the time we pinged the remote last time.
+func (p *pinger) LastSeen() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.lastSeen
+}
+
+// Roundtrip returns the time needed to connect to the remote.
+func (p *pinger) Roundtrip() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.roundtrip
+}
+
+// Err will return a non-nil error when the current ping did not succeed.
+func (p *pinger) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.err
+}
+
+// Close will clean up the pinger.
+func (p *pinger) Close() error {
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+
+	return nil
+}
+
+// ping checks if any listener of `addr` accepts connections.
+func ping(addr string) (time.Duration, error) {
+	addrDir := buildListenAddrDir(addr)
+	entries, err := ioutil.ReadDir(addrDir)
+	if err != nil {
+		return 0, fmt.Errorf("no route: %v", err)
+	}
+
+	for _, entry := range entries {
+		data, err := ioutil.ReadFile(filepath.Join(addrDir, entry.Name())) // #nosec
+		if err != nil {
+			continue
+		}
+
+		start := time.Now()
+		conn, err := net.DialTimeout("tcp", string(data), 5*time.Second)
+		if err != nil {
+			continue
+		}
+
+		conn.Close()
+		return time.Since(start), nil
+	}
+
+	return 0, fmt.Errorf("no route: %s is not listening", addr)
+}
+
+func (p *pinger) update(addr string) {
+	roundtrip, err := ping(addr)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err != nil {
+		p.err = err
+		return
+	}
+
+	p.err = nil
+	p.lastSeen = time.Now()
+	p.roundtrip = roundtrip
+}
+
+func (p *pinger) Run(ctx context.Context, addr string) {
+	p.update(addr)
+
+	tckr := time.NewTicker(10 * time.Second)
+	defer tckr.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tckr.C:
+			p.update(addr)
+		}
+	}
+}
+
+// Ping will return a pinger for `addr`.
+func (nd *Node) Ping(addr string) (netBackend.Pinger, error) {
+	if !nd.isOnline() {
+		return nil, ErrOffline
+	}
+
+	log.Debugf("backend: start ping »%s«", addr)
+	p := &pinger{err: ErrWaiting}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	go p.Run(ctx, addr)
+	return p, nil
+}
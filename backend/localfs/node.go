@@ -0,0 +1,128 @@
+// Package localfs implements a brig backend that stores all data in a
+// content-addressed block store on the local disk. It does not need any
+// daemon running next to brig and is therefore suited for single-machine
+// setups and test environments.
+package localfs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrOffline is returned by operations that need online support
+	// to work when the backend is in offline mode.
+	ErrOffline = errors.New("backend is in offline mode")
+)
+
+const (
+	blocksDir = "blocks"
+	pinsDir   = "pins"
+	tmpDir    = "tmp"
+)
+
+// Node is the struct that holds the localfs backend together.
+// All state is kept on disk below a single directory.
+type Node struct {
+	mu sync.Mutex
+
+	// storeMu protects the block store from being
+	// modified while a garbage collector run is active.
+	storeMu sync.RWMutex
+
+	path        string
+	fingerprint string
+	allowNetOps bool
+	quiet       bool
+
+	// blocks serves our blocks to other nodes.
+	blocks *blockServer
+}
+
+// Option is a option you can pass to NewNode()
+// It controls the behavior of the node.
+type Option func(nd *Node)
+
+// WithNoLogging will make the node not print log messages.
+// Useful for commandline use cases.
+func WithNoLogging() Option {
+	return func(nd *Node) {
+		nd.quiet = true
+	}
+}
+
+// NewNode returns a new localfs backend that stores its data at `path`.
+// The directory is created if it does not exist yet.
+func NewNode(path, fingerprint string, opts ...Option) (*Node, error) {
+	nd := &Node{
+		path:        path,
+		fingerprint: fingerprint,
+		allowNetOps: true,
+	}
+
+	for _, opt := range opts {
+		opt(nd)
+	}
+
+	for _, dir := range []string{blocksDir, pinsDir, tmpDir} {
+		if err := os.MkdirAll(filepath.Join(path, dir), 0700); err != nil {
+			return nil, err
+		}
+	}
+
+	blocks, err := nd.serveBlocks()
+	if err != nil {
+		return nil, err
+	}
+
+	nd.blocks = blocks
+	if !nd.quiet {
+		log.Infof("Using local block store at %s", path)
+	}
+
+	return nd, nil
+}
+
+// IsOnline returns true if the node is in online mode.
+func (nd *Node) IsOnline() bool {
+	return nd.isOnline()
+}
+
+// Connect implements Backend.Connect
+func (nd *Node) Connect() error {
+	nd.mu.Lock()
+	defer nd.mu.Unlock()
+
+	nd.allowNetOps = true
+	return nil
+}
+
+// Disconnect implements Backend.Disconnect
+func (nd *Node) Disconnect() error {
+	nd.mu.Lock()
+	defer nd.mu.Unlock()
+
+	nd.allowNetOps = false
+	return nil
+}
+
+func (nd *Node) isOnline() bool {
+	nd.mu.Lock()
+	defer nd.mu.Unlock()
+
+	return nd.allowNetOps
+}
+
+// Close implements Backend.Close
+func (nd *Node) Close() error {
+	return nd.blocks.Close()
+}
+
+// Name returns "localfs" as name of the backend.
+func (nd *Node) Name() string {
+	return "localfs"
+}
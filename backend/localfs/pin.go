@@ -0,0 +1,92 @@
+package localfs
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/sahib/brig/util"
+	h "github.com/sahib/brig/util/hashlib"
+)
+
+func (nd *Node) pinPath(hash h.Hash) string {
+	return filepath.Join(nd.path, pinsDir, hash.B58String())
+}
+
+func (nd *Node) writePin(hash h.Hash) error {
+	return util.Touch(nd.pinPath(hash))
+}
+
+// IsPinned returns true when `hash` is pinned.
+func (nd *Node) IsPinned(hash h.Hash) (bool, error) {
+	if _, err := os.Stat(nd.pinPath(hash)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Pin will pin `hash`.
+// If the block is not stored locally, it is fetched from other nodes first.
+func (nd *Node) Pin(hash h.Hash) error {
+	isCached, err := nd.IsCached(hash)
+	if err != nil {
+		return err
+	}
+
+	if !isCached {
+		if err := nd.fetchBlock(hash); err != nil {
+			return err
+		}
+	}
+
+	nd.storeMu.RLock()
+	defer nd.storeMu.RUnlock()
+
+	return nd.writePin(hash)
+}
+
+// Unpin will unpin `hash`.
+// Unpinning a hash that is not pinned is not an error.
+func (nd *Node) Unpin(hash h.Hash) error {
+	nd.storeMu.RLock()
+	defer nd.storeMu.RUnlock()
+
+	err := os.Remove(nd.pinPath(hash))
+	if err == nil || os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+// IsCached checks if the block for `hash` is stored locally.
+func (nd *Node) IsCached(hash h.Hash) (bool, error) {
+	if _, err := os.Stat(nd.blockPath(hash)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CachedSize returns the size of the locally stored block.
+// Negative indicates that the block is not stored locally.
+func (nd *Node) CachedSize(hash h.Hash) (int64, error) {
+	info, err := os.Stat(nd.blockPath(hash))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return -1, nil
+		}
+
+		return -1, err
+	}
+
+	return info.Size(), nil
+}
@@ -0,0 +1,81 @@
+package localfs
+
+import (
+	"bytes"
+	"testing"
+
+	h "github.com/sahib/brig/util/hashlib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPinUnpin(t *testing.T) {
+	WithNode(t, func(nd *Node) {
+		hash, err := nd.Add(bytes.NewReader([]byte{1, 2, 3}))
+		require.Nil(t, err)
+
+		isPinned, err := nd.IsPinned(hash)
+		require.Nil(t, err)
+		require.True(t, isPinned)
+
+		require.Nil(t, nd.Unpin(hash))
+		require.Nil(t, nd.Unpin(hash))
+
+		isPinned, err = nd.IsPinned(hash)
+		require.Nil(t, err)
+		require.False(t, isPinned)
+
+		require.Nil(t, nd.Pin(hash))
+
+		isPinned, err = nd.IsPinned(hash)
+		require.Nil(t, err)
+		require.True(t, isPinned)
+	})
+}
+
+func TestIsCachedAndSize(t *testing.T) {
+	WithNode(t, func(nd *Node) {
+		hash, err := nd.Add(bytes.NewReader([]byte{1, 2, 3}))
+		require.Nil(t, err)
+
+		isCached, err := nd.IsCached(hash)
+		require.Nil(t, err)
+		require.True(t, isCached)
+
+		size, err := nd.CachedSize(hash)
+		require.Nil(t, err)
+		require.Equal(t, int64(3), size)
+
+		dummyHash := h.SumWithBackendHash([]byte{4, 5, 6})
+		isCached, err = nd.IsCached(dummyHash)
+		require.Nil(t, err)
+		require.False(t, isCached)
+
+		size, err = nd.CachedSize(dummyHash)
+		require.Nil(t, err)
+		require.Equal(t, int64(-1), size)
+	})
+}
+
+func TestGC(t *testing.T) {
+	WithNode(t, func(nd *Node) {
+		keepHash, err := nd.Add(bytes.NewReader([]byte{1, 2, 3}))
+		require.Nil(t, err)
+
+		dropHash, err := nd.Add(bytes.NewReader([]byte{4, 5, 6}))
+		require.Nil(t, err)
+		require.Nil(t, nd.Unpin(dropHash))
+
+		hs, err := nd.GC()
+		require.Nil(t, err)
+		require.Len(t, hs, 1)
+		require.Equal(t, dropHash, hs[0])
+
+		isCached, err := nd.IsCached(dropHash)
+		require.Nil(t, err)
+		require.False(t, isCached)
+
+		isCached, err = nd.IsCached(keepHash)
+		require.Nil(t, err)
+		require.True(t, isCached)
+	})
+}
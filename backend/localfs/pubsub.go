@@ -0,0 +1,162 @@
+package localfs
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	eventsBackend "github.com/sahib/brig/events/backend"
+	log "github.com/sirupsen/logrus"
+)
+
+// Pub/Sub works like the rest of the network part: Each subscription opens a
+// listener and announces it in the rendezvous directory below the topic.
+// Publishing a message means delivering it to all announced listeners.
+
+func buildTopicDir(topic string) string {
+	return filepath.Join(rendezvousDir(), "topics", url.PathEscape(topic))
+}
+
+type pubsubMessage struct {
+	Src     string `json:"source"`
+	Payload []byte `json:"data"`
+}
+
+func (msg *pubsubMessage) Data() []byte {
+	return msg.Payload
+}
+
+func (msg *pubsubMessage) Source() string {
+	return msg.Src
+}
+
+type subscription struct {
+	lst     net.Listener
+	msgs    chan *pubsubMessage
+	subPath string
+}
+
+func (s *subscription) acceptLoop() {
+	for {
+		conn, err := s.lst.Accept()
+		if err != nil {
+			// Listener was closed.
+			return
+		}
+
+		go func() {
+			defer conn.Close()
+
+			conn.SetDeadline(time.Now().Add(10 * time.Second))
+			msg := &pubsubMessage{}
+			if err := json.NewDecoder(conn).Decode(msg); err != nil {
+				log.Debugf("pubsub: received bad message: %v", err)
+				return
+			}
+
+			select {
+			case s.msgs <- msg:
+			default:
+				log.Debugf("pubsub: dropping message from %s", msg.Src)
+			}
+		}()
+	}
+}
+
+func (s *subscription) Next(ctx context.Context) (eventsBackend.Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case msg := <-s.msgs:
+		return msg, nil
+	}
+}
+
+func (s *subscription) Close() error {
+	defer os.Remove(s.subPath)
+	return s.lst.Close()
+}
+
+// Subscribe will create a subscription for `topic`.
+// You can use the subscription to wait for the next incoming message.
+func (nd *Node) Subscribe(ctx context.Context, topic string) (eventsBackend.Subscription, error) {
+	if !nd.isOnline() {
+		return nil, ErrOffline
+	}
+
+	lst, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	subPath := filepath.Join(buildTopicDir(topic), url.PathEscape(nd.fingerprint))
+	if err := os.MkdirAll(filepath.Dir(subPath), 0700); err != nil {
+		lst.Close()
+		return nil, err
+	}
+
+	if err := ioutil.WriteFile(subPath, []byte(lst.Addr().String()), 0600); err != nil {
+		lst.Close()
+		return nil, err
+	}
+
+	sub := &subscription{
+		lst:     lst,
+		msgs:    make(chan *pubsubMessage, 100),
+		subPath: subPath,
+	}
+
+	go sub.acceptLoop()
+	return sub, nil
+}
+
+// PublishEvent will publish `data` on `topic`.
+// Subscribers that are not reachable are silently skipped.
+func (nd *Node) PublishEvent(topic string, data []byte) error {
+	if !nd.isOnline() {
+		return ErrOffline
+	}
+
+	topicDir := buildTopicDir(topic)
+	entries, err := ioutil.ReadDir(topicDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Nobody is interested.
+			return nil
+		}
+
+		return err
+	}
+
+	msg := pubsubMessage{
+		Src:     nd.fingerprint,
+		Payload: data,
+	}
+
+	for _, entry := range entries {
+		addr, err := ioutil.ReadFile(filepath.Join(topicDir, entry.Name())) // #nosec
+		if err != nil {
+			continue
+		}
+
+		conn, err := net.DialTimeout("tcp", string(addr), 5*time.Second)
+		if err != nil {
+			log.Debugf("pubsub: subscriber %s unreachable: %v", entry.Name(), err)
+			continue
+		}
+
+		conn.SetDeadline(time.Now().Add(10 * time.Second))
+		if err := json.NewEncoder(conn).Encode(msg); err != nil {
+			log.Debugf("pubsub: failed to send to %s: %v", entry.Name(), err)
+		}
+
+		conn.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package localfs
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// WithNode creates a localfs node in a temporary directory and calls `fn`
+// with it. The directory is removed afterwards.
+func WithNode(t *testing.T, fn func(nd *Node)) {
+	path, err := ioutil.TempDir("", "brig-localfs-test-")
+	require.Nil(t, err)
+	defer os.RemoveAll(path)
+
+	nd, err := NewNode(path, "", WithNoLogging())
+	require.Nil(t, err)
+	defer nd.Close()
+
+	fn(nd)
+}
@@ -0,0 +1,32 @@
+package localfs
+
+import "github.com/sahib/brig/version"
+
+// VersionInfo holds version info (yeah, golint)
+type VersionInfo struct {
+	semVer, name, rev string
+}
+
+// SemVer returns a VersionInfo string complying semantic versioning
+func (v *VersionInfo) SemVer() string { return v.semVer }
+
+// Name returns the name of the backend
+func (v *VersionInfo) Name() string { return v.name }
+
+// Rev returns the git revision of the backend
+func (v *VersionInfo) Rev() string { return v.rev }
+
+// Version returns detailed VersionInfo info as struct.
+// Since localfs is part of brig, it shares brig's version.
+func Version() *VersionInfo {
+	return &VersionInfo{
+		semVer: version.String(),
+		name:   "localfs",
+		rev:    version.GitRev,
+	}
+}
+
+// Version returns the same as the package level Version().
+func (nd *Node) Version() *VersionInfo {
+	return Version()
+}
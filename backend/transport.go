@@ -0,0 +1,81 @@
+package backend
+
+import (
+	"context"
+	"net"
+
+	netBackend "github.com/sahib/brig/net/backend"
+	"github.com/sahib/brig/net/peer"
+)
+
+// Transport is a network backend that does not decide our identity.
+// It is used to replace the network part of a data backend.
+type Transport interface {
+	ResolveName(ctx context.Context, name string) ([]peer.Info, error)
+	PublishName(name string) error
+	Dial(peerAddr, fingerprint, protocol string) (net.Conn, error)
+	Listen(protocol string) (net.Listener, error)
+	Ping(peerAddr string) (netBackend.Pinger, error)
+	Connect() error
+	Disconnect() error
+	IsOnline() bool
+}
+
+// transportBackend uses a Transport for all network operations.
+// Everything else (including Identity) is handled by the data backend.
+type transportBackend struct {
+	Backend
+	tp Transport
+}
+
+// WithTransport returns a backend that uses `tp` for talking to other peers
+// and `bk` for everything else. The addr part of our fingerprint is still
+// taken from `bk`, so fingerprints do not change when switching transports.
+func WithTransport(bk Backend, tp Transport) Backend {
+	return &transportBackend{
+		Backend: bk,
+		tp:      tp,
+	}
+}
+
+func (tb *transportBackend) ResolveName(ctx context.Context, name string) ([]peer.Info, error) {
+	return tb.tp.ResolveName(ctx, name)
+}
+
+func (tb *transportBackend) PublishName(name string) error {
+	return tb.tp.PublishName(name)
+}
+
+func (tb *transportBackend) Dial(peerAddr, fingerprint, protocol string) (net.Conn, error) {
+	return tb.tp.Dial(peerAddr, fingerprint, protocol)
+}
+
+func (tb *transportBackend) Listen(protocol string) (net.Listener, error) {
+	return tb.tp.Listen(protocol)
+}
+
+func (tb *transportBackend) Ping(peerAddr string) (netBackend.Pinger, error) {
+	return tb.tp.Ping(peerAddr)
+}
+
+// Connect brings both the data backend and the transport online.
+func (tb *transportBackend) Connect() error {
+	if err := tb.Backend.Connect(); err != nil {
+		return err
+	}
+
+	return tb.tp.Connect()
+}
+
+// Disconnect takes both the data backend and the transport offline.
+func (tb *transportBackend) Disconnect() error {
+	if err := tb.Backend.Disconnect(); err != nil {
+		return err
+	}
+
+	return tb.tp.Disconnect()
+}
+
+func (tb *transportBackend) IsOnline() bool {
+	return tb.tp.IsOnline()
+}
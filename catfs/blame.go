@@ -0,0 +1,64 @@
+package catfs
+
+import (
+	"github.com/sahib/brig/catfs/vcs"
+)
+
+// BlameEntry tells where a single version of a file came from.
+type BlameEntry struct {
+	// Path is the path of the node in this version.
+	Path string
+
+	// Change describes what was changed in this version.
+	Change string
+
+	// Commit is the commit that introduced the version in this repository.
+	Commit *Commit
+
+	// Remote is the owner of the repository where the version was made.
+	Remote string
+
+	// Via is either "local", "sync" or "push".
+	Via string
+}
+
+// Blame returns the origin of each version of the node at `path`,
+// with the most recent version first. Versions that were merged
+// from another branch are attributed to where they were made there.
+func (fs *FS) Blame(path string) ([]BlameEntry, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	nd, err := fs.lkr.LookupModNode(path)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := fs.lkr.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	blame, err := vcs.Blame(fs.lkr, nd, status)
+	if err != nil {
+		return nil, err
+	}
+
+	hashToRef, err := fs.buildCommitHashToRefTable()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []BlameEntry{}
+	for _, entry := range blame {
+		entries = append(entries, BlameEntry{
+			Path:   entry.Change.Curr.Path(),
+			Change: entry.Change.Mask.String(),
+			Commit: commitToExternal(entry.Change.Head, hashToRef),
+			Remote: entry.Remote,
+			Via:    entry.Via,
+		})
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,45 @@
+package catfs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlame(t *testing.T) {
+	t.Parallel()
+
+	withDummyFS(t, func(fsa *FS) {
+		require.Nil(t, fsa.MakeCommit("hello a"))
+		withDummyFS(t, func(fsb *FS) {
+			require.Nil(t, fsb.MakeCommit("hello b"))
+
+			require.Nil(t, fsb.Stage("/x", bytes.NewReader([]byte{1})))
+			require.Nil(t, fsb.MakeCommit("add x"))
+			require.Nil(t, fsa.Sync(fsb, SyncOptPush()))
+
+			require.Nil(t, fsa.Stage("/x", bytes.NewReader([]byte{2})))
+
+			entries, err := fsa.Blame("/x")
+			require.Nil(t, err)
+			require.Len(t, entries, 2)
+
+			owner, err := fsa.lkr.Owner()
+			require.Nil(t, err)
+
+			// The uncommitted change comes first:
+			require.Equal(t, "/x", entries[0].Path)
+			require.Equal(t, "modified", entries[0].Change)
+			require.Equal(t, owner, entries[0].Remote)
+			require.Equal(t, "local", entries[0].Via)
+
+			require.Equal(t, "added", entries[1].Change)
+			require.Equal(t, "push", entries[1].Via)
+
+			head, err := fsa.CommitInfo("head")
+			require.Nil(t, err)
+			require.Equal(t, head.Hash, entries[1].Commit.Hash)
+		})
+	})
+}
@@ -0,0 +1,153 @@
+package catfs
+
+import (
+	"fmt"
+	"strings"
+
+	e "github.com/pkg/errors"
+	"github.com/sahib/brig/catfs/vcs"
+)
+
+// Branch describes a named line of development.
+type Branch struct {
+	// Name is the name of the branch.
+	Name string
+	// Head is the latest commit of the branch.
+	Head *Commit
+	// IsCurrent is true for the branch we are on.
+	IsCurrent bool
+}
+
+// Branches returns all branches, sorted by name.
+func (fs *FS) Branches() ([]Branch, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	curr, err := fs.lkr.CurrentBranch()
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := fs.lkr.ListBranches()
+	if err != nil {
+		return nil, err
+	}
+
+	hashToRef, err := fs.buildCommitHashToRefTable()
+	if err != nil {
+		return nil, err
+	}
+
+	branches := []Branch{}
+	for _, name := range names {
+		head, err := fs.lkr.BranchHead(name)
+		if err != nil {
+			return nil, e.Wrapf(err, "head of branch %s", name)
+		}
+
+		branches = append(branches, Branch{
+			Name:      name,
+			Head:      commitToExternal(head, hashToRef),
+			IsCurrent: name == curr,
+		})
+	}
+
+	return branches, nil
+}
+
+// CurrentBranch returns the name of the branch we are on.
+func (fs *FS) CurrentBranch() (string, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	return fs.lkr.CurrentBranch()
+}
+
+// CreateBranch creates a new branch called `name` that starts at `rev`.
+// If `rev` is empty, the branch starts at HEAD. The new branch is not
+// switched to; use SwitchBranch for that.
+func (fs *FS) CreateBranch(name, rev string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.readOnly {
+		return ErrReadOnly
+	}
+
+	if rev == "" {
+		rev = "head"
+	}
+
+	cmt, err := parseRev(fs.lkr, rev)
+	if err != nil {
+		return e.Wrap(err, "parse ref")
+	}
+
+	return fs.lkr.CreateBranch(name, cmt)
+}
+
+// SwitchBranch makes `name` the current branch and resets the state
+// of the filesystem to its latest commit. If `force` is false, this
+// fails if there are uncommitted changes. Otherwise they are lost.
+func (fs *FS) SwitchBranch(name string, force bool) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.readOnly {
+		return ErrReadOnly
+	}
+
+	return fs.lkr.SwitchBranch(name, force)
+}
+
+// DeleteBranch removes the branch called `name`.
+// The current branch can not be deleted.
+func (fs *FS) DeleteBranch(name string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.readOnly {
+		return ErrReadOnly
+	}
+
+	return fs.lkr.DeleteBranch(name)
+}
+
+// MergeBranch merges the changes of the branch `name` into the current
+// branch. This works like Sync() with a remote and takes the same options.
+// If anything changed, a merge commit is made on the current branch.
+func (fs *FS) MergeBranch(name string, options ...SyncOption) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.readOnly {
+		return ErrReadOnly
+	}
+
+	name = strings.ToLower(name)
+	curr, err := fs.lkr.CurrentBranch()
+	if err != nil {
+		return err
+	}
+
+	if name == curr {
+		return fmt.Errorf("cannot merge branch %s into itself", name)
+	}
+
+	srcHead, err := fs.lkr.BranchHead(name)
+	if err != nil {
+		return err
+	}
+
+	syncCfg, err := fs.buildSyncCfg()
+	if err != nil {
+		return err
+	}
+
+	syncCfg.Message = fmt.Sprintf("merge branch »%s« into »%s«", name, curr)
+	for _, option := range options {
+		option(syncCfg)
+	}
+
+	return vcs.MergeCommit(fs.lkr, name, srcHead, syncCfg)
+}
@@ -0,0 +1,109 @@
+package catfs
+
+import (
+	"bytes"
+	"testing"
+
+	c "github.com/sahib/brig/catfs/core"
+	ie "github.com/sahib/brig/catfs/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBranchCreateSwitchDelete(t *testing.T) {
+	t.Parallel()
+
+	withDummyFS(t, func(fs *FS) {
+		require.Nil(t, fs.Stage("/x", bytes.NewReader([]byte("master"))))
+		require.Nil(t, fs.MakeCommit("add x"))
+
+		require.Nil(t, fs.CreateBranch("dev", ""))
+		require.Nil(t, fs.SwitchBranch("dev", false))
+
+		require.Nil(t, fs.Stage("/x", bytes.NewReader([]byte("dev"))))
+		require.Nil(t, fs.MakeCommit("edit x on dev"))
+
+		branches, err := fs.Branches()
+		require.Nil(t, err)
+		require.Len(t, branches, 2)
+		require.Equal(t, "dev", branches[0].Name)
+		require.True(t, branches[0].IsCurrent)
+		require.Equal(t, "edit x on dev", branches[0].Head.Msg)
+		require.Equal(t, c.DefaultBranch, branches[1].Name)
+		require.False(t, branches[1].IsCurrent)
+		require.Equal(t, "add x", branches[1].Head.Msg)
+
+		require.Nil(t, fs.SwitchBranch(c.DefaultBranch, false))
+		requireContent(t, fs, "/x", "master")
+
+		require.Nil(t, fs.DeleteBranch("dev"))
+		_, err = fs.lkr.BranchHead("dev")
+		require.True(t, ie.IsErrNoSuchRef(err))
+	})
+}
+
+func TestBranchMerge(t *testing.T) {
+	t.Parallel()
+
+	withDummyFS(t, func(fs *FS) {
+		require.Nil(t, fs.Stage("/x", bytes.NewReader([]byte("base"))))
+		require.Nil(t, fs.MakeCommit("add x"))
+
+		require.Nil(t, fs.CreateBranch("dev", "head"))
+		require.Nil(t, fs.SwitchBranch("dev", false))
+		require.Nil(t, fs.Stage("/x", bytes.NewReader([]byte("dev"))))
+		require.Nil(t, fs.Stage("/y", bytes.NewReader([]byte("new"))))
+		require.Nil(t, fs.MakeCommit("work on dev"))
+
+		require.Nil(t, fs.SwitchBranch(c.DefaultBranch, false))
+		require.Nil(t, fs.Stage("/z", bytes.NewReader([]byte("master"))))
+		require.Nil(t, fs.MakeCommit("work on master"))
+
+		require.NotNil(t, fs.MergeBranch(c.DefaultBranch))
+		require.Nil(t, fs.MergeBranch("dev"))
+
+		requireContent(t, fs, "/x", "dev")
+		requireContent(t, fs, "/y", "new")
+		requireContent(t, fs, "/z", "master")
+
+		head, err := fs.lkr.Head()
+		require.Nil(t, err)
+		require.Equal(t, "merge branch »dev« into »master«", head.Message())
+
+		// The merge advanced the current branch, not dev:
+		devHead, err := fs.lkr.BranchHead("dev")
+		require.Nil(t, err)
+		require.Equal(t, "work on dev", devHead.Message())
+	})
+}
+
+func TestBranchMergeTwoBranches(t *testing.T) {
+	t.Parallel()
+
+	withDummyFS(t, func(fs *FS) {
+		require.Nil(t, fs.Stage("/x", bytes.NewReader([]byte("base"))))
+		require.Nil(t, fs.MakeCommit("add x"))
+
+		require.Nil(t, fs.CreateBranch("a", "head"))
+		require.Nil(t, fs.CreateBranch("b", "head"))
+
+		require.Nil(t, fs.SwitchBranch("a", false))
+		require.Nil(t, fs.Stage("/a", bytes.NewReader([]byte("a"))))
+		require.Nil(t, fs.MakeCommit("work on a"))
+
+		require.Nil(t, fs.SwitchBranch("b", false))
+		require.Nil(t, fs.Stage("/x", bytes.NewReader([]byte("b"))))
+		require.Nil(t, fs.MakeCommit("work on b"))
+
+		require.Nil(t, fs.SwitchBranch(c.DefaultBranch, false))
+		require.Nil(t, fs.Stage("/x", bytes.NewReader([]byte("master"))))
+		require.Nil(t, fs.MakeCommit("work on master"))
+
+		require.Nil(t, fs.MergeBranch("a"))
+		requireContent(t, fs, "/a", "a")
+
+		// The merge of a must not be used as base for b,
+		// otherwise the edit of x on master would be overwritten:
+		require.Nil(t, fs.MergeBranch("b"))
+		requireContent(t, fs, "/x", "master")
+	})
+}
@@ -0,0 +1,252 @@
+package catfs
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"io"
+
+	"github.com/dustin/go-humanize"
+	e "github.com/pkg/errors"
+	"github.com/sahib/brig/catfs/mio"
+	"github.com/sahib/brig/catfs/mio/chunker"
+	n "github.com/sahib/brig/catfs/nodes"
+	"github.com/sahib/brig/repo/hints"
+	"github.com/sahib/brig/util"
+	h "github.com/sahib/brig/util/hashlib"
+	log "github.com/sirupsen/logrus"
+)
+
+// contentInfo describes content that was added to the backend.
+type contentInfo struct {
+	contentHash h.Hash
+	backendHash h.Hash
+	chunks      []n.Chunk
+	size        uint64
+	cachedSize  int64
+	isRaw       bool
+}
+
+func (fs *FS) chunkSize() int {
+	if !fs.cfg.Bool("chunking.enabled") {
+		return 0
+	}
+
+	avgSizeSrc := fs.cfg.String("chunking.avg_size")
+	avgSize, err := humanize.ParseBytes(avgSizeSrc)
+	if err != nil {
+		log.Warnf("failed to parse chunking.avg_size '%s': %v", avgSizeSrc, err)
+		return 0
+	}
+
+	return int(avgSize)
+}
+
+// addContent reads all of `r` and adds it to the backend.
+// If chunking is enabled the data is split into content-defined chunks,
+// each stored as separate backend object. Data that fits into one chunk
+// is stored as single object, encrypted with `key`.
+//
+// NOTE: This method can be called without locking fs.mu!
+func (fs *FS) addContent(path string, r io.Reader, key []byte) (*contentInfo, error) {
+	// Branch off a part of the stream and pipe it through
+	// a hash writer to compute the hash while reading the stream:
+	hashWriter := h.NewHashWriter()
+	hashReader := io.TeeReader(r, hashWriter)
+
+	// Do the same with the size.
+	// This actually measures the size of the stream and is
+	// therefore guaranteed to find out the actual stream size.
+	sizeAcc := &util.SizeAccumulator{}
+	sizeReader := io.TeeReader(hashReader, sizeAcc)
+
+	hint := fs.hintManager.Lookup(path)
+
+	var info *contentInfo
+	var err error
+
+	avgSize := fs.chunkSize()
+	if avgSize > 0 {
+		info, err = fs.addChunks(path, sizeReader, key, hint, avgSize)
+	} else {
+		info, err = fs.addBlob(path, sizeReader, key, hint)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The stream was consumed, we now know those attrs:
+	info.size = sizeAcc.Size()
+	info.contentHash = hashWriter.Finalize()
+	return info, nil
+}
+
+// addBlob adds all of `r` as single object to the backend.
+func (fs *FS) addBlob(path string, r io.Reader, key []byte, hint hints.Hint) (*contentInfo, error) {
+	stream, isRaw, err := mio.NewInStream(r, path, key, hint)
+	if err != nil {
+		return nil, err
+	}
+
+	backendHash, err := fs.bk.Add(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	cachedSize, err := fs.bk.CachedSize(backendHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &contentInfo{
+		backendHash: backendHash,
+		cachedSize:  cachedSize,
+		isRaw:       isRaw,
+	}, nil
+}
+
+func (fs *FS) addChunks(path string, r io.Reader, key []byte, hint hints.Hint, avgSize int) (*contentInfo, error) {
+	chk, err := chunker.New(r, avgSize)
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := chk.Next()
+	if err == io.EOF {
+		return fs.addBlob(path, bytes.NewReader(nil), key, hint)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The chunker re-uses its buffer on the next call.
+	first = append([]byte(nil), first...)
+
+	data, err := chk.Next()
+	if err == io.EOF {
+		// Small files are not worth to be chunked.
+		return fs.addBlob(path, bytes.NewReader(first), key, hint)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// All chunks need to be encoded the same way, so resolve
+	// the compression guess once with the start of the file.
+	header := first
+	if len(header) > 2048 {
+		header = header[:2048]
+	}
+
+	hint = mio.ResolveCompressionGuess(path, header, hint)
+
+	info := &contentInfo{}
+	convergent := fs.cfg.Bool("chunking.convergent")
+	for _, data := range [][]byte{first, data} {
+		if err := fs.addChunk(path, data, key, convergent, hint, info); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		data, err := chk.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := fs.addChunk(path, data, key, convergent, hint, info); err != nil {
+			return nil, err
+		}
+	}
+
+	info.backendHash = n.ChunkListHash(info.chunks)
+	return info, nil
+}
+
+// chunkKey derives the key of a chunk from its content, so that chunks with
+// the same data produce the same backend object and get deduplicated.
+//
+// By default the content is mixed with `fileKey`. Since the key of a file is
+// kept over its versions, only chunks of the same file are deduplicated.
+// If `convergent` is true, the key depends only on the content and identical
+// chunks of different files are stored once. This comes at a price: everyone
+// who has a candidate file can check if it is stored by a peer.
+func chunkKey(fileKey, data []byte, convergent bool) []byte {
+	if convergent {
+		key := sha256.Sum256(data)
+		return key[:]
+	}
+
+	mac := hmac.New(sha256.New, fileKey)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// addChunk adds a single chunk to the backend and appends it to `info`.
+func (fs *FS) addChunk(path string, data, fileKey []byte, convergent bool, hint hints.Hint, info *contentInfo) error {
+	key := chunkKey(fileKey, data, convergent)
+
+	blob, err := fs.addBlob(path, bytes.NewReader(data), key, hint)
+	if err != nil {
+		return e.Wrapf(err, "failed to add chunk %d", len(info.chunks))
+	}
+
+	info.isRaw = blob.isRaw
+	info.cachedSize += blob.cachedSize
+	info.chunks = append(info.chunks, n.Chunk{
+		BackendHash: blob.backendHash,
+		Key:         key,
+		Size:        uint64(len(data)),
+	})
+
+	return nil
+}
+
+// catChunks returns a stream that reads all of `chunks`,
+// truncated to `size`.
+//
+// NOTE: This method can be called without locking fs.mu!
+func (fs *FS) catChunks(chunks []n.Chunk, size uint64, isRaw bool) mio.Stream {
+	sizes := make([]uint64, 0, len(chunks))
+	for _, chunk := range chunks {
+		sizes = append(sizes, chunk.Size)
+	}
+
+	stream := mio.NewChunkedStream(sizes, func(idx int) (mio.Stream, error) {
+		chunk := chunks[idx]
+		return fs.catHash(chunk.BackendHash, chunk.Key, chunk.Size, isRaw)
+	})
+
+	return mio.LimitStream(stream, size)
+}
+
+// catFile returns a stream for the content of `file`.
+//
+// NOTE: This method needs fs.mu to be locked, unless `file` is a copy
+// that is not reachable by other goroutines.
+func (fs *FS) catFile(file *n.File) (mio.Stream, error) {
+	if file.IsChunked() {
+		return fs.catChunks(file.Chunks(), file.Size(), file.IsRaw()), nil
+	}
+
+	return fs.catHash(file.BackendHash(), file.Key(), file.Size(), file.IsRaw())
+}
+
+// isContentCached checks if all backend objects of `file` are available locally.
+func (fs *FS) isContentCached(file *n.File) (bool, error) {
+	for _, hash := range file.BackendHashes() {
+		isCached, err := fs.bk.IsCached(hash)
+		if err != nil || !isCached {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
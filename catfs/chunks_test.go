@@ -0,0 +1,151 @@
+package catfs
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	n "github.com/sahib/brig/catfs/nodes"
+	"github.com/sahib/brig/util/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func countSharedChunks(a, b []n.Chunk) int {
+	known := make(map[string]bool)
+	for _, chunk := range a {
+		known[chunk.BackendHash.B58String()] = true
+	}
+
+	shared := 0
+	for _, chunk := range b {
+		if known[chunk.BackendHash.B58String()] {
+			shared++
+		}
+	}
+
+	return shared
+}
+
+func TestStageChunked(t *testing.T) {
+	t.Parallel()
+
+	withDummyFS(t, func(fs *FS) {
+		fs.cfg.SetBool("chunking.enabled", true)
+		fs.cfg.SetString("chunking.avg_size", "4K")
+
+		data := testutil.CreateRandomDummyBuf(256*1024, 23)
+		require.Nil(t, fs.Stage("/x", bytes.NewReader(data)))
+
+		file, err := fs.lkr.LookupFile("/x")
+		require.Nil(t, err)
+		require.True(t, file.IsChunked())
+		require.Equal(t, uint64(len(data)), file.Size())
+		require.True(t, file.BackendHash().Equal(n.ChunkListHash(file.Chunks())))
+
+		stream, err := fs.Cat("/x")
+		require.Nil(t, err)
+		catData, err := ioutil.ReadAll(stream)
+		require.Nil(t, err)
+		require.Equal(t, data, catData)
+		require.Nil(t, stream.Close())
+
+		// Reading through a handle should work across chunks too:
+		hdl, err := fs.Open("/x")
+		require.Nil(t, err)
+		_, err = hdl.Seek(100*1024, io.SeekStart)
+		require.Nil(t, err)
+		buf := make([]byte, 64*1024)
+		_, err = io.ReadFull(hdl, buf)
+		require.Nil(t, err)
+		require.Equal(t, data[100*1024:164*1024], buf)
+		require.Nil(t, hdl.Close())
+
+		// Change a few bytes in the middle; most chunks should stay the same.
+		edited := append([]byte(nil), data...)
+		copy(edited[128*1024:], []byte("hello world"))
+		oldChunks := file.Chunks()
+		require.Nil(t, fs.Stage("/x", bytes.NewReader(edited)))
+
+		file, err = fs.lkr.LookupFile("/x")
+		require.Nil(t, err)
+		newChunks := file.Chunks()
+		shared := countSharedChunks(oldChunks, newChunks)
+		require.True(t, shared >= len(newChunks)-2, "only %d of %d shared", shared, len(newChunks))
+		require.True(t, shared < len(newChunks))
+
+		// Other files use another key, so their chunks differ.
+		require.Nil(t, fs.Stage("/y", bytes.NewReader(edited)))
+		other, err := fs.lkr.LookupFile("/y")
+		require.Nil(t, err)
+		require.Equal(t, 0, countSharedChunks(newChunks, other.Chunks()))
+
+		// Small files are stored as single object.
+		require.Nil(t, fs.Stage("/z", bytes.NewReader([]byte("small"))))
+		small, err := fs.lkr.LookupFile("/z")
+		require.Nil(t, err)
+		require.False(t, small.IsChunked())
+	})
+}
+
+func TestStageChunkedConvergent(t *testing.T) {
+	t.Parallel()
+
+	withDummyFS(t, func(fs *FS) {
+		fs.cfg.SetBool("chunking.enabled", true)
+		fs.cfg.SetString("chunking.avg_size", "4K")
+		fs.cfg.SetBool("chunking.convergent", true)
+
+		data := testutil.CreateRandomDummyBuf(128*1024, 5)
+		require.Nil(t, fs.Stage("/x", bytes.NewReader(data)))
+		require.Nil(t, fs.Stage("/y", bytes.NewReader(data)))
+
+		x, err := fs.lkr.LookupFile("/x")
+		require.Nil(t, err)
+		y, err := fs.lkr.LookupFile("/y")
+		require.Nil(t, err)
+
+		// Same content in another file is stored only once.
+		require.True(t, x.IsChunked())
+		require.Equal(t, len(x.Chunks()), countSharedChunks(x.Chunks(), y.Chunks()))
+
+		// Unpinning /y must not remove chunks that /x still needs.
+		require.Nil(t, fs.Unpin("/y", "curr", true))
+		isPinned, _, err := fs.IsPinned("/x")
+		require.Nil(t, err)
+		require.True(t, isPinned)
+		for _, chunk := range x.Chunks() {
+			isPinned, err := fs.bk.IsPinned(chunk.BackendHash)
+			require.Nil(t, err)
+			require.True(t, isPinned)
+		}
+
+		stream, err := fs.Cat("/y")
+		require.Nil(t, err)
+		catData, err := ioutil.ReadAll(stream)
+		require.Nil(t, err)
+		require.Equal(t, data, catData)
+	})
+}
+
+func TestStageChunkingDisabled(t *testing.T) {
+	t.Parallel()
+
+	withDummyFS(t, func(fs *FS) {
+		fs.cfg.SetBool("chunking.enabled", false)
+		fs.cfg.SetString("chunking.avg_size", "4K")
+
+		data := testutil.CreateRandomDummyBuf(64*1024, 42)
+		require.Nil(t, fs.Stage("/x", bytes.NewReader(data)))
+
+		file, err := fs.lkr.LookupFile("/x")
+		require.Nil(t, err)
+		require.False(t, file.IsChunked())
+
+		stream, err := fs.Cat("/x")
+		require.Nil(t, err)
+		catData, err := ioutil.ReadAll(stream)
+		require.Nil(t, err)
+		require.Equal(t, data, catData)
+	})
+}
@@ -0,0 +1,319 @@
+package catfs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	e "github.com/pkg/errors"
+	c "github.com/sahib/brig/catfs/core"
+	ie "github.com/sahib/brig/catfs/errors"
+	n "github.com/sahib/brig/catfs/nodes"
+	"github.com/sahib/brig/catfs/vcs"
+)
+
+const (
+	// ConflictTakeOurs keeps our version and drops the conflict file.
+	ConflictTakeOurs = "ours"
+	// ConflictTakeTheirs replaces our version with the conflict file.
+	ConflictTakeTheirs = "theirs"
+	// ConflictResolved means that the user merged both versions manually
+	// into our version. Only the conflict file is removed.
+	ConflictResolved = "resolved"
+)
+
+// ConflictSide describes one version of a conflicting file.
+type ConflictSide struct {
+	// Info is the current state of this version.
+	Info *StatInfo
+	// Commit is the commit that last modified this version.
+	Commit *Commit
+}
+
+// Conflict describes a conflict file that was created during sync.
+type Conflict struct {
+	// Path is the path of our version.
+	Path string
+	// ConflictPath is the path of the conflict file
+	// holding the remote's version.
+	ConflictPath string
+	// Remote is the name of the remote we synced with
+	// when the conflict file was created.
+	Remote string
+	// Ours is our version; it is nil if we removed the file since.
+	Ours *ConflictSide
+	// Theirs is the version in the conflict file.
+	Theirs *ConflictSide
+}
+
+// lastChangeCommit returns the commit where `nd` was modified last.
+func (fs *FS) lastChangeCommit(nd n.ModNode, status *n.Commit) (*n.Commit, error) {
+	hist, err := vcs.History(fs.lkr, nd, status, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range hist {
+		if change.Mask != vcs.ChangeTypeNone {
+			return change.Head, nil
+		}
+	}
+
+	// No change recorded; the node is as old as the history goes.
+	if len(hist) > 0 {
+		return hist[len(hist)-1].Head, nil
+	}
+
+	return status, nil
+}
+
+func (fs *FS) conflictSide(nd n.ModNode, status *n.Commit, hashToRef map[string][]string) (*ConflictSide, *n.Commit, error) {
+	cmt, err := fs.lastChangeCommit(nd, status)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &ConflictSide{
+		Info:   fs.nodeToStat(nd),
+		Commit: commitToExternal(cmt, hashToRef),
+	}, cmt, nil
+}
+
+func (fs *FS) conflicts(root string) ([]Conflict, error) {
+	rootNd, err := fs.lkr.LookupNode(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if rootNd.Type() == n.NodeTypeGhost {
+		return nil, ie.NoSuchFile(root)
+	}
+
+	status, err := fs.lkr.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	hashToRef, err := fs.buildCommitHashToRefTable()
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := []Conflict{}
+	err = n.Walk(fs.lkr, rootNd, false, func(child n.Node) error {
+		if child.Type() == n.NodeTypeGhost || !vcs.IsConflictPath(child.Path()) {
+			return nil
+		}
+
+		conflictNd, ok := child.(n.ModNode)
+		if !ok {
+			return ie.ErrBadNode
+		}
+
+		theirs, theirCmt, err := fs.conflictSide(conflictNd, status, hashToRef)
+		if err != nil {
+			return err
+		}
+
+		// The conflict file was created by a sync; the merge marker
+		// of that commit tells us with whom we synced.
+		remote, _ := theirCmt.MergeMarker()
+		if remote == "" {
+			remote = conflictNd.User()
+		}
+
+		cf := Conflict{
+			Path:         vcs.ConflictOrigin(child.Path()),
+			ConflictPath: child.Path(),
+			Remote:       remote,
+			Theirs:       theirs,
+		}
+
+		ourNd, err := fs.lkr.LookupModNode(cf.Path)
+		if err != nil && !ie.IsNoSuchFileError(err) {
+			return err
+		}
+
+		if ourNd != nil && ourNd.Type() != n.NodeTypeGhost {
+			cf.Ours, _, err = fs.conflictSide(ourNd, status, hashToRef)
+			if err != nil {
+				return err
+			}
+		}
+
+		conflicts = append(conflicts, cf)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].ConflictPath < conflicts[j].ConflictPath
+	})
+
+	return conflicts, nil
+}
+
+// Conflicts returns all conflict files below `root`.
+func (fs *FS) Conflicts(root string) ([]Conflict, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	return fs.conflicts(prefixSlash(root))
+}
+
+// selectConflicts returns all conflicts that are referenced by `paths`.
+// A path may point to the original file, the conflict file or a directory
+// above them. Use "/" to select all conflicts.
+func selectConflicts(conflicts []Conflict, paths []string) []Conflict {
+	isBelow := func(p, root string) bool {
+		return p == root || root == "/" || strings.HasPrefix(p, root+"/")
+	}
+
+	selected := []Conflict{}
+	for _, cf := range conflicts {
+		for _, p := range paths {
+			p = prefixSlash(p)
+			if isBelow(cf.Path, p) || isBelow(cf.ConflictPath, p) {
+				selected = append(selected, cf)
+				break
+			}
+		}
+	}
+
+	return selected
+}
+
+// takeTheirs replaces our version with the content of the conflict file.
+// The returned file still needs to be pinned.
+func (fs *FS) takeTheirs(cf Conflict) (*n.File, error) {
+	theirNd, err := fs.lkr.LookupModNode(cf.ConflictPath)
+	if err != nil {
+		return nil, err
+	}
+
+	theirFile, ok := theirNd.(*n.File)
+	if !ok {
+		return nil, fmt.Errorf("cannot take directory %s", cf.ConflictPath)
+	}
+
+	if cf.Ours != nil && cf.Ours.Info.IsDir {
+		return nil, fmt.Errorf("cannot replace directory %s with a file", cf.Path)
+	}
+
+	return c.StageChunked(
+		fs.lkr,
+		cf.Path,
+		theirFile.ContentHash(),
+		theirFile.BackendHash(),
+		theirFile.Chunks(),
+		theirFile.Size(),
+		theirFile.CachedSize(),
+		theirFile.Key(),
+		theirFile.ModTime(),
+		theirFile.IsRaw(),
+	)
+}
+
+// ResolveConflicts resolves the conflicts selected by `paths` (see
+// Conflicts()) using `strategy` and commits the result. Either all selected
+// conflicts are resolved in one commit or none of them.
+func (fs *FS) ResolveConflicts(paths []string, strategy string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.readOnly {
+		return ErrReadOnly
+	}
+
+	switch strategy {
+	case ConflictTakeOurs, ConflictTakeTheirs, ConflictResolved:
+	default:
+		return fmt.Errorf("invalid conflict resolution strategy: %s", strategy)
+	}
+
+	if len(paths) == 0 {
+		return fmt.Errorf("no paths given")
+	}
+
+	conflicts, err := fs.conflicts("/")
+	if err != nil {
+		return err
+	}
+
+	conflicts = selectConflicts(conflicts, paths)
+	if len(conflicts) == 0 {
+		return fmt.Errorf("no conflicts found")
+	}
+
+	owner, err := fs.lkr.Owner()
+	if err != nil {
+		return err
+	}
+
+	// Files are pinned only after the commit went through;
+	// a rolled back resolve should not leave pins behind.
+	takenFiles := []*n.File{}
+	err = fs.lkr.Atomic(func() (bool, error) {
+		resolved := make(map[string]bool)
+		conflictPaths := []string{}
+
+		for _, cf := range conflicts {
+			// In case of several conflict files for one path,
+			// take the one that was created last.
+			if strategy == ConflictTakeTheirs && !resolved[cf.Path] {
+				latest := cf
+				for _, other := range conflicts {
+					if other.Path == cf.Path && other.Theirs.Commit.Index > latest.Theirs.Commit.Index {
+						latest = other
+					}
+				}
+
+				file, err := fs.takeTheirs(latest)
+				if err != nil {
+					return true, e.Wrapf(err, "take theirs: %s", latest.ConflictPath)
+				}
+
+				takenFiles = append(takenFiles, file)
+				resolved[cf.Path] = true
+			}
+
+			conflictNd, err := fs.lkr.LookupModNode(cf.ConflictPath)
+			if err != nil {
+				return true, err
+			}
+
+			if _, _, err := c.Remove(fs.lkr, conflictNd, true, true); err != nil {
+				return true, e.Wrapf(err, "remove: %s", cf.ConflictPath)
+			}
+
+			conflictPaths = append(conflictPaths, cf.ConflictPath)
+		}
+
+		msg := fmt.Sprintf(
+			"resolve conflicts (%s): %s",
+			strategy,
+			strings.Join(conflictPaths, ", "),
+		)
+
+		if err := fs.lkr.MakeCommit(owner, msg); err != nil {
+			return true, err
+		}
+
+		return false, nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, file := range takenFiles {
+		if err := fs.pinner.PinNode(file, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package catfs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func withConflict(t *testing.T, fn func(fsa, fsb *FS)) {
+	bk := NewMemFsBackend()
+	withDummyFSBackend(t, bk, false, func(fsa *FS) {
+		require.Nil(t, fsa.MakeCommit("hello a"))
+		withDummyFSBackend(t, bk, false, func(fsb *FS) {
+			require.Nil(t, fsb.Stage("/sub/x", bytes.NewReader([]byte("base"))))
+			require.Nil(t, fsb.MakeCommit("add x"))
+			require.Nil(t, fsa.Sync(fsb))
+
+			require.Nil(t, fsa.Stage("/sub/x", bytes.NewReader([]byte("ours"))))
+			require.Nil(t, fsa.MakeCommit("edit on a"))
+
+			require.Nil(t, fsb.Stage("/sub/x", bytes.NewReader([]byte("theirs"))))
+			require.Nil(t, fsb.MakeCommit("edit on b"))
+
+			require.Nil(t, fsa.Sync(fsb))
+			fn(fsa, fsb)
+		})
+	})
+}
+
+func requireContent(t *testing.T, fs *FS, path, expect string) {
+	stream, err := fs.Cat(path)
+	require.Nil(t, err)
+
+	data, err := ioutil.ReadAll(stream)
+	require.Nil(t, err)
+	require.Equal(t, expect, string(data))
+}
+
+func TestConflictsList(t *testing.T) {
+	t.Parallel()
+
+	withConflict(t, func(fsa, fsb *FS) {
+		conflicts, err := fsa.Conflicts("/")
+		require.Nil(t, err)
+		require.Len(t, conflicts, 1)
+
+		cf := conflicts[0]
+		require.Equal(t, "/sub/x", cf.Path)
+		require.Equal(t, "/sub/x.conflict.0", cf.ConflictPath)
+		require.NotNil(t, cf.Ours)
+		require.Equal(t, "/sub/x", cf.Ours.Info.Path)
+		require.Equal(t, "edit on a", cf.Ours.Commit.Msg)
+		require.Equal(t, "/sub/x.conflict.0", cf.Theirs.Info.Path)
+		require.Equal(t, uint64(len("theirs")), cf.Theirs.Info.Size)
+
+		_, err = fsa.Conflicts("/other")
+		require.Error(t, err)
+
+		conflicts, err = fsb.Conflicts("/")
+		require.Nil(t, err)
+		require.Len(t, conflicts, 0)
+	})
+}
+
+func TestConflictsResolve(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		strategy string
+		expect   string
+	}{
+		{ConflictTakeOurs, "ours"},
+		{ConflictTakeTheirs, "theirs"},
+		{ConflictResolved, "ours"},
+	}
+
+	for _, tc := range tcs {
+		withConflict(t, func(fsa, fsb *FS) {
+			require.NotNil(t, fsa.ResolveConflicts([]string{"/nope"}, tc.strategy))
+			require.NotNil(t, fsa.ResolveConflicts(nil, "blah"))
+			require.NotNil(t, fsa.ResolveConflicts(nil, tc.strategy))
+
+			require.Nil(t, fsa.ResolveConflicts([]string{"/sub"}, tc.strategy))
+			requireContent(t, fsa, "/sub/x", tc.expect)
+
+			_, err := fsa.Stat("/sub/x.conflict.0")
+			require.Error(t, err)
+
+			conflicts, err := fsa.Conflicts("/")
+			require.Nil(t, err)
+			require.Len(t, conflicts, 0)
+
+			// Resolving should result in exactly one new commit:
+			haveStaged, err := fsa.HaveStagedChanges()
+			require.Nil(t, err)
+			require.False(t, haveStaged)
+
+			head, err := fsa.CommitInfo("head")
+			require.Nil(t, err)
+			require.Contains(t, head.Msg, "/sub/x.conflict.0")
+		})
+	}
+}
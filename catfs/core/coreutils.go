@@ -1,8 +1,10 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"os"
 	"path"
 	"strings"
 	"time"
@@ -17,6 +19,9 @@ import (
 var (
 	// ErrIsGhost is returned by Remove() when calling it on a ghost.
 	ErrIsGhost = errors.New("Is a ghost")
+
+	// ErrStopLog can be returned by the callback of Log() to stop early.
+	ErrStopLog = errors.New("stop log")
 )
 
 // mkdirParents takes the dirname of repoPath and makes sure all intermediate
@@ -90,8 +95,8 @@ func Mkdir(lkr *Linker, repoPath string, createParents bool) (dir *n.Directory,
 				// Nothing to do really. Return the old child.
 				dir = child.(*n.Directory)
 				return false, nil
-			case n.NodeTypeFile:
-				return true, fmt.Errorf("`%s` exists and is a file", repoPath)
+			case n.NodeTypeFile, n.NodeTypeSymlink:
+				return true, fmt.Errorf("`%s` exists and is a %s", repoPath, child.Type())
 			case n.NodeTypeGhost:
 				// Remove the ghost and continue with adding:
 				if err := parent.RemoveChild(lkr, child); err != nil {
@@ -205,10 +210,11 @@ func prepareParent(lkr *Linker, nd n.ModNode, dstPath string) (*n.Directory, err
 
 		// Oh, something is in there?
 		if child != nil {
-			if nd.Type() == n.NodeTypeFile {
+			if nd.Type() != n.NodeTypeDirectory {
 				return nil, fmt.Errorf(
-					"cannot overwrite a directory (%s) with a file (%s)",
+					"cannot overwrite a directory (%s) with a %s (%s)",
 					destNode.Path(),
+					nd.Type(),
 					child.Path(),
 				)
 			}
@@ -234,8 +240,8 @@ func prepareParent(lkr *Linker, nd n.ModNode, dstPath string) (*n.Directory, err
 		}
 
 		return destDir, nil
-	case n.NodeTypeFile:
-		log.Infof("Remove file: %v", destNode.Path())
+	case n.NodeTypeFile, n.NodeTypeSymlink:
+		log.Infof("Remove %s: %v", destNode.Type(), destNode.Path())
 		parentDir, _, err := Remove(lkr, destNode, false, false)
 		return parentDir, err
 	case n.NodeTypeGhost:
@@ -354,13 +360,14 @@ func Move(lkr *Linker, nd n.ModNode, dstPath string) error {
 	})
 }
 
-// StageFromFileNode is a convinience helper that will call Stage() with all necessary params from `f`.
+// StageFromFileNode is a convinience helper that will call StageChunked() with all necessary params from `f`.
 func StageFromFileNode(lkr *Linker, f *n.File) (*n.File, error) {
-	return Stage(
+	return StageChunked(
 		lkr,
 		f.Path(),
 		f.ContentHash(),
 		f.BackendHash(),
+		f.Chunks(),
 		f.Size(),
 		f.CachedSize(),
 		f.Key(),
@@ -383,6 +390,35 @@ func Stage(
 	key []byte,
 	modTime time.Time,
 	isRaw bool,
+) (file *n.File, err error) {
+	return StageChunked(
+		lkr,
+		repoPath,
+		contentHash,
+		backendHash,
+		nil,
+		size,
+		cachedSize,
+		key,
+		modTime,
+		isRaw,
+	)
+}
+
+// StageChunked is like Stage, but also sets the chunk list of the file.
+// `chunks` may be nil for files stored as single backend object,
+// otherwise `backendHash` should be n.ChunkListHash(chunks).
+func StageChunked(
+	lkr *Linker,
+	repoPath string,
+	contentHash,
+	backendHash h.Hash,
+	chunks []n.Chunk,
+	size uint64,
+	cachedSize int64,
+	key []byte,
+	modTime time.Time,
+	isRaw bool,
 ) (file *n.File, err error) {
 	node, lerr := lkr.LookupNode(repoPath)
 	if lerr != nil && !ie.IsNoSuchFileError(lerr) {
@@ -416,7 +452,7 @@ func Stage(
 				var ok bool
 				file, ok = node.(*n.File)
 				if !ok {
-					return true, ie.ErrBadNode
+					return true, fmt.Errorf("`%s` exists and is a %s", repoPath, node.Type())
 				}
 			}
 		}
@@ -462,6 +498,7 @@ func Stage(
 		file.SetModTime(modTime)
 		file.SetContent(lkr, contentHash)
 		file.SetBackend(lkr, backendHash)
+		file.SetChunks(chunks)
 		file.SetKey(key)
 		file.SetUser(lkr.owner)
 		file.SetIsRaw(isRaw)
@@ -482,13 +519,199 @@ func Stage(
 	return
 }
 
+// StageSymlink adds a symlink at `repoPath` pointing to `target`.
+// An existing symlink at this place gets its target updated.
+func StageSymlink(lkr *Linker, repoPath, target string, modTime time.Time) (symlink *n.Symlink, err error) {
+	node, lerr := lkr.LookupNode(repoPath)
+	if lerr != nil && !ie.IsNoSuchFileError(lerr) {
+		err = lerr
+		return
+	}
+
+	err = lkr.Atomic(func() (bool, error) {
+		if node != nil {
+			switch node.Type() {
+			case n.NodeTypeGhost:
+				if err := n.RemoveNode(lkr, node); err != nil {
+					return true, err
+				}
+			case n.NodeTypeSymlink:
+				var ok bool
+				symlink, ok = node.(*n.Symlink)
+				if !ok {
+					return true, ie.ErrBadNode
+				}
+			default:
+				return true, fmt.Errorf("`%s` exists and is a %s", repoPath, node.Type())
+			}
+		}
+
+		if symlink != nil {
+			if symlink.Target() == target {
+				log.Debugf("Symlink target was not modified. Not doing any update.")
+				return false, nil
+			}
+
+			// Remove the child before changing the hash:
+			if err := n.RemoveNode(lkr, symlink); err != nil {
+				return true, err
+			}
+
+			symlink.SetTarget(lkr, target)
+		} else {
+			parent, err := mkdirParents(lkr, repoPath)
+			if err != nil {
+				return true, err
+			}
+
+			symlink = n.NewSymlink(parent, path.Base(repoPath), target, lkr.owner, lkr.NextInode())
+		}
+
+		symlink.SetModTime(modTime)
+		symlink.SetUser(lkr.owner)
+
+		parentDir, err := n.ParentDirectory(lkr, symlink)
+		if err != nil {
+			return true, err
+		}
+
+		if parentDir == nil {
+			return true, fmt.Errorf("%s has no parent yet (BUG)", repoPath)
+		}
+
+		if err := parentDir.Add(lkr, symlink); err != nil {
+			return true, err
+		}
+
+		if err := lkr.StageNode(symlink); err != nil {
+			return true, err
+		}
+
+		return false, nil
+	})
+
+	return
+}
+
+// updateAttrs calls `fn` to modify the metadata of `nd` and makes sure
+// that the parent directory learns about the new hash of `nd` afterwards.
+func updateAttrs(lkr *Linker, nd n.ModNode, fn func()) error {
+	if nd.Type() == n.NodeTypeGhost {
+		return ErrIsGhost
+	}
+
+	return lkr.Atomic(func() (bool, error) {
+		parentDir, err := n.ParentDirectory(lkr, nd)
+		if err != nil {
+			return true, err
+		}
+
+		if parentDir != nil {
+			// Remove the child before changing the hash:
+			if err := parentDir.RemoveChild(lkr, nd); err != nil {
+				return true, err
+			}
+
+			// RemoveChild() unsets the parent, but the path
+			// is still needed to calculate the new hash.
+			if err := nd.SetParent(lkr, parentDir); err != nil {
+				return true, err
+			}
+		}
+
+		fn()
+
+		if parentDir != nil {
+			if err := parentDir.Add(lkr, nd); err != nil {
+				return true, err
+			}
+		}
+
+		if err := lkr.StageNode(nd); err != nil {
+			return true, err
+		}
+
+		return false, nil
+	})
+}
+
+// Chmod sets the permission bits of `nd` to `mode`.
+// Other bits than the permission bits are ignored.
+func Chmod(lkr *Linker, nd n.ModNode, mode os.FileMode) error {
+	if nd.HasMode() && nd.Mode() == mode&os.ModePerm {
+		// Nothing would change, do not create a new version.
+		return nil
+	}
+
+	return updateAttrs(lkr, nd, func() {
+		nd.SetMode(lkr, mode)
+	})
+}
+
+// Chown sets the numeric owner of `nd` to `uid` and `gid`.
+func Chown(lkr *Linker, nd n.ModNode, uid, gid uint32) error {
+	if oldUID, oldGID, ok := nd.Owner(); ok && oldUID == uid && oldGID == gid {
+		return nil
+	}
+
+	return updateAttrs(lkr, nd, func() {
+		nd.SetOwner(lkr, uid, gid)
+	})
+}
+
+// SetXattr sets the extended attribute `name` of `nd` to `value`.
+func SetXattr(lkr *Linker, nd n.ModNode, name string, value []byte) error {
+	if oldValue, ok := nd.Xattr(name); ok && bytes.Equal(oldValue, value) {
+		return nil
+	}
+
+	return updateAttrs(lkr, nd, func() {
+		nd.SetXattr(lkr, name, value)
+	})
+}
+
+// RemoveXattr removes the extended attribute `name` of `nd`.
+// It is not an error if `nd` has no such attribute.
+func RemoveXattr(lkr *Linker, nd n.ModNode, name string) error {
+	if _, ok := nd.Xattr(name); !ok {
+		return nil
+	}
+
+	return updateAttrs(lkr, nd, func() {
+		nd.RemoveXattr(lkr, name)
+	})
+}
+
+// CopyXattrs replaces all extended attributes of `dst` with the ones of `src`.
+func CopyXattrs(lkr *Linker, src n.Metadatable, dst n.ModNode) error {
+	if n.SameXattrs(src, dst) {
+		return nil
+	}
+
+	return updateAttrs(lkr, dst, func() {
+		for _, name := range dst.ListXattr() {
+			dst.RemoveXattr(lkr, name)
+		}
+
+		for _, name := range src.ListXattr() {
+			value, _ := src.Xattr(name)
+			dst.SetXattr(lkr, name, value)
+		}
+	})
+}
+
 // Log will call `fn` on every commit we currently have, starting
 // with the most current one (CURR, then HEAD, ...).
 // If `fn` will return an error, the iteration is being stopped.
+// ErrStopLog stops it without returning an error.
 func Log(lkr *Linker, start *n.Commit, fn func(cmt *n.Commit) error) error {
 	curr := start
 	for curr != nil {
 		if err := fn(curr); err != nil {
+			if err == ErrStopLog {
+				return nil
+			}
+
 			return err
 		}
 
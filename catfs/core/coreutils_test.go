@@ -1,6 +1,7 @@
 package core
 
 import (
+	"os"
 	"path"
 	"sort"
 	"strings"
@@ -545,6 +546,133 @@ func TestStageDirOverGhost(t *testing.T) {
 	})
 }
 
+func TestStageSymlink(t *testing.T) {
+	WithDummyLinker(t, func(lkr *Linker) {
+		symlink := MustSymlink(t, lkr, "/sub/link", "../x")
+		require.Equal(t, "/sub/link", symlink.Path())
+		require.Equal(t, "../x", symlink.Target())
+		require.Equal(t, uint64(len("../x")), symlink.Size())
+
+		oldHash := symlink.TreeHash().Clone()
+		symlink = MustSymlink(t, lkr, "/sub/link", "../y")
+		require.Equal(t, "../y", symlink.Target())
+		require.False(t, oldHash.Equal(symlink.TreeHash()))
+
+		nd, err := lkr.LookupNode("/sub/link")
+		require.Nil(t, err)
+		require.Equal(t, n.NodeTypeSymlink, nd.Type())
+		require.Equal(t, "../y", nd.(*n.Symlink).Target())
+
+		// Symlinks cannot replace other node types:
+		MustTouch(t, lkr, "/sub/file", 1)
+		_, err = StageSymlink(lkr, "/sub/file", "x", time.Now())
+		require.NotNil(t, err)
+
+		// ...and files cannot replace symlinks:
+		_, err = Stage(
+			lkr,
+			"/sub/link",
+			h.TestDummy(t, 2),
+			h.TestDummy(t, 2),
+			1,
+			-1,
+			make([]byte, 32),
+			time.Now(),
+			false,
+		)
+		require.EqualError(t, err, "`/sub/link` exists and is a symlink")
+
+		// ...but they can be moved like a file:
+		MustMove(t, lkr, symlink, "/moved")
+		nd, err = lkr.LookupNode("/moved")
+		require.Nil(t, err)
+		require.Equal(t, n.NodeTypeSymlink, nd.Type())
+	})
+}
+
+func TestChmod(t *testing.T) {
+	WithDummyLinker(t, func(lkr *Linker) {
+		MustMkdir(t, lkr, "/sub")
+		file := MustTouch(t, lkr, "/sub/script.sh", 1)
+		MustCommit(t, lkr, "touch")
+
+		haveStaged, err := lkr.HaveStagedChanges()
+		require.Nil(t, err)
+		require.False(t, haveStaged)
+
+		nd := MustChmod(t, lkr, file, 0755)
+		require.Equal(t, os.FileMode(0755), nd.Mode())
+		require.True(t, nd.ContentHash().Equal(file.ContentHash()))
+
+		// A mode change is a change that can be committed:
+		haveStaged, err = lkr.HaveStagedChanges()
+		require.Nil(t, err)
+		require.True(t, haveStaged)
+
+		// The parent needs to know about the new hash:
+		dir := MustLookupDirectory(t, lkr, "/sub")
+		child, err := dir.Child(lkr, "script.sh")
+		require.Nil(t, err)
+		require.True(t, child.TreeHash().Equal(nd.TreeHash()))
+
+		// Directories can be chmod'ed too without losing their children:
+		dirNd := MustChmod(t, lkr, dir, 0700)
+		require.Equal(t, os.FileMode(0700), dirNd.Mode())
+		require.Equal(t, "/sub", dirNd.Path())
+
+		nd, err = lkr.LookupModNode("/sub/script.sh")
+		require.Nil(t, err)
+		require.Equal(t, os.FileMode(0755), nd.Mode())
+
+		require.Nil(t, Chown(lkr, nd, 1000, 1000))
+		nd, err = lkr.LookupModNode("/sub/script.sh")
+		require.Nil(t, err)
+
+		uid, gid, ok := nd.Owner()
+		require.True(t, ok)
+		require.Equal(t, uint32(1000), uid)
+		require.Equal(t, uint32(1000), gid)
+	})
+}
+
+func TestXattr(t *testing.T) {
+	WithDummyLinker(t, func(lkr *Linker) {
+		MustMkdir(t, lkr, "/sub")
+		file := MustTouch(t, lkr, "/sub/x", 1)
+		MustCommit(t, lkr, "touch")
+
+		require.Nil(t, SetXattr(lkr, file, "user.tags", []byte("red")))
+		haveStaged, err := lkr.HaveStagedChanges()
+		require.Nil(t, err)
+		require.True(t, haveStaged)
+
+		nd, err := lkr.LookupModNode("/sub/x")
+		require.Nil(t, err)
+
+		value, ok := nd.Xattr("user.tags")
+		require.True(t, ok)
+		require.Equal(t, []byte("red"), value)
+
+		dir := MustLookupDirectory(t, lkr, "/sub")
+		child, err := dir.Child(lkr, "x")
+		require.Nil(t, err)
+		require.True(t, child.TreeHash().Equal(nd.TreeHash()))
+
+		// Copying the xattrs onto the directory should replace all of them:
+		require.Nil(t, SetXattr(lkr, dir, "user.other", []byte("1")))
+		dirNd := MustLookupDirectory(t, lkr, "/sub")
+		require.Nil(t, CopyXattrs(lkr, nd, dirNd))
+
+		dirNd = MustLookupDirectory(t, lkr, "/sub")
+		require.Equal(t, []string{"user.tags"}, dirNd.ListXattr())
+
+		require.Nil(t, RemoveXattr(lkr, nd, "user.tags"))
+		nd, err = lkr.LookupModNode("/sub/x")
+		require.Nil(t, err)
+		require.Empty(t, nd.ListXattr())
+	})
+}
+
 func TestCopy(t *testing.T) {
 	for _, tc := range moveAndCopyTestCases {
 		t.Run(tc.name, func(t *testing.T) {
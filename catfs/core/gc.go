@@ -58,6 +58,11 @@ func (gc *GarbageCollector) mark(cmt *n.Commit, recursive bool) error {
 		return nil
 	}
 
+	if _, ok := gc.markMap[cmt.TreeHash().B58String()]; ok && recursive {
+		// Shared history of several branches; this part was marked already.
+		return nil
+	}
+
 	root, err := gc.lkr.DirectoryByHash(cmt.Root())
 	if err != nil {
 		return err
@@ -190,6 +195,38 @@ func (gc *GarbageCollector) Run(allObjects bool) error {
 		if err != nil {
 			return err
 		}
+
+		// Other branches and tags might use commits that are not
+		// part of the current history (e.g. after squashing it).
+		refHeads, err := gc.lkr.RefHeads()
+		if err != nil {
+			return err
+		}
+
+		seen := make(map[string]bool)
+		for _, location := range moveMapLocations {
+			seen[location[len(location)-1]] = true
+		}
+
+		for _, refHead := range refHeads {
+			if err := gc.mark(refHead, true); err != nil {
+				return err
+			}
+
+			locations, err := gc.findAllMoveLocations(refHead)
+			if err != nil {
+				return err
+			}
+
+			// The head itself is a finished commit and might have moves too:
+			locations = append(locations, []string{"moves", refHead.TreeHash().B58String()})
+			for _, location := range locations {
+				if key := location[len(location)-1]; !seen[key] {
+					seen[key] = true
+					moveMapLocations = append(moveMapLocations, location)
+				}
+			}
+		}
 	}
 
 	for _, location := range moveMapLocations {
@@ -210,8 +247,8 @@ func (gc *GarbageCollector) Run(allObjects bool) error {
 		}
 
 		if removed > 0 {
-			log.Warningf("removed %d unreachable permanent objects.", removed)
-			log.Warningf("this might indiciate a bug in catfs somewhere.")
+			// Squashing the history leaves old commits behind on purpose.
+			log.Infof("removed %d unreachable permanent objects.", removed)
 		}
 	}
 
@@ -17,6 +17,7 @@ package core
 //
 // stats/max-inode                       => UINT64
 // refs/<REFNAME>                        => NODE_HASH
+// branches/<BRANCHNAME>                 => COMMIT_HASH
 //
 // Defined by caller:
 //
@@ -30,6 +31,9 @@ package core
 // HEAD -> Points to the latest finished commit, or nil.
 // CURR -> Points to the staging commit.
 //
+// The name of the current branch is stored as metadata/branch.
+// HEAD always points to the latest commit of the current branch.
+//
 // In git terminology, this file implements the following commands:
 //
 // - git add:    StageNode(): Create and Update Nodes.
@@ -44,9 +48,11 @@ import (
 	"fmt"
 	"path"
 	"runtime/debug"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	e "github.com/pkg/errors"
 	"github.com/sahib/brig/catfs/db"
@@ -79,8 +85,14 @@ type Linker struct {
 
 	// Cache for the linker owner.
 	owner string
+
+	// sign is used to sign new commits, if set.
+	sign SignFunc
 }
 
+// SignFunc creates a signature of `data` with the key of the linker's owner.
+type SignFunc func(data []byte) ([]byte, error)
+
 // NewLinker returns a new lkr, ready to use. It assumes the key value store
 // is working and does no check on this.
 func NewLinker(kv db.Database) *Linker {
@@ -216,9 +228,12 @@ func (lkr *Linker) FilesByContents(contents []h.Hash) (map[string]*n.File, error
 			return nil, ie.ErrBadNode
 		}
 
-		for _, content := range contents {
-			if content.Equal(file.BackendHash()) {
-				result[content.B58String()] = file
+		// Chunked files consist of several backend objects:
+		for _, backendHash := range file.BackendHashes() {
+			for _, content := range contents {
+				if content.Equal(backendHash) {
+					result[content.B58String()] = file
+				}
 			}
 		}
 	}
@@ -476,13 +491,44 @@ func (lkr *Linker) stageNodeRecursive(batch db.Batch, nd n.Node) error {
 // SetMergeMarker sets the current status to be a merge commit.
 // Note that this function only will have a result when MakeCommit() is called afterwards.
 // Otherwise, the changes will not be written to disk.
-func (lkr *Linker) SetMergeMarker(with string, remoteHead h.Hash) error {
+// `via` describes how the merge was triggered (e.g. "sync").
+func (lkr *Linker) SetMergeMarker(with, via string, remoteHead h.Hash) error {
 	status, err := lkr.Status()
 	if err != nil {
 		return err
 	}
 
 	status.SetMergeMarker(with, remoteHead)
+	status.SetMergeVia(via)
+	return lkr.saveStatus(status)
+}
+
+// SetSignFunc sets the function that is used to sign new commits.
+// If it is not set (or nil), commits stay unsigned.
+func (lkr *Linker) SetSignFunc(sign SignFunc) {
+	lkr.sign = sign
+}
+
+// Sign signs `data` with the function set by SetSignFunc.
+// If there is none, nil is returned for the signature.
+func (lkr *Linker) Sign(data []byte) ([]byte, error) {
+	if lkr.sign == nil {
+		return nil, nil
+	}
+
+	return lkr.sign(data)
+}
+
+// SetSignature sets the signature of the next commit to `sig`, made for `hash`.
+// This is used when replaying a commit of somebody else, who signed it.
+// Like SetMergeMarker, it only has an effect when MakeCommit() is called afterwards.
+func (lkr *Linker) SetSignature(hash h.Hash, sig []byte) error {
+	status, err := lkr.Status()
+	if err != nil {
+		return err
+	}
+
+	status.SetSignature(hash, sig)
 	return lkr.saveStatus(status)
 }
 
@@ -571,6 +617,16 @@ func (lkr *Linker) makeCommit(batch db.Batch, author string, message string) err
 		return err
 	}
 
+	// Commits that replay a remote commit bring their own signature.
+	if sigHash, _ := status.Signature(); sigHash == nil && lkr.sign != nil {
+		sig, err := lkr.sign(status.TreeHash())
+		if err != nil {
+			return e.Wrapf(err, "sign commit")
+		}
+
+		status.SetSignature(status.TreeHash(), sig)
+	}
+
 	statusData, err := n.MarshalNode(status)
 	if err != nil {
 		return err
@@ -586,6 +642,14 @@ func (lkr *Linker) makeCommit(batch db.Batch, author string, message string) err
 		return err
 	}
 
+	// Advance the current branch along with HEAD:
+	branch, err := lkr.CurrentBranch()
+	if err != nil {
+		return err
+	}
+
+	batch.Put([]byte(statusB58Hash), "branches", branch)
+
 	// Check if we have already tagged the initial commit.
 	if _, err := lkr.ResolveRef("init"); err != nil {
 		if !ie.IsErrNoSuchRef(err) {
@@ -725,6 +789,14 @@ func (lkr *Linker) ResolveRef(refname string) (n.Node, error) {
 		return nil, err
 	}
 
+	if len(b58Hash) == 0 {
+		// Branch names can be used like refs.
+		b58Hash, err = lkr.kv.Get("branches", refname)
+		if err != nil && err != db.ErrNoSuchKey {
+			return nil, err
+		}
+	}
+
 	if len(b58Hash) == 0 {
 		// Try to interpret the refname as b58hash directly.
 		// This path will hit when passing a commit hash directly
@@ -841,6 +913,284 @@ func (lkr *Linker) Head() (*n.Commit, error) {
 	return cmt, nil
 }
 
+/////////////////////
+// BRANCH HANDLING //
+/////////////////////
+
+// DefaultBranch is the branch that is used when no other was created yet.
+const DefaultBranch = "master"
+
+func validateBranchName(name string) error {
+	if name == "" {
+		return fmt.Errorf("empty branch name")
+	}
+
+	// Branch names need to be usable as revision, so the
+	// same restrictions apply as for other refs:
+	for _, c := range name {
+		if !unicode.IsLetter(c) && !unicode.IsNumber(c) {
+			return fmt.Errorf("invalid character in branch name: `%c`", c)
+		}
+	}
+
+	switch name {
+	case "head", "curr", "status", "init":
+		return fmt.Errorf("branch name is reserved: %s", name)
+	}
+
+	return nil
+}
+
+// CurrentBranch returns the name of the branch we are currently on.
+func (lkr *Linker) CurrentBranch() (string, error) {
+	data, err := lkr.MetadataGet("branch")
+	if err != nil && err != db.ErrNoSuchKey {
+		return "", err
+	}
+
+	if len(data) == 0 {
+		return DefaultBranch, nil
+	}
+
+	return string(data), nil
+}
+
+// BranchHead returns the latest commit of the branch called `name`.
+// If there is no such branch, ErrNoSuchRef is returned.
+func (lkr *Linker) BranchHead(name string) (*n.Commit, error) {
+	name = strings.ToLower(name)
+	b58Hash, err := lkr.kv.Get("branches", name)
+	if err != nil && err != db.ErrNoSuchKey {
+		return nil, err
+	}
+
+	if len(b58Hash) == 0 {
+		curr, err := lkr.CurrentBranch()
+		if err != nil {
+			return nil, err
+		}
+
+		// Repositories that never created a branch have no
+		// entry for the default branch. HEAD is its head then.
+		if curr != name {
+			return nil, ie.ErrNoSuchRef(name)
+		}
+
+		return lkr.Head()
+	}
+
+	hash, err := h.FromB58String(string(b58Hash))
+	if err != nil {
+		return nil, err
+	}
+
+	return lkr.CommitByHash(hash)
+}
+
+// ListBranches returns the names of all branches in lexical order.
+func (lkr *Linker) ListBranches() ([]string, error) {
+	curr, err := lkr.CurrentBranch()
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := lkr.kv.Keys("branches")
+	if err != nil {
+		return nil, err
+	}
+
+	haveCurr := false
+	branches := []string{}
+	for _, key := range keys {
+		if len(key) <= 1 {
+			continue
+		}
+
+		haveCurr = haveCurr || key[1] == curr
+		branches = append(branches, key[1])
+	}
+
+	if !haveCurr {
+		branches = append(branches, curr)
+		sort.Strings(branches)
+	}
+
+	return branches, nil
+}
+
+// CreateBranch creates a new branch called `name` that starts at `cmt`.
+// The branch is not switched to. `cmt` may not be the staging commit.
+func (lkr *Linker) CreateBranch(name string, cmt *n.Commit) error {
+	name = strings.ToLower(name)
+	if err := validateBranchName(name); err != nil {
+		return err
+	}
+
+	if _, err := lkr.BranchHead(name); err == nil {
+		return fmt.Errorf("branch exists already: %s", name)
+	} else if !ie.IsErrNoSuchRef(err) {
+		return err
+	}
+
+	status, err := lkr.Status()
+	if err != nil {
+		return err
+	}
+
+	if status.TreeHash().Equal(cmt.TreeHash()) {
+		return fmt.Errorf("cannot create a branch from uncommitted changes")
+	}
+
+	return lkr.AtomicWithBatch(func(batch db.Batch) (bool, error) {
+		batch.Put([]byte(cmt.TreeHash().B58String()), "branches", name)
+		return false, nil
+	})
+}
+
+// DeleteBranch removes the branch called `name`.
+// The commits of the branch are kept. The current branch can not be deleted.
+func (lkr *Linker) DeleteBranch(name string) error {
+	name = strings.ToLower(name)
+	curr, err := lkr.CurrentBranch()
+	if err != nil {
+		return err
+	}
+
+	if name == curr {
+		return fmt.Errorf("cannot delete the current branch: %s", name)
+	}
+
+	if _, err := lkr.BranchHead(name); err != nil {
+		return err
+	}
+
+	return lkr.AtomicWithBatch(func(batch db.Batch) (bool, error) {
+		batch.Erase("branches", name)
+		return false, nil
+	})
+}
+
+// SwitchBranch makes `name` the current branch. HEAD is set to the latest
+// commit of the branch and the staging area is reset to its state. If
+// `force` is false and there are staged changes, ErrStageNotEmpty is returned.
+// Otherwise the staged changes are lost.
+func (lkr *Linker) SwitchBranch(name string, force bool) error {
+	name = strings.ToLower(name)
+	curr, err := lkr.CurrentBranch()
+	if err != nil {
+		return err
+	}
+
+	if name == curr {
+		return nil
+	}
+
+	target, err := lkr.BranchHead(name)
+	if err != nil {
+		return err
+	}
+
+	if !force {
+		haveStaged, err := lkr.HaveStagedChanges()
+		if err != nil {
+			return err
+		}
+
+		if haveStaged {
+			return ie.ErrStageNotEmpty
+		}
+	}
+
+	head, err := lkr.Head()
+	if err != nil {
+		return err
+	}
+
+	root, err := lkr.DirectoryByHash(target.Root())
+	if err != nil {
+		return err
+	}
+
+	return lkr.AtomicWithBatch(func(batch db.Batch) (bool, error) {
+		// Make sure we can come back to the branch we leave:
+		batch.Put([]byte(head.TreeHash().B58String()), "branches", curr)
+		batch.Put([]byte(target.TreeHash().B58String()), "refs", "head")
+		batch.Put([]byte(name), "metadata", "branch")
+
+		if err := lkr.reindexCommits(batch, head, target); err != nil {
+			return true, err
+		}
+
+		if err := lkr.clearStage(batch); err != nil {
+			return true, err
+		}
+
+		status, err := n.NewEmptyCommit(lkr.NextInode(), target.Index()+1)
+		if err != nil {
+			return true, err
+		}
+
+		status.SetRoot(target.Root())
+
+		// Invalidate the cache, causing NodeByHash and ResolveNode to load the
+		// file from the database again:
+		lkr.MemIndexClear()
+		lkr.MemSetRoot(root)
+		return hintRollback(lkr.saveStatus(status))
+	})
+}
+
+// reindexCommits makes the commit index point to the history of `target`
+// instead of the one of `head`, so that CommitByIndex() works on the
+// current branch.
+func (lkr *Linker) reindexCommits(batch db.Batch, head, target *n.Commit) error {
+	for idx := target.Index() + 1; idx <= head.Index(); idx++ {
+		batch.Erase("index", strconv.FormatInt(idx, 10))
+	}
+
+	curr := target
+	for curr != nil {
+		b58Hash := curr.TreeHash().B58String()
+		idxKey := strconv.FormatInt(curr.Index(), 10)
+
+		oldHash, err := lkr.kv.Get("index", idxKey)
+		if err != nil && err != db.ErrNoSuchKey {
+			return err
+		}
+
+		if string(oldHash) == b58Hash {
+			// This is where both branches forked; the rest is shared.
+			return nil
+		}
+
+		batch.Put([]byte(b58Hash), "index", idxKey)
+
+		parent, err := curr.Parent(lkr)
+		if err != nil {
+			return err
+		}
+
+		if parent == nil {
+			break
+		}
+
+		parentCmt, ok := parent.(*n.Commit)
+		if !ok {
+			return ie.ErrBadNode
+		}
+
+		// Squashed histories have gaps; those point to the commit before.
+		parentB58 := []byte(parentCmt.TreeHash().B58String())
+		for idx := parentCmt.Index() + 1; idx < curr.Index(); idx++ {
+			batch.Put(parentB58, "index", strconv.FormatInt(idx, 10))
+		}
+
+		curr = parentCmt
+	}
+
+	return nil
+}
+
 // Root returns the current root directory of CURR.
 // It is never nil when err is nil.
 func (lkr *Linker) Root() (*n.Directory, error) {
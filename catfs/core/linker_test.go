@@ -620,3 +620,75 @@ func TestLookupNodeAt(t *testing.T) {
 		require.Nil(t, last)
 	})
 }
+
+func TestBranches(t *testing.T) {
+	WithDummyLinker(t, func(lkr *Linker) {
+		curr, err := lkr.CurrentBranch()
+		require.Nil(t, err)
+		require.Equal(t, DefaultBranch, curr)
+
+		_, c1 := MustTouchAndCommit(t, lkr, "/x", 1)
+
+		require.Nil(t, lkr.CreateBranch("feature", c1))
+		require.NotNil(t, lkr.CreateBranch("feature", c1))
+		require.NotNil(t, lkr.CreateBranch("bad^name", c1))
+		require.NotNil(t, lkr.CreateBranch("head", c1))
+
+		branches, err := lkr.ListBranches()
+		require.Nil(t, err)
+		require.Equal(t, []string{"feature", DefaultBranch}, branches)
+
+		// Commit on master; feature stays at c1.
+		_, c2 := MustTouchAndCommit(t, lkr, "/y", 2)
+		masterHead, err := lkr.BranchHead(DefaultBranch)
+		require.Nil(t, err)
+		require.Equal(t, c2.TreeHash(), masterHead.TreeHash())
+
+		featureHead, err := lkr.BranchHead("feature")
+		require.Nil(t, err)
+		require.Equal(t, c1.TreeHash(), featureHead.TreeHash())
+
+		// Staged changes prevent switching without force:
+		MustTouch(t, lkr, "/z", 3)
+		require.Equal(t, ie.ErrStageNotEmpty, lkr.SwitchBranch("feature", false))
+		require.Nil(t, lkr.SwitchBranch("feature", true))
+
+		curr, err = lkr.CurrentBranch()
+		require.Nil(t, err)
+		require.Equal(t, "feature", curr)
+
+		head, err := lkr.Head()
+		require.Nil(t, err)
+		require.Equal(t, c1.TreeHash(), head.TreeHash())
+
+		_, err = lkr.LookupNode("/y")
+		require.True(t, ie.IsNoSuchFileError(err))
+		_, err = lkr.LookupNode("/z")
+		require.True(t, ie.IsNoSuchFileError(err))
+
+		// The commit index follows the current branch:
+		_, c3 := MustTouchAndCommit(t, lkr, "/w", 4)
+		idxCmt, err := lkr.CommitByIndex(c3.Index())
+		require.Nil(t, err)
+		require.Equal(t, c3.TreeHash(), idxCmt.TreeHash())
+
+		// Branch names can be used as refs:
+		nd, err := lkr.ResolveRef(DefaultBranch)
+		require.Nil(t, err)
+		require.Equal(t, c2.TreeHash(), nd.TreeHash())
+
+		require.NotNil(t, lkr.DeleteBranch("feature"))
+		require.Nil(t, lkr.SwitchBranch(DefaultBranch, false))
+
+		idxCmt, err = lkr.CommitByIndex(c2.Index())
+		require.Nil(t, err)
+		require.Equal(t, c2.TreeHash(), idxCmt.TreeHash())
+
+		_, err = lkr.LookupNode("/y")
+		require.Nil(t, err)
+
+		require.Nil(t, lkr.DeleteBranch("feature"))
+		_, err = lkr.BranchHead("feature")
+		require.True(t, ie.IsErrNoSuchRef(err))
+	})
+}
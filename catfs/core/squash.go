@@ -0,0 +1,462 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/sahib/brig/catfs/db"
+	n "github.com/sahib/brig/catfs/nodes"
+	h "github.com/sahib/brig/util/hashlib"
+)
+
+// RefHeads returns all commits that are referenced by a tag or a branch.
+// The staging commit is not included.
+func (lkr *Linker) RefHeads() ([]*n.Commit, error) {
+	heads := []*n.Commit{}
+	seen := make(map[string]bool)
+
+	for _, bucket := range []string{"refs", "branches"} {
+		keys, err := lkr.kv.Keys(bucket)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			if len(key) <= 1 || (bucket == "refs" && key[1] == "curr") {
+				continue
+			}
+
+			b58Hash, err := lkr.kv.Get(key...)
+			if err != nil {
+				return nil, err
+			}
+
+			if seen[string(b58Hash)] {
+				continue
+			}
+
+			seen[string(b58Hash)] = true
+
+			hash, err := h.FromB58String(string(b58Hash))
+			if err != nil {
+				return nil, err
+			}
+
+			cmt, err := lkr.CommitByHash(hash)
+			if err != nil {
+				return nil, err
+			}
+
+			if cmt != nil {
+				heads = append(heads, cmt)
+			}
+		}
+	}
+
+	return heads, nil
+}
+
+// squashEndPoints returns the hashes of commits that are referenced by
+// something else than HEAD and the current branch. A squashed range
+// always ends at such a commit, so the reference stays meaningful.
+func (lkr *Linker) squashEndPoints() (map[string]bool, error) {
+	curr, err := lkr.CurrentBranch()
+	if err != nil {
+		return nil, err
+	}
+
+	endPoints := make(map[string]bool)
+	for _, bucket := range []string{"refs", "branches"} {
+		keys, err := lkr.kv.Keys(bucket)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			if len(key) <= 1 {
+				continue
+			}
+
+			switch {
+			case bucket == "refs" && (key[1] == "head" || key[1] == "curr"):
+				continue
+			case bucket == "branches" && key[1] == curr:
+				continue
+			}
+
+			b58Hash, err := lkr.kv.Get(key...)
+			if err != nil {
+				return nil, err
+			}
+
+			endPoints[string(b58Hash)] = true
+		}
+	}
+
+	return endPoints, nil
+}
+
+// squashGroup creates the commit that replaces all commits in `group`
+// (oldest first) and that follows `parent`.
+func (lkr *Linker) squashGroup(group []*n.Commit, parent *n.Commit, owner string) (*n.Commit, error) {
+	last := group[len(group)-1]
+	cmt, err := n.NewEmptyCommit(last.Inode(), last.Index())
+	if err != nil {
+		return nil, err
+	}
+
+	cmt.SetRoot(last.Root())
+	cmt.SetModTime(last.ModTime())
+	if err := cmt.SetParent(lkr, parent); err != nil {
+		return nil, err
+	}
+
+	if with, mergeHead := last.MergeMarker(); with != "" {
+		cmt.SetMergeMarker(with, mergeHead)
+		cmt.SetMergeVia(last.MergeVia())
+	}
+
+	author, message := last.Author(), last.Message()
+	if len(group) > 1 {
+		author = owner
+		message = fmt.Sprintf(
+			"squashed %d commits (%s ... %s)",
+			len(group), group[0].Message(), last.Message(),
+		)
+	}
+
+	if err := cmt.BoxCommit(author, message); err != nil {
+		return nil, err
+	}
+
+	sigHash, sig := last.Signature()
+	switch {
+	case lkr.sign != nil:
+		newSig, err := lkr.sign(cmt.TreeHash())
+		if err != nil {
+			return nil, err
+		}
+
+		cmt.SetSignature(cmt.TreeHash(), newSig)
+	case len(group) == 1 && sigHash != nil && !sigHash.Equal(last.TreeHash()):
+		// Commits that replay a patch carry the signature of the remote's
+		// commit. It does not depend on our hash, so it stays valid.
+		cmt.SetSignature(sigHash, sig)
+	}
+
+	return cmt, nil
+}
+
+// copyMoves makes the move mapping of `oldCmt` available for `newCmt`.
+func (lkr *Linker) copyMoves(batch db.Batch, oldCmt, newCmt *n.Commit) error {
+	keys, err := lkr.kv.Keys("moves", oldCmt.TreeHash().B58String())
+	if err != nil {
+		return err
+	}
+
+	newB58 := newCmt.TreeHash().B58String()
+	for _, key := range keys {
+		data, err := lkr.kv.Get(key...)
+		if err != nil {
+			return err
+		}
+
+		batch.Put(data, "moves", newB58, key[len(key)-1])
+	}
+
+	return nil
+}
+
+// squashMoves combines the move mappings of all commits in `group` into
+// the mapping of `squashed`. If a node was moved several times, the
+// mapping leads from its path before the group to its path after it.
+func (lkr *Linker) squashMoves(batch db.Batch, group []*n.Commit, squashed *n.Commit) error {
+	// The ghost that was left behind by the first move of each inode:
+	ghosts := make(map[uint64]n.Node)
+	for _, cmt := range group {
+		keys, err := lkr.kv.Keys("moves", cmt.TreeHash().B58String())
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			data, err := lkr.kv.Get(key...)
+			if err != nil {
+				return err
+			}
+
+			ghost, moveDir, err := lkr.parseMoveMappingLine(string(data))
+			if err != nil {
+				return err
+			}
+
+			// Only look at the moved node; its partner has the inverse line.
+			if moveDir != MoveDirSrcToDst || ghost == nil {
+				continue
+			}
+
+			movedHash, err := h.FromB58String(key[len(key)-1])
+			if err != nil {
+				return err
+			}
+
+			moved, err := lkr.NodeByHash(movedHash)
+			if err != nil {
+				return err
+			}
+
+			if moved == nil {
+				continue
+			}
+
+			if _, ok := ghosts[moved.Inode()]; !ok {
+				ghosts[moved.Inode()] = ghost
+			}
+		}
+	}
+
+	if len(ghosts) == 0 {
+		return nil
+	}
+
+	root, err := lkr.DirectoryByHash(squashed.Root())
+	if err != nil {
+		return err
+	}
+
+	// Moved nodes keep their inode, so we can find their latest version:
+	cmtB58 := squashed.TreeHash().B58String()
+	return n.Walk(lkr, root, true, func(child n.Node) error {
+		ghost, ok := ghosts[child.Inode()]
+		if !ok || child.Type() == n.NodeTypeGhost {
+			return nil
+		}
+
+		if ghost.Path() == child.Path() {
+			// It was moved back to where it was.
+			return nil
+		}
+
+		movedB58 := child.TreeHash().B58String()
+		ghostB58 := ghost.TreeHash().B58String()
+
+		forwardLine := []byte(fmt.Sprintf("%v hash %s", MoveDirSrcToDst, ghostB58))
+		batch.Put(forwardLine, "moves", cmtB58, movedB58)
+		batch.Put(forwardLine, "moves", "overlay", movedB58)
+
+		reverseLine := []byte(fmt.Sprintf("%v hash %s", MoveDirDstToSrc, movedB58))
+		batch.Put(reverseLine, "moves", cmtB58, ghostB58)
+		batch.Put(reverseLine, "moves", "overlay", ghostB58)
+		return nil
+	})
+}
+
+// SquashCommits rewrites the history of the current branch, so that each
+// range of consecutive commits older than `before` becomes a single commit.
+// Merge commits and the initial commit are kept as they are. Commits that
+// are tagged or the head of another branch end a range, so their state
+// stays reachable. `before` may also be the staging commit.
+//
+// All commits after the first squashed one get a new hash; refs, branches
+// and the commit index are updated. Indices of dropped commits point to the
+// commit before their range. The old commits are left to the garbage
+// collector, unless another branch still uses them.
+//
+// The number of dropped commits is returned.
+func (lkr *Linker) SquashCommits(before *n.Commit) (int, error) {
+	status, err := lkr.Status()
+	if err != nil {
+		return 0, err
+	}
+
+	head, err := lkr.Head()
+	if err != nil {
+		return 0, err
+	}
+
+	// Oldest commit first:
+	chain := []*n.Commit{}
+	err = Log(lkr, head, func(cmt *n.Commit) error {
+		chain = append([]*n.Commit{cmt}, chain...)
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	cut := -1
+	if before.TreeHash().Equal(status.TreeHash()) {
+		cut = len(chain)
+	}
+
+	for idx, cmt := range chain {
+		if cmt.TreeHash().Equal(before.TreeHash()) {
+			cut = idx
+			break
+		}
+	}
+
+	if cut < 0 {
+		return 0, fmt.Errorf("commit %s is not part of the current branch", before.TreeHash().ShortB58())
+	}
+
+	if cut <= 1 {
+		// Nothing before `before`, except the initial commit.
+		return 0, nil
+	}
+
+	endPoints, err := lkr.squashEndPoints()
+	if err != nil {
+		return 0, err
+	}
+
+	groups := [][]*n.Commit{}
+	run := []*n.Commit{}
+	for _, cmt := range chain[1:cut] {
+		if with, _ := cmt.MergeMarker(); with != "" {
+			if len(run) > 0 {
+				groups = append(groups, run)
+				run = []*n.Commit{}
+			}
+
+			groups = append(groups, []*n.Commit{cmt})
+			continue
+		}
+
+		run = append(run, cmt)
+		if endPoints[cmt.TreeHash().B58String()] {
+			groups = append(groups, run)
+			run = []*n.Commit{}
+		}
+	}
+
+	if len(run) > 0 {
+		groups = append(groups, run)
+	}
+
+	dropped := 0
+	for _, group := range groups {
+		dropped += len(group) - 1
+	}
+
+	if dropped == 0 {
+		return 0, nil
+	}
+
+	// Everything after the squashed range needs a new parent hash too:
+	for _, cmt := range chain[cut:] {
+		groups = append(groups, []*n.Commit{cmt})
+	}
+
+	owner, err := lkr.Owner()
+	if err != nil {
+		return 0, err
+	}
+
+	refHeads, err := lkr.RefHeads()
+	if err != nil {
+		return 0, err
+	}
+
+	return dropped, lkr.AtomicWithBatch(func(batch db.Batch) (bool, error) {
+		rewritten := make(map[string]string)
+		parent := chain[0]
+
+		for _, group := range groups {
+			cmt, err := lkr.squashGroup(group, parent, owner)
+			if err != nil {
+				return true, err
+			}
+
+			data, err := n.MarshalNode(cmt)
+			if err != nil {
+				return true, err
+			}
+
+			b58Hash := cmt.TreeHash().B58String()
+			batch.Put(data, "objects", b58Hash)
+			batch.Put([]byte(b58Hash), "index", strconv.FormatInt(cmt.Index(), 10))
+
+			parentB58 := parent.TreeHash().B58String()
+			for _, old := range group[:len(group)-1] {
+				batch.Put([]byte(parentB58), "index", strconv.FormatInt(old.Index(), 10))
+			}
+
+			if len(group) == 1 {
+				err = lkr.copyMoves(batch, group[0], cmt)
+			} else {
+				err = lkr.squashMoves(batch, group, cmt)
+			}
+
+			if err != nil {
+				return true, err
+			}
+
+			for _, old := range group {
+				rewritten[old.TreeHash().B58String()] = b58Hash
+				batch.Put([]byte(b58Hash), "inode", strconv.FormatUint(old.Inode(), 10))
+			}
+
+			parent = cmt
+		}
+
+		for _, bucket := range []string{"refs", "branches"} {
+			keys, err := lkr.kv.Keys(bucket)
+			if err != nil {
+				return true, err
+			}
+
+			for _, key := range keys {
+				b58Hash, err := lkr.kv.Get(key...)
+				if err != nil {
+					return true, err
+				}
+
+				if newB58, ok := rewritten[string(b58Hash)]; ok {
+					batch.Put([]byte(newB58), key...)
+				}
+			}
+		}
+
+		// Repositories that never created a branch have no entry for it yet:
+		branch, err := lkr.CurrentBranch()
+		if err != nil {
+			return true, err
+		}
+
+		batch.Put([]byte(parent.TreeHash().B58String()), "branches", branch)
+
+		// Old commits that are still used by other branches keep their moves.
+		inUse := make(map[string]bool)
+		for _, refHead := range refHeads {
+			if _, ok := rewritten[refHead.TreeHash().B58String()]; ok {
+				continue
+			}
+
+			err := Log(lkr, refHead, func(cmt *n.Commit) error {
+				b58Hash := cmt.TreeHash().B58String()
+				if inUse[b58Hash] {
+					return ErrStopLog
+				}
+
+				inUse[b58Hash] = true
+				return nil
+			})
+
+			if err != nil {
+				return true, err
+			}
+		}
+
+		for _, old := range chain[1:] {
+			if b58Hash := old.TreeHash().B58String(); !inUse[b58Hash] {
+				if err := batch.Clear("moves", b58Hash); err != nil {
+					return true, err
+				}
+			}
+		}
+
+		return hintRollback(lkr.saveStatus(status))
+	})
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"testing"
+
+	n "github.com/sahib/brig/catfs/nodes"
+	h "github.com/sahib/brig/util/hashlib"
+	"github.com/stretchr/testify/require"
+)
+
+func mustLogMessages(t *testing.T, lkr *Linker) []string {
+	head, err := lkr.Head()
+	require.Nil(t, err)
+
+	msgs := []string{}
+	require.Nil(t, Log(lkr, head, func(cmt *n.Commit) error {
+		msgs = append([]string{cmt.Message()}, msgs...)
+		return nil
+	}))
+
+	return msgs
+}
+
+func TestSquashCommits(t *testing.T) {
+	WithDummyLinker(t, func(lkr *Linker) {
+		x, _ := MustTouchAndCommit(t, lkr, "/x", 1)
+		MustTouchAndCommit(t, lkr, "/y", 2)
+		MustMove(t, lkr, x, "/z")
+		MustCommit(t, lkr, "move x")
+		_, tagged := MustTouchAndCommit(t, lkr, "/y", 3)
+		require.Nil(t, lkr.SaveRef("v1", tagged))
+		MustTouchAndCommit(t, lkr, "/y", 4)
+		_, before := MustTouchAndCommit(t, lkr, "/y", 5)
+		MustTouchAndCommit(t, lkr, "/y", 6)
+
+		oldHead, err := lkr.Head()
+		require.Nil(t, err)
+
+		dropped, err := lkr.SquashCommits(before)
+		require.Nil(t, err)
+		require.Equal(t, 3, dropped)
+
+		// init, everything up to v1, the commit after v1, before and after:
+		msgs := mustLogMessages(t, lkr)
+		require.Len(t, msgs, 5)
+		require.Equal(t, "init", msgs[0])
+		require.Contains(t, msgs[1], "squashed 4 commits")
+		require.Equal(t, "cmt 4", msgs[2])
+		require.Equal(t, "cmt 5", msgs[3])
+		require.Equal(t, "cmt 6", msgs[4])
+
+		head, err := lkr.Head()
+		require.Nil(t, err)
+		require.Equal(t, oldHead.Root(), head.Root())
+
+		// The tag follows the squashed commit, with the same content:
+		tagNd, err := lkr.ResolveRef("v1")
+		require.Nil(t, err)
+		tag, ok := tagNd.(*n.Commit)
+		require.True(t, ok)
+		require.Equal(t, tagged.Root(), tag.Root())
+		require.Contains(t, tag.Message(), "squashed 4 commits")
+
+		// The move is still visible across the squashed commit:
+		z, err := lkr.LookupModNodeAt(tag, "/z")
+		require.Nil(t, err)
+		ghost, dir, err := lkr.MoveMapping(tag, z)
+		require.Nil(t, err)
+		require.Equal(t, MoveDirSrcToDst, dir)
+		require.Equal(t, "/x", ghost.Path())
+
+		// Dropped indices lead to the commit before them:
+		init, err := lkr.CommitByIndex(0)
+		require.Nil(t, err)
+		dropped1, err := lkr.CommitByIndex(1)
+		require.Nil(t, err)
+		require.Equal(t, init.TreeHash(), dropped1.TreeHash())
+
+		// Nothing left to squash:
+		dropped, err = lkr.SquashCommits(before)
+		require.NotNil(t, err)
+		require.Equal(t, 0, dropped)
+	})
+}
+
+func TestSquashCommitsKeepsOtherBranches(t *testing.T) {
+	WithDummyLinker(t, func(lkr *Linker) {
+		MustTouchAndCommit(t, lkr, "/x", 1)
+		_, fork := MustTouchAndCommit(t, lkr, "/x", 2)
+		require.Nil(t, lkr.CreateBranch("dev", fork))
+		MustTouchAndCommit(t, lkr, "/x", 3)
+		MustTouchAndCommit(t, lkr, "/x", 4)
+
+		status, err := lkr.Status()
+		require.Nil(t, err)
+
+		dropped, err := lkr.SquashCommits(status)
+		require.Nil(t, err)
+		require.Equal(t, 2, dropped)
+		require.Len(t, mustLogMessages(t, lkr), 3)
+
+		// dev still points to the commit it was forked from:
+		devHead, err := lkr.BranchHead("dev")
+		require.Nil(t, err)
+		require.Equal(t, fork.Root(), devHead.Root())
+
+		x, err := lkr.LookupFile("/x")
+		require.Nil(t, err)
+		require.Equal(t, h.TestDummy(t, 4), x.ContentHash())
+	})
+}
+
+func TestSquashCommitsGC(t *testing.T) {
+	WithDummyLinker(t, func(lkr *Linker) {
+		for seed := byte(1); seed < 5; seed++ {
+			MustTouchAndCommit(t, lkr, "/x", seed)
+		}
+
+		status, err := lkr.Status()
+		require.Nil(t, err)
+
+		dropped, err := lkr.SquashCommits(status)
+		require.Nil(t, err)
+		require.Equal(t, 3, dropped)
+
+		collected := 0
+		gc := NewGarbageCollector(lkr, lkr.kv, func(nd n.Node) bool {
+			collected++
+			return true
+		})
+
+		require.Nil(t, gc.Run(true))
+		require.True(t, collected > 0)
+
+		// Everything that is still referenced survived:
+		head, err := lkr.Head()
+		require.Nil(t, err)
+		_, err = lkr.kv.Get("objects", head.TreeHash().B58String())
+		require.Nil(t, err)
+		_, err = lkr.kv.Get("objects", head.Root().B58String())
+		require.Nil(t, err)
+
+		x, err := lkr.LookupFile("/x")
+		require.Nil(t, err)
+		require.Equal(t, h.TestDummy(t, 4), x.ContentHash())
+	})
+}
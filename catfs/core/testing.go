@@ -140,6 +140,30 @@ func MustTouch(t *testing.T, lkr *Linker, touchPath string, seed byte) *n.File {
 	return file
 }
 
+// MustSymlink creates a symlink at `linkPath` pointing to `target` or fails `t`.
+func MustSymlink(t *testing.T, lkr *Linker, linkPath, target string) *n.Symlink {
+	symlink, err := StageSymlink(lkr, linkPath, target, time.Now())
+	if err != nil {
+		t.Fatalf("symlink: Failed to stage %s: %v", linkPath, err)
+	}
+
+	return symlink
+}
+
+// MustChmod sets the mode of `nd` to `mode` or fails `t`.
+func MustChmod(t *testing.T, lkr *Linker, nd n.ModNode, mode os.FileMode) n.ModNode {
+	if err := Chmod(lkr, nd, mode); err != nil {
+		t.Fatalf("chmod of %s failed: %v", nd.Path(), err)
+	}
+
+	newNd, err := lkr.LookupModNode(nd.Path())
+	if err != nil {
+		t.Fatalf("Failed to lookup chmod'ed node: %v", err)
+	}
+
+	return newNd
+}
+
 // MustMove moves the node `nd` to `destPath` or fails `t`.
 func MustMove(t *testing.T, lkr *Linker, nd n.ModNode, destPath string) n.ModNode {
 	if err := Move(lkr, nd, destPath); err != nil {
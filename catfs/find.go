@@ -0,0 +1,533 @@
+package catfs
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dustin/go-humanize"
+	ie "github.com/sahib/brig/catfs/errors"
+	"github.com/sahib/brig/catfs/mio/compress"
+	n "github.com/sahib/brig/catfs/nodes"
+	log "github.com/sirupsen/logrus"
+)
+
+// Query is a parsed search expression that can be passed to Find().
+type Query struct {
+	terms []*queryTerm
+}
+
+// metaMatcher checks a node only by its metadata.
+type metaMatcher func(fs *FS, nd n.Node, info *StatInfo) (bool, error)
+
+type queryTerm struct {
+	negate bool
+
+	// matchMeta is set for terms that only need the metadata of a node.
+	matchMeta metaMatcher
+
+	// matchData is set for terms that need to read the content of a file.
+	// It is only called for files that matched all other terms.
+	matchData func(fs *FS, file *n.File) (bool, error)
+
+	// needsIndex is true for terms that look at the content index.
+	needsIndex bool
+}
+
+// ParseQuery parses a search expression for Find(). The expression is a list
+// of terms separated by whitespace; a node has to match all of them. A term is
+// either a plain word that must be part of the path (case-insensitive) or has
+// the form key:value, key<value or key>value. Supported keys are:
+//
+//	name:*.png       glob on the name of the node.
+//	path:/photos/*   glob on the full path of the node.
+//	type:file        one of file, dir or symlink.
+//	size>10M         size with optional unit (<, > and : are supported).
+//	mtime>2020-01-01 modification time. Durations like 7d are counted back
+//	                 from now, i.e. mtime>7d matches nodes changed last week.
+//	owner:alice      user that modified the node last.
+//	pinned:yes       pin state of the node (explicit:yes for explicit pins).
+//	cached:no        whether all content of the node is stored locally.
+//	mime:image/*     glob on the mime type guessed from the file header.
+//	encryption:aes256gcm, compression:lz4
+//	                 hint settings that apply to the node.
+//	content:word     text files that contain all given words.
+//	                 Only available if fs.find.content_index is enabled.
+//
+// Each term can be negated with a leading "-". Values containing spaces can
+// be put in double quotes.
+func ParseQuery(query string) (*Query, error) {
+	tokens, err := splitQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Query{}
+	for _, token := range tokens {
+		term, err := parseQueryTerm(token)
+		if err != nil {
+			return nil, err
+		}
+
+		q.terms = append(q.terms, term)
+	}
+
+	return q, nil
+}
+
+func splitQuery(query string) ([]string, error) {
+	tokens := []string{}
+	curr := strings.Builder{}
+	inQuote, haveToken := false, false
+
+	for _, r := range query {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			haveToken = true
+		case unicode.IsSpace(r) && !inQuote:
+			if haveToken {
+				tokens = append(tokens, curr.String())
+				curr.Reset()
+				haveToken = false
+			}
+		default:
+			curr.WriteRune(r)
+			haveToken = true
+		}
+	}
+
+	if inQuote {
+		return nil, fmt.Errorf("unterminated quote in query")
+	}
+
+	if haveToken {
+		tokens = append(tokens, curr.String())
+	}
+
+	return tokens, nil
+}
+
+func parseQueryTerm(token string) (*queryTerm, error) {
+	term := &queryTerm{}
+	if len(token) > 1 && token[0] == '-' {
+		term.negate = true
+		token = token[1:]
+	}
+
+	idx := strings.IndexAny(token, ":<>")
+	if idx <= 0 {
+		needle := strings.ToLower(token)
+		term.matchMeta = func(fs *FS, nd n.Node, info *StatInfo) (bool, error) {
+			return strings.Contains(strings.ToLower(info.Path), needle), nil
+		}
+
+		return term, nil
+	}
+
+	key, op, value := token[:idx], token[idx], token[idx+1:]
+	if op != ':' {
+		switch key {
+		case "size", "mtime":
+		default:
+			return nil, fmt.Errorf("only »:« is allowed for »%s«", key)
+		}
+	}
+
+	var err error
+	switch key {
+	case "name", "path":
+		if _, err := path.Match(value, ""); err != nil {
+			return nil, fmt.Errorf("bad glob »%s«: %v", value, err)
+		}
+
+		term.matchMeta = func(fs *FS, nd n.Node, info *StatInfo) (bool, error) {
+			subject := info.Path
+			if key == "name" {
+				subject = path.Base(subject)
+			}
+
+			return path.Match(value, subject)
+		}
+	case "type":
+		term.matchMeta, err = parseTypeTerm(value)
+	case "size":
+		term.matchMeta, err = parseSizeTerm(op, value)
+	case "mtime":
+		term.matchMeta, err = parseModTimeTerm(op, value)
+	case "owner":
+		term.matchMeta = func(fs *FS, nd n.Node, info *StatInfo) (bool, error) {
+			return info.User == value, nil
+		}
+	case "pinned", "explicit", "cached":
+		term.matchMeta, err = parseStateTerm(key, value)
+	case "encryption", "compression":
+		term.matchMeta = func(fs *FS, nd n.Node, info *StatInfo) (bool, error) {
+			hint := fs.hintManager.Lookup(info.Path)
+			if key == "encryption" {
+				return string(hint.EncryptionAlgo) == value, nil
+			}
+
+			return string(hint.CompressionAlgo) == value, nil
+		}
+	case "mime":
+		if _, err := path.Match(value, ""); err != nil {
+			return nil, fmt.Errorf("bad glob »%s«: %v", value, err)
+		}
+
+		term.matchData = func(fs *FS, file *n.File) (bool, error) {
+			mimeType, err := fs.guessMimeType(file)
+			if err != nil {
+				return false, err
+			}
+
+			return path.Match(value, mimeType)
+		}
+	case "content":
+		needles := splitWords(value)
+		if len(needles) == 0 {
+			return nil, fmt.Errorf("content: needs at least one word")
+		}
+
+		term.needsIndex = true
+		term.matchData = func(fs *FS, file *n.File) (bool, error) {
+			words, err := fs.contentWords(file)
+			if err != nil {
+				return false, err
+			}
+
+			for _, needle := range needles {
+				if !words[needle] {
+					return false, nil
+				}
+			}
+
+			return true, nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown query key »%s«", key)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return term, nil
+}
+
+func parseTypeTerm(value string) (metaMatcher, error) {
+	var want n.NodeType
+	switch value {
+	case "file", "f":
+		want = n.NodeTypeFile
+	case "dir", "directory", "d":
+		want = n.NodeTypeDirectory
+	case "symlink", "link", "l":
+		want = n.NodeTypeSymlink
+	default:
+		return nil, fmt.Errorf("bad type »%s«: use file, dir or symlink", value)
+	}
+
+	return func(fs *FS, nd n.Node, info *StatInfo) (bool, error) {
+		return nd.Type() == want, nil
+	}, nil
+}
+
+// compareByOp checks the result of a three-way comparison against `op`.
+func compareByOp(op byte, cmp int) bool {
+	switch op {
+	case '<':
+		return cmp < 0
+	case '>':
+		return cmp > 0
+	default:
+		return cmp == 0
+	}
+}
+
+func parseSizeTerm(op byte, value string) (metaMatcher, error) {
+	size, err := humanize.ParseBytes(value)
+	if err != nil {
+		return nil, fmt.Errorf("bad size »%s«: %v", value, err)
+	}
+
+	return func(fs *FS, nd n.Node, info *StatInfo) (bool, error) {
+		cmp := 0
+		switch {
+		case info.Size < size:
+			cmp = -1
+		case info.Size > size:
+			cmp = 1
+		}
+
+		return compareByOp(op, cmp), nil
+	}, nil
+}
+
+// parseTimeValue parses either an absolute date or a duration
+// that is counted back from `now`.
+func parseTimeValue(value string, now time.Time) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	// time.ParseDuration does not know about days and weeks:
+	if len(value) > 1 {
+		unit := map[byte]time.Duration{
+			'd': 24 * time.Hour,
+			'w': 7 * 24 * time.Hour,
+		}[value[len(value)-1]]
+
+		if unit != 0 {
+			count, err := strconv.ParseFloat(value[:len(value)-1], 64)
+			if err == nil {
+				return now.Add(-time.Duration(count * float64(unit))), nil
+			}
+		}
+	}
+
+	dur, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("bad time »%s«: use a date or a duration like 7d", value)
+	}
+
+	return now.Add(-dur), nil
+}
+
+func parseModTimeTerm(op byte, value string) (metaMatcher, error) {
+	ref, err := parseTimeValue(value, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return func(fs *FS, nd n.Node, info *StatInfo) (bool, error) {
+		cmp := 0
+		switch {
+		case info.ModTime.Before(ref):
+			cmp = -1
+		case info.ModTime.After(ref):
+			cmp = 1
+		}
+
+		// mtime:<date> means "on this day" for plain dates.
+		if op == ':' && len(value) == len("2006-01-02") {
+			y1, m1, d1 := info.ModTime.Date()
+			y2, m2, d2 := ref.Date()
+			return y1 == y2 && m1 == m2 && d1 == d2, nil
+		}
+
+		return compareByOp(op, cmp), nil
+	}, nil
+}
+
+func parseYesNo(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "y":
+		return true, nil
+	case "no", "n":
+		return false, nil
+	}
+
+	return strconv.ParseBool(value)
+}
+
+func parseStateTerm(key, value string) (metaMatcher, error) {
+	want, err := parseYesNo(value)
+	if err != nil {
+		return nil, fmt.Errorf("bad value »%s« for %s: use yes or no", value, key)
+	}
+
+	return func(fs *FS, nd n.Node, info *StatInfo) (bool, error) {
+		switch key {
+		case "pinned":
+			return info.IsPinned == want, nil
+		case "explicit":
+			return info.IsExplicit == want, nil
+		}
+
+		isCached, err := fs.isNodeCached(nd)
+		if err != nil {
+			return false, err
+		}
+
+		return isCached == want, nil
+	}, nil
+}
+
+func (q *Query) needsIndex() bool {
+	for _, term := range q.terms {
+		if term.needsIndex {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (q *Query) matchMeta(fs *FS, nd n.Node, info *StatInfo) (bool, error) {
+	for _, term := range q.terms {
+		if term.matchMeta == nil {
+			continue
+		}
+
+		isMatch, err := term.matchMeta(fs, nd, info)
+		if err != nil {
+			return false, err
+		}
+
+		if isMatch == term.negate {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (q *Query) matchData(fs *FS, file *n.File) (bool, error) {
+	for _, term := range q.terms {
+		if term.matchData == nil {
+			continue
+		}
+
+		// Only files have content that can be checked.
+		isMatch := false
+		if file != nil {
+			var err error
+			if isMatch, err = term.matchData(fs, file); err != nil {
+				return false, err
+			}
+		}
+
+		if isMatch == term.negate {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+type findCandidate struct {
+	info *StatInfo
+
+	// file is a copy of the node if it is a file, otherwise nil.
+	file *n.File
+}
+
+// Find returns all nodes below `root` that match `query`.
+// The root itself is not part of the result. If `filter` is not nil,
+// only nodes for which it returns true are considered. This is checked
+// before anything else, so the content of other files is never read.
+// Unlike for Tar, the children of a filtered directory are still visited.
+func (fs *FS) Find(root string, query *Query, filter func(info *StatInfo) bool) ([]*StatInfo, error) {
+	if query.needsIndex() && !fs.cfg.Bool("find.content_index") {
+		return nil, fmt.Errorf("content queries need fs.find.content_index to be enabled")
+	}
+
+	candidates, err := fs.findCandidates(root, query, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reading content happens without the lock,
+	// since it may need to fetch data from the backend.
+	result := []*StatInfo{}
+	for _, cand := range candidates {
+		isMatch, err := query.matchData(fs, cand.file)
+		if err != nil {
+			log.Warningf("find: failed to check content of %s: %v", cand.info.Path, err)
+			continue
+		}
+
+		if isMatch {
+			result = append(result, cand.info)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		iDepth := result[i].Depth
+		jDepth := result[j].Depth
+
+		if iDepth == jDepth {
+			return result[i].Path < result[j].Path
+		}
+
+		return iDepth < jDepth
+	})
+
+	return result, nil
+}
+
+func (fs *FS) findCandidates(root string, query *Query, filter func(info *StatInfo) bool) ([]findCandidate, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	rootNd, err := fs.lkr.LookupNode(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if rootNd.Type() == n.NodeTypeGhost {
+		return nil, ie.NoSuchFile(root)
+	}
+
+	candidates := []findCandidate{}
+	err = n.Walk(fs.lkr, rootNd, false, func(child n.Node) error {
+		// Ghost nodes should not be visible to the outside.
+		if child.Type() == n.NodeTypeGhost {
+			return nil
+		}
+
+		if child.Path() == rootNd.Path() {
+			return nil
+		}
+
+		info := fs.nodeToStat(child)
+		if filter != nil && !filter(info) {
+			return nil
+		}
+
+		isMatch, err := query.matchMeta(fs, child, info)
+		if err != nil || !isMatch {
+			return err
+		}
+
+		cand := findCandidate{info: info}
+		if file, ok := child.(*n.File); ok {
+			// Copy, since the content is read beyond the lock.
+			cand.file, _ = file.Copy(file.Inode()).(*n.File)
+		}
+
+		candidates = append(candidates, cand)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return candidates, nil
+}
+
+func (fs *FS) guessMimeType(file *n.File) (string, error) {
+	stream, err := fs.catFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	defer stream.Close()
+
+	header := make([]byte, 512)
+	nread, err := io.ReadFull(stream, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	return compress.GuessMimeType(file.Path(), header[:nread]), nil
+}
@@ -0,0 +1,141 @@
+package catfs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sahib/brig/catfs/db"
+	"github.com/stretchr/testify/require"
+)
+
+func findPaths(t *testing.T, fs *FS, root, query string) []string {
+	q, err := ParseQuery(query)
+	require.NoError(t, err)
+
+	infos, err := fs.Find(root, q, nil)
+	require.NoError(t, err)
+
+	paths := []string{}
+	for _, info := range infos {
+		paths = append(paths, info.Path)
+	}
+
+	return paths
+}
+
+func TestFind(t *testing.T) {
+	t.Parallel()
+
+	withDummyFS(t, func(fs *FS) {
+		require.NoError(t, fs.Stage("/photos/a.png", bytes.NewReader([]byte("\x89PNG\r\n\x1a\n"))))
+		require.NoError(t, fs.Stage("/photos/b.jpg", bytes.NewReader(make([]byte, 4096))))
+		require.NoError(t, fs.Stage("/docs/notes.txt", bytes.NewReader([]byte("hello world"))))
+		require.NoError(t, fs.Mkdir("/empty", false))
+
+		require.Equal(t, []string{"/photos/a.png"}, findPaths(t, fs, "/", "name:*.png"))
+		require.Equal(t, []string{"/photos/a.png", "/photos/b.jpg"}, findPaths(t, fs, "/", "path:/photos/*"))
+		require.Equal(t, []string{"/photos/b.jpg"}, findPaths(t, fs, "/", "size>1K"))
+		require.Equal(t, []string{"/docs", "/empty", "/photos"}, findPaths(t, fs, "/", "type:dir"))
+		require.Equal(t, []string{"/docs", "/photos"}, findPaths(t, fs, "/", "type:dir -empty"))
+		require.Equal(t, []string{"/docs/notes.txt"}, findPaths(t, fs, "/", "NOTES"))
+		require.Equal(t, []string{"/photos/a.png"}, findPaths(t, fs, "/photos", "type:file size<1K"))
+		require.Empty(t, findPaths(t, fs, "/", "owner:bob"))
+		require.Len(t, findPaths(t, fs, "/", "type:file owner:alice mtime>1h"), 3)
+		require.Empty(t, findPaths(t, fs, "/", "type:file mtime<1h"))
+
+		require.NoError(t, fs.Pin("/docs", "curr", true))
+		require.Equal(t, []string{"/docs", "/docs/notes.txt"}, findPaths(t, fs, "/", "explicit:yes"))
+		require.Equal(t, []string{"/docs/notes.txt"}, findPaths(t, fs, "/", "mime:text/*"))
+		require.Equal(t, []string{"/photos/a.png"}, findPaths(t, fs, "/", "mime:image/*"))
+	})
+}
+
+func TestFindContent(t *testing.T) {
+	t.Parallel()
+
+	withDummyFS(t, func(fs *FS) {
+		require.NoError(t, fs.Stage("/a.txt", bytes.NewReader([]byte("Hello, World!"))))
+		require.NoError(t, fs.Stage("/b.txt", bytes.NewReader([]byte("Goodbye world"))))
+
+		q, err := ParseQuery("content:hello")
+		require.NoError(t, err)
+
+		// The index is disabled by default:
+		_, err = fs.Find("/", q, nil)
+		require.Error(t, err)
+
+		require.NoError(t, fs.cfg.SetBool("find.content_index", true))
+		require.Equal(t, []string{"/a.txt", "/b.txt"}, findPaths(t, fs, "/", "content:world"))
+		require.Equal(t, []string{"/a.txt"}, findPaths(t, fs, "/", `content:"world hello"`))
+		require.Equal(t, []string{"/b.txt"}, findPaths(t, fs, "/", "-content:hello"))
+
+		// Second time the words come from the index:
+		require.Equal(t, []string{"/a.txt"}, findPaths(t, fs, "/", "content:hello"))
+	})
+}
+
+func TestFindFilter(t *testing.T) {
+	t.Parallel()
+
+	withDummyFS(t, func(fs *FS) {
+		require.NoError(t, fs.Stage("/public/a.txt", bytes.NewReader([]byte("hello public"))))
+		require.NoError(t, fs.Stage("/private/b.txt", bytes.NewReader([]byte("hello private"))))
+		require.NoError(t, fs.cfg.SetBool("find.content_index", true))
+
+		q, err := ParseQuery("content:hello")
+		require.NoError(t, err)
+
+		infos, err := fs.Find("/", q, func(info *StatInfo) bool {
+			return strings.HasPrefix(info.Path, "/public")
+		})
+		require.NoError(t, err)
+		require.Len(t, infos, 1)
+		require.Equal(t, "/public/a.txt", infos[0].Path)
+
+		// The private file was never read and is not in the index:
+		info, err := fs.Stat("/private/b.txt")
+		require.NoError(t, err)
+
+		_, err = fs.kv.Get("words", info.ContentHash.B58String())
+		require.Equal(t, db.ErrNoSuchKey, err)
+
+		info, err = fs.Stat("/public/a.txt")
+		require.NoError(t, err)
+
+		_, err = fs.kv.Get("words", info.ContentHash.B58String())
+		require.NoError(t, err)
+	})
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, query := range []string{
+		"size>big",
+		"mtime>yesterday",
+		"pinned:maybe",
+		"type:socket",
+		"name<x",
+		"color:red",
+		`"unterminated`,
+	} {
+		_, err := ParseQuery(query)
+		require.Error(t, err, query)
+	}
+}
+
+func TestParseTimeValue(t *testing.T) {
+	now := time.Date(2020, 6, 15, 12, 0, 0, 0, time.Local)
+
+	ref, err := parseTimeValue("2d", now)
+	require.NoError(t, err)
+	require.Equal(t, now.Add(-48*time.Hour), ref)
+
+	ref, err = parseTimeValue("90m", now)
+	require.NoError(t, err)
+	require.Equal(t, now.Add(-90*time.Minute), ref)
+
+	ref, err = parseTimeValue("2020-01-01", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local), ref)
+}
@@ -2,12 +2,14 @@ package catfs
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"bytes"
 	"crypto/rand"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
 	"path"
 	"sort"
 	"strconv"
@@ -28,7 +30,8 @@ import (
 	n "github.com/sahib/brig/catfs/nodes"
 	"github.com/sahib/brig/catfs/vcs"
 	"github.com/sahib/brig/repo/hints"
-	"github.com/sahib/brig/util"
+	"github.com/sahib/brig/repo/pinrules"
+	"github.com/sahib/brig/repo/retention"
 	h "github.com/sahib/brig/util/hashlib"
 )
 
@@ -63,6 +66,42 @@ func (dhm defaultHintManager) Set(path string, hint hints.Hint) error {
 	return fmt.Errorf("no hint manager, cannot remember hints")
 }
 
+// RetentionManager is the API for looking up retention policies.
+type RetentionManager interface {
+	// Lookup should return the policy for the path, which may be inherited
+	// from a parent directory. If there is no policy, false is returned.
+	Lookup(path string) (retention.Policy, bool)
+}
+
+// dummy retention manager that keeps every version.
+type defaultRetentionManager struct{}
+
+func (drm defaultRetentionManager) Lookup(path string) (retention.Policy, bool) {
+	return retention.Policy{}, false
+}
+
+// PinRuleManager is the API for looking up per-folder repin settings.
+type PinRuleManager interface {
+	// LookupQuota should return the folder whose quota `path` counts
+	// against and the quota itself. If there is none, false is returned.
+	LookupQuota(path string) (string, uint64, bool)
+
+	// LookupPriority should return the priority of `path`,
+	// which may be inherited from a parent directory.
+	LookupPriority(path string) pinrules.Priority
+}
+
+// dummy pin rule manager without any folder quotas.
+type defaultPinRuleManager struct{}
+
+func (dpm defaultPinRuleManager) LookupQuota(path string) (string, uint64, bool) {
+	return "", 0, false
+}
+
+func (dpm defaultPinRuleManager) LookupPriority(path string) pinrules.Priority {
+	return pinrules.PriorityNormal
+}
+
 // FS (short for Filesystem) is the central API entry for everything related to
 // paths.  It exposes a POSIX-like interface where path are mapped to the
 // actual underlying hashes and the associated metadata.
@@ -108,6 +147,12 @@ type FS struct {
 	// interface to load stream hints
 	hintManager HintManager
 
+	// interface to load retention policies
+	retentionManager RetentionManager
+
+	// interface to load per-folder quotas and priorities
+	pinRuleManager PinRuleManager
+
 	// cache for storing pages written to catfs.Handle
 	// (may be nil if not used, e.g. for tests)
 	pageCache pagecache.Cache
@@ -117,6 +162,13 @@ type FS struct {
 // and a modifying operation was called on it.
 var ErrReadOnly = errors.New("fs is read only")
 
+// ErrNoSuchXattr is returned when an extended attribute does not exist.
+var ErrNoSuchXattr = errors.New("no such extended attribute")
+
+// ErrBadXattrName is returned for extended attributes outside of the user.*
+// namespace or inside the reserved user.brig.* namespace.
+var ErrBadXattrName = errors.New("only user.* xattrs (except user.brig.*) can be stored")
+
 // StatInfo describes the metadata of a single node.
 // The concept is comparable to the POSIX stat() call.
 type StatInfo struct {
@@ -147,6 +199,10 @@ type StatInfo struct {
 
 	// IsDir tells you if this node is a dir
 	IsDir bool
+	// IsSymlink tells you if this node is a symbolic link
+	IsSymlink bool
+	// Target is the path a symlink points to (empty for other nodes)
+	Target string
 	// IsPinned tells you if this node is pinned (either implicit or explicit)
 	IsPinned bool
 	// IsExplicit is true when the user pinned this node on purpose
@@ -156,6 +212,16 @@ type StatInfo struct {
 	// was encoded by brig or can be consumed from ipfs directly.
 	IsRaw bool
 
+	// Mode contains the permission bits of the node.
+	// If no mode was set explicitly, a default for the node type is used.
+	Mode os.FileMode
+	// HasOwner is true when Uid and Gid were recorded for this node.
+	HasOwner bool
+	// Uid is the numeric user id of the owner (if HasOwner)
+	Uid uint32
+	// Gid is the numeric group id of the owner (if HasOwner)
+	Gid uint32
+
 	// Key is the encryption key for the file.
 	Key []byte
 }
@@ -206,6 +272,23 @@ type Commit struct {
 	Date time.Time
 	// Index is the index of the commit:
 	Index int64
+	// SignedHash is the hash that Signature was made for.
+	// Both are nil if the commit is not signed.
+	SignedHash h.Hash
+	// Signature is the signature made by the committer.
+	Signature []byte
+}
+
+// SignatureStatus checks the signature of the commit with `verify`
+// and returns either "good", "bad" or "unsigned". Like vcs.VerifyCommit,
+// the signature has to be made for the hash of the commit itself.
+func (cmt *Commit) SignatureStatus(verify func(data, sig []byte) error) string {
+	status := vcs.CheckSignature(cmt.SignedHash, cmt.Signature, verify)
+	if status == vcs.SignatureGood && !cmt.SignedHash.Equal(cmt.Hash) {
+		status = vcs.SignatureBad
+	}
+
+	return status.String()
 }
 
 // Change describes a single change to a node between two versions
@@ -255,6 +338,8 @@ func (fs *FS) nodeToStat(nd n.Node) *StatInfo {
 
 	var isDir bool
 	var isRaw bool
+	var isSymlink bool
+	var target string
 	var key []byte
 
 	switch nd.Type() {
@@ -268,6 +353,13 @@ func (fs *FS) nodeToStat(nd n.Node) *StatInfo {
 		isRaw = file.IsRaw()
 	case n.NodeTypeDirectory:
 		isDir = true
+	case n.NodeTypeSymlink:
+		symlink, ok := nd.(*n.Symlink)
+		if ok {
+			target = symlink.Target()
+		}
+
+		isSymlink = true
 	case n.NodeTypeGhost:
 		ghost, ok := nd.(*n.Ghost)
 		if ok {
@@ -275,11 +367,15 @@ func (fs *FS) nodeToStat(nd n.Node) *StatInfo {
 		}
 	}
 
+	uid, gid, hasOwner := nd.Owner()
+
 	return &StatInfo{
 		Path:        nd.Path(),
 		User:        nd.User(),
 		ModTime:     nd.ModTime(),
 		IsDir:       isDir,
+		IsSymlink:   isSymlink,
+		Target:      target,
 		Inode:       nd.Inode(),
 		Size:        nd.Size(),
 		CachedSize:  nd.CachedSize(),
@@ -291,6 +387,10 @@ func (fs *FS) nodeToStat(nd n.Node) *StatInfo {
 		BackendHash: nd.BackendHash().Clone(),
 		TreeHash:    nd.TreeHash().Clone(),
 		Key:         key,
+		Mode:        nd.Mode(),
+		HasOwner:    hasOwner,
+		Uid:         uid,
+		Gid:         gid,
 	}
 }
 
@@ -327,8 +427,10 @@ func (fs *FS) handleGcEvent(nd n.Node) bool {
 
 	// This node will not be reachable anymore by brig.
 	// Make sure it is also unpinned to save space.
-	if err := fs.pinner.Unpin(file.Inode(), file.BackendHash(), true); err != nil {
-		log.Warningf("unpinning attempt failed: %v", err)
+	for _, hash := range file.BackendHashes() {
+		if err := fs.pinner.Unpin(file.Inode(), hash, true); err != nil {
+			log.Warningf("unpinning attempt failed: %v", err)
+		}
 	}
 
 	// Still return true, no need to stop the GC
@@ -405,6 +507,8 @@ func NewFilesystem(
 		repinControl:      make(chan string, 1),
 		pinner:            pinCache,
 		hintManager:       hintManager,
+		retentionManager:  defaultRetentionManager{},
+		pinRuleManager:    defaultPinRuleManager{},
 		pageCache:         pageCache,
 	}
 
@@ -532,7 +636,9 @@ func (fs *FS) Export(w io.Writer) error {
 }
 
 // Import will read a previously FS dump from `r`.
-func (fs *FS) Import(r io.Reader) error {
+// Only SyncOptVerifySignatures is respected from `options`. If given,
+// every commit of the dump has to be signed for its own hash.
+func (fs *FS) Import(r io.Reader, options ...SyncOption) error {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
 
@@ -542,7 +648,29 @@ func (fs *FS) Import(r io.Reader) error {
 
 	// disk (probably) changed, delete memcache:
 	fs.lkr.MemIndexClear()
-	return nil
+
+	syncCfg := &vcs.SyncOptions{
+		RequireSignatures: fs.cfg.Bool("sync.require_signatures"),
+	}
+
+	for _, option := range options {
+		option(syncCfg)
+	}
+
+	if syncCfg.VerifySignature == nil {
+		return nil
+	}
+
+	head, err := fs.lkr.Head()
+	if err != nil {
+		if ie.IsErrNoSuchRef(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	return vcs.VerifyHistory(fs.lkr, head, syncCfg.VerifySignature, syncCfg.RequireSignatures)
 }
 
 /////////////////////
@@ -602,6 +730,161 @@ func (fs *FS) Mkdir(dir string, createParents bool) error {
 	return err
 }
 
+// Symlink creates a symbolic link at `linkPath` pointing to `target`.
+// The target is not resolved and does not need to exist.
+// An existing symlink at `linkPath` is changed to point to `target`.
+func (fs *FS) Symlink(linkPath, target string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.readOnly {
+		return ErrReadOnly
+	}
+
+	if target == "" {
+		return fmt.Errorf("empty symlink target")
+	}
+
+	_, err := c.StageSymlink(fs.lkr, prefixSlash(linkPath), target, time.Now())
+	return err
+}
+
+// Chmod sets the permission bits of the node at `path` to `mode`.
+func (fs *FS) Chmod(path string, mode os.FileMode) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.readOnly {
+		return ErrReadOnly
+	}
+
+	nd, err := lookupFileOrDir(fs.lkr, prefixSlash(path))
+	if err != nil {
+		return err
+	}
+
+	return c.Chmod(fs.lkr, nd, mode)
+}
+
+// Chown sets the numeric owner of the node at `path`.
+func (fs *FS) Chown(path string, uid, gid uint32) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.readOnly {
+		return ErrReadOnly
+	}
+
+	nd, err := lookupFileOrDir(fs.lkr, prefixSlash(path))
+	if err != nil {
+		return err
+	}
+
+	return c.Chown(fs.lkr, nd, uid, gid)
+}
+
+func checkXattrName(name string) error {
+	if !strings.HasPrefix(name, "user.") || strings.HasPrefix(name, "user.brig.") {
+		return ErrBadXattrName
+	}
+
+	return nil
+}
+
+// GetXattr returns the value of the extended attribute `name` of `path`.
+func (fs *FS) GetXattr(path, name string) ([]byte, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	nd, err := lookupFileOrDir(fs.lkr, prefixSlash(path))
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := nd.Xattr(name)
+	if !ok {
+		return nil, ErrNoSuchXattr
+	}
+
+	return value, nil
+}
+
+// ListXattr returns the names of all extended attributes of `path`.
+func (fs *FS) ListXattr(path string) ([]string, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	nd, err := lookupFileOrDir(fs.lkr, prefixSlash(path))
+	if err != nil {
+		return nil, err
+	}
+
+	return nd.ListXattr(), nil
+}
+
+// SetXattr sets the extended attribute `name` of `path` to `value`.
+// Only names in the user.* namespace are allowed.
+func (fs *FS) SetXattr(path, name string, value []byte) error {
+	if err := checkXattrName(name); err != nil {
+		return err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.readOnly {
+		return ErrReadOnly
+	}
+
+	nd, err := lookupFileOrDir(fs.lkr, prefixSlash(path))
+	if err != nil {
+		return err
+	}
+
+	return c.SetXattr(fs.lkr, nd, name, value)
+}
+
+// RemoveXattr removes the extended attribute `name` of `path`.
+func (fs *FS) RemoveXattr(path, name string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.readOnly {
+		return ErrReadOnly
+	}
+
+	nd, err := lookupFileOrDir(fs.lkr, prefixSlash(path))
+	if err != nil {
+		return err
+	}
+
+	if _, ok := nd.Xattr(name); !ok {
+		return ErrNoSuchXattr
+	}
+
+	return c.RemoveXattr(fs.lkr, nd, name)
+}
+
+// StageAttrs takes over the permission bits of a local file described by
+// `info` to the node at `path`. If the "preserve_owner" option is set,
+// the numeric owner is taken over as well.
+func (fs *FS) StageAttrs(path string, info os.FileInfo) error {
+	if err := fs.Chmod(path, info.Mode()); err != nil {
+		return err
+	}
+
+	if !fs.cfg.Bool("preserve_owner") {
+		return nil
+	}
+
+	uid, gid, ok := fileOwner(info)
+	if !ok {
+		return nil
+	}
+
+	return fs.Chown(path, uid, gid)
+}
+
 // Remove removes the file or directory at `path`.
 func (fs *FS) Remove(path string) error {
 	fs.mu.Lock()
@@ -621,12 +904,42 @@ func (fs *FS) Remove(path string) error {
 	return err
 }
 
+// lookupNodeAt finds the node at `path` in the commit referenced by `rev`.
+// An empty `rev` means the current state of the filesystem.
+// NOTE: fs.mu needs to be locked.
+func (fs *FS) lookupNodeAt(rev, path string) (n.Node, error) {
+	if rev == "" {
+		return fs.lkr.LookupNode(path)
+	}
+
+	cmt, err := parseRev(fs.lkr, rev)
+	if err != nil {
+		return nil, e.Wrap(err, "parse ref")
+	}
+
+	nd, err := fs.lkr.LookupNodeAt(cmt, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if nd == nil {
+		return nil, ie.NoSuchFile(path)
+	}
+
+	return nd, nil
+}
+
 // Stat delivers detailed information about the node at `path`.
 func (fs *FS) Stat(path string) (*StatInfo, error) {
+	return fs.StatAt("", path)
+}
+
+// StatAt is like Stat, but looks at the state of `rev`.
+func (fs *FS) StatAt(rev, path string) (*StatInfo, error) {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
 
-	nd, err := fs.lkr.LookupNode(path)
+	nd, err := fs.lookupNodeAt(rev, path)
 	if err != nil {
 		return nil, err
 	}
@@ -699,6 +1012,11 @@ func (fs *FS) Filter(root, query string) ([]*StatInfo, error) {
 // Nodes deeper than maxDepth will not be shown. If maxDepth is a
 // negative number, all nodes will be shown.
 func (fs *FS) List(root string, maxDepth int) ([]*StatInfo, error) {
+	return fs.ListAt("", root, maxDepth)
+}
+
+// ListAt is like List, but looks at the state of `rev`.
+func (fs *FS) ListAt(rev, root string, maxDepth int) ([]*StatInfo, error) {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
 
@@ -707,7 +1025,7 @@ func (fs *FS) List(root string, maxDepth int) ([]*StatInfo, error) {
 	//
 	// Fix whenever it proves to be a problem.
 	// I don't want to engineer something now until I know what's needed.
-	rootNd, err := fs.lkr.LookupNode(root)
+	rootNd, err := fs.lookupNodeAt(rev, root)
 	if err != nil {
 		return nil, err
 	}
@@ -769,24 +1087,29 @@ func (fs *FS) List(root string, maxDepth int) ([]*StatInfo, error) {
 
 // preCache makes the backend fetch the data already from the network,
 // even though it might not be needed yet.
-func (fs *FS) preCache(hash h.Hash) error {
-	stream, err := fs.bk.Cat(hash)
-	if err != nil {
-		return err
+func (fs *FS) preCache(hashes []h.Hash) error {
+	for _, hash := range hashes {
+		stream, err := fs.bk.Cat(hash)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(ioutil.Discard, stream); err != nil {
+			return err
+		}
 	}
 
-	_, err = io.Copy(ioutil.Discard, stream)
-	return err
+	return nil
 }
 
-func (fs *FS) preCacheInBackground(hash h.Hash) {
+func (fs *FS) preCacheInBackground(hashes []h.Hash) {
 	if !fs.cfg.Bool("pre_cache.enabled") {
 		return
 	}
 
 	go func() {
-		if err := fs.preCache(hash); err != nil {
-			log.Debugf("failed to pre-cache `%s`: %v", hash, err)
+		if err := fs.preCache(hashes); err != nil {
+			log.Debugf("failed to pre-cache `%v`: %v", hashes, err)
 		}
 	}()
 }
@@ -829,8 +1152,8 @@ func (fs *FS) doPin(path, rev string, op func(nd n.Node, explicit bool) error, e
 	}
 
 	// Make sure the data is available (if requested):
-	if nd.Type() == n.NodeTypeFile {
-		fs.preCacheInBackground(nd.BackendHash())
+	if file, ok := nd.(*n.File); ok {
+		fs.preCacheInBackground(file.BackendHashes())
 	}
 
 	return nil
@@ -977,6 +1300,8 @@ func (fs *FS) preStageKeyGen(path string) ([]byte, error) {
 		switch oldNode.Type() {
 		case n.NodeTypeDirectory:
 			return nil, fmt.Errorf("Cannot stage over directory: %v", path)
+		case n.NodeTypeSymlink:
+			return nil, fmt.Errorf("`%s` exists and is a %s", path, oldNode.Type())
 		case n.NodeTypeGhost:
 			// Act like there was no such node:
 			err = ie.NoSuchFile(path)
@@ -1033,52 +1358,27 @@ func (fs *FS) stageWithKey(path string, r io.Reader, key []byte) error {
 	// NOTE: fs.mu is not locked here since I/O can be done in parallel.
 	//       If you need locking, you can do it at the bottom of this method.
 
-	// Branch off a part of the stream and pipe it through
-	// a hash writer to compute the hash while reading the stream:
-	hashWriter := h.NewHashWriter()
-	hashReader := io.TeeReader(r, hashWriter)
-
-	// Do the same with the size.
-	// This actually measures the size of the stream and is
-	// therefore guaranteed to find out the actual stream size.
-	sizeAcc := &util.SizeAccumulator{}
-	sizeReader := io.TeeReader(hashReader, sizeAcc)
-
-	hint := fs.hintManager.Lookup(path)
-	stream, isRaw, err := mio.NewInStream(sizeReader, path, key, hint)
-	if err != nil {
-		return err
-	}
-
-	backendHash, err := fs.bk.Add(stream)
+	info, err := fs.addContent(path, r, key)
 	if err != nil {
 		return err
 	}
 
-	// The stream was consumed, we now know those attrs:
-	size := sizeAcc.Size()
-	contentHash := hashWriter.Finalize()
-
 	// Lock it again for the metadata staging:
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
 
-	cachedSize, err := fs.bk.CachedSize(backendHash)
-	if err != nil {
-		return err
-	}
-
 	// Remember the metadata:
-	newFile, err := c.Stage(
+	newFile, err := c.StageChunked(
 		fs.lkr,
 		path,
-		contentHash,
-		backendHash,
-		size,
-		cachedSize,
+		info.contentHash,
+		info.backendHash,
+		info.chunks,
+		info.size,
+		info.cachedSize,
 		key,
 		time.Now(),
-		isRaw,
+		info.isRaw,
 	)
 
 	if err != nil {
@@ -1096,13 +1396,50 @@ type tarEntry struct {
 	path   string
 	size   int64
 	stream mio.Stream
+
+	// target is only set for symlinks, which have no stream.
+	target string
+
+	// isDir is set for directories, which have no stream either.
+	isDir bool
+
+	mode     os.FileMode
+	modTime  time.Time
+	uid, gid uint32
+	hasOwner bool
+
+	// xattrs are stored as PAX records, like GNU tar does.
+	xattrs map[string]string
 }
 
-func (fs *FS) getTarableEntries(root string, filter func(node *StatInfo) bool) ([]tarEntry, string, error) {
+func newTarEntry(nd n.Node) tarEntry {
+	uid, gid, hasOwner := nd.Owner()
+	entry := tarEntry{
+		path:     nd.Path(),
+		mode:     nd.Mode(),
+		modTime:  nd.ModTime(),
+		uid:      uid,
+		gid:      gid,
+		hasOwner: hasOwner,
+	}
+
+	for _, name := range nd.ListXattr() {
+		if entry.xattrs == nil {
+			entry.xattrs = make(map[string]string)
+		}
+
+		value, _ := nd.Xattr(name)
+		entry.xattrs["SCHILY.xattr."+name] = string(value)
+	}
+
+	return entry
+}
+
+func (fs *FS) getTarableEntries(rev, root string, filter func(node *StatInfo) bool) ([]tarEntry, string, error) {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
 
-	rootNd, err := fs.lkr.LookupNode(root)
+	rootNd, err := fs.lookupNodeAt(rev, root)
 	if err != nil {
 		return nil, "", err
 	}
@@ -1116,6 +1453,25 @@ func (fs *FS) getTarableEntries(root string, filter func(node *StatInfo) bool) (
 			}
 		}
 
+		if symlink, ok := child.(*n.Symlink); ok {
+			entry := newTarEntry(child)
+			entry.target = symlink.Target()
+			entries = append(entries, entry)
+			return nil
+		}
+
+		// Directories are implicitly created by their children.
+		// Only add them when they have a mode worth preserving.
+		if dir, ok := child.(*n.Directory); ok {
+			if dir.HasMode() && dir.Path() != rootNd.Path() {
+				entry := newTarEntry(child)
+				entry.isDir = true
+				entries = append(entries, entry)
+			}
+
+			return nil
+		}
+
 		if child.Type() != n.NodeTypeFile {
 			return nil
 		}
@@ -1125,21 +1481,15 @@ func (fs *FS) getTarableEntries(root string, filter func(node *StatInfo) bool) (
 			return ie.ErrBadNode
 		}
 
-		stream, err := fs.catHash(
-			file.BackendHash(),
-			file.Key(),
-			file.Size(),
-			file.IsRaw(),
-		)
+		stream, err := fs.catFile(file)
 		if err != nil {
 			return e.Wrapf(err, "failed to open stream for %s", file.Path())
 		}
 
-		entries = append(entries, tarEntry{
-			path:   child.Path(),
-			size:   int64(child.Size()),
-			stream: stream,
-		})
+		entry := newTarEntry(child)
+		entry.size = int64(child.Size())
+		entry.stream = stream
+		entries = append(entries, entry)
 		return nil
 	})
 
@@ -1159,10 +1509,15 @@ func (fs *FS) getTarableEntries(root string, filter func(node *StatInfo) bool) (
 // Tar produces a tar archive from the file or directory at `root` and writes
 // the output to `w`. If you want compression, supply a gzip writer.
 func (fs *FS) Tar(root string, w io.Writer, filter func(node *StatInfo) bool) error {
+	return fs.TarAt("", root, w, filter)
+}
+
+// TarAt is like Tar, but archives the state of `rev`.
+func (fs *FS) TarAt(rev, root string, w io.Writer, filter func(node *StatInfo) bool) error {
 	// getTarableEntries is locking fs.mu while it is running.
 	// the rest of the code in this method should NOT use any nodes
 	// or anything that is open to race conditions!
-	entries, prefixPath, err := fs.getTarableEntries(root, filter)
+	entries, prefixPath, err := fs.getTarableEntries(rev, root, filter)
 	if err != nil {
 		return err
 	}
@@ -1175,6 +1530,10 @@ func (fs *FS) Tar(root string, w io.Writer, filter func(node *StatInfo) bool) er
 	cleanup := func(idx int) {
 		for ; idx < len(entries); idx++ {
 			entry := entries[idx]
+			if entry.stream == nil {
+				continue
+			}
+
 			if err := entry.stream.Close(); err != nil {
 				log.Debugf("could not close stream: %v (file descriptor leak?)", entry.path)
 			}
@@ -1185,9 +1544,39 @@ func (fs *FS) Tar(root string, w io.Writer, filter func(node *StatInfo) bool) er
 
 	for idx, entry := range entries {
 		hdr := &tar.Header{
-			Name: entry.path[len(prefixPath):],
-			Mode: 0600,
-			Size: entry.size,
+			Name:    entry.path[len(prefixPath):],
+			Mode:    int64(entry.mode),
+			Size:    entry.size,
+			ModTime: entry.modTime,
+		}
+
+		if entry.hasOwner {
+			hdr.Uid = int(entry.uid)
+			hdr.Gid = int(entry.gid)
+		}
+
+		if entry.xattrs != nil {
+			hdr.PAXRecords = entry.xattrs
+		}
+
+		if entry.stream == nil {
+			// Symlinks and directories only consist of the header.
+			if entry.isDir {
+				hdr.Typeflag = tar.TypeDir
+				hdr.Name += "/"
+			} else {
+				hdr.Typeflag = tar.TypeSymlink
+				hdr.Linkname = entry.target
+			}
+
+			hdr.Size = 0
+
+			if err := tw.WriteHeader(hdr); err != nil {
+				cleanup(idx + 1)
+				return err
+			}
+
+			continue
 		}
 
 		if err := tw.WriteHeader(hdr); err != nil {
@@ -1209,32 +1598,124 @@ func (fs *FS) Tar(root string, w io.Writer, filter func(node *StatInfo) bool) er
 	return tw.Close()
 }
 
+// Zip is like Tar, but produces a zip archive. Owners and
+// extended attributes are not included, since zip can't store them.
+func (fs *FS) Zip(root string, w io.Writer, filter func(node *StatInfo) bool) error {
+	return fs.ZipAt("", root, w, filter)
+}
+
+// ZipAt is like Zip, but archives the state of `rev`.
+func (fs *FS) ZipAt(rev, root string, w io.Writer, filter func(node *StatInfo) bool) error {
+	// See TarAt() on why no nodes may be used beyond this call.
+	entries, prefixPath, err := fs.getTarableEntries(rev, root, filter)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+
+	cleanup := func(idx int) {
+		for ; idx < len(entries); idx++ {
+			entry := entries[idx]
+			if entry.stream == nil {
+				continue
+			}
+
+			if err := entry.stream.Close(); err != nil {
+				log.Debugf("could not close stream: %v (file descriptor leak?)", entry.path)
+			}
+		}
+
+		zw.Close()
+	}
+
+	for idx, entry := range entries {
+		hdr := &zip.FileHeader{
+			// zip does not like absolute paths:
+			Name:     strings.TrimPrefix(entry.path[len(prefixPath):], "/"),
+			Modified: entry.modTime,
+			Method:   zip.Deflate,
+		}
+
+		if entry.stream == nil {
+			// Directories are marked by a trailing slash,
+			// symlinks store their target as content.
+			hdr.Method = zip.Store
+			if entry.isDir {
+				hdr.Name += "/"
+				hdr.SetMode(entry.mode | os.ModeDir)
+			} else {
+				hdr.SetMode(entry.mode | os.ModeSymlink)
+			}
+
+			zf, err := zw.CreateHeader(hdr)
+			if err != nil {
+				cleanup(idx + 1)
+				return err
+			}
+
+			if !entry.isDir {
+				if _, err := zf.Write([]byte(entry.target)); err != nil {
+					cleanup(idx + 1)
+					return err
+				}
+			}
+
+			continue
+		}
+
+		hdr.SetMode(entry.mode)
+		zf, err := zw.CreateHeader(hdr)
+		if err != nil {
+			cleanup(idx)
+			return err
+		}
+
+		if _, err := io.Copy(zf, entry.stream); err != nil {
+			cleanup(idx)
+			return err
+		}
+
+		if err := entry.stream.Close(); err != nil {
+			cleanup(idx + 1)
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
 // Cat will open a file read-only and expose it's underlying data as stream.
 // If no such path is known or it was deleted, nil is returned as stream.
 func (fs *FS) Cat(path string) (mio.Stream, error) {
+	return fs.CatAt("", path)
+}
+
+// CatAt is like Cat, but reads the version of the file at `rev`.
+func (fs *FS) CatAt(rev, path string) (mio.Stream, error) {
 	fs.mu.Lock()
 
-	file, err := fs.lkr.LookupFile(path)
-	if err == ie.ErrBadNode {
+	nd, err := fs.lookupNodeAt(rev, path)
+	if err != nil {
 		fs.mu.Unlock()
-		return nil, ie.NoSuchFile(path)
+		return nil, err
 	}
 
-	if err != nil {
+	file, ok := nd.(*n.File)
+	if !ok {
 		fs.mu.Unlock()
-		return nil, err
+		return nil, ie.NoSuchFile(path)
 	}
 
 	// Copy all attributes, since accessing them beyond the lock might be racy.
-	size := file.Size()
-	backendHash := file.BackendHash().Clone()
-	key := make([]byte, len(file.Key()))
-	isRaw := file.IsRaw()
-	copy(key, file.Key())
-
+	file, ok = file.Copy(file.Inode()).(*n.File)
 	fs.mu.Unlock()
 
-	return fs.catHash(backendHash, key, size, isRaw)
+	if !ok {
+		return nil, ie.ErrBadNode
+	}
+
+	return fs.catFile(file)
 }
 
 // NOTE: This method can be called without locking fs.mu!
@@ -1278,6 +1759,16 @@ func (fs *FS) Open(path string) (*Handle, error) {
 // VCS OPERATIONS //
 ////////////////////
 
+// SetSignFunc sets the function that signs new commits of this filesystem.
+// The signature is stored in the commit and can be checked by others with
+// SyncOptVerifySignatures. Without it, commits stay unsigned.
+func (fs *FS) SetSignFunc(sign func(data []byte) ([]byte, error)) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.lkr.SetSignFunc(sign)
+}
+
 // MakeCommit bundles all staged changes into one commit described by `msg`.
 // If no changes were made since the last call to MakeCommit() ErrNoConflict
 // is returned.
@@ -1414,12 +1905,15 @@ func commitToExternal(cmt *n.Commit, hashToRef map[string][]string) *Commit {
 		tags = hashToRef[cmt.TreeHash().B58String()]
 	}
 
+	signedHash, sig := cmt.Signature()
 	return &Commit{
-		Hash:  cmt.TreeHash().Clone(),
-		Msg:   cmt.Message(),
-		Tags:  tags,
-		Date:  cmt.ModTime(),
-		Index: cmt.Index(),
+		Hash:       cmt.TreeHash().Clone(),
+		Msg:        cmt.Message(),
+		Tags:       tags,
+		Date:       cmt.ModTime(),
+		Index:      cmt.Index(),
+		SignedHash: signedHash.Clone(),
+		Signature:  sig,
 	}
 }
 
@@ -1507,9 +2001,10 @@ func (fs *FS) buildSyncCfg() (*vcs.SyncOptions, error) {
 	}
 
 	return &vcs.SyncOptions{
-		ConflictStrategy: conflictStrategy,
-		IgnoreDeletes:    fs.cfg.Bool("sync.ignore_removed"),
-		IgnoreMoves:      fs.cfg.Bool("sync.ignore_moved"),
+		ConflictStrategy:  conflictStrategy,
+		IgnoreDeletes:     fs.cfg.Bool("sync.ignore_removed"),
+		IgnoreMoves:       fs.cfg.Bool("sync.ignore_moved"),
+		RequireSignatures: fs.cfg.Bool("sync.require_signatures"),
 		OnAdd: func(newNd n.ModNode) bool {
 			if fs.cfg.Bool("sync.pin_added") {
 				// do pinning and more importantly caching
@@ -1561,6 +2056,7 @@ func (fs *FS) buildSyncCfg() (*vcs.SyncOptions, error) {
 			// conflict files will not get a pin by default.
 			return true
 		},
+		OnContentMerge: fs.mergeContent,
 	}, nil
 }
 
@@ -1621,6 +2117,23 @@ func SyncOptConflictgStrategyPerFolder(strategies map[string]string) SyncOption
 	}
 }
 
+// SyncOptVerifySignatures makes Sync, Import and ApplyPatches check the signatures
+// of the remote's commits with `verify`. Bad signatures are an error.
+// Unsigned commits only if fs.sync.require_signatures is set.
+func SyncOptVerifySignatures(verify func(data, sig []byte) error) SyncOption {
+	return func(cfg *vcs.SyncOptions) {
+		cfg.VerifySignature = verify
+	}
+}
+
+// SyncOptPush marks the merge commit of the sync as triggered by a push
+// of the remote. This is shown by Blame().
+func SyncOptPush() SyncOption {
+	return func(cfg *vcs.SyncOptions) {
+		cfg.Via = vcs.MergeViaPush
+	}
+}
+
 // Sync will synchronize the state of two filesystems.
 // If one of filesystems have unstaged changes, they will be committted first.
 // If our filesystem was changed by Sync(), a new merge commit will also be created.
@@ -1817,6 +2330,55 @@ func (fs *FS) Reset(path, rev string) error {
 	return fs.pinner.PinNode(newNode, false)
 }
 
+// Revert undoes the changes made by the commit `rev` with a new commit.
+// Changes of later commits are kept. If a file was modified again since
+// `rev`, the conflict is handled like during Sync().
+func (fs *FS) Revert(rev string, options ...SyncOption) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.readOnly {
+		return ErrReadOnly
+	}
+
+	cmt, err := parseRev(fs.lkr, rev)
+	if err != nil {
+		return e.Wrap(err, "parse ref")
+	}
+
+	syncCfg, err := fs.buildSyncCfg()
+	if err != nil {
+		return err
+	}
+
+	for _, option := range options {
+		option(syncCfg)
+	}
+
+	restored, err := vcs.Revert(fs.lkr, cmt, syncCfg)
+	if err != nil {
+		return err
+	}
+
+	// Restored versions should be available, just like after Reset():
+	for _, path := range restored {
+		newNode, err := fs.lkr.LookupNode(path)
+		if ie.IsNoSuchFileError(err) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := fs.pinner.PinNode(newNode, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Checkout reverts all state to the commit referenced by `rev`.
 // If `force` is true a non-empty staging area will be overwritten.
 func (fs *FS) Checkout(rev string, force bool) error {
@@ -1988,7 +2550,8 @@ func (fs *FS) MakePatches(fromRev string, folders []string, remoteName string) (
 }
 
 // ApplyPatch reads the binary patch coming from MakePatch and tries to apply it.
-func (fs *FS) ApplyPatch(data []byte) error {
+// Only SyncOptVerifySignatures is respected from `options`.
+func (fs *FS) ApplyPatch(data []byte, options ...SyncOption) error {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
 
@@ -2002,11 +2565,12 @@ func (fs *FS) ApplyPatch(data []byte) error {
 		return err
 	}
 
-	return fs.applyPatches(vcs.Patches{patch})
+	return fs.applyPatches(vcs.Patches{patch}, options)
 }
 
 // ApplyPatches reads the binary patch coming from MakePatches and tries to apply them.
-func (fs *FS) ApplyPatches(data []byte) error {
+// Only SyncOptVerifySignatures is respected from `options`.
+func (fs *FS) ApplyPatches(data []byte, options ...SyncOption) error {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
 
@@ -2020,21 +2584,44 @@ func (fs *FS) ApplyPatches(data []byte) error {
 		return err
 	}
 
-	return fs.applyPatches(*patches)
+	return fs.applyPatches(*patches, options)
 }
 
-func (fs *FS) applyPatches(patches vcs.Patches) error {
+func (fs *FS) applyPatches(patches vcs.Patches, options []SyncOption) error {
 	owner, err := fs.lkr.Owner()
 	if err != nil {
 		return err
 	}
 
+	syncCfg := &vcs.SyncOptions{
+		RequireSignatures: fs.cfg.Bool("sync.require_signatures"),
+	}
+
+	for _, option := range options {
+		option(syncCfg)
+	}
+
+	// Check all signatures before changing anything.
+	if syncCfg.VerifySignature != nil {
+		for _, patch := range patches {
+			if err := vcs.VerifyPatch(patch, syncCfg.VerifySignature, syncCfg.RequireSignatures); err != nil {
+				return err
+			}
+		}
+	}
+
 	highestIndex := int64(-1)
 	for _, patch := range patches {
 		if err := vcs.ApplyPatch(fs.lkr, patch); err != nil {
 			return err
 		}
 
+		// The commit we make replays the remote's patch,
+		// so it gets the signature of the patch.
+		if err := fs.lkr.SetSignature(patch.SignedHash, patch.Signature); err != nil {
+			return err
+		}
+
 		if idx := patch.CurrIndex; highestIndex < idx {
 			highestIndex = idx
 		}
@@ -2105,14 +2692,25 @@ func (fs *FS) HaveStagedChanges() (bool, error) {
 
 // IsCached will return true when the file is cached locally.
 func (fs *FS) IsCached(path string) (bool, error) {
+	return fs.IsCachedAt("", path)
+}
+
+// IsCachedAt is like IsCached, but checks the state of `rev`.
+func (fs *FS) IsCachedAt(rev, path string) (bool, error) {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
 
-	nd, err := fs.lkr.LookupNode(path)
+	nd, err := fs.lookupNodeAt(rev, path)
 	if err != nil {
 		return false, err
 	}
 
+	return fs.isNodeCached(nd)
+}
+
+// isNodeCached checks if all files in or below `nd` are cached locally.
+// fs.mu must be held by the caller.
+func (fs *FS) isNodeCached(nd n.Node) (bool, error) {
 	if nd.Type() == n.NodeTypeDirectory && nd.NChildren() == 0 {
 		return true, nil
 	}
@@ -2121,13 +2719,18 @@ func (fs *FS) IsCached(path string) (bool, error) {
 	cachedCount := 0
 	errNotCachedSentinel := errors.New("not cached found")
 
-	err = n.Walk(fs.lkr, nd, true, func(child n.Node) error {
+	err := n.Walk(fs.lkr, nd, true, func(child n.Node) error {
 		if child.Type() != n.NodeTypeFile {
 			return nil
 		}
 
+		file, ok := child.(*n.File)
+		if !ok {
+			return ie.ErrBadNode
+		}
+
 		totalCount++
-		isCached, err := fs.bk.IsCached(child.BackendHash())
+		isCached, err := fs.isContentCached(file)
 		if err != nil {
 			return err
 		}
@@ -2156,3 +2759,29 @@ func (fs *FS) IsCached(path string) (bool, error) {
 func (fs *FS) Hints() HintManager {
 	return fs.hintManager
 }
+
+// SetRetentionManager sets where the retention policies of the repinner
+// come from. Without one, all versions are kept.
+func (fs *FS) SetRetentionManager(retentionManager RetentionManager) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if retentionManager == nil {
+		retentionManager = defaultRetentionManager{}
+	}
+
+	fs.retentionManager = retentionManager
+}
+
+// SetPinRuleManager sets where the repinner gets per-folder quotas and
+// priorities from. Without one, only the global quota is used.
+func (fs *FS) SetPinRuleManager(pinRuleManager PinRuleManager) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if pinRuleManager == nil {
+		pinRuleManager = defaultPinRuleManager{}
+	}
+
+	fs.pinRuleManager = pinRuleManager
+}
@@ -2,6 +2,7 @@ package catfs
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"bytes"
 	"fmt"
 	"io"
@@ -32,7 +33,10 @@ func init() {
 }
 
 func withDummyFSReadOnly(t *testing.T, readOnly bool, fn func(fs *FS)) {
-	backend := NewMemFsBackend()
+	withDummyFSBackend(t, NewMemFsBackend(), readOnly, fn)
+}
+
+func withDummyFSBackend(t *testing.T, backend FsBackend, readOnly bool, fn func(fs *FS)) {
 	owner := "alice"
 
 	dbPath, err := ioutil.TempDir("", "brig-fs-test")
@@ -484,6 +488,50 @@ func TestSync(t *testing.T) {
 	})
 }
 
+func TestSyncMergeStrategy(t *testing.T) {
+	t.Parallel()
+
+	// Both filesystems need to see the same content:
+	bk := NewMemFsBackend()
+	withDummyFSBackend(t, bk, false, func(fsa *FS) {
+		require.Nil(t, fsa.MakeCommit("hello a"))
+		withDummyFSBackend(t, bk, false, func(fsb *FS) {
+			require.Nil(t, fsb.Stage("/x", bytes.NewReader([]byte("a\nb\nc\n"))))
+			require.Nil(t, fsb.Stage("/y", bytes.NewReader([]byte("a\nb\nc\n"))))
+			require.Nil(t, fsb.MakeCommit("add x and y"))
+			require.Nil(t, fsa.Sync(fsb))
+
+			// Compatible changes on /x, overlapping changes on /y:
+			require.Nil(t, fsa.Stage("/x", bytes.NewReader([]byte("A\nb\nc\n"))))
+			require.Nil(t, fsa.Stage("/y", bytes.NewReader([]byte("a\nB\nc\n"))))
+			require.Nil(t, fsa.MakeCommit("edit on a"))
+
+			require.Nil(t, fsb.Stage("/x", bytes.NewReader([]byte("a\nb\nC\n"))))
+			require.Nil(t, fsb.Stage("/y", bytes.NewReader([]byte("a\nX\nc\n"))))
+			require.Nil(t, fsb.MakeCommit("edit on b"))
+
+			require.Nil(t, fsa.Sync(fsb, SyncOptConflictStrategy("merge")))
+
+			stream, err := fsa.Cat("/x")
+			require.Nil(t, err)
+
+			data, err := ioutil.ReadAll(stream)
+			require.Nil(t, err)
+			require.Equal(t, "A\nb\nC\n", string(data))
+
+			stream, err = fsa.Cat("/y")
+			require.Nil(t, err)
+
+			data, err = ioutil.ReadAll(stream)
+			require.Nil(t, err)
+			require.Equal(t, "a\nB\nc\n", string(data))
+
+			_, err = fsa.Stat("/y.conflict.0")
+			require.Nil(t, err)
+		})
+	})
+}
+
 func TestMakeDiff(t *testing.T) {
 	t.Parallel()
 
@@ -894,6 +942,189 @@ func TestTar(t *testing.T) {
 	})
 }
 
+func TestZip(t *testing.T) {
+	withDummyFS(t, func(fs *FS) {
+		require.Nil(t, fs.Stage("/a/file.png", bytes.NewReader([]byte("hello"))))
+		require.Nil(t, fs.Stage("/b/file.jpg", bytes.NewReader([]byte("world"))))
+		require.Nil(t, fs.Stage("/c/file.gif", bytes.NewReader([]byte("!"))))
+		require.Nil(t, fs.Symlink("/b/link", "file.jpg"))
+
+		buf := &bytes.Buffer{}
+		require.Nil(t, fs.Zip("/", buf, func(info *StatInfo) bool {
+			// Exclude the /c directory:
+			return info.Path != "/c"
+		}))
+
+		r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.Nil(t, err)
+		require.Len(t, r.File, 3)
+
+		expect := []struct {
+			name, data string
+		}{
+			{"a/file.png", "hello"},
+			{"b/file.jpg", "world"},
+			{"b/link", "file.jpg"},
+		}
+
+		for idx, zf := range r.File {
+			require.Equal(t, expect[idx].name, zf.Name)
+
+			fd, err := zf.Open()
+			require.Nil(t, err)
+
+			data, err := ioutil.ReadAll(fd)
+			require.Nil(t, err)
+			require.Nil(t, fd.Close())
+			require.Equal(t, []byte(expect[idx].data), data)
+		}
+
+		require.True(t, r.File[2].Mode()&os.ModeSymlink != 0)
+	})
+}
+
+func TestSymlink(t *testing.T) {
+	withDummyFS(t, func(fs *FS) {
+		require.Nil(t, fs.Stage("/a/file.png", bytes.NewReader([]byte("hello"))))
+		require.Nil(t, fs.Symlink("/a/link", "file.png"))
+		require.NotNil(t, fs.Symlink("/a/empty", ""))
+		require.NotNil(t, fs.Symlink("/a/file.png", "other"))
+
+		err := fs.Stage("/a/link", bytes.NewReader([]byte("world")))
+		require.EqualError(t, err, "`/a/link` exists and is a symlink")
+
+		info, err := fs.Stat("/a/link")
+		require.Nil(t, err)
+		require.True(t, info.IsSymlink)
+		require.False(t, info.IsDir)
+		require.Equal(t, "file.png", info.Target)
+		require.Equal(t, uint64(len("file.png")), info.Size)
+
+		// Symlinks are not followed:
+		_, err = fs.Cat("/a/link")
+		require.NotNil(t, err)
+
+		require.Nil(t, fs.MakeCommit("add link"))
+		require.Nil(t, fs.Move("/a/link", "/link"))
+
+		infos, err := fs.List("/", -1)
+		require.Nil(t, err)
+		require.Len(t, infos, 4)
+		require.Equal(t, "/link", infos[2].Path)
+		require.True(t, infos[2].IsSymlink)
+
+		buf := &bytes.Buffer{}
+		require.Nil(t, fs.Tar("/", buf, nil))
+
+		r := tar.NewReader(buf)
+		hdr, err := r.Next()
+		require.Nil(t, err)
+		require.Equal(t, "a/file.png", hdr.Name)
+
+		hdr, err = r.Next()
+		require.Nil(t, err)
+		require.Equal(t, "link", hdr.Name)
+		require.Equal(t, byte(tar.TypeSymlink), hdr.Typeflag)
+		require.Equal(t, "file.png", hdr.Linkname)
+
+		_, err = r.Next()
+		require.Equal(t, io.EOF, err)
+	})
+}
+
+func TestChmod(t *testing.T) {
+	withDummyFS(t, func(fs *FS) {
+		require.Nil(t, fs.Stage("/dir/script.sh", bytes.NewReader([]byte("#!/bin/sh"))))
+
+		info, err := fs.Stat("/dir/script.sh")
+		require.Nil(t, err)
+		require.Equal(t, os.FileMode(0640), info.Mode)
+		require.False(t, info.HasOwner)
+
+		require.Nil(t, fs.Chmod("/dir/script.sh", 0755))
+		require.Nil(t, fs.Chmod("/dir", 0700))
+		require.Nil(t, fs.Chown("/dir/script.sh", 1000, 100))
+
+		info, err = fs.Stat("/dir/script.sh")
+		require.Nil(t, err)
+		require.Equal(t, os.FileMode(0755), info.Mode)
+		require.True(t, info.HasOwner)
+		require.Equal(t, uint32(1000), info.Uid)
+		require.Equal(t, uint32(100), info.Gid)
+
+		require.Nil(t, fs.MakeCommit("chmod"))
+
+		// Modifying the content should keep the mode:
+		require.Nil(t, fs.Stage("/dir/script.sh", bytes.NewReader([]byte("#!/bin/bash"))))
+		info, err = fs.Stat("/dir/script.sh")
+		require.Nil(t, err)
+		require.Equal(t, os.FileMode(0755), info.Mode)
+
+		buf := &bytes.Buffer{}
+		require.Nil(t, fs.Tar("/", buf, nil))
+
+		r := tar.NewReader(buf)
+		hdr, err := r.Next()
+		require.Nil(t, err)
+		require.Equal(t, "dir/", hdr.Name)
+		require.Equal(t, byte(tar.TypeDir), hdr.Typeflag)
+		require.Equal(t, int64(0700), hdr.Mode)
+
+		hdr, err = r.Next()
+		require.Nil(t, err)
+		require.Equal(t, "dir/script.sh", hdr.Name)
+		require.Equal(t, int64(0755), hdr.Mode)
+		require.Equal(t, 1000, hdr.Uid)
+		require.Equal(t, 100, hdr.Gid)
+
+		_, err = r.Next()
+		require.Equal(t, io.EOF, err)
+	})
+}
+
+func TestXattr(t *testing.T) {
+	withDummyFS(t, func(fs *FS) {
+		require.Nil(t, fs.Stage("/x", bytes.NewReader([]byte{1, 2, 3})))
+
+		_, err := fs.GetXattr("/x", "user.tags")
+		require.Equal(t, ErrNoSuchXattr, err)
+
+		require.Equal(t, ErrBadXattrName, fs.SetXattr("/x", "trusted.tags", []byte("red")))
+		require.Equal(t, ErrBadXattrName, fs.SetXattr("/x", "user.brig.pinned", []byte("yes")))
+
+		require.Nil(t, fs.SetXattr("/x", "user.tags", []byte("red")))
+		require.Nil(t, fs.MakeCommit("tag"))
+
+		value, err := fs.GetXattr("/x", "user.tags")
+		require.Nil(t, err)
+		require.Equal(t, []byte("red"), value)
+
+		names, err := fs.ListXattr("/x")
+		require.Nil(t, err)
+		require.Equal(t, []string{"user.tags"}, names)
+
+		// Modifying the content should keep the xattrs:
+		require.Nil(t, fs.Stage("/x", bytes.NewReader([]byte{4, 5, 6})))
+		value, err = fs.GetXattr("/x", "user.tags")
+		require.Nil(t, err)
+		require.Equal(t, []byte("red"), value)
+
+		buf := &bytes.Buffer{}
+		require.Nil(t, fs.Tar("/", buf, nil))
+
+		hdr, err := tar.NewReader(buf).Next()
+		require.Nil(t, err)
+		require.Equal(t, "red", hdr.PAXRecords["SCHILY.xattr.user.tags"])
+
+		require.Nil(t, fs.RemoveXattr("/x", "user.tags"))
+		require.Equal(t, ErrNoSuchXattr, fs.RemoveXattr("/x", "user.tags"))
+
+		names, err = fs.ListXattr("/x")
+		require.Nil(t, err)
+		require.Empty(t, names)
+	})
+}
+
 func TestReadOnly(t *testing.T) {
 	withDummyFSReadOnly(t, true, func(fs *FS) {
 		err := fs.Stage("/x", bytes.NewReader([]byte{1, 2, 3}))
@@ -1002,3 +1233,68 @@ func TestUndeleteDirectory(t *testing.T) {
 		}, paths)
 	})
 }
+
+func TestRevert(t *testing.T) {
+	t.Parallel()
+
+	withDummyFS(t, func(fs *FS) {
+		require.Nil(t, fs.Stage("/x", bytes.NewReader([]byte("old"))))
+		require.Nil(t, fs.MakeCommit("add x"))
+		require.Nil(t, fs.Stage("/x", bytes.NewReader([]byte("new"))))
+		require.Nil(t, fs.MakeCommit("edit x"))
+		require.Nil(t, fs.Stage("/y", bytes.NewReader([]byte("y"))))
+		require.Nil(t, fs.MakeCommit("add y"))
+
+		// Unpin the version that will be restored:
+		require.Nil(t, fs.Unpin("/x", "head^^", true))
+		require.Nil(t, fs.Revert("head^"))
+
+		requireContent(t, fs, "/x", "old")
+		requireContent(t, fs, "/y", "y")
+
+		// The restored version is pinned:
+		info, err := fs.Stat("/x")
+		require.Nil(t, err)
+		require.True(t, info.IsPinned)
+	})
+}
+
+func TestAccessAtRev(t *testing.T) {
+	t.Parallel()
+
+	withDummyFS(t, func(fs *FS) {
+		require.Nil(t, fs.Stage("/dir/x", bytes.NewReader([]byte("old"))))
+		require.Nil(t, fs.MakeCommit("add x"))
+		require.Nil(t, fs.Stage("/dir/x", bytes.NewReader([]byte("new"))))
+		require.Nil(t, fs.Remove("/dir/x"))
+		require.Nil(t, fs.MakeCommit("edit and remove x"))
+
+		_, err := fs.Stat("/dir/x")
+		require.True(t, ie.IsNoSuchFileError(err))
+
+		info, err := fs.StatAt("head^", "/dir/x")
+		require.Nil(t, err)
+		require.Equal(t, uint64(3), info.Size)
+
+		entries, err := fs.ListAt("@{now}^", "/", -1)
+		require.Nil(t, err)
+		require.Len(t, entries, 3)
+		require.Equal(t, "/dir/x", entries[2].Path)
+
+		stream, err := fs.CatAt("head^", "/dir/x")
+		require.Nil(t, err)
+		data, err := ioutil.ReadAll(stream)
+		require.Nil(t, err)
+		require.Equal(t, "old", string(data))
+
+		_, err = fs.CatAt("head^", "/dir")
+		require.True(t, ie.IsNoSuchFileError(err))
+
+		_, err = fs.StatAt("head^", "/nope")
+		require.True(t, ie.IsNoSuchFileError(err))
+
+		buf := &bytes.Buffer{}
+		require.Nil(t, fs.TarAt("head^", "/dir", buf, nil))
+		require.True(t, buf.Len() > 0)
+	})
+}
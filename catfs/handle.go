@@ -49,20 +49,31 @@ func (hdl *Handle) initStreamIfNeeded() error {
 	}
 
 	// Initialize the stream lazily to avoid I/O on open()
-	rawStream, err := hdl.fs.bk.Cat(hdl.file.BackendHash())
-	if err != nil {
-		return err
+	if hdl.file.IsChunked() {
+		// Chunks are opened on demand by the stream.
+		hdl.stream = hdl.fs.catChunks(
+			hdl.file.Chunks(),
+			hdl.file.Size(),
+			hdl.file.IsRaw(),
+		)
+	} else {
+		rawStream, err := hdl.fs.bk.Cat(hdl.file.BackendHash())
+		if err != nil {
+			return err
+		}
+
+		// Stack the mio stack on top:
+		hdl.stream, err = mio.NewOutStream(
+			rawStream,
+			hdl.file.IsRaw(),
+			hdl.file.Key(),
+		)
+		if err != nil {
+			return err
+		}
 	}
 
-	// Stack the mio stack on top:
-	hdl.stream, err = mio.NewOutStream(
-		rawStream,
-		hdl.file.IsRaw(),
-		hdl.file.Key(),
-	)
-	if err != nil {
-		return err
-	}
+	var err error
 
 	hdl.layer, err = pagecache.NewLayer(
 		hdl.stream,
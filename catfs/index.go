@@ -0,0 +1,114 @@
+package catfs
+
+import (
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dustin/go-humanize"
+	"github.com/sahib/brig/catfs/db"
+	"github.com/sahib/brig/catfs/mio/compress"
+	n "github.com/sahib/brig/catfs/nodes"
+)
+
+// The content index remembers the words of text files, so that a
+// »content:« query does not need to read all files every time.
+// Entries are keyed by content hash and never change, since the
+// same content always yields the same words.
+
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// contentWords returns the set of (lower case) words in `file`.
+// Files that are no text files or too big do not have any words.
+func (fs *FS) contentWords(file *n.File) (map[string]bool, error) {
+	key := []string{"words", file.ContentHash().B58String()}
+
+	fs.mu.Lock()
+	data, err := fs.kv.Get(key...)
+	fs.mu.Unlock()
+
+	if err != nil && err != db.ErrNoSuchKey {
+		return nil, err
+	}
+
+	if err == db.ErrNoSuchKey {
+		words, isIndexable, err := fs.indexFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		data = []byte(strings.Join(words, "\n"))
+		if isIndexable {
+			fs.mu.Lock()
+			batch := fs.kv.Batch()
+			batch.Put(data, key...)
+			err = batch.Flush()
+			fs.mu.Unlock()
+
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	words := make(map[string]bool)
+	for _, word := range strings.Split(string(data), "\n") {
+		if word != "" {
+			words[word] = true
+		}
+	}
+
+	return words, nil
+}
+
+// indexFile reads `file` and returns the sorted words in it.
+// isIndexable is false when the file is too big to be indexed
+// and the result should not be remembered.
+func (fs *FS) indexFile(file *n.File) (words []string, isIndexable bool, err error) {
+	maxSize, err := humanize.ParseBytes(fs.cfg.String("find.index_max_size"))
+	if err != nil {
+		return nil, false, err
+	}
+
+	if file.Size() > maxSize {
+		return nil, false, nil
+	}
+
+	stream, err := fs.catFile(file)
+	if err != nil {
+		return nil, false, err
+	}
+
+	defer stream.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(stream, int64(maxSize)))
+	if err != nil {
+		return nil, false, err
+	}
+
+	header := data
+	if len(header) > 512 {
+		header = header[:512]
+	}
+
+	if !compress.IsTextFile(file.Path(), header) {
+		return nil, true, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, word := range splitWords(string(data)) {
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+
+	sort.Strings(words)
+	return words, true, nil
+}
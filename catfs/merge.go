@@ -0,0 +1,98 @@
+package catfs
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io/ioutil"
+
+	"github.com/sahib/brig/catfs/mio/compress"
+	n "github.com/sahib/brig/catfs/nodes"
+	"github.com/sahib/brig/catfs/vcs"
+	"github.com/sahib/brig/util/diff3"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxMergeSize is the max size of files that we try to merge
+	// with the "merge" conflict strategy. Bigger files get a conflict file.
+	maxMergeSize = 16 * 1024 * 1024
+)
+
+func (fs *FS) readAll(file *n.File) ([]byte, error) {
+	stream, err := fs.catFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	defer stream.Close()
+	return ioutil.ReadAll(stream)
+}
+
+// mergeContent does a three-way merge of the text files `src` and `dst`,
+// with `base` being their common ancestor. If the files are no text files or
+// the changes overlap, nil is returned.
+//
+// NOTE: This is called during Sync() and expects fs.mu to be locked.
+func (fs *FS) mergeContent(base, src, dst n.ModNode) (*vcs.MergeResult, error) {
+	files := []*n.File{}
+	for _, nd := range []n.ModNode{base, src, dst} {
+		file, ok := nd.(*n.File)
+		if !ok || file.Size() > maxMergeSize {
+			return nil, nil
+		}
+
+		files = append(files, file)
+	}
+
+	contents := [][]byte{}
+	for _, file := range files {
+		data, err := fs.readAll(file)
+		if err != nil {
+			// Content might not be reachable right now;
+			// this should not let the whole sync fail.
+			log.Warningf("merge: failed to read %s: %v", file.Path(), err)
+			return nil, nil
+		}
+
+		header := data
+		if len(header) > compress.HeaderSizeThreshold {
+			header = header[:compress.HeaderSizeThreshold]
+		}
+
+		if !compress.IsTextFile(dst.Path(), header) {
+			return nil, nil
+		}
+
+		contents = append(contents, data)
+	}
+
+	merged, ok := diff3.Merge(contents[0], contents[2], contents[1])
+	if !ok {
+		return nil, nil
+	}
+
+	// Keep the key of our file, like Stage() does for existing files.
+	dstFile := files[2]
+	key := dstFile.Key()
+	if dstFile.Size() == 0 {
+		key = make([]byte, defaultEncryptionKeyLength)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+	}
+
+	info, err := fs.addContent(dst.Path(), bytes.NewReader(merged), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vcs.MergeResult{
+		ContentHash: info.contentHash,
+		BackendHash: info.backendHash,
+		Chunks:      info.chunks,
+		Size:        info.size,
+		CachedSize:  info.cachedSize,
+		Key:         key,
+		IsRaw:       info.isRaw,
+	}, nil
+}
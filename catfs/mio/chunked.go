@@ -0,0 +1,147 @@
+package mio
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// chunkedStream concatenates several streams to a single seekable stream.
+// The streams are opened lazily, so only the chunk at the current seek
+// position needs to be available.
+type chunkedStream struct {
+	sizes   []uint64
+	offsets []uint64
+	size    uint64
+	open    func(idx int) (Stream, error)
+
+	pos  uint64
+	idx  int
+	curr Stream
+}
+
+// NewChunkedStream returns a stream that reads all chunks in order.
+// `sizes` contains the size of each chunk, `open` is called to open the
+// chunk with the index `idx` once it is needed.
+func NewChunkedStream(sizes []uint64, open func(idx int) (Stream, error)) Stream {
+	offsets := make([]uint64, len(sizes))
+	size := uint64(0)
+	for idx, chunkSize := range sizes {
+		offsets[idx] = size
+		size += chunkSize
+	}
+
+	return &chunkedStream{
+		sizes:   sizes,
+		offsets: offsets,
+		size:    size,
+		open:    open,
+		idx:     -1,
+	}
+}
+
+// chunkAt returns the index of the chunk that contains `pos`.
+func (cs *chunkedStream) chunkAt(pos uint64) int {
+	return sort.Search(len(cs.offsets), func(idx int) bool {
+		return cs.offsets[idx]+cs.sizes[idx] > pos
+	})
+}
+
+func (cs *chunkedStream) closeCurr() error {
+	if cs.curr == nil {
+		return nil
+	}
+
+	err := cs.curr.Close()
+	cs.curr = nil
+	cs.idx = -1
+	return err
+}
+
+func (cs *chunkedStream) Read(buf []byte) (int, error) {
+	if cs.pos >= cs.size {
+		return 0, io.EOF
+	}
+
+	idx := cs.chunkAt(cs.pos)
+	if idx != cs.idx || cs.curr == nil {
+		if err := cs.closeCurr(); err != nil {
+			return 0, err
+		}
+
+		stream, err := cs.open(idx)
+		if err != nil {
+			return 0, err
+		}
+
+		if offset := cs.pos - cs.offsets[idx]; offset > 0 {
+			if _, err := stream.Seek(int64(offset), io.SeekStart); err != nil {
+				stream.Close()
+				return 0, err
+			}
+		}
+
+		cs.curr = stream
+		cs.idx = idx
+	}
+
+	// Never read over the end of the current chunk:
+	chunkEnd := cs.offsets[idx] + cs.sizes[idx]
+	if left := chunkEnd - cs.pos; uint64(len(buf)) > left {
+		buf = buf[:left]
+	}
+
+	n, err := cs.curr.Read(buf)
+	cs.pos += uint64(n)
+
+	if err == io.EOF {
+		if cs.pos < chunkEnd {
+			return n, fmt.Errorf("chunk %d is shorter than expected", idx)
+		}
+
+		// The next read will continue with the next chunk.
+		err = nil
+	}
+
+	return n, err
+}
+
+func (cs *chunkedStream) Seek(offset int64, whence int) (int64, error) {
+	var newPos int64
+	switch whence {
+	case io.SeekStart:
+		newPos = offset
+	case io.SeekCurrent:
+		newPos = int64(cs.pos) + offset
+	case io.SeekEnd:
+		newPos = int64(cs.size) + offset
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+
+	if newPos < 0 {
+		return 0, fmt.Errorf("negative seek position: %d", newPos)
+	}
+
+	cs.pos = uint64(newPos)
+	if cs.curr == nil || cs.pos >= cs.size {
+		return newPos, nil
+	}
+
+	if idx := cs.chunkAt(cs.pos); idx != cs.idx {
+		// Open the right chunk on the next read.
+		return newPos, cs.closeCurr()
+	}
+
+	_, err := cs.curr.Seek(int64(cs.pos-cs.offsets[cs.idx]), io.SeekStart)
+	return newPos, err
+}
+
+func (cs *chunkedStream) WriteTo(w io.Writer) (int64, error) {
+	// Hide our WriteTo from io.Copy, otherwise it would call us again.
+	return io.Copy(w, struct{ io.Reader }{cs})
+}
+
+func (cs *chunkedStream) Close() error {
+	return cs.closeCurr()
+}
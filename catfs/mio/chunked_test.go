@@ -0,0 +1,88 @@
+package mio
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sahib/brig/util/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func bytesStream(data []byte) Stream {
+	r := bytes.NewReader(data)
+	return stream{
+		Reader:   r,
+		Seeker:   r,
+		WriterTo: r,
+		Closer:   ioutil.NopCloser(r),
+	}
+}
+
+func newTestChunkedStream(data []byte, sizes []uint64) (Stream, *int) {
+	opened := 0
+	offsets := []uint64{}
+	off := uint64(0)
+	for _, size := range sizes {
+		offsets = append(offsets, off)
+		off += size
+	}
+
+	return NewChunkedStream(sizes, func(idx int) (Stream, error) {
+		opened++
+		start := offsets[idx]
+		return bytesStream(data[start : start+sizes[idx]]), nil
+	}), &opened
+}
+
+func TestChunkedStreamRead(t *testing.T) {
+	data := testutil.CreateDummyBuf(1000)
+	stream, opened := newTestChunkedStream(data, []uint64{100, 1, 499, 400})
+
+	read, err := ioutil.ReadAll(stream)
+	require.Nil(t, err)
+	require.Equal(t, data, read)
+	require.Equal(t, 4, *opened)
+
+	buf := &bytes.Buffer{}
+	_, err = stream.Seek(0, io.SeekStart)
+	require.Nil(t, err)
+	_, err = stream.WriteTo(buf)
+	require.Nil(t, err)
+	require.Equal(t, data, buf.Bytes())
+	require.Nil(t, stream.Close())
+}
+
+func TestChunkedStreamSeek(t *testing.T) {
+	data := testutil.CreateDummyBuf(1000)
+	stream, opened := newTestChunkedStream(data, []uint64{100, 500, 400})
+
+	for _, pos := range []int64{550, 50, 999, 600, 0, 599} {
+		newPos, err := stream.Seek(pos, io.SeekStart)
+		require.Nil(t, err)
+		require.Equal(t, pos, newPos)
+
+		buf := make([]byte, 1)
+		n, err := stream.Read(buf)
+		require.Nil(t, err)
+		require.Equal(t, 1, n)
+		require.Equal(t, data[pos], buf[0])
+	}
+
+	// Seeking inside the same chunk should not open it again:
+	before := *opened
+	_, err := stream.Seek(10, io.SeekCurrent)
+	require.Nil(t, err)
+	_, err = stream.Read(make([]byte, 1))
+	require.Nil(t, err)
+	require.Equal(t, before, *opened)
+
+	pos, err := stream.Seek(-1, io.SeekEnd)
+	require.Nil(t, err)
+	require.Equal(t, int64(999), pos)
+
+	rest, err := ioutil.ReadAll(stream)
+	require.Nil(t, err)
+	require.Equal(t, data[999:], rest)
+}
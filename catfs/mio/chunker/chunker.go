@@ -0,0 +1,101 @@
+// Package chunker implements content-defined chunking.
+//
+// The input stream is split at positions where a rolling hash over the last
+// bytes matches a certain pattern. Since the cut points depend only on the
+// content around them, inserting or removing data in a stream only changes
+// the chunks near the edit. All other chunks stay the same and can be
+// deduplicated by the storage below.
+//
+// The rolling hash used is a "gear" hash, as described in the FastCDC paper.
+package chunker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+var gearTable [256]uint64
+
+func init() {
+	// Fill the table with pseudo random numbers from a fixed seed.
+	// The values must never change, otherwise all cut points move.
+	state := uint64(0x6272696763646321)
+	for idx := range gearTable {
+		// splitmix64:
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		gearTable[idx] = z ^ (z >> 31)
+	}
+}
+
+// Chunker splits a stream into content-defined chunks.
+type Chunker struct {
+	r        *bufio.Reader
+	buf      []byte
+	mask     uint64
+	minSize  int
+	maxSize  int
+	finished bool
+}
+
+// New returns a new Chunker reading from `r`. The produced chunks have an
+// average size of roughly `avgSize`. No chunk is smaller than a quarter or
+// bigger than four times of `avgSize`, except the last one which might be
+// smaller. `avgSize` is rounded down to the next power of two.
+func New(r io.Reader, avgSize int) (*Chunker, error) {
+	if avgSize < 64 {
+		return nil, fmt.Errorf("average chunk size is too small: %d", avgSize)
+	}
+
+	// Use the upper bits of the hash, since they depend on more input bytes.
+	maskBits := uint(bits.Len(uint(avgSize)) - 1)
+	mask := ((uint64(1) << maskBits) - 1) << (64 - maskBits)
+
+	return &Chunker{
+		r:       bufio.NewReaderSize(r, 64*1024),
+		buf:     make([]byte, 0, 4*avgSize),
+		mask:    mask,
+		minSize: avgSize / 4,
+		maxSize: avgSize * 4,
+	}, nil
+}
+
+// Next returns the next chunk. io.EOF is returned when no data is left.
+// The returned slice is only valid until the next call of Next().
+func (c *Chunker) Next() ([]byte, error) {
+	if c.finished {
+		return nil, io.EOF
+	}
+
+	c.buf = c.buf[:0]
+
+	var hash uint64
+	for len(c.buf) < c.maxSize {
+		b, err := c.r.ReadByte()
+		if err == io.EOF {
+			c.finished = true
+			if len(c.buf) == 0 {
+				return nil, io.EOF
+			}
+
+			return c.buf, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		c.buf = append(c.buf, b)
+		hash = (hash << 1) + gearTable[b]
+
+		if len(c.buf) >= c.minSize && hash&c.mask == 0 {
+			break
+		}
+	}
+
+	return c.buf, nil
+}
@@ -0,0 +1,76 @@
+package chunker
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/sahib/brig/util/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func chunkAll(t *testing.T, data []byte, avgSize int) [][]byte {
+	chunker, err := New(bytes.NewReader(data), avgSize)
+	require.Nil(t, err)
+
+	chunks := [][]byte{}
+	for {
+		chunk, err := chunker.Next()
+		if err == io.EOF {
+			break
+		}
+
+		require.Nil(t, err)
+		chunks = append(chunks, append([]byte{}, chunk...))
+	}
+
+	return chunks
+}
+
+func TestChunkerEmpty(t *testing.T) {
+	require.Empty(t, chunkAll(t, nil, 1024))
+}
+
+func TestChunkerBounds(t *testing.T) {
+	data := testutil.CreateRandomDummyBuf(1024*1024, 42)
+	chunks := chunkAll(t, data, 4096)
+	require.True(t, len(chunks) > 1)
+
+	for idx, chunk := range chunks {
+		require.True(t, len(chunk) <= 4*4096)
+		if idx != len(chunks)-1 {
+			require.True(t, len(chunk) >= 4096/4)
+		}
+	}
+
+	require.Equal(t, data, bytes.Join(chunks, nil))
+}
+
+func TestChunkerShifted(t *testing.T) {
+	data := testutil.CreateRandomDummyBuf(1024*1024, 23)
+	chunks := chunkAll(t, data, 4096)
+
+	// Insert some bytes at the beginning. Apart from the first few chunks,
+	// all other chunks should still be the same.
+	shifted := append([]byte("hello world"), data...)
+	shiftedChunks := chunkAll(t, shifted, 4096)
+
+	known := make(map[string]bool)
+	for _, chunk := range chunks {
+		known[string(chunk)] = true
+	}
+
+	same := 0
+	for _, chunk := range shiftedChunks {
+		if known[string(chunk)] {
+			same++
+		}
+	}
+
+	require.True(t, same >= len(chunks)-2, "only %d of %d chunks are equal", same, len(chunks))
+}
+
+func TestChunkerBadSize(t *testing.T) {
+	_, err := New(bytes.NewReader(nil), 1)
+	require.NotNil(t, err)
+}
@@ -1,6 +1,7 @@
 package compress
 
 import (
+	"bytes"
 	"mime"
 	"net/http"
 	"path/filepath"
@@ -54,6 +55,22 @@ func isCompressible(mimetype string) bool {
 	return CompressibleMapping[mimetype]
 }
 
+// GuessMimeType guesses the mime type of a file from its path name and
+// the header data. An empty string is returned if nothing matched.
+func GuessMimeType(path string, header []byte) string {
+	return guessMime(path, header)
+}
+
+// IsTextFile guesses from the path name and the header data
+// whether the file contains text. Files with NUL bytes are never text.
+func IsTextFile(path string, header []byte) bool {
+	if bytes.IndexByte(header, 0) >= 0 {
+		return false
+	}
+
+	return strings.HasPrefix(guessMime(path, header), "text/")
+}
+
 // GuessAlgorithm takes the path name and the header data of it
 // and tries to guess a suitable compression algorithm.
 func GuessAlgorithm(path string, header []byte) (AlgorithmType, error) {
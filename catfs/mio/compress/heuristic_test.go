@@ -58,3 +58,23 @@ func TestChooseCompressAlgo(t *testing.T) {
 		})
 	}
 }
+
+func TestIsTextFile(t *testing.T) {
+	t.Parallel()
+
+	if !IsTextFile("notes", []byte("hello world\nhow are you?\n")) {
+		t.Errorf("plain text was not detected as text")
+	}
+
+	if !IsTextFile("main.go", []byte("package main\n")) {
+		t.Errorf("go source was not detected as text")
+	}
+
+	if IsTextFile("1.txt", []byte{'a', 0, 'b'}) {
+		t.Errorf("data with NUL bytes was detected as text")
+	}
+
+	if IsTextFile("4.zip", []byte{0x50, 0x4b, 0x3, 0x4}) {
+		t.Errorf("zip was detected as text")
+	}
+}
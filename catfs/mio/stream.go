@@ -126,7 +126,19 @@ func guessCompression(path string, r io.Reader, hint *hints.Hint) (io.Reader, er
 		return nil, err
 	}
 
-	compressAlgo, err := compress.GuessAlgorithm(path, headerBuf)
+	*hint = ResolveCompressionGuess(path, headerBuf, *hint)
+	return headerReader, nil
+}
+
+// ResolveCompressionGuess replaces a "guess" compression in `hint` by an
+// actual algorithm, based on `path` and the first bytes of the file in `header`.
+// Hints with another compression setting are returned unchanged.
+func ResolveCompressionGuess(path string, header []byte, hint hints.Hint) hints.Hint {
+	if hint.CompressionAlgo != hints.CompressionGuess {
+		return hint
+	}
+
+	compressAlgo, err := compress.GuessAlgorithm(path, header)
 	if err != nil {
 		// NOTE: don't error out here. That just means we don't
 		// guessed the perfect settings.
@@ -137,7 +149,7 @@ func guessCompression(path string, r io.Reader, hint *hints.Hint) (io.Reader, er
 
 	log.Debugf("guessed '%s' compression for file %s", compressAlgo, path)
 	hint.CompressionAlgo = hints.CompressAlgorithmTypeToCompressionHint(compressAlgo)
-	return headerReader, nil
+	return hint
 }
 
 // NewInStream creates a new stream that pipes data into ipfs.
@@ -217,18 +229,19 @@ type limitedStream struct {
 }
 
 func (ls *limitedStream) Read(buf []byte) (int, error) {
-	isEOF := false
-	if ls.pos+uint64(len(buf)) >= ls.size {
-		buf = buf[:ls.size-ls.pos]
-		isEOF = true
+	if ls.pos >= ls.size {
+		return 0, io.EOF
 	}
 
-	n, err := ls.stream.Read(buf)
-	if err != nil {
-		return n, err
+	if rest := ls.size - ls.pos; uint64(len(buf)) > rest {
+		buf = buf[:rest]
 	}
 
-	if isEOF {
+	// Keep track of the position, so that short reads
+	// of the underlying stream do not end the stream early.
+	n, err := ls.stream.Read(buf)
+	ls.pos += uint64(n)
+	if err == nil && ls.pos >= ls.size {
 		err = io.EOF
 	}
 
@@ -6,6 +6,7 @@ import (
 	"io"
 	"io/ioutil"
 	"testing"
+	"testing/iotest"
 
 	"github.com/brianvoe/gofakeit/v6"
 	"github.com/sahib/brig/catfs/mio/compress"
@@ -182,6 +183,49 @@ func TestLimitedStream(t *testing.T) {
 	require.Equal(t, buf.Bytes(), testData)
 }
 
+func TestLimitedStreamShortReads(t *testing.T) {
+	t.Parallel()
+
+	testData := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	r := bytes.NewReader(testData)
+	stream := struct {
+		io.Reader
+		io.Seeker
+		io.Closer
+		io.WriterTo
+	}{
+		// Only ever return a single byte per read call:
+		Reader:   iotest.OneByteReader(r),
+		Seeker:   r,
+		WriterTo: r,
+		Closer:   ioutil.NopCloser(r),
+	}
+
+	limitStream := LimitStream(stream, 5)
+	data, err := ioutil.ReadAll(limitStream)
+	require.Nil(t, err)
+	require.Equal(t, testData[:5], data)
+
+	// Reading in the middle should continue at the seeked position:
+	off, err := limitStream.Seek(2, io.SeekStart)
+	require.Nil(t, err)
+	require.Equal(t, int64(2), off)
+
+	buf := make([]byte, 1)
+	_, err = io.ReadFull(limitStream, buf)
+	require.Nil(t, err)
+	require.Equal(t, testData[2:3], buf)
+
+	off, err = limitStream.Seek(0, io.SeekCurrent)
+	require.Nil(t, err)
+	require.Equal(t, int64(3), off)
+
+	data, err = ioutil.ReadAll(limitStream)
+	require.Nil(t, err)
+	require.Equal(t, testData[3:5], data)
+}
+
 func TestLimitStreamSize(t *testing.T) {
 	// Size taken from a dummy file that showed this bug:
 	data := testutil.CreateDummyBuf(6041)
@@ -1,7 +1,10 @@
 package nodes
 
 import (
+	"bytes"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -38,6 +41,17 @@ type Base struct {
 
 	// Unique identifier for this node
 	inode uint64
+
+	// Permission bits of this node, if hasMode is set.
+	mode    os.FileMode
+	hasMode bool
+
+	// Numeric owner of this node, if hasOwner is set.
+	uid, gid uint32
+	hasOwner bool
+
+	// User defined extended attributes (name -> value).
+	xattrs map[string][]byte
 }
 
 // copyBase will copy all attributes from the base.
@@ -51,9 +65,28 @@ func (b *Base) copyBase(inode uint64) Base {
 		modTime:  b.modTime,
 		nodeType: b.nodeType,
 		inode:    inode,
+		mode:     b.mode,
+		hasMode:  b.hasMode,
+		uid:      b.uid,
+		gid:      b.gid,
+		hasOwner: b.hasOwner,
+		xattrs:   copyXattrs(b.xattrs),
 	}
 }
 
+func copyXattrs(xattrs map[string][]byte) map[string][]byte {
+	if len(xattrs) == 0 {
+		return nil
+	}
+
+	cp := make(map[string][]byte, len(xattrs))
+	for name, value := range xattrs {
+		cp[name] = append([]byte{}, value...)
+	}
+
+	return cp
+}
+
 // User returns the user that last modified this node.
 func (b *Base) User() string {
 	return b.user
@@ -96,6 +129,90 @@ func (b *Base) Inode() uint64 {
 	return b.inode
 }
 
+// Mode returns the permission bits of this node.
+// If no mode was ever set, a sensible default for the node type is returned.
+func (b *Base) Mode() os.FileMode {
+	if b.hasMode {
+		return b.mode
+	}
+
+	return DefaultMode(b.nodeType)
+}
+
+// HasMode returns true if the permission bits were set explicitly.
+func (b *Base) HasMode() bool {
+	return b.hasMode
+}
+
+// Owner returns the numeric owner of the node.
+// `ok` is false if no owner was recorded for this node.
+func (b *Base) Owner() (uid, gid uint32, ok bool) {
+	return b.uid, b.gid, b.hasOwner
+}
+
+// Xattr returns the value of the extended attribute `name`.
+// `ok` is false if no such attribute exists.
+func (b *Base) Xattr(name string) (value []byte, ok bool) {
+	value, ok = b.xattrs[name]
+	return value, ok
+}
+
+// ListXattr returns the sorted names of all extended attributes.
+func (b *Base) ListXattr() []string {
+	names := make([]string, 0, len(b.xattrs))
+	for name := range b.xattrs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func (b *Base) setXattr(name string, value []byte) {
+	if b.xattrs == nil {
+		b.xattrs = make(map[string][]byte)
+	}
+
+	b.xattrs[name] = append([]byte{}, value...)
+}
+
+func (b *Base) removeXattr(name string) {
+	delete(b.xattrs, name)
+	if len(b.xattrs) == 0 {
+		b.xattrs = nil
+	}
+}
+
+func (b *Base) setMode(mode os.FileMode) {
+	b.mode = mode & os.ModePerm
+	b.hasMode = true
+}
+
+func (b *Base) setOwner(uid, gid uint32) {
+	b.uid, b.gid = uid, gid
+	b.hasOwner = true
+}
+
+// attrHashSuffix returns a string that should be appended to the input of the
+// tree hash. Nodes without explicit mode, owner or xattrs produce an empty string,
+// so their hashes stay the same as before those attributes existed.
+func (b *Base) attrHashSuffix() string {
+	suffix := ""
+	if b.hasMode {
+		suffix += fmt.Sprintf("|mode:%o", b.mode)
+	}
+
+	if b.hasOwner {
+		suffix += fmt.Sprintf("|owner:%d:%d", b.uid, b.gid)
+	}
+
+	for _, name := range b.ListXattr() {
+		suffix += fmt.Sprintf("|xattr:%s=%x", name, b.xattrs[name])
+	}
+
+	return suffix
+}
+
 /////// UTILS /////////
 
 func (b *Base) setBaseAttrsToNode(capnode capnp_model.Node) error {
@@ -124,6 +241,65 @@ func (b *Base) setBaseAttrsToNode(capnode capnp_model.Node) error {
 	}
 
 	capnode.SetInode(b.inode)
+	capnode.SetMode(uint32(b.mode))
+	capnode.SetHasMode(b.hasMode)
+	capnode.SetUid(b.uid)
+	capnode.SetGid(b.gid)
+	capnode.SetHasOwner(b.hasOwner)
+	return b.setXattrsToNode(capnode)
+}
+
+func (b *Base) setXattrsToNode(capnode capnp_model.Node) error {
+	if len(b.xattrs) == 0 {
+		return nil
+	}
+
+	names := b.ListXattr()
+	capXattrs, err := capnode.NewXattrs(int32(len(names)))
+	if err != nil {
+		return err
+	}
+
+	for idx, name := range names {
+		capXattr := capXattrs.At(idx)
+		if err := capXattr.SetName(name); err != nil {
+			return err
+		}
+
+		if err := capXattr.SetValue(b.xattrs[name]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *Base) parseXattrsFromNode(capnode capnp_model.Node) error {
+	b.xattrs = nil
+	if !capnode.HasXattrs() {
+		return nil
+	}
+
+	capXattrs, err := capnode.Xattrs()
+	if err != nil {
+		return err
+	}
+
+	for idx := 0; idx < capXattrs.Len(); idx++ {
+		capXattr := capXattrs.At(idx)
+		name, err := capXattr.Name()
+		if err != nil {
+			return err
+		}
+
+		value, err := capXattr.Value()
+		if err != nil {
+			return err
+		}
+
+		b.setXattr(name, value)
+	}
+
 	return nil
 }
 
@@ -170,6 +346,8 @@ func (b *Base) parseBaseAttrsFromNode(capnode capnp_model.Node) error {
 		b.nodeType = NodeTypeDirectory
 	case capnp_model.Node_Which_commit:
 		b.nodeType = NodeTypeCommit
+	case capnp_model.Node_Which_symlink:
+		b.nodeType = NodeTypeSymlink
 	case capnp_model.Node_Which_ghost:
 		// Ghost set the nodeType themselves.
 		// Ignore them here.
@@ -178,7 +356,12 @@ func (b *Base) parseBaseAttrsFromNode(capnode capnp_model.Node) error {
 	}
 
 	b.inode = capnode.Inode()
-	return nil
+	b.mode = os.FileMode(capnode.Mode()) & os.ModePerm
+	b.hasMode = capnode.HasMode()
+	b.uid = capnode.Uid()
+	b.gid = capnode.Gid()
+	b.hasOwner = capnode.HasOwner()
+	return b.parseXattrsFromNode(capnode)
 }
 
 func prefixSlash(s string) string {
@@ -233,6 +416,8 @@ func CapNodeToNode(capNd capnp_model.Node) (Node, error) {
 		node = &Directory{}
 	case capnp_model.Node_Which_commit:
 		node = &Commit{}
+	case capnp_model.Node_Which_symlink:
+		node = &Symlink{}
 	default:
 		return nil, fmt.Errorf("Bad capnp node type `%d`", typ)
 	}
@@ -303,12 +488,63 @@ func ParentDirectory(lkr Linker, nd Node) (*Directory, error) {
 	return parDir, nil
 }
 
+// DefaultMode returns the permission bits that are reported
+// for nodes of type `typ` that have no explicit mode.
+func DefaultMode(typ NodeType) os.FileMode {
+	switch typ {
+	case NodeTypeDirectory:
+		return 0755
+	case NodeTypeSymlink:
+		return 0777
+	default:
+		return 0640
+	}
+}
+
+// SameAttrs checks if `a` and `b` have the same mode, owner and xattrs.
+func SameAttrs(a, b Metadatable) bool {
+	return SameModeAndOwner(a, b) && SameXattrs(a, b)
+}
+
+// SameModeAndOwner checks if `a` and `b` have the same mode and owner.
+func SameModeAndOwner(a, b Metadatable) bool {
+	aUID, aGID, aOk := a.Owner()
+	bUID, bGID, bOk := b.Owner()
+	if aOk != bOk || aUID != bUID || aGID != bGID {
+		return false
+	}
+
+	return a.HasMode() == b.HasMode() && a.Mode() == b.Mode()
+}
+
+// SameXattrs checks if `a` and `b` have the same extended attributes.
+func SameXattrs(a, b Metadatable) bool {
+	aNames, bNames := a.ListXattr(), b.ListXattr()
+	if len(aNames) != len(bNames) {
+		return false
+	}
+
+	for idx, name := range aNames {
+		if bNames[idx] != name {
+			return false
+		}
+
+		aValue, _ := a.Xattr(name)
+		bValue, _ := b.Xattr(name)
+		if !bytes.Equal(aValue, bValue) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // ContentHash returns the correct content hash for `nd`.
 // This also works for ghosts where the content hash is taken from the
 // underlying node (ghosts themselve have no content).
 func ContentHash(nd Node) (h.Hash, error) {
 	switch nd.Type() {
-	case NodeTypeDirectory, NodeTypeCommit, NodeTypeFile:
+	case NodeTypeDirectory, NodeTypeCommit, NodeTypeFile, NodeTypeSymlink:
 		return nd.ContentHash(), nil
 	case NodeTypeGhost:
 		ghost, ok := nd.(*Ghost)
@@ -331,6 +567,13 @@ func ContentHash(nd Node) (h.Hash, error) {
 			}
 
 			return oldDirectory.ContentHash(), nil
+		case NodeTypeSymlink:
+			oldSymlink, err := ghost.OldSymlink()
+			if err != nil {
+				return nil, err
+			}
+
+			return oldSymlink.ContentHash(), nil
 		}
 	}
 
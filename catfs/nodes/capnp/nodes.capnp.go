@@ -12,17 +12,18 @@ import (
 // Commit is a set of changes to nodes
 type Commit struct{ capnp.Struct }
 type Commit_merge Commit
+type Commit_signature Commit
 
 // Commit_TypeID is the unique identifier for the type Commit.
 const Commit_TypeID = 0x8da013c66e545daf
 
 func NewCommit(s *capnp.Segment) (Commit, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 6})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 9})
 	return Commit{st}, err
 }
 
 func NewRootCommit(s *capnp.Segment) (Commit, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 6})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 9})
 	return Commit{st}, err
 }
 
@@ -145,12 +146,61 @@ func (s Commit_merge) SetHead(v []byte) error {
 	return s.Struct.SetData(5, v)
 }
 
+func (s Commit_merge) Via() (string, error) {
+	p, err := s.Struct.Ptr(8)
+	return p.Text(), err
+}
+
+func (s Commit_merge) HasVia() bool {
+	p, err := s.Struct.Ptr(8)
+	return p.IsValid() || err != nil
+}
+
+func (s Commit_merge) ViaBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(8)
+	return p.TextBytes(), err
+}
+
+func (s Commit_merge) SetVia(v string) error {
+	return s.Struct.SetText(8, v)
+}
+
+func (s Commit) Signature() Commit_signature { return Commit_signature(s) }
+
+func (s Commit_signature) Hash() ([]byte, error) {
+	p, err := s.Struct.Ptr(6)
+	return []byte(p.Data()), err
+}
+
+func (s Commit_signature) HasHash() bool {
+	p, err := s.Struct.Ptr(6)
+	return p.IsValid() || err != nil
+}
+
+func (s Commit_signature) SetHash(v []byte) error {
+	return s.Struct.SetData(6, v)
+}
+
+func (s Commit_signature) Data() ([]byte, error) {
+	p, err := s.Struct.Ptr(7)
+	return []byte(p.Data()), err
+}
+
+func (s Commit_signature) HasData() bool {
+	p, err := s.Struct.Ptr(7)
+	return p.IsValid() || err != nil
+}
+
+func (s Commit_signature) SetData(v []byte) error {
+	return s.Struct.SetData(7, v)
+}
+
 // Commit_List is a list of Commit.
 type Commit_List struct{ capnp.List }
 
 // NewCommit creates a new list of Commit.
 func NewCommit_List(s *capnp.Segment, sz int32) (Commit_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 6}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 9}, sz)
 	return Commit_List{l}, err
 }
 
@@ -181,6 +231,18 @@ func (p Commit_merge_Promise) Struct() (Commit_merge, error) {
 	return Commit_merge{s}, err
 }
 
+func (p Commit_Promise) Signature() Commit_signature_Promise {
+	return Commit_signature_Promise{p.Pipeline}
+}
+
+// Commit_signature_Promise is a wrapper for a Commit_signature promised by a client call.
+type Commit_signature_Promise struct{ *capnp.Pipeline }
+
+func (p Commit_signature_Promise) Struct() (Commit_signature, error) {
+	s, err := p.Pipeline.Struct()
+	return Commit_signature{s}, err
+}
+
 // A single directory entry
 type DirEntry struct{ capnp.Struct }
 
@@ -410,12 +472,12 @@ type File struct{ capnp.Struct }
 const File_TypeID = 0x8ea7393d37893155
 
 func NewFile(s *capnp.Segment) (File, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 2})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 3})
 	return File{st}, err
 }
 
 func NewRootFile(s *capnp.Segment) (File, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 2})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 3})
 	return File{st}, err
 }
 
@@ -486,12 +548,37 @@ func (s File) SetIsRaw(v bool) {
 	s.Struct.SetBit(128, v)
 }
 
+func (s File) Chunks() (Chunk_List, error) {
+	p, err := s.Struct.Ptr(2)
+	return Chunk_List{List: p.List()}, err
+}
+
+func (s File) HasChunks() bool {
+	p, err := s.Struct.Ptr(2)
+	return p.IsValid() || err != nil
+}
+
+func (s File) SetChunks(v Chunk_List) error {
+	return s.Struct.SetPtr(2, v.List.ToPtr())
+}
+
+// NewChunks sets the chunks field to a newly
+// allocated Chunk_List, preferring placement in s's segment.
+func (s File) NewChunks(n int32) (Chunk_List, error) {
+	l, err := NewChunk_List(s.Struct.Segment(), n)
+	if err != nil {
+		return Chunk_List{}, err
+	}
+	err = s.Struct.SetPtr(2, l.List.ToPtr())
+	return l, err
+}
+
 // File_List is a list of File.
 type File_List struct{ capnp.List }
 
 // NewFile creates a new list of File.
 func NewFile_List(s *capnp.Segment, sz int32) (File_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 24, PointerCount: 2}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 24, PointerCount: 3}, sz)
 	return File_List{l}, err
 }
 
@@ -513,6 +600,96 @@ func (p File_Promise) Struct() (File, error) {
 }
 
 // Ghost indicates that a certain node was at this path once
+// Symlink is a node that points to another path
+type Symlink struct{ capnp.Struct }
+
+// Symlink_TypeID is the unique identifier for the type Symlink.
+const Symlink_TypeID = 0xf52e382104eb49c2
+
+func NewSymlink(s *capnp.Segment) (Symlink, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return Symlink{st}, err
+}
+
+func NewRootSymlink(s *capnp.Segment) (Symlink, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return Symlink{st}, err
+}
+
+func ReadRootSymlink(msg *capnp.Message) (Symlink, error) {
+	root, err := msg.RootPtr()
+	return Symlink{root.Struct()}, err
+}
+
+func (s Symlink) String() string {
+	str, _ := text.Marshal(0xf52e382104eb49c2, s.Struct)
+	return str
+}
+
+func (s Symlink) Target() (string, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.Text(), err
+}
+
+func (s Symlink) HasTarget() bool {
+	p, err := s.Struct.Ptr(0)
+	return p.IsValid() || err != nil
+}
+
+func (s Symlink) TargetBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s Symlink) SetTarget(v string) error {
+	return s.Struct.SetText(0, v)
+}
+
+func (s Symlink) Parent() (string, error) {
+	p, err := s.Struct.Ptr(1)
+	return p.Text(), err
+}
+
+func (s Symlink) HasParent() bool {
+	p, err := s.Struct.Ptr(1)
+	return p.IsValid() || err != nil
+}
+
+func (s Symlink) ParentBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(1)
+	return p.TextBytes(), err
+}
+
+func (s Symlink) SetParent(v string) error {
+	return s.Struct.SetText(1, v)
+}
+
+// Symlink_List is a list of Symlink.
+type Symlink_List struct{ capnp.List }
+
+// NewSymlink creates a new list of Symlink.
+func NewSymlink_List(s *capnp.Segment, sz int32) (Symlink_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
+	return Symlink_List{l}, err
+}
+
+func (s Symlink_List) At(i int) Symlink { return Symlink{s.List.Struct(i)} }
+
+func (s Symlink_List) Set(i int, v Symlink) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s Symlink_List) String() string {
+	str, _ := text.MarshalList(0xf52e382104eb49c2, s.List)
+	return str
+}
+
+// Symlink_Promise is a wrapper for a Symlink promised by a client call.
+type Symlink_Promise struct{ *capnp.Pipeline }
+
+func (p Symlink_Promise) Struct() (Symlink, error) {
+	s, err := p.Pipeline.Struct()
+	return Symlink{s}, err
+}
+
 type Ghost struct{ capnp.Struct }
 type Ghost_Which uint16
 
@@ -520,10 +697,11 @@ const (
 	Ghost_Which_commit    Ghost_Which = 0
 	Ghost_Which_directory Ghost_Which = 1
 	Ghost_Which_file      Ghost_Which = 2
+	Ghost_Which_symlink   Ghost_Which = 3
 )
 
 func (w Ghost_Which) String() string {
-	const s = "commitdirectoryfile"
+	const s = "commitdirectoryfilesymlink"
 	switch w {
 	case Ghost_Which_commit:
 		return s[0:6]
@@ -531,6 +709,8 @@ func (w Ghost_Which) String() string {
 		return s[6:15]
 	case Ghost_Which_file:
 		return s[15:19]
+	case Ghost_Which_symlink:
+		return s[19:26]
 
 	}
 	return "Ghost_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
@@ -688,6 +868,39 @@ func (s Ghost) NewFile() (File, error) {
 	return ss, err
 }
 
+func (s Ghost) Symlink() (Symlink, error) {
+	if s.Struct.Uint16(8) != 3 {
+		panic("Which() != symlink")
+	}
+	p, err := s.Struct.Ptr(1)
+	return Symlink{Struct: p.Struct()}, err
+}
+
+func (s Ghost) HasSymlink() bool {
+	if s.Struct.Uint16(8) != 3 {
+		return false
+	}
+	p, err := s.Struct.Ptr(1)
+	return p.IsValid() || err != nil
+}
+
+func (s Ghost) SetSymlink(v Symlink) error {
+	s.Struct.SetUint16(8, 3)
+	return s.Struct.SetPtr(1, v.Struct.ToPtr())
+}
+
+// NewSymlink sets the symlink field to a newly
+// allocated Symlink struct, preferring placement in s's segment.
+func (s Ghost) NewSymlink() (Symlink, error) {
+	s.Struct.SetUint16(8, 3)
+	ss, err := NewSymlink(s.Struct.Segment())
+	if err != nil {
+		return Symlink{}, err
+	}
+	err = s.Struct.SetPtr(1, ss.Struct.ToPtr())
+	return ss, err
+}
+
 // Ghost_List is a list of Ghost.
 type Ghost_List struct{ capnp.List }
 
@@ -726,6 +939,10 @@ func (p Ghost_Promise) File() File_Promise {
 	return File_Promise{Pipeline: p.Pipeline.GetPipeline(1)}
 }
 
+func (p Ghost_Promise) Symlink() Symlink_Promise {
+	return Symlink_Promise{Pipeline: p.Pipeline.GetPipeline(1)}
+}
+
 // Node is a node in the merkle dag of brig
 type Node struct{ capnp.Struct }
 type Node_Which uint16
@@ -735,10 +952,11 @@ const (
 	Node_Which_directory Node_Which = 1
 	Node_Which_file      Node_Which = 2
 	Node_Which_ghost     Node_Which = 3
+	Node_Which_symlink   Node_Which = 4
 )
 
 func (w Node_Which) String() string {
-	const s = "commitdirectoryfileghost"
+	const s = "commitdirectoryfileghostsymlink"
 	switch w {
 	case Node_Which_commit:
 		return s[0:6]
@@ -748,6 +966,8 @@ func (w Node_Which) String() string {
 		return s[15:19]
 	case Node_Which_ghost:
 		return s[19:24]
+	case Node_Which_symlink:
+		return s[24:31]
 
 	}
 	return "Node_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
@@ -757,12 +977,12 @@ func (w Node_Which) String() string {
 const Node_TypeID = 0xa629eb7f7066fae3
 
 func NewNode(s *capnp.Segment) (Node, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 7})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 8})
 	return Node{st}, err
 }
 
 func NewRootNode(s *capnp.Segment) (Node, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 7})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 8})
 	return Node{st}, err
 }
 
@@ -1004,6 +1224,39 @@ func (s Node) NewGhost() (Ghost, error) {
 	return ss, err
 }
 
+func (s Node) Symlink() (Symlink, error) {
+	if s.Struct.Uint16(8) != 4 {
+		panic("Which() != symlink")
+	}
+	p, err := s.Struct.Ptr(5)
+	return Symlink{Struct: p.Struct()}, err
+}
+
+func (s Node) HasSymlink() bool {
+	if s.Struct.Uint16(8) != 4 {
+		return false
+	}
+	p, err := s.Struct.Ptr(5)
+	return p.IsValid() || err != nil
+}
+
+func (s Node) SetSymlink(v Symlink) error {
+	s.Struct.SetUint16(8, 4)
+	return s.Struct.SetPtr(5, v.Struct.ToPtr())
+}
+
+// NewSymlink sets the symlink field to a newly
+// allocated Symlink struct, preferring placement in s's segment.
+func (s Node) NewSymlink() (Symlink, error) {
+	s.Struct.SetUint16(8, 4)
+	ss, err := NewSymlink(s.Struct.Segment())
+	if err != nil {
+		return Symlink{}, err
+	}
+	err = s.Struct.SetPtr(5, ss.Struct.ToPtr())
+	return ss, err
+}
+
 func (s Node) BackendHash() ([]byte, error) {
 	p, err := s.Struct.Ptr(6)
 	return []byte(p.Data()), err
@@ -1018,12 +1271,77 @@ func (s Node) SetBackendHash(v []byte) error {
 	return s.Struct.SetData(6, v)
 }
 
+func (s Node) Mode() uint32 {
+	return s.Struct.Uint32(12)
+}
+
+func (s Node) SetMode(v uint32) {
+	s.Struct.SetUint32(12, v)
+}
+
+func (s Node) Uid() uint32 {
+	return s.Struct.Uint32(16)
+}
+
+func (s Node) SetUid(v uint32) {
+	s.Struct.SetUint32(16, v)
+}
+
+func (s Node) Gid() uint32 {
+	return s.Struct.Uint32(20)
+}
+
+func (s Node) SetGid(v uint32) {
+	s.Struct.SetUint32(20, v)
+}
+
+func (s Node) HasOwner() bool {
+	return s.Struct.Bit(80)
+}
+
+func (s Node) SetHasOwner(v bool) {
+	s.Struct.SetBit(80, v)
+}
+
+func (s Node) HasMode() bool {
+	return s.Struct.Bit(81)
+}
+
+func (s Node) SetHasMode(v bool) {
+	s.Struct.SetBit(81, v)
+}
+
+func (s Node) Xattrs() (XAttr_List, error) {
+	p, err := s.Struct.Ptr(7)
+	return XAttr_List{List: p.List()}, err
+}
+
+func (s Node) HasXattrs() bool {
+	p, err := s.Struct.Ptr(7)
+	return p.IsValid() || err != nil
+}
+
+func (s Node) SetXattrs(v XAttr_List) error {
+	return s.Struct.SetPtr(7, v.List.ToPtr())
+}
+
+// NewXattrs sets the xattrs field to a newly
+// allocated XAttr_List, preferring placement in s's segment.
+func (s Node) NewXattrs(n int32) (XAttr_List, error) {
+	l, err := NewXAttr_List(s.Struct.Segment(), n)
+	if err != nil {
+		return XAttr_List{}, err
+	}
+	err = s.Struct.SetPtr(7, l.List.ToPtr())
+	return l, err
+}
+
 // Node_List is a list of Node.
 type Node_List struct{ capnp.List }
 
 // NewNode creates a new list of Node.
 func NewNode_List(s *capnp.Segment, sz int32) (Node_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 7}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 24, PointerCount: 8}, sz)
 	return Node_List{l}, err
 }
 
@@ -1060,96 +1378,317 @@ func (p Node_Promise) Ghost() Ghost_Promise {
 	return Ghost_Promise{Pipeline: p.Pipeline.GetPipeline(5)}
 }
 
-const schema_9195d073cb5c5953 = "x\xda\xb4\x96\xefk\x14\xd7\x1a\xc7\x9f\xef9\xb3;Y" +
-	"\xd1\xbb\xd9;\x11D\\\xf7 \x0aQ\x12M\\/W" +
-	"\x83\x17\x8d\xc6k\xcc\xcd\x95\x1cW\xb9*za\xdc=" +
-	"\xd9\x1d\xdc\x9d\x093cc\xa4E[\x14\xb45E\xa9" +
-	"\x82BBm\x89\xfd\x01-m\xff\x80\xd2\x1f`)\xed" +
-	"\x9b\xd2\x17-\xed\xcb\xb6/j\x0b}]\xda\xea\x94\xb3" +
-	"\xbf&\x86\xb4Ji\xdfe\x9e\xe7\xfc\xf8\x9e\xcf\xf3<" +
-	"\xdfl\xdf\xcfl'\xebOx\x8cH\xaeJ$\xa3\xef" +
-	"\xfe>\xf3\xed\xe7\xdd\x1f\x9e#\xb9\x1a,*\x1c9\xf6" +
-	"q\xf0\xc9\xf5\xab\xb4\x87\x99\x1cF>\x8b5\xb0za" +
-	"Z\xbd\xc8\xe5\x1d\xe4@\x88fs\xff\x99|\xec\x87\xe5" +
-	"\xcfPf5\xe2\x0d\x09f\x12\xe5/\xb0\x01X\xd7\x99" +
-	"i]g9\xeb=6I\x88\xde8~\xd0\xfd\xc0\xba" +
-	"5\xad/\x98\xbf>\xa9\xd7g\xf9\x06X\xbd\xdc\xb4z" +
-	"y.\x7f\x9c\xffO\x9f\x7f\xa8\xff\xd2?\xff\xb5\xed\xe5" +
-	"g\xf5\x06\xbe\xf0\x82ic%\xacY\xc3\xb4f\x8d\\" +
-	"\xfe\x8eQ\x17\xf4\xf5O\xe3\x13g\xbf_\xff\xd2\xc2'" +
-	"\x98\xa6\x01#\xffEb%\xac\xbb\x09\xd3\xba\x9b\xc8\xe5" +
-	"\xb3I\x8f\x11\xa2\xb9oF\xbfL\xcf\xfd\xf8\x0e\xc9u" +
-	"\x98\xa7py\xd2\x04Q\xfev\xc7Q\x10\xac\xb7:\xb4" +
-	"|\xcc<U\xed;2\xfa\xd5\x82\xc3\x13\\\xabY\x96" +
-	"\xda\x05+\x9b2\xadl*\x97?\x94\xaa\xab)\xda\xe1" +
-	"x\xb0\xc9\xf5xI\x05\x9b\x8a\xf6\x84;\xb1\xc9\xf5J" +
-	"*\xd8X\xff{`o\xc5\xf4\x82p\x0c\x90\x06X\xf4" +
-	"\xff\xe7\x9e\x97o\x7f\xf6\xf4\x1d\x92\x06\xc3`\x0f\xb0\x94" +
-	"\xa8\x1f\x9f\"\xda[\xf1\x82P8n\xb2\xe4\x14\xedP" +
-	"\x05\"\xac\xd8\xa1\xb0EQ\xf9\xa1\xed\xb8B\x1f)&" +
-	"\xed@\xd8\xa1\x08+N &\xec\xb0\"<\xb7\x08E" +
-	"$\xbb\xb8Ad\x80(\xf3\xc4Q\"\xf98\x87\xbc\xc8" +
-	"\x00tA\xc7.\x1c \x92\xe79\xe4\x15\x86,\x8b\"" +
-	"t\x81\x11e\xa6\x07\x88\xe4E\x0ey\x8d!\xcb\xef\xeb" +
-	"0'\xca\\\xd5\xab\xafp\xc8\x19\x86\xacqO\x87\x0d" +
-	"\xa2\xcc\xcd\x0dD\xf2\x1a\x87\xbc\xc5\x10\x95\xb5\xda}\xae" +
-	"G\xbc\xa4\x90\"\x86\x145\x83cvH\xa8`)1" +
-	",%\xec(z\xb5\x9a\x13\xa23fN@'!*" +
-	"9\xbe*\x86\x9eO\x98Bg\x0c\xbd\x91M\x8f;U" +
-	"\x85\xce\xb83\x9a\x9b\x1e\x82z\xc8\xd9\xe1\xefqC\x7f" +
-	"jq\xda\xab\xea\xb43\xf8(\x1a\x14\x81\xe3\x96\xab\x8a" +
-	"\x89\x96\x8c)\xa1\xf4F\x82\xech\xa3\\\xaf_\xbc\x96" +
-	"C\xf61dZ,{u\xb0\x9bCnaH\xbbv" +
-	"M\xb5\x9e\x9a\xae\xd8A\x05\xcb\x88a\xd9\xc3\x95\xee\xf6" +
-	"\xd2\x9a\xcb\xe2:E\xb3+\xd6 \xda]\xc7'\x1c\x1e" +
-	"\x08[\x04*\x14\xde\xb8(Vl\xb7\xac\x1b\xc4\x13\xae" +
-	"g\x96T@$W\xb4E\xdf\xdc\x15\x97\xa9-zV" +
-	"W\xfa\x06\x87\x9cc\xc80\xd6(\xff\x0b:8\xc3!" +
-	"_a\xc8p\xde(\xfem\xfd\xbc[\x1c\xf25\x06\x18" +
-	"\x8d\xca\xbf\xba\x99H\xceq\xc87\x19\x90\xc0\xbci\xca" +
-	"\xbc\xbe\x99\xd8\xd9\x9a\x0a\x02\xbb\xdc\x06\xb1\xc3>\x15V" +
-	"<\xbf\xfd9a\xfb\xca\x0d[d\xd2\xbe\xe7\xb5?r" +
-	"\x8e[R\xa7\x91 \x86\x04!WS~Y=\x0c\xdd" +
-	"\xbf\x1d^U\x8b\x83[\xd1,\xf0\xfb\xd1\xa0\xa8*{" +
-	"\\\xb8LO\x8d\xe3\x8a\xb0\xa2\xc4\x7f\x87\x06\xf7\xd2\x83" +
-	"\xb3\xa2\x1f{\x9aC\x9e\x9f\xc7\xeaI=@\xe78\xe4" +
-	"e\x064Q]\x1a\x88\x07(\xc3\x9bs2\xbd&\x1e" +
-	"\x9f\x8cq\xae\xc1\xea\xaafu\x99C\xde`H\x07\xce" +
-	"\x99x:\x8av\xb1\xa2J\x05\x87\xf8\x19\xd5zq\x8b" +
-	"M\x13\x95yRM\xc5h\x82\x03\xf6$@\x0c\x8f`" +
-	"2\xfbubq(k\x9b\xdd4\x82h\x7f\x9dF " +
-	"\x0c\xbba'M05\xe5\x9f\xac*Q\xb2\xcb\xba\xbd" +
-	"N\xf8N\x99 {Z\x94\xacu\xd8@T\x10\xe0(" +
-	"\xf4 \x06e\xad\xc7\x08Q\xa1[\xc7\xb7 \xee+\xab" +
-	"\x1f\xbb\x88\x0a=:\xbe\x15\x0cht\x96\xf5\x0fl&" +
-	"*\xf4\xe9\xf0v\xbd\xdc\xe0ub\xd66\x9c *l" +
-	"\xd5\xf1!\x1dO\x18]H\x10Y\x83\xf5k\xb7\xeb\xf8" +
-	"0\x18\xb2\xc9(Jt!Id\xed\xc1\x00Qa\xa7" +
-	"\xce\x8c\xea\x8cy_gL\"k\x1f\x0e\x10\x15\x86u" +
-	"\xe6\xa0\xcet\xdc\xd3\x99\x0e\"K\xd6O\x1b\xd5\x99\xc3" +
-	":\x93\xfaEgRD\xd6\xa1\xba\xae1\x9d9\xa6\xef" +
-	"_\x92\xec\xc2\x12\"\xebH]\xd7a\x1d/a\xc1\xac" +
-	"G\xa1\xaf\xd4\xb0\x1dT\x88\xa8U\xb0\xb35\xaft\xd0" +
-	"\x89\xd7\xe4\x1c\xcd8.\xbf\xe7\x86\xca\x0d\x87\xc9\x9cg" +
-	"\x13\xe9S\x81\xf2\xff\x1a\xaf\xcc\xd5\xdd\x18\x9d\xf1\xff\xfb" +
-	"\xe6a'\xec\xe2I\xe5\x96\x1e\x14\xd2\xee/\xe3\xb7\xfc" +
-	"JK\xdbXS>/+m\x91\x9d\x8d*-\xf0\xc8" +
-	"F\x81\x1e\xf4\xc8I'\xac\xc4\x1e\xa9\xec\xd2\xa3\xde9" +
-	"\xd4\xb2fZ\xbc\xb1\xbb\x9b\x8d\xfd\"\xa2\xd6\xd2\xc4\x94" +
-	"\xd0\x9cm\xc7\x0d\x84\xe7*\xe1\xf9\xa2\xe6\xf9\xaa\xed\xf2" +
-	"\x8e\x0atl\xdc1\xabu\xdb\xfc\xd3\xac`\xa49\xf5" +
-	"\xdaJ\x0d\xd6\xb0\x82\xdb#M\xdb|\xf7\x0fXAT" +
-	"\xac8\xd5\x92\xaf\\\xdda\x7f#\x8cq\xa03\xfe%" +
-	"F\xd0\xc1VS\x05\xbf\xb7\xe8\xd7\x00\x00\x00\xff\xff*" +
-	"\xb6Z]"
+func (p Node_Promise) Symlink() Symlink_Promise {
+	return Symlink_Promise{Pipeline: p.Pipeline.GetPipeline(5)}
+}
+
+// A single user-defined extended attribute
+type XAttr struct{ capnp.Struct }
+
+// XAttr_TypeID is the unique identifier for the type XAttr.
+const XAttr_TypeID = 0x8e91935769efa88b
+
+func NewXAttr(s *capnp.Segment) (XAttr, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return XAttr{st}, err
+}
+
+func NewRootXAttr(s *capnp.Segment) (XAttr, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return XAttr{st}, err
+}
+
+func ReadRootXAttr(msg *capnp.Message) (XAttr, error) {
+	root, err := msg.RootPtr()
+	return XAttr{root.Struct()}, err
+}
+
+func (s XAttr) String() string {
+	str, _ := text.Marshal(0x8e91935769efa88b, s.Struct)
+	return str
+}
+
+func (s XAttr) Name() (string, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.Text(), err
+}
+
+func (s XAttr) HasName() bool {
+	p, err := s.Struct.Ptr(0)
+	return p.IsValid() || err != nil
+}
+
+func (s XAttr) NameBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s XAttr) SetName(v string) error {
+	return s.Struct.SetText(0, v)
+}
+
+func (s XAttr) Value() ([]byte, error) {
+	p, err := s.Struct.Ptr(1)
+	return []byte(p.Data()), err
+}
+
+func (s XAttr) HasValue() bool {
+	p, err := s.Struct.Ptr(1)
+	return p.IsValid() || err != nil
+}
+
+func (s XAttr) SetValue(v []byte) error {
+	return s.Struct.SetData(1, v)
+}
+
+// XAttr_List is a list of XAttr.
+type XAttr_List struct{ capnp.List }
+
+// NewXAttr creates a new list of XAttr.
+func NewXAttr_List(s *capnp.Segment, sz int32) (XAttr_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
+	return XAttr_List{l}, err
+}
+
+func (s XAttr_List) At(i int) XAttr { return XAttr{s.List.Struct(i)} }
+
+func (s XAttr_List) Set(i int, v XAttr) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s XAttr_List) String() string {
+	str, _ := text.MarshalList(0x8e91935769efa88b, s.List)
+	return str
+}
+
+// XAttr_Promise is a wrapper for a XAttr promised by a client call.
+type XAttr_Promise struct{ *capnp.Pipeline }
+
+func (p XAttr_Promise) Struct() (XAttr, error) {
+	s, err := p.Pipeline.Struct()
+	return XAttr{s}, err
+}
+
+// A part of a file that is stored as separate object in the backend
+type Chunk struct{ capnp.Struct }
+
+// Chunk_TypeID is the unique identifier for the type Chunk.
+const Chunk_TypeID = 0xbc5ccb3176996e4c
+
+func NewChunk(s *capnp.Segment) (Chunk, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 2})
+	return Chunk{st}, err
+}
+
+func NewRootChunk(s *capnp.Segment) (Chunk, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 2})
+	return Chunk{st}, err
+}
+
+func ReadRootChunk(msg *capnp.Message) (Chunk, error) {
+	root, err := msg.RootPtr()
+	return Chunk{root.Struct()}, err
+}
+
+func (s Chunk) String() string {
+	str, _ := text.Marshal(0xbc5ccb3176996e4c, s.Struct)
+	return str
+}
+
+func (s Chunk) BackendHash() ([]byte, error) {
+	p, err := s.Struct.Ptr(0)
+	return []byte(p.Data()), err
+}
+
+func (s Chunk) HasBackendHash() bool {
+	p, err := s.Struct.Ptr(0)
+	return p.IsValid() || err != nil
+}
+
+func (s Chunk) SetBackendHash(v []byte) error {
+	return s.Struct.SetData(0, v)
+}
+
+func (s Chunk) Key() ([]byte, error) {
+	p, err := s.Struct.Ptr(1)
+	return []byte(p.Data()), err
+}
+
+func (s Chunk) HasKey() bool {
+	p, err := s.Struct.Ptr(1)
+	return p.IsValid() || err != nil
+}
+
+func (s Chunk) SetKey(v []byte) error {
+	return s.Struct.SetData(1, v)
+}
+
+func (s Chunk) Size() uint64 {
+	return s.Struct.Uint64(0)
+}
+
+func (s Chunk) SetSize(v uint64) {
+	s.Struct.SetUint64(0, v)
+}
+
+// Chunk_List is a list of Chunk.
+type Chunk_List struct{ capnp.List }
+
+// NewChunk creates a new list of Chunk.
+func NewChunk_List(s *capnp.Segment, sz int32) (Chunk_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 2}, sz)
+	return Chunk_List{l}, err
+}
+
+func (s Chunk_List) At(i int) Chunk { return Chunk{s.List.Struct(i)} }
+
+func (s Chunk_List) Set(i int, v Chunk) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s Chunk_List) String() string {
+	str, _ := text.MarshalList(0xbc5ccb3176996e4c, s.List)
+	return str
+}
+
+// Chunk_Promise is a wrapper for a Chunk promised by a client call.
+type Chunk_Promise struct{ *capnp.Pipeline }
+
+func (p Chunk_Promise) Struct() (Chunk, error) {
+	s, err := p.Pipeline.Struct()
+	return Chunk{s}, err
+}
+
+const schema_9195d073cb5c5953 = "x\xda\xb4W}\x88\\W\x15?\xe7\xde7\xf3v\xdb" +
+	"\x99\xccL\xef\x04\xb2%\xdb\xb9]\"$!_\x9b\x8d" +
+	"\x18\x17C\xb2I\xd6|\x98\xd4\xdc\x9dh\x93R\x85\xb7" +
+	"\xf3\xee\xce{\xee\xce{\xeb{o\xb3\xd9\x80$\x91\xfe" +
+	"S\xdbFK\x15,$\x18%\xd5\x04\x1a\xdaB\x0b\xad" +
+	"\xa4\x18\x8b\x95h\x15E\xa8\xa2\xd0?\xac\x0dX\xebG" +
+	"A\x8b\x8d&}r\xde|\xbc\xc9\xb2\xe9\x06\xa1\xff\xcd" +
+	"\xfb\x9d\xfb\xee\xfb\xdd\xdf9\xe7w\xcfl8klc" +
+	"\x83\x99_q\x00\xb5&\x93\x8d\xffr\xd7\xe9?\xffn" +
+	"\xe5\x95\x13\xa0\xeeA\x16W\x0f?\xf8Z\xf8\xebo=" +
+	"\x0e\xa3\xcc4\xd0\x18\xfa%\x0e\xa0x\x03M\xf1\x06V" +
+	"\x86\xfa\xd8\xfd\x08\x18\x9f\xa9|f\xf6\xc8\xdf\x97>\x02" +
+	"\xa5{0}!\xc3L\x80\xa1/\xf3a\x14'\xb9)" +
+	"N\xf2\x8a\xb8\xc8g\x01\xe3g\xbep\xd0\xfb\xa98\xfb" +
+	"\x18}\xa0{}/\xad\xef5V\xa3\xe83L\xd1g" +
+	"T\x86\xf6\x18_\xa7\xfd\x1f9\xff\x0f\xf7\xfe'\x1e?" +
+	"\xb5\xd0\xfeg2\x03(.fLq1S\x11\x7f\xcc" +
+	"\xd0\xfe\x9f\x1b|\xf8\x13[>\xf9\x83S\xb4?\xefZ" +
+	"\xcfi\xfd\x96\xec\xdd(\xf6gM\xb1?[\x19\x9a\xc9" +
+	"&\xfc\xff\xf4\x9f\x89\xe9\xe3\xef\xac\xfa\xfe\xbc\x17F{" +
+	"\xcc\x0c\x1aC\xaf\x9bw\xa3\xb8j\x9a\xe2\xaaY\x19\xea" +
+	"\xeb\xf97\x07\x8c\xf7yO\x1e\x19|\xed\xc1K\xf3\x8f" +
+	"\x90P:\x9c\x1b@\xe1\xe6L\xe1\xe6*\xe2B\xee\x19" +
+	"\xc0\xf8\xdc[\xfb\xfeP8\xf7\xfe\x8f@}\x0c\xbb\x04" +
+	"X\xdak\"\xc0\xd0h\xfe\x01\x04\x14*OK\xf1\xf4" +
+	"W\xa76\x1c\xde\xf7\xe6<\xf9\x9b\xec\xdf\xceoGq" +
+	"-o\x8ak\xf9\xca\xd0\xe0\x92\x0a\xb1?e\x7f>\xff" +
+	"\xfb7\x7f\xf8\xcf\x85\xf7n\x14\x8e\xd1\xdes\x05R\xe6" +
+	"\x95=\xef\x18\xf7n^\xf7\xdeBB\xbe^\xd8\x88\xe2" +
+	"j\xc1\x14W\x0b\x15\xd1W\xa4\xe55+\x9a\x08\xd7{" +
+	">\xb7u\xb8\xbefM{\xd3\xeb=\xdf\xd6\xe1\xba\xe4" +
+	"\xf7\xf0.\xc7\xf4\xc3\xe8\x00\xa22\x90\xc5_|\xe2;" +
+	"\xea\xe5\xdf~\xedUP\x06\xc3\x915\x889\x80A\xfc" +
+	"\x0d\xc6\xbb\x1c?\x8c\xa4\xebem\xb7fE:\x94\x91" +
+	"cE\xd2\x925\x1dD\x96\xebI\xdaR\xceZ\xa1\xb4" +
+	"\"\x199n(\xa7\xad\xc8\x91\xbeWC\x0d\xa0\x96q" +
+	"\x03\xc0@\x80\xd2\x93\x0f\x00\xa8osT\xe7\x18\"\x96" +
+	"\x91\xb0\xef\x8e\x01\xa8\xb3\x1c\xd5\xd3\x0c\xfbY\x1cc\x19" +
+	"\x19@\xe9\xc20\x80:\xc7Q=\xcb\xb0\x9f\x7f@0" +
+	"\x07(]\xa4\xd5OsT/2\xec7n\x10l\x00" +
+	"\x94^X\x0d\xa0\x9e\xe5\xa8.1\xec\xcf\\'8\x03" +
+	"Pzi;\x80z\x9e\xa3\xba\xcc0\xae\xd3!\xf6x" +
+	">p[c/0\xec\x85\x16x\xc0\x8a\x00\x1d\xcc\x01" +
+	"\xc3\x1c\xe0\xd6\x9a\xdfh\xb8\x11\x16\xd3D\x00b\x110" +
+	"\xb6\xdd@\xd7\"?\x00\x9c\xc3b\x9a\xe5f\xb40\xe1" +
+	"Ni,\xa6\xa5\xdb\x84\x8f\x87s\x8d)\xd7\x9b\xc4b" +
+	"\x9a\xba\xd6v\x8b\xe4f\xa7\xbb5\x18\xf5\xa2`n\xe1" +
+	"\xf4,O\xd2S\xc2\x9f\xc7#2t\xbd\xfa\x94f\xb2" +
+	"MpNjz\x11P\xf5t\xb4_E\x12\xad\xe0\xa8" +
+	"60,\xb5\xc5_K\xe0J\x8ej\x13\xc3\x82g5" +
+	"t[\x84\x82c\x85\x0e\xe6\x81a~q\xa6;\xfc\x02" +
+	")\xb60O\xd9*\xa3\x01\x8cw$\xc2J\x97\x87\xd2" +
+	"\x92\xa1\x8e\xa4?!k\x8e\xe5\xd5\xa9\xa2|\xe9\xf9\xa6" +
+	"\xadC\x00\xb5\xbcC\xfa\x85\xedi^;\xa4_\x1aN" +
+	"\xb3Zb\xacY//\x13\xf8\"G\xf5\x13\x86%\xce" +
+	"\x9b\xd5\xf2c:\xde%\x8e\xea\x0aC4\x9a\xa5\xf2\xea" +
+	"F\x00u\x99\xa3\xfa\x05C\xcc`Wc\x97~\xb6\x11" +
+	"\x18f\xb1\xab\x1fK\xcf\x8d\x01;\xde\xd0ah\xd5;" +
+	"\xdal\xb5f\"\xc7\x0f:\x8f\xd3V\xa0\xbd\xa8-V" +
+	"!\xf0\xfd\xceC\xc5\xf5l}\x143\xc00\x03Xi" +
+	"\xe8\xa0\xae\xe3\xd0\xad{V4\x13\x00\xea\xc5\x94=4" +
+	"bFQ\xb0\xb0\xb0+Z\xc2\xee\xc5N\x05\x18r&" +
+	"\xd4\xc1Z[O\xb8\x9e\xb6\xa5>\x1ai\xcf\xd6\xb6\xb4" +
+	"\xa2(p\xc7g\"}\x1b\x15\xb1\xf1\x16\x15Q9b" +
+	"M\xcd\xe8\xdb-\x89O\xbb|J/\xcc{Y\xabp" +
+	"_\x89G\xe4\x94\xb6&\xa4\xc7\xc8>\\OF\x8e\x96" +
+	"\xfbw\x8e\xec\x82\x9bM\x83h~\x93\xa3:\xdbE\xf3" +
+	"\x0c9\xc9i\x8e\xea<Cl\x95\xc0S\xc3\xa9\x93\x94" +
+	"x\xcb0.\x0c\xa4>R2N4k\xe0\"\x1d\xf2" +
+	"<G\xf5<\xc3R\x865\xcd\xe2\xb9\xe1\x96\xb5\\a" +
+	"X\x08\xddc\xa9I\xd4\xac\x9a\xa3\xed\xaa\x0b\xfc\x98n" +
+	"\xe7\xb2\x9d\xf5\x96:\xe6\xa4\x9eK\x93\x1e\x8eY\xb3\x88" +
+	"\xc0\x10\xc9N\x9c\x19o2\xc4%\x80\x078b1\xbd" +
+	"x\x00q\xc9\xe2J\xdeG\x81E+\xe0\xbeD\xc2P" +
+	"\x1a\x96\xf4\xba\xd4l\xe8`rJK\xdb\xaaS\xaf\x8d" +
+	"\x07n\x1dP\xednK+\xde\xc6\xd5\x00\xd5\xb7\x90c" +
+	"\xf5]L\xd5\x15\x7f\xc3\xbd\x00\xd5\xbf\x12\xfe>\xa6M" +
+	"&\xde\xc3\xed\x00\xd5w\x09\xbf\x8e\x0c\xb1\xd9f\xe2\x1a" +
+	"n\x04\xa8\xfe\x0b9\x8e1R\x99'*\x8b\x1b8\x0e" +
+	"P\xbdN\xab{\x08\xcf\x18\x89\xd0\"\xc3V\x03\x8c1" +
+	"\x8e\xd5\x1cc\xd8\x9f\x8d\xe3L\x19\xb3\x00\xa2\x97\x0d\x03" +
+	"T\x0d\x8a\x14)b~@\x11\x13@\xe4\xd9\x18@5" +
+	"G\x91e\x14\xe9\xb9A\x91\x1e\x00\xb1\x946\xab\x16)" +
+	"\xb2\x9c\"\xbd\xd7)\xd2\x0b \xfa\x18\xb1*SDR" +
+	"\xe4\x8e\xffR\xe4N\x00\xd1\xcf\xe8\x18\xcb(\xb2\x82\x88" +
+	"\xdd\x99-\xe3\x1d\x00\xe2^F\x84%\xe1k\x08\xcf\xf1" +
+	"2\x09,V%\xdfXA\xf8\x06\xc2\xf3F\x19\xf3\x00" +
+	"b-\x1b\x00\xa8\xae$|\x13\xe1K2e\\\x02 " +
+	"\x06\x13|\x0d\xe1\x9b\x09/\x1c(c\x01@|\x9c\x91" +
+	"\xac\x9b\x08\xdfFxQ\x95\xb1\x08 \xb6$|6\x13" +
+	"\xbe\x93\xf0\x92Y\xc6\x12\x80\x18I\xf4\xf8\x14\xe1\x87\xd8" +
+	"\xbcv\x8c\xa3@\xeb\xddV\xe8\x00@\xbb\xf0\x8e7|" +
+	"\xfb\xa0\xdb\xd5\xb2.\xd5BZ\xc6\xbe\x17i/\xda\x0d" +
+	"f\x97\xb7\x17\xc81>\x9a\xab\xaf\x92\\\xaeXL\xc7" +
+	"\xd1E\xaf\xc4q\xab6\xa9={\x1e\xc5\x06\x9d\xa2\x07" +
+	"\x18\xf6\x00\x9a3\xae\xdd\xf9]O\x7f\xc7\x8e\x15~v" +
+	"\xd6\xd3\x01\x00\xb4[\xef\xb8c\x85\xfb\xe9\xddv+\x1e" +
+	"%#\xecj\xc5\xceTz{\xad\xb8\xc31g\xbc\xc9" +
+	"\x85{qC\xab\x17\xefb\xf1\x88\x9c\xb6\x82H\xfa\xe6" +
+	"\x84\xb4$I\xd3\x1c\x97\xdcP\x86\x91\x1f\x90\x1d\x872" +
+	"\xd4\xd3V`EZ\xfa\xe3_\xd2\xb5\xa8\xdd\xaf\xcd\xf3" +
+	"\xa3\x0d\xa0r\x1d\x03\x1c\x1d\x07P;9\xaa\x03]\x06" +
+	"\xb8\x9f|m7Gu05@EN\xb9\x8f\xa3:" +
+	"\xc4n!e\xb7M\xcd\xf7\xb8\xe6\xd1\x8d[]\xf1T" +
+	"\x18\xeb\x1a:\xe0uM\xdc\x8a\xcdf.\x8d\xd27\xb7" +
+	"qT\xfb\x88\\\xb3\x91K{Vw1f=I?" +
+	"\xde\xc4\xb80\xebFN:kh\xcb\xee0<\xe2Z" +
+	"\xed\xc0b\xa4v\xb6\xc7\x1dX8'+[9\xf9\x1e" +
+	"\xc6\xed\xa5\x999Im`\xb9^(}OK?\x90" +
+	"\x0d?\xd0\x9d\xc9\xc9\xd5!a\x13\xae9\x95\x8c\"\xe5" +
+	"N\x16\xbeBg:\xcaQ=\xd4\x95\x85\x93t\x0d\x9d" +
+	"\xe0\xa8\x1eM\xb3\xf00\xdd#\x0fqT\xdf\xe8\xba\x86" +
+	"\x1e\xdb\x0b\xa0\x1em\xdeW%\x835\xaf\xa1\xa7\xf6\xb6" +
+	"\xee\xa6\xcb\xff\xc7\x8d\x13\xd7\x1cw\xca\x0e\xb4\x07\x00i" +
+	"Ew\xfe\xc7\xb5+\xba\xd9\xf3\xe1\x87/\xba\xad\xdc\x87" +
+	"n}k2\xb7h\x9a!\x8a\xd9\xc4\x99\xe7\x0d\x11f" +
+	"b\xca7\x8f\x95\xdd\x93d\xc1\xb6\"\xebvg\x88\xea" +
+	"\\%\xf1\x89\x0f\xbf\xfc\x02\x8c\xabM?\x91\x86K\x83" +
+	"er\xff%\x1d7\xed\xbb^\x94\x8c\x96\x96\xe7G\x8e" +
+	"\x0e*\xc9\x1f\x13\x80\xee!hx\xa1!h8\xe5\xbf" +
+	"5\xb2\x82\xba\x8e\xe6O{\xad\xc7\xff\x0d\x00_\x9f\xad" +
+	"\x90"
 
 func init() {
 	schemas.Register(schema_9195d073cb5c5953,
 		0x80c828d7e89c12ea,
 		0x8b15ee76774b1f9d,
 		0x8da013c66e545daf,
+		0x8e91935769efa88b,
 		0x8ea7393d37893155,
 		0xa629eb7f7066fae3,
+		0xbc5ccb3176996e4c,
 		0xbff8a40fda4ce4a4,
-		0xe24c59306c829c01)
+		0xe24c59306c829c01,
+		0xf3bae2d90d56648e,
+		0xf52e382104eb49c2)
 }
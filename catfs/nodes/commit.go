@@ -43,6 +43,17 @@ type Commit struct {
 		// head is a reference to the commit we merged with on
 		// the remote side.
 		head h.Hash
+
+		// via tells how the merge was triggered (e.g. "sync" or "push").
+		via string
+	}
+
+	signature struct {
+		// hash is the hash that was signed.
+		hash h.Hash
+
+		// data is the detached signature of hash.
+		data []byte
 	}
 }
 
@@ -121,6 +132,19 @@ func (c *Commit) setCommitAttrs(seg *capnp.Segment) (*capnp_model.Commit, error)
 		return nil, err
 	}
 
+	if err := capmerge.SetVia(c.merge.via); err != nil {
+		return nil, err
+	}
+
+	capsig := capCmt.Signature()
+	if err := capsig.SetHash(c.signature.hash); err != nil {
+		return nil, err
+	}
+
+	if err := capsig.SetData(c.signature.data); err != nil {
+		return nil, err
+	}
+
 	return &capCmt, nil
 }
 
@@ -182,6 +206,22 @@ func (c *Commit) readCommitAttrs(capCmt capnp_model.Commit) error {
 	}
 
 	c.merge.with, err = capMerge.With()
+	if err != nil {
+		return err
+	}
+
+	c.merge.via, err = capMerge.Via()
+	if err != nil {
+		return err
+	}
+
+	capSig := capCmt.Signature()
+	c.signature.hash, err = capSig.Hash()
+	if err != nil {
+		return err
+	}
+
+	c.signature.data, err = capSig.Data()
 	return err
 }
 
@@ -262,6 +302,31 @@ func (c *Commit) MergeMarker() (string, h.Hash) {
 	return c.merge.with, c.merge.head
 }
 
+// SetMergeVia remembers how the merge in this commit was triggered.
+// Only meaningful together with SetMergeMarker.
+func (c *Commit) SetMergeVia(via string) {
+	c.merge.via = via
+}
+
+// MergeVia returns how the merge was triggered or an empty string
+// for commits that are no merges or that were made by older versions.
+func (c *Commit) MergeVia() string {
+	return c.merge.via
+}
+
+// SetSignature remembers `sig` as signature of `hash`.
+// `hash` is usually the hash of the boxed commit itself.
+func (c *Commit) SetSignature(hash h.Hash, sig []byte) {
+	c.signature.hash = hash.Clone()
+	c.signature.data = sig
+}
+
+// Signature returns the signed hash and the signature of this commit.
+// Both are nil if the commit was not signed.
+func (c *Commit) Signature() (h.Hash, []byte) {
+	return c.signature.hash, c.signature.data
+}
+
 // /////////////////// METADATA INTERFACE ///////////////////
 
 // Name will return the hash of the commit.
@@ -274,6 +339,11 @@ func (c *Commit) Message() string {
 	return c.message
 }
 
+// Author returns the owner of the repository that made the commit.
+func (c *Commit) Author() string {
+	return c.author
+}
+
 // Path will return the path of the commit, which will
 func (c *Commit) Path() string {
 	return prefixSlash(path.Join(".snapshots", c.Name()))
@@ -19,6 +19,8 @@ func TestCommit(t *testing.T) {
 	cmt.Base.name = "some commit"
 
 	cmt.SetMergeMarker(AuthorOfStage, h.TestDummy(t, 42))
+	cmt.SetMergeVia("push")
+	cmt.SetSignature(h.TestDummy(t, 23), []byte("signature"))
 
 	if err := cmt.BoxCommit(AuthorOfStage, "Hello"); err != nil {
 		t.Fatalf("Failed to box commit: %v", err)
@@ -73,6 +75,12 @@ func TestCommit(t *testing.T) {
 		t.Fatalf("Person from unmarshaled commit does not equal staging author: %v", person)
 	}
 
+	require.Equal(t, "push", empty.MergeVia())
+
+	sigHash, sig := empty.Signature()
+	require.Equal(t, h.TestDummy(t, 23), sigHash)
+	require.Equal(t, []byte("signature"), sig)
+
 	empty.modTime = cmt.modTime
 	require.Equal(t, empty, cmt)
 }
@@ -3,6 +3,7 @@ package nodes
 import (
 	"errors"
 	"fmt"
+	"os"
 	"path"
 	"sort"
 	"strings"
@@ -556,7 +557,7 @@ func (d *Directory) Copy(inode uint64) ModNode {
 }
 
 func (d *Directory) rehash(lkr Linker, updateContentHash bool) error {
-	newTreeHash := h.Sum([]byte(path.Join(d.parentName, d.name)))
+	newTreeHash := h.Sum([]byte(path.Join(d.parentName, d.name) + d.attrHashSuffix()))
 	newContentHash := h.EmptyInternalHash.Clone()
 	for _, name := range d.order {
 		newTreeHash = newTreeHash.Mix(d.children[name])
@@ -744,6 +745,15 @@ func (d *Directory) NotifyMove(lkr Linker, newParent *Directory, newPath string)
 			if err := childFile.NotifyMove(lkr, nil, newChildPath); err != nil {
 				return err
 			}
+		case NodeTypeSymlink:
+			childSymlink, ok := child.(*Symlink)
+			if !ok {
+				return ie.ErrBadNode
+			}
+
+			if err := childSymlink.NotifyMove(lkr, nil, newChildPath); err != nil {
+				return err
+			}
 		case NodeTypeGhost:
 			childGhost, ok := child.(*Ghost)
 			if !ok {
@@ -784,5 +794,29 @@ func (d *Directory) SetUser(user string) {
 	d.Base.user = user
 }
 
+// SetMode sets the permission bits of the directory and updates its hash.
+func (d *Directory) SetMode(lkr Linker, mode os.FileMode) {
+	d.setMode(mode)
+	d.rehash(lkr, false)
+}
+
+// SetOwner sets the numeric owner of the directory and updates its hash.
+func (d *Directory) SetOwner(lkr Linker, uid, gid uint32) {
+	d.setOwner(uid, gid)
+	d.rehash(lkr, false)
+}
+
+// SetXattr sets the extended attribute `name` of the directory and updates its hash.
+func (d *Directory) SetXattr(lkr Linker, name string, value []byte) {
+	d.setXattr(name, value)
+	d.rehash(lkr, false)
+}
+
+// RemoveXattr removes the extended attribute `name` of the directory and updates its hash.
+func (d *Directory) RemoveXattr(lkr Linker, name string) {
+	d.removeXattr(name)
+	d.rehash(lkr, false)
+}
+
 // Assert that Directory follows the Node interface:
 var _ ModNode = &Directory{}
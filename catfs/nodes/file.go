@@ -2,6 +2,7 @@ package nodes
 
 import (
 	"fmt"
+	"os"
 	"path"
 	"time"
 
@@ -20,6 +21,43 @@ type File struct {
 	parent     string
 	key        []byte
 	isRaw      bool
+
+	// chunks is only set if the content was split into several
+	// backend objects. Otherwise the backend hash points to the content.
+	chunks []Chunk
+}
+
+// Chunk is a part of a file's content that is stored as a separate
+// object in the backend. Each chunk is encoded with its own key.
+type Chunk struct {
+	// BackendHash is the hash of the encoded chunk in the backend.
+	BackendHash h.Hash
+
+	// Key is the key the chunk was encrypted with.
+	Key []byte
+
+	// Size is the decoded size of the chunk.
+	Size uint64
+}
+
+func (ck Chunk) copyChunk() Chunk {
+	return Chunk{
+		BackendHash: ck.BackendHash.Clone(),
+		Key:         append([]byte{}, ck.Key...),
+		Size:        ck.Size,
+	}
+}
+
+// ChunkListHash returns the backend hash of a file consisting of `chunks`.
+// It does not point to any object in the backend, but changes
+// whenever one of the chunks changes.
+func ChunkListHash(chunks []Chunk) h.Hash {
+	data := []byte("chunks:")
+	for _, chunk := range chunks {
+		data = append(data, chunk.BackendHash...)
+	}
+
+	return h.Sum(data)
 }
 
 // NewEmptyFile returns a newly created file under `parent`, named `name`.
@@ -82,6 +120,29 @@ func (f *File) setFileAttrs(seg *capnp.Segment) (*capnp_model.File, error) {
 	capFile.SetSize(f.size)
 	capFile.SetCachedSize(f.cachedSize)
 	capFile.SetIsRaw(f.isRaw)
+
+	if len(f.chunks) == 0 {
+		return &capFile, nil
+	}
+
+	capChunks, err := capFile.NewChunks(int32(len(f.chunks)))
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, chunk := range f.chunks {
+		capChunk := capChunks.At(idx)
+		if err := capChunk.SetBackendHash(chunk.BackendHash); err != nil {
+			return nil, err
+		}
+
+		if err := capChunk.SetKey(chunk.Key); err != nil {
+			return nil, err
+		}
+
+		capChunk.SetSize(chunk.Size)
+	}
+
 	return &capFile, nil
 }
 
@@ -122,7 +183,44 @@ func (f *File) readFileAttrs(capFile capnp_model.File) error {
 	f.size = capFile.Size()
 	f.cachedSize = capFile.CachedSize()
 	f.key, err = capFile.Key()
-	return err
+	if err != nil {
+		return err
+	}
+
+	return f.readChunks(capFile)
+}
+
+func (f *File) readChunks(capFile capnp_model.File) error {
+	f.chunks = nil
+	if !capFile.HasChunks() {
+		return nil
+	}
+
+	capChunks, err := capFile.Chunks()
+	if err != nil {
+		return err
+	}
+
+	for idx := 0; idx < capChunks.Len(); idx++ {
+		capChunk := capChunks.At(idx)
+		backendHash, err := capChunk.BackendHash()
+		if err != nil {
+			return err
+		}
+
+		key, err := capChunk.Key()
+		if err != nil {
+			return err
+		}
+
+		f.chunks = append(f.chunks, Chunk{
+			BackendHash: backendHash,
+			Key:         key,
+			Size:        capChunk.Size(),
+		})
+	}
+
+	return nil
 }
 
 ////////////////// METADATA INTERFACE //////////////////
@@ -133,6 +231,39 @@ func (f *File) Size() uint64 { return f.size }
 // CachedSize returns the number of bytes in the file's backend storage.
 func (f *File) CachedSize() int64 { return f.cachedSize }
 
+// IsChunked returns true if the content is split into several chunks.
+func (f *File) IsChunked() bool { return len(f.chunks) > 0 }
+
+// Chunks returns a copy of the chunk list of the file.
+// It is empty for files that are stored as a single backend object.
+func (f *File) Chunks() []Chunk {
+	if len(f.chunks) == 0 {
+		return nil
+	}
+
+	chunks := make([]Chunk, 0, len(f.chunks))
+	for _, chunk := range f.chunks {
+		chunks = append(chunks, chunk.copyChunk())
+	}
+
+	return chunks
+}
+
+// BackendHashes returns the hashes of all backend objects
+// that are needed to read the content of the file.
+func (f *File) BackendHashes() []h.Hash {
+	if len(f.chunks) == 0 {
+		return []h.Hash{f.backend}
+	}
+
+	hashes := make([]h.Hash, 0, len(f.chunks))
+	for _, chunk := range f.chunks {
+		hashes = append(hashes, chunk.BackendHash)
+	}
+
+	return hashes
+}
+
 ////////////////// ATTRIBUTE SETTERS //////////////////
 
 // SetModTime udates the mod time of the file (i.e. "touch"es it)
@@ -149,6 +280,10 @@ func (f *File) SetIsRaw(isRaw bool) { f.isRaw = isRaw }
 // SetKey updates the key to a new value, taking ownership of the value.
 func (f *File) SetKey(k []byte) { f.key = k }
 
+// SetChunks updates the chunk list, taking ownership of the value.
+// The backend hash needs to be updated separately with SetBackend().
+func (f *File) SetChunks(chunks []Chunk) { f.chunks = chunks }
+
 // SetSize will update the size of the file and update it's mod time.
 func (f *File) SetSize(s uint64) {
 	f.size = s
@@ -179,6 +314,8 @@ func (f *File) Copy(inode uint64) ModNode {
 		cachedSize: f.cachedSize,
 		parent:     f.parent,
 		key:        copyKey,
+		isRaw:      f.isRaw,
+		chunks:     f.Chunks(),
 	}
 }
 
@@ -191,7 +328,7 @@ func (f *File) rehash(lkr Linker, newPath string) {
 		contentHash = h.EmptyInternalHash.Clone()
 	}
 
-	f.tree = h.Sum([]byte(fmt.Sprintf("%s|%s", newPath, contentHash)))
+	f.tree = h.Sum([]byte(fmt.Sprintf("%s|%s%s", newPath, contentHash, f.attrHashSuffix())))
 	lkr.MemIndexSwap(f, oldHash, true)
 }
 
@@ -284,6 +421,30 @@ func (f *File) SetUser(user string) {
 	f.Base.user = user
 }
 
+// SetMode sets the permission bits of the file and updates its hash.
+func (f *File) SetMode(lkr Linker, mode os.FileMode) {
+	f.setMode(mode)
+	f.rehash(lkr, f.Path())
+}
+
+// SetOwner sets the numeric owner of the file and updates its hash.
+func (f *File) SetOwner(lkr Linker, uid, gid uint32) {
+	f.setOwner(uid, gid)
+	f.rehash(lkr, f.Path())
+}
+
+// SetXattr sets the extended attribute `name` of the file and updates its hash.
+func (f *File) SetXattr(lkr Linker, name string, value []byte) {
+	f.setXattr(name, value)
+	f.rehash(lkr, f.Path())
+}
+
+// RemoveXattr removes the extended attribute `name` of the file and updates its hash.
+func (f *File) RemoveXattr(lkr Linker, name string) {
+	f.removeXattr(name)
+	f.rehash(lkr, f.Path())
+}
+
 // Interface check for debugging:
 var _ ModNode = &File{}
 var _ Streamable = &File{}
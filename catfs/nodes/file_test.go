@@ -2,9 +2,11 @@ package nodes
 
 import (
 	"bytes"
+	"os"
 	"testing"
 	"time"
 
+	h "github.com/sahib/brig/util/hashlib"
 	"github.com/stretchr/testify/require"
 	capnp "zombiezen.com/go/capnproto2"
 )
@@ -86,3 +88,142 @@ func TestFile(t *testing.T) {
 	empty.modTime = file.modTime
 	require.Equal(t, empty, file)
 }
+
+func TestFileModeAndOwner(t *testing.T) {
+	lkr := NewMockLinker()
+	root, err := NewEmptyDirectory(lkr, nil, "", "a", 2)
+	require.Nil(t, err)
+	lkr.AddNode(root, true)
+	lkr.MemSetRoot(root)
+
+	file := NewEmptyFile(root, "script.sh", "a", 3)
+	file.SetContent(lkr, []byte{1, 2, 3})
+	lkr.AddNode(file, true)
+
+	require.False(t, file.HasMode())
+	require.Equal(t, DefaultMode(NodeTypeFile), file.Mode())
+	_, _, hasOwner := file.Owner()
+	require.False(t, hasOwner)
+
+	oldHash := file.TreeHash().Clone()
+	file.SetMode(lkr, 0755)
+	require.True(t, file.HasMode())
+	require.Equal(t, os.FileMode(0755), file.Mode())
+	require.False(t, oldHash.Equal(file.TreeHash()))
+
+	// The content does not change by changing the mode:
+	require.Equal(t, []byte{1, 2, 3}, []byte(file.ContentHash()))
+
+	file.SetOwner(lkr, 1000, 100)
+
+	data, err := MarshalNode(file)
+	require.Nil(t, err)
+
+	nd, err := UnmarshalNode(data)
+	require.Nil(t, err)
+
+	empty, ok := nd.(*File)
+	require.True(t, ok)
+	require.Equal(t, os.FileMode(0755), empty.Mode())
+	require.True(t, empty.TreeHash().Equal(file.TreeHash()))
+	require.True(t, SameAttrs(file, empty))
+
+	uid, gid, hasOwner := empty.Owner()
+	require.True(t, hasOwner)
+	require.Equal(t, uint32(1000), uid)
+	require.Equal(t, uint32(100), gid)
+
+	copied := file.Copy(4)
+	require.True(t, SameAttrs(file, copied))
+
+	copied.SetMode(lkr, 0600)
+	require.False(t, SameAttrs(file, copied))
+}
+
+func TestFileXattr(t *testing.T) {
+	lkr := NewMockLinker()
+	root, err := NewEmptyDirectory(lkr, nil, "", "a", 2)
+	require.Nil(t, err)
+	lkr.AddNode(root, true)
+	lkr.MemSetRoot(root)
+
+	file := NewEmptyFile(root, "tagged", "a", 3)
+	file.SetContent(lkr, []byte{1, 2, 3})
+	lkr.AddNode(file, true)
+
+	require.Empty(t, file.ListXattr())
+
+	oldHash := file.TreeHash().Clone()
+	file.SetXattr(lkr, "user.tags", []byte("red,blue"))
+	file.SetXattr(lkr, "user.checksum", []byte{0, 1, 2})
+	require.False(t, oldHash.Equal(file.TreeHash()))
+	require.Equal(t, []string{"user.checksum", "user.tags"}, file.ListXattr())
+
+	data, err := MarshalNode(file)
+	require.Nil(t, err)
+
+	nd, err := UnmarshalNode(data)
+	require.Nil(t, err)
+
+	empty, ok := nd.(*File)
+	require.True(t, ok)
+	require.True(t, empty.TreeHash().Equal(file.TreeHash()))
+	require.True(t, SameXattrs(file, empty))
+
+	value, ok := empty.Xattr("user.tags")
+	require.True(t, ok)
+	require.Equal(t, []byte("red,blue"), value)
+
+	// Copies should not share the underlying storage:
+	copied := file.Copy(4)
+	file.SetXattr(lkr, "user.tags", []byte("green"))
+	value, _ = copied.Xattr("user.tags")
+	require.Equal(t, []byte("red,blue"), value)
+	require.False(t, SameXattrs(file, copied))
+
+	file.RemoveXattr(lkr, "user.tags")
+	file.RemoveXattr(lkr, "user.checksum")
+	require.Empty(t, file.ListXattr())
+	require.True(t, oldHash.Equal(file.TreeHash()))
+}
+
+func TestFileChunks(t *testing.T) {
+	lkr := NewMockLinker()
+	root, err := NewEmptyDirectory(lkr, nil, "", "a", 2)
+	require.Nil(t, err)
+	lkr.AddNode(root, true)
+	lkr.MemSetRoot(root)
+
+	file := NewEmptyFile(root, "big", "a", 3)
+	require.False(t, file.IsChunked())
+
+	chunks := []Chunk{
+		{BackendHash: h.TestDummy(t, 1), Key: []byte{1}, Size: 10},
+		{BackendHash: h.TestDummy(t, 2), Key: []byte{2}, Size: 20},
+	}
+
+	file.SetChunks(chunks)
+	file.SetBackend(lkr, ChunkListHash(chunks))
+	file.SetSize(30)
+	require.True(t, file.IsChunked())
+	require.Equal(t, []h.Hash{h.TestDummy(t, 1), h.TestDummy(t, 2)}, file.BackendHashes())
+
+	data, err := MarshalNode(file)
+	require.Nil(t, err)
+
+	nd, err := UnmarshalNode(data)
+	require.Nil(t, err)
+
+	empty, ok := nd.(*File)
+	require.True(t, ok)
+	require.Equal(t, chunks, empty.Chunks())
+	require.True(t, empty.BackendHash().Equal(ChunkListHash(chunks)))
+
+	// Changing a single chunk changes the backend hash:
+	chunks[1].BackendHash = h.TestDummy(t, 3)
+	require.False(t, empty.BackendHash().Equal(ChunkListHash(chunks)))
+
+	copied, ok := file.Copy(4).(*File)
+	require.True(t, ok)
+	require.Equal(t, file.Chunks(), copied.Chunks())
+}
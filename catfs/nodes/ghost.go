@@ -70,6 +70,17 @@ func (g *Ghost) OldDirectory() (*Directory, error) {
 	return directory, nil
 }
 
+// OldSymlink returns the symlink the ghost was when it still was alive.
+// Returns ErrBadNode when it wasn't a symlink.
+func (g *Ghost) OldSymlink() (*Symlink, error) {
+	symlink, ok := g.ModNode.(*Symlink)
+	if !ok {
+		return nil, ie.ErrBadNode
+	}
+
+	return symlink, nil
+}
+
 func (g *Ghost) String() string {
 	return fmt.Sprintf("<ghost: %s %v>", g.TreeHash(), g.ModNode)
 }
@@ -153,6 +164,21 @@ func (g *Ghost) ToCapnpNode(seg *capnp.Segment, capNd capnp_model.Node) error {
 		if err = capghost.SetDirectory(*capdir); err != nil {
 			return err
 		}
+	case NodeTypeSymlink:
+		symlink, ok := g.ModNode.(*Symlink)
+		if !ok {
+			return ie.ErrBadNode
+		}
+
+		capsymlink, err := symlink.setSymlinkAttrs(seg)
+		if err != nil {
+			return err
+		}
+
+		base = &symlink.Base
+		if err = capghost.SetSymlink(*capsymlink); err != nil {
+			return err
+		}
 	case NodeTypeGhost:
 		panic("Recursive ghosts are not possible")
 	default:
@@ -228,6 +254,20 @@ func (g *Ghost) FromCapnpNode(capNd capnp_model.Node) error {
 		g.ModNode = file
 		g.oldType = NodeTypeFile
 		base = &file.Base
+	case capnp_model.Ghost_Which_symlink:
+		capsymlink, err := capghost.Symlink()
+		if err != nil {
+			return err
+		}
+
+		symlink := &Symlink{}
+		if err := symlink.readSymlinkAttrs(capsymlink); err != nil {
+			return err
+		}
+
+		g.ModNode = symlink
+		g.oldType = NodeTypeSymlink
+		base = &symlink.Base
 	default:
 		return ie.ErrBadNode
 	}
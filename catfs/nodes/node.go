@@ -1,6 +1,7 @@
 package nodes
 
 import (
+	"os"
 	"time"
 
 	capnp_model "github.com/sahib/brig/catfs/nodes/capnp"
@@ -22,6 +23,8 @@ const (
 	NodeTypeCommit
 	// NodeTypeGhost indicates a moved node
 	NodeTypeGhost
+	// NodeTypeSymlink indicates a symbolic link
+	NodeTypeSymlink
 )
 
 var nodeTypeToString = map[NodeType]string{
@@ -29,6 +32,7 @@ var nodeTypeToString = map[NodeType]string{
 	NodeTypeGhost:     "ghost",
 	NodeTypeFile:      "file",
 	NodeTypeDirectory: "directory",
+	NodeTypeSymlink:   "symlink",
 }
 
 func (n NodeType) String() string {
@@ -83,6 +87,21 @@ type Metadatable interface {
 	// can be read from the backend.
 	// It is valid to return nil if the file is empty.
 	BackendHash() h.Hash
+
+	// Mode returns the permission bits of the node.
+	Mode() os.FileMode
+
+	// HasMode tells if Mode() was set explicitly or is just a default.
+	HasMode() bool
+
+	// Owner returns the numeric uid and gid of the node, if any was recorded.
+	Owner() (uid, gid uint32, ok bool)
+
+	// Xattr returns the value of the user defined extended attribute `name`.
+	Xattr(name string) (value []byte, ok bool)
+
+	// ListXattr returns the sorted names of all extended attributes.
+	ListXattr() []string
 }
 
 // Serializable is a thing that can be converted to a capnproto message.
@@ -118,7 +137,7 @@ type Streamable interface {
 }
 
 // Node is a single node in brig's MDAG.
-// It is currently either a Commit, a File or a Directory.
+// It is currently either a Commit, a File, a Directory or a Symlink.
 type Node interface {
 	Metadatable
 	Serializable
@@ -143,6 +162,22 @@ type ModNode interface {
 	// SetUser sets the user that last modified the file
 	SetUser(user string)
 
+	// SetMode sets the permission bits of the node.
+	// The hash of the node changes by this.
+	SetMode(lkr Linker, mode os.FileMode)
+
+	// SetOwner sets the numeric owner of the node.
+	// The hash of the node changes by this.
+	SetOwner(lkr Linker, uid, gid uint32)
+
+	// SetXattr sets the extended attribute `name` to `value`.
+	// The hash of the node changes by this.
+	SetXattr(lkr Linker, name string, value []byte)
+
+	// RemoveXattr removes the extended attribute `name`, if it exists.
+	// The hash of the node changes by this.
+	RemoveXattr(lkr Linker, name string)
+
 	// NotifyMove tells the node that it was moved.
 	// It should be called whenever the path of the node changed.
 	// (i.e. not only the name, but parts of the parent path)
@@ -0,0 +1,279 @@
+package nodes
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	capnp_model "github.com/sahib/brig/catfs/nodes/capnp"
+	h "github.com/sahib/brig/util/hashlib"
+	capnp "zombiezen.com/go/capnproto2"
+)
+
+// Symlink is a node that points to another path.
+// The target is stored as given by the user and is never resolved by catfs;
+// it may be relative, absolute or point to a non-existing path.
+type Symlink struct {
+	Base
+
+	parent string
+	target string
+}
+
+// NewSymlink returns a new symlink under `parent`, named `name`,
+// that points to `target`.
+func NewSymlink(parent *Directory, name, target, user string, inode uint64) *Symlink {
+	sl := &Symlink{
+		Base: Base{
+			name:     name,
+			user:     user,
+			inode:    inode,
+			modTime:  time.Now().Truncate(time.Microsecond),
+			nodeType: NodeTypeSymlink,
+		},
+		parent: parent.Path(),
+		target: target,
+	}
+
+	sl.content = symlinkContentHash(target)
+	sl.tree = symlinkTreeHash(sl.Path(), sl.content, "")
+	return sl
+}
+
+// The target is part of the content hash, so two symlinks with
+// the same target are considered equal in content.
+func symlinkContentHash(target string) h.Hash {
+	return h.Sum([]byte(fmt.Sprintf("symlink:%s", target)))
+}
+
+func symlinkTreeHash(nodePath string, content h.Hash, attrs string) h.Hash {
+	return h.Sum([]byte(fmt.Sprintf("%s|%s%s", nodePath, content, attrs)))
+}
+
+// ToCapnp converts a symlink to a capnp message.
+func (sl *Symlink) ToCapnp() (*capnp.Message, error) {
+	msg, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	capNd, err := capnp_model.NewRootNode(seg)
+	if err != nil {
+		return nil, err
+	}
+
+	return msg, sl.ToCapnpNode(seg, capNd)
+}
+
+// ToCapnpNode converts this node to a serializable capnp proto node.
+func (sl *Symlink) ToCapnpNode(seg *capnp.Segment, capNd capnp_model.Node) error {
+	if err := sl.setBaseAttrsToNode(capNd); err != nil {
+		return err
+	}
+
+	capSymlink, err := sl.setSymlinkAttrs(seg)
+	if err != nil {
+		return err
+	}
+
+	return capNd.SetSymlink(*capSymlink)
+}
+
+func (sl *Symlink) setSymlinkAttrs(seg *capnp.Segment) (*capnp_model.Symlink, error) {
+	capSymlink, err := capnp_model.NewSymlink(seg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := capSymlink.SetParent(sl.parent); err != nil {
+		return nil, err
+	}
+
+	if err := capSymlink.SetTarget(sl.target); err != nil {
+		return nil, err
+	}
+
+	return &capSymlink, nil
+}
+
+// FromCapnp sets all state of `msg` into the symlink.
+func (sl *Symlink) FromCapnp(msg *capnp.Message) error {
+	capNd, err := capnp_model.ReadRootNode(msg)
+	if err != nil {
+		return err
+	}
+
+	return sl.FromCapnpNode(capNd)
+}
+
+// FromCapnpNode converts a serialized node to a normal node.
+func (sl *Symlink) FromCapnpNode(capNd capnp_model.Node) error {
+	if err := sl.parseBaseAttrsFromNode(capNd); err != nil {
+		return err
+	}
+
+	capSymlink, err := capNd.Symlink()
+	if err != nil {
+		return err
+	}
+
+	return sl.readSymlinkAttrs(capSymlink)
+}
+
+func (sl *Symlink) readSymlinkAttrs(capSymlink capnp_model.Symlink) error {
+	var err error
+
+	sl.parent, err = capSymlink.Parent()
+	if err != nil {
+		return err
+	}
+
+	sl.nodeType = NodeTypeSymlink
+	sl.target, err = capSymlink.Target()
+	return err
+}
+
+////////////////// METADATA INTERFACE //////////////////
+
+// Size returns the length of the target, like lstat(2) does.
+func (sl *Symlink) Size() uint64 { return uint64(len(sl.target)) }
+
+// CachedSize returns 0, since a symlink has no content in the backend.
+func (sl *Symlink) CachedSize() int64 { return 0 }
+
+// Target returns the path the symlink points to.
+func (sl *Symlink) Target() string { return sl.target }
+
+////////////////// ATTRIBUTE SETTERS //////////////////
+
+// SetModTime udates the mod time of the symlink.
+func (sl *Symlink) SetModTime(t time.Time) {
+	sl.modTime = t.Truncate(time.Microsecond)
+}
+
+// SetName set the name of the symlink.
+func (sl *Symlink) SetName(n string) { sl.name = n }
+
+// SetSize is a no-op; the size of a symlink is defined by its target.
+func (sl *Symlink) SetSize(s uint64) {}
+
+// SetUser sets the user that last modified the symlink.
+func (sl *Symlink) SetUser(user string) {
+	sl.Base.user = user
+}
+
+// SetTarget changes the target of the symlink and updates its hashes.
+func (sl *Symlink) SetTarget(lkr Linker, target string) {
+	sl.target = target
+	sl.Base.content = symlinkContentHash(target)
+	sl.rehash(lkr, sl.Path())
+	sl.SetModTime(time.Now())
+}
+
+// SetMode sets the permission bits of the symlink and updates its hash.
+// Most systems ignore the mode of symlinks, but it is kept for completeness.
+func (sl *Symlink) SetMode(lkr Linker, mode os.FileMode) {
+	sl.setMode(mode)
+	sl.rehash(lkr, sl.Path())
+}
+
+// SetOwner sets the numeric owner of the symlink and updates its hash.
+func (sl *Symlink) SetOwner(lkr Linker, uid, gid uint32) {
+	sl.setOwner(uid, gid)
+	sl.rehash(lkr, sl.Path())
+}
+
+// SetXattr sets the extended attribute `name` of the symlink and updates its hash.
+func (sl *Symlink) SetXattr(lkr Linker, name string, value []byte) {
+	sl.setXattr(name, value)
+	sl.rehash(lkr, sl.Path())
+}
+
+// RemoveXattr removes the extended attribute `name` of the symlink and updates its hash.
+func (sl *Symlink) RemoveXattr(lkr Linker, name string) {
+	sl.removeXattr(name)
+	sl.rehash(lkr, sl.Path())
+}
+
+// Copy copies the symlink, except `inode`.
+func (sl *Symlink) Copy(inode uint64) ModNode {
+	if sl == nil {
+		return nil
+	}
+
+	return &Symlink{
+		Base:   sl.Base.copyBase(inode),
+		parent: sl.parent,
+		target: sl.target,
+	}
+}
+
+func (sl *Symlink) rehash(lkr Linker, newPath string) {
+	oldHash := sl.tree.Clone()
+	sl.tree = symlinkTreeHash(newPath, sl.content, sl.attrHashSuffix())
+	lkr.MemIndexSwap(sl, oldHash, true)
+}
+
+// NotifyMove should be called when the node moved parents.
+func (sl *Symlink) NotifyMove(lkr Linker, newParent *Directory, newPath string) error {
+	dirname, basename := path.Split(newPath)
+	sl.SetName(basename)
+	sl.parent = dirname
+	sl.rehash(lkr, newPath)
+
+	if newParent != nil {
+		if err := newParent.Add(lkr, sl); err != nil {
+			return err
+		}
+
+		newParent.rebuildOrderCache()
+	}
+
+	return nil
+}
+
+func (sl *Symlink) String() string {
+	return fmt.Sprintf(
+		"<symlink %s -> %s:%s:%d>",
+		sl.Path(),
+		sl.target,
+		sl.TreeHash(),
+		sl.Inode(),
+	)
+}
+
+// Path will return the absolute path of the symlink.
+func (sl *Symlink) Path() string {
+	return prefixSlash(path.Join(sl.parent, sl.name))
+}
+
+////////////////// HIERARCHY INTERFACE //////////////////
+
+// NChildren always returns 0, since symlinks are leaf nodes.
+func (sl *Symlink) NChildren() int {
+	return 0
+}
+
+// Child will return always nil, since symlinks are not followed in catfs.
+func (sl *Symlink) Child(_ Linker, name string) (Node, error) {
+	return nil, nil
+}
+
+// Parent returns the parent directory of the symlink.
+func (sl *Symlink) Parent(lkr Linker) (Node, error) {
+	return lkr.LookupNode(sl.parent)
+}
+
+// SetParent will set the parent of the symlink to `parent`.
+func (sl *Symlink) SetParent(_ Linker, parent Node) error {
+	if parent == nil {
+		return nil
+	}
+
+	sl.parent = parent.Path()
+	return nil
+}
+
+// Interface check for debugging:
+var _ ModNode = &Symlink{}
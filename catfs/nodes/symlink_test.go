@@ -0,0 +1,84 @@
+package nodes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	capnp "zombiezen.com/go/capnproto2"
+)
+
+func TestSymlink(t *testing.T) {
+	lkr := NewMockLinker()
+	root, err := NewEmptyDirectory(lkr, nil, "", "a", 2)
+	require.Nil(t, err)
+	lkr.AddNode(root, true)
+	lkr.MemSetRoot(root)
+
+	symlink := NewSymlink(root, "link", "../target", "a", 3)
+	lkr.AddNode(symlink, true)
+
+	require.Equal(t, NodeTypeSymlink, symlink.Type())
+	require.Equal(t, "/link", symlink.Path())
+	require.Equal(t, uint64(len("../target")), symlink.Size())
+
+	other := NewSymlink(root, "other", "../target", "a", 4)
+	require.True(t, symlink.ContentHash().Equal(other.ContentHash()))
+	require.False(t, symlink.TreeHash().Equal(other.TreeHash()))
+
+	symlink.SetTarget(lkr, "/abs/target")
+	require.False(t, symlink.ContentHash().Equal(other.ContentHash()))
+
+	now := time.Now()
+	symlink.SetModTime(now)
+
+	data, err := MarshalNode(symlink)
+	require.Nil(t, err)
+
+	nd, err := UnmarshalNode(data)
+	require.Nil(t, err)
+
+	empty, ok := nd.(*Symlink)
+	require.True(t, ok)
+	require.Equal(t, NodeTypeSymlink, empty.Type())
+	require.Equal(t, "/link", empty.Path())
+	require.Equal(t, "/abs/target", empty.Target())
+	require.Equal(t, uint64(3), empty.Inode())
+	require.Equal(t, now.Truncate(time.Microsecond), empty.ModTime())
+	require.True(t, empty.TreeHash().Equal(symlink.TreeHash()))
+	require.True(t, empty.ContentHash().Equal(symlink.ContentHash()))
+}
+
+func TestSymlinkGhost(t *testing.T) {
+	lkr := NewMockLinker()
+	root, err := NewEmptyDirectory(lkr, nil, "", "a", 1)
+	require.Nil(t, err)
+	lkr.AddNode(root, true)
+	lkr.MemSetRoot(root)
+
+	symlink := NewSymlink(root, "link", "x", "a", 2)
+	ghost, err := MakeGhost(symlink, 3)
+	require.Nil(t, err)
+
+	msg, err := ghost.ToCapnp()
+	require.Nil(t, err)
+
+	data, err := msg.Marshal()
+	require.Nil(t, err)
+
+	newMsg, err := capnp.Unmarshal(data)
+	require.Nil(t, err)
+
+	empty := &Ghost{}
+	require.Nil(t, empty.FromCapnp(newMsg))
+	require.Equal(t, NodeTypeSymlink, empty.OldNode().Type())
+
+	oldSymlink, err := empty.OldSymlink()
+	require.Nil(t, err)
+	require.Equal(t, "x", oldSymlink.Target())
+	require.Equal(t, "/link", oldSymlink.Path())
+
+	content, err := ContentHash(empty)
+	require.Nil(t, err)
+	require.True(t, content.Equal(symlink.ContentHash()))
+}
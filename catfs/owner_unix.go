@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package catfs
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileOwner returns the numeric owner of the file described by `info`.
+func fileOwner(info os.FileInfo) (uid, gid uint32, ok bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+
+	return stat.Uid, stat.Gid, true
+}
@@ -0,0 +1,199 @@
+package catfs
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	e "github.com/pkg/errors"
+	ie "github.com/sahib/brig/catfs/errors"
+	n "github.com/sahib/brig/catfs/nodes"
+	"github.com/sahib/brig/util"
+)
+
+// PinStats sums up the pin state of all versions of all files below Path.
+// All sizes are in bytes.
+type PinStats struct {
+	Path  string
+	IsDir bool
+
+	// Files is the number of files below Path.
+	Files int
+
+	// Versions is the number of distinct versions of these files.
+	Versions int
+
+	// PinnedSize is the size of all pinned versions.
+	PinnedSize uint64
+
+	// ExplicitSize is the part of PinnedSize that is pinned explicitly.
+	ExplicitSize uint64
+
+	// UnpinnedSize is the size of all versions that are not pinned.
+	UnpinnedSize uint64
+
+	// CachedSize is the size of all versions that are available locally,
+	// pinned or not.
+	CachedSize uint64
+
+	// RepinUnpinSize is the size of the pinned versions that the next repin
+	// will unpin, because they are beyond max_depth or their retention
+	// policy does not keep them.
+	RepinUnpinSize uint64
+
+	// QuotaCandidateSize is the size of the pinned versions that the next
+	// repin will unpin if the quota is exceeded.
+	QuotaCandidateSize uint64
+}
+
+func (ps *PinStats) add(other *PinStats) {
+	ps.Files += other.Files
+	ps.Versions += other.Versions
+	ps.PinnedSize += other.PinnedSize
+	ps.ExplicitSize += other.ExplicitSize
+	ps.UnpinnedSize += other.UnpinnedSize
+	ps.CachedSize += other.CachedSize
+	ps.RepinUnpinSize += other.RepinUnpinSize
+	ps.QuotaCandidateSize += other.QuotaCandidateSize
+}
+
+// filePinStats computes the stats of a single file.
+// The repin fields are only filled if `withRepin` is true.
+func (fs *FS) filePinStats(nd n.ModNode, minDepth, maxDepth int64, withRepin bool) (*PinStats, error) {
+	part, err := fs.partitionNodeHashes(nd, minDepth, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &PinStats{Path: nd.Path(), Files: 1}
+	buckets := [][]n.ModNode{part.ShouldPin, part.QuotaCandidates, part.DepthCandidates}
+	for bucketIdx, versions := range buckets {
+		for _, version := range versions {
+			stats.Versions++
+
+			isPinned, isExplicit, err := fs.pinner.IsNodePinned(version)
+			if err != nil {
+				return nil, err
+			}
+
+			size := version.Size()
+			if isPinned {
+				stats.PinnedSize += size
+				if isExplicit {
+					stats.ExplicitSize += size
+				}
+
+				if withRepin && bucketIdx == 1 {
+					stats.QuotaCandidateSize += size
+				}
+
+				if withRepin && bucketIdx == 2 {
+					stats.RepinUnpinSize += size
+				}
+			} else {
+				stats.UnpinnedSize += size
+			}
+
+			file, ok := version.(*n.File)
+			if !ok {
+				continue
+			}
+
+			isCached, err := fs.isContentCached(file)
+			if err != nil {
+				return nil, err
+			}
+
+			if isCached {
+				stats.CachedSize += size
+			}
+		}
+	}
+
+	return stats, nil
+}
+
+// PinStatus returns the pin stats of `root` and of everything below it,
+// down to `depth` levels (a negative depth means no limit). The stats of a
+// directory include everything below it. The entries are sorted by path.
+//
+// The repin fields are computed with the current repin settings, as if
+// repin was run right now. If repinning is disabled they are zero.
+func (fs *FS) PinStatus(root string, depth int) ([]*PinStats, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	root = prefixSlash(path.Clean(root))
+	rootNd, err := fs.lkr.LookupNode(root)
+	if err != nil {
+		return nil, err
+	}
+
+	withRepin := fs.cfg.Bool("repin.enabled")
+	minDepth := util.Max64(0, fs.cfg.Int("repin.min_depth"))
+	maxDepth := util.Max64(1, fs.cfg.Int("repin.max_depth"))
+
+	// The depth of a path below root; root itself is at depth 0.
+	relDepth := func(childPath string) int {
+		rel := strings.Trim(strings.TrimPrefix(childPath, root), "/")
+		if rel == "" {
+			return 0
+		}
+
+		return strings.Count(rel, "/") + 1
+	}
+
+	entries := make(map[string]*PinStats)
+	err = n.Walk(fs.lkr, rootNd, false, func(child n.Node) error {
+		childDepth := relDepth(child.Path())
+		switch child.Type() {
+		case n.NodeTypeGhost:
+			return nil
+		case n.NodeTypeDirectory:
+			if depth < 0 || childDepth <= depth {
+				entries[child.Path()] = &PinStats{Path: child.Path(), IsDir: true}
+			}
+
+			return nil
+		}
+
+		modChild, ok := child.(n.ModNode)
+		if !ok {
+			return e.Wrapf(ie.ErrBadNode, "pin status")
+		}
+
+		stats, err := fs.filePinStats(modChild, minDepth, maxDepth, withRepin)
+		if err != nil {
+			return err
+		}
+
+		if depth < 0 || childDepth <= depth {
+			entries[child.Path()] = stats
+		}
+
+		// Add the file to all directories above it, up to root:
+		for dir := child.Path(); dir != root && dir != "/"; {
+			dir = path.Dir(dir)
+			if dirStats, ok := entries[dir]; ok {
+				dirStats.add(stats)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*PinStats{}
+	for _, stats := range entries {
+		result = append(result, stats)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+
+	return result, nil
+}
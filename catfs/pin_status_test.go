@@ -0,0 +1,75 @@
+package catfs
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPinStatus(t *testing.T) {
+	withDummyFS(t, func(fs *FS) {
+		fs.cfg.SetBool("repin.enabled", true)
+		fs.cfg.SetString("repin.quota", "10G")
+		fs.cfg.SetInt("repin.min_depth", 1)
+		fs.cfg.SetInt("repin.max_depth", 5)
+
+		require.Nil(t, fs.Mkdir("/empty", true))
+		for idx := 0; idx < 10; idx++ {
+			require.Nil(t, fs.Stage("/dir/a", bytes.NewReader([]byte{byte(idx)})))
+			require.Nil(t, fs.MakeCommit(fmt.Sprintf("state: %d", idx)))
+		}
+
+		for idx := 0; idx < 10; idx++ {
+			require.Nil(t, fs.Pin("/dir/a", "HEAD"+strings.Repeat("^", idx), false))
+		}
+
+		stats, err := fs.PinStatus("/", -1)
+		require.Nil(t, err)
+		require.Len(t, stats, 4)
+
+		paths := []string{}
+		for _, entry := range stats {
+			paths = append(paths, entry.Path)
+		}
+		require.Equal(t, []string{"/", "/dir", "/dir/a", "/empty"}, paths)
+
+		// All versions are pinned; 5 of them are beyond max_depth and the
+		// ones between min_depth and max_depth may go if over quota:
+		for _, entry := range stats[:3] {
+			require.Equal(t, 1, entry.Files, entry.Path)
+			require.Equal(t, 10, entry.Versions, entry.Path)
+			require.Equal(t, uint64(10), entry.PinnedSize, entry.Path)
+			require.Equal(t, uint64(0), entry.ExplicitSize, entry.Path)
+			require.Equal(t, uint64(0), entry.UnpinnedSize, entry.Path)
+			require.Equal(t, uint64(5), entry.RepinUnpinSize, entry.Path)
+			require.Equal(t, uint64(4), entry.QuotaCandidateSize, entry.Path)
+		}
+
+		require.True(t, stats[1].IsDir)
+		require.False(t, stats[2].IsDir)
+		require.Equal(t, &PinStats{Path: "/empty", IsDir: true}, stats[3])
+
+		// After repinning, there should be nothing left to unpin:
+		require.Nil(t, fs.repin("/"))
+		stats, err = fs.PinStatus("/dir", 0)
+		require.Nil(t, err)
+		require.Len(t, stats, 1)
+		require.Equal(t, "/dir", stats[0].Path)
+		require.Equal(t, uint64(5), stats[0].PinnedSize)
+		require.Equal(t, uint64(5), stats[0].UnpinnedSize)
+		require.Equal(t, uint64(0), stats[0].RepinUnpinSize)
+
+		// Without repin, the repin columns stay empty:
+		fs.cfg.SetBool("repin.enabled", false)
+		stats, err = fs.PinStatus("/dir/a", 0)
+		require.Nil(t, err)
+		require.Len(t, stats, 1)
+		require.Equal(t, uint64(0), stats[0].QuotaCandidateSize)
+
+		_, err = fs.PinStatus("/nothing-here", 0)
+		require.NotNil(t, err)
+	})
+}
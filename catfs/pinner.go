@@ -231,18 +231,63 @@ func (pc *Pinner) Unpin(inode uint64, hash h.Hash, explicit bool) error {
 			return nil
 		}
 
-		if err := pc.bk.Unpin(hash); err != nil {
+		// Chunks might be shared by several files.
+		// Keep them in the backend as long as one of them is pinned.
+		isShared, err := pc.isPinnedByOthers(inode, hash)
+		if err != nil {
 			return err
 		}
+
+		if !isShared {
+			if err := pc.bk.Unpin(hash); err != nil {
+				return err
+			}
+		}
 	}
 
 	return pc.remember(inode, hash, false, explicit)
 }
 
+// isPinnedByOthers checks if `hash` is pinned by any inode other than `inode`.
+func (pc *Pinner) isPinnedByOthers(inode uint64, hash h.Hash) (bool, error) {
+	entry, err := getEntry(pc.lkr.KV(), hash)
+	if err != nil || entry == nil {
+		return false, err
+	}
+
+	for otherInode := range entry.Inodes {
+		if otherInode != inode {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// isFilePinned checks if all backend objects of `file` are pinned.
+// The second return value is only true if all of them are pinned explicitly.
+func (pc *Pinner) isFilePinned(file *n.File) (bool, bool, error) {
+	allExplicit := true
+	for _, hash := range file.BackendHashes() {
+		isPinned, isExplicit, err := pc.IsPinned(file.Inode(), hash)
+		if err != nil {
+			return false, false, err
+		}
+
+		if !isPinned {
+			return false, false, nil
+		}
+
+		allExplicit = allExplicit && isExplicit
+	}
+
+	return true, allExplicit, nil
+}
+
 ////////////////////////////
 
 // doPinOp recursively walks over all children of a node and pins or unpins them.
-func (pc *Pinner) doPinOp(op func(uint64, h.Hash, bool) error, nd n.Node, explicit bool) error {
+func (pc *Pinner) doPinOp(op func(*n.File, bool) error, nd n.Node, explicit bool) error {
 	return n.Walk(pc.lkr, nd, true, func(child n.Node) error {
 		if child.Type() != n.NodeTypeFile {
 			return nil
@@ -253,10 +298,62 @@ func (pc *Pinner) doPinOp(op func(uint64, h.Hash, bool) error, nd n.Node, explic
 			return ie.ErrBadNode
 		}
 
-		return op(file.Inode(), file.BackendHash(), explicit)
+		return op(file, explicit)
 	})
 }
 
+func (pc *Pinner) pinFile(file *n.File, explicit bool) error {
+	for _, hash := range file.BackendHashes() {
+		if err := pc.Pin(file.Inode(), hash, explicit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (pc *Pinner) unpinFile(file *n.File, explicit bool) error {
+	shared := pc.sharedWithCurrent(file)
+	for _, hash := range file.BackendHashes() {
+		if shared[hash.B58String()] {
+			continue
+		}
+
+		if err := pc.Unpin(file.Inode(), hash, explicit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sharedWithCurrent returns the backend hashes of `file` that are also used
+// by the current version of it. Old versions of a chunked file share most
+// chunks with newer ones; those must stay pinned when unpinning the old version.
+func (pc *Pinner) sharedWithCurrent(file *n.File) map[string]bool {
+	if !file.IsChunked() {
+		return nil
+	}
+
+	curr, err := pc.lkr.NodeByInode(file.Inode())
+	if err != nil {
+		// The file might not exist anymore; nothing to share then.
+		return nil
+	}
+
+	currFile, ok := curr.(*n.File)
+	if !ok || currFile.TreeHash().Equal(file.TreeHash()) {
+		return nil
+	}
+
+	shared := make(map[string]bool)
+	for _, hash := range currFile.BackendHashes() {
+		shared[hash.B58String()] = true
+	}
+
+	return shared
+}
+
 // PinNode tries to pin the node referenced by `nd`.
 // The difference to calling Pin(nd.BackendHash()) is,
 // that this method will pin directories recursively, if given.
@@ -265,12 +362,12 @@ func (pc *Pinner) doPinOp(op func(uint64, h.Hash, bool) error, nd n.Node, explic
 // to pin it non-exclusive, this will be a no-op.
 // In this case you have to unpin it first exclusively.
 func (pc *Pinner) PinNode(nd n.Node, explicit bool) error {
-	return pc.doPinOp(pc.Pin, nd, explicit)
+	return pc.doPinOp(pc.pinFile, nd, explicit)
 }
 
 // UnpinNode is the exact opposite of PinNode.
 func (pc *Pinner) UnpinNode(nd n.Node, explicit bool) error {
-	return pc.doPinOp(pc.Unpin, nd, explicit)
+	return pc.doPinOp(pc.unpinFile, nd, explicit)
 }
 
 // IsNodePinned checks if all `nd` is pinned and if so, exlusively.
@@ -285,6 +382,12 @@ func (pc *Pinner) IsNodePinned(nd n.Node) (bool, bool, error) {
 		return true, true, nil
 	}
 
+	// Symlinks have no content that could be pinned;
+	// treat them like empty directories.
+	if nd.Type() == n.NodeTypeSymlink {
+		return true, true, nil
+	}
+
 	pinCount := 0
 	explicitCount := 0
 	totalCount := 0
@@ -301,7 +404,7 @@ func (pc *Pinner) IsNodePinned(nd n.Node) (bool, bool, error) {
 
 		totalCount++
 
-		isPinned, isExplicit, err := pc.IsPinned(file.Inode(), file.BackendHash())
+		isPinned, isExplicit, err := pc.isFilePinned(file)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,182 @@
+package catfs
+
+import (
+	"fmt"
+	"sync"
+
+	ie "github.com/sahib/brig/catfs/errors"
+	n "github.com/sahib/brig/catfs/nodes"
+	h "github.com/sahib/brig/util/hashlib"
+)
+
+// PrefetchProgress is passed to the progress callback of Prefetch
+// each time a file is done.
+type PrefetchProgress struct {
+	// Path is the path of the file that is done.
+	Path string
+
+	// Size is the size of this file.
+	Size uint64
+
+	// Skipped is true if the file was already pinned and cached,
+	// for example by an earlier, interrupted run.
+	Skipped bool
+
+	// Err is set if the file could not be fetched or pinned.
+	Err error
+
+	// DoneFiles and DoneBytes count the files that are done so far,
+	// including this one and including failed ones.
+	DoneFiles int
+	DoneBytes uint64
+
+	// TotalFiles and TotalBytes describe all files that will be fetched.
+	TotalFiles int
+	TotalBytes uint64
+}
+
+// prefetchFile makes sure that the content of `file` is pinned explicitly
+// and available locally. It returns true if there was nothing to do.
+func (fs *FS) prefetchFile(file *n.File) (bool, error) {
+	isDone := func() (bool, error) {
+		fs.mu.Lock()
+		defer fs.mu.Unlock()
+
+		isPinned, isExplicit, err := fs.pinner.IsNodePinned(file)
+		if err != nil || !isPinned || !isExplicit {
+			return false, err
+		}
+
+		return fs.isContentCached(file)
+	}
+
+	done, err := isDone()
+	if err != nil || done {
+		return done, err
+	}
+
+	// Fetch the content without holding the lock, since this might take
+	// a long time. Pinning afterwards is cheap then.
+	for _, hash := range file.BackendHashes() {
+		isCached, err := fs.bk.IsCached(hash)
+		if err != nil {
+			return false, err
+		}
+
+		if isCached {
+			continue
+		}
+
+		if err := fs.preCache([]h.Hash{hash}); err != nil {
+			return false, err
+		}
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if err := fs.pinner.PinNode(file, true); err != nil {
+		return false, err
+	}
+
+	isCached, err := fs.isContentCached(file)
+	if err != nil {
+		return false, err
+	}
+
+	if !isCached {
+		return false, fmt.Errorf("content of %s is not available after fetching", file.Path())
+	}
+
+	return false, nil
+}
+
+// Prefetch pins all files below `root` (as of `rev`) explicitly and makes
+// sure that their content is fully available in the local backend, so they
+// can be read without network access afterwards. Up to `parallel` files
+// are fetched at the same time.
+//
+// `progressFn` is called after each file; calls are never concurrent.
+// Files that fail are reported there and do not stop the others.
+// Files that are already pinned and cached are skipped, so an interrupted
+// prefetch can simply be started again.
+func (fs *FS) Prefetch(root, rev string, parallel int, progressFn func(progress *PrefetchProgress)) error {
+	fs.mu.Lock()
+
+	rootNd, err := fs.lookupNodeAt(rev, prefixSlash(root))
+	if err != nil {
+		fs.mu.Unlock()
+		return err
+	}
+
+	if rootNd.Type() == n.NodeTypeGhost {
+		fs.mu.Unlock()
+		return ie.NoSuchFile(root)
+	}
+
+	files := []*n.File{}
+	totalBytes := uint64(0)
+	err = n.Walk(fs.lkr, rootNd, true, func(child n.Node) error {
+		if child.Type() != n.NodeTypeFile {
+			return nil
+		}
+
+		file, ok := child.(*n.File)
+		if !ok {
+			return ie.ErrBadNode
+		}
+
+		files = append(files, file)
+		totalBytes += file.Size()
+		return nil
+	})
+
+	fs.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	progressMu := sync.Mutex{}
+	progress := PrefetchProgress{
+		TotalFiles: len(files),
+		TotalBytes: totalBytes,
+	}
+
+	fileCh := make(chan *n.File)
+	wg := sync.WaitGroup{}
+	for idx := 0; idx < parallel; idx++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for file := range fileCh {
+				skipped, err := fs.prefetchFile(file)
+
+				progressMu.Lock()
+				progress.Path = file.Path()
+				progress.Size = file.Size()
+				progress.Skipped = skipped
+				progress.Err = err
+				progress.DoneFiles++
+				progress.DoneBytes += file.Size()
+
+				curr := progress
+				progressFn(&curr)
+				progressMu.Unlock()
+			}
+		}()
+	}
+
+	for _, file := range files {
+		fileCh <- file
+	}
+
+	close(fileCh)
+	wg.Wait()
+	return nil
+}
@@ -0,0 +1,68 @@
+package catfs
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefetch(t *testing.T) {
+	t.Parallel()
+
+	withDummyFS(t, func(fs *FS) {
+		for idx := 0; idx < 10; idx++ {
+			path := fmt.Sprintf("/dir/sub/%d", idx)
+			require.Nil(t, fs.Stage(path, bytes.NewReader([]byte{byte(idx), 1, 2})))
+			require.Nil(t, fs.Unpin(path, "curr", true))
+		}
+
+		require.Nil(t, fs.Stage("/other", bytes.NewReader([]byte{42})))
+		require.Nil(t, fs.MakeCommit("initial"))
+
+		// Pin one of them before, it should be skipped:
+		require.Nil(t, fs.Pin("/dir/sub/0", "curr", true))
+
+		paths := []string{}
+		skipped := 0
+		var last *PrefetchProgress
+
+		require.Nil(t, fs.Prefetch("/dir", "curr", 3, func(progress *PrefetchProgress) {
+			require.Nil(t, progress.Err)
+			paths = append(paths, progress.Path)
+			if progress.Skipped {
+				skipped++
+			}
+
+			last = progress
+		}))
+
+		sort.Strings(paths)
+		require.Len(t, paths, 10)
+		require.Equal(t, "/dir/sub/0", paths[0])
+		require.Equal(t, 1, skipped)
+		require.Equal(t, 10, last.DoneFiles)
+		require.Equal(t, 10, last.TotalFiles)
+		require.Equal(t, uint64(30), last.DoneBytes)
+		require.Equal(t, uint64(30), last.TotalBytes)
+
+		isPinned, isExplicit, err := fs.IsPinned("/dir")
+		require.Nil(t, err)
+		require.True(t, isPinned)
+		require.True(t, isExplicit)
+
+		// A second run should have nothing left to do:
+		skipped = 0
+		require.Nil(t, fs.Prefetch("/dir/sub", "HEAD", 1, func(progress *PrefetchProgress) {
+			require.Nil(t, progress.Err)
+			if progress.Skipped {
+				skipped++
+			}
+		}))
+
+		require.Equal(t, 10, skipped)
+		require.NotNil(t, fs.Prefetch("/nope", "curr", 1, func(progress *PrefetchProgress) {}))
+	})
+}
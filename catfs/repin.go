@@ -1,13 +1,16 @@
 package catfs
 
 import (
+	"math"
 	"sort"
+	"time"
 
 	"github.com/dustin/go-humanize"
 	e "github.com/pkg/errors"
 	ie "github.com/sahib/brig/catfs/errors"
 	n "github.com/sahib/brig/catfs/nodes"
 	"github.com/sahib/brig/catfs/vcs"
+	"github.com/sahib/brig/repo/pinrules"
 	"github.com/sahib/brig/util"
 	log "github.com/sirupsen/logrus"
 )
@@ -15,6 +18,13 @@ import (
 type partition struct {
 	PinSize uint64
 
+	// Priority of the node (see pinrules.Priority)
+	Priority pinrules.Priority
+
+	// QuotaFolder is the folder with a quota that the node counts
+	// against, or empty if there is none.
+	QuotaFolder string
+
 	// nodes that are within min_depth and should stay pinned
 	// (or are even re-pinned if needed)
 	ShouldPin []n.ModNode
@@ -28,18 +38,15 @@ type partition struct {
 	DepthCandidates []n.ModNode
 }
 
-// partitionNodeHashes takes all hashes of a node and sorts them into the
-// buckets described in the partition docs.
-func (fs *FS) partitionNodeHashes(nd n.ModNode, minDepth, maxDepth int64) (*partition, error) {
-	currDepth := int64(0)
-	part := &partition{}
-
+// nodeVersions returns all distinct versions of `nd`, newest first.
+func (fs *FS) nodeVersions(nd n.ModNode) ([]n.ModNode, error) {
 	curr, err := fs.lkr.Status()
 	if err != nil {
 		return nil, err
 	}
 
 	seen := make(map[string]bool)
+	versions := []n.ModNode{}
 	walker := vcs.NewHistoryWalker(fs.lkr, curr, nd)
 
 	for walker.Next() {
@@ -58,7 +65,62 @@ func (fs *FS) partitionNodeHashes(nd n.ModNode, minDepth, maxDepth int64) (*part
 			continue
 		}
 
+		seen[curr.BackendHash().B58String()] = true
+		versions = append(versions, curr)
+
+		// TODO: Optimization: Save depth of last run and abort early if we know
+		//       that we unpinned everything at this level already.
+	}
+
+	if err := walker.Err(); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// partitionNodeHashes takes all hashes of a node and sorts them into the
+// buckets described in the partition docs. If there is a retention policy
+// for the node, the versions it does not keep are always unpinned and
+// `maxDepth` does not apply; the quota still does. Nodes with high priority
+// have no quota candidates; everything that is kept should be pinned.
+func (fs *FS) partitionNodeHashes(nd n.ModNode, minDepth, maxDepth int64) (*partition, error) {
+	part := &partition{
+		Priority: fs.pinRuleManager.LookupPriority(nd.Path()),
+	}
+
+	if folder, _, ok := fs.pinRuleManager.LookupQuota(nd.Path()); ok {
+		part.QuotaFolder = folder
+	}
+
+	versions, err := fs.nodeVersions(nd)
+	if err != nil {
+		return nil, err
+	}
+
+	var keep []bool
+	if policy, ok := fs.retentionManager.Lookup(nd.Path()); ok {
+		dates := make([]time.Time, len(versions))
+		for idx, version := range versions {
+			dates[idx] = version.ModTime()
+		}
+
+		keep = policy.Keep(dates, time.Now())
+		maxDepth = math.MaxInt64
+	}
+
+	if part.Priority == pinrules.PriorityHigh {
+		minDepth = maxDepth
+	}
+
+	currDepth := int64(0)
+	for idx, curr := range versions {
 		// Sort the entry into the right bucket:
+		if keep != nil && !keep[idx] {
+			part.DepthCandidates = append(part.DepthCandidates, curr)
+			continue
+		}
+
 		if currDepth < minDepth {
 			part.ShouldPin = append(part.ShouldPin, curr)
 			part.PinSize += nd.Size()
@@ -77,40 +139,32 @@ func (fs *FS) partitionNodeHashes(nd n.ModNode, minDepth, maxDepth int64) (*part
 			part.DepthCandidates = append(part.DepthCandidates, curr)
 		}
 
-		seen[curr.BackendHash().B58String()] = true
 		currDepth++
-
-		// TODO: Optimization: Save depth of last run and abort early if we know
-		//       that we unpinned everything at this level already.
-	}
-
-	if err := walker.Err(); err != nil {
-		return nil, err
 	}
 
 	return part, nil
 }
 
-func (fs *FS) ensurePin(entries []n.ModNode) (uint64, error) {
+func (fs *FS) ensurePin(entries []n.ModNode, isPinUnpinned bool) (uint64, error) {
 	newlyPinned := uint64(0)
-	isPinUnpinned := fs.cfg.Bool("repin.pin_unpinned")
 
 	for _, nd := range entries {
 		isPinned, _, err := fs.pinner.IsNodePinned(nd)
 		if err != nil {
 			return newlyPinned, err
 		}
-		if nd.Type() == n.NodeTypeFile && isPinned {
+		if file, ok := nd.(*n.File); ok && isPinned {
 			// let's make sure that this file node is pinned at backend as well
-			isCached, err := fs.bk.IsCached(nd.BackendHash())
+			isCached, err := fs.isContentCached(file)
 			if err != nil {
 				return newlyPinned, err
 			}
 			if !isCached {
 				log.Warningf("The %+v should be cached, but it is not. Recaching", nd)
-				err := fs.bk.Pin(nd.BackendHash())
-				if err != nil {
-					return newlyPinned, err
+				for _, hash := range file.BackendHashes() {
+					if err := fs.bk.Pin(hash); err != nil {
+						return newlyPinned, err
+					}
 				}
 			}
 		}
@@ -169,6 +223,16 @@ func findLastPinnedIdx(pinner *Pinner, nds []n.ModNode) (int, error) {
 	return -1, nil
 }
 
+// subStorage subtracts `size` from `storage` without wrapping around.
+// The pin size of a partition is only an estimate, so this might happen.
+func subStorage(storage, size uint64) uint64 {
+	if size > storage {
+		return 0
+	}
+
+	return storage - size
+}
+
 func (fs *FS) balanceQuota(ps []*partition, totalStorage, quota uint64) (uint64, error) {
 	sort.Slice(ps, func(i, j int) bool {
 		return ps[i].PinSize < ps[j].PinSize
@@ -180,7 +244,11 @@ func (fs *FS) balanceQuota(ps []*partition, totalStorage, quota uint64) (uint64,
 	// Try to reduce the pinned storage amount until
 	// we stay below the determined quota.
 	for totalStorage >= quota && empties < len(ps) {
-		cnds := ps[idx%len(ps)].QuotaCandidates
+		// Take one version of each partition in turn:
+		part := ps[idx%len(ps)]
+		idx++
+
+		cnds := part.QuotaCandidates
 		if len(cnds) == 0 {
 			empties++
 			continue
@@ -194,12 +262,14 @@ func (fs *FS) balanceQuota(ps []*partition, totalStorage, quota uint64) (uint64,
 
 		if lastPinIdx < 0 {
 			empties++
-			ps[idx%len(ps)].QuotaCandidates = cnds[:0]
+			part.QuotaCandidates = cnds[:0]
 			continue
 		}
 
+		empties = 0
+
 		cnd := cnds[lastPinIdx]
-		totalStorage -= cnd.Size()
+		totalStorage = subStorage(totalStorage, cnd.Size())
 		savedStorage += cnd.Size()
 
 		explicit := true // we are unpinning even explicitly pinned
@@ -207,13 +277,48 @@ func (fs *FS) balanceQuota(ps []*partition, totalStorage, quota uint64) (uint64,
 			return 0, err
 		}
 
-		ps[idx%len(ps)].QuotaCandidates = cnds[:lastPinIdx]
+		part.QuotaCandidates = cnds[:lastPinIdx]
 	}
 
 	log.Infof("quota collector unpinned %d bytes", savedStorage)
 	return savedStorage, nil
 }
 
+// balanceFolderQuotas makes sure that each folder with a quota stays
+// below it. The number of unpinned bytes is returned.
+func (fs *FS) balanceFolderQuotas(parts []*partition) (uint64, error) {
+	folderParts := make(map[string][]*partition)
+	for _, part := range parts {
+		if part.QuotaFolder != "" {
+			folderParts[part.QuotaFolder] = append(folderParts[part.QuotaFolder], part)
+		}
+	}
+
+	savedStorage := uint64(0)
+	for folder, ps := range folderParts {
+		_, quota, _ := fs.pinRuleManager.LookupQuota(folder)
+
+		folderStorage := uint64(0)
+		for _, part := range ps {
+			folderStorage += part.PinSize
+		}
+
+		if folderStorage < quota {
+			continue
+		}
+
+		log.Infof("folder %s exceeds its quota (%s of %s)", folder, humanize.Bytes(folderStorage), humanize.Bytes(quota))
+		quotaUnpins, err := fs.balanceQuota(ps, folderStorage, quota)
+		if err != nil {
+			return 0, err
+		}
+
+		savedStorage += quotaUnpins
+	}
+
+	return savedStorage, nil
+}
+
 func (fs *FS) repin(root string) error {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
@@ -264,7 +369,9 @@ func (fs *FS) repin(root string) error {
 			return err
 		}
 
-		pinBytes, err := fs.ensurePin(part.ShouldPin)
+		// Folders with high priority are always fully pinned.
+		isPinUnpinned := fs.cfg.Bool("repin.pin_unpinned") || part.Priority == pinrules.PriorityHigh
+		pinBytes, err := fs.ensurePin(part.ShouldPin, isPinUnpinned)
 		if err != nil {
 			return err
 		}
@@ -286,13 +393,33 @@ func (fs *FS) repin(root string) error {
 		return e.Wrapf(err, "repin: walk")
 	}
 
-	quotaUnpins, err := fs.balanceQuota(parts, totalStorage, quota)
+	quotaUnpins, err := fs.balanceFolderQuotas(parts)
 	if err != nil {
-		return e.Wrapf(err, "repin: quota balance")
+		return e.Wrapf(err, "repin: folder quota balance")
 	}
 
 	savedStorage += quotaUnpins
-	totalStorage -= quotaUnpins
+	totalStorage = subStorage(totalStorage, quotaUnpins)
+
+	// Low priority folders give up their versions first:
+	lowParts, otherParts := []*partition{}, []*partition{}
+	for _, part := range parts {
+		if part.Priority == pinrules.PriorityLow {
+			lowParts = append(lowParts, part)
+		} else {
+			otherParts = append(otherParts, part)
+		}
+	}
+
+	for _, ps := range [][]*partition{lowParts, otherParts} {
+		quotaUnpins, err := fs.balanceQuota(ps, totalStorage, quota)
+		if err != nil {
+			return e.Wrapf(err, "repin: quota balance")
+		}
+
+		savedStorage += quotaUnpins
+		totalStorage = subStorage(totalStorage, quotaUnpins)
+	}
 
 	if savedStorage >= addedToStorage {
 		log.Infof("repin finished; freed %s, total storage is %s", humanize.Bytes(savedStorage-addedToStorage), humanize.Bytes(totalStorage))
@@ -313,3 +440,110 @@ func (fs *FS) Repin(root string) error {
 	fs.repinControl <- prefixSlash(root)
 	return nil
 }
+
+// EnforceRetention unpins all versions of files below `root` that are not
+// kept by their retention policy, so the next backend gc can free them.
+// Unlike Repin() this also works when repinning is disabled and only
+// returns when it is done. Files without a policy are not touched.
+// The number of unpinned bytes is returned.
+func (fs *FS) EnforceRetention(root string) (uint64, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.readOnly {
+		return 0, nil
+	}
+
+	rootNd, err := fs.lkr.LookupDirectory(prefixSlash(root))
+	if err != nil {
+		return 0, err
+	}
+
+	savedStorage := uint64(0)
+	err = n.Walk(fs.lkr, rootNd, true, func(child n.Node) error {
+		if child.Type() == n.NodeTypeDirectory {
+			return nil
+		}
+
+		if _, ok := fs.retentionManager.Lookup(child.Path()); !ok {
+			return nil
+		}
+
+		modChild, ok := child.(n.ModNode)
+		if !ok {
+			return e.Wrapf(ie.ErrBadNode, "retention")
+		}
+
+		part, err := fs.partitionNodeHashes(modChild, 0, math.MaxInt64)
+		if err != nil {
+			return err
+		}
+
+		unpinBytes, err := fs.ensureUnpin(part.DepthCandidates)
+		if err != nil {
+			return err
+		}
+
+		savedStorage += unpinBytes
+		return nil
+	})
+
+	if err != nil {
+		return 0, e.Wrapf(err, "retention: walk")
+	}
+
+	if savedStorage > 0 {
+		log.Infof("retention unpinned %s", humanize.Bytes(savedStorage))
+	}
+
+	return savedStorage, nil
+}
+
+// PinnedSize returns how much storage the pinned versions of all files
+// below `root` take up, including explicit pins. If there is nothing at
+// `root`, zero is returned.
+func (fs *FS) PinnedSize(root string) (uint64, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	rootNd, err := fs.lkr.LookupNode(prefixSlash(root))
+	if ie.IsNoSuchFileError(err) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	pinnedSize := uint64(0)
+	err = n.Walk(fs.lkr, rootNd, true, func(child n.Node) error {
+		if child.Type() == n.NodeTypeDirectory {
+			return nil
+		}
+
+		modChild, ok := child.(n.ModNode)
+		if !ok {
+			return e.Wrapf(ie.ErrBadNode, "pinned size")
+		}
+
+		versions, err := fs.nodeVersions(modChild)
+		if err != nil {
+			return err
+		}
+
+		for _, version := range versions {
+			isPinned, _, err := fs.pinner.IsNodePinned(version)
+			if err != nil {
+				return err
+			}
+
+			if isPinned {
+				pinnedSize += version.Size()
+			}
+		}
+
+		return nil
+	})
+
+	return pinnedSize, err
+}
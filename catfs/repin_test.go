@@ -6,6 +6,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/sahib/brig/repo/pinrules"
+	"github.com/sahib/brig/repo/retention"
 	"github.com/stretchr/testify/require"
 )
 
@@ -71,6 +73,170 @@ func TestRepinOldBehaviour(t *testing.T) {
 	})
 }
 
+func withRetention(t *testing.T, fs *FS, path, spec string) {
+	mgr, err := retention.NewManager(nil)
+	require.Nil(t, err)
+
+	policy, err := retention.ParsePolicy(spec)
+	require.Nil(t, err)
+	require.Nil(t, mgr.Set(path, policy))
+
+	fs.SetRetentionManager(mgr)
+}
+
+func TestRepinRetentionReplacesMaxDepth(t *testing.T) {
+	withDummyFS(t, func(fs *FS) {
+		fs.cfg.SetBool("repin.enabled", true)
+		fs.cfg.SetString("repin.quota", "10G")
+		fs.cfg.SetInt("repin.min_depth", 1)
+		fs.cfg.SetInt("repin.max_depth", 2)
+
+		// All versions were made just now, so all of them are kept:
+		withRetention(t, fs, "/dir", "all:1d")
+		testRun(t, fs, 20, 20)
+	})
+}
+
+func TestRepinRetentionDropsVersions(t *testing.T) {
+	withDummyFS(t, func(fs *FS) {
+		fs.cfg.SetBool("repin.enabled", true)
+		fs.cfg.SetString("repin.quota", "10G")
+		fs.cfg.SetInt("repin.min_depth", 5)
+		fs.cfg.SetInt("repin.max_depth", 10)
+
+		// No version is young enough; only the current one is kept:
+		withRetention(t, fs, "/", "all:1ns")
+		testRun(t, fs, 1, 20)
+	})
+}
+
+func TestEnforceRetention(t *testing.T) {
+	withDummyFS(t, func(fs *FS) {
+		fs.cfg.SetBool("repin.enabled", false)
+		withRetention(t, fs, "/dir", "all:1ns")
+
+		for idx := 0; idx < 5; idx++ {
+			require.Nil(t, fs.Stage("/dir/a", bytes.NewReader([]byte{byte(idx)})))
+			require.Nil(t, fs.Stage("/other", bytes.NewReader([]byte{byte(idx + 100)})))
+
+			require.Nil(t, fs.MakeCommit(fmt.Sprintf("state: %d", idx)))
+			for _, path := range []string{"/dir/a", "/other"} {
+				require.Nil(t, fs.Pin(path, "HEAD", false))
+			}
+		}
+
+		saved, err := fs.EnforceRetention("/")
+		require.Nil(t, err)
+		require.Equal(t, uint64(4), saved)
+
+		histA, err := fs.History("/dir/a")
+		require.Nil(t, err)
+		for idx, entry := range histA {
+			// The first entry is the staging commit with the current version.
+			require.Equal(t, idx <= 1, entry.IsPinned, fmt.Sprintf("%d", idx))
+		}
+
+		// Files without policy are not touched:
+		histOther, err := fs.History("/other")
+		require.Nil(t, err)
+		for idx, entry := range histOther {
+			require.True(t, entry.IsPinned, fmt.Sprintf("%d", idx))
+		}
+	})
+}
+
+func withPinRules(t *testing.T, fs *FS, rules map[string]pinrules.Rule) {
+	mgr, err := pinrules.NewManager(nil)
+	require.Nil(t, err)
+
+	for path, rule := range rules {
+		require.Nil(t, mgr.Set(path, rule))
+	}
+
+	fs.SetPinRuleManager(mgr)
+}
+
+func TestRepinFolderQuota(t *testing.T) {
+	withDummyFS(t, func(fs *FS) {
+		fs.cfg.SetBool("repin.enabled", true)
+		fs.cfg.SetString("repin.quota", "10G")
+		fs.cfg.SetInt("repin.min_depth", 1)
+		fs.cfg.SetInt("repin.max_depth", 100)
+
+		// Same as TestRepinQuota, but with a folder quota:
+		withPinRules(t, fs, map[string]pinrules.Rule{
+			"/dir": {Quota: 11},
+		})
+
+		testRun(t, fs, 10, 20)
+
+		used, err := fs.PinnedSize("/dir")
+		require.Nil(t, err)
+		require.Equal(t, uint64(10), used)
+
+		used, err = fs.PinnedSize("/nothing-here")
+		require.Nil(t, err)
+		require.Equal(t, uint64(0), used)
+	})
+}
+
+func TestRepinHighPriority(t *testing.T) {
+	withDummyFS(t, func(fs *FS) {
+		fs.cfg.SetBool("repin.enabled", true)
+		fs.cfg.SetString("repin.quota", "0B")
+		fs.cfg.SetInt("repin.min_depth", 1)
+		fs.cfg.SetInt("repin.max_depth", 10)
+
+		// The quota is ignored, but max_depth is not:
+		withPinRules(t, fs, map[string]pinrules.Rule{
+			"/dir": {Priority: pinrules.PriorityHigh},
+		})
+
+		testRun(t, fs, 10, 20)
+	})
+}
+
+func TestRepinLowPriorityFirst(t *testing.T) {
+	withDummyFS(t, func(fs *FS) {
+		fs.cfg.SetBool("repin.enabled", true)
+		fs.cfg.SetString("repin.quota", "12B")
+		fs.cfg.SetInt("repin.min_depth", 1)
+		fs.cfg.SetInt("repin.max_depth", 100)
+
+		withPinRules(t, fs, map[string]pinrules.Rule{
+			"/low": {Priority: pinrules.PriorityLow},
+		})
+
+		for idx := 0; idx < 10; idx++ {
+			require.Nil(t, fs.Stage("/low/a", bytes.NewReader([]byte{byte(idx)})))
+			require.Nil(t, fs.Stage("/normal/a", bytes.NewReader([]byte{byte(idx + 100)})))
+			require.Nil(t, fs.MakeCommit(fmt.Sprintf("state: %d", idx)))
+		}
+
+		for idx := 0; idx < 10; idx++ {
+			rev := "HEAD" + strings.Repeat("^", idx)
+			require.Nil(t, fs.Pin("/low/a", rev, false))
+			require.Nil(t, fs.Pin("/normal/a", rev, false))
+		}
+
+		require.Nil(t, fs.repin("/"))
+
+		// 20 versions were pinned; the low priority ones went first
+		// and that was enough to get below the quota:
+		histLow, err := fs.History("/low/a")
+		require.Nil(t, err)
+		for idx, entry := range histLow {
+			require.Equal(t, idx <= 1, entry.IsPinned, fmt.Sprintf("%d", idx))
+		}
+
+		histNormal, err := fs.History("/normal/a")
+		require.Nil(t, err)
+		for idx, entry := range histNormal {
+			require.True(t, entry.IsPinned, fmt.Sprintf("%d", idx))
+		}
+	})
+}
+
 func testRun(t *testing.T, fs *FS, split, n int) {
 	for idx := 0; idx < n; idx++ {
 		require.Nil(t, fs.Stage("/dir/a", bytes.NewReader([]byte{byte(idx)})))
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	e "github.com/pkg/errors"
@@ -14,9 +15,112 @@ import (
 )
 
 var (
-	indexCommitPattern = regexp.MustCompile(`^commit\[([-\+]{0,1}[0-9]+)\]$`)
+	indexCommitPattern  = regexp.MustCompile(`^commit\[([-\+]{0,1}[0-9]+)\]$`)
+	relativeDatePattern = regexp.MustCompile(
+		`^([0-9]+)\s*(second|minute|hour|day|week|month|year)s?\s+ago$`,
+	)
 )
 
+var relativeDateUnits = map[string]time.Duration{
+	"second": time.Second,
+	"minute": time.Minute,
+	"hour":   time.Hour,
+	"day":    24 * time.Hour,
+	"week":   7 * 24 * time.Hour,
+	"month":  30 * 24 * time.Hour,
+	"year":   365 * 24 * time.Hour,
+}
+
+// parseRevDate parses the date part of a date rev. Next to the formats
+// of parseTimeValue() this understands "now", "yesterday" and
+// expressions like "3 days ago".
+func parseRevDate(spec string, now time.Time) (time.Time, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	switch spec {
+	case "now":
+		return now, nil
+	case "yesterday":
+		return now.Add(-24 * time.Hour), nil
+	}
+
+	if matches := relativeDatePattern.FindStringSubmatch(spec); matches != nil {
+		count, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		return now.Add(-time.Duration(count) * relativeDateUnits[matches[2]]), nil
+	}
+
+	return parseTimeValue(spec, now)
+}
+
+// commitAtDate returns the last commit of the current branch
+// that was made at or before `date`.
+func commitAtDate(lkr *c.Linker, date time.Time) (*n.Commit, error) {
+	head, err := lkr.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	var found *n.Commit
+	err = c.Log(lkr, head, func(cmt *n.Commit) error {
+		if cmt.ModTime().After(date) {
+			return nil
+		}
+
+		found = cmt
+		return c.ErrStopLog
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
+
+// parseDateRev resolves revs like @2026-10-01T12:00 or @{3 days ago}
+// to the commit that was current at this time. Like other revs,
+// they may end with any number of '^'.
+func parseDateRev(lkr *c.Linker, rev string) (*n.Commit, error) {
+	spec := strings.TrimRight(rev[1:], "^")
+	ups := len(rev) - 1 - len(spec)
+	if strings.HasPrefix(spec, "{") && strings.HasSuffix(spec, "}") {
+		spec = spec[1 : len(spec)-1]
+	}
+
+	date, err := parseRevDate(spec, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	cmt, err := commitAtDate(lkr, date)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmt == nil {
+		return nil, ie.ErrNoSuchRef(rev)
+	}
+
+	if ups == 0 {
+		return cmt, nil
+	}
+
+	nd, err := lkr.ResolveRef(cmt.TreeHash().B58String() + strings.Repeat("^", ups))
+	if err != nil {
+		return nil, err
+	}
+
+	parent, ok := nd.(*n.Commit)
+	if !ok {
+		return nil, ie.ErrBadNode
+	}
+
+	return parent, nil
+}
+
 // validateRev check is a rev spec looks like it's valid
 // from a syntactic point of view.
 //
@@ -52,7 +156,12 @@ func validateRev(rev string) error {
 
 // parseRev resolves a base58 to a commit or if it looks like a refname
 // it tries to resolve that (HEAD, CURR, INIT e.g.).
+// Revs starting with '@' are resolved by date, see parseDateRev().
 func parseRev(lkr *c.Linker, rev string) (*n.Commit, error) {
+	if strings.HasPrefix(rev, "@") {
+		return parseDateRev(lkr, rev)
+	}
+
 	if err := validateRev(rev); err != nil {
 		return nil, e.Wrapf(err, "validate")
 	}
@@ -2,8 +2,10 @@ package catfs
 
 import (
 	"testing"
+	"time"
 
 	c "github.com/sahib/brig/catfs/core"
+	ie "github.com/sahib/brig/catfs/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -14,3 +16,54 @@ func TestRevParse(t *testing.T) {
 		require.Equal(t, "init", init.Message())
 	})
 }
+
+func TestParseRevDate(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local)
+
+	tcs := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"now", now},
+		{"yesterday", now.Add(-24 * time.Hour)},
+		{"3 days ago", now.Add(-3 * 24 * time.Hour)},
+		{"1 hour ago", now.Add(-time.Hour)},
+		{"2 Weeks Ago", now.Add(-14 * 24 * time.Hour)},
+		{"2026-10-01", time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)},
+		{"2026-10-01T12:00", time.Date(2026, 10, 1, 12, 0, 0, 0, time.Local)},
+	}
+
+	for _, tc := range tcs {
+		date, err := parseRevDate(tc.spec, now)
+		require.Nil(t, err, tc.spec)
+		require.True(t, tc.expected.Equal(date), tc.spec)
+	}
+
+	_, err := parseRevDate("3 fortnights ago", now)
+	require.NotNil(t, err)
+}
+
+func TestRevParseDate(t *testing.T) {
+	c.WithDummyLinker(t, func(lkr *c.Linker) {
+		c.MustTouchAndCommit(t, lkr, "/x", 1)
+		c.MustTouchAndCommit(t, lkr, "/x", 2)
+
+		head, err := lkr.Head()
+		require.Nil(t, err)
+
+		cmt, err := parseRev(lkr, "@{now}")
+		require.Nil(t, err)
+		require.Equal(t, head.TreeHash(), cmt.TreeHash())
+
+		cmt, err = parseRev(lkr, "@{now}^^")
+		require.Nil(t, err)
+		require.Equal(t, "cmt 1", cmt.Message())
+
+		// Nothing was committed before:
+		_, err = parseRev(lkr, "@{1 year ago}")
+		require.True(t, ie.IsErrNoSuchRef(err))
+
+		_, err = parseRev(lkr, "@{whenever}")
+		require.NotNil(t, err)
+	})
+}
@@ -0,0 +1,79 @@
+package catfs
+
+import (
+	"time"
+
+	e "github.com/pkg/errors"
+	c "github.com/sahib/brig/catfs/core"
+	n "github.com/sahib/brig/catfs/nodes"
+)
+
+// squashBoundary resolves `before` to the first commit that should not be
+// squashed anymore. `before` is either a rev or a date (or a duration like
+// 30d that is counted back from now). For a date the first commit made at
+// or after it is used.
+func squashBoundary(lkr *c.Linker, before string) (*n.Commit, error) {
+	if cmt, err := parseRev(lkr, before); err == nil {
+		return cmt, nil
+	}
+
+	date, err := parseTimeValue(before, time.Now())
+	if err != nil {
+		return nil, e.Errorf("»%s« is neither a rev nor a date", before)
+	}
+
+	boundary, err := lkr.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := lkr.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.Log(lkr, head, func(cmt *n.Commit) error {
+		if cmt.ModTime().Before(date) {
+			return c.ErrStopLog
+		}
+
+		boundary = cmt
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return boundary, nil
+}
+
+// Squash collapses all ranges of commits on the current branch that are
+// older than `before` into single commits. Tags, heads of other branches
+// and merge commits are kept. See squashBoundary() for what `before` may be.
+// The number of commits that were dropped is returned.
+func (fs *FS) Squash(before string) (int, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.readOnly {
+		return 0, ErrReadOnly
+	}
+
+	boundary, err := squashBoundary(fs.lkr, before)
+	if err != nil {
+		return 0, err
+	}
+
+	dropped, err := fs.lkr.SquashCommits(boundary)
+	if err != nil {
+		return 0, err
+	}
+
+	if dropped > 0 {
+		// The old commits and their nodes are not needed anymore.
+		fs.ScheduleGCRun()
+	}
+
+	return dropped, nil
+}
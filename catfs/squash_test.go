@@ -0,0 +1,51 @@
+package catfs
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSquash(t *testing.T) {
+	t.Parallel()
+
+	withDummyFS(t, func(fs *FS) {
+		for _, content := range []string{"a", "b", "c"} {
+			require.Nil(t, fs.Stage("/x", bytes.NewReader([]byte(content))))
+			require.Nil(t, fs.MakeCommit("edit "+content))
+		}
+
+		require.Nil(t, fs.Tag("head", "v1"))
+		require.Nil(t, fs.Stage("/x", bytes.NewReader([]byte("d"))))
+		require.Nil(t, fs.MakeCommit("edit d"))
+
+		_, err := fs.Squash("this-is-no-rev")
+		require.NotNil(t, err)
+
+		// A date in the future squashes everything:
+		future := time.Now().Add(time.Hour).Format(time.RFC3339)
+		dropped, err := fs.Squash(future)
+		require.Nil(t, err)
+		require.Equal(t, 2, dropped)
+
+		msgs := []string{}
+		require.Nil(t, fs.Log("", func(cmt *Commit) error {
+			msgs = append(msgs, cmt.Msg)
+			return nil
+		}))
+
+		// curr, edit d, the squashed commit (tagged) and init:
+		require.Len(t, msgs, 4)
+		requireContent(t, fs, "/x", "d")
+
+		tagged, err := fs.CommitInfo("v1")
+		require.Nil(t, err)
+		require.Contains(t, tagged.Msg, "squashed 3 commits")
+
+		dropped, err = fs.Squash("curr")
+		require.Nil(t, err)
+		require.Equal(t, 0, dropped)
+	})
+}
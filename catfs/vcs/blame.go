@@ -0,0 +1,126 @@
+package vcs
+
+import (
+	c "github.com/sahib/brig/catfs/core"
+	ie "github.com/sahib/brig/catfs/errors"
+	n "github.com/sahib/brig/catfs/nodes"
+)
+
+// BlameViaLocal marks versions that were made in this repository.
+const BlameViaLocal = "local"
+
+// BlameEntry attributes a single version of a node to where it came from.
+type BlameEntry struct {
+	// Change is the change that produced the version.
+	// Change.Head is the commit that introduced it locally.
+	Change *Change
+
+	// Remote is the owner of the repository the version was made in.
+	Remote string
+
+	// Via is BlameViaLocal for local edits, otherwise one of
+	// MergeViaSync or MergeViaPush.
+	Via string
+}
+
+type blamer struct {
+	lkr   *c.Linker
+	owner string
+}
+
+// origin figures out where the version of `nd` in `cmt` was made.
+// Merges of local branches are followed into the merged branch.
+func (bl *blamer) origin(cmt *n.Commit, nd n.ModNode) (string, string, error) {
+	with, mergeHead := cmt.MergeMarker()
+	if with == "" {
+		author := cmt.Author()
+		if author == "" || author == n.AuthorOfStage {
+			author = bl.owner
+		}
+
+		return author, BlameViaLocal, nil
+	}
+
+	via := cmt.MergeVia()
+	if via == "" {
+		// Merges of older versions were always syncs.
+		via = MergeViaSync
+	}
+
+	if via != MergeViaBranch {
+		return with, via, nil
+	}
+
+	// Branches are made locally, the merge marker only names the branch.
+	with = bl.owner
+
+	// The version came from another branch. Check where it was made there.
+	merged, err := bl.lkr.CommitByHash(mergeHead)
+	if err != nil || merged == nil {
+		return with, BlameViaLocal, err
+	}
+
+	prev, err := bl.lkr.LookupModNodeAt(merged, nd.Path())
+	if ie.IsNoSuchFileError(err) {
+		return with, BlameViaLocal, nil
+	}
+
+	if err != nil {
+		return "", "", err
+	}
+
+	if !prev.ContentHash().Equal(nd.ContentHash()) {
+		// Changed during the merge (e.g. by a content merge).
+		return with, BlameViaLocal, nil
+	}
+
+	walker := NewHistoryWalker(bl.lkr, merged, prev)
+	for walker.Next() {
+		state := walker.State()
+		if state.Mask == ChangeTypeNone {
+			continue
+		}
+
+		return bl.origin(state.Head, state.Curr)
+	}
+
+	return with, BlameViaLocal, walker.Err()
+}
+
+// Blame returns one entry for each version of `nd`, starting at the
+// commit `start`. Commits that did not change `nd` are left out.
+// The most recent version comes first.
+func Blame(lkr *c.Linker, nd n.ModNode, start *n.Commit) ([]*BlameEntry, error) {
+	owner, err := lkr.Owner()
+	if err != nil {
+		return nil, err
+	}
+
+	bl := &blamer{lkr: lkr, owner: owner}
+	entries := []*BlameEntry{}
+	walker := NewHistoryWalker(lkr, start, nd)
+
+	for walker.Next() {
+		state := walker.State()
+		if state.Mask == ChangeTypeNone {
+			continue
+		}
+
+		remote, via, err := bl.origin(state.Head, state.Curr)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, &BlameEntry{
+			Change: state,
+			Remote: remote,
+			Via:    via,
+		})
+	}
+
+	if err := walker.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
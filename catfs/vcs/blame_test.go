@@ -0,0 +1,84 @@
+package vcs
+
+import (
+	"testing"
+
+	c "github.com/sahib/brig/catfs/core"
+	h "github.com/sahib/brig/util/hashlib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlame(t *testing.T) {
+	c.WithLinkerPair(t, func(lkrSrc, lkrDst *c.Linker) {
+		c.MustTouchAndCommit(t, lkrSrc, "/x", 1)
+		require.Nil(t, Sync(lkrSrc, lkrDst, nil))
+
+		c.MustTouchAndCommit(t, lkrSrc, "/x", 2)
+		require.Nil(t, Sync(lkrSrc, lkrDst, &SyncOptions{Via: MergeViaPush}))
+
+		c.MustTouchAndCommit(t, lkrDst, "/x", 3)
+
+		x, err := lkrDst.LookupModNode("/x")
+		require.Nil(t, err)
+
+		head, err := lkrDst.Head()
+		require.Nil(t, err)
+
+		entries, err := Blame(lkrDst, x, head)
+		require.Nil(t, err)
+		require.Len(t, entries, 3)
+
+		expect := []struct {
+			seed   byte
+			remote string
+			via    string
+		}{
+			{3, "dst", BlameViaLocal},
+			{2, "src", MergeViaPush},
+			{1, "src", MergeViaSync},
+		}
+
+		for idx, exp := range expect {
+			entry := entries[idx]
+			require.Equal(t, h.TestDummy(t, exp.seed), entry.Change.Curr.ContentHash())
+			require.Equal(t, exp.remote, entry.Remote)
+			require.Equal(t, exp.via, entry.Via)
+		}
+	})
+}
+
+func TestBlameAcrossBranchMerge(t *testing.T) {
+	c.WithLinkerPair(t, func(lkrSrc, lkrDst *c.Linker) {
+		c.MustTouchAndCommit(t, lkrDst, "/y", 1)
+
+		// Get a version from src on a separate branch:
+		head, err := lkrDst.Head()
+		require.Nil(t, err)
+		require.Nil(t, lkrDst.CreateBranch("dev", head))
+		require.Nil(t, lkrDst.SwitchBranch("dev", false))
+
+		c.MustTouchAndCommit(t, lkrSrc, "/x", 2)
+		require.Nil(t, Sync(lkrSrc, lkrDst, nil))
+
+		devHead, err := lkrDst.Head()
+		require.Nil(t, err)
+
+		require.Nil(t, lkrDst.SwitchBranch(c.DefaultBranch, false))
+		require.Nil(t, MergeCommit(lkrDst, "dev", devHead, nil))
+
+		x, err := lkrDst.LookupModNode("/x")
+		require.Nil(t, err)
+
+		head, err = lkrDst.Head()
+		require.Nil(t, err)
+
+		entries, err := Blame(lkrDst, x, head)
+		require.Nil(t, err)
+		require.Len(t, entries, 1)
+
+		// The merge commit introduced it here, but it was synced from src:
+		require.Equal(t, head.TreeHash(), entries[0].Change.Head.TreeHash())
+		require.Equal(t, "src", entries[0].Remote)
+		require.Equal(t, MergeViaSync, entries[0].Via)
+	})
+}
@@ -201,12 +201,12 @@ type Patch struct{ capnp.Struct }
 const Patch_TypeID = 0x927c7336e3054805
 
 func NewPatch(s *capnp.Segment) (Patch, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 1})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 3})
 	return Patch{st}, err
 }
 
 func NewRootPatch(s *capnp.Segment) (Patch, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 1})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 3})
 	return Patch{st}, err
 }
 
@@ -261,12 +261,40 @@ func (s Patch) NewChanges(n int32) (Change_List, error) {
 	return l, err
 }
 
+func (s Patch) SignedHash() ([]byte, error) {
+	p, err := s.Struct.Ptr(1)
+	return []byte(p.Data()), err
+}
+
+func (s Patch) HasSignedHash() bool {
+	p, err := s.Struct.Ptr(1)
+	return p.IsValid() || err != nil
+}
+
+func (s Patch) SetSignedHash(v []byte) error {
+	return s.Struct.SetData(1, v)
+}
+
+func (s Patch) Signature() ([]byte, error) {
+	p, err := s.Struct.Ptr(2)
+	return []byte(p.Data()), err
+}
+
+func (s Patch) HasSignature() bool {
+	p, err := s.Struct.Ptr(2)
+	return p.IsValid() || err != nil
+}
+
+func (s Patch) SetSignature(v []byte) error {
+	return s.Struct.SetData(2, v)
+}
+
 // Patch_List is a list of Patch.
 type Patch_List struct{ capnp.List }
 
 // NewPatch creates a new list of Patch.
 func NewPatch_List(s *capnp.Segment, sz int32) (Patch_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 1}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 3}, sz)
 	return Patch_List{l}, err
 }
 
@@ -364,42 +392,45 @@ func (p Patches_Promise) Struct() (Patches, error) {
 	return Patches{s}, err
 }
 
-const schema_b943b54bf1683782 = "x\xda\x84\x93\xc1k\x13O\x1c\xc5\xbf\xef;\xbb\xbf\xb4" +
-	"?R\xd319\x88T\xba\x88\x97z\xb0-E\x05\x0f" +
-	"\xd6Z\x05E\xc5\x8c(\xeaEX7\xd3n0\xd9\x84" +
-	"\xcc6V\xb0\x04z\x11=V\x85\x16\x14+\xb4b\xa1" +
-	"R/\x82\x1e\xbc\x08\xfe\x0b\xfe\x03=\x89'\xc1K{" +
-	"Y\x99$\xa6\xa5\x16{[>\xf3\xd8\xd9\xcf\xe3\xed\x10" +
-	"p\x86\x87\xdd\x0c\x88T\xda\xfd/q/\xb8\xeb'\xcc" +
-	"\xc39R}\xe0d\xf6d\xf8\xf3\xd2\x87\xf1O\xe4\"" +
-	"E4\\\xde\x0f9\x93\x923\xfdre\x8d\x90\xac=" +
-	"~\xf4\xabgh\xee\xb9\xcdb[\xd6M\x11\x8d\xdc\xc6" +
-	"Ad\x8bHe\x8b\xe8\x1fY\xc0M\x10\x92C\xc7\x97" +
-	"Ow]\x9d\xffB\xb2\x0f;\xde=\xf2\x9d\x0f#\xbb" +
-	"\xc1\xa9\xec\x06\xf7g\x07\xc4(!\x09\xfcx\xc2\x0c\xd6" +
-	"\x03a\x06\x03\xbf\x1aU\x07\xab~\x1c\x84\xc7\x9a\xcf\xa7" +
-	"\xf2~\x1c \xcc\x03\xca\x01'w\x9e\xbeR\x9f\xbf=" +
-	"\xf9J\xcaa\x8c\xf5\x01i\"\x89\xcd\xc4\xa6B/\xa8" +
-	"p\x14\xfb\xc5\xc8x\xbeg\x8a\xd1dI{\xa3A\xe8" +
-	"G\x93\xdaZ\x0b\x87\xc8\x01\x91<\x7f\x8dH\x9d\x13P" +
-	"y\x86\x04r\xb0\xf0\x8a\x85\x97\x05\xd4-\x068\x07&" +
-	"\x927\xce\x12\xa9\xbc\x80*1\x92\x89Z\xa5|1*" +
-	"h\xc24\\b\xb8\xf6\xcb\xa7j\xb5\x1d\xac\xd1\xba\xd0" +
-	"`\x1f!/\x80\xde\xad\xfa\x08\x16\xfe[w<\xf4#" +
-	"1\xa9w\xf7\xf5\x9a\xbe\xc3\xf8\x1f\xc9x\xf3\x16\xaf " +
-	"\xb4\x09j\xc5\xbbz\x9br\xdb\x18\xea@\xc7x\xe1(" +
-	"\x91z&\xa0\x16\x19\x7f\x84_Z6/\xa0\x96\x18\x92" +
-	"\xd12~m\xe1\x0b\x01\xf5\x96!\x05\xe7 \x88\xe4\xb2" +
-	"\x85\x8b\x02j\x95!\x1d\x91\x83C$Wl7K\x02" +
-	"\xea=C\xbaN\x0e.\x91|7K\xa4V\x05\xd4G" +
-	"F\xa6\xec\x9b{\xe8&F7!\x13j\xbf\x80\xded" +
-	"}s\xa2\xda\xf81\xf0\xc6v\xd1K\xc8Dz:\xde" +
-	"\x05\xdb^\xff\xc6\x8dr\xa5\xae\x0b\xd7+H\x13#M" +
-	"H\xee\xfb&_\xd3\xf5\"*S\xa6\xf4`,\xa6\xce" +
-	"\xc9\x9e\x93J\x85\xda\xecYrsU\xdax\"\xa8\xb4" +
-	"wet]\xd7\xfc\x92Wm\x9d\x10\x94\xd3)\xb9\xc7" +
-	"6\xd2%\xa0\x8e0\x1a\xed\xc0\xd6\x0a:?\\k\x05" +
-	"\xbf\x03\x00\x00\xff\xff\xe5\xc7\xe9\x1d"
+const schema_b943b54bf1683782 = "x\xda\x84\x93\xc1k\x13[\x14\xc6\xcfw\xef\xccK\xfb" +
+	"H^:/Y\xa8T2\x8a\x1b\xbb\xb0\x0dE\x05\x17" +
+	"\xd6\xdaM\xc5\x85\xb9\"\x08.\x84\xeb\xe46\x13l&" +
+	"a\xee4\xb6\xd0\x12(\x8aU\x10\xa4*TPl\xa1" +
+	"\x95\x16*\xbaP\xd0\x85\x1b\xc1\x7fAp\xddUq%" +
+	"\xb8\xeaf\xe4&1\x0dZ\xecn\xf8\x9d\xef\xc0\x99\xdf" +
+	"|3t\x04\xe7X\xdeN\x83H$\xed\x7fb{\xdc" +
+	"\xde:\xa5g\x17I\xf4\x83\xc5\xf3\xa7\xfd\xef\x17\xdf\x8d" +
+	"} \x9b'\x88\xf2\x95\xff\xe1\xcc%\x9c\xb9\\~#" +
+	"\x07B\xfc\xfa\xde\xdd\x1f\xa9\xa1\xc5'&\x8c\xae\xb0\x9d" +
+	" \x1a\xfe\x8aC\xc8l#\x91\xd9Fn\xf8 \xbbj" +
+	"\x16\x0e\x9f\\;\xdbsi\xe9\x139\xfd\xddy\x98|" +
+	"\x99\x1fEf\x86'23<\x97\xd9\xe0#\x84\xd8\x93" +
+	"\xd1\x84\x1e\xac{\\\x0fz\xb2\x16\xd4\x06k2\xf2\xfc" +
+	"\x13\xcd\xe73\x05\x19y\xf0\x0b\x80\xb0\xc0\xe2\xeb\x8f^" +
+	"\x88\x8f_\xee\x7f&a1\x8c\xf6\x03I\"\x07;\xb1" +
+	"I\xf9\xaeWeA$\xcb\x81v\xa5\xab\xcbAiR" +
+	"\xb9#\x9e/\x83\x92\"\x12Yn\x11Y r\xe6." +
+	"\x13\x89Y\x0e\xb1\xc0\xe0\x00Y\x18x\xc7\xc0\xdb\x1c\xe2" +
+	"!\x03X\x16\x8c\xc8yp\x9eH,p\x88U\x06\x87" +
+	"#\x0bN\xe4\xac\\#\x12\xcb\x1cb\x93\xc1\xb1X\x16" +
+	"\x16\x91\xb3a\xb6\xd79\xc4[\x86x\"\xacV.\x04" +
+	"EE\x98\x86M\x0c\xb6y\xc7\xa90\xfc\x8d5Z\xa7" +
+	"i\xfcG(p\xa0oW4\xc1\xc0X\x97K\x81*" +
+	"\x8eK\xe2\xdaG\x8a\x18Rm(\xa3\xa9\x90\xa0:\xec" +
+	"\xaf\x06\xc7|\x19\xf0\x92\xda[\xa1\xdbT\x98\xc7\xbf\x88" +
+	"\xc7\x9a\xe7\xb8E\xae\xb4\x17\x96o\xa8.\x8bm\x89\x10" +
+	"\x07:\x12\x9f\x0e\x10\x89\xc7\x1cb\x99\xe1\x97\xc3\xe7\x86" +
+	"-\xb5}1\xb4$\xae\x18\xf8\x8cC\xac\x1b\x89\xac%" +
+	"qm\xa0[\"oK4\xbaW9\xc4\x1b\x06\xc7\xb6" +
+	"\xb2\xb0\x89\x9cW\xf3Db\x93C\xbcgHW\xa4\xbe" +
+	"\x89^b\xe8%\xa4}%\x8b\xe8\x8b\xb7v&j\x8d" +
+	"o\xc7_\x12\x01}\x84t\xa0\xa6\xa3=\xb0\xf9\x00\x7f" +
+	"\xe2F\xa5ZW\xc5+U$\x89!I\x88oI]" +
+	"\x08U\xbd\x8c\xea\x94\x9e\x9c\x19\x8d\xa83\xd9\xb7\xa5\x09" +
+	"_\xe9}%7\x8b\xaa\xb4\xcb\xbdj\xbb\xaaZ\xd5U" +
+	"('\xddZkB\x10VGr\xca\x18\xe9\xe1\x10\xc7" +
+	"\x18\x1a\xed\xc0n]:?q\xab.?\x07\x00\xae\xb3" +
+	"\xfbS"
 
 func init() {
 	schemas.Register(schema_b943b54bf1683782,
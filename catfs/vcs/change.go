@@ -27,8 +27,17 @@ const (
 	ChangeTypeMove
 	// ChangeTypeRemove says that the node was removed after HEAD.
 	ChangeTypeRemove
+	// ChangeTypeMode says that the mode or owner of the node changed after HEAD.
+	// It can be combined with all other change types.
+	ChangeTypeMode
+	// ChangeTypeXattr says that the extended attributes of the node changed
+	// after HEAD. Like ChangeTypeMode, it can be combined with all other types.
+	ChangeTypeXattr
 )
 
+// attrMask contains all change types that only touch metadata.
+const attrMask = ChangeTypeMode | ChangeTypeXattr
+
 // ChangeType is a mask of possible state change events.
 type ChangeType uint8
 
@@ -48,6 +57,12 @@ func (ct ChangeType) String() string {
 	if ct&ChangeTypeRemove != 0 {
 		v = append(v, "removed")
 	}
+	if ct&ChangeTypeMode != 0 {
+		v = append(v, "mode")
+	}
+	if ct&ChangeTypeXattr != 0 {
+		v = append(v, "xattr")
+	}
 
 	if len(v) == 0 {
 		return "none"
@@ -61,6 +76,7 @@ func (ct ChangeType) String() string {
 // without loosing any content. We may loose metadata though,
 // e.g. when one side was moved, but the other removed:
 // Here the remove would win and no move is counted.
+// Mode and xattr changes never cause a conflict on their own.
 func (ct ChangeType) IsCompatible(ot ChangeType) bool {
 	modifyMask := ChangeTypeAdd | ChangeTypeModify
 	return ct&modifyMask == 0 || ot&modifyMask == 0
@@ -153,6 +169,10 @@ func replayAdd(lkr *c.Linker, currNd n.ModNode) error {
 		if _, err := c.StageFromFileNode(lkr, currNd.(*n.File)); err != nil {
 			return e.Wrapf(err, "replay: stage")
 		}
+	case *n.Symlink:
+		if _, err := c.StageSymlink(lkr, currNd.Path(), currNd.(*n.Symlink).Target(), currNd.ModTime()); err != nil {
+			return e.Wrapf(err, "replay: symlink")
+		}
 	case *n.Directory:
 		if _, err := c.Mkdir(lkr, currNd.Path(), true); err != nil {
 			return e.Wrapf(err, "replay: mkdir")
@@ -246,8 +266,55 @@ func replayRemove(lkr *c.Linker, ch *Change) error {
 	return nil
 }
 
+func replayAttrs(lkr *c.Linker, ch *Change) error {
+	// Directories with children only transmit their mode,
+	// so they might not exist yet at this point.
+	if ch.Curr.Type() == n.NodeTypeDirectory {
+		if _, err := c.Mkdir(lkr, ch.Curr.Path(), true); err != nil {
+			return e.Wrapf(err, "replay: mkdir")
+		}
+	}
+
+	currNd, err := lkr.LookupModNode(ch.Curr.Path())
+	if err != nil {
+		return e.Wrapf(err, "replay: lookup: %v", ch.Curr.Path())
+	}
+
+	if currNd.Type() == n.NodeTypeGhost {
+		return nil
+	}
+
+	if ch.Curr.HasMode() {
+		if err := c.Chmod(lkr, currNd, ch.Curr.Mode()); err != nil {
+			return e.Wrap(err, "replay: chmod")
+		}
+	}
+
+	if uid, gid, ok := ch.Curr.Owner(); ok {
+		if err := c.Chown(lkr, currNd, uid, gid); err != nil {
+			return e.Wrap(err, "replay: chown")
+		}
+	}
+
+	// Only replace the xattrs if they were actually part of the change,
+	// otherwise patches of older versions would wipe them.
+	if ch.Mask&ChangeTypeXattr != 0 {
+		// Chmod/Chown might have replaced the node in the meantime.
+		currNd, err = lkr.LookupModNode(ch.Curr.Path())
+		if err != nil {
+			return e.Wrapf(err, "replay: lookup: %v", ch.Curr.Path())
+		}
+
+		if err := c.CopyXattrs(lkr, ch.Curr, currNd); err != nil {
+			return e.Wrap(err, "replay: xattr")
+		}
+	}
+
+	return nil
+}
+
 // Replay applies the change `ch` onto `lkr` by redoing the same operations:
-// move, remove, modify, add. Commits are not replayed, everything happens in
+// move, remove, modify, add, mode, xattr. Commits are not replayed, everything happens in
 // lkr.Status() without creating a new commit.
 func (ch *Change) Replay(lkr *c.Linker) error {
 	return lkr.Atomic(func() (bool, error) {
@@ -273,6 +340,15 @@ func (ch *Change) Replay(lkr *c.Linker) error {
 			}
 		}
 
+		// Added or modified nodes might come with a mode,
+		// so make sure to take it over in those cases too.
+		modeMask := attrMask | ChangeTypeAdd | ChangeTypeModify
+		if ch.Mask&modeMask != 0 && ch.Curr.Type() != n.NodeTypeGhost {
+			if err := replayAttrs(lkr, ch); err != nil {
+				return true, err
+			}
+		}
+
 		return false, nil
 	})
 }
@@ -83,7 +83,7 @@ func (df *Diff) handleMove(src, dst n.ModNode) error {
 	return nil
 }
 
-func (df *Diff) handleConflict(src, dst n.ModNode, srcMask, dstMask ChangeType) error {
+func (df *Diff) handleConflict(src, dst, base n.ModNode, srcMask, dstMask ChangeType) error {
 	df.Conflict = append(df.Conflict, DiffPair{
 		Src:     src,
 		Dst:     dst,
@@ -105,6 +105,22 @@ func (df *Diff) handleMerge(src, dst n.ModNode, srcMask, dstMask ChangeType) err
 	return nil
 }
 
+func (df *Diff) handleAttrs(src, dst n.ModNode, srcMask, dstMask ChangeType) error {
+	// Only report it when sync would actually take over the remote's attributes.
+	if takeOverAttrs(srcMask, dstMask) == 0 {
+		return nil
+	}
+
+	df.Merged = append(df.Merged, DiffPair{
+		Src:     src,
+		Dst:     dst,
+		SrcMask: srcMask,
+		DstMask: dstMask,
+	})
+
+	return nil
+}
+
 // MakeDiff show the differences between two linkers.
 //
 // Internally it works like Sync() but does not modify anything and just
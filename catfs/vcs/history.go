@@ -76,6 +76,18 @@ func (hw *HistoryWalker) maskFromState(curr, next n.ModNode) ChangeType {
 		mask |= ChangeTypeModify
 	}
 
+	// Mode, owner and xattrs are not part of the content hash,
+	// check them separately.
+	if !isGhostCurr && !isGhostNext {
+		if !n.SameModeAndOwner(curr, next) {
+			mask |= ChangeTypeMode
+		}
+
+		if !n.SameXattrs(curr, next) {
+			mask |= ChangeTypeXattr
+		}
+	}
+
 	if next.Path() != curr.Path() {
 		mask |= ChangeTypeMove
 	} else {
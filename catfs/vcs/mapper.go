@@ -35,6 +35,9 @@ import (
 //
 // If SrcWasMoved is true, the two nodes were purely moved,
 // but not modified otherwise.
+//
+// If AttrsOnly is true, both nodes are directories that only differ in their
+// mode or owner. Their children are reported separately.
 type MapPair struct {
 	Src n.ModNode
 	Dst n.ModNode
@@ -42,6 +45,7 @@ type MapPair struct {
 	SrcWasRemoved bool
 	SrcWasMoved   bool
 	TypeMismatch  bool
+	AttrsOnly     bool
 }
 
 // flags that are set during the mapper run.
@@ -165,6 +169,12 @@ func (ma *Mapper) reportByType(src, dst n.ModNode) error {
 			return ma.report(src, dst, isTypeMismatch, false, true)
 		}
 
+		// Same content, but the mode or owner differs.
+		// Let the resolver figure out which side changed it.
+		if !n.SameAttrs(src, dst) {
+			return ma.report(src, dst, isTypeMismatch, false, false)
+		}
+
 		// The files appear to be equal.
 		// We need to remember to not output them again.
 		ma.setSrcHandled(src)
@@ -175,7 +185,8 @@ func (ma *Mapper) reportByType(src, dst n.ModNode) error {
 	return ma.report(src, dst, isTypeMismatch, false, false)
 }
 
-func (ma *Mapper) mapFile(srcCurr *n.File, dstFilePath string) error {
+// mapFile maps a leaf node (i.e. a file or a symlink) to its counterpart.
+func (ma *Mapper) mapFile(srcCurr n.ModNode, dstFilePath string) error {
 	// Check if we already visited this file.
 	if ma.isSrcVisited(srcCurr) {
 		return nil
@@ -207,14 +218,15 @@ func (ma *Mapper) mapFile(srcCurr *n.File, dstFilePath string) error {
 
 		// File and Directory don't go well together.
 		return ma.report(srcCurr, dstDir, true, false, false)
-	case n.NodeTypeFile:
-		// We have two competing files.
-		dstFile, ok := dstCurr.(*n.File)
+	case n.NodeTypeFile, n.NodeTypeSymlink:
+		// We have two competing files (or symlinks).
+		// reportByType() will notice if only one of them is a symlink.
+		dstLeaf, ok := dstCurr.(n.ModNode)
 		if !ok {
 			return ie.ErrBadNode
 		}
 
-		return ma.reportByType(srcCurr, dstFile)
+		return ma.reportByType(srcCurr, dstLeaf)
 	case n.NodeTypeGhost:
 		// It's still possible that the file was moved or removed on our side.
 		aliveDstCurr, err := ma.ghostToAlive(ma.lkrDst, ma.dstHead, dstCurr)
@@ -261,16 +273,16 @@ func (ma *Mapper) mapDirectoryContents(srcCurr *n.Directory, dstPath string) err
 			if err == nil {
 				ma.setDstHandled(dstCurrNd)
 			}
-		case n.NodeTypeFile:
-			srcChildFile, ok := srcChild.(*n.File)
+		case n.NodeTypeFile, n.NodeTypeSymlink:
+			srcChildLeaf, ok := srcChild.(n.ModNode)
 			if !ok {
 				return ie.ErrBadNode
 			}
 
-			if err := ma.mapFile(srcChildFile, childDstPath); err != nil {
+			if err := ma.mapFile(srcChildLeaf, childDstPath); err != nil {
 				return err
 			}
-			ma.setSrcHandled(srcChildFile)
+			ma.setSrcHandled(srcChildLeaf)
 		case n.NodeTypeGhost:
 			// remote ghosts are ignored, since they were handled beforehand.
 		default:
@@ -336,6 +348,14 @@ func (ma *Mapper) mapDirectory(srcCurr *n.Directory, dstPath string, force bool)
 		return ie.ErrBadNode
 	}
 
+	// Directories are usually not reported, since their state is defined by
+	// their children. Their mode is not though, so report it separately.
+	if !n.SameAttrs(srcCurr, dstCurr) {
+		if err := ma.fn(MapPair{Src: srcCurr, Dst: dstCurr, AttrsOnly: true}); err != nil {
+			return err
+		}
+	}
+
 	// Check if we're lucky and the directory hash is equal:
 	if srcCurr.ContentHash().Equal(dstCurr.ContentHash()) {
 		// Remember that we visited this subtree.
@@ -517,9 +537,9 @@ func (ma *Mapper) extractGhostDirs() ([]ghostDir, error) {
 		}
 
 		switch aliveSrcNd.Type() {
-		case n.NodeTypeFile:
+		case n.NodeTypeFile, n.NodeTypeSymlink:
 			// Mark those both ghosts and original node as visited.
-			err = ma.mapFile(aliveSrcNd.(*n.File), dstRefModNd.Path())
+			err = ma.mapFile(aliveSrcNd, dstRefModNd.Path())
 			ma.setSrcVisited(aliveSrcNd)
 			ma.setSrcVisited(srcNd)
 			return err
@@ -705,17 +725,17 @@ func (ma *Mapper) extractLeftovers(lkr *c.Linker, root *n.Directory, srcToDst bo
 					return err
 				}
 			}
-		case n.NodeTypeFile:
-			file, ok := child.(*n.File)
+		case n.NodeTypeFile, n.NodeTypeSymlink:
+			leaf, ok := child.(n.ModNode)
 			if !ok {
 				return ie.ErrBadNode
 			}
 
 			// Report the leftover:
 			if srcToDst {
-				err = ma.report(file, nil, false, false, false)
+				err = ma.report(leaf, nil, false, false, false)
 			} else {
-				err = ma.report(nil, file, false, false, false)
+				err = ma.report(nil, leaf, false, false, false)
 			}
 
 			if err != nil {
@@ -800,13 +820,13 @@ func (ma *Mapper) Map(fn func(pair MapPair) error) error {
 		// Check for files that we have, but dst does not.
 		// We call those files "missing".
 		return ma.extractLeftovers(ma.lkrDst, dstRoot, false)
-	case n.NodeTypeFile:
-		file, ok := ma.srcRoot.(*n.File)
+	case n.NodeTypeFile, n.NodeTypeSymlink:
+		leaf, ok := ma.srcRoot.(n.ModNode)
 		if !ok {
 			return ie.ErrBadNode
 		}
 
-		return ma.mapFile(file, file.Path())
+		return ma.mapFile(leaf, leaf.Path())
 	case n.NodeTypeGhost:
 		// Not sure how this would happen.
 		return nil
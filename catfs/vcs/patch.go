@@ -1,15 +1,19 @@
 package vcs
 
 import (
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"path"
 	"sort"
+	"strings"
 
 	e "github.com/pkg/errors"
 	c "github.com/sahib/brig/catfs/core"
 	ie "github.com/sahib/brig/catfs/errors"
 	n "github.com/sahib/brig/catfs/nodes"
 	capnp_patch "github.com/sahib/brig/catfs/vcs/capnp"
+	h "github.com/sahib/brig/util/hashlib"
 	"github.com/sahib/brig/util/trie"
 	log "github.com/sirupsen/logrus"
 	capnp "zombiezen.com/go/capnproto2"
@@ -21,6 +25,48 @@ type Patch struct {
 	FromIndex int64
 	CurrIndex int64
 	Changes   []*Change
+
+	// SignedHash is the Digest() of the patch when it was made and
+	// Signature the signature of it. Both are nil for unsigned patches.
+	SignedHash h.Hash
+	Signature  []byte
+}
+
+// Digest returns a hash over the contents of the patch. This is what
+// gets signed, so the receiver can recompute it to check that the patch
+// was not altered. The order of the changes does not matter.
+func (p *Patch) Digest() h.Hash {
+	lines := make([]string, 0, len(p.Changes))
+	for _, change := range p.Changes {
+		nd := change.Curr
+		line := fmt.Sprintf(
+			"%d|%d|%s|%s|%s|%s|%d|%s|%s",
+			change.Mask,
+			nd.Type(),
+			nd.Path(),
+			nd.TreeHash(),
+			nd.ContentHash(),
+			nd.BackendHash(),
+			nd.Size(),
+			change.MovedTo,
+			change.WasPreviouslyAt,
+		)
+
+		// The key and the chunks are not part of the tree hash,
+		// but decide what content the file points to.
+		if file, ok := nd.(*n.File); ok {
+			line += "|" + hex.EncodeToString(file.Key())
+			for _, chunk := range file.Chunks() {
+				line += fmt.Sprintf("|%s:%s:%d", chunk.BackendHash, hex.EncodeToString(chunk.Key), chunk.Size)
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	sort.Strings(lines)
+	header := fmt.Sprintf("%d|%d\n", p.FromIndex, p.CurrIndex)
+	return h.Sum([]byte(header + strings.Join(lines, "\n")))
 }
 
 // Patches is just a list of patches
@@ -76,6 +122,14 @@ func (p *Patch) toCapnpPatch(seg *capnp.Segment, capPatch capnp_patch.Patch) err
 	capPatch.SetFromIndex(p.FromIndex)
 	capPatch.SetCurrIndex(p.CurrIndex)
 
+	if err := capPatch.SetSignedHash(p.SignedHash); err != nil {
+		return err
+	}
+
+	if err := capPatch.SetSignature(p.Signature); err != nil {
+		return err
+	}
+
 	capChangeLst, err := capnp_patch.NewChange_List(seg, int32(len(p.Changes)))
 	if err != nil {
 		return err
@@ -161,6 +215,17 @@ func (p *Patch) fromCapnpPatch(capPatch capnp_patch.Patch) error {
 	p.FromIndex = capPatch.FromIndex()
 	p.CurrIndex = capPatch.CurrIndex()
 
+	signedHash, err := capPatch.SignedHash()
+	if err != nil {
+		return err
+	}
+
+	p.SignedHash = signedHash
+	p.Signature, err = capPatch.Signature()
+	if err != nil {
+		return err
+	}
+
 	capChs, err := capPatch.Changes()
 	if err != nil {
 		return err
@@ -331,7 +396,30 @@ func MakePatches(lkr *c.Linker, from *n.Commit, prefixes []string) (Patches, err
 }
 
 // MakePatchFromTo makes a patch between two commits `from` (older one)  and `to` (newer one)
+// If the linker can sign, the patch is signed with it.
 func MakePatchFromTo(lkr *c.Linker, from, to *n.Commit, prefixes []string) (*Patch, error) {
+	patch, err := makePatchFromTo(lkr, from, to, prefixes)
+	if err != nil {
+		return nil, err
+	}
+
+	// The signature has to cover the patch as it is sent,
+	// i.e. after it was filtered by `prefixes`.
+	digest := patch.Digest()
+	sig, err := lkr.Sign(digest)
+	if err != nil {
+		return nil, e.Wrapf(err, "sign patch")
+	}
+
+	if sig != nil {
+		patch.SignedHash = digest
+		patch.Signature = sig
+	}
+
+	return patch, nil
+}
+
+func makePatchFromTo(lkr *c.Linker, from, to *n.Commit, prefixes []string) (*Patch, error) {
 	root, err := to.Child(lkr, "does not matter") // child actually means Root for commits
 	if err != nil {
 		return nil, err
@@ -398,13 +486,35 @@ func MakePatchFromTo(lkr *c.Linker, from, to *n.Commit, prefixes []string) (*Pat
 				return e.Wrapf(ie.ErrBadNode, "make-patch: dir")
 			}
 
+			// Mode and xattr changes are interesting though,
+			// since they are not visible in any of the children.
+			modeMask := combCh.Mask & attrMask
+			if combCh.Mask&ChangeTypeAdd != 0 {
+				if dir.HasMode() {
+					modeMask |= ChangeTypeMode
+				}
+
+				if len(dir.ListXattr()) > 0 {
+					modeMask |= ChangeTypeXattr
+				}
+			}
+
 			if combCh.Mask&ChangeTypeMove == 0 {
 				if dir.NChildren() > 0 {
-					return nil
+					if modeMask == 0 {
+						return nil
+					}
+
+					combCh.Mask = modeMask
+				} else {
+					combCh.Mask |= modeMask
 				}
 			} else {
-				combCh.Mask = ChangeTypeMove
+				combCh.Mask = ChangeTypeMove | modeMask
 			}
+		} else if combCh.Mask&ChangeTypeAdd != 0 && len(child.ListXattr()) > 0 {
+			// New nodes should bring their xattrs with them.
+			combCh.Mask |= ChangeTypeXattr
 		}
 
 		// Some special filtering needs to be done here. If it'a "move" ghost
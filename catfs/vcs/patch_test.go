@@ -1,6 +1,7 @@
 package vcs
 
 import (
+	"os"
 	"testing"
 
 	c "github.com/sahib/brig/catfs/core"
@@ -37,8 +38,10 @@ func TestPatchMarshalling(t *testing.T) {
 		}
 
 		patch := &Patch{
-			FromIndex: head.Index(),
-			Changes:   []*Change{change2, change1},
+			FromIndex:  head.Index(),
+			Changes:    []*Change{change2, change1},
+			SignedHash: nextNext.TreeHash(),
+			Signature:  []byte("signature"),
 		}
 
 		msg, err := patch.ToCapnp()
@@ -51,6 +54,47 @@ func TestPatchMarshalling(t *testing.T) {
 	})
 }
 
+func TestVerifyPatch(t *testing.T) {
+	c.WithLinkerPair(t, func(lkrSrc, lkrDst *c.Linker) {
+		init, err := lkrSrc.Head()
+		require.Nil(t, err)
+
+		lkrSrc.SetSignFunc(testSign)
+		c.MustTouch(t, lkrSrc, "/x", 1)
+		c.MustTouch(t, lkrSrc, "/y", 2)
+		c.MustCommit(t, lkrSrc, "2 files")
+
+		patch, err := MakePatch(lkrSrc, init, []string{"/"})
+		require.Nil(t, err)
+		require.Equal(t, patch.Digest(), patch.SignedHash)
+		require.Nil(t, VerifyPatch(patch, testVerify, true))
+
+		// The digest does not depend on the order of the changes:
+		patch.Changes[0], patch.Changes[1] = patch.Changes[1], patch.Changes[0]
+		require.Nil(t, VerifyPatch(patch, testVerify, true))
+
+		// Leaving out changes invalidates the signature:
+		patch.Changes = patch.Changes[1:]
+		require.Error(t, VerifyPatch(patch, testVerify, true))
+
+		// So does re-using the signature of another patch:
+		c.MustTouch(t, lkrSrc, "/z", 3)
+		c.MustCommit(t, lkrSrc, "another file")
+		otherPatch, err := MakePatch(lkrSrc, init, []string{"/"})
+		require.Nil(t, err)
+		otherPatch.SignedHash = patch.SignedHash
+		otherPatch.Signature = patch.Signature
+		require.Error(t, VerifyPatch(otherPatch, testVerify, true))
+
+		// Unsigned patches are only accepted if not required:
+		lkrSrc.SetSignFunc(nil)
+		unsigned, err := MakePatch(lkrSrc, init, []string{"/"})
+		require.Nil(t, err)
+		require.Nil(t, VerifyPatch(unsigned, testVerify, false))
+		require.Error(t, VerifyPatch(unsigned, testVerify, true))
+	})
+}
+
 func TestPrefixTrie(t *testing.T) {
 	prefixes := []string{
 		"/a",
@@ -302,3 +346,82 @@ func TestSyncPartialTwiceWithMovedFile(t *testing.T) {
 		require.Len(t, diff.Ignored, 0)
 	})
 }
+
+func TestMakePatchMode(t *testing.T) {
+	c.WithLinkerPair(t, func(lkrSrc, lkrDst *c.Linker) {
+		init, err := lkrSrc.Head()
+		require.Nil(t, err)
+
+		srcDir := c.MustMkdir(t, lkrSrc, "/sub")
+		c.MustTouch(t, lkrSrc, "/sub/x", 1)
+		c.MustChmod(t, lkrSrc, srcDir, 0700)
+		c.MustCommit(t, lkrSrc, "add dir")
+
+		patch, err := MakePatch(lkrSrc, init, []string{"/"})
+		require.Nil(t, err)
+		require.Nil(t, ApplyPatch(lkrDst, patch))
+
+		dstDir, err := lkrDst.LookupDirectory("/sub")
+		require.Nil(t, err)
+		require.Equal(t, os.FileMode(0700), dstDir.Mode())
+
+		next, err := lkrSrc.Head()
+		require.Nil(t, err)
+
+		srcX, err := lkrSrc.LookupModNode("/sub/x")
+		require.Nil(t, err)
+		c.MustChmod(t, lkrSrc, srcX, 0755)
+		c.MustCommit(t, lkrSrc, "chmod")
+
+		patch, err = MakePatch(lkrSrc, next, []string{"/"})
+		require.Nil(t, err)
+		require.Len(t, patch.Changes, 1)
+		require.Equal(t, ChangeTypeMode, patch.Changes[0].Mask)
+		require.Nil(t, ApplyPatch(lkrDst, patch))
+
+		dstX, err := lkrDst.LookupFile("/sub/x")
+		require.Nil(t, err)
+		require.Equal(t, os.FileMode(0755), dstX.Mode())
+		require.True(t, dstX.ContentHash().Equal(h.TestDummy(t, 1)))
+	})
+}
+
+func TestMakePatchXattr(t *testing.T) {
+	c.WithLinkerPair(t, func(lkrSrc, lkrDst *c.Linker) {
+		init, err := lkrSrc.Head()
+		require.Nil(t, err)
+
+		c.MustMkdir(t, lkrSrc, "/sub")
+		srcX := c.MustTouch(t, lkrSrc, "/sub/x", 1)
+		require.Nil(t, c.SetXattr(lkrSrc, srcX, "user.tags", []byte("red")))
+		c.MustCommit(t, lkrSrc, "add tagged file")
+
+		patch, err := MakePatch(lkrSrc, init, []string{"/"})
+		require.Nil(t, err)
+		require.Nil(t, ApplyPatch(lkrDst, patch))
+
+		dstX, err := lkrDst.LookupFile("/sub/x")
+		require.Nil(t, err)
+		value, ok := dstX.Xattr("user.tags")
+		require.True(t, ok)
+		require.Equal(t, []byte("red"), value)
+
+		next, err := lkrSrc.Head()
+		require.Nil(t, err)
+
+		srcNd, err := lkrSrc.LookupModNode("/sub/x")
+		require.Nil(t, err)
+		require.Nil(t, c.RemoveXattr(lkrSrc, srcNd, "user.tags"))
+		c.MustCommit(t, lkrSrc, "untag")
+
+		patch, err = MakePatch(lkrSrc, next, []string{"/"})
+		require.Nil(t, err)
+		require.Len(t, patch.Changes, 1)
+		require.Equal(t, ChangeTypeXattr, patch.Changes[0].Mask)
+		require.Nil(t, ApplyPatch(lkrDst, patch))
+
+		dstX, err = lkrDst.LookupFile("/sub/x")
+		require.Nil(t, err)
+		require.Empty(t, dstX.ListXattr())
+	})
+}
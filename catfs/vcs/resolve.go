@@ -61,7 +61,8 @@ import (
 )
 
 var (
-	conflictNodePattern = regexp.MustCompile(`/.*\.conflict\.\d+`)
+	conflictNodePattern   = regexp.MustCompile(`/.*\.conflict\.\d+`)
+	conflictSuffixPattern = regexp.MustCompile(`\.conflict\.\d+$`)
 )
 
 // executor is the interface that executes the actual action
@@ -74,7 +75,8 @@ type executor interface {
 	handleConflictNode(src n.ModNode) error
 	handleTypeConflict(src, dst n.ModNode) error
 	handleMerge(src, dst n.ModNode, srcMask, dstMask ChangeType) error
-	handleConflict(src, dst n.ModNode, srcMask, dstMask ChangeType) error
+	handleConflict(src, dst, base n.ModNode, srcMask, dstMask ChangeType) error
+	handleAttrs(src, dst n.ModNode, srcMask, dstMask ChangeType) error
 }
 
 //////////////////////////////////////////////
@@ -89,6 +91,10 @@ type resolver struct {
 	dstHead *n.Commit
 	srcHead *n.Commit
 
+	// The merge marker that identifies earlier merges with src.
+	// If empty, the owner of src is used.
+	mergeWith string
+
 	// cached attributes:
 	dstMergeCmt *n.Commit
 	srcMergeCmt *n.Commit
@@ -157,16 +163,21 @@ func (rv *resolver) resolve() error {
 }
 
 func (rv *resolver) cacheLastCommonMerge() error {
-	srcOwner, err := rv.lkrSrc.Owner()
-	if err != nil {
-		return err
+	mergeWith := rv.mergeWith
+	if mergeWith == "" {
+		srcOwner, err := rv.lkrSrc.Owner()
+		if err != nil {
+			return err
+		}
+
+		mergeWith = srcOwner
 	}
 
 	currHead := rv.dstHead
 
 	for currHead != nil {
 		with, srcRef := currHead.MergeMarker()
-		if with == srcOwner {
+		if with == mergeWith {
 			srcHead, err := rv.lkrSrc.CommitByHash(srcRef)
 			if err != nil {
 				return err
@@ -203,6 +214,18 @@ func isConflictPath(path string) bool {
 	return conflictNodePattern.MatchString(path)
 }
 
+// IsConflictPath reports if `path` belongs to a conflict file.
+func IsConflictPath(path string) bool {
+	return isConflictPath(path) && conflictSuffixPattern.MatchString(path)
+}
+
+// ConflictOrigin returns the path of the node that the conflict file
+// at `path` was created for. If `path` is no conflict file,
+// it is returned unchanged.
+func ConflictOrigin(path string) string {
+	return conflictSuffixPattern.ReplaceAllString(path, "")
+}
+
 // hasConflictFile reports if we already created a conflict file for `dstNd`.
 func (rv *resolver) hasConflictFile(dstNd n.ModNode) (bool, error) {
 	parent, err := rv.lkrDst.LookupDirectory(path.Dir(dstNd.Path()))
@@ -236,20 +259,28 @@ func (rv *resolver) hasConflictFile(dstNd n.ModNode) (bool, error) {
 // not have the same hash. In the best case, both have compatible changes and
 // can be merged, otherwise a user defined conflict strategy has to be applied.
 func (rv *resolver) hasConflicts(src, dst n.ModNode) (bool, ChangeType, ChangeType, error) {
+	hasConflicts, srcMask, dstMask, _, err := rv.checkConflicts(src, dst)
+	return hasConflicts, srcMask, dstMask, err
+}
+
+// checkConflicts works like hasConflicts, but also returns the last version
+// of the node (as seen by dst) that both sides had in common. This is nil if
+// there is no common version within the history since the last merge.
+func (rv *resolver) checkConflicts(src, dst n.ModNode) (bool, ChangeType, ChangeType, n.ModNode, error) {
 	// Nodes with same hashes are no conflicts...
 	// (tree hash is also influenced by content)
 	if src.TreeHash().Equal(dst.TreeHash()) {
-		return false, 0, 0, nil
+		return false, 0, 0, nil, nil
 	}
 
 	srcHist, err := History(rv.lkrSrc, src, rv.srcHead, rv.srcMergeCmt)
 	if err != nil {
-		return false, 0, 0, e.Wrapf(err, "history src")
+		return false, 0, 0, nil, e.Wrapf(err, "history src")
 	}
 
 	dstHist, err := History(rv.lkrDst, dst, rv.dstHead, rv.dstMergeCmt)
 	if err != nil {
-		return false, 0, 0, e.Wrapf(err, "history dst")
+		return false, 0, 0, nil, e.Wrapf(err, "history dst")
 	}
 
 	// This loop can be optimized if the need arises:
@@ -260,13 +291,19 @@ func (rv *resolver) hasConflicts(src, dst n.ModNode) (bool, ChangeType, ChangeTy
 		for dstIdx := 0; dstIdx < len(dstHist) && !commonRootFound; dstIdx++ {
 			srcChange, dstChange := srcHist[srcIdx], dstHist[dstIdx]
 
-			if srcChange.Curr.ContentHash().Equal(dstChange.Curr.ContentHash()) {
+			srcCurr, dstCurr := srcChange.Curr, dstChange.Curr
+			if srcCurr.ContentHash().Equal(dstCurr.ContentHash()) && n.SameAttrs(srcCurr, dstCurr) {
 				srcRoot, dstRoot = srcIdx, dstIdx
 				commonRootFound = true
 			}
 		}
 	}
 
+	var base n.ModNode
+	if commonRootFound {
+		base = dstHist[dstRoot].Curr
+	}
+
 	srcHist = srcHist[:srcRoot]
 	dstHist = dstHist[:dstRoot]
 
@@ -280,19 +317,19 @@ func (rv *resolver) hasConflicts(src, dst n.ModNode) (bool, ChangeType, ChangeTy
 	}
 
 	if len(srcHist) == 0 && len(dstHist) == 0 {
-		return false, 0, 0, nil
+		return false, 0, 0, base, nil
 	}
 
 	// Handle a few lucky cases:
 	if len(srcHist) > 0 && len(dstHist) == 0 {
 		// We can "fast forward" our node.
 		// There are only remote changes for this file.
-		return false, srcMask, dstMask, nil
+		return false, srcMask, dstMask, base, nil
 
 	}
 	if len(srcHist) == 0 && len(dstHist) > 0 {
 		// Only our side has changes. We can consider this node as merged.
-		return false, 0, 0, nil
+		return false, 0, 0, base, nil
 	}
 
 	// Both sides have changes. Now we need to figure out if they are compatible.
@@ -301,11 +338,75 @@ func (rv *resolver) hasConflicts(src, dst n.ModNode) (bool, ChangeType, ChangeTy
 	if !dstMask.IsCompatible(srcMask) {
 		// The changes are not compatible.
 		// We need to apply a conflict resolution strategy.
-		return true, srcMask, dstMask, nil
+		return true, srcMask, dstMask, base, nil
 	}
 
 	// No conflict. We can merge src and dst.
-	return false, srcMask, dstMask, nil
+	return false, srcMask, dstMask, base, nil
+}
+
+// attrMaskSince checks if the mode, owner or xattrs of `nd` changed since `mergeCmt`.
+// Nodes without any explicit attributes never count as changed.
+func attrMaskSince(lkr *c.Linker, nd n.ModNode, mergeCmt *n.Commit) (ChangeType, error) {
+	hasXattrs := len(nd.ListXattr()) > 0
+	_, _, hasOwner := nd.Owner()
+	hasModeOrOwner := nd.HasMode() || hasOwner
+	if !hasModeOrOwner && !hasXattrs {
+		return ChangeTypeNone, nil
+	}
+
+	var oldNd n.Node
+	var err error
+	if mergeCmt != nil {
+		oldNd, err = lkr.LookupNodeAt(mergeCmt, nd.Path())
+	}
+
+	if mergeCmt == nil || ie.IsNoSuchFileError(err) {
+		// No merge yet or the node did not exist back then,
+		// so everything is new.
+		mask := ChangeTypeNone
+		if hasModeOrOwner {
+			mask |= ChangeTypeMode
+		}
+
+		if hasXattrs {
+			mask |= ChangeTypeXattr
+		}
+
+		return mask, nil
+	}
+
+	if err != nil {
+		return ChangeTypeNone, err
+	}
+
+	mask := ChangeTypeNone
+	if !n.SameModeAndOwner(oldNd, nd) {
+		mask |= ChangeTypeMode
+	}
+
+	if !n.SameXattrs(oldNd, nd) {
+		mask |= ChangeTypeXattr
+	}
+
+	return mask, nil
+}
+
+// decideAttrs figures out which side changed the attributes of a directory.
+// Directories are not checked with checkConflicts(), since their history
+// is dominated by the changes of their children.
+func (rv *resolver) decideAttrs(src, dst n.ModNode) error {
+	srcMask, err := attrMaskSince(rv.lkrSrc, src, rv.srcMergeCmt)
+	if err != nil {
+		return err
+	}
+
+	dstMask, err := attrMaskSince(rv.lkrDst, dst, rv.dstMergeCmt)
+	if err != nil {
+		return err
+	}
+
+	return rv.exec.handleAttrs(src, dst, srcMask, dstMask)
 }
 
 func pathOrNil(nd n.Node) string {
@@ -335,6 +436,10 @@ func (rv *resolver) decide(pair MapPair) error {
 		return rv.exec.handleConflictNode(pair.Dst)
 	}
 
+	if pair.AttrsOnly {
+		return rv.decideAttrs(pair.Src, pair.Dst)
+	}
+
 	if pair.SrcWasMoved {
 		return rv.exec.handleMove(pair.Src, pair.Dst)
 	}
@@ -360,13 +465,13 @@ func (rv *resolver) decide(pair MapPair) error {
 		return rv.exec.handleTypeConflict(pair.Src, pair.Dst)
 	}
 
-	hasConflicts, srcMask, dstMask, err := rv.hasConflicts(pair.Src, pair.Dst)
+	hasConflicts, srcMask, dstMask, base, err := rv.checkConflicts(pair.Src, pair.Dst)
 	if err != nil {
 		return err
 	}
 
 	if hasConflicts {
-		return rv.exec.handleConflict(pair.Src, pair.Dst, srcMask, dstMask)
+		return rv.exec.handleConflict(pair.Src, pair.Dst, base, srcMask, dstMask)
 	}
 
 	hasConflictFile, err := rv.hasConflictFile(pair.Dst)
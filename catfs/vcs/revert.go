@@ -0,0 +1,231 @@
+package vcs
+
+import (
+	"fmt"
+
+	c "github.com/sahib/brig/catfs/core"
+	ie "github.com/sahib/brig/catfs/errors"
+	n "github.com/sahib/brig/catfs/nodes"
+	log "github.com/sirupsen/logrus"
+)
+
+// reverter undoes the changes of a single commit.
+// It uses a syncer to apply the old versions, so that
+// conflicts are handled like during a sync.
+type reverter struct {
+	lkr    *c.Linker
+	parent *n.Commit
+	sy     *syncer
+
+	// paths of the nodes whose content was restored
+	restored []string
+}
+
+// lookupAlive returns the node at `ndPath` in the staging area
+// or nil if there is none (or only a ghost).
+func (rv *reverter) lookupAlive(ndPath string) (n.ModNode, error) {
+	nd, err := rv.lkr.LookupModNode(ndPath)
+	if ie.IsNoSuchFileError(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if nd.Type() == n.NodeTypeGhost {
+		return nil, nil
+	}
+
+	return nd, nil
+}
+
+// isUnchanged checks if `curr` is still the version `committed`.
+func isUnchanged(curr, committed n.ModNode) bool {
+	if curr.Type() != committed.Type() {
+		return false
+	}
+
+	return curr.ContentHash().Equal(committed.ContentHash()) && n.SameAttrs(curr, committed)
+}
+
+func (rv *reverter) revertChange(ch *Change) error {
+	if ch.Curr.Type() == n.NodeTypeGhost && ch.MovedTo != "" {
+		// The move is reverted together with the node it was moved to.
+		return nil
+	}
+
+	currPath := ch.Curr.Path()
+	curr, err := rv.lookupAlive(currPath)
+	if err != nil {
+		return err
+	}
+
+	if ch.Curr.Type() == n.NodeTypeGhost {
+		// The node was removed by the commit. Bring it back.
+		if curr != nil {
+			log.Warningf("revert: not restoring %s; it was added again since", currPath)
+			return nil
+		}
+
+		if _, err := ResetNode(rv.lkr, rv.parent, currPath); err != nil {
+			return err
+		}
+
+		rv.restored = append(rv.restored, currPath)
+		return nil
+	}
+
+	if curr == nil {
+		log.Warningf("revert: skipping %s; it was removed since", currPath)
+		return nil
+	}
+
+	if ch.Mask&ChangeTypeAdd != 0 {
+		// Removing a node that was changed later would lose those changes.
+		if !isUnchanged(curr, ch.Curr) {
+			log.Warningf("revert: not removing %s; it was modified since", currPath)
+			return nil
+		}
+
+		_, _, err := c.Remove(rv.lkr, curr, true, true)
+		return err
+	}
+
+	oldPath := currPath
+	if ch.Mask&ChangeTypeMove != 0 && ch.WasPreviouslyAt != "" {
+		oldPath = ch.WasPreviouslyAt
+	}
+
+	old, err := rv.lkr.LookupModNodeAt(rv.parent, oldPath)
+	if ie.IsNoSuchFileError(err) {
+		log.Warningf("revert: skipping %s; no previous version at %s", currPath, oldPath)
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if oldPath != currPath {
+		inTheWay, err := rv.lookupAlive(oldPath)
+		if err != nil {
+			return err
+		}
+
+		if inTheWay != nil {
+			log.Warningf("revert: not moving %s back; %s exists already", currPath, oldPath)
+			return nil
+		}
+
+		if err := c.Move(rv.lkr, curr, oldPath); err != nil {
+			return err
+		}
+
+		if curr, err = rv.lkr.LookupModNode(oldPath); err != nil {
+			return err
+		}
+	}
+
+	// Directories only change their attributes on their own.
+	if curr.Type() == n.NodeTypeDirectory || ch.Mask&ChangeTypeModify == 0 {
+		return rv.sy.copyAttrs(old, curr, ch.Mask&attrMask)
+	}
+
+	rv.restored = append(rv.restored, curr.Path())
+	if isUnchanged(curr, ch.Curr) {
+		return rv.sy.handleMerge(old, curr, ch.Mask, ChangeTypeNone)
+	}
+
+	// The node was modified since. Use the old version as "their"
+	// version and the committed one as the common base.
+	return rv.sy.handleConflict(old, curr, ch.Curr, ch.Mask, ChangeTypeModify)
+}
+
+// Revert undoes the changes that `cmt` introduced and records this as new
+// commit. Later commits are kept. If a node was modified again after `cmt`,
+// the old version is handled according to the conflict strategy in `cfg`,
+// just like Sync() would do. The staging area needs to be empty.
+// The paths of the nodes whose old content was restored are returned.
+func Revert(lkr *c.Linker, cmt *n.Commit, cfg *SyncOptions) ([]string, error) {
+	if cfg == nil {
+		cfg = defaultSyncConfig
+	}
+
+	haveStaged, err := lkr.HaveStagedChanges()
+	if err != nil {
+		return nil, err
+	}
+
+	if haveStaged {
+		return nil, ie.ErrStageNotEmpty
+	}
+
+	parentNd, err := cmt.Parent(lkr)
+	if err != nil {
+		return nil, err
+	}
+
+	if parentNd == nil {
+		return nil, fmt.Errorf("cannot revert the initial commit")
+	}
+
+	parent, ok := parentNd.(*n.Commit)
+	if !ok {
+		return nil, ie.ErrBadNode
+	}
+
+	patch, err := MakePatchFromTo(lkr, parent, cmt, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rv := &reverter{
+		lkr:    lkr,
+		parent: parent,
+		sy: &syncer{
+			cfg:    cfg,
+			lkrSrc: lkr,
+			lkrDst: lkr,
+		},
+	}
+
+	err = lkr.Atomic(func() (bool, error) {
+		for _, ch := range patch.Changes {
+			if err := rv.revertChange(ch); err != nil {
+				return true, err
+			}
+		}
+
+		wasModified, err := lkr.HaveStagedChanges()
+		if err != nil {
+			return true, err
+		}
+
+		if !wasModified {
+			return false, ie.ErrNoChange
+		}
+
+		owner, err := lkr.Owner()
+		if err != nil {
+			return true, err
+		}
+
+		message := cfg.Message
+		if message == "" {
+			message = fmt.Sprintf("revert %s (%s)", cmt.TreeHash().ShortB58(), cmt.Message())
+		}
+
+		if err := lkr.MakeCommit(owner, message); err != nil {
+			return true, err
+		}
+
+		return false, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return rv.restored, nil
+}
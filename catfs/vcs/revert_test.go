@@ -0,0 +1,111 @@
+package vcs
+
+import (
+	"testing"
+
+	c "github.com/sahib/brig/catfs/core"
+	ie "github.com/sahib/brig/catfs/errors"
+	h "github.com/sahib/brig/util/hashlib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevertModify(t *testing.T) {
+	c.WithDummyLinker(t, func(lkr *c.Linker) {
+		c.MustTouchAndCommit(t, lkr, "/x", 1)
+		_, modCmt := c.MustTouchAndCommit(t, lkr, "/x", 2)
+		c.MustTouchAndCommit(t, lkr, "/y", 3)
+
+		restored, err := Revert(lkr, modCmt, nil)
+		require.Nil(t, err)
+		require.Equal(t, []string{"/x"}, restored)
+
+		x, err := lkr.LookupFile("/x")
+		require.Nil(t, err)
+		require.Equal(t, h.TestDummy(t, 1), x.ContentHash())
+
+		// Later work is kept:
+		y, err := lkr.LookupFile("/y")
+		require.Nil(t, err)
+		require.Equal(t, h.TestDummy(t, 3), y.ContentHash())
+
+		head, err := lkr.Head()
+		require.Nil(t, err)
+		require.Contains(t, head.Message(), "revert")
+	})
+}
+
+func TestRevertAddAndRemove(t *testing.T) {
+	c.WithDummyLinker(t, func(lkr *c.Linker) {
+		_, addCmt := c.MustTouchAndCommit(t, lkr, "/x", 1)
+
+		restored, err := Revert(lkr, addCmt, nil)
+		require.Nil(t, err)
+		require.Empty(t, restored)
+
+		_, err = lkr.LookupFile("/x")
+		require.True(t, ie.IsNoSuchFileError(err))
+
+		// Reverting the revert brings it back:
+		revertCmt, err := lkr.Head()
+		require.Nil(t, err)
+		restored, err = Revert(lkr, revertCmt, nil)
+		require.Nil(t, err)
+		require.Equal(t, []string{"/x"}, restored)
+
+		x, err := lkr.LookupFile("/x")
+		require.Nil(t, err)
+		require.Equal(t, h.TestDummy(t, 1), x.ContentHash())
+	})
+}
+
+func TestRevertMove(t *testing.T) {
+	c.WithDummyLinker(t, func(lkr *c.Linker) {
+		x, _ := c.MustTouchAndCommit(t, lkr, "/x", 1)
+		c.MustMove(t, lkr, x, "/y")
+		moveCmt := c.MustCommit(t, lkr, "move")
+
+		_, err := Revert(lkr, moveCmt, nil)
+		require.Nil(t, err)
+
+		_, err = lkr.LookupFile("/x")
+		require.Nil(t, err)
+		_, err = lkr.LookupFile("/y")
+		require.True(t, ie.IsNoSuchFileError(err))
+	})
+}
+
+func TestRevertConflict(t *testing.T) {
+	c.WithDummyLinker(t, func(lkr *c.Linker) {
+		c.MustTouchAndCommit(t, lkr, "/x", 1)
+		_, modCmt := c.MustTouchAndCommit(t, lkr, "/x", 2)
+		c.MustTouchAndCommit(t, lkr, "/x", 3)
+
+		_, err := Revert(lkr, modCmt, &SyncOptions{
+			ConflictStrategy: ConflictStragetyMarker,
+		})
+		require.Nil(t, err)
+
+		// Our version stays, the reverted one is put next to it:
+		x, err := lkr.LookupFile("/x")
+		require.Nil(t, err)
+		require.Equal(t, h.TestDummy(t, 3), x.ContentHash())
+
+		conflict, err := lkr.LookupFile("/x.conflict.0")
+		require.Nil(t, err)
+		require.Equal(t, h.TestDummy(t, 1), conflict.ContentHash())
+	})
+}
+
+func TestRevertErrors(t *testing.T) {
+	c.WithDummyLinker(t, func(lkr *c.Linker) {
+		init, err := lkr.Head()
+		require.Nil(t, err)
+		_, err = Revert(lkr, init, nil)
+		require.NotNil(t, err)
+
+		_, cmt := c.MustTouchAndCommit(t, lkr, "/x", 1)
+		c.MustTouch(t, lkr, "/y", 2)
+		_, err = Revert(lkr, cmt, nil)
+		require.Equal(t, ie.ErrStageNotEmpty, err)
+	})
+}
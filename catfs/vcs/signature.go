@@ -0,0 +1,116 @@
+package vcs
+
+import (
+	"fmt"
+
+	c "github.com/sahib/brig/catfs/core"
+	n "github.com/sahib/brig/catfs/nodes"
+)
+
+// VerifyFunc checks if `sig` is a valid signature of `data`
+// made by the key of the person we expect.
+type VerifyFunc func(data, sig []byte) error
+
+// SignatureStatus is the result of checking the signature of a commit.
+type SignatureStatus int
+
+const (
+	// SignatureUnsigned means that the commit carries no signature.
+	SignatureUnsigned = SignatureStatus(iota)
+
+	// SignatureGood means that the signature is valid.
+	SignatureGood
+
+	// SignatureBad means that the signature is not valid,
+	// either because it was forged or made by somebody else.
+	SignatureBad
+)
+
+func (ss SignatureStatus) String() string {
+	switch ss {
+	case SignatureGood:
+		return "good"
+	case SignatureBad:
+		return "bad"
+	default:
+		return "unsigned"
+	}
+}
+
+// CheckSignature checks if `sig` is a valid signature of `hash`.
+func CheckSignature(hash, sig []byte, verify VerifyFunc) SignatureStatus {
+	if len(hash) == 0 || len(sig) == 0 {
+		return SignatureUnsigned
+	}
+
+	if err := verify(hash, sig); err != nil {
+		return SignatureBad
+	}
+
+	return SignatureGood
+}
+
+// VerifyCommit checks the signature of `cmt` with `verify`.
+// The signature has to be made for the hash of `cmt` itself,
+// otherwise it could have been copied from another commit.
+func VerifyCommit(cmt *n.Commit, verify VerifyFunc) SignatureStatus {
+	hash, sig := cmt.Signature()
+	status := CheckSignature(hash, sig, verify)
+	if status == SignatureGood && !hash.Equal(cmt.TreeHash()) {
+		return SignatureBad
+	}
+
+	return status
+}
+
+// VerifyPatch checks the signature attached to `patch`. The digest of the
+// patch is recomputed, so a valid signature of another patch does not help.
+// Unsigned patches are only an error if `requireSigned` is true.
+func VerifyPatch(patch *Patch, verify VerifyFunc, requireSigned bool) error {
+	status := CheckSignature(patch.SignedHash, patch.Signature, verify)
+	if status == SignatureGood && !patch.SignedHash.Equal(patch.Digest()) {
+		status = SignatureBad
+	}
+
+	switch status {
+	case SignatureBad:
+		return fmt.Errorf("patch for commit #%d has a bad signature", patch.CurrIndex)
+	case SignatureUnsigned:
+		if requireSigned {
+			return fmt.Errorf("patch for commit #%d is not signed", patch.CurrIndex)
+		}
+	}
+
+	return nil
+}
+
+// VerifyHistory checks the signatures of all commits reachable from `head`.
+// Bad signatures are always an error. Unsigned commits are only an error if
+// `requireSigned` is true; the initial commit never needs a signature.
+func VerifyHistory(lkr *c.Linker, head *n.Commit, verify VerifyFunc, requireSigned bool) error {
+	return verifyHistory(lkr, head, verify, requireSigned, false)
+}
+
+// verifyHistory is like VerifyHistory. If `allowReplays` is true, commits
+// may also carry the signature of the patch they replay instead of their
+// own. Those commits are only made by applying a patch, which checks
+// the signature against the recomputed digest of the patch before.
+func verifyHistory(lkr *c.Linker, head *n.Commit, verify VerifyFunc, requireSigned, allowReplays bool) error {
+	return c.Log(lkr, head, func(cmt *n.Commit) error {
+		status := VerifyCommit(cmt, verify)
+		if hash, sig := cmt.Signature(); allowReplays && !hash.Equal(cmt.TreeHash()) {
+			status = CheckSignature(hash, sig, verify)
+		}
+
+		switch status {
+		case SignatureBad:
+			return fmt.Errorf("commit %s has a bad signature", cmt.TreeHash().B58String())
+		case SignatureUnsigned:
+			if requireSigned && cmt.Index() > 0 {
+				return fmt.Errorf("commit %s is not signed", cmt.TreeHash().B58String())
+			}
+		}
+
+		return nil
+	})
+}
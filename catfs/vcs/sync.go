@@ -8,6 +8,7 @@ import (
 	c "github.com/sahib/brig/catfs/core"
 	ie "github.com/sahib/brig/catfs/errors"
 	n "github.com/sahib/brig/catfs/nodes"
+	h "github.com/sahib/brig/util/hashlib"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -23,6 +24,10 @@ const (
 
 	// ConflictStragetyUnknown should be used when the strategy is not clear.
 	ConflictStragetyUnknown
+
+	// ConflictStragetyMerge tries to merge the contents of both versions.
+	// It falls back to ConflictStragetyMarker if that is not possible.
+	ConflictStragetyMerge
 )
 
 // ConflictStrategy defines what conflict strategy to apply in case of
@@ -37,6 +42,8 @@ func (cs ConflictStrategy) String() string {
 		return "ignore"
 	case ConflictStragetyEmbrace:
 		return "embrace"
+	case ConflictStragetyMerge:
+		return "merge"
 	default:
 		return "unknown"
 	}
@@ -52,6 +59,8 @@ func ConflictStrategyFromString(spec string) ConflictStrategy {
 		return ConflictStragetyIgnore
 	case "embrace":
 		return ConflictStragetyEmbrace
+	case "merge":
+		return ConflictStragetyMerge
 	default:
 		return ConflictStragetyUnknown
 	}
@@ -62,6 +71,17 @@ type PinStats struct {
 	Pinned, Explicit bool
 }
 
+// MergeResult describes the content of a file after a successful content merge.
+type MergeResult struct {
+	ContentHash h.Hash
+	BackendHash h.Hash
+	Chunks      []n.Chunk
+	Size        uint64
+	CachedSize  int64
+	Key         []byte
+	IsRaw       bool
+}
+
 // SyncOptions gives you the possibility to configure the sync algorithm.
 type SyncOptions struct {
 	ConflictStrategy          ConflictStrategy
@@ -75,8 +95,35 @@ type SyncOptions struct {
 	OnRemove   func(oldNd n.ModNode) bool
 	OnMerge    func(nd n.ModNode, isGet bool, ndPinStats *PinStats) bool
 	OnConflict func(src, dst n.ModNode) bool
+
+	// OnContentMerge is called with the "merge" conflict strategy.
+	// It should merge the contents of `src` and `dst`, using `base` as common
+	// ancestor. If this is not possible, nil should be returned.
+	OnContentMerge func(base, src, dst n.ModNode) (*MergeResult, error)
+
+	// VerifySignature is used to check the signatures of all commits of the
+	// source before syncing. If nil, signatures are not checked.
+	VerifySignature VerifyFunc
+
+	// RequireSignatures makes unsigned commits of the source an error.
+	// It has no effect if VerifySignature is nil.
+	RequireSignatures bool
+
+	// Via is stored in the merge commit to tell how the merge was
+	// triggered (one of the MergeVia* constants). If empty, MergeViaSync
+	// is used for syncs and MergeViaBranch for MergeCommit.
+	Via string
 }
 
+const (
+	// MergeViaSync marks merges that we did by syncing with a remote.
+	MergeViaSync = "sync"
+	// MergeViaPush marks merges that a remote triggered by pushing to us.
+	MergeViaPush = "push"
+	// MergeViaBranch marks merges of a local branch.
+	MergeViaBranch = "branch"
+)
+
 var (
 	defaultSyncConfig = &SyncOptions{}
 )
@@ -114,6 +161,10 @@ func (sy *syncer) add(src n.ModNode, srcParent, srcName string) error {
 			return err
 		}
 
+		if err := sy.copyAttrs(src, newDstNode, attrMask); err != nil {
+			return err
+		}
+
 		srcDir, ok := src.(*n.Directory)
 		if !ok {
 			return ie.ErrBadNode
@@ -148,6 +199,7 @@ func (sy *syncer) add(src n.ModNode, srcParent, srcName string) error {
 		if ok {
 			newDstFile.SetContent(sy.lkrDst, srcFile.ContentHash())
 			newDstFile.SetBackend(sy.lkrDst, srcFile.BackendHash())
+			newDstFile.SetChunks(srcFile.Chunks())
 			newDstFile.SetSize(srcFile.Size())
 			newDstFile.SetCachedSize(srcFile.CachedSize())
 			newDstFile.SetKey(srcFile.Key())
@@ -163,7 +215,41 @@ func (sy *syncer) add(src n.ModNode, srcParent, srcName string) error {
 			return err
 		}
 
-		return sy.lkrDst.StageNode(newDstNode)
+		if err := sy.lkrDst.StageNode(newDstNode); err != nil {
+			return err
+		}
+
+		return sy.copyAttrs(src, newDstNode, attrMask)
+	case n.NodeTypeSymlink:
+		srcSymlink, ok := src.(*n.Symlink)
+		if !ok {
+			return ie.ErrBadNode
+		}
+
+		newDstSymlink := n.NewSymlink(
+			parentDir,
+			srcName,
+			srcSymlink.Target(),
+			src.User(),
+			sy.lkrDst.NextInode(),
+		)
+
+		newDstSymlink.SetModTime(srcSymlink.ModTime())
+		if sy.cfg.OnAdd != nil {
+			if !sy.cfg.OnAdd(newDstSymlink) {
+				return nil
+			}
+		}
+
+		if err := parentDir.Add(sy.lkrDst, newDstSymlink); err != nil {
+			return err
+		}
+
+		if err := sy.lkrDst.StageNode(newDstSymlink); err != nil {
+			return err
+		}
+
+		return sy.copyAttrs(src, newDstSymlink, attrMask)
 	case n.NodeTypeGhost:
 		// skipping addition of a ghost
 		return nil
@@ -277,7 +363,7 @@ func (sy *syncer) getConflictStrategy(nd n.ModNode) ConflictStrategy {
 	return sy.cfg.ConflictStrategy
 }
 
-func (sy *syncer) handleConflict(src, dst n.ModNode, srcMask, dstMask ChangeType) error {
+func (sy *syncer) handleConflict(src, dst, base n.ModNode, srcMask, dstMask ChangeType) error {
 	cs := sy.getConflictStrategy(dst)
 
 	if cs == ConflictStragetyIgnore {
@@ -292,6 +378,21 @@ func (sy *syncer) handleConflict(src, dst n.ModNode, srcMask, dstMask ChangeType
 		return nil
 	}
 
+	if cs == ConflictStragetyMerge {
+		wasMerged, err := sy.mergeContent(src, dst, base)
+		if err != nil {
+			return err
+		}
+
+		if wasMerged {
+			return nil
+		}
+
+		// The changes overlap or the files can't be merged at all.
+		// Fall back to creating a conflict file.
+		log.Debugf("could not merge %s; creating conflict file", dst.Path())
+	}
+
 	log.Debugf("handling conflict: %s <-> %s", src.Path(), dst.Path())
 
 	// Find a path that we do not have yet.
@@ -344,6 +445,13 @@ func (sy *syncer) handleMerge(src, dst n.ModNode, srcMask, dstMask ChangeType) e
 		return nil
 	}
 
+	// Take over the mode and xattrs, unless we changed them ourselves.
+	if mask := takeOverAttrs(srcMask, dstMask); mask != 0 {
+		if err := sy.copyAttrs(src, dst, mask); err != nil {
+			return err
+		}
+	}
+
 	// If src did not change, there's no need to sync the content.
 	// If src has no changes, we know that dst must have changes,
 	// otherwise it would have been reported as conflict.
@@ -351,23 +459,74 @@ func (sy *syncer) handleMerge(src, dst n.ModNode, srcMask, dstMask ChangeType) e
 		return nil
 	}
 
-	dstParent, err := n.ParentDirectory(sy.lkrDst, dst)
+	if src.Type() == n.NodeTypeSymlink {
+		return sy.updateSymlink(src, dst)
+	}
+
+	srcFile, ok := src.(*n.File)
+	if !ok {
+		return ie.ErrBadNode
+	}
+
+	return sy.updateContent(dst, func(dstFile *n.File) {
+		dstFile.SetContent(sy.lkrDst, srcFile.ContentHash())
+		dstFile.SetBackend(sy.lkrDst, srcFile.BackendHash())
+		dstFile.SetChunks(srcFile.Chunks())
+		dstFile.SetSize(srcFile.Size())
+		dstFile.SetCachedSize(srcFile.CachedSize())
+		dstFile.SetKey(srcFile.Key())
+	})
+}
+
+// mergeContent tries to merge the contents of `src` and `dst`.
+// It returns false if this was not possible.
+func (sy *syncer) mergeContent(src, dst, base n.ModNode) (bool, error) {
+	if sy.cfg.OnContentMerge == nil || base == nil {
+		return false, nil
+	}
+
+	if src.Type() != n.NodeTypeFile || dst.Type() != n.NodeTypeFile {
+		return false, nil
+	}
+
+	result, err := sy.cfg.OnContentMerge(base, src, dst)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	if err := dstParent.RemoveChild(sy.lkrDst, dst); err != nil {
-		return err
+	if result == nil {
+		return false, nil
 	}
 
+	log.Debugf("handling content merge: %s <-> %s", src.Path(), dst.Path())
+	err = sy.updateContent(dst, func(dstFile *n.File) {
+		dstFile.SetContent(sy.lkrDst, result.ContentHash)
+		dstFile.SetBackend(sy.lkrDst, result.BackendHash)
+		dstFile.SetChunks(result.Chunks)
+		dstFile.SetSize(result.Size)
+		dstFile.SetCachedSize(result.CachedSize)
+		dstFile.SetKey(result.Key)
+		dstFile.SetIsRaw(result.IsRaw)
+	})
+
+	return err == nil, err
+}
+
+// updateContent modifies the content of `dst` with `setContent`
+// and stages it. The pin state of `dst` is kept.
+func (sy *syncer) updateContent(dst n.ModNode, setContent func(dstFile *n.File)) error {
 	dstFile, ok := dst.(*n.File)
 	if !ok {
 		return ie.ErrBadNode
 	}
 
-	srcFile, ok := src.(*n.File)
-	if !ok {
-		return ie.ErrBadNode
+	dstParent, err := n.ParentDirectory(sy.lkrDst, dst)
+	if err != nil {
+		return err
+	}
+
+	if err := dstParent.RemoveChild(sy.lkrDst, dst); err != nil {
+		return err
 	}
 
 	oldDstPinStats := PinStats{false, false}
@@ -376,11 +535,7 @@ func (sy *syncer) handleMerge(src, dst n.ModNode, srcMask, dstMask ChangeType) e
 		sy.cfg.OnMerge(dst, isGet, &oldDstPinStats)
 	}
 
-	dstFile.SetContent(sy.lkrDst, srcFile.ContentHash())
-	dstFile.SetBackend(sy.lkrDst, srcFile.BackendHash())
-	dstFile.SetSize(srcFile.Size())
-	dstFile.SetCachedSize(srcFile.CachedSize())
-	dstFile.SetKey(srcFile.Key())
+	setContent(dstFile)
 
 	if err := dstParent.Add(sy.lkrDst, dstFile); err != nil {
 		return err
@@ -395,6 +550,84 @@ func (sy *syncer) handleMerge(src, dst n.ModNode, srcMask, dstMask ChangeType) e
 	return sy.lkrDst.StageNode(dstFile)
 }
 
+// updateSymlink sets the target of the symlink `dst` to the one of `src`.
+func (sy *syncer) updateSymlink(src, dst n.ModNode) error {
+	srcSymlink, ok := src.(*n.Symlink)
+	if !ok {
+		return ie.ErrBadNode
+	}
+
+	dstSymlink, ok := dst.(*n.Symlink)
+	if !ok {
+		return ie.ErrBadNode
+	}
+
+	dstParent, err := n.ParentDirectory(sy.lkrDst, dst)
+	if err != nil {
+		return err
+	}
+
+	if err := dstParent.RemoveChild(sy.lkrDst, dst); err != nil {
+		return err
+	}
+
+	dstSymlink.SetTarget(sy.lkrDst, srcSymlink.Target())
+	dstSymlink.SetModTime(srcSymlink.ModTime())
+
+	if err := dstParent.Add(sy.lkrDst, dstSymlink); err != nil {
+		return err
+	}
+
+	return sy.lkrDst.StageNode(dstSymlink)
+}
+
+// takeOverAttrs returns the attribute changes of src that should be applied
+// to dst. Attributes that were changed on both sides stay as they are on dst.
+func takeOverAttrs(srcMask, dstMask ChangeType) ChangeType {
+	return srcMask & attrMask &^ (dstMask & attrMask)
+}
+
+// copyAttrs takes over the attributes of `src` to `dst` that are part of `mask`.
+// Mode and owner are only taken over if `src` has any.
+func (sy *syncer) copyAttrs(src, dst n.ModNode, mask ChangeType) error {
+	if mask&ChangeTypeXattr != 0 {
+		if err := c.CopyXattrs(sy.lkrDst, src, dst); err != nil {
+			return err
+		}
+	}
+
+	if mask&ChangeTypeMode == 0 {
+		return nil
+	}
+
+	if src.HasMode() {
+		if err := c.Chmod(sy.lkrDst, dst, src.Mode()); err != nil {
+			return err
+		}
+	}
+
+	if uid, gid, ok := src.Owner(); ok {
+		return c.Chown(sy.lkrDst, dst, uid, gid)
+	}
+
+	return nil
+}
+
+func (sy *syncer) handleAttrs(src, dst n.ModNode, srcMask, dstMask ChangeType) error {
+	if isReadOnly(sy.cfg.ReadOnlyFolders, src.Path(), dst.Path()) {
+		return nil
+	}
+
+	// Only take over the remote's attributes if we did not change ours.
+	mask := takeOverAttrs(srcMask, dstMask)
+	if mask == 0 {
+		return nil
+	}
+
+	log.Debugf("handling attrs: %s <-> %s", src.Path(), dst.Path())
+	return sy.copyAttrs(src, dst, mask)
+}
+
 func (sy *syncer) handleTypeConflict(src, dst n.ModNode) error {
 	log.Debugf("handling type conflict: %s <-> %s", src.Path(), dst.Path())
 
@@ -414,21 +647,74 @@ func (sy *syncer) handleConflictNode(src n.ModNode) error {
 // A new commit might be created with `message`, defaulting to a default message
 // when an empty string was given.
 func Sync(lkrSrc, lkrDst *c.Linker, cfg *SyncOptions) error {
+	return syncFrom(lkrSrc, lkrDst, nil, "", cfg)
+}
+
+// branchMergeMarker is what we store in the merge marker when merging
+// the local branch `name`. All branches share the same owner, so the
+// owner alone would not tell us which branch was merged last.
+func branchMergeMarker(name string) string {
+	return "branch:" + name
+}
+
+// MergeCommit works like Sync, but takes the changes of `srcHead` and merges
+// them into the staging commit of the same linker. This is used to merge
+// the branch called `branch` into another.
+func MergeCommit(lkr *c.Linker, branch string, srcHead *n.Commit, cfg *SyncOptions) error {
+	if srcHead == nil {
+		return fmt.Errorf("no commit to merge given")
+	}
+
+	return syncFrom(lkr, lkr, srcHead, branchMergeMarker(branch), cfg)
+}
+
+// syncFrom implements Sync and MergeCommit. If `srcHead` is nil,
+// the staging commit and HEAD of `lkrSrc` are used. `mergeWith` is
+// stored in the merge marker; if empty, the owner of `lkrSrc` is used.
+func syncFrom(lkrSrc, lkrDst *c.Linker, srcHead *n.Commit, mergeWith string, cfg *SyncOptions) error {
 	if cfg == nil {
 		cfg = defaultSyncConfig
 	}
 
+	srcOwner, err := lkrSrc.Owner()
+	if err != nil {
+		return err
+	}
+
+	if mergeWith == "" {
+		mergeWith = srcOwner
+	}
+
+	mergeHead := srcHead
+	if mergeHead == nil {
+		if mergeHead, err = lkrSrc.Head(); err != nil {
+			return err
+		}
+	}
+
+	if cfg.VerifySignature != nil {
+		// Our copy of a remote is built by applying its patches,
+		// so its commits carry the signatures of those patches.
+		// Branches only consist of our own commits.
+		allowReplays := lkrSrc != lkrDst
+		if err := verifyHistory(lkrSrc, mergeHead, cfg.VerifySignature, cfg.RequireSignatures, allowReplays); err != nil {
+			return e.Wrapf(err, "verify")
+		}
+	}
+
 	syncer := &syncer{
 		cfg:    cfg,
 		lkrSrc: lkrSrc,
 		lkrDst: lkrDst,
 	}
 
-	resolver, err := newResolver(lkrSrc, lkrDst, nil, nil, syncer)
+	resolver, err := newResolver(lkrSrc, lkrDst, srcHead, nil, syncer)
 	if err != nil {
 		return err
 	}
 
+	resolver.mergeWith = mergeWith
+
 	// Make sure the complete sync goes through in one disk transaction.
 	return lkrDst.Atomic(func() (bool, error) {
 		// This calls all the handleXXX() callbacks above.
@@ -444,19 +730,17 @@ func Sync(lkrSrc, lkrDst *c.Linker, cfg *SyncOptions) error {
 		// If something was changed, we should set the merge marker
 		// and also create a new commit.
 		if wasModified {
-			srcOwner, err := lkrSrc.Owner()
-			if err != nil {
-				return true, err
-			}
-
-			srcHead, err := lkrSrc.Head()
-			if err != nil {
-				return true, err
-			}
-
 			// If something was changed, remember that we merged with src.
 			// This avoids merging conflicting files a second time in the next resolve().
-			if err := lkrDst.SetMergeMarker(srcOwner, srcHead.TreeHash()); err != nil {
+			via := cfg.Via
+			if via == "" {
+				via = MergeViaSync
+				if srcHead != nil {
+					via = MergeViaBranch
+				}
+			}
+
+			if err := lkrDst.SetMergeMarker(mergeWith, via, mergeHead.TreeHash()); err != nil {
 				return true, err
 			}
 
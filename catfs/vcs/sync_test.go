@@ -1,9 +1,13 @@
 package vcs
 
 import (
+	"bytes"
+	"errors"
+	"os"
 	"testing"
 
 	c "github.com/sahib/brig/catfs/core"
+	n "github.com/sahib/brig/catfs/nodes"
 	h "github.com/sahib/brig/util/hashlib"
 	"github.com/stretchr/testify/require"
 )
@@ -380,3 +384,251 @@ func TestSyncReadOnlyFolders(t *testing.T) {
 		require.Equal(t, srcX.ContentHash(), h.TestDummy(t, byte(1)))
 	})
 }
+
+func TestSyncConflictStrategyMerge(t *testing.T) {
+	c.WithLinkerPair(t, func(lkrSrc, lkrDst *c.Linker) {
+		c.MustTouchAndCommit(t, lkrSrc, "/x.txt", 1)
+		require.Nil(t, Sync(lkrSrc, lkrDst, nil))
+
+		c.MustTouchAndCommit(t, lkrSrc, "/x.txt", 2)
+		c.MustTouchAndCommit(t, lkrDst, "/x.txt", 3)
+
+		var mergeBase n.ModNode
+		cfg := &SyncOptions{
+			ConflictStrategy: ConflictStragetyMerge,
+			OnContentMerge: func(base, src, dst n.ModNode) (*MergeResult, error) {
+				mergeBase = base
+				return &MergeResult{
+					ContentHash: h.TestDummy(t, 4),
+					BackendHash: h.TestDummy(t, 4),
+					Size:        4,
+				}, nil
+			},
+		}
+
+		require.Nil(t, Sync(lkrSrc, lkrDst, cfg))
+
+		// The base must be the version both sides had after the first sync:
+		require.NotNil(t, mergeBase)
+		require.Equal(t, h.TestDummy(t, 1), mergeBase.ContentHash())
+
+		dstX, err := lkrDst.LookupFile("/x.txt")
+		require.Nil(t, err)
+		require.Equal(t, h.TestDummy(t, 4), dstX.ContentHash())
+		require.Equal(t, uint64(4), dstX.Size())
+
+		_, err = lkrDst.LookupNode("/x.txt.conflict.0")
+		require.Error(t, err)
+	})
+}
+
+func TestSyncConflictStrategyMergeFallback(t *testing.T) {
+	c.WithLinkerPair(t, func(lkrSrc, lkrDst *c.Linker) {
+		c.MustTouchAndCommit(t, lkrSrc, "/x.txt", 1)
+		c.MustTouchAndCommit(t, lkrDst, "/x.txt", 2)
+
+		cfg := &SyncOptions{
+			ConflictStrategy: ConflictStragetyMerge,
+			OnContentMerge: func(base, src, dst n.ModNode) (*MergeResult, error) {
+				// Pretend that the changes overlap.
+				return nil, nil
+			},
+		}
+
+		require.Nil(t, Sync(lkrSrc, lkrDst, cfg))
+
+		dstX, err := lkrDst.LookupFile("/x.txt")
+		require.Nil(t, err)
+		require.Equal(t, h.TestDummy(t, 2), dstX.ContentHash())
+
+		_, err = lkrDst.LookupNode("/x.txt.conflict.0")
+		require.Nil(t, err)
+	})
+}
+
+func TestSyncSymlink(t *testing.T) {
+	c.WithLinkerPair(t, func(lkrSrc, lkrDst *c.Linker) {
+		c.MustMkdir(t, lkrSrc, "/sub")
+		c.MustTouch(t, lkrSrc, "/sub/x.png", 1)
+		c.MustSymlink(t, lkrSrc, "/sub/link", "x.png")
+		c.MustCommit(t, lkrSrc, "add link")
+
+		diff, err := MakeDiff(lkrSrc, lkrDst, nil, nil, nil)
+		require.Nil(t, err)
+		require.NotEmpty(t, diff.Added)
+		require.Empty(t, diff.Conflict)
+
+		require.Nil(t, Sync(lkrSrc, lkrDst, nil))
+
+		dstNd, err := lkrDst.LookupNode("/sub/link")
+		require.Nil(t, err)
+		require.Equal(t, n.NodeTypeSymlink, dstNd.Type())
+		require.Equal(t, "x.png", dstNd.(*n.Symlink).Target())
+
+		// Change the target on src; dst should follow:
+		c.MustSymlink(t, lkrSrc, "/sub/link", "../y.png")
+		c.MustCommit(t, lkrSrc, "change link")
+
+		diff, err = MakeDiff(lkrSrc, lkrDst, nil, nil, nil)
+		require.Nil(t, err)
+		require.Len(t, diff.Merged, 1)
+		require.Equal(t, "/sub/link", diff.Merged[0].Dst.Path())
+
+		require.Nil(t, Sync(lkrSrc, lkrDst, nil))
+
+		dstNd, err = lkrDst.LookupNode("/sub/link")
+		require.Nil(t, err)
+		require.Equal(t, "../y.png", dstNd.(*n.Symlink).Target())
+	})
+}
+
+func TestSyncMode(t *testing.T) {
+	c.WithLinkerPair(t, func(lkrSrc, lkrDst *c.Linker) {
+		c.MustMkdir(t, lkrSrc, "/sub")
+		srcFile := c.MustTouch(t, lkrSrc, "/sub/run.sh", 1)
+		c.MustCommit(t, lkrSrc, "add script")
+
+		require.Nil(t, Sync(lkrSrc, lkrDst, nil))
+
+		c.MustChmod(t, lkrSrc, srcFile, 0755)
+		srcDir := c.MustLookupDirectory(t, lkrSrc, "/sub")
+		c.MustChmod(t, lkrSrc, srcDir, 0700)
+		c.MustCommit(t, lkrSrc, "chmod")
+
+		diff, err := MakeDiff(lkrSrc, lkrDst, nil, nil, nil)
+		require.Nil(t, err)
+		require.Len(t, diff.Merged, 2)
+		require.Empty(t, diff.Conflict)
+		require.Equal(t, ChangeTypeMode, diff.Merged[1].SrcMask)
+
+		require.Nil(t, Sync(lkrSrc, lkrDst, nil))
+
+		dstFile, err := lkrDst.LookupModNode("/sub/run.sh")
+		require.Nil(t, err)
+		require.Equal(t, os.FileMode(0755), dstFile.Mode())
+		require.True(t, dstFile.ContentHash().Equal(srcFile.ContentHash()))
+
+		dstDir, err := lkrDst.LookupModNode("/sub")
+		require.Nil(t, err)
+		require.Equal(t, os.FileMode(0700), dstDir.Mode())
+
+		// Our own mode changes should not be overwritten
+		// when the remote did not change anything.
+		c.MustChmod(t, lkrDst, dstFile, 0600)
+		c.MustCommit(t, lkrDst, "chmod on dst")
+
+		require.Nil(t, Sync(lkrSrc, lkrDst, nil))
+
+		dstFile, err = lkrDst.LookupModNode("/sub/run.sh")
+		require.Nil(t, err)
+		require.Equal(t, os.FileMode(0600), dstFile.Mode())
+	})
+}
+
+func TestSyncXattr(t *testing.T) {
+	c.WithLinkerPair(t, func(lkrSrc, lkrDst *c.Linker) {
+		c.MustMkdir(t, lkrSrc, "/sub")
+		srcFile := c.MustTouch(t, lkrSrc, "/sub/x", 1)
+		require.Nil(t, c.SetXattr(lkrSrc, srcFile, "user.tags", []byte("red")))
+		c.MustCommit(t, lkrSrc, "add tagged file")
+
+		require.Nil(t, Sync(lkrSrc, lkrDst, nil))
+
+		dstFile, err := lkrDst.LookupModNode("/sub/x")
+		require.Nil(t, err)
+		value, ok := dstFile.Xattr("user.tags")
+		require.True(t, ok)
+		require.Equal(t, []byte("red"), value)
+
+		srcNd, err := lkrSrc.LookupModNode("/sub/x")
+		require.Nil(t, err)
+		require.Nil(t, c.SetXattr(lkrSrc, srcNd, "user.tags", []byte("blue")))
+		srcDir := c.MustLookupDirectory(t, lkrSrc, "/sub")
+		require.Nil(t, c.SetXattr(lkrSrc, srcDir, "user.color", []byte("green")))
+		c.MustCommit(t, lkrSrc, "retag")
+
+		diff, err := MakeDiff(lkrSrc, lkrDst, nil, nil, nil)
+		require.Nil(t, err)
+		require.Len(t, diff.Merged, 2)
+		require.Empty(t, diff.Conflict)
+
+		require.Nil(t, Sync(lkrSrc, lkrDst, nil))
+
+		dstFile, err = lkrDst.LookupModNode("/sub/x")
+		require.Nil(t, err)
+		value, _ = dstFile.Xattr("user.tags")
+		require.Equal(t, []byte("blue"), value)
+		require.True(t, dstFile.ContentHash().Equal(srcFile.ContentHash()))
+
+		dstDir, err := lkrDst.LookupModNode("/sub")
+		require.Nil(t, err)
+		value, _ = dstDir.Xattr("user.color")
+		require.Equal(t, []byte("green"), value)
+	})
+}
+
+// testSign and testVerify fake a signature scheme for the tests.
+func testSign(data []byte) ([]byte, error) {
+	return append([]byte("signed:"), data...), nil
+}
+
+func testVerify(data, sig []byte) error {
+	if !bytes.Equal(sig, append([]byte("signed:"), data...)) {
+		return errors.New("bad signature")
+	}
+
+	return nil
+}
+
+func TestSyncVerifySignatures(t *testing.T) {
+	sign, verify := testSign, testVerify
+
+	c.WithLinkerPair(t, func(lkrSrc, lkrDst *c.Linker) {
+		c.MustTouchAndCommit(t, lkrSrc, "/unsigned.png", 1)
+
+		cfg := &SyncOptions{
+			VerifySignature:   verify,
+			RequireSignatures: true,
+		}
+
+		require.Error(t, Sync(lkrSrc, lkrDst, cfg))
+
+		cfg.RequireSignatures = false
+		require.Nil(t, Sync(lkrSrc, lkrDst, cfg))
+
+		lkrSrc.SetSignFunc(sign)
+		_, signedCmt := c.MustTouchAndCommit(t, lkrSrc, "/signed.png", 2)
+		require.Equal(t, SignatureGood, VerifyCommit(signedCmt, verify))
+		require.Nil(t, Sync(lkrSrc, lkrDst, cfg))
+
+		// Commits signed by somebody else are always refused:
+		lkrSrc.SetSignFunc(func(data []byte) ([]byte, error) {
+			return []byte("forged"), nil
+		})
+
+		_, forgedCmt := c.MustTouchAndCommit(t, lkrSrc, "/forged.png", 3)
+		require.Equal(t, SignatureBad, VerifyCommit(forgedCmt, verify))
+		require.Error(t, Sync(lkrSrc, lkrDst, cfg))
+
+		_, err := lkrDst.LookupFile("/forged.png")
+		require.Error(t, err)
+	})
+}
+
+func TestVerifyCopiedSignature(t *testing.T) {
+	c.WithDummyLinker(t, func(lkr *c.Linker) {
+		lkr.SetSignFunc(testSign)
+		_, signedCmt := c.MustTouchAndCommit(t, lkr, "/signed.png", 1)
+		require.Equal(t, SignatureGood, VerifyCommit(signedCmt, testVerify))
+
+		// A valid signature of another commit must not be accepted:
+		hash, sig := signedCmt.Signature()
+		require.Nil(t, lkr.SetSignature(hash, sig))
+		_, copiedCmt := c.MustTouchAndCommit(t, lkr, "/copied.png", 2)
+		require.Equal(t, SignatureBad, VerifyCommit(copiedCmt, testVerify))
+
+		head, err := lkr.Head()
+		require.Nil(t, err)
+		require.Error(t, VerifyHistory(lkr, head, testVerify, false))
+	})
+}
@@ -87,6 +87,14 @@ func Undelete(lkr *c.Linker, root string) error {
 				// Stage that old state:
 				_, err := c.StageFromFileNode(lkr, file)
 				return err
+			case n.NodeTypeSymlink:
+				symlink, ok := child.(*n.Symlink)
+				if !ok {
+					return ie.ErrBadNode
+				}
+
+				_, err := c.StageSymlink(lkr, symlink.Path(), symlink.Target(), symlink.ModTime())
+				return err
 			}
 			return nil
 		})
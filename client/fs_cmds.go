@@ -22,11 +22,17 @@ type StatInfo struct {
 	CachedSize  int64
 	Inode       uint64
 	IsDir       bool
+	IsSymlink   bool
+	Target      string
 	IsRaw       bool
 	Depth       int
 	ModTime     time.Time
 	IsPinned    bool
 	IsExplicit  bool
+	Mode        os.FileMode
+	HasOwner    bool
+	Uid         uint32
+	Gid         uint32
 	TreeHash    h.Hash
 	ContentHash h.Hash
 	BackendHash h.Hash
@@ -75,6 +81,11 @@ func convertCapStatInfo(capInfo *capnp.StatInfo) (*StatInfo, error) {
 		return nil, err
 	}
 
+	target, err := capInfo.Target()
+	if err != nil {
+		return nil, err
+	}
+
 	modTimeData, err := capInfo.ModTime()
 	if err != nil {
 		return nil, err
@@ -100,9 +111,15 @@ func convertCapStatInfo(capInfo *capnp.StatInfo) (*StatInfo, error) {
 	info.CachedSize = capInfo.CachedSize()
 	info.Inode = capInfo.Inode()
 	info.IsDir = capInfo.IsDir()
+	info.IsSymlink = capInfo.IsSymlink()
+	info.Target = target
 	info.IsRaw = capInfo.IsRaw()
 	info.IsPinned = capInfo.IsPinned()
 	info.IsExplicit = capInfo.IsExplicit()
+	info.Mode = os.FileMode(capInfo.Mode())
+	info.HasOwner = capInfo.HasOwner()
+	info.Uid = capInfo.Uid()
+	info.Gid = capInfo.Gid()
 	info.Depth = int(capInfo.Depth())
 
 	info.TreeHash = treeHash
@@ -115,8 +132,18 @@ func convertCapStatInfo(capInfo *capnp.StatInfo) (*StatInfo, error) {
 
 // List will list all nodes beneath and including `root` up to `maxDepth`.
 func (cl *Client) List(root string, maxDepth int) ([]StatInfo, error) {
+	return cl.ListAt("", root, maxDepth)
+}
+
+// ListAt is like List, but lists the state at `rev`.
+// An empty `rev` means the current state.
+func (cl *Client) ListAt(rev, root string, maxDepth int) ([]StatInfo, error) {
 	call := cl.api.List(cl.ctx, func(p capnp.FS_list_Params) error {
 		p.SetMaxDepth(int32(maxDepth))
+		if err := p.SetRev(rev); err != nil {
+			return err
+		}
+
 		return p.SetRoot(root)
 	})
 
@@ -144,6 +171,179 @@ func (cl *Client) List(root string, maxDepth int) ([]StatInfo, error) {
 	return results, err
 }
 
+// Find returns all nodes below `root` that match `query`.
+// See catfs.ParseQuery for the query syntax.
+func (cl *Client) Find(root, query string) ([]StatInfo, error) {
+	call := cl.api.Find(cl.ctx, func(p capnp.FS_find_Params) error {
+		if err := p.SetRoot(root); err != nil {
+			return err
+		}
+
+		return p.SetQuery(query)
+	})
+
+	result, err := call.Struct()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []StatInfo{}
+	statList, err := result.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := 0; idx < statList.Len(); idx++ {
+		capInfo := statList.At(idx)
+		info, err := convertCapStatInfo(&capInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, *info)
+	}
+
+	return results, nil
+}
+
+// PinStats sums up the pin state of all versions of all files below Path.
+// All sizes are in bytes.
+type PinStats struct {
+	Path               string `json:"path"`
+	IsDir              bool   `json:"is_dir"`
+	Files              int64  `json:"files"`
+	Versions           int64  `json:"versions"`
+	PinnedSize         uint64 `json:"pinned_size"`
+	ExplicitSize       uint64 `json:"explicit_size"`
+	UnpinnedSize       uint64 `json:"unpinned_size"`
+	CachedSize         uint64 `json:"cached_size"`
+	RepinUnpinSize     uint64 `json:"repin_unpin_size"`
+	QuotaCandidateSize uint64 `json:"quota_candidate_size"`
+}
+
+// PinStatus returns the pin stats of `root` and everything below it,
+// down to `depth` levels. A negative depth means no limit.
+func (cl *Client) PinStatus(root string, depth int) ([]PinStats, error) {
+	call := cl.api.PinStatus(cl.ctx, func(p capnp.FS_pinStatus_Params) error {
+		p.SetDepth(int32(depth))
+		return p.SetRoot(root)
+	})
+
+	result, err := call.Struct()
+	if err != nil {
+		return nil, err
+	}
+
+	capStats, err := result.Stats()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := []PinStats{}
+	for idx := 0; idx < capStats.Len(); idx++ {
+		capEntry := capStats.At(idx)
+		path, err := capEntry.Path()
+		if err != nil {
+			return nil, err
+		}
+
+		stats = append(stats, PinStats{
+			Path:               path,
+			IsDir:              capEntry.IsDir(),
+			Files:              capEntry.Files(),
+			Versions:           capEntry.Versions(),
+			PinnedSize:         capEntry.PinnedSize(),
+			ExplicitSize:       capEntry.ExplicitSize(),
+			UnpinnedSize:       capEntry.UnpinnedSize(),
+			CachedSize:         capEntry.CachedSize(),
+			RepinUnpinSize:     capEntry.RepinUnpinSize(),
+			QuotaCandidateSize: capEntry.QuotaCandidateSize(),
+		})
+	}
+
+	return stats, nil
+}
+
+// PrefetchProgress is reported by Prefetch after each file.
+type PrefetchProgress struct {
+	Path       string
+	Size       uint64
+	Skipped    bool
+	Error      string
+	DoneFiles  int64
+	DoneBytes  uint64
+	TotalFiles int64
+	TotalBytes uint64
+}
+
+// Prefetch pins and fetches all files below `path` (as of `rev`), fetching
+// up to `parallel` files at the same time. `fn` is called after each file.
+// It returns once all files are done. Files that failed to fetch are only
+// reported to `fn` via PrefetchProgress.Error.
+func (cl *Client) Prefetch(path, rev string, parallel int, fn func(progress PrefetchProgress)) error {
+	call := cl.api.Prefetch(cl.ctx, func(p capnp.FS_prefetch_Params) error {
+		p.SetParallel(int32(parallel))
+		if err := p.SetRev(rev); err != nil {
+			return err
+		}
+
+		return p.SetPath(path)
+	})
+
+	result, err := call.Struct()
+	if err != nil {
+		return err
+	}
+
+	ticket := result.Ticket()
+	for {
+		nextCall := cl.api.PrefetchNext(cl.ctx, func(p capnp.FS_prefetchNext_Params) error {
+			p.SetTicket(ticket)
+			return nil
+		})
+
+		result, err := nextCall.Struct()
+		if err != nil {
+			return err
+		}
+
+		if !result.HasProgress() {
+			return nil
+		}
+
+		capProgress, err := result.Progress()
+		if err != nil {
+			return err
+		}
+
+		path, err := capProgress.Path()
+		if err != nil {
+			return err
+		}
+
+		// An empty path is only sent to keep us waiting.
+		if path == "" {
+			continue
+		}
+
+		errMsg, err := capProgress.Error()
+		if err != nil {
+			return err
+		}
+
+		fn(PrefetchProgress{
+			Path:       path,
+			Size:       capProgress.Size(),
+			Skipped:    capProgress.Skipped(),
+			Error:      errMsg,
+			DoneFiles:  capProgress.DoneFiles(),
+			DoneBytes:  capProgress.DoneBytes(),
+			TotalFiles: capProgress.TotalFiles(),
+			TotalBytes: capProgress.TotalBytes(),
+		})
+	}
+}
+
 // Stage will add a new node at `repoPath` with the contents of `localPath`.
 func (cl *Client) Stage(localPath, repoPath string) error {
 	call := cl.api.Stage(cl.ctx, func(p capnp.FS_stage_Params) error {
@@ -219,8 +419,17 @@ func (cl *Client) StageFromReader(repoPath string, r io.Reader) error {
 // Cat outputs the contents of the node at `path`.
 // The node must be a file.
 func (cl *Client) Cat(path string, offline bool) (io.ReadCloser, error) {
+	return cl.CatAt("", path, offline)
+}
+
+// CatAt is like Cat, but outputs the version of the file at `rev`.
+func (cl *Client) CatAt(rev, path string, offline bool) (io.ReadCloser, error) {
 	call := cl.api.Cat(cl.ctx, func(p capnp.FS_cat_Params) error {
 		p.SetOffline(offline)
+		if err := p.SetRev(rev); err != nil {
+			return err
+		}
+
 		return p.SetPath(path)
 	})
 
@@ -296,8 +505,17 @@ func (cl *Client) CatOnClient(path string, offline bool, w io.Writer) error {
 // Tar outputs a tar archive with the contents of `path`.
 // `path` can be either a file or directory.
 func (cl *Client) Tar(path string, offline bool) (io.ReadCloser, error) {
+	return cl.TarAt("", path, offline)
+}
+
+// TarAt is like Tar, but archives the state at `rev`.
+func (cl *Client) TarAt(rev, path string, offline bool) (io.ReadCloser, error) {
 	call := cl.api.Tar(cl.ctx, func(p capnp.FS_tar_Params) error {
 		p.SetOffline(offline)
+		if err := p.SetRev(rev); err != nil {
+			return err
+		}
+
 		return p.SetPath(path)
 	})
 
@@ -327,6 +545,43 @@ func (cl *Client) Mkdir(path string, createParents bool) error {
 	return err
 }
 
+// Symlink creates a symbolic link at `path` that points to `target`.
+func (cl *Client) Symlink(path, target string) error {
+	call := cl.api.Symlink(cl.ctx, func(p capnp.FS_symlink_Params) error {
+		if err := p.SetTarget(target); err != nil {
+			return err
+		}
+
+		return p.SetPath(path)
+	})
+
+	_, err := call.Struct()
+	return err
+}
+
+// Chmod sets the permission bits of the node at `path` to `mode`.
+func (cl *Client) Chmod(path string, mode os.FileMode) error {
+	call := cl.api.Chmod(cl.ctx, func(p capnp.FS_chmod_Params) error {
+		p.SetMode(uint32(mode.Perm()))
+		return p.SetPath(path)
+	})
+
+	_, err := call.Struct()
+	return err
+}
+
+// Chown sets the numeric owner of the node at `path`.
+func (cl *Client) Chown(path string, uid, gid uint32) error {
+	call := cl.api.Chown(cl.ctx, func(p capnp.FS_chown_Params) error {
+		p.SetUid(uid)
+		p.SetGid(gid)
+		return p.SetPath(path)
+	})
+
+	_, err := call.Struct()
+	return err
+}
+
 // Remove removes the node at `path`.
 // Directories are removed recursively.
 func (cl *Client) Remove(path string) error {
@@ -398,7 +653,16 @@ func (cl *Client) Repin(root string) error {
 
 // Stat gives detailed information about the node at `path`.
 func (cl *Client) Stat(path string) (*StatInfo, error) {
+	return cl.StatAt("", path)
+}
+
+// StatAt is like Stat, but looks at the state at `rev`.
+func (cl *Client) StatAt(rev, path string) (*StatInfo, error) {
 	call := cl.api.Stat(cl.ctx, func(p capnp.FS_stat_Params) error {
+		if err := p.SetRev(rev); err != nil {
+			return err
+		}
+
 		return p.SetPath(path)
 	})
 
@@ -481,7 +745,16 @@ func (cl *Client) DeletedNodes(root string) ([]StatInfo, error) {
 
 // IsCached checks if file or directory at `path` is cached.
 func (cl *Client) IsCached(path string) (bool, error) {
+	return cl.IsCachedAt("", path)
+}
+
+// IsCachedAt is like IsCached, but checks the state at `rev`.
+func (cl *Client) IsCachedAt(rev, path string) (bool, error) {
 	call := cl.api.IsCached(cl.ctx, func(p capnp.FS_isCached_Params) error {
+		if err := p.SetRev(rev); err != nil {
+			return err
+		}
+
 		return p.SetPath(path)
 	})
 
@@ -28,6 +28,7 @@ type Remote struct {
 	AutoUpdate       bool           `yaml:"AutoUpdate"`
 	ConflictStrategy string         `yaml:"ConflictStrategy"`
 	AcceptPush       bool           `yaml:"AcceptPush"`
+	Addresses        []string       `yaml:"Addresses,flow"`
 }
 
 func capRemoteToRemote(capRemote capnp.Remote) (*Remote, error) {
@@ -71,6 +72,21 @@ func capRemoteToRemote(capRemote capnp.Remote) (*Remote, error) {
 		})
 	}
 
+	capAddrs, err := capRemote.Addresses()
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := []string{}
+	for idx := 0; idx < capAddrs.Len(); idx++ {
+		addr, err := capAddrs.At(idx)
+		if err != nil {
+			return nil, err
+		}
+
+		addrs = append(addrs, addr)
+	}
+
 	return &Remote{
 		Name:             remoteName,
 		Fingerprint:      remoteFp,
@@ -78,6 +94,7 @@ func capRemoteToRemote(capRemote capnp.Remote) (*Remote, error) {
 		AutoUpdate:       capRemote.AcceptAutoUpdates(),
 		AcceptPush:       capRemote.AcceptPush(),
 		ConflictStrategy: conflictStrategy,
+		Addresses:        addrs,
 	}, nil
 }
 
@@ -128,6 +145,21 @@ func remoteToCapRemote(remote Remote, seg *capnplib.Segment) (*capnp.Remote, err
 		return nil, err
 	}
 
+	capAddrs, err := capnplib.NewTextList(seg, int32(len(remote.Addresses)))
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, addr := range remote.Addresses {
+		if err := capAddrs.Set(idx, addr); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := capRemote.SetAddresses(capAddrs); err != nil {
+		return nil, err
+	}
+
 	capRemote.SetAcceptAutoUpdates(remote.AutoUpdate)
 	capRemote.SetAcceptPush(remote.AcceptPush)
 	return &capRemote, nil
@@ -530,3 +562,64 @@ func (cl *Client) Push(remoteName string, dryRun bool) error {
 	_, err := call.Struct()
 	return err
 }
+
+// Transfer is the progress of a metadata transfer from a remote.
+type Transfer struct {
+	Remote  string
+	Kind    string
+	Done    int64
+	Total   int64
+	Started time.Time
+}
+
+// TransferList returns the progress of all transfers that are
+// currently running as part of a fetch or sync.
+func (cl *Client) TransferList() ([]Transfer, error) {
+	call := cl.api.TransferList(cl.ctx, func(p capnp.Net_transferList_Params) error {
+		return nil
+	})
+
+	result, err := call.Struct()
+	if err != nil {
+		return nil, err
+	}
+
+	capTransfers, err := result.Transfers()
+	if err != nil {
+		return nil, err
+	}
+
+	transfers := []Transfer{}
+	for idx := 0; idx < capTransfers.Len(); idx++ {
+		capTransfer := capTransfers.At(idx)
+		remote, err := capTransfer.Remote()
+		if err != nil {
+			return nil, err
+		}
+
+		kind, err := capTransfer.Kind()
+		if err != nil {
+			return nil, err
+		}
+
+		startedStamp, err := capTransfer.Started()
+		if err != nil {
+			return nil, err
+		}
+
+		started, err := time.Parse(time.RFC3339, startedStamp)
+		if err != nil {
+			return nil, err
+		}
+
+		transfers = append(transfers, Transfer{
+			Remote:  remote,
+			Kind:    kind,
+			Done:    capTransfer.Done(),
+			Total:   capTransfer.Total(),
+			Started: started,
+		})
+	}
+
+	return transfers, nil
+}
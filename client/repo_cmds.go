@@ -2,8 +2,10 @@ package client
 
 import (
 	"sort"
+	"time"
 
 	gwdb "github.com/sahib/brig/gateway/db"
+	gwcapnp "github.com/sahib/brig/gateway/db/capnp"
 	"github.com/sahib/brig/server/capnp"
 	h "github.com/sahib/brig/util/hashlib"
 	capnplib "zombiezen.com/go/capnproto2"
@@ -448,6 +450,18 @@ type GatewayUser struct {
 	Salt         string
 	Folders      []string
 	Rights       []string
+	AccessKeys   []string
+	APITokens    []GatewayAPIToken
+}
+
+// GatewayAPIToken is an api token of a gateway user.
+// Empty rights or folders mean that the ones of the user apply.
+type GatewayAPIToken struct {
+	ID        string
+	Name      string
+	Rights    []string
+	Folders   []string
+	CreatedAt time.Time
 }
 
 // GatewayUserAdd adds a new user to the user database.
@@ -536,18 +550,262 @@ func (ctl *Client) GatewayUserList() ([]GatewayUser, error) {
 			return nil, err
 		}
 
+		// Only the ids of the access keys are passed on;
+		// the secrets are only shown once when they are created.
+		accessKeys := []string{}
+		for _, key := range gwuser.AccessKeys {
+			accessKeys = append(accessKeys, key.ID)
+		}
+
+		// The hashes of the api tokens are of no use for the client.
+		apiTokens := []GatewayAPIToken{}
+		for _, token := range gwuser.APITokens {
+			apiTokens = append(apiTokens, GatewayAPIToken{
+				ID:        token.ID,
+				Name:      token.Name,
+				Rights:    token.Rights,
+				Folders:   token.Folders,
+				CreatedAt: token.CreatedAt,
+			})
+		}
+
 		users = append(users, GatewayUser{
 			Name:         gwuser.Name,
 			Salt:         gwuser.Salt,
 			PasswordHash: gwuser.PasswordHash,
 			Folders:      gwuser.Folders,
 			Rights:       gwuser.Rights,
+			AccessKeys:   accessKeys,
+			APITokens:    apiTokens,
 		})
 	}
 
 	return users, err
 }
 
+// GatewayUserKeyAdd creates a new access key for the S3 API for the user
+// `name`. It returns the id and the secret of the key.
+func (ctl *Client) GatewayUserKeyAdd(name string) (string, string, error) {
+	call := ctl.api.GatewayUserKeyAdd(ctl.ctx, func(p capnp.Repo_gatewayUserKeyAdd_Params) error {
+		return p.SetName(name)
+	})
+
+	result, err := call.Struct()
+	if err != nil {
+		return "", "", err
+	}
+
+	id, err := result.Id()
+	if err != nil {
+		return "", "", err
+	}
+
+	secret, err := result.Secret()
+	if err != nil {
+		return "", "", err
+	}
+
+	return id, secret, nil
+}
+
+// GatewayUserKeyRemove removes the access key `id` of the user `name`.
+func (ctl *Client) GatewayUserKeyRemove(name, id string) error {
+	call := ctl.api.GatewayUserKeyRm(ctl.ctx, func(p capnp.Repo_gatewayUserKeyRm_Params) error {
+		if err := p.SetName(name); err != nil {
+			return err
+		}
+
+		return p.SetId(id)
+	})
+
+	_, err := call.Struct()
+	return err
+}
+
+// GatewayUserTokenAdd creates a new api token for the user `name`. The token
+// is limited to `rights` and `folders`, if they are not empty. It returns
+// the token (which can not be retrieved later) and its id.
+func (ctl *Client) GatewayUserTokenAdd(name, tokenName string, rights, folders []string) (string, string, error) {
+	call := ctl.api.GatewayUserTokenAdd(ctl.ctx, func(p capnp.Repo_gatewayUserTokenAdd_Params) error {
+		if err := p.SetName(name); err != nil {
+			return err
+		}
+
+		if err := p.SetTokenName(tokenName); err != nil {
+			return err
+		}
+
+		seg := p.Segment()
+		capRights, err := capnplib.NewTextList(seg, int32(len(rights)))
+		if err != nil {
+			return err
+		}
+
+		for idx, right := range rights {
+			if err := capRights.Set(idx, right); err != nil {
+				return err
+			}
+		}
+
+		if err := p.SetRights(capRights); err != nil {
+			return err
+		}
+
+		capFolders, err := capnplib.NewTextList(seg, int32(len(folders)))
+		if err != nil {
+			return err
+		}
+
+		for idx, folder := range folders {
+			if err := capFolders.Set(idx, folder); err != nil {
+				return err
+			}
+		}
+
+		return p.SetFolders(capFolders)
+	})
+
+	result, err := call.Struct()
+	if err != nil {
+		return "", "", err
+	}
+
+	token, err := result.Token()
+	if err != nil {
+		return "", "", err
+	}
+
+	id, err := result.Id()
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, id, nil
+}
+
+// GatewayUserTokenRemove removes the api token `id` of the user `name`.
+func (ctl *Client) GatewayUserTokenRemove(name, id string) error {
+	call := ctl.api.GatewayUserTokenRm(ctl.ctx, func(p capnp.Repo_gatewayUserTokenRm_Params) error {
+		if err := p.SetName(name); err != nil {
+			return err
+		}
+
+		return p.SetId(id)
+	})
+
+	_, err := call.Struct()
+	return err
+}
+
+// GatewayShare is a public link to a file or directory in the gateway.
+type GatewayShare struct {
+	Token        string
+	Path         string
+	Rev          string
+	Owner        string
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+	MaxDownloads uint64
+	Downloads    uint64
+	HasPassword  bool
+	IsExpired    bool
+}
+
+func gatewayShareFromCapnp(capLink gwcapnp.ShareLink) (*GatewayShare, error) {
+	link, err := gwdb.ShareLinkFromCapnp(capLink)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GatewayShare{
+		Token:        link.Token,
+		Path:         link.Path,
+		Rev:          link.Rev,
+		Owner:        link.Owner,
+		CreatedAt:    link.CreatedAt,
+		ExpiresAt:    link.ExpiresAt,
+		MaxDownloads: link.MaxDownloads,
+		Downloads:    link.Downloads,
+		HasPassword:  link.HasPassword(),
+		IsExpired:    link.IsExpired(time.Now()),
+	}, nil
+}
+
+// GatewayShareCreate creates a new share link to `path` at `rev`.
+// `rev` may be empty to share the current state. A zero `expiresAt` and
+// a zero `maxDownloads` mean no limit. `password` may be empty.
+func (ctl *Client) GatewayShareCreate(path, rev string, expiresAt time.Time, maxDownloads uint64, password string) (*GatewayShare, error) {
+	call := ctl.api.GatewayShareCreate(ctl.ctx, func(p capnp.Repo_gatewayShareCreate_Params) error {
+		if err := p.SetPath(path); err != nil {
+			return err
+		}
+
+		if err := p.SetRev(rev); err != nil {
+			return err
+		}
+
+		if !expiresAt.IsZero() {
+			if err := p.SetExpiresAt(expiresAt.UTC().Format(time.RFC3339)); err != nil {
+				return err
+			}
+		}
+
+		p.SetMaxDownloads(maxDownloads)
+		return p.SetPassword(password)
+	})
+
+	result, err := call.Struct()
+	if err != nil {
+		return nil, err
+	}
+
+	capLink, err := result.Link()
+	if err != nil {
+		return nil, err
+	}
+
+	return gatewayShareFromCapnp(capLink)
+}
+
+// GatewayShareList lists all share links, including expired ones.
+func (ctl *Client) GatewayShareList() ([]GatewayShare, error) {
+	call := ctl.api.GatewayShareList(ctl.ctx, func(p capnp.Repo_gatewayShareList_Params) error {
+		return nil
+	})
+
+	result, err := call.Struct()
+	if err != nil {
+		return nil, err
+	}
+
+	capLinks, err := result.Links()
+	if err != nil {
+		return nil, err
+	}
+
+	links := []GatewayShare{}
+	for idx := 0; idx < capLinks.Len(); idx++ {
+		link, err := gatewayShareFromCapnp(capLinks.At(idx))
+		if err != nil {
+			return nil, err
+		}
+
+		links = append(links, *link)
+	}
+
+	return links, nil
+}
+
+// GatewayShareRevoke deletes the share link with `token`.
+func (ctl *Client) GatewayShareRevoke(token string) error {
+	call := ctl.api.GatewayShareRevoke(ctl.ctx, func(p capnp.Repo_gatewayShareRevoke_Params) error {
+		return p.SetToken(token)
+	})
+
+	_, err := call.Struct()
+	return err
+}
+
 // DebugProfilePort will get the port of pprof server in the backend.
 // The port changes during daemon restarts.
 func (ctl *Client) DebugProfilePort() (int, error) {
@@ -674,3 +932,172 @@ func (ctl *Client) HintList() ([]Hint, error) {
 
 	return hints, nil
 }
+
+// RetentionPolicy tells which old versions of the files at Path are kept.
+type RetentionPolicy struct {
+	// Path is the path the policy applies to (recursively)
+	Path string
+
+	// Policy is a list of rules like "1h:1d,1d:30d".
+	Policy string
+}
+
+// RetentionSet remembers `policy` for `path` (and below).
+func (ctl *Client) RetentionSet(path, policy string) error {
+	call := ctl.api.RetentionSet(ctl.ctx, func(p capnp.Repo_retentionSet_Params) error {
+		if err := p.SetPath(path); err != nil {
+			return err
+		}
+
+		return p.SetPolicy(policy)
+	})
+
+	_, err := call.Struct()
+	return err
+}
+
+// RetentionRemove removes the retention policy at `path`.
+func (ctl *Client) RetentionRemove(path string) error {
+	call := ctl.api.RetentionRemove(ctl.ctx, func(p capnp.Repo_retentionRemove_Params) error {
+		return p.SetPath(path)
+	})
+
+	_, err := call.Struct()
+	return err
+}
+
+// RetentionList lists all retention policies that are currently set.
+func (ctl *Client) RetentionList() ([]RetentionPolicy, error) {
+	call := ctl.api.RetentionList(ctl.ctx, func(p capnp.Repo_retentionList_Params) error {
+		return nil
+	})
+
+	result, err := call.Struct()
+	if err != nil {
+		return nil, err
+	}
+
+	capPolicies, err := result.Policies()
+	if err != nil {
+		return nil, err
+	}
+
+	policies := []RetentionPolicy{}
+	for idx := 0; idx < capPolicies.Len(); idx++ {
+		capPolicy := capPolicies.At(idx)
+		path, err := capPolicy.Path()
+		if err != nil {
+			return nil, err
+		}
+
+		policy, err := capPolicy.Policy()
+		if err != nil {
+			return nil, err
+		}
+
+		policies = append(policies, RetentionPolicy{Path: path, Policy: policy})
+	}
+
+	// Sort for display convenience:
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].Path < policies[j].Path
+	})
+
+	return policies, nil
+}
+
+// PinRule is a per-folder setting for the repinner.
+type PinRule struct {
+	// Path is the folder the rule applies to (recursively)
+	Path string `json:"path"`
+
+	// Quota is the maximum pinned storage below Path (0 for none).
+	Quota uint64 `json:"quota"`
+
+	// Priority is one of "low", "normal" or "high" (or empty to inherit).
+	Priority string `json:"priority"`
+
+	// Used is the storage the pinned versions below Path take up.
+	Used uint64 `json:"used"`
+}
+
+// PinRuleSet changes the rule at `path`. Nil values are left as they are.
+func (ctl *Client) PinRuleSet(path string, quota, priority *string) error {
+	call := ctl.api.PinRuleSet(ctl.ctx, func(p capnp.Repo_pinRuleSet_Params) error {
+		if err := p.SetPath(path); err != nil {
+			return err
+		}
+
+		if quota != nil {
+			if err := p.SetQuota(*quota); err != nil {
+				return err
+			}
+		}
+
+		if priority != nil {
+			if err := p.SetPriority(*priority); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	_, err := call.Struct()
+	return err
+}
+
+// PinRuleRemove removes the rule at `path`.
+func (ctl *Client) PinRuleRemove(path string) error {
+	call := ctl.api.PinRuleRemove(ctl.ctx, func(p capnp.Repo_pinRuleRemove_Params) error {
+		return p.SetPath(path)
+	})
+
+	_, err := call.Struct()
+	return err
+}
+
+// PinRuleList lists all pin rules with their current usage.
+func (ctl *Client) PinRuleList() ([]PinRule, error) {
+	call := ctl.api.PinRuleList(ctl.ctx, func(p capnp.Repo_pinRuleList_Params) error {
+		return nil
+	})
+
+	result, err := call.Struct()
+	if err != nil {
+		return nil, err
+	}
+
+	capRules, err := result.Rules()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []PinRule{}
+	for idx := 0; idx < capRules.Len(); idx++ {
+		capRule := capRules.At(idx)
+		path, err := capRule.Path()
+		if err != nil {
+			return nil, err
+		}
+
+		priority, err := capRule.Priority()
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, PinRule{
+			Path:     path,
+			Quota:    capRule.Quota(),
+			Priority: priority,
+			Used:     capRule.Used(),
+		})
+	}
+
+	// Sort for display convenience:
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Path < rules[j].Path
+	})
+
+	return rules, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/sahib/brig/server/capnp"
 	h "github.com/sahib/brig/util/hashlib"
+	capnplib "zombiezen.com/go/capnproto2"
 )
 
 // MakeCommit creates a new commit from the current staging area.
@@ -25,6 +26,10 @@ type Commit struct {
 	Msg  string
 	Tags []string
 	Date time.Time
+
+	// Signature is either "good", "bad" or "unsigned".
+	// It is only set by Log() if asked to verify.
+	Signature string
 }
 
 func convertCapCommit(capEntry *capnp.Commit) (*Commit, error) {
@@ -48,6 +53,11 @@ func convertCapCommit(capEntry *capnp.Commit) (*Commit, error) {
 		return nil, err
 	}
 
+	result.Signature, err = capEntry.Signature()
+	if err != nil {
+		return nil, err
+	}
+
 	tagList, err := capEntry.Tags()
 	if err != nil {
 		return nil, err
@@ -68,8 +78,10 @@ func convertCapCommit(capEntry *capnp.Commit) (*Commit, error) {
 }
 
 // Log lists all commits, starting with the newest one.
-func (ctl *Client) Log() ([]Commit, error) {
+// If `verify` is true, the signature of each commit is checked.
+func (ctl *Client) Log(verify bool) ([]Commit, error) {
 	call := ctl.api.Log(ctl.ctx, func(p capnp.VCS_log_Params) error {
+		p.SetVerify(verify)
 		return nil
 	})
 
@@ -474,3 +486,338 @@ func (ctl *Client) CommitInfo(rev string) (bool, *Commit, error) {
 
 	return true, cmt, nil
 }
+
+// ConflictSide is one version of a conflicting file.
+type ConflictSide struct {
+	Info   StatInfo
+	Commit Commit
+}
+
+// Conflict describes a conflict file that was created during sync.
+type Conflict struct {
+	Path         string
+	ConflictPath string
+	Remote       string
+
+	// Ours is nil if our version was removed in the meantime.
+	Ours   *ConflictSide
+	Theirs ConflictSide
+}
+
+func convertCapConflictSide(capInfo capnp.StatInfo, capCmt capnp.Commit) (*ConflictSide, error) {
+	info, err := convertCapStatInfo(&capInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	cmt, err := convertCapCommit(&capCmt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConflictSide{Info: *info, Commit: *cmt}, nil
+}
+
+func convertCapConflict(capCf capnp.Conflict) (*Conflict, error) {
+	cf := &Conflict{}
+
+	var err error
+	cf.Path, err = capCf.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	cf.ConflictPath, err = capCf.ConflictPath()
+	if err != nil {
+		return nil, err
+	}
+
+	cf.Remote, err = capCf.Remote()
+	if err != nil {
+		return nil, err
+	}
+
+	if capCf.HasOurs() {
+		capOurs, err := capCf.Ours()
+		if err != nil {
+			return nil, err
+		}
+
+		capOursCmt, err := capCf.OursCommit()
+		if err != nil {
+			return nil, err
+		}
+
+		cf.Ours, err = convertCapConflictSide(capOurs, capOursCmt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	capTheirs, err := capCf.Theirs()
+	if err != nil {
+		return nil, err
+	}
+
+	capTheirsCmt, err := capCf.TheirsCommit()
+	if err != nil {
+		return nil, err
+	}
+
+	theirs, err := convertCapConflictSide(capTheirs, capTheirsCmt)
+	if err != nil {
+		return nil, err
+	}
+
+	cf.Theirs = *theirs
+	return cf, nil
+}
+
+// ConflictList returns all conflict files below `root`.
+func (ctl *Client) ConflictList(root string) ([]Conflict, error) {
+	call := ctl.api.ConflictList(ctl.ctx, func(p capnp.VCS_conflictList_Params) error {
+		return p.SetRoot(root)
+	})
+
+	result, err := call.Struct()
+	if err != nil {
+		return nil, err
+	}
+
+	capLst, err := result.Conflicts()
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := []Conflict{}
+	for idx := 0; idx < capLst.Len(); idx++ {
+		cf, err := convertCapConflict(capLst.At(idx))
+		if err != nil {
+			return nil, err
+		}
+
+		conflicts = append(conflicts, *cf)
+	}
+
+	return conflicts, nil
+}
+
+// ConflictResolve resolves the conflicts referenced by `paths` with
+// `strategy` ("ours", "theirs" or "resolved") in a single commit.
+// If `paths` is empty, all conflicts are resolved.
+func (ctl *Client) ConflictResolve(paths []string, strategy string) error {
+	call := ctl.api.ConflictResolve(ctl.ctx, func(p capnp.VCS_conflictResolve_Params) error {
+		capPaths, err := capnplib.NewTextList(p.Segment(), int32(len(paths)))
+		if err != nil {
+			return err
+		}
+
+		for idx, path := range paths {
+			if err := capPaths.Set(idx, path); err != nil {
+				return err
+			}
+		}
+
+		if err := p.SetPaths(capPaths); err != nil {
+			return err
+		}
+
+		return p.SetStrategy(strategy)
+	})
+
+	_, err := call.Struct()
+	return err
+}
+
+// Branch is a named line of development.
+type Branch struct {
+	Name      string
+	Head      Commit
+	IsCurrent bool
+}
+
+// BranchList returns all branches, sorted by name.
+func (ctl *Client) BranchList() ([]Branch, error) {
+	call := ctl.api.BranchList(ctl.ctx, func(p capnp.VCS_branchList_Params) error {
+		return nil
+	})
+
+	result, err := call.Struct()
+	if err != nil {
+		return nil, err
+	}
+
+	capLst, err := result.Branches()
+	if err != nil {
+		return nil, err
+	}
+
+	branches := []Branch{}
+	for idx := 0; idx < capLst.Len(); idx++ {
+		capBranch := capLst.At(idx)
+		name, err := capBranch.Name()
+		if err != nil {
+			return nil, err
+		}
+
+		capHead, err := capBranch.Head()
+		if err != nil {
+			return nil, err
+		}
+
+		head, err := convertCapCommit(&capHead)
+		if err != nil {
+			return nil, err
+		}
+
+		branches = append(branches, Branch{
+			Name:      name,
+			Head:      *head,
+			IsCurrent: capBranch.IsCurrent(),
+		})
+	}
+
+	return branches, nil
+}
+
+// BranchCreate creates a new branch called `name` starting at `rev`.
+// If `rev` is empty, the branch starts at HEAD.
+func (ctl *Client) BranchCreate(name, rev string) error {
+	call := ctl.api.BranchCreate(ctl.ctx, func(p capnp.VCS_branchCreate_Params) error {
+		if err := p.SetName(name); err != nil {
+			return err
+		}
+
+		return p.SetRev(rev)
+	})
+
+	_, err := call.Struct()
+	return err
+}
+
+// BranchSwitch makes `name` the current branch.
+// If `force` is true, uncommitted changes are overwritten.
+func (ctl *Client) BranchSwitch(name string, force bool) error {
+	call := ctl.api.BranchSwitch(ctl.ctx, func(p capnp.VCS_branchSwitch_Params) error {
+		p.SetForce(force)
+		return p.SetName(name)
+	})
+
+	_, err := call.Struct()
+	return err
+}
+
+// BranchDelete removes the branch called `name`.
+func (ctl *Client) BranchDelete(name string) error {
+	call := ctl.api.BranchDelete(ctl.ctx, func(p capnp.VCS_branchDelete_Params) error {
+		return p.SetName(name)
+	})
+
+	_, err := call.Struct()
+	return err
+}
+
+// BranchMerge merges the branch called `name` into the current branch.
+func (ctl *Client) BranchMerge(name string) error {
+	call := ctl.api.BranchMerge(ctl.ctx, func(p capnp.VCS_branchMerge_Params) error {
+		return p.SetName(name)
+	})
+
+	_, err := call.Struct()
+	return err
+}
+
+// Revert undoes the changes of the commit `rev` with a new commit.
+func (ctl *Client) Revert(rev string) error {
+	call := ctl.api.Revert(ctl.ctx, func(p capnp.VCS_revert_Params) error {
+		return p.SetRev(rev)
+	})
+
+	_, err := call.Struct()
+	return err
+}
+
+// BlameEntry tells where a single version of a file came from.
+type BlameEntry struct {
+	Path   string
+	Mask   []string
+	Commit *Commit
+	Remote string
+	Via    string
+}
+
+// Blame returns the origin of each version of the file at `path`.
+func (ctl *Client) Blame(path string) ([]*BlameEntry, error) {
+	call := ctl.api.Blame(ctl.ctx, func(p capnp.VCS_blame_Params) error {
+		return p.SetPath(path)
+	})
+
+	result, err := call.Struct()
+	if err != nil {
+		return nil, err
+	}
+
+	capEntries, err := result.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []*BlameEntry{}
+	for idx := 0; idx < capEntries.Len(); idx++ {
+		capEntry := capEntries.At(idx)
+		path, err := capEntry.Path()
+		if err != nil {
+			return nil, err
+		}
+
+		change, err := capEntry.Change()
+		if err != nil {
+			return nil, err
+		}
+
+		capCmt, err := capEntry.Commit()
+		if err != nil {
+			return nil, err
+		}
+
+		cmt, err := convertCapCommit(&capCmt)
+		if err != nil {
+			return nil, err
+		}
+
+		remote, err := capEntry.Remote()
+		if err != nil {
+			return nil, err
+		}
+
+		via, err := capEntry.Via()
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, &BlameEntry{
+			Path:   path,
+			Mask:   strings.Split(change, "|"),
+			Commit: cmt,
+			Remote: remote,
+			Via:    via,
+		})
+	}
+
+	return entries, nil
+}
+
+// HistorySquash collapses the commits before `before` (a rev or a date)
+// and returns how many commits were dropped.
+func (ctl *Client) HistorySquash(before string) (int, error) {
+	call := ctl.api.HistorySquash(ctl.ctx, func(p capnp.VCS_historySquash_Params) error {
+		return p.SetBefore(before)
+	})
+
+	result, err := call.Struct()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.Dropped()), nil
+}
@@ -59,6 +59,15 @@ func handleStage(ctx *cli.Context, ctl *client.Client) error {
 type twins struct {
 	localPath string
 	repoPaths []string
+
+	// linkTarget is set when localPath is a symlink that should be
+	// staged as such (and not by its content).
+	linkTarget string
+
+	// isDir is set when localPath is a directory. Directories are not
+	// staged themselves, but their mode is applied after staging.
+	isDir bool
+	mode  os.FileMode
 }
 
 type walkOptions struct {
@@ -78,15 +87,34 @@ func walk(root, repoRoot string, depth int, opt walkOptions) (map[string]twins,
 	err := filepath.Walk(root, func(childPath string, info os.FileInfo, err error) error {
 		repoPath := filepath.Join("/", repoRoot, childPath[len(root):])
 
+		if !opt.dereference && info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(childPath)
+			if err != nil {
+				msg := fmt.Sprintf("Failed to read link: %v: %v", childPath, err)
+				if opt.continueOnError {
+					fmt.Fprintf(os.Stderr, "WARNING: %s\n", msg)
+					return nil
+				}
+				return fmt.Errorf(msg)
+			}
+
+			// Links are unique by their path, not by their inode:
+			toBeStaged["link:"+childPath] = twins{
+				localPath:  childPath,
+				repoPaths:  []string{repoPath},
+				linkTarget: target,
+			}
+			return nil
+		}
+
 		if opt.dereference && info.Mode()&os.ModeSymlink != 0 {
-			// NOTE: `brig` does not have concept of symlink
-			//       The lack of native symlinks in `brig` has the following potential issues
+			// NOTE: With --dereference we stage what the link points to.
+			//       This has the following potential issues:
 			//       * Ignoring cycles limits valid use cases.
 			//       * Not ignoring cycles opens room for malicious input.
 			//
-			//       Here we implement a dereference of symlinks as a temporary measure
-			//       which works in the most likely user scenarios (we assume that user
-			//       is not malicious to herself and does not create infinite symlinked loops).
+			//       We assume that the user is not malicious to herself
+			//       and does not create infinite symlinked loops.
 			//       If the level of recursion or cycles
 			//       (where link points to itself directly or indirectly) is exceeded,
 			//       we just fail on such link.
@@ -122,10 +150,8 @@ func walk(root, repoRoot string, depth int, opt walkOptions) (map[string]twins,
 				for k, v := range extra {
 					t, ok := toBeStaged[k]
 					if !ok {
-						t = twins{
-							v.localPath,
-							[]string{},
-						}
+						t = v
+						t.repoPaths = []string{}
 					}
 					t.repoPaths = append(t.repoPaths, v.repoPaths...)
 					toBeStaged[k] = t
@@ -134,13 +160,23 @@ func walk(root, repoRoot string, depth int, opt walkOptions) (map[string]twins,
 			}
 		}
 
+		if info.IsDir() {
+			toBeStaged["dir:"+childPath] = twins{
+				localPath: childPath,
+				repoPaths: []string{repoPath},
+				isDir:     true,
+				mode:      info.Mode(),
+			}
+			return nil
+		}
+
 		if info.Mode().IsRegular() {
 			k, _ := inodeString(childPath)
 			t, ok := toBeStaged[k]
 			if !ok {
 				t = twins{
-					childPath,
-					[]string{},
+					localPath: childPath,
+					repoPaths: []string{},
 				}
 			}
 			t.repoPaths = append(t.repoPaths, repoPath)
@@ -176,7 +212,7 @@ func handleStageDirectory(ctx *cli.Context, ctl *client.Client, root, repoRoot s
 	repoRoot = filepath.Clean(repoRoot)
 
 	opt := walkOptions{
-		dereference:     !ctx.Bool("no-dereference"),
+		dereference:     ctx.Bool("dereference") && !ctx.Bool("no-dereference"),
 		continueOnError: ctx.Bool("continue-on-error"),
 	}
 
@@ -185,6 +221,16 @@ func handleStageDirectory(ctx *cli.Context, ctl *client.Client, root, repoRoot s
 		return fmt.Errorf("failed to walk dir: %v: %v", root, err)
 	}
 
+	// Directories are created implicitly by staging their files,
+	// their modes are applied once everything below them was staged.
+	dirs := []twins{}
+	for k, v := range toBeStaged {
+		if v.isDir {
+			dirs = append(dirs, v)
+			delete(toBeStaged, k)
+		}
+	}
+
 	if len(toBeStaged) == 0 {
 		// This might happen if ask to stage a symlink pointing to a dir
 		// but Walk does not travel symlinks and we end up with empty list.
@@ -237,6 +283,18 @@ func handleStageDirectory(ctx *cli.Context, ctl *client.Client, root, repoRoot s
 					return
 				}
 
+				if twinsSet.linkTarget != "" {
+					for _, repoPath := range twinsSet.repoPaths {
+						if err := ctl.Symlink(repoPath, twinsSet.linkTarget); err != nil {
+							fmt.Fprintf(os.Stderr, "failed to stage link '%s' as '%s': %v\n", twinsSet.localPath, repoPath, err)
+						}
+					}
+
+					bar.IncrBy(1, time.Since(start))
+					start = time.Now()
+					continue
+				}
+
 				firstToStage := ""
 				for i, repoPath := range twinsSet.repoPaths {
 					if i == 0 {
@@ -281,16 +339,44 @@ func handleStageDirectory(ctx *cli.Context, ctl *client.Client, root, repoRoot s
 
 	close(jobs)
 	pbars.Wait()
+
+	stageDirModes(ctl, dirs, toBeStaged)
 	return nil
 }
 
+// stageDirModes applies the mode of each directory in `dirs`. Directories
+// without any staged node below them were not created and are skipped.
+func stageDirModes(ctl *client.Client, dirs []twins, staged map[string]twins) {
+	created := make(map[string]bool)
+	for _, twinsSet := range staged {
+		for _, repoPath := range twinsSet.repoPaths {
+			for dir := path.Dir(repoPath); !created[dir]; dir = path.Dir(dir) {
+				created[dir] = true
+			}
+		}
+	}
+
+	for _, dir := range dirs {
+		for _, repoPath := range dir.repoPaths {
+			if !created[repoPath] {
+				continue
+			}
+
+			if err := ctl.Chmod(repoPath, dir.mode); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to set mode of '%s': %v\n", repoPath, err)
+			}
+		}
+	}
+}
+
 func handleCat(ctx *cli.Context, ctl *client.Client) error {
 	path := "/"
 	if len(ctx.Args()) >= 1 {
 		path = ctx.Args().First()
 	}
 
-	info, err := ctl.Stat(path)
+	rev := ctx.String("rev")
+	info, err := ctl.StatAt(rev, path)
 	if err != nil {
 		return err
 	}
@@ -299,11 +385,11 @@ func handleCat(ctx *cli.Context, ctl *client.Client) error {
 
 	var stream io.ReadCloser
 	if info.IsDir {
-		stream, err = ctl.Tar(path, doOffline)
-	} else if ctx.Bool("stream") {
+		stream, err = ctl.TarAt(rev, path, doOffline)
+	} else if ctx.Bool("stream") && rev == "" {
 		return ctl.CatOnClient(path, doOffline, os.Stdout)
 	} else {
-		stream, err = ctl.Cat(path, doOffline)
+		stream, err = ctl.CatAt(rev, path, doOffline)
 	}
 
 	if err != nil {
@@ -411,7 +497,8 @@ func handleList(ctx *cli.Context, ctl *client.Client) error {
 		root = ctx.Args().First()
 	}
 
-	entries, err := ctl.List(root, maxDepth)
+	rev := ctx.String("rev")
+	entries, err := ctl.ListAt(rev, root, maxDepth)
 	if err != nil {
 		return err
 	}
@@ -456,9 +543,12 @@ func handleList(ctx *cli.Context, ctl *client.Client) error {
 		pinState := " " + pinStateToSymbol(entry.IsPinned, entry.IsExplicit)
 
 		var coloredPath string
-		if entry.IsDir {
+		switch {
+		case entry.IsDir:
 			coloredPath = color.GreenString(entry.Path)
-		} else {
+		case entry.IsSymlink:
+			coloredPath = color.CyanString(entry.Path) + " -> " + entry.Target
+		default:
 			coloredPath = color.WhiteString(entry.Path)
 		}
 
@@ -467,7 +557,7 @@ func handleList(ctx *cli.Context, ctl *client.Client) error {
 			userEntry = color.GreenString(userMap[entry.User]) + "\t"
 		}
 
-		isCached, err := ctl.IsCached(entry.Path)
+		isCached, err := ctl.IsCachedAt(rev, entry.Path)
 		if err != nil {
 			return err
 		}
@@ -490,6 +580,37 @@ func handleList(ctx *cli.Context, ctl *client.Client) error {
 	return tabW.Flush()
 }
 
+func handleFind(ctx *cli.Context, ctl *client.Client) error {
+	query := strings.Join(ctx.Args(), " ")
+	entries, err := ctl.Find(ctx.String("root"), query)
+	if err != nil {
+		return ExitCode{UnknownError, fmt.Sprintf("find: %v", err)}
+	}
+
+	tmpl, err := readFormatTemplate(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if tmpl != nil {
+			if err := tmpl.Execute(os.Stdout, entry); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if entry.IsDir {
+			fmt.Println(color.GreenString(entry.Path))
+		} else {
+			fmt.Println(entry.Path)
+		}
+	}
+
+	return nil
+}
+
 func handleTree(ctx *cli.Context, ctl *client.Client) error {
 	root := "/"
 	if ctx.NArg() > 0 {
@@ -684,6 +805,19 @@ func handleEdit(ctx *cli.Context, ctl *client.Client) error {
 		}
 	}()
 
+	if exists {
+		// Staging takes over the mode of the temp file;
+		// make sure the original permissions survive the edit.
+		info, err := ctl.Stat(repoPath)
+		if err != nil {
+			return err
+		}
+
+		if err := os.Chmod(tempPath, info.Mode); err != nil {
+			return err
+		}
+	}
+
 	return ctl.Stage(tempPath, repoPath)
 }
 
@@ -692,6 +826,47 @@ func handleTouch(ctx *cli.Context, ctl *client.Client) error {
 	return ctl.Touch(repoPath)
 }
 
+func handleChmod(ctx *cli.Context, ctl *client.Client) error {
+	mode, err := strconv.ParseUint(ctx.Args().First(), 8, 32)
+	if err != nil || os.FileMode(mode) != os.FileMode(mode).Perm() {
+		return ExitCode{BadArgs, fmt.Sprintf("invalid mode: %s", ctx.Args().First())}
+	}
+
+	for _, repoPath := range ctx.Args().Tail() {
+		if err := ctl.Chmod(repoPath, os.FileMode(mode)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func handleChown(ctx *cli.Context, ctl *client.Client) error {
+	spec := ctx.Args().First()
+	split := strings.SplitN(spec, ":", 2)
+	if len(split) != 2 {
+		return ExitCode{BadArgs, fmt.Sprintf("owner needs to be <uid>:<gid>: %s", spec)}
+	}
+
+	uid, err := strconv.ParseUint(split[0], 10, 32)
+	if err != nil {
+		return ExitCode{BadArgs, fmt.Sprintf("invalid uid: %s", split[0])}
+	}
+
+	gid, err := strconv.ParseUint(split[1], 10, 32)
+	if err != nil {
+		return ExitCode{BadArgs, fmt.Sprintf("invalid gid: %s", split[1])}
+	}
+
+	for _, repoPath := range ctx.Args().Tail() {
+		if err := ctl.Chown(repoPath, uint32(uid), uint32(gid)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func handleTrashList(ctx *cli.Context, ctl *client.Client) error {
 	root := "/"
 	if firstArg := ctx.Args().First(); firstArg != "" {
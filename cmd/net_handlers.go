@@ -2,12 +2,16 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"strings"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/fatih/color"
+	isatty "github.com/mattn/go-isatty"
 	"github.com/sahib/brig/cmd/tabwriter"
 
 	"github.com/sahib/brig/client"
@@ -239,6 +243,7 @@ func handleRemoteAdd(ctx *cli.Context, ctl *client.Client) error {
 		AutoUpdate:       ctx.Bool("auto-update"),
 		ConflictStrategy: ctx.String("conflict-strategy"),
 		AcceptPush:       ctx.Bool("accept-push"),
+		Addresses:        ctx.StringSlice("address"),
 	}
 
 	for _, folder := range ctx.StringSlice("folder") {
@@ -639,6 +644,206 @@ func handleRepin(ctx *cli.Context, ctl *client.Client) error {
 	return ctl.Repin(root)
 }
 
+func handlePrefetch(ctx *cli.Context, ctl *client.Client) error {
+	root := ctx.Args().First()
+
+	failed := []client.PrefetchProgress{}
+	skipped := 0
+	isTerm := isatty.IsTerminal(os.Stdout.Fd())
+
+	err := ctl.Prefetch(root, ctx.String("rev"), ctx.Int("jobs"), func(progress client.PrefetchProgress) {
+		if progress.Skipped {
+			skipped++
+		}
+
+		if progress.Error != "" {
+			failed = append(failed, progress)
+		}
+
+		if !isTerm {
+			return
+		}
+
+		// We can't use tabwriter here, since it needs to update in realtime.
+		fmt.Printf(
+			"\r\033[K[%d/%d files, %s/%s] %s",
+			progress.DoneFiles,
+			progress.TotalFiles,
+			humanize.Bytes(progress.DoneBytes),
+			humanize.Bytes(progress.TotalBytes),
+			progress.Path,
+		)
+	})
+
+	if isTerm {
+		fmt.Printf("\r\033[K")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, progress := range failed {
+		fmt.Printf("%s %s: %s\n", color.RedString("✘"), progress.Path, progress.Error)
+	}
+
+	if len(failed) > 0 {
+		return ExitCode{
+			UnknownError,
+			fmt.Sprintf("%d files could not be fetched; run the same command again to retry", len(failed)),
+		}
+	}
+
+	fmt.Printf("%s All files below %s are pinned and available offline", color.GreenString("✔"), root)
+	if skipped > 0 {
+		fmt.Printf(" (%d were already there)", skipped)
+	}
+
+	fmt.Println()
+	return nil
+}
+
+func printPinRules(rules []client.PinRule) error {
+	tabW := tabwriter.NewWriter(
+		os.Stdout, 0, 0, 2, ' ',
+		tabwriter.StripEscape,
+	)
+
+	fmt.Fprintln(tabW, "PATH\tPRIORITY\tUSED\tQUOTA\t")
+
+	for _, rule := range rules {
+		priority := rule.Priority
+		if priority == "" {
+			priority = "inherit"
+		}
+
+		quota, used := "-", humanize.Bytes(rule.Used)
+		if rule.Quota > 0 {
+			quota = humanize.Bytes(rule.Quota)
+			used = fmt.Sprintf("%s (%.0f%%)", used, 100*float64(rule.Used)/float64(rule.Quota))
+		}
+
+		fmt.Fprintf(tabW, "%s\t%s\t%s\t%s\t\n", rule.Path, priority, used, quota)
+	}
+
+	return tabW.Flush()
+}
+
+func handlePinRuleList(ctx *cli.Context, ctl *client.Client) error {
+	rules, err := ctl.PinRuleList()
+	if err != nil {
+		return err
+	}
+
+	if len(rules) == 0 {
+		fmt.Println("No pin rules set; only the global quota (fs.repin.quota) applies.")
+		return nil
+	}
+
+	return printPinRules(rules)
+}
+
+func handlePinStatus(ctx *cli.Context, ctl *client.Client) error {
+	root := "/"
+	if len(ctx.Args()) > 0 {
+		root = path.Clean("/" + ctx.Args().First())
+	}
+
+	stats, err := ctl.PinStatus(root, ctx.Int("depth"))
+	if err != nil {
+		return err
+	}
+
+	allRules, err := ctl.PinRuleList()
+	if err != nil {
+		return err
+	}
+
+	// Only show the rules that affect something below root:
+	rules := []client.PinRule{}
+	for _, rule := range allRules {
+		if root == "/" || rule.Path == root || strings.HasPrefix(rule.Path, root+"/") {
+			rules = append(rules, rule)
+		}
+	}
+
+	if ctx.Bool("json") {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "    ")
+		return enc.Encode(struct {
+			Stats []client.PinStats `json:"stats"`
+			Rules []client.PinRule  `json:"rules"`
+		}{stats, rules})
+	}
+
+	tabW := tabwriter.NewWriter(
+		os.Stdout, 0, 0, 2, ' ',
+		tabwriter.StripEscape,
+	)
+
+	fmt.Fprintln(tabW, "PATH\tFILES\tVERSIONS\tPINNED\tEXPLICIT\tUNPINNED\tCACHED\tREPIN WILL UNPIN\tOVER QUOTA UNPINS\t")
+
+	for _, entry := range stats {
+		// Show the entries as tree below root:
+		name := entry.Path
+		if entry.Path != root {
+			rel := strings.TrimPrefix(strings.TrimPrefix(entry.Path, root), "/")
+			name = strings.Repeat("  ", strings.Count(rel, "/")+1) + path.Base(entry.Path)
+		}
+
+		if entry.IsDir && name != "/" {
+			name += "/"
+		}
+
+		fmt.Fprintf(
+			tabW,
+			"%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			name,
+			entry.Files,
+			entry.Versions,
+			humanize.Bytes(entry.PinnedSize),
+			humanize.Bytes(entry.ExplicitSize),
+			humanize.Bytes(entry.UnpinnedSize),
+			humanize.Bytes(entry.CachedSize),
+			humanize.Bytes(entry.RepinUnpinSize),
+			humanize.Bytes(entry.QuotaCandidateSize),
+		)
+	}
+
+	if err := tabW.Flush(); err != nil {
+		return err
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	fmt.Println()
+	return printPinRules(rules)
+}
+
+func handlePinRuleSet(ctx *cli.Context, ctl *client.Client) error {
+	path := ctx.Args().First()
+
+	if !ctx.Bool("force") {
+		if _, err := ctl.Stat(path); err != nil {
+			return fmt.Errorf("no file or directory at »%s« (use --force to create anyways)", path)
+		}
+	}
+
+	quota := optionalStringParamAsPtr(ctx, "quota")
+	priority := optionalStringParamAsPtr(ctx, "priority")
+	if quota == nil && priority == nil {
+		return fmt.Errorf("need at least one of --quota or --priority")
+	}
+
+	return ctl.PinRuleSet(path, quota, priority)
+}
+
+func handlePinRuleRemove(ctx *cli.Context, ctl *client.Client) error {
+	return ctl.PinRuleRemove(ctx.Args().First())
+}
+
 func handleWhoami(ctx *cli.Context, ctl *client.Client) error {
 	self, err := ctl.Whoami()
 	if err != nil {
@@ -255,8 +255,34 @@ func RunCmdline(args []string) int {
 					Name:    "remove",
 					Aliases: []string{"rm"},
 					Action:  withArgCheck(needAtLeast(1), withDaemon(handleUnpin, true)),
+				}, {
+					Name:    "status",
+					Aliases: []string{"st"},
+					Action:  withDaemon(handlePinStatus, true),
+				}, {
+					Name:   "rule",
+					Action: withDaemon(handlePinRuleList, true),
+					Subcommands: []cli.Command{
+						{
+							Name:    "list",
+							Aliases: []string{"ls"},
+							Action:  withDaemon(handlePinRuleList, true),
+						}, {
+							Name:    "set",
+							Aliases: []string{"s"},
+							Action:  withArgCheck(needAtLeast(1), withDaemon(handlePinRuleSet, true)),
+						}, {
+							Name:    "remove",
+							Aliases: []string{"rm"},
+							Action:  withArgCheck(needAtLeast(1), withDaemon(handlePinRuleRemove, true)),
+						},
+					},
 				},
 			},
+		}, {
+			Name:     "prefetch",
+			Category: vcscGroup,
+			Action:   withArgCheck(needAtLeast(1), withDaemon(handlePrefetch, true)),
 		}, {
 			Name:     "net",
 			Category: netwGroup,
@@ -314,6 +340,10 @@ func RunCmdline(args []string) int {
 			Aliases:  []string{"re"},
 			Category: vcscGroup,
 			Action:   withArgCheck(needAtLeast(1), withDaemon(handleReset, true)),
+		}, {
+			Name:     "revert",
+			Category: vcscGroup,
+			Action:   withArgCheck(needAtLeast(1), withDaemon(handleRevert, true)),
 		}, {
 			Name:     "become",
 			Aliases:  []string{"be"},
@@ -324,6 +354,65 @@ func RunCmdline(args []string) int {
 			Aliases:  []string{"hst", "hist"},
 			Category: vcscGroup,
 			Action:   withArgCheck(needAtLeast(1), withDaemon(handleHistory, true)),
+			Subcommands: []cli.Command{
+				{
+					Name:   "squash",
+					Action: withDaemon(handleHistorySquash, true),
+				},
+			},
+		}, {
+			Name:     "blame",
+			Category: vcscGroup,
+			Action:   withArgCheck(needAtLeast(1), withDaemon(handleBlame, true)),
+		}, {
+			Name:     "conflicts",
+			Aliases:  []string{"cf"},
+			Category: vcscGroup,
+			Action:   withDaemon(handleConflictsList, true),
+			Subcommands: []cli.Command{
+				{
+					Name:    "list",
+					Aliases: []string{"ls"},
+					Action:  withDaemon(handleConflictsList, true),
+				}, {
+					Name:   "show",
+					Action: withArgCheck(needAtLeast(1), withDaemon(handleConflictsShow, true)),
+				}, {
+					Name:   "take-ours",
+					Action: withDaemon(handleConflictsResolveWith("ours"), true),
+				}, {
+					Name:   "take-theirs",
+					Action: withDaemon(handleConflictsResolveWith("theirs"), true),
+				}, {
+					Name:   "resolve",
+					Action: withArgCheck(needAtLeast(1), withDaemon(handleConflictsResolveWith("resolved"), true)),
+				},
+			},
+		}, {
+			Name:     "branch",
+			Aliases:  []string{"br"},
+			Category: vcscGroup,
+			Action:   withDaemon(handleBranchList, true),
+			Subcommands: []cli.Command{
+				{
+					Name:    "list",
+					Aliases: []string{"ls"},
+					Action:  withDaemon(handleBranchList, true),
+				}, {
+					Name:   "create",
+					Action: withArgCheck(needAtLeast(1), withDaemon(handleBranchCreate, true)),
+				}, {
+					Name:   "switch",
+					Action: withArgCheck(needAtLeast(1), withDaemon(handleBranchSwitch, true)),
+				}, {
+					Name:    "delete",
+					Aliases: []string{"rm"},
+					Action:  withArgCheck(needAtLeast(1), withDaemon(handleBranchDelete, true)),
+				}, {
+					Name:   "merge",
+					Action: withArgCheck(needAtLeast(1), withDaemon(handleBranchMerge, true)),
+				},
+			},
 		}, {
 			Name:     "stage",
 			Aliases:  []string{"stg", "add", "a"},
@@ -352,6 +441,10 @@ func RunCmdline(args []string) int {
 			Name:     "ls",
 			Category: wdirGroup,
 			Action:   withDaemon(handleList, true),
+		}, {
+			Name:     "find",
+			Category: wdirGroup,
+			Action:   withArgCheck(needAtLeast(1), withDaemon(handleFind, true)),
 		}, {
 			Name:     "tree",
 			Category: wdirGroup,
@@ -372,6 +465,14 @@ func RunCmdline(args []string) int {
 			Name:     "edit",
 			Category: wdirGroup,
 			Action:   withArgCheck(needAtLeast(1), withDaemon(handleEdit, true)),
+		}, {
+			Name:     "chmod",
+			Category: wdirGroup,
+			Action:   withArgCheck(needAtLeast(2), withDaemon(handleChmod, true)),
+		}, {
+			Name:     "chown",
+			Category: wdirGroup,
+			Action:   withArgCheck(needAtLeast(2), withDaemon(handleChown, true)),
 		}, {
 			Name:     "daemon",
 			Category: repoGroup,
@@ -470,6 +571,26 @@ func RunCmdline(args []string) int {
 					Action:  withDaemon(handleRepoHintsRecode, true),
 				},
 			},
+		}, {
+			Name:     "retention",
+			Aliases:  []string{"ret"},
+			Category: repoGroup,
+			Action:   withDaemon(handleRetentionList, true),
+			Subcommands: []cli.Command{
+				{
+					Name:    "list",
+					Aliases: []string{"ls"},
+					Action:  withDaemon(handleRetentionList, true),
+				}, {
+					Name:    "set",
+					Aliases: []string{"s"},
+					Action:  withArgCheck(needAtLeast(2), withDaemon(handleRetentionSet, true)),
+				}, {
+					Name:    "remove",
+					Aliases: []string{"rm"},
+					Action:  withArgCheck(needAtLeast(1), withDaemon(handleRetentionRemove, true)),
+				},
+			},
 		}, {
 			Name:     "gateway",
 			Aliases:  []string{"gw"},
@@ -510,6 +631,64 @@ func RunCmdline(args []string) int {
 							Aliases: []string{"ls"},
 							Action:  withDaemon(handleGatewayUserList, true),
 						},
+						{
+							Name:    "key",
+							Aliases: []string{"k"},
+							Subcommands: []cli.Command{
+								{
+									Name:    "add",
+									Aliases: []string{"a"},
+									Action:  withArgCheck(needAtLeast(1), withDaemon(handleGatewayUserKeyAdd, true)),
+								},
+								{
+									Name:    "remove",
+									Aliases: []string{"rm"},
+									Action:  withArgCheck(needAtLeast(2), withDaemon(handleGatewayUserKeyRemove, true)),
+								},
+							},
+						},
+						{
+							Name:    "token",
+							Aliases: []string{"t"},
+							Subcommands: []cli.Command{
+								{
+									Name:    "add",
+									Aliases: []string{"a"},
+									Action:  withArgCheck(needAtLeast(1), withDaemon(handleGatewayUserTokenAdd, true)),
+								},
+								{
+									Name:    "list",
+									Aliases: []string{"ls"},
+									Action:  withArgCheck(needAtLeast(1), withDaemon(handleGatewayUserTokenList, true)),
+								},
+								{
+									Name:    "remove",
+									Aliases: []string{"rm"},
+									Action:  withArgCheck(needAtLeast(2), withDaemon(handleGatewayUserTokenRemove, true)),
+								},
+							},
+						},
+					},
+				},
+				{
+					Name:    "share",
+					Aliases: []string{"s"},
+					Subcommands: []cli.Command{
+						{
+							Name:    "create",
+							Aliases: []string{"c"},
+							Action:  withArgCheck(needAtLeast(1), withDaemon(handleGatewayShareCreate, true)),
+						},
+						{
+							Name:    "list",
+							Aliases: []string{"ls"},
+							Action:  withDaemon(handleGatewayShareList, true),
+						},
+						{
+							Name:    "revoke",
+							Aliases: []string{"rm"},
+							Action:  withArgCheck(needAtLeast(1), withDaemon(handleGatewayShareRevoke, true)),
+						},
 					},
 				},
 			},
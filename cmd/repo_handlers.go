@@ -22,6 +22,7 @@ import (
 	"github.com/sahib/brig/cmd/tabwriter"
 	"github.com/sahib/brig/repo"
 	"github.com/sahib/brig/repo/repopack"
+	"github.com/sahib/brig/repo/retention"
 	"github.com/sahib/brig/repo/setup"
 	"github.com/sahib/brig/server"
 	"github.com/sahib/brig/util"
@@ -417,14 +418,17 @@ func handleDaemonLaunch(ctx *cli.Context) error {
 		ipfsPath = cfg.String("daemon.ipfs_path_or_url")
 	}
 
-	if _, err := setup.IPFS(setup.Options{
-		LogWriter:        &logWriter{prefix: "ipfs"},
-		Setup:            true,
-		SetDefaultConfig: true,
-		SetExtraConfig:   false,
-		IpfsPath:         ipfsPath,
-	}); err != nil {
-		return err
+	// The localfs backend stores everything itself and needs no IPFS.
+	if repoBackendName(repoPath) != "localfs" {
+		if _, err := setup.IPFS(setup.Options{
+			LogWriter:        &logWriter{prefix: "ipfs"},
+			Setup:            true,
+			SetDefaultConfig: true,
+			SetExtraConfig:   false,
+			IpfsPath:         ipfsPath,
+		}); err != nil {
+			return err
+		}
 	}
 
 	logToStdout := ctx.Bool("log-to-stdout")
@@ -704,6 +708,22 @@ func handleGatewayStatus(ctx *cli.Context, ctl *client.Client) error {
 		fmt.Println("")
 	}
 
+	s3IsEnabled, err := ctl.ConfigGet("gateway.s3.enabled")
+	if err != nil {
+		return err
+	}
+
+	if s3IsEnabled == "true" {
+		s3Port, err := ctl.ConfigGet("gateway.s3.port")
+		if err != nil {
+			return err
+		}
+
+		s3URL := fmt.Sprintf("%s://%s:%s", protocol, domain, s3Port)
+		fmt.Printf("• The S3 API is enabled on %s\n", color.GreenString(s3URL))
+		fmt.Println("  Users need an access key for it: »brig gw user key add <user>«.")
+	}
+
 	users, err := ctl.GatewayUserList()
 	if err != nil {
 		return err
@@ -745,16 +765,12 @@ func handleGatewayStop(ctx *cli.Context, ctl *client.Client) error {
 	return nil
 }
 
-func handleGatewayURL(ctx *cli.Context, ctl *client.Client) error {
-	path := ctx.Args().First()
-	if _, err := ctl.Stat(path); err != nil {
-		return err
-	}
-
+// gatewayBaseURL returns the url under which the local gateway is reachable.
+func gatewayBaseURL(ctl *client.Client) (string, error) {
 	domain := "localhost"
 	port, err := ctl.ConfigGet("gateway.port")
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	if port == "80" || port == "443" {
@@ -764,8 +780,22 @@ func handleGatewayURL(ctx *cli.Context, ctl *client.Client) error {
 	}
 
 	protocol := "http"
+	return fmt.Sprintf("%s://%s%s", protocol, domain, port), nil
+}
+
+func handleGatewayURL(ctx *cli.Context, ctl *client.Client) error {
+	path := ctx.Args().First()
+	if _, err := ctl.Stat(path); err != nil {
+		return err
+	}
+
+	baseURL, err := gatewayBaseURL(ctl)
+	if err != nil {
+		return err
+	}
+
 	escapedPath := url.PathEscape(strings.TrimLeft(path, "/"))
-	fmt.Printf("%s://%s%s/get/%s\n", protocol, domain, port, escapedPath)
+	fmt.Printf("%s/get/%s\n", baseURL, escapedPath)
 	return nil
 }
 
@@ -856,7 +886,7 @@ func handleGatewayUserList(ctx *cli.Context, ctl *client.Client) error {
 		if len(users) == 0 {
 			fmt.Println("No users. Add some with »brig gw user add <name> <pass> <folders...>«")
 		} else {
-			fmt.Fprintln(tabW, "NAME\tFOLDERS\tRIGHTS\t")
+			fmt.Fprintln(tabW, "NAME\tFOLDERS\tRIGHTS\tACCESS KEYS\t")
 		}
 	}
 
@@ -871,16 +901,232 @@ func handleGatewayUserList(ctx *cli.Context, ctl *client.Client) error {
 
 		fmt.Fprintf(
 			tabW,
-			"%s\t%s\t%s\t\n",
+			"%s\t%s\t%s\t%s\t\n",
 			user.Name,
 			strings.Join(user.Folders, ","),
 			strings.Join(user.Rights, ","),
+			strings.Join(user.AccessKeys, ","),
+		)
+	}
+
+	return tabW.Flush()
+}
+
+func handleGatewayUserKeyAdd(ctx *cli.Context, ctl *client.Client) error {
+	id, secret, err := ctl.GatewayUserKeyAdd(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Access key id:     %s\n", id)
+	fmt.Printf("Secret access key: %s\n", secret)
+	return nil
+}
+
+func handleGatewayUserKeyRemove(ctx *cli.Context, ctl *client.Client) error {
+	return ctl.GatewayUserKeyRemove(ctx.Args().Get(0), ctx.Args().Get(1))
+}
+
+func handleGatewayUserTokenAdd(ctx *cli.Context, ctl *client.Client) error {
+	rights := []string{}
+	if r := ctx.String("rights"); r != "" {
+		rights = strings.Split(r, ",")
+	}
+
+	name := ctx.Args().First()
+	folders := ctx.Args().Tail()
+	token, _, err := ctl.GatewayUserTokenAdd(name, ctx.String("name"), rights, folders)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("API token: %s\n", token)
+	fmt.Println("It is only shown once. Send it as »Authorization: Bearer <token>« header.")
+	return nil
+}
+
+func handleGatewayUserTokenList(ctx *cli.Context, ctl *client.Client) error {
+	users, err := ctl.GatewayUserList()
+	if err != nil {
+		return err
+	}
+
+	name := ctx.Args().First()
+	for _, user := range users {
+		if user.Name != name {
+			continue
+		}
+
+		if len(user.APITokens) == 0 {
+			fmt.Printf("No api tokens. Add some with »brig gw user token add %s«\n", name)
+			return nil
+		}
+
+		tabW := tabwriter.NewWriter(
+			os.Stdout, 0, 0, 2, ' ',
+			tabwriter.StripEscape,
+		)
+
+		fmt.Fprintln(tabW, "ID\tNAME\tRIGHTS\tFOLDERS\tCREATED\t")
+		for _, token := range user.APITokens {
+			rights := "all"
+			if len(token.Rights) > 0 {
+				rights = strings.Join(token.Rights, ",")
+			}
+
+			folders := "all"
+			if len(token.Folders) > 0 {
+				folders = strings.Join(token.Folders, ",")
+			}
+
+			fmt.Fprintf(
+				tabW,
+				"%s\t%s\t%s\t%s\t%s\t\n",
+				token.ID,
+				token.Name,
+				rights,
+				folders,
+				token.CreatedAt.Local().Format("2006-01-02 15:04"),
+			)
+		}
+
+		return tabW.Flush()
+	}
+
+	return fmt.Errorf("no such user: %s", name)
+}
+
+func handleGatewayUserTokenRemove(ctx *cli.Context, ctl *client.Client) error {
+	name := ctx.Args().First()
+	for _, id := range ctx.Args().Tail() {
+		if err := ctl.GatewayUserTokenRemove(name, id); err != nil {
+			return e.Wrapf(err, "failed to remove api token %s", id)
+		}
+	}
+
+	return nil
+}
+
+func handleGatewayShareCreate(ctx *cli.Context, ctl *client.Client) error {
+	var expiresAt time.Time
+	if expire := ctx.String("expire"); expire != "" {
+		lifetime, err := retention.ParseDuration(expire)
+		if err != nil {
+			return err
+		}
+
+		expiresAt = time.Now().Add(lifetime)
+	}
+
+	link, err := ctl.GatewayShareCreate(
+		ctx.Args().First(),
+		ctx.String("rev"),
+		expiresAt,
+		uint64(ctx.Int("max-downloads")),
+		ctx.String("password"),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	baseURL, err := gatewayBaseURL(ctl)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s/share/%s\n", baseURL, link.Token)
+	return nil
+}
+
+func handleGatewayShareList(ctx *cli.Context, ctl *client.Client) error {
+	links, err := ctl.GatewayShareList()
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := readFormatTemplate(ctx)
+	if err != nil {
+		return err
+	}
+
+	if tmpl == nil && len(links) == 0 {
+		fmt.Println("No share links. Create one with »brig gw share create <path>«")
+		return nil
+	}
+
+	tabW := tabwriter.NewWriter(
+		os.Stdout, 0, 0, 2, ' ',
+		tabwriter.StripEscape,
+	)
+
+	if tmpl == nil {
+		fmt.Fprintln(tabW, "TOKEN\tPATH\tREV\tOWNER\tEXPIRES\tDOWNLOADS\tPASSWORD\t")
+	}
+
+	for _, link := range links {
+		if tmpl != nil {
+			if err := tmpl.Execute(os.Stdout, link); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		rev := "-"
+		if link.Rev != "" {
+			rev = link.Rev
+		}
+
+		owner := "-"
+		if link.Owner != "" {
+			owner = link.Owner
+		}
+
+		expires := "never"
+		switch {
+		case link.IsExpired:
+			expires = color.RedString("expired")
+		case !link.ExpiresAt.IsZero():
+			expires = link.ExpiresAt.Local().Format("2006-01-02 15:04")
+		}
+
+		downloads := fmt.Sprintf("%d", link.Downloads)
+		if link.MaxDownloads > 0 {
+			downloads += fmt.Sprintf("/%d", link.MaxDownloads)
+		}
+
+		password := "no"
+		if link.HasPassword {
+			password = "yes"
+		}
+
+		fmt.Fprintf(
+			tabW,
+			"%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			link.Token,
+			link.Path,
+			rev,
+			owner,
+			expires,
+			downloads,
+			password,
 		)
 	}
 
 	return tabW.Flush()
 }
 
+func handleGatewayShareRevoke(ctx *cli.Context, ctl *client.Client) error {
+	for _, token := range ctx.Args() {
+		if err := ctl.GatewayShareRevoke(token); err != nil {
+			return e.Wrapf(err, "failed to revoke »%s«", token)
+		}
+	}
+
+	return nil
+}
+
 func readPassword(ctx *cli.Context, isNew bool) ([]byte, error) {
 	if ctx.IsSet("password-command") {
 		log.Debugf("reading by password command.")
@@ -1061,6 +1307,51 @@ func handleRepoHintsRemove(ctx *cli.Context, ctl *client.Client) error {
 	return ctl.HintRemove(ctx.Args().First())
 }
 
+func handleRetentionSet(ctx *cli.Context, ctl *client.Client) error {
+	path, policy := ctx.Args().Get(0), ctx.Args().Get(1)
+
+	if !ctx.Bool("force") {
+		if _, err := ctl.Stat(path); err != nil {
+			return fmt.Errorf("no file or directory at »%s« (use --force to create anyways)", path)
+		}
+	}
+
+	return ctl.RetentionSet(path, policy)
+}
+
+func handleRetentionList(ctx *cli.Context, ctl *client.Client) error {
+	if len(ctx.Args()) != 0 {
+		return fmt.Errorf("extra arguments passed")
+	}
+
+	policies, err := ctl.RetentionList()
+	if err != nil {
+		return err
+	}
+
+	if len(policies) == 0 {
+		fmt.Println("No retention policies set; all versions are kept.")
+		return nil
+	}
+
+	tabW := tabwriter.NewWriter(
+		os.Stdout, 0, 0, 2, ' ',
+		tabwriter.StripEscape,
+	)
+
+	fmt.Fprintln(tabW, "PATH\tPOLICY\t")
+
+	for _, policy := range policies {
+		fmt.Fprintf(tabW, "%s\t%s\t\n", policy.Path, policy.Policy)
+	}
+
+	return tabW.Flush()
+}
+
+func handleRetentionRemove(ctx *cli.Context, ctl *client.Client) error {
+	return ctl.RetentionRemove(ctx.Args().First())
+}
+
 func handleRepoHintsRecode(ctx *cli.Context, ctl *client.Client) error {
 	repoPath := ctx.Args().Get(0)
 	if repoPath == "" {
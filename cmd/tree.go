@@ -156,6 +156,8 @@ func (n *treeNode) Print(cfg *treeCfg) {
 				return color.MagentaString("•")
 			case n.entry.IsDir:
 				return " " + color.GreenString(n.name+"/")
+			case n.entry.IsSymlink:
+				return " " + color.CyanString(n.name) + " -> " + n.entry.Target
 			}
 
 			return " " + n.name
@@ -20,6 +20,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/sahib/brig/client"
 	"github.com/sahib/brig/defaults"
+	"github.com/sahib/brig/repo"
 	"github.com/sahib/brig/util"
 	"github.com/sahib/config"
 	log "github.com/sirupsen/logrus"
@@ -106,6 +107,18 @@ func openConfig(folder string) (*config.Config, error) {
 	return cfg, nil
 }
 
+// repoBackendName returns the name of the backend the repository at `folder`
+// was initialized with. If it cannot be read, "httpipfs" is assumed.
+func repoBackendName(folder string) string {
+	immutables, err := repo.NewImmutables(filepath.Join(folder, "immutable.yml"))
+	if err != nil {
+		log.Debugf("failed to read immutables at %v: %v", folder, err)
+		return "httpipfs"
+	}
+
+	return immutables.Backend()
+}
+
 func guessDaemonURL(ctx *cli.Context) (string, error) {
 	if ctx.GlobalIsSet("url") {
 		// No guessing needed, follow user wish.
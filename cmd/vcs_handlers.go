@@ -11,7 +11,9 @@ import (
 
 	"github.com/sahib/brig/cmd/tabwriter"
 
+	"github.com/dustin/go-humanize"
 	"github.com/fatih/color"
+	isatty "github.com/mattn/go-isatty"
 	"github.com/sahib/brig/client"
 	"github.com/urfave/cli"
 )
@@ -136,6 +138,62 @@ func handleHistory(ctx *cli.Context, ctl *client.Client) error {
 	return tabW.Flush()
 }
 
+func handleHistorySquash(ctx *cli.Context, ctl *client.Client) error {
+	before := ctx.String("before")
+	if before == "" {
+		return ExitCode{BadArgs, "history squash: --before is required"}
+	}
+
+	dropped, err := ctl.HistorySquash(before)
+	if err != nil {
+		return ExitCode{UnknownError, fmt.Sprintf("history squash: %v", err)}
+	}
+
+	if dropped == 0 {
+		fmt.Println("Nothing to squash.")
+		return nil
+	}
+
+	fmt.Printf("Squashed %s commits.\n", color.GreenString("%d", dropped))
+	return nil
+}
+
+func handleBlame(ctx *cli.Context, ctl *client.Client) error {
+	entries, err := ctl.Blame(ctx.Args().First())
+	if err != nil {
+		return ExitCode{UnknownError, fmt.Sprintf("blame: %v", err)}
+	}
+
+	tabW := tabwriter.NewWriter(
+		os.Stdout, 0, 0, 2, ' ',
+		tabwriter.StripEscape,
+	)
+
+	if len(entries) != 0 {
+		fmt.Fprintf(tabW, "CHANGE\tCOMMIT\tREMOTE\tVIA\tWHEN\t\n")
+	}
+
+	for _, entry := range entries {
+		via := color.GreenString(entry.Via)
+		if entry.Via != "local" {
+			via = color.RedString(entry.Via)
+		}
+
+		fmt.Fprintf(
+			tabW,
+			"%s\t%s\t%s\t%s\t%s\t%s\n",
+			color.YellowString(strings.Join(entry.Mask, ", ")),
+			color.CyanString(commitName(entry.Commit)),
+			color.BlueString(entry.Remote),
+			via,
+			color.MagentaString(entry.Commit.Date.Format(time.UnixDate)),
+			entry.Commit.Msg,
+		)
+	}
+
+	return tabW.Flush()
+}
+
 // makePathAbbrev tries to abbreviate the `dst` path if
 // both are in the same directory.
 func makePathAbbrev(srcNd, dstNd client.StatInfo) string {
@@ -486,8 +544,71 @@ func handleDiff(ctx *cli.Context, ctl *client.Client) error {
 	return nil
 }
 
+// showTransferProgress prints the progress of metadata transfers from `who`
+// to stderr until the returned function is called. Nothing is shown if
+// stderr is not a terminal.
+func showTransferProgress(ctl *client.Client, who string) func() {
+	if !isatty.IsTerminal(os.Stderr.Fd()) {
+		return func() {}
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+
+		printed := false
+		for {
+			select {
+			case <-stop:
+				if printed {
+					// Clear the progress line again:
+					fmt.Fprintf(os.Stderr, "\r\033[K")
+				}
+				return
+			case <-ticker.C:
+			}
+
+			transfers, err := ctl.TransferList()
+			if err != nil {
+				continue
+			}
+
+			for _, transfer := range transfers {
+				if transfer.Remote != who || transfer.Total <= 0 {
+					continue
+				}
+
+				fmt.Fprintf(
+					os.Stderr,
+					"\r\033[KFetching %s from %s: %s / %s (%d%%)",
+					transfer.Kind,
+					transfer.Remote,
+					humanize.Bytes(uint64(transfer.Done)),
+					humanize.Bytes(uint64(transfer.Total)),
+					100*transfer.Done/transfer.Total,
+				)
+				printed = true
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
 func handleFetch(ctx *cli.Context, ctl *client.Client) error {
 	who := ctx.Args().First()
+
+	stopProgress := showTransferProgress(ctl, who)
+	defer stopProgress()
+
 	return ctl.Fetch(who)
 }
 
@@ -527,7 +648,10 @@ func handleSyncSingle(ctx *cli.Context, ctl *client.Client, remoteName string) e
 		return nil
 	}
 
+	stopProgress := showTransferProgress(ctl, remoteName)
 	diff, err := ctl.Sync(remoteName, needFetch)
+	stopProgress()
+
 	if err != nil {
 		return err
 	}
@@ -648,8 +772,19 @@ func handleTag(ctx *cli.Context, ctl *client.Client) error {
 	return nil
 }
 
+func formatSignature(status string) string {
+	switch status {
+	case "good":
+		return color.GreenString("good")
+	case "bad":
+		return color.RedString("BAD!")
+	default:
+		return color.YellowString("none")
+	}
+}
+
 func handleLog(ctx *cli.Context, ctl *client.Client) error {
-	entries, err := ctl.Log()
+	entries, err := ctl.Log(ctx.Bool("verify"))
 	if err != nil {
 		return ExitCode{UnknownError, fmt.Sprintf("commit: %v", err)}
 	}
@@ -693,6 +828,10 @@ func handleLog(ctx *cli.Context, ctl *client.Client) error {
 			commitHash = "      -     "
 		}
 
+		if ctx.Bool("verify") {
+			fmt.Printf("%s ", formatSignature(entry.Signature))
+		}
+
 		fmt.Printf(
 			"%s %s %s%s\n",
 			color.GreenString(commitHash),
@@ -704,3 +843,199 @@ func handleLog(ctx *cli.Context, ctl *client.Client) error {
 
 	return nil
 }
+
+func handleConflictsList(ctx *cli.Context, ctl *client.Client) error {
+	root := "/"
+	if ctx.NArg() > 0 {
+		root = ctx.Args().First()
+	}
+
+	conflicts, err := ctl.ConflictList(root)
+	if err != nil {
+		return ExitCode{UnknownError, fmt.Sprintf("conflicts: %v", err)}
+	}
+
+	if len(conflicts) == 0 {
+		fmt.Println("There are no conflicts.")
+		return nil
+	}
+
+	tabW := tabwriter.NewWriter(
+		os.Stdout, 0, 0, 2, ' ',
+		tabwriter.StripEscape,
+	)
+
+	fmt.Fprintf(tabW, "PATH\tCONFLICT FILE\tREMOTE\tWHEN\t\n")
+	for _, cf := range conflicts {
+		fmt.Fprintf(
+			tabW,
+			"%s\t%s\t%s\t%s\t\n",
+			color.GreenString(cf.Path),
+			color.RedString(cf.ConflictPath),
+			color.CyanString(cf.Remote),
+			color.MagentaString(cf.Theirs.Commit.Date.Format(time.UnixDate)),
+		)
+	}
+
+	return tabW.Flush()
+}
+
+func printConflictSide(tabW *tabwriter.Writer, title string, side *client.ConflictSide) {
+	printPair := func(name string, val interface{}) {
+		fmt.Fprintf(
+			tabW,
+			"  %s\t%v\t\n",
+			color.WhiteString(name),
+			val,
+		)
+	}
+
+	fmt.Fprintf(tabW, "%s\t\t\n", color.YellowString(title))
+	if side == nil {
+		printPair("Path", color.RedString("(removed)"))
+		return
+	}
+
+	printPair("Path", side.Info.Path)
+	printPair("User", side.Info.User)
+	printPair("Size", fmt.Sprintf("%s (%d bytes)", humanize.Bytes(side.Info.Size), side.Info.Size))
+	printPair("ModTime", side.Info.ModTime.Format(time.RFC3339))
+	printPair("Content", side.Info.ContentHash.B58String())
+	printPair("Commit", color.GreenString(commitName(&side.Commit)))
+	printPair("Commit Date", side.Commit.Date.Format(time.RFC3339))
+	printPair("Commit Msg", side.Commit.Msg)
+}
+
+func handleConflictsShow(ctx *cli.Context, ctl *client.Client) error {
+	root := ctx.Args().First()
+	conflicts, err := ctl.ConflictList(root)
+	if err != nil {
+		return ExitCode{UnknownError, fmt.Sprintf("conflicts: %v", err)}
+	}
+
+	if len(conflicts) == 0 {
+		return ExitCode{BadArgs, fmt.Sprintf("no conflicts for %s", root)}
+	}
+
+	tabW := tabwriter.NewWriter(
+		os.Stdout, 0, 0, 2, ' ',
+		tabwriter.StripEscape,
+	)
+
+	for idx, cf := range conflicts {
+		if idx > 0 {
+			fmt.Fprintf(tabW, "\t\t\n")
+		}
+
+		printConflictSide(tabW, "Ours:", cf.Ours)
+		printConflictSide(tabW, fmt.Sprintf("Theirs (%s):", cf.Remote), &cf.Theirs)
+	}
+
+	return tabW.Flush()
+}
+
+func handleConflictsResolveWith(strategy string) cmdHandlerWithClient {
+	return func(ctx *cli.Context, ctl *client.Client) error {
+		paths := []string(ctx.Args())
+		if ctx.Bool("all") {
+			if len(paths) > 0 {
+				return ExitCode{BadArgs, "conflicts: --all does not take paths"}
+			}
+
+			paths = []string{"/"}
+		}
+
+		if len(paths) == 0 {
+			return ExitCode{BadArgs, "conflicts: need at least one path or --all"}
+		}
+
+		if err := ctl.ConflictResolve(paths, strategy); err != nil {
+			return ExitCode{UnknownError, fmt.Sprintf("conflicts: %v", err)}
+		}
+
+		return nil
+	}
+}
+
+func handleBranchList(ctx *cli.Context, ctl *client.Client) error {
+	branches, err := ctl.BranchList()
+	if err != nil {
+		return ExitCode{UnknownError, fmt.Sprintf("branch: %v", err)}
+	}
+
+	tabW := tabwriter.NewWriter(
+		os.Stdout, 0, 0, 2, ' ',
+		tabwriter.StripEscape,
+	)
+
+	fmt.Fprintf(tabW, "\tNAME\tHEAD\tWHEN\tMESSAGE\t\n")
+	for _, branch := range branches {
+		marker, name := "", branch.Name
+		if branch.IsCurrent {
+			marker, name = "*", color.GreenString(branch.Name)
+		}
+
+		fmt.Fprintf(
+			tabW,
+			"%s\t%s\t%s\t%s\t%s\t\n",
+			marker,
+			name,
+			color.CyanString(branch.Head.Hash.ShortB58()),
+			color.YellowString(branch.Head.Date.Format(time.UnixDate)),
+			branch.Head.Msg,
+		)
+	}
+
+	return tabW.Flush()
+}
+
+func handleBranchCreate(ctx *cli.Context, ctl *client.Client) error {
+	name := ctx.Args().First()
+	rev := ctx.Args().Get(1)
+
+	if err := ctl.BranchCreate(name, rev); err != nil {
+		return ExitCode{UnknownError, fmt.Sprintf("branch create: %v", err)}
+	}
+
+	if ctx.Bool("switch") {
+		if err := ctl.BranchSwitch(name, false); err != nil {
+			return ExitCode{UnknownError, fmt.Sprintf("branch switch: %v", err)}
+		}
+	}
+
+	return nil
+}
+
+func handleBranchSwitch(ctx *cli.Context, ctl *client.Client) error {
+	if err := ctl.BranchSwitch(ctx.Args().First(), ctx.Bool("force")); err != nil {
+		return ExitCode{UnknownError, fmt.Sprintf("branch switch: %v", err)}
+	}
+
+	return nil
+}
+
+func handleBranchDelete(ctx *cli.Context, ctl *client.Client) error {
+	for _, name := range ctx.Args() {
+		if err := ctl.BranchDelete(name); err != nil {
+			return ExitCode{UnknownError, fmt.Sprintf("branch delete: %v", err)}
+		}
+	}
+
+	return nil
+}
+
+func handleBranchMerge(ctx *cli.Context, ctl *client.Client) error {
+	if err := ctl.BranchMerge(ctx.Args().First()); err != nil {
+		return ExitCode{UnknownError, fmt.Sprintf("branch merge: %v", err)}
+	}
+
+	return nil
+}
+
+func handleRevert(ctx *cli.Context, ctl *client.Client) error {
+	if err := ctl.Revert(ctx.Args().First()); err != nil {
+		return ExitCode{UnknownError, fmt.Sprintf("revert: %v", err)}
+	}
+
+	return nil
+}
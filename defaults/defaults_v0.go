@@ -105,6 +105,25 @@ var DefaultsV0 = config.DefaultMapping{
 				Docs:         "Enable debug mode (load resources from filesystem).",
 			},
 		},
+		"webdav": config.DefaultMapping{
+			"enabled": config.DefaultEntry{
+				Default:      true,
+				NeedsRestart: false,
+				Docs:         "Serve the filesystem via WebDAV under /webdav.",
+			},
+		},
+		"s3": config.DefaultMapping{
+			"enabled": config.DefaultEntry{
+				Default:      false,
+				NeedsRestart: false,
+				Docs:         "Serve an S3 compatible API, where buckets are top-level folders.",
+			},
+			"port": config.DefaultEntry{
+				Default:      6003,
+				NeedsRestart: false,
+				Docs:         "On what port the S3 API runs on.",
+			},
+		},
 		"auth": config.DefaultMapping{
 			"anon_allowed": config.DefaultEntry{
 				Default:      false,
@@ -133,6 +152,27 @@ var DefaultsV0 = config.DefaultMapping{
 			},
 		},
 	},
+	"net": config.DefaultMapping{
+		"transport": config.DefaultEntry{
+			Default:      "backend",
+			NeedsRestart: true,
+			Validator: config.EnumValidator(
+				"backend", "direct",
+			),
+			Docs: `How to talk to other peers:
+
+  * backend: Use the network of the data backend (e.g. IPFS p2p streams).
+  * direct: Use TLS over TCP and the addresses stored in the remote list.
+`,
+		},
+		"direct": config.DefaultMapping{
+			"listen_addr": config.DefaultEntry{
+				Default:      ":6002",
+				NeedsRestart: true,
+				Docs:         "Address (host:port) to accept peer connections on with the direct transport.",
+			},
+		},
+	},
 	"fs": config.DefaultMapping{
 		"sync": config.DefaultMapping{
 			"ignore_removed": config.DefaultEntry{
@@ -150,17 +190,29 @@ var DefaultsV0 = config.DefaultMapping{
 				NeedsRestart: false,
 				Docs:         "Do not pin files which were added at the remote",
 			},
+			"require_signatures": config.DefaultEntry{
+				Default:      false,
+				NeedsRestart: false,
+				Docs: `Refuse to sync with remotes that have unsigned commits.
+
+  Commits are signed with the key of the committer. Commits with a bad
+  signature are always refused. Unsigned commits are made by older versions
+  of brig and are only refused if this option is enabled.
+`,
+			},
 			"conflict_strategy": config.DefaultEntry{
 				Default:      "marker",
 				NeedsRestart: false,
 				Validator: config.EnumValidator(
-					"marker", "ignore", "embrace",
+					"marker", "ignore", "embrace", "merge",
 				),
 				Docs: `What strategy to apply in case of conflicts:
 
   * marker: Create a conflict file with the remote's version.
   * ignore: Ignore the remote version completely and keep our version.
   * embrace: Take the remote version and replace ours with it.
+  * merge: Merge both versions of text files line by line. Falls back to
+    "marker" if the changes overlap or the files are not text.
 `,
 			},
 		},
@@ -171,6 +223,63 @@ var DefaultsV0 = config.DefaultMapping{
 				Docs:         "pre-cache files up-on pinning.",
 			},
 		},
+		"preserve_owner": config.DefaultEntry{
+			Default:      false,
+			NeedsRestart: false,
+			Docs: `Record the numeric owner (uid/gid) of staged files.
+
+  If enabled, the fuse layer reports the recorded owner instead of the user
+  running brig. Only useful if all peers share the same uid/gid mapping.
+`,
+		},
+		"chunking": config.DefaultMapping{
+			"enabled": config.DefaultEntry{
+				Default:      false,
+				NeedsRestart: false,
+				Docs: `Split staged files into content-defined chunks.
+
+  Each chunk is stored as separate object in the backend. Editing a big file
+  will then only add the changed chunks. Chunks of different files are only
+  shared if chunking.convergent is enabled. Files smaller than one chunk are
+  not affected. Older versions of brig can not read chunked files, so only
+  enable this if all your peers are up to date.
+`,
+			},
+			"avg_size": config.DefaultEntry{
+				Default:      "1M",
+				NeedsRestart: false,
+				Docs:         "Average size of a chunk. Chunks are between a quarter and four times of this size.",
+			},
+			"convergent": config.DefaultEntry{
+				Default:      false,
+				NeedsRestart: false,
+				Docs: `Derive the key of a chunk only from its content.
+
+  By default chunks are encrypted with a key derived from the file's key,
+  so only chunks of the same file (over its versions) are deduplicated.
+  If enabled, identical chunks of different files are stored only once.
+  WARNING: This is convergent encryption. Everyone who has a candidate file
+  can find out if a peer stores it. Only enable it if you trust your peers.
+`,
+			},
+		},
+		"find": config.DefaultMapping{
+			"content_index": config.DefaultEntry{
+				Default:      false,
+				NeedsRestart: false,
+				Docs: `Allow searching the content of text files with »brig find content:...«.
+
+  The words of each text file are stored in the metadata once the file was
+  searched for the first time. This needs to read the content of all files
+  that match the rest of the query, which might fetch them from the network.
+`,
+			},
+			"index_max_size": config.DefaultEntry{
+				Default:      "8M",
+				NeedsRestart: false,
+				Docs:         "Text files bigger than this are not indexed for content searches.",
+			},
+		},
 		"pagecache": config.DefaultMapping{
 			"max_memory": config.DefaultEntry{
 				Default:      "1G",
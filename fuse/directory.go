@@ -11,6 +11,7 @@ import (
 
 	"bazil.org/fuse"
 	"bazil.org/fuse/fs"
+	ie "github.com/sahib/brig/catfs/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -30,17 +31,28 @@ func (dir *Directory) Attr(ctx context.Context, attr *fuse.Attr) error {
 		return errorize("dir-attr", err)
 	}
 
-	// Act like the file is owned by the user of the brig process.
-	attr.Uid = uint32(os.Getuid())
-	attr.Gid = uint32(os.Getgid())
+	setAttrOwner(attr, info)
 
-	attr.Mode = os.ModeDir | 0755
+	attr.Mode = os.ModeDir | info.Mode
 	attr.Size = info.Size
 	attr.Mtime = info.ModTime
 	attr.Inode = info.Inode
 	return nil
 }
 
+// Setattr is called once an attribute of the directory changes.
+// Only permission bits and ownership are handled for directories.
+func (dir *Directory) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *fuse.SetattrResponse) error {
+	defer logPanic("dir: setattr")
+	debugLog("Exec dir setattr: %v", dir.path)
+
+	if err := setAttrs(dir.m.fs, dir.path, req); err != nil {
+		return errorize("dir-setattr", err)
+	}
+
+	return nil
+}
+
 // Lookup is called to lookup a direct child of the directory.
 func (dir *Directory) Lookup(ctx context.Context, name string) (fs.Node, error) {
 	defer logPanic("dir: lookup")
@@ -59,12 +71,22 @@ func (dir *Directory) Lookup(ctx context.Context, name string) (fs.Node, error)
 
 	info, err := dir.m.fs.Stat(childPath)
 	if err != nil {
+		// The snapshots are hidden; they do not show up in ReadDirAll.
+		// A real node with the same name always takes precedence.
+		isSnapshots := name == snapshotsDirName && dir.path == dir.m.options.Root
+		if isSnapshots && ie.IsNoSuchFileError(err) {
+			return &SnapshotsDir{m: dir.m}, nil
+		}
+
 		return nil, errorize("dir-lookup", err)
 	}
 
-	if info.IsDir {
+	switch {
+	case info.IsDir:
 		result = &Directory{path: childPath, m: dir.m}
-	} else {
+	case info.IsSymlink:
+		result = &Symlink{path: childPath, m: dir.m}
+	default:
 		result = &File{path: childPath, m: dir.m}
 	}
 
@@ -91,6 +113,26 @@ func (dir *Directory) Mkdir(ctx context.Context, req *fuse.MkdirRequest) (fs.Nod
 	return &Directory{path: childPath, m: dir.m}, nil
 }
 
+// Symlink is called to create a new symbolic link inside the receiver.
+func (dir *Directory) Symlink(ctx context.Context, req *fuse.SymlinkRequest) (fs.Node, error) {
+	defer logPanic("dir: symlink")
+
+	debugLog("fuse-symlink: %v -> %v", req.NewName, req.Target)
+
+	childPath := path.Join(dir.path, req.NewName)
+	if err := dir.m.fs.Symlink(childPath, req.Target); err != nil {
+		log.WithFields(log.Fields{
+			"path":  childPath,
+			"error": err,
+		}).Warning("fuse-symlink failed")
+
+		return nil, fuse.EIO
+	}
+
+	notifyChange(dir.m, 100*time.Millisecond)
+	return &Symlink{path: childPath, m: dir.m}, nil
+}
+
 // Create is called to create an opened file or directory  as child of the receiver.
 func (dir *Directory) Create(ctx context.Context, req *fuse.CreateRequest, resp *fuse.CreateResponse) (fs.Node, fs.Handle, error) {
 	defer logPanic("dir: create")
@@ -179,6 +221,8 @@ func (dir *Directory) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
 		childType := fuse.DT_File
 		if entry.IsDir {
 			childType = fuse.DT_Dir
+		} else if entry.IsSymlink {
+			childType = fuse.DT_Link
 		}
 
 		// If we return the same path (or just "/") to fuse
@@ -255,9 +299,25 @@ func (dir *Directory) Listxattr(ctx context.Context, req *fuse.ListxattrRequest,
 
 	// Do not worry about req.Size
 	// fuse will cut it to allowed size and report to the caller that buffer need to be larger
-	resp.Xattr = listXattr()
+	xattrs, err := listXattr(dir.m.fs, dir.path)
+	if err != nil {
+		return err
+	}
+
+	resp.Xattr = xattrs
 	return nil
 }
 
+// Removexattr is called by the removexattr syscall.
+// Only user defined xattrs can be removed.
+func (dir *Directory) Removexattr(ctx context.Context, req *fuse.RemovexattrRequest) error {
+	defer logPanic("dir: removexattr")
+
+	return removeXattr(dir.m.fs, req.Name, dir.path)
+}
+
 var _ = fs.NodeGetxattrer(&Directory{})
+var _ = fs.NodeSymlinker(&Directory{})
 var _ = fs.NodeListxattrer(&Directory{})
+var _ = fs.NodeRemovexattrer(&Directory{})
+var _ = fs.NodeSetattrer(&Directory{})
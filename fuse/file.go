@@ -42,7 +42,7 @@ func (fi *File) Attr(ctx context.Context, attr *fuse.Attr) error {
 	}
 	debugLog("exec file attr: %v", fi.path)
 
-	var filePerm os.FileMode = 0640
+	filePerm := info.Mode
 	attr.Mode = filePerm
 	if fi.m.options.Offline {
 		isCached, err := fi.m.fs.IsCached(fi.path)
@@ -59,10 +59,7 @@ func (fi *File) Attr(ctx context.Context, attr *fuse.Attr) error {
 	attr.Size = info.Size
 	attr.Mtime = info.ModTime
 	attr.Inode = info.Inode
-
-	// Act like the file is owned by the user of the brig process.
-	attr.Uid = uint32(os.Getuid())
-	attr.Gid = uint32(os.Getgid())
+	setAttrOwner(attr, info)
 
 	// tools like `du` rely on this for size calculation
 	// (assuming every fs block takes actual storage, but we only emulate this
@@ -132,6 +129,10 @@ func (fi *File) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *fus
 		}
 	}
 
+	if err := setAttrs(fi.m.fs, fi.path, req); err != nil {
+		return errorize("file-setattr-attrs", err)
+	}
+
 	return nil
 }
 
@@ -172,10 +173,23 @@ func (fi *File) Listxattr(ctx context.Context, req *fuse.ListxattrRequest, resp
 
 	// Do not worry about req.Size
 	// fuse will cut it to allowed size and report to the caller that buffer need to be larger
-	resp.Xattr = listXattr()
+	xattrs, err := listXattr(fi.m.fs, fi.path)
+	if err != nil {
+		return err
+	}
+
+	resp.Xattr = xattrs
 	return nil
 }
 
+// Removexattr is called by the removexattr syscall.
+// Only user defined xattrs can be removed.
+func (fi *File) Removexattr(ctx context.Context, req *fuse.RemovexattrRequest) error {
+	defer logPanic("file: removexattr")
+
+	return removeXattr(fi.m.fs, req.Name, fi.path)
+}
+
 // Readlink reads a symbolic link.
 // This call is triggered when OS tries to see where symlink points
 func (fi *File) Readlink(ctx context.Context, req *fuse.ReadlinkRequest) (string, error) {
@@ -197,12 +211,12 @@ var _ = fs.NodeOpener(&File{})
 var _ = fs.NodeSetattrer(&File{})
 var _ = fs.NodeReadlinker(&File{})
 var _ = fs.NodeSetxattrer(&File{})
+var _ = fs.NodeRemovexattrer(&File{})
 
 // Other interfaces are available, but currently not needed or make sense:
 // var _ = fs.NodeRenamer(&File{})
 // var _ = fs.NodeReadlinker(&File{})
 // var _ = fs.NodeRemover(&File{})
-// var _ = fs.NodeRemovexattrer(&File{})
 // var _ = fs.NodeRequestLookuper(&File{})
 // var _ = fs.NodeAccesser(&File{})
 // var _ = fs.NodeForgetter(&File{})
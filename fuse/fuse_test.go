@@ -407,6 +407,35 @@ func TestFileXattr(t *testing.T) {
 	})
 }
 
+func TestFileUserXattr(t *testing.T) {
+	withMount(t, MountOptions{}, func(ctx context.Context, control *spawntest.Control, mount *mountInfo) {
+		catfsFilePath := "/tagged"
+		req := catfsPayload{Path: catfsFilePath, Data: testutil.CreateDummyBuf(4)}
+		require.NoError(t, control.JSON("/catfsStage").Call(ctx, req, &nothing{}))
+
+		fuseFilePath := filepath.Join(mount.Dir, catfsFilePath)
+		require.NoError(t, syscall.Setxattr(fuseFilePath, "user.tags", []byte("red"), 0))
+
+		// Only the user namespace can be written to:
+		err := syscall.Setxattr(fuseFilePath, "trusted.tags", []byte("red"), 0)
+		require.Error(t, err)
+
+		response := make([]byte, 64)
+		sz, err := syscall.Getxattr(fuseFilePath, "user.tags", response)
+		require.NoError(t, err)
+		require.Equal(t, "red", string(response[:sz]))
+
+		response = make([]byte, 1024*4)
+		sz, err = syscall.Listxattr(fuseFilePath, response)
+		require.NoError(t, err)
+		require.Contains(t, bytes.Split(response[:sz], []byte{0}), []byte("user.tags"))
+
+		require.NoError(t, syscall.Removexattr(fuseFilePath, "user.tags"))
+		_, err = syscall.Getxattr(fuseFilePath, "user.tags", response)
+		require.Error(t, err)
+	})
+}
+
 func TestWrite(t *testing.T) {
 	withMount(t, MountOptions{}, func(ctx context.Context, control *spawntest.Control, mount *mountInfo) {
 		for _, size := range DataSizes {
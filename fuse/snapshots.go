@@ -0,0 +1,327 @@
+// +build !windows
+
+package fuse
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"sync"
+
+	"bazil.org/fuse"
+	"bazil.org/fuse/fs"
+	"github.com/sahib/brig/catfs"
+	"github.com/sahib/brig/catfs/mio"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// snapshotsDirName is the hidden directory in the root of the mount
+	// that shows the state of old commits.
+	snapshotsDirName = ".snapshots"
+
+	// snapshotDateLayout is used for the names of the snapshot directories.
+	snapshotDateLayout = "2006-01-02T15:04:05"
+)
+
+// SnapshotsDir lists one directory per commit, named after its date.
+// Other names are looked up as date rev (see »brig tag --help«) or as
+// normal rev, so `.snapshots/2026-10-01` or `.snapshots/head^` work too.
+type SnapshotsDir struct {
+	m *Mount
+}
+
+// Attr is called to retrieve stat-metadata about the directory.
+func (sd *SnapshotsDir) Attr(ctx context.Context, attr *fuse.Attr) error {
+	defer logPanic("snapshots: attr")
+
+	head, err := sd.m.fs.CommitInfo("head")
+	if err != nil {
+		return errorize("snapshots-attr", err)
+	}
+
+	attr.Mode = os.ModeDir | 0555
+	attr.Uid = uint32(os.Getuid())
+	attr.Gid = uint32(os.Getgid())
+	if head != nil {
+		attr.Mtime = head.Date
+	}
+
+	return nil
+}
+
+// Lookup resolves `name` to the commit it refers to.
+func (sd *SnapshotsDir) Lookup(ctx context.Context, name string) (fs.Node, error) {
+	defer logPanic("snapshots: lookup")
+
+	for _, rev := range []string{"@" + name, name} {
+		cmt, err := sd.m.fs.CommitInfo(rev)
+		if err != nil || cmt == nil {
+			continue
+		}
+
+		return &SnapshotDir{
+			rev:  cmt.Hash.B58String(),
+			path: sd.m.options.Root,
+			m:    sd.m,
+		}, nil
+	}
+
+	return nil, fuse.ENOENT
+}
+
+// ReadDirAll lists all commits of the current branch.
+func (sd *SnapshotsDir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
+	defer logPanic("snapshots: readdirall")
+
+	fuseEnts := []fuse.Dirent{
+		{Type: fuse.DT_Dir, Name: "."},
+		{Type: fuse.DT_Dir, Name: ".."},
+	}
+
+	// Several commits in the same second share a name;
+	// the newest one comes first and is the one Lookup() finds.
+	seen := make(map[string]bool)
+	err := sd.m.fs.Log("head", func(cmt *catfs.Commit) error {
+		name := cmt.Date.Format(snapshotDateLayout)
+		if !seen[name] {
+			seen[name] = true
+			fuseEnts = append(fuseEnts, fuse.Dirent{Type: fuse.DT_Dir, Name: name})
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errorize("snapshots-readdirall", err)
+	}
+
+	return fuseEnts, nil
+}
+
+// SnapshotDir is a read-only directory in a commit.
+// Snapshot nodes report no inode, so that fuse generates one for them;
+// the inodes stored in the commit are the same as the current ones.
+type SnapshotDir struct {
+	rev  string
+	path string
+	m    *Mount
+}
+
+// Attr is called to retrieve stat-metadata about the directory.
+func (sd *SnapshotDir) Attr(ctx context.Context, attr *fuse.Attr) error {
+	defer logPanic("snapshot-dir: attr")
+
+	info, err := sd.m.fs.StatAt(sd.rev, sd.path)
+	if err != nil {
+		return errorize("snapshot-dir-attr", err)
+	}
+
+	setAttrOwner(attr, info)
+	attr.Mode = os.ModeDir | (info.Mode &^ 0222)
+	attr.Size = info.Size
+	attr.Mtime = info.ModTime
+	return nil
+}
+
+// Lookup is called to lookup a direct child of the directory.
+func (sd *SnapshotDir) Lookup(ctx context.Context, name string) (fs.Node, error) {
+	defer logPanic("snapshot-dir: lookup")
+
+	childPath := path.Join(sd.path, name)
+	info, err := sd.m.fs.StatAt(sd.rev, childPath)
+	if err != nil {
+		return nil, errorize("snapshot-dir-lookup", err)
+	}
+
+	switch {
+	case info.IsDir:
+		return &SnapshotDir{rev: sd.rev, path: childPath, m: sd.m}, nil
+	case info.IsSymlink:
+		return &SnapshotSymlink{rev: sd.rev, path: childPath, m: sd.m}, nil
+	default:
+		return &SnapshotFile{rev: sd.rev, path: childPath, m: sd.m}, nil
+	}
+}
+
+// ReadDirAll is called to get a directory listing of the receiver.
+func (sd *SnapshotDir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
+	defer logPanic("snapshot-dir: readdirall")
+
+	entries, err := sd.m.fs.ListAt(sd.rev, sd.path, 1)
+	if err != nil {
+		return nil, errorize("snapshot-dir-readdirall", err)
+	}
+
+	fuseEnts := []fuse.Dirent{
+		{Type: fuse.DT_Dir, Name: "."},
+		{Type: fuse.DT_Dir, Name: ".."},
+	}
+
+	for _, entry := range entries {
+		if entry.Path == "/" || entry.Path == sd.path {
+			continue
+		}
+
+		childType := fuse.DT_File
+		if entry.IsDir {
+			childType = fuse.DT_Dir
+		} else if entry.IsSymlink {
+			childType = fuse.DT_Link
+		}
+
+		fuseEnts = append(fuseEnts, fuse.Dirent{
+			Type: childType,
+			Name: path.Base(entry.Path),
+		})
+	}
+
+	return fuseEnts, nil
+}
+
+// SnapshotFile is a read-only file in a commit.
+type SnapshotFile struct {
+	rev  string
+	path string
+	m    *Mount
+}
+
+// Attr is called to get the stat(2) attributes of a file.
+func (sf *SnapshotFile) Attr(ctx context.Context, attr *fuse.Attr) error {
+	defer logPanic("snapshot-file: attr")
+
+	info, err := sf.m.fs.StatAt(sf.rev, sf.path)
+	if err != nil {
+		return errorize("snapshot-file-attr", err)
+	}
+
+	setAttrOwner(attr, info)
+	attr.Mode = info.Mode &^ 0222
+	attr.Size = info.Size
+	attr.Mtime = info.ModTime
+	attr.BlockSize = 4096
+	attr.Blocks = info.Size / 512
+	if info.Size%uint64(512) > 0 {
+		attr.Blocks++
+	}
+
+	return nil
+}
+
+// Open is called to get a read-only handle of the file.
+func (sf *SnapshotFile) Open(ctx context.Context, req *fuse.OpenRequest, resp *fuse.OpenResponse) (fs.Handle, error) {
+	defer logPanic("snapshot-file: open")
+
+	if !req.Flags.IsReadOnly() {
+		return nil, fuse.EPERM
+	}
+
+	if sf.m.options.Offline {
+		isCached, err := sf.m.fs.IsCachedAt(sf.rev, sf.path)
+		if err != nil {
+			return nil, errorize("snapshot-file-is-cached", err)
+		}
+
+		if !isCached {
+			return nil, errorize("snapshot-file-not-cached", ErrNotCached)
+		}
+	}
+
+	stream, err := sf.m.fs.CatAt(sf.rev, sf.path)
+	if err != nil {
+		return nil, errorize("snapshot-file-open", err)
+	}
+
+	// The content of a commit never changes.
+	resp.Flags |= fuse.OpenKeepCache
+	return &SnapshotHandle{stream: stream, path: sf.path}, nil
+}
+
+// SnapshotHandle reads the content of a SnapshotFile.
+type SnapshotHandle struct {
+	mu     sync.Mutex
+	stream mio.Stream
+	path   string
+}
+
+// Read is called to read a block of data at a certain offset.
+func (sh *SnapshotHandle) Read(ctx context.Context, req *fuse.ReadRequest, resp *fuse.ReadResponse) error {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	defer logPanic("snapshot-handle: read")
+
+	if _, err := sh.stream.Seek(req.Offset, io.SeekStart); err != nil {
+		return errorize("snapshot-handle-seek", err)
+	}
+
+	n, err := io.ReadFull(sh.stream, resp.Data[:req.Size])
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return errorize("snapshot-handle-read", err)
+	}
+
+	resp.Data = resp.Data[:n]
+	return nil
+}
+
+// Release is called to close the handle.
+func (sh *SnapshotHandle) Release(ctx context.Context, req *fuse.ReleaseRequest) error {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	defer logPanic("snapshot-handle: release")
+
+	if err := sh.stream.Close(); err != nil {
+		log.Debugf("fuse: could not close snapshot stream of %s: %v", sh.path, err)
+	}
+
+	return nil
+}
+
+// SnapshotSymlink is a symbolic link in a commit.
+type SnapshotSymlink struct {
+	rev  string
+	path string
+	m    *Mount
+}
+
+// Attr is called to get the stat(2) attributes of a symlink.
+func (sl *SnapshotSymlink) Attr(ctx context.Context, attr *fuse.Attr) error {
+	defer logPanic("snapshot-symlink: attr")
+
+	info, err := sl.m.fs.StatAt(sl.rev, sl.path)
+	if err != nil {
+		return errorize("snapshot-symlink-attr", err)
+	}
+
+	setAttrOwner(attr, info)
+	attr.Mode = os.ModeSymlink | 0777
+	attr.Size = info.Size
+	attr.Mtime = info.ModTime
+	return nil
+}
+
+// Readlink is called to get the target of the symlink.
+func (sl *SnapshotSymlink) Readlink(ctx context.Context, req *fuse.ReadlinkRequest) (string, error) {
+	defer logPanic("snapshot-symlink: readlink")
+
+	info, err := sl.m.fs.StatAt(sl.rev, sl.path)
+	if err != nil {
+		return "", errorize("snapshot-symlink-readlink", err)
+	}
+
+	return info.Target, nil
+}
+
+// Compile time checks to see which interfaces we implement:
+var _ = fs.Node(&SnapshotsDir{})
+var _ = fs.NodeStringLookuper(&SnapshotsDir{})
+var _ = fs.HandleReadDirAller(&SnapshotsDir{})
+var _ = fs.Node(&SnapshotDir{})
+var _ = fs.NodeStringLookuper(&SnapshotDir{})
+var _ = fs.HandleReadDirAller(&SnapshotDir{})
+var _ = fs.Node(&SnapshotFile{})
+var _ = fs.NodeOpener(&SnapshotFile{})
+var _ = fs.HandleReader(&SnapshotHandle{})
+var _ = fs.HandleReleaser(&SnapshotHandle{})
+var _ = fs.Node(&SnapshotSymlink{})
+var _ = fs.NodeReadlinker(&SnapshotSymlink{})
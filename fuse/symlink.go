@@ -0,0 +1,54 @@
+// +build !windows
+
+package fuse
+
+import (
+	"context"
+	"os"
+
+	"bazil.org/fuse"
+	"bazil.org/fuse/fs"
+	log "github.com/sirupsen/logrus"
+)
+
+// Symlink is a symbolic link inside a directory.
+// The target is handed to the kernel as-is, which will do the resolving.
+type Symlink struct {
+	path string
+	m    *Mount
+}
+
+// Attr is called to get the stat(2) attributes of a symlink.
+func (sl *Symlink) Attr(ctx context.Context, attr *fuse.Attr) error {
+	defer logPanic("symlink: attr")
+	log.Debugf("fuse-symlink-attr: %v", sl.path)
+
+	info, err := sl.m.fs.Stat(sl.path)
+	if err != nil {
+		return errorize("symlink-attr", err)
+	}
+
+	attr.Mode = os.ModeSymlink | 0777
+	attr.Size = info.Size
+	attr.Mtime = info.ModTime
+	attr.Inode = info.Inode
+	setAttrOwner(attr, info)
+	return nil
+}
+
+// Readlink is called to get the target of the symlink.
+func (sl *Symlink) Readlink(ctx context.Context, req *fuse.ReadlinkRequest) (string, error) {
+	defer logPanic("symlink: readlink")
+	log.Debugf("fuse-symlink-readlink: %v", sl.path)
+
+	info, err := sl.m.fs.Stat(sl.path)
+	if err != nil {
+		return "", errorize("symlink-readlink", err)
+	}
+
+	return info.Target, nil
+}
+
+// Compile time checks to see which interfaces we implement:
+var _ = fs.Node(&Symlink{})
+var _ = fs.NodeReadlinker(&Symlink{})
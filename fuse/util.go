@@ -4,6 +4,7 @@ package fuse
 
 import (
 	"fmt"
+	"os"
 	"time"
 
 	"bazil.org/fuse"
@@ -102,19 +103,43 @@ var xattrMap = map[string]xattrHandler{
 	},
 }
 
-func listXattr() []byte {
+func listXattr(cfs *catfs.FS, path string) ([]byte, error) {
 	resp := []byte{}
 	for k := range xattrMap {
 		resp = append(resp, k...)
 		resp = append(resp, '\x00')
 	}
 
-	return resp
+	names, err := cfs.ListXattr(path)
+	if err != nil {
+		return nil, errorize("listxattr", err)
+	}
+
+	for _, name := range names {
+		resp = append(resp, name...)
+		resp = append(resp, '\x00')
+	}
+
+	return resp, nil
 }
 
 func getXattr(cfs *catfs.FS, name, path string) ([]byte, error) {
 	handler, ok := xattrMap[name]
-	if !ok || handler.get == nil {
+	if !ok {
+		// Not one of ours, might be one set by the user.
+		value, err := cfs.GetXattr(path, name)
+		if err == catfs.ErrNoSuchXattr {
+			return nil, fuse.ErrNoXattr
+		}
+
+		if err != nil {
+			return nil, errorize("getxattr", err)
+		}
+
+		return value, nil
+	}
+
+	if handler.get == nil {
 		return nil, fuse.ErrNoXattr
 	}
 
@@ -128,7 +153,20 @@ func getXattr(cfs *catfs.FS, name, path string) ([]byte, error) {
 
 func setXattr(cfs *catfs.FS, name, path string, val []byte) error {
 	handler, ok := xattrMap[name]
-	if !ok || handler.set == nil {
+	if !ok {
+		err := cfs.SetXattr(path, name, val)
+		if err == catfs.ErrBadXattrName {
+			return fuse.ENOTSUP
+		}
+
+		if err != nil {
+			return errorize("setxattr", err)
+		}
+
+		return nil
+	}
+
+	if handler.set == nil {
 		return fuse.ErrNoXattr
 	}
 
@@ -139,6 +177,71 @@ func setXattr(cfs *catfs.FS, name, path string, val []byte) error {
 	return nil
 }
 
+func removeXattr(cfs *catfs.FS, name, path string) error {
+	if _, ok := xattrMap[name]; ok {
+		// Internal attributes cannot be removed.
+		return fuse.EPERM
+	}
+
+	err := cfs.RemoveXattr(path, name)
+	if err == catfs.ErrNoSuchXattr {
+		return fuse.ErrNoXattr
+	}
+
+	if err != nil {
+		return errorize("removexattr", err)
+	}
+
+	return nil
+}
+
+// setAttrOwner fills the owner of `attr` from `info`.
+// Nodes without a stored owner act like they are owned
+// by the user of the brig process.
+func setAttrOwner(attr *fuse.Attr, info *catfs.StatInfo) {
+	if info.HasOwner {
+		attr.Uid = info.Uid
+		attr.Gid = info.Gid
+		return
+	}
+
+	attr.Uid = uint32(os.Getuid())
+	attr.Gid = uint32(os.Getgid())
+}
+
+// setAttrs applies mode and owner changes of a setattr request to `path`.
+func setAttrs(cfs *catfs.FS, path string, req *fuse.SetattrRequest) error {
+	if req.Valid.Mode() {
+		if err := cfs.Chmod(path, req.Mode); err != nil {
+			return err
+		}
+	}
+
+	if !req.Valid.Uid() && !req.Valid.Gid() {
+		return nil
+	}
+
+	info, err := cfs.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	uid, gid := uint32(os.Getuid()), uint32(os.Getgid())
+	if info.HasOwner {
+		uid, gid = info.Uid, info.Gid
+	}
+
+	if req.Valid.Uid() {
+		uid = req.Uid
+	}
+
+	if req.Valid.Gid() {
+		gid = req.Gid
+	}
+
+	return cfs.Chown(path, uid, gid)
+}
+
 func notifyChange(m *Mount, d time.Duration) {
 	if m.notifier == nil {
 		// this can happen in tests.
@@ -14,12 +14,12 @@ type User struct{ capnp.Struct }
 const User_TypeID = 0x861de4463c5a4a22
 
 func NewUser(s *capnp.Segment) (User, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 5})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 7})
 	return User{st}, err
 }
 
 func NewRootUser(s *capnp.Segment) (User, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 5})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 7})
 	return User{st}, err
 }
 
@@ -140,12 +140,62 @@ func (s User) NewRights(n int32) (capnp.TextList, error) {
 	return l, err
 }
 
+func (s User) AccessKeys() (AccessKey_List, error) {
+	p, err := s.Struct.Ptr(5)
+	return AccessKey_List{List: p.List()}, err
+}
+
+func (s User) HasAccessKeys() bool {
+	p, err := s.Struct.Ptr(5)
+	return p.IsValid() || err != nil
+}
+
+func (s User) SetAccessKeys(v AccessKey_List) error {
+	return s.Struct.SetPtr(5, v.List.ToPtr())
+}
+
+// NewAccessKeys sets the accessKeys field to a newly
+// allocated AccessKey_List, preferring placement in s's segment.
+func (s User) NewAccessKeys(n int32) (AccessKey_List, error) {
+	l, err := NewAccessKey_List(s.Struct.Segment(), n)
+	if err != nil {
+		return AccessKey_List{}, err
+	}
+	err = s.Struct.SetPtr(5, l.List.ToPtr())
+	return l, err
+}
+
+func (s User) ApiTokens() (ApiToken_List, error) {
+	p, err := s.Struct.Ptr(6)
+	return ApiToken_List{List: p.List()}, err
+}
+
+func (s User) HasApiTokens() bool {
+	p, err := s.Struct.Ptr(6)
+	return p.IsValid() || err != nil
+}
+
+func (s User) SetApiTokens(v ApiToken_List) error {
+	return s.Struct.SetPtr(6, v.List.ToPtr())
+}
+
+// NewApiTokens sets the apiTokens field to a newly
+// allocated ApiToken_List, preferring placement in s's segment.
+func (s User) NewApiTokens(n int32) (ApiToken_List, error) {
+	l, err := NewApiToken_List(s.Struct.Segment(), n)
+	if err != nil {
+		return ApiToken_List{}, err
+	}
+	err = s.Struct.SetPtr(6, l.List.ToPtr())
+	return l, err
+}
+
 // User_List is a list of User.
 type User_List struct{ capnp.List }
 
 // NewUser creates a new list of User.
 func NewUser_List(s *capnp.Segment, sz int32) (User_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 5}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 7}, sz)
 	return User_List{l}, err
 }
 
@@ -166,25 +216,544 @@ func (p User_Promise) Struct() (User, error) {
 	return User{s}, err
 }
 
-const schema_a0b1c18bd0f965c4 = "x\xda\\\xca1J\x03A\x18\xc5\xf1\xf7ff\x15$" +
-	"$\x0e\xac\x9d\"\x0a6\x16\x06\xdb (\x16\"V\xf9" +
-	"\x0a\x1b\x1b\x19\xdd11\xc4d\xd9Y\x89\x16\xa2B\x10" +
-	"E\x05\x0f`\xa1\xe0\x05\xecl\x05\x0f\xe0!\xbc\x84\xd5" +
-	"\xca\x06\xd2\xd8\xfd\xdf\x8f7\xdd\xd9P6\xda\x07\xc4D" +
-	"\x13\xc5\xe2\xce\xde\xda\xd6\xcf\xdc\x0d\xec,\x8b/\xff\xfb" +
-	"}\xff\xf9\xfe\x82(\x9a\x04Vg\xa6h\x97\xcaX\x98" +
-	"'X\xb4\\\xee\x07\xee\xbc\xae\x93\x83\xfa\xa1K{i" +
-	"\xfd4\xf8le\x94\x8d\xdd\xe03\xa0IJ\xac\x0d`" +
-	"\x08\xd8\x8be@\xce4e\xa8h\xc9\x98%^w\x00" +
-	"\xb9\xd2\x94\x07E\xabTL\x05\xd8\xbb\xf29\xd4\x94'" +
-	"E\xabuL\x0d\xd8\xc7M@n5\xe5M\xd1\x1a\x13" +
-	"\xd3\x00\xf6\xb5\x01\xc8\xb3\xa6|(\xd6z\xee\xc4\xb3\x02" +
-	"\xc5\x0aX\xa4.\x84A?KP\xdbv\xa1=\xe6Z" +
-	"p\xdd|<.\x8f\xfa\xdd\xc4g\x81U\xb0\xa99\xe2" +
-	"*\xb8\x9e\x1d\xb7\xda\xf9\x7f\xfd\x0b\x00\x00\xff\xff\x84\x94" +
-	":\x18"
+type AccessKey struct{ capnp.Struct }
+
+// AccessKey_TypeID is the unique identifier for the type AccessKey.
+const AccessKey_TypeID = 0xa175f6e2f6d566e2
+
+func NewAccessKey(s *capnp.Segment) (AccessKey, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return AccessKey{st}, err
+}
+
+func NewRootAccessKey(s *capnp.Segment) (AccessKey, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return AccessKey{st}, err
+}
+
+func ReadRootAccessKey(msg *capnp.Message) (AccessKey, error) {
+	root, err := msg.RootPtr()
+	return AccessKey{root.Struct()}, err
+}
+
+func (s AccessKey) String() string {
+	str, _ := text.Marshal(0xa175f6e2f6d566e2, s.Struct)
+	return str
+}
+
+func (s AccessKey) Id() (string, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.Text(), err
+}
+
+func (s AccessKey) HasId() bool {
+	p, err := s.Struct.Ptr(0)
+	return p.IsValid() || err != nil
+}
+
+func (s AccessKey) IdBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s AccessKey) SetId(v string) error {
+	return s.Struct.SetText(0, v)
+}
+
+func (s AccessKey) Secret() (string, error) {
+	p, err := s.Struct.Ptr(1)
+	return p.Text(), err
+}
+
+func (s AccessKey) HasSecret() bool {
+	p, err := s.Struct.Ptr(1)
+	return p.IsValid() || err != nil
+}
+
+func (s AccessKey) SecretBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(1)
+	return p.TextBytes(), err
+}
+
+func (s AccessKey) SetSecret(v string) error {
+	return s.Struct.SetText(1, v)
+}
+
+// AccessKey_List is a list of AccessKey.
+type AccessKey_List struct{ capnp.List }
+
+// NewAccessKey creates a new list of AccessKey.
+func NewAccessKey_List(s *capnp.Segment, sz int32) (AccessKey_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
+	return AccessKey_List{l}, err
+}
+
+func (s AccessKey_List) At(i int) AccessKey { return AccessKey{s.List.Struct(i)} }
+
+func (s AccessKey_List) Set(i int, v AccessKey) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s AccessKey_List) String() string {
+	str, _ := text.MarshalList(0xa175f6e2f6d566e2, s.List)
+	return str
+}
+
+// AccessKey_Promise is a wrapper for a AccessKey promised by a client call.
+type AccessKey_Promise struct{ *capnp.Pipeline }
+
+func (p AccessKey_Promise) Struct() (AccessKey, error) {
+	s, err := p.Pipeline.Struct()
+	return AccessKey{s}, err
+}
+
+type ShareLink struct{ capnp.Struct }
+
+// ShareLink_TypeID is the unique identifier for the type ShareLink.
+const ShareLink_TypeID = 0xa7aff471010eadee
+
+func NewShareLink(s *capnp.Segment) (ShareLink, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 8})
+	return ShareLink{st}, err
+}
+
+func NewRootShareLink(s *capnp.Segment) (ShareLink, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 8})
+	return ShareLink{st}, err
+}
+
+func ReadRootShareLink(msg *capnp.Message) (ShareLink, error) {
+	root, err := msg.RootPtr()
+	return ShareLink{root.Struct()}, err
+}
+
+func (s ShareLink) String() string {
+	str, _ := text.Marshal(0xa7aff471010eadee, s.Struct)
+	return str
+}
+
+func (s ShareLink) Token() (string, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.Text(), err
+}
+
+func (s ShareLink) HasToken() bool {
+	p, err := s.Struct.Ptr(0)
+	return p.IsValid() || err != nil
+}
+
+func (s ShareLink) TokenBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s ShareLink) SetToken(v string) error {
+	return s.Struct.SetText(0, v)
+}
+
+func (s ShareLink) Path() (string, error) {
+	p, err := s.Struct.Ptr(1)
+	return p.Text(), err
+}
+
+func (s ShareLink) HasPath() bool {
+	p, err := s.Struct.Ptr(1)
+	return p.IsValid() || err != nil
+}
+
+func (s ShareLink) PathBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(1)
+	return p.TextBytes(), err
+}
+
+func (s ShareLink) SetPath(v string) error {
+	return s.Struct.SetText(1, v)
+}
+
+func (s ShareLink) Rev() (string, error) {
+	p, err := s.Struct.Ptr(2)
+	return p.Text(), err
+}
+
+func (s ShareLink) HasRev() bool {
+	p, err := s.Struct.Ptr(2)
+	return p.IsValid() || err != nil
+}
+
+func (s ShareLink) RevBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(2)
+	return p.TextBytes(), err
+}
+
+func (s ShareLink) SetRev(v string) error {
+	return s.Struct.SetText(2, v)
+}
+
+func (s ShareLink) Owner() (string, error) {
+	p, err := s.Struct.Ptr(3)
+	return p.Text(), err
+}
+
+func (s ShareLink) HasOwner() bool {
+	p, err := s.Struct.Ptr(3)
+	return p.IsValid() || err != nil
+}
+
+func (s ShareLink) OwnerBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(3)
+	return p.TextBytes(), err
+}
+
+func (s ShareLink) SetOwner(v string) error {
+	return s.Struct.SetText(3, v)
+}
+
+func (s ShareLink) CreatedAt() (string, error) {
+	p, err := s.Struct.Ptr(4)
+	return p.Text(), err
+}
+
+func (s ShareLink) HasCreatedAt() bool {
+	p, err := s.Struct.Ptr(4)
+	return p.IsValid() || err != nil
+}
+
+func (s ShareLink) CreatedAtBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(4)
+	return p.TextBytes(), err
+}
+
+func (s ShareLink) SetCreatedAt(v string) error {
+	return s.Struct.SetText(4, v)
+}
+
+func (s ShareLink) ExpiresAt() (string, error) {
+	p, err := s.Struct.Ptr(5)
+	return p.Text(), err
+}
+
+func (s ShareLink) HasExpiresAt() bool {
+	p, err := s.Struct.Ptr(5)
+	return p.IsValid() || err != nil
+}
+
+func (s ShareLink) ExpiresAtBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(5)
+	return p.TextBytes(), err
+}
+
+func (s ShareLink) SetExpiresAt(v string) error {
+	return s.Struct.SetText(5, v)
+}
+
+func (s ShareLink) MaxDownloads() uint64 {
+	return s.Struct.Uint64(0)
+}
+
+func (s ShareLink) SetMaxDownloads(v uint64) {
+	s.Struct.SetUint64(0, v)
+}
+
+func (s ShareLink) Downloads() uint64 {
+	return s.Struct.Uint64(8)
+}
+
+func (s ShareLink) SetDownloads(v uint64) {
+	s.Struct.SetUint64(8, v)
+}
+
+func (s ShareLink) PasswordHash() (string, error) {
+	p, err := s.Struct.Ptr(6)
+	return p.Text(), err
+}
+
+func (s ShareLink) HasPasswordHash() bool {
+	p, err := s.Struct.Ptr(6)
+	return p.IsValid() || err != nil
+}
+
+func (s ShareLink) PasswordHashBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(6)
+	return p.TextBytes(), err
+}
+
+func (s ShareLink) SetPasswordHash(v string) error {
+	return s.Struct.SetText(6, v)
+}
+
+func (s ShareLink) Salt() (string, error) {
+	p, err := s.Struct.Ptr(7)
+	return p.Text(), err
+}
+
+func (s ShareLink) HasSalt() bool {
+	p, err := s.Struct.Ptr(7)
+	return p.IsValid() || err != nil
+}
+
+func (s ShareLink) SaltBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(7)
+	return p.TextBytes(), err
+}
+
+func (s ShareLink) SetSalt(v string) error {
+	return s.Struct.SetText(7, v)
+}
+
+// ShareLink_List is a list of ShareLink.
+type ShareLink_List struct{ capnp.List }
+
+// NewShareLink creates a new list of ShareLink.
+func NewShareLink_List(s *capnp.Segment, sz int32) (ShareLink_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 8}, sz)
+	return ShareLink_List{l}, err
+}
+
+func (s ShareLink_List) At(i int) ShareLink { return ShareLink{s.List.Struct(i)} }
+
+func (s ShareLink_List) Set(i int, v ShareLink) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s ShareLink_List) String() string {
+	str, _ := text.MarshalList(0xa7aff471010eadee, s.List)
+	return str
+}
+
+// ShareLink_Promise is a wrapper for a ShareLink promised by a client call.
+type ShareLink_Promise struct{ *capnp.Pipeline }
+
+func (p ShareLink_Promise) Struct() (ShareLink, error) {
+	s, err := p.Pipeline.Struct()
+	return ShareLink{s}, err
+}
+
+type ApiToken struct{ capnp.Struct }
+
+// ApiToken_TypeID is the unique identifier for the type ApiToken.
+const ApiToken_TypeID = 0xc1d198c749f3caac
+
+func NewApiToken(s *capnp.Segment) (ApiToken, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 6})
+	return ApiToken{st}, err
+}
+
+func NewRootApiToken(s *capnp.Segment) (ApiToken, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 6})
+	return ApiToken{st}, err
+}
+
+func ReadRootApiToken(msg *capnp.Message) (ApiToken, error) {
+	root, err := msg.RootPtr()
+	return ApiToken{root.Struct()}, err
+}
+
+func (s ApiToken) String() string {
+	str, _ := text.Marshal(0xc1d198c749f3caac, s.Struct)
+	return str
+}
+
+func (s ApiToken) Id() (string, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.Text(), err
+}
+
+func (s ApiToken) HasId() bool {
+	p, err := s.Struct.Ptr(0)
+	return p.IsValid() || err != nil
+}
+
+func (s ApiToken) IdBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s ApiToken) SetId(v string) error {
+	return s.Struct.SetText(0, v)
+}
+
+func (s ApiToken) Name() (string, error) {
+	p, err := s.Struct.Ptr(1)
+	return p.Text(), err
+}
+
+func (s ApiToken) HasName() bool {
+	p, err := s.Struct.Ptr(1)
+	return p.IsValid() || err != nil
+}
+
+func (s ApiToken) NameBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(1)
+	return p.TextBytes(), err
+}
+
+func (s ApiToken) SetName(v string) error {
+	return s.Struct.SetText(1, v)
+}
+
+func (s ApiToken) Hash() (string, error) {
+	p, err := s.Struct.Ptr(2)
+	return p.Text(), err
+}
+
+func (s ApiToken) HasHash() bool {
+	p, err := s.Struct.Ptr(2)
+	return p.IsValid() || err != nil
+}
+
+func (s ApiToken) HashBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(2)
+	return p.TextBytes(), err
+}
+
+func (s ApiToken) SetHash(v string) error {
+	return s.Struct.SetText(2, v)
+}
+
+func (s ApiToken) Rights() (capnp.TextList, error) {
+	p, err := s.Struct.Ptr(3)
+	return capnp.TextList{List: p.List()}, err
+}
+
+func (s ApiToken) HasRights() bool {
+	p, err := s.Struct.Ptr(3)
+	return p.IsValid() || err != nil
+}
+
+func (s ApiToken) SetRights(v capnp.TextList) error {
+	return s.Struct.SetPtr(3, v.List.ToPtr())
+}
+
+// NewRights sets the rights field to a newly
+// allocated capnp.TextList, preferring placement in s's segment.
+func (s ApiToken) NewRights(n int32) (capnp.TextList, error) {
+	l, err := capnp.NewTextList(s.Struct.Segment(), n)
+	if err != nil {
+		return capnp.TextList{}, err
+	}
+	err = s.Struct.SetPtr(3, l.List.ToPtr())
+	return l, err
+}
+
+func (s ApiToken) Folders() (capnp.TextList, error) {
+	p, err := s.Struct.Ptr(4)
+	return capnp.TextList{List: p.List()}, err
+}
+
+func (s ApiToken) HasFolders() bool {
+	p, err := s.Struct.Ptr(4)
+	return p.IsValid() || err != nil
+}
+
+func (s ApiToken) SetFolders(v capnp.TextList) error {
+	return s.Struct.SetPtr(4, v.List.ToPtr())
+}
+
+// NewFolders sets the folders field to a newly
+// allocated capnp.TextList, preferring placement in s's segment.
+func (s ApiToken) NewFolders(n int32) (capnp.TextList, error) {
+	l, err := capnp.NewTextList(s.Struct.Segment(), n)
+	if err != nil {
+		return capnp.TextList{}, err
+	}
+	err = s.Struct.SetPtr(4, l.List.ToPtr())
+	return l, err
+}
+
+func (s ApiToken) CreatedAt() (string, error) {
+	p, err := s.Struct.Ptr(5)
+	return p.Text(), err
+}
+
+func (s ApiToken) HasCreatedAt() bool {
+	p, err := s.Struct.Ptr(5)
+	return p.IsValid() || err != nil
+}
+
+func (s ApiToken) CreatedAtBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(5)
+	return p.TextBytes(), err
+}
+
+func (s ApiToken) SetCreatedAt(v string) error {
+	return s.Struct.SetText(5, v)
+}
+
+// ApiToken_List is a list of ApiToken.
+type ApiToken_List struct{ capnp.List }
+
+// NewApiToken creates a new list of ApiToken.
+func NewApiToken_List(s *capnp.Segment, sz int32) (ApiToken_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 6}, sz)
+	return ApiToken_List{l}, err
+}
+
+func (s ApiToken_List) At(i int) ApiToken { return ApiToken{s.List.Struct(i)} }
+
+func (s ApiToken_List) Set(i int, v ApiToken) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s ApiToken_List) String() string {
+	str, _ := text.MarshalList(0xc1d198c749f3caac, s.List)
+	return str
+}
+
+// ApiToken_Promise is a wrapper for a ApiToken promised by a client call.
+type ApiToken_Promise struct{ *capnp.Pipeline }
+
+func (p ApiToken_Promise) Struct() (ApiToken, error) {
+	s, err := p.Pipeline.Struct()
+	return ApiToken{s}, err
+}
+
+const schema_a0b1c18bd0f965c4 = "x\xda\x8c\x93Oh\x1ce\x18\xc6\x9f\xe7\xfbv\xf6\x8f" +
+	"\xc4l\x86\x19!\xca\xca\xd6\xd2C\x1a\xd4$\xb5\xa0\x96" +
+	"H\x8d\x14i\xfd\x03\xfd\xba\x15\xa1\xb7q\xe7kv\xdb" +
+	"tw\x9d\xd9\xba\xdd\x93'\x05\xf1\xe0\xc5C\x0b\x0a)" +
+	"XTPTP\xb0\xd0\x83\x81\x8a\"\x0a\x15\x14\x14\xbc" +
+	"Xz\xb4\xa2(\x05O#\xef\x98\x9d\x99\x84Z\xbd}" +
+	"\xfb\xdbg\xe6\x9d\xef}\x9eg\xf1\x1a\x1fUK\xce*" +
+	"\x013\xe3\x94\x93\x9dO\x1c[~\xfc\xda\xdd/\xc3m" +
+	"0\xb9l\xff\xba\xf2\xea\xc6G\xebp*\x15`\xe9\xdc" +
+	"mt\xdf\x95\xc3\x85\xd7\x08&W\x8f\x7f\x7f\xe3\xea\x8d" +
+	"\xd3\xe7\xb7IU\x05x`\xac\xf6\xd0{E\x8e\xdeK" +
+	"j\x04&\xbf\xbe?\xcd\xe7\xff\xfc\xe0m\x98\x06UA" +
+	"]\x15\xf5\xcf\xa2\xfe=}\xf0\xbazP\x81\xc9{_" +
+	"\xfdq\xe8\x8b\xb3\xdfnl{wY$\x8f8\xf3\xf4" +
+	"\x9ev\xe4x\xc8yV\xbed5\x18\xdaQ0^\xd0" +
+	"\xe1s\x0b\xed`\xd0\x1b,\x9c\x8emt\x7fz\xdc\xf7" +
+	"Ll#\xe00i\x1a\xba\x04\x94\x08\xb8\x9f\xcc\x03\xe6" +
+	"CMsI\xd1%}\x0a\xbcx\x020\x9fj\x9a\xcb" +
+	"\x8a\xaeR>\x15\xe0n\x88\xf2\x92\xa6\xf9R\xd1\xd5\xda" +
+	"\xa7\x06\xdc\xcf\x1f\x03\xccg\x9a\xe6'E\xb7T\xf2Y" +
+	"\x02\xdc\x1f\xf7\x01\xe6;M\xf3\x9b\xa2\xeb8>\x1d\xc0" +
+	"\xbd~\x0c0\xbfh\xb6\xaaTt\xcbe\x9fe\xc0s" +
+	"x\x04h\x95\xa8\xd9\xdaA\xc5z/8e9\x05\xc5" +
+	")0\x19\x04q<\xeaG!\xea\x07\x83\xb83\xc1\xf5" +
+	"8X\x1bN~\xbcx\xbc\xbf\x16\xda(\xe64xX" +
+	"3\xc5\xd3\xe0\xfe\xa8\xbb\xda\x19n\xa7I\xd0n\xdb8" +
+	"~\xd2B\x8f\xb3\xfffr\xf7\xc0\x7fT\x83\xee\xd1\xfe" +
+	"I\xdb\x03\x0b\xa2\xcc\x86M\xd1-\x17\xbd\xd2n\xdb\xa6" +
+	"\x0c\x1a\xcb\xb2\xab\xd9\xb2w\xdf\x05\x98]\x9af\xb1\xb0" +
+	"\xec\xfbd[s\x9af\xaf\xa2\xee\x86\x93\x9b\xed\x8fm" +
+	";\xb2\xd9Eo=\xb0\xd5\x09\xa2\xa6}\xaa\xdb;)" +
+	"\x03\xe7&\x03\xbd\x1a\xf7L\xd6;\xc3|\xa6w;\xe7" +
+	"\x81VU\xb8\xcf\xdcc\xcf\xe5N\xa05%|\x96\xb9" +
+	"\xcd\xde\x1d\xe9{f\x847\x98;\xed\xdd\x99\xda7+" +
+	"|\x17s\xb3\xbd{R\xbeC\xf8\xbdT\xe4\xa6\xdb\xbb" +
+	"y\x02h\xcd\x09\xde+\xf2\x0a}J1\x96R\xf9\xa2" +
+	"\xf0e\xe1\xd5\xb2\xcf*\xe0=\x9c\xea\x1f\x12~@x" +
+	"\xad\xe2\xb3\x06x+\xe9\xe7/\x0b?H\xc5\xe6P\xec" +
+	"\xca\xf21\x08\x86YX*\x91}arn\xf6G=" +
+	"\x1be\x0bmG6\x18\xdap\x05\xcc\x97l\xcf\x0c\xba" +
+	"\x91\x8d\xb7\xb0S\xc1\x99\x03\xfdQo\x0d\xf5~\x10\xc6" +
+	"\xacA\xb1\x06&\xa1\xb0~\x10\x829\xfb\x1f\x81\xfd\x8f" +
+	"\xe0\x0c\xbaG\xebr\x19\xb1q6\xcb\xcd9\xc9\xcd\xeb" +
+	"\x9af\xbd\x90\x9b7\xa5\x8fg5\xcd[\x85\x92\x9e\x17" +
+	"\xf8\x86\xa6y\xa7P\xd2\x0b\x92\xb0\xf5\xcd\x8eOJz" +
+	"Q\x9a\xfb\xb1\xa6\xb9R(\xe97G\x00\xf3\xb5\xa6\xf9" +
+	"aK\x16\xb7\xd4\xb2\xde)\xdc\xed\xe6E\xfb\x97V\xde" +
+	"l\xe5\x7f\x0f\x00MB\x1b;"
 
 func init() {
 	schemas.Register(schema_a0b1c18bd0f965c4,
-		0x861de4463c5a4a22)
+		0x861de4463c5a4a22,
+		0xa175f6e2f6d566e2,
+		0xa7aff471010eadee,
+		0xc1d198c749f3caac)
 }
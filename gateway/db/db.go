@@ -1,10 +1,17 @@
 package db
 
 import (
+	"bytes"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/base32"
 	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"path"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -54,6 +61,26 @@ var (
 	}
 )
 
+var (
+	// ErrNoSuchAccessKey is returned when an access key is not known.
+	ErrNoSuchAccessKey = errors.New("no such access key")
+
+	// ErrNoSuchAPIToken is returned when an api token is not known
+	// or its secret does not match.
+	ErrNoSuchAPIToken = errors.New("no such api token")
+
+	// ErrNoSuchShare is returned when a share link token is not known.
+	ErrNoSuchShare = errors.New("no such share link")
+
+	// ErrShareExpired is returned when a share link expired or
+	// its download limit was reached.
+	ErrShareExpired = errors.New("share link expired")
+)
+
+// Share links live in the same database as the users.
+// User names may not contain a null byte, so they can't clash.
+const sharePrefix = "\x00share:"
+
 // UserDatabase is a badger db that stores user information,
 // using the user name as unique key.
 type UserDatabase struct {
@@ -175,15 +202,167 @@ func UserFromCapnp(capUser capnp.User) (*User, error) {
 		return nil, err
 	}
 
+	capKeys, err := capUser.AccessKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []AccessKey{}
+	for idx := 0; idx < capKeys.Len(); idx++ {
+		capKey := capKeys.At(idx)
+		id, err := capKey.Id()
+		if err != nil {
+			return nil, err
+		}
+
+		secret, err := capKey.Secret()
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, AccessKey{ID: id, Secret: secret})
+	}
+
+	capTokens, err := capUser.ApiTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := []APIToken{}
+	for idx := 0; idx < capTokens.Len(); idx++ {
+		token, err := apiTokenFromCapnp(capTokens.At(idx))
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, *token)
+	}
+
 	return &User{
 		Name:         name,
 		PasswordHash: passwordHash,
 		Salt:         salt,
 		Folders:      folders,
 		Rights:       rights,
+		AccessKeys:   keys,
+		APITokens:    tokens,
+	}, nil
+}
+
+func readTextList(list capnp_lib.TextList, err error) ([]string, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	result := []string{}
+	for idx := 0; idx < list.Len(); idx++ {
+		elem, err := list.At(idx)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, elem)
+	}
+
+	return result, nil
+}
+
+func newTextList(seg *capnp_lib.Segment, elems []string) (capnp_lib.TextList, error) {
+	list, err := capnp_lib.NewTextList(seg, int32(len(elems)))
+	if err != nil {
+		return capnp_lib.TextList{}, err
+	}
+
+	for idx, elem := range elems {
+		if err := list.Set(idx, elem); err != nil {
+			return capnp_lib.TextList{}, err
+		}
+	}
+
+	return list, nil
+}
+
+func apiTokenFromCapnp(capToken capnp.ApiToken) (*APIToken, error) {
+	id, err := capToken.Id()
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := capToken.Name()
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := capToken.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	rights, err := readTextList(capToken.Rights())
+	if err != nil {
+		return nil, err
+	}
+
+	folders, err := readTextList(capToken.Folders())
+	if err != nil {
+		return nil, err
+	}
+
+	createdAtStr, err := capToken.CreatedAt()
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt, err := parseTimestamp(createdAtStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &APIToken{
+		ID:        id,
+		Name:      name,
+		Hash:      hash,
+		Rights:    rights,
+		Folders:   folders,
+		CreatedAt: createdAt,
 	}, nil
 }
 
+func apiTokenToCapnp(token *APIToken, capToken capnp.ApiToken) error {
+	seg := capToken.Segment()
+	if err := capToken.SetId(token.ID); err != nil {
+		return err
+	}
+
+	if err := capToken.SetName(token.Name); err != nil {
+		return err
+	}
+
+	if err := capToken.SetHash(token.Hash); err != nil {
+		return err
+	}
+
+	capRights, err := newTextList(seg, token.Rights)
+	if err != nil {
+		return err
+	}
+
+	if err := capToken.SetRights(capRights); err != nil {
+		return err
+	}
+
+	capFolders, err := newTextList(seg, token.Folders)
+	if err != nil {
+		return err
+	}
+
+	if err := capToken.SetFolders(capFolders); err != nil {
+		return err
+	}
+
+	return capToken.SetCreatedAt(formatTimestamp(token.CreatedAt))
+}
+
 func marshalUser(user *User) ([]byte, error) {
 	msg, seg, err := capnp_lib.NewMessage(capnp_lib.SingleSegment(nil))
 	if err != nil {
@@ -246,6 +425,41 @@ func UserToCapnp(user *User, seg *capnp_lib.Segment) (*capnp.User, error) {
 		return nil, err
 	}
 
+	capKeys, err := capnp.NewAccessKey_List(seg, int32(len(user.AccessKeys)))
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, key := range user.AccessKeys {
+		capKey := capKeys.At(idx)
+		if err := capKey.SetId(key.ID); err != nil {
+			return nil, err
+		}
+
+		if err := capKey.SetSecret(key.Secret); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := capUser.SetAccessKeys(capKeys); err != nil {
+		return nil, err
+	}
+
+	capTokens, err := capnp.NewApiToken_List(seg, int32(len(user.APITokens)))
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range user.APITokens {
+		if err := apiTokenToCapnp(&user.APITokens[idx], capTokens.At(idx)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := capUser.SetApiTokens(capTokens); err != nil {
+		return nil, err
+	}
+
 	return &capUser, nil
 }
 
@@ -257,16 +471,119 @@ type User struct {
 	Salt         string
 	Folders      []string
 	Rights       []string
+	AccessKeys   []AccessKey
+	APITokens    []APIToken
+}
+
+// AccessKey is a key pair that can be used instead of name and password,
+// for example by S3 clients. Unlike the password, the secret has to be
+// stored as-is, since request signatures are computed from it.
+type AccessKey struct {
+	ID     string
+	Secret string
+}
+
+// APIToken is a personal token that can be sent as "Authorization: Bearer"
+// header instead of logging in. It can be restricted to some of the rights
+// and folders of its user. Only a hash of its secret is stored.
+type APIToken struct {
+	ID   string
+	Name string
+	Hash string
+
+	// Rights and Folders are empty if the token
+	// may do everything its user may do.
+	Rights  []string
+	Folders []string
+
+	CreatedAt time.Time
+}
+
+// The secret is long and random, so unlike passwords
+// it does not need a slow hash function. This matters, since
+// the token is checked on every request.
+func hashAPISecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func (t APIToken) checkSecret(secret string) bool {
+	return subtle.ConstantTimeCompare(
+		[]byte(t.Hash),
+		[]byte(hashAPISecret(secret)),
+	) == 1
+}
+
+// Restrict returns a copy of `user` that has only the rights
+// and folders that both the user and the token have.
+func (t APIToken) Restrict(user User) User {
+	if len(t.Rights) > 0 {
+		hasRight := make(map[string]bool)
+		for _, right := range user.Rights {
+			hasRight[right] = true
+		}
+
+		rights := []string{}
+		for _, right := range t.Rights {
+			if hasRight[right] {
+				rights = append(rights, right)
+			}
+		}
+
+		user.Rights = rights
+	}
+
+	if len(t.Folders) > 0 {
+		user.Folders = intersectFolders(user.Folders, t.Folders)
+	}
+
+	return user
+}
+
+// isBelowFolder returns true if `nodePath` is `folder` or inside of it.
+func isBelowFolder(nodePath, folder string) bool {
+	nodePath = path.Clean("/" + nodePath)
+	folder = path.Clean("/" + folder)
+	return folder == "/" || nodePath == folder || strings.HasPrefix(nodePath, folder+"/")
+}
+
+// intersectFolders returns the folders that are covered by both `a` and `b`.
+func intersectFolders(a, b []string) []string {
+	seen := make(map[string]bool)
+	folders := []string{}
+	add := func(folder string) {
+		if !seen[folder] {
+			seen[folder] = true
+			folders = append(folders, folder)
+		}
+	}
+
+	for _, folderA := range a {
+		for _, folderB := range b {
+			switch {
+			case isBelowFolder(folderA, folderB):
+				add(folderA)
+			case isBelowFolder(folderB, folderA):
+				add(folderB)
+			}
+		}
+	}
+
+	return folders
 }
 
 // CheckPassword checks if `password` matches the stored one.
 func (u User) CheckPassword(password string) (bool, error) {
-	salt, err := base64.StdEncoding.DecodeString(u.Salt)
+	return checkPassword(password, u.PasswordHash, u.Salt)
+}
+
+func checkPassword(password, passwordHash, encSalt string) (bool, error) {
+	salt, err := base64.StdEncoding.DecodeString(encSalt)
 	if err != nil {
 		return false, err
 	}
 
-	oldHash, err := base64.StdEncoding.DecodeString(u.PasswordHash)
+	oldHash, err := base64.StdEncoding.DecodeString(passwordHash)
 	if err != nil {
 		return false, err
 	}
@@ -292,12 +609,41 @@ func HashPassword(password string) (string, string, error) {
 	return encode(hash), encode(salt), nil
 }
 
+func randomString(size int, encode func([]byte) string) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return encode(buf), nil
+}
+
+// NewAccessKey generates a new random access key.
+// The id has 20 and the secret 40 characters, like the ones of AWS.
+func NewAccessKey() (AccessKey, error) {
+	id, err := randomString(12, base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString)
+	if err != nil {
+		return AccessKey{}, err
+	}
+
+	secret, err := randomString(30, base64.StdEncoding.EncodeToString)
+	if err != nil {
+		return AccessKey{}, err
+	}
+
+	return AccessKey{ID: "BRIG" + id[:16], Secret: secret}, nil
+}
+
 // Add adds a new user to the database.
 // If the user exists already, it is overwritten.
 func (ub *UserDatabase) Add(name, password string, folders []string, rights []string) error {
 	ub.mu.Lock()
 	defer ub.mu.Unlock()
 
+	if name == "" || strings.ContainsRune(name, 0) {
+		return fmt.Errorf("invalid user name: %q", name)
+	}
+
 	if len(folders) == 0 {
 		folders = []string{"/"}
 	}
@@ -335,6 +681,30 @@ func (ub *UserDatabase) Add(name, password string, folders []string, rights []st
 	})
 }
 
+func getUser(txn *badger.Txn, name string) (*User, error) {
+	item, err := txn.Get([]byte(name))
+	if err != nil {
+		return nil, err
+	}
+
+	var user *User
+	err = item.Value(func(data []byte) error {
+		user, err = unmarshalUser(data)
+		return err
+	})
+
+	return user, err
+}
+
+func putUser(txn *badger.Txn, user *User) error {
+	data, err := marshalUser(user)
+	if err != nil {
+		return err
+	}
+
+	return txn.Set([]byte(user.Name), data)
+}
+
 // Get returns a User, if it exists. If it does not exist,
 // an error will be returned.
 func (ub *UserDatabase) Get(name string) (User, error) {
@@ -343,20 +713,13 @@ func (ub *UserDatabase) Get(name string) (User, error) {
 
 	user := User{}
 	return user, ub.db.View(func(txn *badger.Txn) error {
-		item, err := txn.Get([]byte(name))
+		decUser, err := getUser(txn, name)
 		if err != nil {
 			return err
 		}
 
-		return item.Value(func(data []byte) error {
-			decUser, err := unmarshalUser(data)
-			if err != nil {
-				return err
-			}
-
-			user = *decUser
-			return nil
-		})
+		user = *decUser
+		return nil
 	})
 }
 
@@ -386,6 +749,10 @@ func (ub *UserDatabase) List() ([]User, error) {
 		defer iter.Close()
 
 		for iter.Rewind(); iter.Valid(); iter.Next() {
+			if bytes.HasPrefix(iter.Item().Key(), []byte(sharePrefix)) {
+				continue
+			}
+
 			err := iter.Item().Value(func(data []byte) error {
 				user, err := unmarshalUser(data)
 				if err != nil {
@@ -404,3 +771,541 @@ func (ub *UserDatabase) List() ([]User, error) {
 		return nil
 	})
 }
+
+// AddAccessKey creates a new access key for the user `name`.
+func (ub *UserDatabase) AddAccessKey(name string) (AccessKey, error) {
+	ub.mu.Lock()
+	defer ub.mu.Unlock()
+
+	key, err := NewAccessKey()
+	if err != nil {
+		return AccessKey{}, err
+	}
+
+	return key, ub.db.Update(func(txn *badger.Txn) error {
+		user, err := getUser(txn, name)
+		if err != nil {
+			return err
+		}
+
+		user.AccessKeys = append(user.AccessKeys, key)
+		return putUser(txn, user)
+	})
+}
+
+// RemoveAccessKey removes the access key with `id` from the user `name`.
+func (ub *UserDatabase) RemoveAccessKey(name, id string) error {
+	ub.mu.Lock()
+	defer ub.mu.Unlock()
+
+	return ub.db.Update(func(txn *badger.Txn) error {
+		user, err := getUser(txn, name)
+		if err != nil {
+			return err
+		}
+
+		keys := []AccessKey{}
+		for _, key := range user.AccessKeys {
+			if key.ID != id {
+				keys = append(keys, key)
+			}
+		}
+
+		if len(keys) == len(user.AccessKeys) {
+			return ErrNoSuchAccessKey
+		}
+
+		user.AccessKeys = keys
+		return putUser(txn, user)
+	})
+}
+
+// UserByAccessKey returns the user that owns the access key `id`,
+// together with the key itself.
+func (ub *UserDatabase) UserByAccessKey(id string) (User, AccessKey, error) {
+	users, err := ub.List()
+	if err != nil {
+		return User{}, AccessKey{}, err
+	}
+
+	for _, user := range users {
+		for _, key := range user.AccessKeys {
+			if key.ID == id {
+				return user, key, nil
+			}
+		}
+	}
+
+	return User{}, AccessKey{}, ErrNoSuchAccessKey
+}
+
+// AddAPIToken creates a new api token for the user `name`. The token may use
+// only `rights` and `folders`; if they are empty, those of the user are used.
+// The returned string is the token that has to be sent by the client.
+// It can not be recovered later, since only a hash of it is stored.
+func (ub *UserDatabase) AddAPIToken(name, tokenName string, rights, folders []string) (string, APIToken, error) {
+	ub.mu.Lock()
+	defer ub.mu.Unlock()
+
+	for _, right := range rights {
+		if !AllRights[right] {
+			return "", APIToken{}, fmt.Errorf("invalid right: %s", right)
+		}
+	}
+
+	cleanFolders := []string{}
+	for _, folder := range folders {
+		cleanFolders = append(cleanFolders, path.Clean("/"+folder))
+	}
+
+	id, err := randomString(8, hex.EncodeToString)
+	if err != nil {
+		return "", APIToken{}, err
+	}
+
+	secret, err := randomString(24, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return "", APIToken{}, err
+	}
+
+	token := APIToken{
+		ID:        id,
+		Name:      tokenName,
+		Hash:      hashAPISecret(secret),
+		Rights:    rights,
+		Folders:   cleanFolders,
+		CreatedAt: time.Now(),
+	}
+
+	err = ub.db.Update(func(txn *badger.Txn) error {
+		user, err := getUser(txn, name)
+		if err != nil {
+			return err
+		}
+
+		user.APITokens = append(user.APITokens, token)
+		return putUser(txn, user)
+	})
+
+	if err != nil {
+		return "", APIToken{}, err
+	}
+
+	return id + "." + secret, token, nil
+}
+
+// RemoveAPIToken removes the api token with `id` from the user `name`.
+func (ub *UserDatabase) RemoveAPIToken(name, id string) error {
+	ub.mu.Lock()
+	defer ub.mu.Unlock()
+
+	return ub.db.Update(func(txn *badger.Txn) error {
+		user, err := getUser(txn, name)
+		if err != nil {
+			return err
+		}
+
+		tokens := []APIToken{}
+		for _, token := range user.APITokens {
+			if token.ID != id {
+				tokens = append(tokens, token)
+			}
+		}
+
+		if len(tokens) == len(user.APITokens) {
+			return ErrNoSuchAPIToken
+		}
+
+		user.APITokens = tokens
+		return putUser(txn, user)
+	})
+}
+
+// UserByAPIToken returns the user that owns `token`, as returned by
+// AddAPIToken. The rights and folders of the user are restricted
+// to the ones of the token.
+func (ub *UserDatabase) UserByAPIToken(token string) (User, error) {
+	split := strings.SplitN(token, ".", 2)
+	if len(split) != 2 {
+		return User{}, ErrNoSuchAPIToken
+	}
+
+	users, err := ub.List()
+	if err != nil {
+		return User{}, err
+	}
+
+	id, secret := split[0], split[1]
+	for _, user := range users {
+		for _, apiToken := range user.APITokens {
+			if apiToken.ID != id {
+				continue
+			}
+
+			if !apiToken.checkSecret(secret) {
+				return User{}, ErrNoSuchAPIToken
+			}
+
+			return apiToken.Restrict(user), nil
+		}
+	}
+
+	return User{}, ErrNoSuchAPIToken
+}
+
+// ShareLink is a public link to a file or directory. Everyone who knows
+// the token (and the password, if any) can download it without logging in.
+type ShareLink struct {
+	Token string
+	Path  string
+
+	// Rev is the commit the link points to.
+	// If empty, the current state is shared.
+	Rev string
+
+	// Owner is the gateway user that created the link.
+	// It is empty for links created via the command line.
+	Owner string
+
+	CreatedAt time.Time
+
+	// ExpiresAt is zero for links that do not expire.
+	ExpiresAt time.Time
+
+	// MaxDownloads is zero for links that can be downloaded any number of times.
+	MaxDownloads uint64
+	Downloads    uint64
+
+	// PasswordHash and Salt are empty for links without password.
+	PasswordHash string
+	Salt         string
+}
+
+// HasPassword returns true if the link is protected by a password.
+func (sl ShareLink) HasPassword() bool {
+	return sl.PasswordHash != ""
+}
+
+// CheckPassword checks if `password` matches the one of the link.
+func (sl ShareLink) CheckPassword(password string) (bool, error) {
+	if !sl.HasPassword() {
+		return true, nil
+	}
+
+	return checkPassword(password, sl.PasswordHash, sl.Salt)
+}
+
+// IsExpired returns true if the link expired at `now`
+// or if it was downloaded too often already.
+func (sl ShareLink) IsExpired(now time.Time) bool {
+	if !sl.ExpiresAt.IsZero() && !now.Before(sl.ExpiresAt) {
+		return true
+	}
+
+	return sl.MaxDownloads > 0 && sl.Downloads >= sl.MaxDownloads
+}
+
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseTimestamp(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, s)
+}
+
+// ShareLinkFromCapnp converts a capnp.ShareLink to a ShareLink.
+func ShareLinkFromCapnp(capLink capnp.ShareLink) (*ShareLink, error) {
+	token, err := capLink.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := capLink.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	rev, err := capLink.Rev()
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := capLink.Owner()
+	if err != nil {
+		return nil, err
+	}
+
+	createdAtStr, err := capLink.CreatedAt()
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt, err := parseTimestamp(createdAtStr)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAtStr, err := capLink.ExpiresAt()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt, err := parseTimestamp(expiresAtStr)
+	if err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := capLink.PasswordHash()
+	if err != nil {
+		return nil, err
+	}
+
+	salt, err := capLink.Salt()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ShareLink{
+		Token:        token,
+		Path:         path,
+		Rev:          rev,
+		Owner:        owner,
+		CreatedAt:    createdAt,
+		ExpiresAt:    expiresAt,
+		MaxDownloads: capLink.MaxDownloads(),
+		Downloads:    capLink.Downloads(),
+		PasswordHash: passwordHash,
+		Salt:         salt,
+	}, nil
+}
+
+// ShareLinkToCapnp converts a ShareLink to a capnp.ShareLink.
+func ShareLinkToCapnp(link *ShareLink, seg *capnp_lib.Segment) (*capnp.ShareLink, error) {
+	capLink, err := capnp.NewRootShareLink(seg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := capLink.SetToken(link.Token); err != nil {
+		return nil, err
+	}
+
+	if err := capLink.SetPath(link.Path); err != nil {
+		return nil, err
+	}
+
+	if err := capLink.SetRev(link.Rev); err != nil {
+		return nil, err
+	}
+
+	if err := capLink.SetOwner(link.Owner); err != nil {
+		return nil, err
+	}
+
+	if err := capLink.SetCreatedAt(formatTimestamp(link.CreatedAt)); err != nil {
+		return nil, err
+	}
+
+	if err := capLink.SetExpiresAt(formatTimestamp(link.ExpiresAt)); err != nil {
+		return nil, err
+	}
+
+	if err := capLink.SetPasswordHash(link.PasswordHash); err != nil {
+		return nil, err
+	}
+
+	if err := capLink.SetSalt(link.Salt); err != nil {
+		return nil, err
+	}
+
+	capLink.SetMaxDownloads(link.MaxDownloads)
+	capLink.SetDownloads(link.Downloads)
+	return &capLink, nil
+}
+
+func unmarshalShareLink(data []byte) (*ShareLink, error) {
+	msg, err := capnp_lib.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	capLink, err := capnp.ReadRootShareLink(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return ShareLinkFromCapnp(capLink)
+}
+
+func marshalShareLink(link *ShareLink) ([]byte, error) {
+	msg, seg, err := capnp_lib.NewMessage(capnp_lib.SingleSegment(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := ShareLinkToCapnp(link, seg); err != nil {
+		return nil, err
+	}
+
+	return msg.Marshal()
+}
+
+func shareKey(token string) []byte {
+	return []byte(sharePrefix + token)
+}
+
+func getShare(txn *badger.Txn, token string) (*ShareLink, error) {
+	item, err := txn.Get(shareKey(token))
+	if err != nil {
+		if err == badger.ErrKeyNotFound {
+			return nil, ErrNoSuchShare
+		}
+
+		return nil, err
+	}
+
+	var link *ShareLink
+	err = item.Value(func(data []byte) error {
+		link, err = unmarshalShareLink(data)
+		return err
+	})
+
+	return link, err
+}
+
+func putShare(txn *badger.Txn, link *ShareLink) error {
+	data, err := marshalShareLink(link)
+	if err != nil {
+		return err
+	}
+
+	return txn.Set(shareKey(link.Token), data)
+}
+
+// AddShare stores a new share link. Token and creation time are
+// generated; `password` is hashed if it is not empty.
+func (ub *UserDatabase) AddShare(link ShareLink, password string) (ShareLink, error) {
+	ub.mu.Lock()
+	defer ub.mu.Unlock()
+
+	token, err := randomString(18, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return ShareLink{}, err
+	}
+
+	link.Token = token
+	link.CreatedAt = time.Now()
+	link.Downloads = 0
+	link.PasswordHash, link.Salt = "", ""
+
+	if password != "" {
+		link.PasswordHash, link.Salt, err = HashPassword(password)
+		if err != nil {
+			return ShareLink{}, err
+		}
+	}
+
+	return link, ub.db.Update(func(txn *badger.Txn) error {
+		return putShare(txn, &link)
+	})
+}
+
+// GetShare returns the share link with `token`.
+func (ub *UserDatabase) GetShare(token string) (ShareLink, error) {
+	ub.mu.Lock()
+	defer ub.mu.Unlock()
+
+	link := ShareLink{}
+	err := ub.db.View(func(txn *badger.Txn) error {
+		decLink, err := getShare(txn, token)
+		if err != nil {
+			return err
+		}
+
+		link = *decLink
+		return nil
+	})
+
+	return link, err
+}
+
+// UseShare counts a download of the share link with `token`.
+// ErrShareExpired is returned if the link may not be used anymore.
+func (ub *UserDatabase) UseShare(token string, now time.Time) (ShareLink, error) {
+	ub.mu.Lock()
+	defer ub.mu.Unlock()
+
+	link := ShareLink{}
+	err := ub.db.Update(func(txn *badger.Txn) error {
+		decLink, err := getShare(txn, token)
+		if err != nil {
+			return err
+		}
+
+		if decLink.IsExpired(now) {
+			return ErrShareExpired
+		}
+
+		decLink.Downloads++
+		link = *decLink
+		return putShare(txn, decLink)
+	})
+
+	return link, err
+}
+
+// RemoveShare revokes the share link with `token`.
+func (ub *UserDatabase) RemoveShare(token string) error {
+	ub.mu.Lock()
+	defer ub.mu.Unlock()
+
+	return ub.db.Update(func(txn *badger.Txn) error {
+		if _, err := getShare(txn, token); err != nil {
+			return err
+		}
+
+		return txn.Delete(shareKey(token))
+	})
+}
+
+// ListShares returns all share links, including expired ones.
+func (ub *UserDatabase) ListShares() ([]ShareLink, error) {
+	ub.mu.Lock()
+	defer ub.mu.Unlock()
+
+	links := []ShareLink{}
+	err := ub.db.View(func(txn *badger.Txn) error {
+		iter := txn.NewIterator(badger.IteratorOptions{
+			Prefix: []byte(sharePrefix),
+		})
+		defer iter.Close()
+
+		for iter.Rewind(); iter.Valid(); iter.Next() {
+			err := iter.Item().Value(func(data []byte) error {
+				link, err := unmarshalShareLink(data)
+				if err != nil {
+					return err
+				}
+
+				links = append(links, *link)
+				return nil
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return links, err
+}
@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -33,3 +34,153 @@ func TestAddGet(t *testing.T) {
 		require.Equal(t, []string{"fs.view"}, user.Rights)
 	})
 }
+
+func TestAccessKeys(t *testing.T) {
+	withDummyDb(t, func(db *UserDatabase) {
+		require.Nil(t, db.Add("hello", "world", []string{"/"}, []string{"fs.view"}))
+		require.Nil(t, db.Add("other", "world", []string{"/"}, []string{"fs.view"}))
+
+		key, err := db.AddAccessKey("hello")
+		require.Nil(t, err)
+		require.Len(t, key.ID, 20)
+		require.Len(t, key.Secret, 40)
+
+		_, err = db.AddAccessKey("nobody")
+		require.NotNil(t, err)
+
+		user, err := db.Get("hello")
+		require.Nil(t, err)
+		require.Equal(t, []AccessKey{key}, user.AccessKeys)
+
+		user, foundKey, err := db.UserByAccessKey(key.ID)
+		require.Nil(t, err)
+		require.Equal(t, "hello", user.Name)
+		require.Equal(t, key, foundKey)
+
+		require.Equal(t, ErrNoSuchAccessKey, db.RemoveAccessKey("other", key.ID))
+		require.Nil(t, db.RemoveAccessKey("hello", key.ID))
+
+		_, _, err = db.UserByAccessKey(key.ID)
+		require.Equal(t, ErrNoSuchAccessKey, err)
+	})
+}
+
+func TestAPITokens(t *testing.T) {
+	withDummyDb(t, func(db *UserDatabase) {
+		require.Nil(t, db.Add("hello", "world", []string{"/public", "/photos"}, []string{"fs.view", "fs.edit"}))
+
+		secret, token, err := db.AddAPIToken("hello", "ci", []string{"fs.view", "fs.download"}, []string{"/public/ci"})
+		require.Nil(t, err)
+		require.Equal(t, token.ID, secret[:len(token.ID)])
+		require.NotContains(t, token.Hash, secret[len(token.ID)+1:])
+
+		_, _, err = db.AddAPIToken("hello", "bad", []string{"fs.fly"}, nil)
+		require.NotNil(t, err)
+
+		_, _, err = db.AddAPIToken("nobody", "ci", nil, nil)
+		require.NotNil(t, err)
+
+		user, err := db.Get("hello")
+		require.Nil(t, err)
+		require.Len(t, user.APITokens, 1)
+		require.Equal(t, token.ID, user.APITokens[0].ID)
+		require.Equal(t, "ci", user.APITokens[0].Name)
+		require.Equal(t, token.Hash, user.APITokens[0].Hash)
+
+		// The token may only do what both token and user may do:
+		user, err = db.UserByAPIToken(secret)
+		require.Nil(t, err)
+		require.Equal(t, "hello", user.Name)
+		require.Equal(t, []string{"fs.view"}, user.Rights)
+		require.Equal(t, []string{"/public/ci"}, user.Folders)
+
+		_, err = db.UserByAPIToken(token.ID + ".wrong")
+		require.Equal(t, ErrNoSuchAPIToken, err)
+
+		_, err = db.UserByAPIToken(token.ID)
+		require.Equal(t, ErrNoSuchAPIToken, err)
+
+		// Without restrictions, the token has all rights of the user:
+		allSecret, _, err := db.AddAPIToken("hello", "all", nil, nil)
+		require.Nil(t, err)
+
+		user, err = db.UserByAPIToken(allSecret)
+		require.Nil(t, err)
+		require.Equal(t, []string{"fs.view", "fs.edit"}, user.Rights)
+		require.Equal(t, []string{"/public", "/photos"}, user.Folders)
+
+		require.Equal(t, ErrNoSuchAPIToken, db.RemoveAPIToken("hello", "nope"))
+		require.Nil(t, db.RemoveAPIToken("hello", token.ID))
+
+		_, err = db.UserByAPIToken(secret)
+		require.Equal(t, ErrNoSuchAPIToken, err)
+	})
+}
+
+func TestIntersectFolders(t *testing.T) {
+	require.Equal(t, []string{"/a/b"}, intersectFolders([]string{"/a"}, []string{"/a/b"}))
+	require.Equal(t, []string{"/a/b"}, intersectFolders([]string{"/a/b"}, []string{"/"}))
+	require.Equal(t, []string{}, intersectFolders([]string{"/a"}, []string{"/ab"}))
+	require.Equal(t, []string{"/a", "/b/c"}, intersectFolders([]string{"/a", "/b/c"}, []string{"/a", "/b"}))
+}
+
+func TestShareLinks(t *testing.T) {
+	withDummyDb(t, func(db *UserDatabase) {
+		require.Nil(t, db.Add("hello", "world", []string{"/"}, []string{"fs.view"}))
+
+		now := time.Now()
+		link, err := db.AddShare(ShareLink{
+			Path:         "/photos",
+			Owner:        "hello",
+			ExpiresAt:    now.Add(time.Hour),
+			MaxDownloads: 2,
+		}, "secret")
+		require.Nil(t, err)
+		require.NotEmpty(t, link.Token)
+		require.True(t, link.HasPassword())
+
+		ok, err := link.CheckPassword("secret")
+		require.Nil(t, err)
+		require.True(t, ok)
+
+		ok, err = link.CheckPassword("wrong")
+		require.Nil(t, err)
+		require.False(t, ok)
+
+		// Share links should not show up as users:
+		users, err := db.List()
+		require.Nil(t, err)
+		require.Len(t, users, 1)
+
+		storedLink, err := db.GetShare(link.Token)
+		require.Nil(t, err)
+		require.Equal(t, "/photos", storedLink.Path)
+		require.Equal(t, "hello", storedLink.Owner)
+		require.Equal(t, link.ExpiresAt.Unix(), storedLink.ExpiresAt.Unix())
+
+		require.True(t, storedLink.IsExpired(now.Add(2*time.Hour)))
+		_, err = db.UseShare(link.Token, now.Add(2*time.Hour))
+		require.Equal(t, ErrShareExpired, err)
+
+		for idx := uint64(1); idx <= 2; idx++ {
+			usedLink, err := db.UseShare(link.Token, now)
+			require.Nil(t, err)
+			require.Equal(t, idx, usedLink.Downloads)
+		}
+
+		// The download limit is reached now:
+		_, err = db.UseShare(link.Token, now)
+		require.Equal(t, ErrShareExpired, err)
+
+		links, err := db.ListShares()
+		require.Nil(t, err)
+		require.Len(t, links, 1)
+		require.Equal(t, link.Token, links[0].Token)
+
+		require.Nil(t, db.RemoveShare(link.Token))
+		require.Equal(t, ErrNoSuchShare, db.RemoveShare(link.Token))
+
+		_, err = db.GetShare(link.Token)
+		require.Equal(t, ErrNoSuchShare, err)
+	})
+}
@@ -10,6 +10,7 @@ module Commands exposing
     , LoginResponse
     , Remote
     , SelfResponse
+    , ShareLink
     , WhoamiResponse
     , diffChangeCount
     , doCopy
@@ -32,6 +33,9 @@ module Commands exposing
     , doRemove
     , doReset
     , doSelfQuery
+    , doShareCreate
+    , doShareList
+    , doShareRevoke
     , doUndelete
     , doUnpin
     , doUpload
@@ -961,3 +965,108 @@ doUnpin toMsg path revision =
         , body = Http.jsonBody <| encodePinQuery <| PinQuery path revision
         , expect = Http.expectJson toMsg decodePinResponse
         }
+
+
+
+-- SHARE LINKS
+
+
+type alias ShareLink =
+    { token : String
+    , path : String
+    , rev : String
+    , createdAt : Time.Posix
+    , expiresAt : Maybe Time.Posix
+    , maxDownloads : Int
+    , downloads : Int
+    , hasPassword : Bool
+    , isExpired : Bool
+    }
+
+
+type alias ShareCreateQuery =
+    { path : String
+    , expiresIn : Int
+    , maxDownloads : Int
+    , password : String
+    }
+
+
+encodeShareCreateQuery : ShareCreateQuery -> E.Value
+encodeShareCreateQuery q =
+    E.object
+        [ ( "path", E.string q.path )
+        , ( "expires_in", E.int q.expiresIn )
+        , ( "max_downloads", E.int q.maxDownloads )
+        , ( "password", E.string q.password )
+        ]
+
+
+decodeExpiresAt : D.Decoder (Maybe Time.Posix)
+decodeExpiresAt =
+    D.int
+        |> D.andThen
+            (\ms ->
+                if ms == 0 then
+                    D.succeed Nothing
+
+                else
+                    D.succeed <| Just <| Time.millisToPosix ms
+            )
+
+
+decodeShareLink : D.Decoder ShareLink
+decodeShareLink =
+    D.succeed ShareLink
+        |> DP.required "token" D.string
+        |> DP.required "path" D.string
+        |> DP.required "rev" D.string
+        |> DP.required "created_at" timestampToPosix
+        |> DP.required "expires_at" decodeExpiresAt
+        |> DP.required "max_downloads" D.int
+        |> DP.required "downloads" D.int
+        |> DP.required "has_password" D.bool
+        |> DP.required "is_expired" D.bool
+
+
+doShareCreate : (Result Http.Error ShareLink -> msg) -> String -> Int -> Int -> String -> Cmd msg
+doShareCreate toMsg path expiresIn maxDownloads password =
+    Http.post
+        { url = "/api/v0/share/create"
+        , body = Http.jsonBody <| encodeShareCreateQuery <| ShareCreateQuery path expiresIn maxDownloads password
+        , expect = Http.expectJson toMsg (D.field "link" decodeShareLink)
+        }
+
+
+doShareList : (Result Http.Error (List ShareLink) -> msg) -> Cmd msg
+doShareList toMsg =
+    Http.post
+        { url = "/api/v0/share/list"
+        , body = Http.emptyBody
+        , expect = Http.expectJson toMsg (D.field "links" (D.list decodeShareLink))
+        }
+
+
+type alias ShareRevokeQuery =
+    { token : String
+    }
+
+
+encodeShareRevokeQuery : ShareRevokeQuery -> E.Value
+encodeShareRevokeQuery q =
+    E.object
+        [ ( "token", E.string q.token ) ]
+
+
+decodeShareRevokeResponse : D.Decoder String
+decodeShareRevokeResponse =
+    D.field "message" D.string
+
+
+doShareRevoke : (Result Http.Error String -> msg) -> String -> Cmd msg
+doShareRevoke toMsg token =
+    Http.post
+        { url = "/api/v0/share/revoke"
+        , body = Http.jsonBody <| encodeShareRevokeQuery <| ShareRevokeQuery token
+        , expect = Http.expectJson toMsg decodeShareRevokeResponse
+        }
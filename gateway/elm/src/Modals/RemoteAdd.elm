@@ -216,6 +216,9 @@ showCurrentConflictStrategy model =
         "embrace" ->
             span [] [ text "Embrace ", span [ class "fas fa-handshake" ] [] ]
 
+        "merge" ->
+            span [] [ text "Merge ", span [ class "fas fa-code-branch" ] [] ]
+
         _ ->
             span [] [ text "Unknown ", span [ class "fas fa-question" ] [] ]
 
@@ -243,6 +246,9 @@ viewConflictDropdown model =
             , Dropdown.buttonItem
                 [ onClick (ConflictStrategyChanged "embrace") ]
                 [ span [ class "fas fa-md fa-handshake" ] [], text " Embrace" ]
+            , Dropdown.buttonItem
+                [ onClick (ConflictStrategyChanged "merge") ]
+                [ span [ class "fas fa-md fa-code-branch" ] [], text " Merge" ]
             , Dropdown.buttonItem
                 [ onClick (ConflictStrategyChanged "") ]
                 [ span [ class "fas fa-md fa-eraser" ] [], text " Default" ]
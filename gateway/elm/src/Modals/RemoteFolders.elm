@@ -248,6 +248,9 @@ conflictStrategyToIconName strategy =
         "embrace" ->
             "fa-handshake"
 
+        "merge" ->
+            "fa-code-branch"
+
         _ ->
             "fa-question"
 
@@ -272,6 +275,9 @@ viewConflictDropdown model folder =
             , Dropdown.buttonItem
                 [ onClick (ConflictStrategyToggled folder.folder "embrace") ]
                 [ span [ class "fas fa-md fa-handshake" ] [], text " Embrace" ]
+            , Dropdown.buttonItem
+                [ onClick (ConflictStrategyToggled folder.folder "merge") ]
+                [ span [ class "fas fa-md fa-code-branch" ] [], text " Merge" ]
             , Dropdown.buttonItem
                 [ onClick (ConflictStrategyToggled folder.folder "") ]
                 [ span [ class "fas fa-md fa-eraser" ] [], text " Default" ]
@@ -1,27 +1,51 @@
 module Modals.Share exposing (Model, Msg, newModel, show, subscriptions, update, view)
 
+import Bootstrap.Alert as Alert
 import Bootstrap.Button as Button
+import Bootstrap.Form.Input as Input
 import Bootstrap.Grid as Grid
 import Bootstrap.Grid.Col as Col
 import Bootstrap.Grid.Row as Row
 import Bootstrap.Modal as Modal
+import Commands
 import Html exposing (..)
 import Html.Attributes exposing (..)
 import Html.Events exposing (..)
+import Http
 import Url
 import Util
 
 
+type State
+    = Ready
+    | Fail String
+
+
 type alias Model =
     { paths : List String
+    , links : List Commands.ShareLink
+    , state : State
+    , expiresIn : Int
+    , maxDownloads : String
+    , password : String
     , modal : Modal.Visibility
+    , alert : Alert.Visibility
     }
 
 
 type Msg
     = ModalShow (List String)
     | AnimateModal Modal.Visibility
+    | AlertMsg Alert.Visibility
     | ModalClose
+    | ExpiresInChanged String
+    | MaxDownloadsChanged String
+    | PasswordChanged String
+    | CreateLink String
+    | RevokeLink String
+    | GotCreateResponse (Result Http.Error Commands.ShareLink)
+    | GotListResponse (Result Http.Error (List Commands.ShareLink))
+    | GotRevokeResponse (Result Http.Error String)
 
 
 
@@ -31,7 +55,13 @@ type Msg
 newModel : Model
 newModel =
     { paths = []
+    , links = []
+    , state = Ready
+    , expiresIn = 7 * 24 * 60 * 60
+    , maxDownloads = ""
+    , password = ""
     , modal = Modal.hidden
+    , alert = Alert.shown
     }
 
 
@@ -39,38 +69,213 @@ newModel =
 -- UPDATE
 
 
+failWith : Model -> String -> Http.Error -> ( Model, Cmd Msg )
+failWith model prefix err =
+    ( { model | state = Fail (prefix ++ Util.httpErrorToString err), alert = Alert.shown }, Cmd.none )
+
+
 update : Msg -> Model -> ( Model, Cmd Msg )
 update msg model =
     case msg of
         AnimateModal visibility ->
             ( { model | modal = visibility }, Cmd.none )
 
+        AlertMsg visibility ->
+            ( { model | alert = visibility }, Cmd.none )
+
         ModalShow paths ->
-            ( { model | modal = Modal.shown, paths = paths }, Cmd.none )
+            ( { newModel | modal = Modal.shown, paths = paths }
+            , Commands.doShareList GotListResponse
+            )
 
         ModalClose ->
             ( { model | modal = Modal.hidden, paths = [] }, Cmd.none )
 
+        ExpiresInChanged str ->
+            ( { model | expiresIn = Maybe.withDefault 0 (String.toInt str) }, Cmd.none )
+
+        MaxDownloadsChanged str ->
+            ( { model | maxDownloads = str }, Cmd.none )
+
+        PasswordChanged str ->
+            ( { model | password = str }, Cmd.none )
+
+        CreateLink path ->
+            ( model
+            , Commands.doShareCreate GotCreateResponse
+                path
+                model.expiresIn
+                (Maybe.withDefault 0 (String.toInt model.maxDownloads))
+                model.password
+            )
+
+        RevokeLink token ->
+            ( model, Commands.doShareRevoke GotRevokeResponse token )
+
+        GotCreateResponse result ->
+            case result of
+                Ok link ->
+                    ( { model | links = link :: model.links, state = Ready }, Cmd.none )
+
+                Err err ->
+                    failWith model "Could not create share link: " err
+
+        GotListResponse result ->
+            case result of
+                Ok links ->
+                    ( { model | links = links }, Cmd.none )
+
+                Err err ->
+                    failWith model "Could not list share links: " err
+
+        GotRevokeResponse result ->
+            case result of
+                Ok _ ->
+                    ( { model | state = Ready }, Commands.doShareList GotListResponse )
+
+                Err err ->
+                    failWith model "Could not revoke share link: " err
+
 
 
 -- VIEW
 
 
-formatEntry : Url.Url -> String -> Html msg
-formatEntry url path =
+expiryChoices : List ( Int, String )
+expiryChoices =
+    [ ( 60 * 60, "1 hour" )
+    , ( 24 * 60 * 60, "1 day" )
+    , ( 7 * 24 * 60 * 60, "1 week" )
+    , ( 30 * 24 * 60 * 60, "30 days" )
+    , ( 0, "Never" )
+    ]
+
+
+viewExpirySelect : Model -> Html Msg
+viewExpirySelect model =
+    select [ class "form-control", onInput ExpiresInChanged ]
+        (List.map
+            (\( secs, desc ) ->
+                option
+                    [ value (String.fromInt secs), selected (secs == model.expiresIn) ]
+                    [ text desc ]
+            )
+            expiryChoices
+        )
+
+
+viewOptions : Model -> Html Msg
+viewOptions model =
+    Grid.row []
+        [ Grid.col [ Col.xs4 ]
+            [ label [] [ text "Expires after" ]
+            , viewExpirySelect model
+            ]
+        , Grid.col [ Col.xs4 ]
+            [ label [] [ text "Max. downloads" ]
+            , Input.number
+                [ Input.placeholder "Unlimited"
+                , Input.value model.maxDownloads
+                , Input.onInput MaxDownloadsChanged
+                , Input.attrs [ Html.Attributes.min "0" ]
+                ]
+            ]
+        , Grid.col [ Col.xs4 ]
+            [ label [] [ text "Password" ]
+            , Input.password
+                [ Input.placeholder "None"
+                , Input.value model.password
+                , Input.onInput PasswordChanged
+                ]
+            ]
+        ]
+
+
+shareUrl : Url.Url -> Commands.ShareLink -> String
+shareUrl url link =
+    Util.urlPrefixToString url ++ "share/" ++ link.token
+
+
+viewLinkInfo : Commands.ShareLink -> String
+viewLinkInfo link =
+    let
+        downloads =
+            if link.maxDownloads == 0 then
+                String.fromInt link.downloads ++ " downloads"
+
+            else
+                String.fromInt link.downloads ++ "/" ++ String.fromInt link.maxDownloads ++ " downloads"
+
+        password =
+            if link.hasPassword then
+                ", password protected"
+
+            else
+                ""
+    in
+    if link.isExpired then
+        " (expired)"
+
+    else
+        " (" ++ downloads ++ password ++ ")"
+
+
+viewLink : Url.Url -> Commands.ShareLink -> Html Msg
+viewLink url link =
+    li []
+        [ if link.isExpired then
+            span [ class "text-muted" ] [ text (shareUrl url link) ]
+
+          else
+            a [ href (shareUrl url link) ] [ text (shareUrl url link) ]
+        , span [ class "text-muted" ] [ text (viewLinkInfo link) ]
+        , Button.button
+            [ Button.roleLink
+            , Button.small
+            , Button.attrs [ onClick (RevokeLink link.token) ]
+            ]
+            [ span [ class "fas fa-times text-danger" ] [] ]
+        ]
+
+
+formatEntry : Model -> Url.Url -> String -> Html Msg
+formatEntry model url path =
     let
         link =
             Util.urlPrefixToString url ++ "get" ++ Util.urlEncodePath path
+
+        shareLinks =
+            List.filter (\l -> l.path == path) model.links
     in
-    li [] [ a [ href link ] [ text link ] ]
+    li []
+        [ a [ href link ] [ text link ]
+        , Button.button
+            [ Button.outlinePrimary
+            , Button.small
+            , Button.attrs [ class "ml-2", onClick (CreateLink path) ]
+            ]
+            [ text "Create share link" ]
+        , ul [] (List.map (viewLink url) shareLinks)
+        ]
 
 
 viewShare : Model -> Url.Url -> List (Grid.Column Msg)
 viewShare model url =
     [ Grid.col [ Col.xs12 ]
         [ p [] [ text "Use those links to share the selected files with people that do not use brig." ]
-        , p [] [ b [] [ text "Note:" ], text " Remember, they still need to authenticate themselves." ]
-        , ul [ id "share-list" ] (List.map (formatEntry url) model.paths)
+        , p []
+            [ b [] [ text "Note:" ]
+            , text " Plain links still need a login. Share links work without one, but only until they expire."
+            ]
+        , viewOptions model
+        , br [] []
+        , ul [ id "share-list" ] (List.map (formatEntry model url) model.paths)
+        , case model.state of
+            Ready ->
+                text ""
+
+            Fail message ->
+                Util.buildAlert model.alert AlertMsg Alert.danger "Oh no!" message
         ]
     ]
 
@@ -109,4 +314,5 @@ subscriptions : Model -> Sub Msg
 subscriptions model =
     Sub.batch
         [ Modal.subscriptions model.modal AnimateModal
+        , Alert.subscriptions model.alert AlertMsg
         ]
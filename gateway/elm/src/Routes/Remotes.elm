@@ -329,6 +329,9 @@ conflictStrategyToIconName model strategy =
         "embrace" ->
             "fa-handshake"
 
+        "merge" ->
+            "fa-code-branch"
+
         _ ->
             "fa-question"
 
@@ -362,6 +365,11 @@ viewConflictDropdown model remote isDisabled =
                 , disabled isDisabled
                 ]
                 [ span [ class "fas fa-md fa-handshake" ] [], text " Embrace" ]
+            , Dropdown.buttonItem
+                [ onClick (ConflictStrategyToggled remote "merge")
+                , disabled isDisabled
+                ]
+                [ span [ class "fas fa-md fa-code-branch" ] [], text " Merge" ]
             , Dropdown.buttonItem
                 [ onClick (ConflictStrategyToggled remote "")
                 , disabled isDisabled
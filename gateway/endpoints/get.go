@@ -1,12 +1,12 @@
 package endpoints
 
 import (
+	"compress/gzip"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"path"
-	"strconv"
 	"strings"
 
 	"github.com/sahib/brig/catfs"
@@ -36,17 +36,33 @@ func mimeTypeFromStream(stream mio.Stream) (io.ReadSeeker, string) {
 	return newStream, http.DetectContentType(hdr)
 }
 
+// archiveFormat describes how a directory is packed for download.
+type archiveFormat struct {
+	ext      string
+	mimeType string
+}
+
+// archiveFormats maps the ?format= query parameter to an archive format.
+var archiveFormats = map[string]archiveFormat{
+	"":       {ext: ".tar", mimeType: "application/x-tar"},
+	"tar":    {ext: ".tar", mimeType: "application/x-tar"},
+	"tar.gz": {ext: ".tar.gz", mimeType: "application/gzip"},
+	"tgz":    {ext: ".tar.gz", mimeType: "application/gzip"},
+	"zip":    {ext: ".zip", mimeType: "application/zip"},
+}
+
 // setContentDisposition sets the Content-Disposition header, based on
 // the content we are serving. It tells a browser if it should open
-// a save dialog or display it inline (and how)
-func setContentDisposition(info *catfs.StatInfo, hdr http.Header, dispoType string) {
+// a save dialog or display it inline (and how). `ext` is appended
+// to the name of directories, which are served as archive.
+func setContentDisposition(info *catfs.StatInfo, hdr http.Header, dispoType, ext string) {
 	basename := path.Base(info.Path)
 	if info.IsDir {
 		if basename == "/" {
 			basename = "root"
 		}
 
-		basename += ".tar"
+		basename += ext
 	}
 
 	hdr.Set(
@@ -104,6 +120,25 @@ func (gh *GetHandler) checkBasicAuth(nodePath string, w http.ResponseWriter, r *
 	return true
 }
 
+// checkBearerAuth checks if the request has a valid api token
+// that may download `nodePath`.
+func (gh *GetHandler) checkBearerAuth(token, nodePath string, w http.ResponseWriter, r *http.Request) bool {
+	user, err := gh.userDb.UserByAPIToken(token)
+	if err != nil {
+		return false
+	}
+
+	hasRight := false
+	for _, right := range user.Rights {
+		if right == db.RightDownload {
+			hasRight = true
+			break
+		}
+	}
+
+	return hasRight && gh.validatePathForUser(nodePath, user, w, r)
+}
+
 func (gh *GetHandler) checkDownloadRight(w http.ResponseWriter, r *http.Request) bool {
 	name := getUserName(gh.store, w, r)
 	if name == "" {
@@ -142,7 +177,13 @@ func (gh *GetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if gh.cfg.Bool("auth.anon_allowed") {
+	if token, ok := bearerToken(r); ok {
+		// Scripts may use an api token instead of a session or basic auth.
+		if !gh.checkBearerAuth(token, nodePath, w, r) {
+			http.Error(w, "not authorized", http.StatusUnauthorized)
+			return
+		}
+	} else if gh.cfg.Bool("auth.anon_allowed") {
 		// validatePath will check if the user is actually logged in
 		// and may access the path in question. The login could come
 		// from a previous login to the UI (the /get endpoint could be used separately)
@@ -176,7 +217,33 @@ func (gh *GetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	info, err := gh.fs.Stat(nodePath)
+	gh.serveNode(w, r, "", nodePath)
+}
+
+// writeArchive packs the directory at `nodePath` (as of `rev`) in `format` to `w`.
+func (s *State) writeArchive(w io.Writer, format, rev, nodePath string, filter func(info *catfs.StatInfo) bool) error {
+	switch format {
+	case "zip":
+		return s.fs.ZipAt(rev, nodePath, w, filter)
+	case "tar.gz", "tgz":
+		zw := gzip.NewWriter(w)
+		if err := s.fs.TarAt(rev, nodePath, zw, filter); err != nil {
+			zw.Close()
+			return err
+		}
+
+		return zw.Close()
+	default:
+		return s.fs.TarAt(rev, nodePath, w, filter)
+	}
+}
+
+// serveNode writes the file at `nodePath` (as of `rev`) to `w`.
+// Files support range requests and conditional requests via ETag
+// and Last-Modified. Directories are sent as archive, which is a tar
+// by default and can be changed with the ?format= query parameter.
+func (s *State) serveNode(w http.ResponseWriter, r *http.Request, rev, nodePath string) {
+	info, err := s.fs.StatAt(rev, nodePath)
 	if err != nil {
 		// Handle a bad nodePath more explicit:
 		if ie.IsNoSuchFileError(err) {
@@ -190,13 +257,18 @@ func (gh *GetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	hdr := w.Header()
-	hdr.Set("ETag", info.ContentHash.B58String())
-	hdr.Set("Last-Modified", info.ModTime.Format(http.TimeFormat))
 
 	if info.IsDir {
 		params := r.URL.Query()
 		includes := params["include"]
 
+		format := params.Get("format")
+		archive, ok := archiveFormats[format]
+		if !ok {
+			http.Error(w, "unknown archive format", http.StatusBadRequest)
+			return
+		}
+
 		filter := func(info *catfs.StatInfo) bool {
 			if len(includes) == 0 {
 				return true
@@ -211,33 +283,44 @@ func (gh *GetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			return false
 		}
 
-		setContentDisposition(info, hdr, "attachment")
-		if err := gh.fs.Tar(nodePath, w, filter); err != nil {
+		// Archives are generated on the fly, so neither ranges
+		// nor a stable ETag can be offered for them.
+		hdr.Set("Content-Type", archive.mimeType)
+		hdr.Set("Last-Modified", info.ModTime.UTC().Format(http.TimeFormat))
+		setContentDisposition(info, hdr, "attachment", archive.ext)
+		if err := s.writeArchive(w, format, rev, nodePath, filter); err != nil {
 			log.Errorf("gateway: failed to stream %s: %v", nodePath, err)
 			http.Error(w, "failed to stream", http.StatusInternalServerError)
 			return
 		}
 	} else {
-		stream, err := gh.fs.Cat(nodePath)
+		stream, err := s.fs.CatAt(rev, nodePath)
 		if err != nil {
 			log.Errorf("gateway: failed to stream %s: %v", nodePath, err)
 			http.Error(w, "failed to stream", http.StatusInternalServerError)
 			return
 		}
 
+		defer stream.Close()
+
 		prefixStream, mimeType := mimeTypeFromStream(stream)
 		hdr.Set("Content-Type", mimeType)
-		hdr.Set("Content-Length", strconv.FormatUint(info.Size, 10))
+
+		// The content hash identifies the exact bytes we serve. ServeContent
+		// needs it quoted to handle If-Range and If-None-Match correctly.
+		hdr.Set("ETag", fmt.Sprintf("\"%s\"", info.ContentHash.B58String()))
 
 		isDirectDownload := r.URL.Query().Get("direct") == "yes"
 
 		// Set the content disposition to inline if it looks like something viewable.
 		if mimeType == "application/octet-stream" || isDirectDownload {
-			setContentDisposition(info, hdr, "attachment")
+			setContentDisposition(info, hdr, "attachment", "")
 		} else {
-			setContentDisposition(info, hdr, "inline")
+			setContentDisposition(info, hdr, "inline", "")
 		}
 
+		// ServeContent takes care of Range, If-Range, If-Modified-Since
+		// and friends. It seeks in the stream to serve partial content.
 		http.ServeContent(w, r, path.Base(info.Path), info.ModTime, prefixStream)
 	}
 }
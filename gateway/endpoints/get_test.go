@@ -1,11 +1,19 @@
 package endpoints
 
 import (
+	"archive/tar"
+	"archive/zip"
 	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
 
+	"github.com/sahib/brig/gateway/db"
+	"github.com/sahib/brig/util/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -45,3 +53,146 @@ func TestGetEndpointDisallowed(t *testing.T) {
 		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 	})
 }
+
+func TestGetEndpointAPIToken(t *testing.T) {
+	withState(t, func(s *testState) {
+		fileData := []byte("HelloWorld")
+		require.Nil(t, s.fs.Stage("/file", bytes.NewReader(fileData)))
+
+		secret, _, err := s.userDb.AddAPIToken("ali", "ci", []string{db.RightDownload}, nil)
+		require.Nil(t, err)
+
+		resp := s.mustRunWithToken(t, NewGetHandler(s.State), "GET", "http://localhost:5000/get/file", secret, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		data, err := ioutil.ReadAll(resp.Body)
+		require.Nil(t, err)
+		require.Equal(t, fileData, data)
+
+		// Tokens without download right are not enough:
+		secret, _, err = s.userDb.AddAPIToken("ali", "view", []string{db.RightFsView}, nil)
+		require.Nil(t, err)
+
+		resp = s.mustRunWithToken(t, NewGetHandler(s.State), "GET", "http://localhost:5000/get/file", secret, nil)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestGetEndpointRange(t *testing.T) {
+	withState(t, func(s *testState) {
+		fileData := testutil.CreateDummyBuf(64 * 1024)
+		require.Nil(t, s.fs.Stage("/file", bytes.NewReader(fileData)))
+
+		hdl := NewGetHandler(s.State)
+		url := "http://localhost:5000/get/file"
+
+		resp := s.mustRun(t, hdl, "GET", url, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "bytes", resp.Header.Get("Accept-Ranges"))
+		require.Equal(t, fmt.Sprintf("%d", len(fileData)), resp.Header.Get("Content-Length"))
+		require.NotEmpty(t, resp.Header.Get("Last-Modified"))
+
+		etag := resp.Header.Get("ETag")
+		require.True(t, len(etag) > 2)
+		require.Equal(t, byte('"'), etag[0])
+		require.Equal(t, byte('"'), etag[len(etag)-1])
+
+		// Resuming somewhere in the middle of the file:
+		resp = s.mustRunWithHeaders(t, hdl, "GET", url, map[string]string{
+			"Range": "bytes=1000-",
+		}, nil)
+		require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+		require.Equal(t, fmt.Sprintf("bytes 1000-%d/%d", len(fileData)-1, len(fileData)), resp.Header.Get("Content-Range"))
+		data, err := ioutil.ReadAll(resp.Body)
+		require.Nil(t, err)
+		require.Equal(t, fileData[1000:], data)
+
+		// Ranges inside the first few bytes that are used for mime detection:
+		resp = s.mustRunWithHeaders(t, hdl, "GET", url, map[string]string{
+			"Range": "bytes=10-19",
+		}, nil)
+		require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+		data, err = ioutil.ReadAll(resp.Body)
+		require.Nil(t, err)
+		require.Equal(t, fileData[10:20], data)
+
+		// Unchanged content does not need to be sent again:
+		resp = s.mustRunWithHeaders(t, hdl, "GET", url, map[string]string{
+			"If-None-Match": etag,
+		}, nil)
+		require.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+		// If-Range only yields a partial response when the etag matches:
+		resp = s.mustRunWithHeaders(t, hdl, "GET", url, map[string]string{
+			"Range":    "bytes=0-9",
+			"If-Range": etag,
+		}, nil)
+		require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+		data, err = ioutil.ReadAll(resp.Body)
+		require.Nil(t, err)
+		require.Equal(t, fileData[:10], data)
+
+		resp = s.mustRunWithHeaders(t, hdl, "GET", url, map[string]string{
+			"Range":    "bytes=0-9",
+			"If-Range": `"outdated"`,
+		}, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		data, err = ioutil.ReadAll(resp.Body)
+		require.Nil(t, err)
+		require.Equal(t, fileData, data)
+	})
+}
+
+func TestGetEndpointArchiveFormats(t *testing.T) {
+	withState(t, func(s *testState) {
+		require.Nil(t, s.fs.Stage("/dir/a", bytes.NewReader([]byte("hello"))))
+		require.Nil(t, s.fs.Stage("/dir/b", bytes.NewReader([]byte("world"))))
+
+		hdl := NewGetHandler(s.State)
+		url := "http://localhost:5000/get/dir"
+
+		resp := s.mustRun(t, hdl, "GET", url+"?format=zip", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+		require.Contains(t, resp.Header.Get("Content-Disposition"), "dir.zip")
+
+		data, err := ioutil.ReadAll(resp.Body)
+		require.Nil(t, err)
+
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		require.Nil(t, err)
+		require.Len(t, zr.File, 2)
+		require.Equal(t, "a", zr.File[0].Name)
+		require.Equal(t, "b", zr.File[1].Name)
+
+		resp = s.mustRun(t, hdl, "GET", url+"?format=tar.gz", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/gzip", resp.Header.Get("Content-Type"))
+		require.Contains(t, resp.Header.Get("Content-Disposition"), "dir.tar.gz")
+
+		gr, err := gzip.NewReader(resp.Body)
+		require.Nil(t, err)
+
+		names := []string{}
+		tr := tar.NewReader(gr)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+
+			require.Nil(t, err)
+			names = append(names, strings.TrimPrefix(hdr.Name, "/"))
+		}
+
+		require.Equal(t, []string{"a", "b"}, names)
+
+		// The default is still a plain tar:
+		resp = s.mustRun(t, hdl, "GET", url, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/x-tar", resp.Header.Get("Content-Type"))
+		require.Contains(t, resp.Header.Get("Content-Disposition"), "dir.tar")
+
+		resp = s.mustRun(t, hdl, "GET", url+"?format=rar", nil)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
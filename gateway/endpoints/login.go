@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/gorilla/sessions"
 	"github.com/sahib/brig/gateway/db"
@@ -33,6 +34,24 @@ func getUserName(store *sessions.CookieStore, w http.ResponseWriter, r *http.Req
 	return userName
 }
 
+// bearerToken returns the api token sent in the
+// "Authorization: Bearer <token>" header, if any.
+func bearerToken(r *http.Request) (string, bool) {
+	auth := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", false
+	}
+
+	return strings.TrimSpace(auth[len(prefix):]), true
+}
+
+// HasAPIToken returns true if the request carries an api token.
+func HasAPIToken(r *http.Request) bool {
+	_, ok := bearerToken(r)
+	return ok
+}
+
 func setSession(store *sessions.CookieStore, userName string, w http.ResponseWriter, r *http.Request) {
 	// Ignore the error here, since it will usually trigger when there was a previously
 	// outdated session that fails to decode. Since we overwrite the session anyways, it
@@ -192,6 +211,23 @@ type WhoamiResponse struct {
 }
 
 func (wh *WhoamiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if token, ok := bearerToken(r); ok {
+		// Requests with api tokens do not get a session.
+		user, err := wh.userDb.UserByAPIToken(token)
+		if err != nil {
+			jsonifyErrf(w, http.StatusUnauthorized, "not authorized")
+			return
+		}
+
+		jsonify(w, http.StatusOK, WhoamiResponse{
+			IsLoggedIn:    true,
+			AnonIsAllowed: wh.cfg.Bool("auth.anon_allowed"),
+			User:          user.Name,
+			Rights:        user.Rights,
+		})
+		return
+	}
+
 	rights := []string{}
 	isAnon := false
 
@@ -231,7 +267,34 @@ type authMiddleware struct {
 
 type dbUserKey string
 
+func (am *authMiddleware) serveWithUser(w http.ResponseWriter, r *http.Request, user db.User) {
+	r = r.WithContext(
+		context.WithValue(r.Context(), dbUserKey("brig.db_user"),
+			user,
+		),
+	)
+
+	am.SubHandler.ServeHTTP(w, r)
+}
+
 func (am *authMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if token, ok := bearerToken(r); ok {
+		// If a token was sent, it has to be valid.
+		// There is no fallback to the session or the anon user.
+		user, err := am.userDb.UserByAPIToken(token)
+		if err != nil {
+			if err != db.ErrNoSuchAPIToken {
+				log.Warningf("failed to check api token: %v", err)
+			}
+
+			jsonifyErrf(w, http.StatusUnauthorized, "not authorized")
+			return
+		}
+
+		am.serveWithUser(w, r, user)
+		return
+	}
+
 	anonIsAllowed := am.cfg.Bool("auth.anon_allowed")
 	name := getUserName(am.store, w, r)
 
@@ -253,18 +316,17 @@ func (am *authMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	r = r.WithContext(
-		context.WithValue(r.Context(), dbUserKey("brig.db_user"),
-			user,
-		),
-	)
-
-	am.SubHandler.ServeHTTP(w, r)
+	am.serveWithUser(w, r, user)
 }
 
-func checkRights(w http.ResponseWriter, r *http.Request, rights ...string) bool {
+// userFromRequest returns the user that was set by the auth middleware.
+func userFromRequest(r *http.Request) (db.User, bool) {
 	user, ok := r.Context().Value(dbUserKey("brig.db_user")).(db.User)
+	return user, ok
+}
 
+func checkRights(w http.ResponseWriter, r *http.Request, rights ...string) bool {
+	user, ok := userFromRequest(r)
 	if !ok {
 		jsonifyErrf(w, http.StatusInternalServerError, "could not cast user")
 		return false
@@ -287,7 +349,7 @@ func checkRights(w http.ResponseWriter, r *http.Request, rights ...string) bool
 
 // AuthMiddleware returns a new handler wrapper, that will require
 // all calls to the respective handler to have a "sess" cookie with
-// a valid user name.
+// a valid user name or a valid api token as bearer token.
 func AuthMiddleware(s *State) func(http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
 		return &authMiddleware{State: s, SubHandler: h}
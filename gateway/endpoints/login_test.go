@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/sahib/brig/gateway/db"
 	"github.com/stretchr/testify/require"
 )
 
@@ -34,3 +35,39 @@ func TestLoginEndpointSuccess(t *testing.T) {
 		require.Equal(t, "sess", cookies[0].Name)
 	})
 }
+
+func TestAuthMiddlewareAPIToken(t *testing.T) {
+	withState(t, func(s *testState) {
+		secret, _, err := s.userDb.AddAPIToken(
+			"ali",
+			"ci",
+			[]string{db.RightFsView, db.RightFsEdit},
+			[]string{"/public"},
+		)
+		require.Nil(t, err)
+
+		hdl := AuthMiddleware(s.State)(NewMkdirHandler(s.State))
+		resp := s.mustRunWithToken(
+			t, hdl, "POST", "http://localhost:5000/api/v0/mkdir",
+			secret, &MkdirRequest{Path: "/public/dir"},
+		)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		_, err = s.fs.Stat("/public/dir")
+		require.Nil(t, err)
+
+		// The folders of the token apply:
+		resp = s.mustRunWithToken(
+			t, hdl, "POST", "http://localhost:5000/api/v0/mkdir",
+			secret, &MkdirRequest{Path: "/private"},
+		)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		// Invalid tokens do not fall back to anything else:
+		resp = s.mustRunWithToken(
+			t, hdl, "POST", "http://localhost:5000/api/v0/mkdir",
+			secret+"x", &MkdirRequest{Path: "/public/other"},
+		)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
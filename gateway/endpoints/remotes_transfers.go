@@ -0,0 +1,46 @@
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/sahib/brig/gateway/db"
+	"github.com/sahib/brig/gateway/remotesapi"
+)
+
+// RemotesTransfersHandler implements http.Handler.
+// It returns the progress of running metadata transfers (during sync or diff).
+type RemotesTransfersHandler struct {
+	*State
+}
+
+// NewRemotesTransfersHandler returns a new RemotesTransfersHandler
+func NewRemotesTransfersHandler(s *State) *RemotesTransfersHandler {
+	return &RemotesTransfersHandler{State: s}
+}
+
+// RemotesTransfersResponse is the response given by this endpoint.
+type RemotesTransfersResponse struct {
+	Success   bool                  `json:"success"`
+	Transfers []remotesapi.Transfer `json:"transfers"`
+}
+
+func (rh *RemotesTransfersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !checkRights(w, r, db.RightRemotesView) {
+		return
+	}
+
+	transfers, err := rh.rapi.Transfers()
+	if err != nil {
+		jsonifyErrf(w, http.StatusInternalServerError, "failed to list transfers")
+		return
+	}
+
+	if transfers == nil {
+		transfers = []remotesapi.Transfer{}
+	}
+
+	jsonify(w, http.StatusOK, &RemotesTransfersResponse{
+		Success:   true,
+		Transfers: transfers,
+	})
+}
@@ -0,0 +1,44 @@
+package endpoints
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sahib/brig/gateway/remotesapi"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemotesTransfersEndpoint(t *testing.T) {
+	withState(t, func(s *testState) {
+		mock, ok := s.State.rapi.(*remotesapi.Mock)
+		require.True(t, ok)
+
+		mock.SetTransfers([]remotesapi.Transfer{{
+			Remote:  "bob",
+			Kind:    "patches",
+			Done:    1024,
+			Total:   4096,
+			Started: time.Now(),
+		}})
+
+		resp := s.mustRun(
+			t,
+			NewRemotesTransfersHandler(s.State),
+			"POST",
+			"http://localhost:5000/api/v0/remotes/transfers",
+			nil,
+		)
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		data := &RemotesTransfersResponse{}
+		mustDecodeBody(t, resp.Body, &data)
+
+		require.True(t, data.Success)
+		require.Len(t, data.Transfers, 1)
+		require.Equal(t, "bob", data.Transfers[0].Remote)
+		require.Equal(t, int64(1024), data.Transfers[0].Done)
+		require.Equal(t, int64(4096), data.Transfers[0].Total)
+	})
+}
@@ -0,0 +1,1033 @@
+package endpoints
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sahib/brig/catfs"
+	ie "github.com/sahib/brig/catfs/errors"
+	"github.com/sahib/brig/gateway/db"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	s3XMLNamespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+	s3XMLTime      = "2006-01-02T15:04:05.000Z"
+	s3MaxKeys      = 1000
+
+	// s3MetaXattr is the extended attribute that stores the etag,
+	// content type and user metadata of an object.
+	s3MetaXattr = "user.s3.meta"
+)
+
+// s3EmptyETag is the etag of empty objects like directory markers.
+var s3EmptyETag = fmt.Sprintf("\"%x\"", md5.Sum(nil))
+
+// s3Error is an error in the format that S3 clients understand.
+type s3Error struct {
+	Code       string
+	Message    string
+	StatusCode int
+}
+
+func (se *s3Error) Error() string {
+	return se.Message
+}
+
+var (
+	s3ErrAccessDenied          = &s3Error{"AccessDenied", "Access denied", http.StatusForbidden}
+	s3ErrAuthMalformed         = &s3Error{"AuthorizationHeaderMalformed", "The authorization is malformed", http.StatusBadRequest}
+	s3ErrBadDigest             = &s3Error{"BadDigest", "The Content-MD5 you specified did not match what we received", http.StatusBadRequest}
+	s3ErrBucketExists          = &s3Error{"BucketAlreadyExists", "A file with this name exists already", http.StatusConflict}
+	s3ErrBucketOwnedByYou      = &s3Error{"BucketAlreadyOwnedByYou", "The bucket exists already", http.StatusConflict}
+	s3ErrBucketNotEmpty        = &s3Error{"BucketNotEmpty", "The bucket you tried to delete is not empty", http.StatusConflict}
+	s3ErrContentSHA256Mismatch = &s3Error{"XAmzContentSHA256Mismatch", "The content hash does not match the signed one", http.StatusBadRequest}
+	s3ErrExpired               = &s3Error{"AccessDenied", "Request has expired", http.StatusForbidden}
+	s3ErrInternal              = &s3Error{"InternalError", "We encountered an internal error, please try again", http.StatusInternalServerError}
+	s3ErrInvalidAccessKeyID    = &s3Error{"InvalidAccessKeyId", "The access key id you provided does not exist", http.StatusForbidden}
+	s3ErrInvalidArgument       = &s3Error{"InvalidArgument", "Invalid argument", http.StatusBadRequest}
+	s3ErrInvalidBucketName     = &s3Error{"InvalidBucketName", "The specified bucket is not valid", http.StatusBadRequest}
+	s3ErrInvalidDigest         = &s3Error{"InvalidDigest", "The Content-MD5 you specified is not valid", http.StatusBadRequest}
+	s3ErrInvalidKey            = &s3Error{"InvalidArgument", "Keys must be clean paths", http.StatusBadRequest}
+	s3ErrInvalidPart           = &s3Error{"InvalidPart", "One or more of the specified parts could not be found", http.StatusBadRequest}
+	s3ErrInvalidPartOrder      = &s3Error{"InvalidPartOrder", "The list of parts was not in ascending order", http.StatusBadRequest}
+	s3ErrKeyIsDirectory        = &s3Error{"InvalidRequest", "There is a directory at this key", http.StatusBadRequest}
+	s3ErrMalformedXML          = &s3Error{"MalformedXML", "The XML you provided was not well-formed", http.StatusBadRequest}
+	s3ErrMethodNotAllowed      = &s3Error{"MethodNotAllowed", "The method is not allowed against this resource", http.StatusMethodNotAllowed}
+	s3ErrNoSuchBucket          = &s3Error{"NoSuchBucket", "The specified bucket does not exist", http.StatusNotFound}
+	s3ErrNoSuchKey             = &s3Error{"NoSuchKey", "The specified key does not exist", http.StatusNotFound}
+	s3ErrNoSuchUpload          = &s3Error{"NoSuchUpload", "The specified multipart upload does not exist", http.StatusNotFound}
+	s3ErrNotImplemented        = &s3Error{"NotImplemented", "This operation is not supported", http.StatusNotImplemented}
+	s3ErrRequestTimeTooSkewed  = &s3Error{"RequestTimeTooSkewed", "The difference between the request time and the server's time is too large", http.StatusForbidden}
+	s3ErrSignatureDoesNotMatch = &s3Error{"SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided", http.StatusForbidden}
+)
+
+// s3Subresources are query parameters that select another operation,
+// which is not supported (like ?acl or ?tagging).
+var s3Subresources = []string{
+	"accelerate", "acl", "analytics", "attributes", "cors", "delete",
+	"encryption", "inventory", "legal-hold", "lifecycle", "logging",
+	"metrics", "notification", "object-lock", "ownershipControls",
+	"policy", "publicAccessBlock", "replication", "requestPayment",
+	"restore", "retention", "select", "tagging", "torrent", "versioning",
+	"versions", "website",
+}
+
+// S3Handler implements http.Handler.
+// It serves an S3 compatible API, where buckets are the top-level folders.
+// Clients authenticate with the access keys of a user (see
+// db.UserDatabase.AddAccessKey); the user's folders and rights apply.
+type S3Handler struct {
+	*State
+	uploads *s3Uploads
+}
+
+// NewS3Handler returns a new S3Handler.
+func NewS3Handler(s *State) *S3Handler {
+	return &S3Handler{
+		State:   s,
+		uploads: newS3Uploads(),
+	}
+}
+
+// Close removes the data of all unfinished multipart uploads.
+func (sh *S3Handler) Close() error {
+	return sh.uploads.Close()
+}
+
+func writeS3XML(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(statusCode)
+
+	w.Write([]byte(xml.Header))
+	if err := xml.NewEncoder(w).Encode(data); err != nil {
+		log.Warningf("s3: failed to encode xml: %v", err)
+	}
+}
+
+func writeS3Error(w http.ResponseWriter, r *http.Request, err error) {
+	se, ok := err.(*s3Error)
+	if !ok {
+		if ie.IsNoSuchFileError(err) {
+			se = s3ErrNoSuchKey
+		} else {
+			log.Warningf("s3: %s %s failed: %v", r.Method, r.URL.Path, err)
+			se = s3ErrInternal
+		}
+	}
+
+	log.Debugf("s3: %s %s: %s", r.Method, r.URL.Path, se.Code)
+	if r.Method == http.MethodHead {
+		w.WriteHeader(se.StatusCode)
+		return
+	}
+
+	writeS3XML(w, se.StatusCode, struct {
+		XMLName  xml.Name `xml:"Error"`
+		Code     string
+		Message  string
+		Resource string
+	}{
+		Code:     se.Code,
+		Message:  se.Message,
+		Resource: r.URL.Path,
+	})
+}
+
+// splitS3Path splits an url path into bucket and key.
+// Keys that end with a slash are directory markers.
+func splitS3Path(urlPath string) (string, string, error) {
+	parts := strings.SplitN(strings.TrimPrefix(urlPath, "/"), "/", 2)
+	bucket, key := parts[0], ""
+	if len(parts) > 1 {
+		key = parts[1]
+	}
+
+	if bucket == "." || bucket == ".." || (bucket == "" && urlPath != "/") {
+		return "", "", s3ErrInvalidBucketName
+	}
+
+	if key != "" {
+		trimmedKey := strings.TrimSuffix(key, "/")
+		if path.Clean("/"+trimmedKey) != "/"+trimmedKey || trimmedKey == "" {
+			return "", "", s3ErrInvalidKey
+		}
+	}
+
+	return bucket, key, nil
+}
+
+func s3NodePath(bucket, key string) string {
+	return "/" + bucket + "/" + strings.TrimSuffix(key, "/")
+}
+
+func (sh *S3Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, err := sh.authenticateS3(r)
+	if err != nil {
+		writeS3Error(w, r, err)
+		return
+	}
+
+	if err := sh.dispatch(w, r, user); err != nil {
+		writeS3Error(w, r, err)
+	}
+}
+
+// dispatch calls the right operation for `r`.
+// If an error is returned, nothing was written to `w` yet.
+func (sh *S3Handler) dispatch(w http.ResponseWriter, r *http.Request, user *db.User) error {
+	bucket, key, err := splitS3Path(r.URL.Path)
+	if err != nil {
+		return err
+	}
+
+	query := r.URL.Query()
+	for _, subresource := range s3Subresources {
+		if _, ok := query[subresource]; ok {
+			return s3ErrNotImplemented
+		}
+	}
+
+	if bucket == "" {
+		if r.Method != http.MethodGet {
+			return s3ErrMethodNotAllowed
+		}
+
+		return sh.listBuckets(w, r, user)
+	}
+
+	if key == "" {
+		switch r.Method {
+		case http.MethodGet:
+			if _, ok := query["location"]; ok {
+				return sh.getBucketLocation(w, r, user, bucket)
+			}
+
+			if _, ok := query["uploads"]; ok {
+				return s3ErrNotImplemented
+			}
+
+			return sh.listObjects(w, r, user, bucket)
+		case http.MethodHead:
+			return sh.headBucket(w, r, user, bucket)
+		case http.MethodPut:
+			return sh.createBucket(w, r, user, bucket)
+		case http.MethodDelete:
+			return sh.deleteBucket(w, r, user, bucket)
+		default:
+			return s3ErrMethodNotAllowed
+		}
+	}
+
+	_, isUploadsRequest := query["uploads"]
+	uploadID := query.Get("uploadId")
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if uploadID != "" {
+			// Listing the parts of an upload.
+			return s3ErrNotImplemented
+		}
+
+		return sh.getObject(w, r, user, bucket, key)
+	case http.MethodPut:
+		if r.Header.Get("X-Amz-Copy-Source") != "" {
+			return s3ErrNotImplemented
+		}
+
+		if uploadID != "" {
+			return sh.uploadPart(w, r, user, bucket, key, uploadID)
+		}
+
+		return sh.putObject(w, r, user, bucket, key)
+	case http.MethodPost:
+		if isUploadsRequest {
+			return sh.createMultipartUpload(w, r, user, bucket, key)
+		}
+
+		if uploadID != "" {
+			return sh.completeMultipartUpload(w, r, user, bucket, key, uploadID)
+		}
+
+		return s3ErrMethodNotAllowed
+	case http.MethodDelete:
+		if uploadID != "" {
+			return sh.abortMultipartUpload(w, r, user, bucket, key, uploadID)
+		}
+
+		return sh.deleteObject(w, r, user, bucket, key)
+	default:
+		return s3ErrMethodNotAllowed
+	}
+}
+
+func checkS3Rights(user *db.User, rights ...string) error {
+	rmap := make(map[string]bool)
+	for _, right := range user.Rights {
+		rmap[right] = true
+	}
+
+	for _, right := range rights {
+		if !rmap[right] {
+			return s3ErrAccessDenied
+		}
+	}
+
+	return nil
+}
+
+func (sh *S3Handler) canAccess(user *db.User, nodePath string) bool {
+	return sh.validatePathForUser(nodePath, *user, nil, nil)
+}
+
+func (sh *S3Handler) commit(r *http.Request, user *db.User, msg string) error {
+	return sh.makeCommit(r.Context(), user.Name, "s3: "+msg)
+}
+
+// statBucket returns the directory of `bucket`, if the user may see it.
+func (sh *S3Handler) statBucket(user *db.User, bucket string) (*catfs.StatInfo, error) {
+	bucketPath := "/" + bucket
+	if !sh.pathIsVisibleForUser(bucketPath, *user) {
+		// Do not tell if there is something:
+		return nil, s3ErrNoSuchBucket
+	}
+
+	info, err := sh.fs.Stat(bucketPath)
+	if err != nil {
+		if ie.IsNoSuchFileError(err) {
+			return nil, s3ErrNoSuchBucket
+		}
+
+		return nil, err
+	}
+
+	if !info.IsDir {
+		return nil, s3ErrNoSuchBucket
+	}
+
+	return info, nil
+}
+
+///////
+
+type s3Owner struct {
+	ID          string
+	DisplayName string
+}
+
+type s3Bucket struct {
+	Name         string
+	CreationDate string
+}
+
+func (sh *S3Handler) listBuckets(w http.ResponseWriter, r *http.Request, user *db.User) error {
+	if err := checkS3Rights(user, db.RightFsView); err != nil {
+		return err
+	}
+
+	entries, err := sh.fs.List("/", 1)
+	if err != nil {
+		return err
+	}
+
+	buckets := []s3Bucket{}
+	for _, entry := range entries {
+		if !entry.IsDir || !sh.pathIsVisibleForUser(entry.Path, *user) {
+			continue
+		}
+
+		buckets = append(buckets, s3Bucket{
+			Name:         path.Base(entry.Path),
+			CreationDate: entry.ModTime.UTC().Format(s3XMLTime),
+		})
+	}
+
+	writeS3XML(w, http.StatusOK, struct {
+		XMLName xml.Name   `xml:"ListAllMyBucketsResult"`
+		Xmlns   string     `xml:"xmlns,attr"`
+		Owner   s3Owner    `xml:"Owner"`
+		Buckets []s3Bucket `xml:"Buckets>Bucket"`
+	}{
+		Xmlns:   s3XMLNamespace,
+		Owner:   s3Owner{ID: user.Name, DisplayName: user.Name},
+		Buckets: buckets,
+	})
+
+	return nil
+}
+
+func (sh *S3Handler) headBucket(w http.ResponseWriter, r *http.Request, user *db.User, bucket string) error {
+	if err := checkS3Rights(user, db.RightFsView); err != nil {
+		return err
+	}
+
+	if _, err := sh.statBucket(user, bucket); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (sh *S3Handler) getBucketLocation(w http.ResponseWriter, r *http.Request, user *db.User, bucket string) error {
+	if err := checkS3Rights(user, db.RightFsView); err != nil {
+		return err
+	}
+
+	if _, err := sh.statBucket(user, bucket); err != nil {
+		return err
+	}
+
+	// An empty location means the default region (us-east-1).
+	// The region is not used for anything but signing anyways.
+	writeS3XML(w, http.StatusOK, struct {
+		XMLName xml.Name `xml:"LocationConstraint"`
+		Xmlns   string   `xml:"xmlns,attr"`
+	}{
+		Xmlns: s3XMLNamespace,
+	})
+
+	return nil
+}
+
+func (sh *S3Handler) createBucket(w http.ResponseWriter, r *http.Request, user *db.User, bucket string) error {
+	if err := checkS3Rights(user, db.RightFsView, db.RightFsEdit); err != nil {
+		return err
+	}
+
+	bucketPath := "/" + bucket
+	if !sh.canAccess(user, bucketPath) {
+		return s3ErrAccessDenied
+	}
+
+	if info, err := sh.fs.Stat(bucketPath); err == nil {
+		if info.IsDir {
+			return s3ErrBucketOwnedByYou
+		}
+
+		return s3ErrBucketExists
+	}
+
+	if err := sh.fs.Mkdir(bucketPath, false); err != nil {
+		return err
+	}
+
+	if err := sh.commit(r, user, fmt.Sprintf("created bucket »%s«", bucket)); err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", bucketPath)
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (sh *S3Handler) deleteBucket(w http.ResponseWriter, r *http.Request, user *db.User, bucket string) error {
+	if err := checkS3Rights(user, db.RightFsView, db.RightFsEdit); err != nil {
+		return err
+	}
+
+	info, err := sh.statBucket(user, bucket)
+	if err != nil {
+		return err
+	}
+
+	if !sh.canAccess(user, info.Path) {
+		return s3ErrAccessDenied
+	}
+
+	children, err := sh.fs.List(info.Path, 1)
+	if err != nil {
+		return err
+	}
+
+	if len(children) > 0 {
+		return s3ErrBucketNotEmpty
+	}
+
+	if err := sh.fs.Remove(info.Path); err != nil {
+		return err
+	}
+
+	if err := sh.commit(r, user, fmt.Sprintf("removed bucket »%s«", bucket)); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+///////
+
+// s3Meta is what S3 clients store alongside an object. It is kept in the
+// s3MetaXattr attribute and only used as long as the content was not
+// changed by other means since.
+type s3Meta struct {
+	ContentHash string            `json:"content_hash"`
+	ETag        string            `json:"etag"`
+	ContentType string            `json:"content_type,omitempty"`
+	UserMeta    map[string]string `json:"user_meta,omitempty"`
+}
+
+// readS3Meta returns the meta data of the object at `info`.
+// If there is none, the content hash is used as etag.
+func (sh *S3Handler) readS3Meta(info *catfs.StatInfo) *s3Meta {
+	if info.IsDir {
+		return &s3Meta{ETag: s3EmptyETag, ContentType: "application/x-directory"}
+	}
+
+	meta := &s3Meta{}
+	data, err := sh.fs.GetXattr(info.Path, s3MetaXattr)
+	if err != nil || json.Unmarshal(data, meta) != nil || meta.ContentHash != info.ContentHash.B58String() {
+		meta = &s3Meta{ETag: fmt.Sprintf("\"%s\"", info.ContentHash.B58String())}
+	}
+
+	if meta.ContentType == "" {
+		meta.ContentType = mime.TypeByExtension(path.Ext(info.Path))
+		if meta.ContentType == "" {
+			meta.ContentType = "application/octet-stream"
+		}
+	}
+
+	return meta
+}
+
+// writeS3Meta remembers `meta` for the content that is now at `nodePath`.
+func (sh *S3Handler) writeS3Meta(nodePath string, meta *s3Meta) error {
+	info, err := sh.fs.Stat(nodePath)
+	if err != nil {
+		return err
+	}
+
+	meta.ContentHash = info.ContentHash.B58String()
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	return sh.fs.SetXattr(nodePath, s3MetaXattr, data)
+}
+
+// s3MetaFromRequest reads the content type and the user meta data
+// (X-Amz-Meta-* headers) that should be stored with the object.
+func s3MetaFromRequest(r *http.Request) *s3Meta {
+	meta := &s3Meta{
+		ContentType: r.Header.Get("Content-Type"),
+		UserMeta:    make(map[string]string),
+	}
+
+	for name, values := range r.Header {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, "x-amz-meta-") && len(values) > 0 {
+			meta.UserMeta[strings.TrimPrefix(name, "x-amz-meta-")] = values[0]
+		}
+	}
+
+	return meta
+}
+
+// s3BodyReader computes the md5 sum of the request body. At the end of the
+// body it checks the Content-MD5 header (if any). Errors while reading are
+// remembered, since the fs layer might not pass them on as-is.
+type s3BodyReader struct {
+	rd      io.Reader
+	hash    hash.Hash
+	wantMD5 []byte
+	err     error
+}
+
+func newS3BodyReader(r *http.Request) (*s3BodyReader, error) {
+	br := &s3BodyReader{rd: r.Body, hash: md5.New()}
+	if contentMD5 := r.Header.Get("Content-MD5"); contentMD5 != "" {
+		wantMD5, err := base64.StdEncoding.DecodeString(contentMD5)
+		if err != nil || len(wantMD5) != md5.Size {
+			return nil, s3ErrInvalidDigest
+		}
+
+		br.wantMD5 = wantMD5
+	}
+
+	return br, nil
+}
+
+func (br *s3BodyReader) Read(buf []byte) (int, error) {
+	n, err := br.rd.Read(buf)
+	br.hash.Write(buf[:n])
+
+	if err == io.EOF && br.wantMD5 != nil && !bytes.Equal(br.hash.Sum(nil), br.wantMD5) {
+		err = s3ErrBadDigest
+	}
+
+	if err != nil && err != io.EOF {
+		br.err = err
+	}
+
+	return n, err
+}
+
+func (br *s3BodyReader) sum() []byte {
+	return br.hash.Sum(nil)
+}
+
+// checkS3PutPath checks if an object may be written to `nodePath`.
+func (sh *S3Handler) checkS3PutPath(user *db.User, bucket, nodePath string) error {
+	if err := checkS3Rights(user, db.RightFsView, db.RightFsEdit); err != nil {
+		return err
+	}
+
+	if !sh.canAccess(user, nodePath) {
+		return s3ErrAccessDenied
+	}
+
+	if _, err := sh.statBucket(user, bucket); err != nil {
+		return err
+	}
+
+	if info, err := sh.fs.Stat(nodePath); err == nil && info.IsDir {
+		return s3ErrKeyIsDirectory
+	}
+
+	return nil
+}
+
+func (sh *S3Handler) putDirectoryMarker(w http.ResponseWriter, r *http.Request, user *db.User, bucket, key string) error {
+	nodePath := s3NodePath(bucket, key)
+	if err := checkS3Rights(user, db.RightFsView, db.RightFsEdit); err != nil {
+		return err
+	}
+
+	if !sh.canAccess(user, nodePath) {
+		return s3ErrAccessDenied
+	}
+
+	if _, err := sh.statBucket(user, bucket); err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, 1))
+	if err != nil {
+		return err
+	}
+
+	if len(data) > 0 {
+		return &s3Error{"InvalidArgument", "Directory markers can't have content", http.StatusBadRequest}
+	}
+
+	if info, err := sh.fs.Stat(nodePath); err != nil || !info.IsDir {
+		if err := sh.fs.Mkdir(nodePath, true); err != nil {
+			return err
+		}
+
+		if err := sh.commit(r, user, fmt.Sprintf("mkdir'd »%s«", nodePath)); err != nil {
+			return err
+		}
+	}
+
+	w.Header().Set("ETag", s3EmptyETag)
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (sh *S3Handler) putObject(w http.ResponseWriter, r *http.Request, user *db.User, bucket, key string) error {
+	if strings.HasSuffix(key, "/") {
+		return sh.putDirectoryMarker(w, r, user, bucket, key)
+	}
+
+	nodePath := s3NodePath(bucket, key)
+	if err := sh.checkS3PutPath(user, bucket, nodePath); err != nil {
+		return err
+	}
+
+	body, err := newS3BodyReader(r)
+	if err != nil {
+		return err
+	}
+
+	if err := sh.fs.Stage(nodePath, body); err != nil {
+		if body.err != nil {
+			// The upload was aborted or did not match its checksum.
+			return body.err
+		}
+
+		return err
+	}
+
+	meta := s3MetaFromRequest(r)
+	meta.ETag = fmt.Sprintf("\"%x\"", body.sum())
+	if err := sh.writeS3Meta(nodePath, meta); err != nil {
+		return err
+	}
+
+	if err := sh.commit(r, user, fmt.Sprintf("uploaded »%s«", nodePath)); err != nil {
+		return err
+	}
+
+	w.Header().Set("ETag", meta.ETag)
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// s3HeadContent stands in for the content on HEAD requests,
+// where http.ServeContent only needs the size.
+type s3HeadContent struct{}
+
+func (s3HeadContent) ReadAt(buf []byte, off int64) (int, error) {
+	return 0, io.EOF
+}
+
+func (sh *S3Handler) getObject(w http.ResponseWriter, r *http.Request, user *db.User, bucket, key string) error {
+	if err := checkS3Rights(user, db.RightFsView, db.RightDownload); err != nil {
+		return err
+	}
+
+	nodePath := s3NodePath(bucket, key)
+	if !sh.canAccess(user, nodePath) {
+		return s3ErrAccessDenied
+	}
+
+	info, err := sh.fs.Stat(nodePath)
+	if err != nil {
+		if ie.IsNoSuchFileError(err) {
+			if _, err := sh.statBucket(user, bucket); err != nil {
+				return err
+			}
+		}
+
+		return err
+	}
+
+	isMarker := strings.HasSuffix(key, "/")
+	if info.IsDir != isMarker {
+		return s3ErrNoSuchKey
+	}
+
+	meta := sh.readS3Meta(info)
+	hdr := w.Header()
+	hdr.Set("ETag", meta.ETag)
+	hdr.Set("Content-Type", meta.ContentType)
+	for name, value := range meta.UserMeta {
+		hdr.Set("X-Amz-Meta-"+name, value)
+	}
+
+	// Presigned urls may ask to override some headers:
+	for _, name := range []string{
+		"Cache-Control",
+		"Content-Disposition",
+		"Content-Encoding",
+		"Content-Language",
+		"Content-Type",
+		"Expires",
+	} {
+		if value := r.URL.Query().Get("response-" + strings.ToLower(name)); value != "" {
+			hdr.Set(name, value)
+		}
+	}
+
+	if isMarker {
+		http.ServeContent(w, r, "", info.ModTime, bytes.NewReader(nil))
+		return nil
+	}
+
+	if r.Method == http.MethodHead {
+		content := io.NewSectionReader(s3HeadContent{}, 0, int64(info.Size))
+		http.ServeContent(w, r, "", info.ModTime, content)
+		return nil
+	}
+
+	stream, err := sh.fs.Cat(nodePath)
+	if err != nil {
+		return err
+	}
+
+	defer stream.Close()
+
+	// This takes care of Range requests and conditional headers:
+	http.ServeContent(w, r, "", info.ModTime, stream)
+	return nil
+}
+
+func (sh *S3Handler) deleteObject(w http.ResponseWriter, r *http.Request, user *db.User, bucket, key string) error {
+	if err := checkS3Rights(user, db.RightFsView, db.RightFsEdit); err != nil {
+		return err
+	}
+
+	nodePath := s3NodePath(bucket, key)
+	if !sh.canAccess(user, nodePath) {
+		return s3ErrAccessDenied
+	}
+
+	if _, err := sh.statBucket(user, bucket); err != nil {
+		return err
+	}
+
+	// Deleting something that does not exist is no error in S3.
+	isMarker := strings.HasSuffix(key, "/")
+	info, err := sh.fs.Stat(nodePath)
+	if err == nil && info.IsDir == isMarker {
+		isEmpty := true
+		if info.IsDir {
+			children, err := sh.fs.List(nodePath, 1)
+			if err != nil {
+				return err
+			}
+
+			// Only the marker of a directory with content is deleted
+			// in S3; the directory stays here.
+			isEmpty = len(children) == 0
+		}
+
+		if isEmpty {
+			if err := sh.fs.Remove(nodePath); err != nil {
+				return err
+			}
+
+			if err := sh.commit(r, user, fmt.Sprintf("removed »%s«", nodePath)); err != nil {
+				return err
+			}
+		}
+	} else if err != nil && !ie.IsNoSuchFileError(err) {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+///////
+
+type s3ObjectEntry struct {
+	Key          string
+	LastModified string
+	ETag         string
+	Size         uint64
+	StorageClass string
+}
+
+type s3CommonPrefix struct {
+	Prefix string
+}
+
+type s3ListBucketResult struct {
+	XMLName xml.Name `xml:"ListBucketResult"`
+	Xmlns   string   `xml:"xmlns,attr"`
+
+	Name         string
+	Prefix       string
+	Delimiter    string `xml:",omitempty"`
+	MaxKeys      int
+	EncodingType string `xml:",omitempty"`
+	IsTruncated  bool
+
+	// Version 1 only:
+	Marker     *string `xml:",omitempty"`
+	NextMarker string  `xml:",omitempty"`
+
+	// Version 2 only:
+	KeyCount              *int   `xml:",omitempty"`
+	ContinuationToken     string `xml:",omitempty"`
+	NextContinuationToken string `xml:",omitempty"`
+	StartAfter            string `xml:",omitempty"`
+
+	Contents       []s3ObjectEntry
+	CommonPrefixes []s3CommonPrefix
+}
+
+// s3ListEntry is either an object or, if info is nil, a common prefix.
+type s3ListEntry struct {
+	key  string
+	info *catfs.StatInfo
+}
+
+// collectS3Entries returns all keys below `bucketPath` that start with
+// `prefix`, sorted like S3 does. Keys that contain `delimiter` after the
+// prefix are rolled up into a common prefix. Empty directories are
+// listed as directory markers.
+func (sh *S3Handler) collectS3Entries(user *db.User, bucketPath, prefix, delimiter string) ([]s3ListEntry, error) {
+	// No need to look at what can't match the prefix anyways:
+	listRoot := bucketPath
+	if idx := strings.LastIndex(prefix, "/"); idx >= 0 {
+		listRoot = path.Join(bucketPath, prefix[:idx])
+	}
+
+	infos, err := sh.fs.List(listRoot, -1)
+	if err != nil {
+		if ie.IsNoSuchFileError(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	hasChildren := make(map[string]bool)
+	for _, info := range infos {
+		hasChildren[path.Dir(info.Path)] = true
+	}
+
+	seenPrefixes := make(map[string]bool)
+	entries := []s3ListEntry{}
+	for _, info := range infos {
+		if info.Path == bucketPath || !strings.HasPrefix(info.Path, bucketPath+"/") {
+			continue
+		}
+
+		key := strings.TrimPrefix(info.Path, bucketPath+"/")
+		if info.IsDir {
+			if hasChildren[info.Path] {
+				continue
+			}
+
+			key += "/"
+		}
+
+		if !strings.HasPrefix(key, prefix) || !sh.canAccess(user, info.Path) {
+			continue
+		}
+
+		if delimiter != "" {
+			if idx := strings.Index(key[len(prefix):], delimiter); idx >= 0 {
+				commonPrefix := key[:len(prefix)+idx+len(delimiter)]
+				if !seenPrefixes[commonPrefix] {
+					seenPrefixes[commonPrefix] = true
+					entries = append(entries, s3ListEntry{key: commonPrefix})
+				}
+
+				continue
+			}
+		}
+
+		entries = append(entries, s3ListEntry{key: key, info: info})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	return entries, nil
+}
+
+func (sh *S3Handler) listObjects(w http.ResponseWriter, r *http.Request, user *db.User, bucket string) error {
+	if err := checkS3Rights(user, db.RightFsView); err != nil {
+		return err
+	}
+
+	info, err := sh.statBucket(user, bucket)
+	if err != nil {
+		return err
+	}
+
+	query := r.URL.Query()
+	isV2 := query.Get("list-type") == "2"
+	prefix := query.Get("prefix")
+	delimiter := query.Get("delimiter")
+
+	maxKeys := s3MaxKeys
+	if maxKeysStr := query.Get("max-keys"); maxKeysStr != "" {
+		requested, err := strconv.Atoi(maxKeysStr)
+		if err != nil || requested < 0 {
+			return s3ErrInvalidArgument
+		}
+
+		if requested < maxKeys {
+			maxKeys = requested
+		}
+	}
+
+	encode := func(s string) string { return s }
+	switch encodingType := query.Get("encoding-type"); encodingType {
+	case "":
+	case "url":
+		encode = func(s string) string { return s3Escape(s, true) }
+	default:
+		return s3ErrInvalidArgument
+	}
+
+	result := s3ListBucketResult{
+		Xmlns:        s3XMLNamespace,
+		Name:         bucket,
+		Prefix:       encode(prefix),
+		Delimiter:    encode(delimiter),
+		MaxKeys:      maxKeys,
+		EncodingType: query.Get("encoding-type"),
+	}
+
+	// Everything up to and including `marker` was listed already.
+	marker := ""
+	if isV2 {
+		result.StartAfter = encode(query.Get("start-after"))
+		result.ContinuationToken = query.Get("continuation-token")
+		marker = query.Get("start-after")
+		if result.ContinuationToken != "" {
+			decoded, err := base64.RawURLEncoding.DecodeString(result.ContinuationToken)
+			if err != nil {
+				return &s3Error{"InvalidArgument", "The continuation token is not valid", http.StatusBadRequest}
+			}
+
+			marker = string(decoded)
+		}
+	} else {
+		marker = query.Get("marker")
+		encodedMarker := encode(marker)
+		result.Marker = &encodedMarker
+	}
+
+	entries, err := sh.collectS3Entries(user, info.Path, prefix, delimiter)
+	if err != nil {
+		return err
+	}
+
+	idx := sort.Search(len(entries), func(i int) bool {
+		return entries[i].key > marker
+	})
+
+	entries = entries[idx:]
+	if len(entries) > maxKeys {
+		entries = entries[:maxKeys]
+		result.IsTruncated = true
+	}
+
+	if result.IsTruncated && len(entries) > 0 {
+		lastKey := entries[len(entries)-1].key
+		if isV2 {
+			result.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(lastKey))
+		} else {
+			result.NextMarker = encode(lastKey)
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.info == nil {
+			result.CommonPrefixes = append(result.CommonPrefixes, s3CommonPrefix{
+				Prefix: encode(entry.key),
+			})
+			continue
+		}
+
+		result.Contents = append(result.Contents, s3ObjectEntry{
+			Key:          encode(entry.key),
+			LastModified: entry.info.ModTime.UTC().Format(s3XMLTime),
+			ETag:         sh.readS3Meta(entry.info).ETag,
+			Size:         entry.info.Size,
+			StorageClass: "STANDARD",
+		})
+	}
+
+	if isV2 {
+		keyCount := len(entries)
+		result.KeyCount = &keyCount
+	}
+
+	writeS3XML(w, http.StatusOK, result)
+	return nil
+}
@@ -0,0 +1,521 @@
+package endpoints
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sahib/brig/gateway/db"
+)
+
+// This file implements AWS signature version 4, which is what all S3
+// clients use to authenticate. See also:
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-authenticating-requests.html
+
+const (
+	s3SignAlgorithm    = "AWS4-HMAC-SHA256"
+	s3TimeFormat       = "20060102T150405Z"
+	s3MaxClockSkew     = 15 * time.Minute
+	s3MaxPresignExpiry = 7 * 24 * time.Hour
+
+	s3UnsignedPayload        = "UNSIGNED-PAYLOAD"
+	s3StreamingPayload       = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	s3StreamingUnsignedTrail = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+
+	// Clients use 64KB chunks usually; do not buffer arbitrarily large ones.
+	s3MaxChunkSize = 16 * 1024 * 1024
+)
+
+var s3EmptySHA256 = hex.EncodeToString(sha256.New().Sum(nil))
+
+// s3Signature holds what is needed to verify the signature of a request
+// and, for streaming uploads, the signatures of the single chunks.
+type s3Signature struct {
+	accessKey     string
+	date          time.Time
+	scope         string
+	signedHeaders []string
+	signature     string
+	payloadHash   string
+	signingKey    []byte
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// s3Escape encodes `s` like AWS does: everything except the unreserved
+// characters is percent encoded. Slashes are kept if `keepSlash` is true.
+func s3Escape(s string, keepSlash bool) string {
+	buf := strings.Builder{}
+	for idx := 0; idx < len(s); idx++ {
+		c := s[idx]
+		isUnreserved := (c >= 'A' && c <= 'Z') ||
+			(c >= 'a' && c <= 'z') ||
+			(c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~'
+
+		if isUnreserved || (keepSlash && c == '/') {
+			buf.WriteByte(c)
+			continue
+		}
+
+		fmt.Fprintf(&buf, "%%%02X", c)
+	}
+
+	return buf.String()
+}
+
+// parseS3Credential parses "<key>/<date>/<region>/s3/aws4_request".
+func parseS3Credential(credential string) (string, string, error) {
+	parts := strings.SplitN(credential, "/", 2)
+	if len(parts) != 2 {
+		return "", "", s3ErrAuthMalformed
+	}
+
+	scope := strings.Split(parts[1], "/")
+	if len(scope) != 4 || scope[2] != "s3" || scope[3] != "aws4_request" {
+		return "", "", s3ErrAuthMalformed
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// parseS3Signature extracts the signature of `r`, either from the
+// Authorization header or from the query of a presigned url.
+func parseS3Signature(r *http.Request) (*s3Signature, error) {
+	sig := &s3Signature{}
+	credential, amzDate := "", ""
+
+	query := r.URL.Query()
+	if authHdr := r.Header.Get("Authorization"); authHdr != "" {
+		if !strings.HasPrefix(authHdr, s3SignAlgorithm+" ") {
+			// Most likely signature version 2, which is deprecated.
+			return nil, s3ErrNotImplemented
+		}
+
+		for _, field := range strings.Split(strings.TrimPrefix(authHdr, s3SignAlgorithm), ",") {
+			kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+			if len(kv) != 2 {
+				return nil, s3ErrAuthMalformed
+			}
+
+			switch kv[0] {
+			case "Credential":
+				credential = kv[1]
+			case "SignedHeaders":
+				sig.signedHeaders = strings.Split(kv[1], ";")
+			case "Signature":
+				sig.signature = kv[1]
+			}
+		}
+
+		amzDate = r.Header.Get("X-Amz-Date")
+		if amzDate == "" {
+			amzDate = r.Header.Get("Date")
+		}
+
+		sig.payloadHash = r.Header.Get("X-Amz-Content-Sha256")
+		if sig.payloadHash == "" {
+			return nil, s3ErrAuthMalformed
+		}
+	} else if query.Get("X-Amz-Algorithm") != "" {
+		if query.Get("X-Amz-Algorithm") != s3SignAlgorithm {
+			return nil, s3ErrNotImplemented
+		}
+
+		credential = query.Get("X-Amz-Credential")
+		sig.signedHeaders = strings.Split(query.Get("X-Amz-SignedHeaders"), ";")
+		sig.signature = query.Get("X-Amz-Signature")
+		amzDate = query.Get("X-Amz-Date")
+		sig.payloadHash = s3UnsignedPayload
+	} else {
+		return nil, s3ErrAccessDenied
+	}
+
+	if credential == "" || sig.signature == "" || len(sig.signedHeaders) == 0 {
+		return nil, s3ErrAuthMalformed
+	}
+
+	// The host has to be signed, otherwise a signed request
+	// could be replayed against another server with the same keys.
+	hostSigned := false
+	for _, name := range sig.signedHeaders {
+		if strings.ToLower(name) == "host" {
+			hostSigned = true
+			break
+		}
+	}
+
+	if !hostSigned {
+		return nil, s3ErrAuthMalformed
+	}
+
+	var err error
+	sig.accessKey, sig.scope, err = parseS3Credential(credential)
+	if err != nil {
+		return nil, err
+	}
+
+	sig.date, err = time.Parse(s3TimeFormat, amzDate)
+	if err != nil {
+		// Some clients send a HTTP date in the Date header instead:
+		sig.date, err = http.ParseTime(amzDate)
+		if err != nil {
+			return nil, s3ErrAuthMalformed
+		}
+	}
+
+	if !strings.HasPrefix(sig.scope, sig.date.UTC().Format("20060102")+"/") {
+		return nil, s3ErrAuthMalformed
+	}
+
+	return sig, nil
+}
+
+// checkExpiry makes sure that the request was signed recently
+// or, for presigned urls, that the url did not expire yet.
+func (sig *s3Signature) checkExpiry(r *http.Request, now time.Time) error {
+	expires := r.URL.Query().Get("X-Amz-Expires")
+	if expires == "" {
+		skew := now.Sub(sig.date)
+		if skew > s3MaxClockSkew || skew < -s3MaxClockSkew {
+			return s3ErrRequestTimeTooSkewed
+		}
+
+		return nil
+	}
+
+	seconds, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || seconds < 0 || time.Duration(seconds)*time.Second > s3MaxPresignExpiry {
+		return s3ErrAuthMalformed
+	}
+
+	if now.Before(sig.date.Add(-s3MaxClockSkew)) || now.After(sig.date.Add(time.Duration(seconds)*time.Second)) {
+		return s3ErrExpired
+	}
+
+	return nil
+}
+
+func canonicalS3Query(r *http.Request) string {
+	query := r.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if key != "X-Amz-Signature" {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	parts := []string{}
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, s3Escape(key, false)+"="+s3Escape(value, false))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+func canonicalS3HeaderValue(r *http.Request, name string) string {
+	values := r.Header.Values(name)
+	switch {
+	case name == "host":
+		values = []string{r.Host}
+	case name == "content-length" && len(values) == 0:
+		values = []string{strconv.FormatInt(r.ContentLength, 10)}
+	}
+
+	trimmed := []string{}
+	for _, value := range values {
+		trimmed = append(trimmed, strings.Join(strings.Fields(value), " "))
+	}
+
+	return strings.Join(trimmed, ",")
+}
+
+func (sig *s3Signature) canonicalRequest(r *http.Request) string {
+	buf := strings.Builder{}
+	for _, name := range sig.signedHeaders {
+		buf.WriteString(name + ":" + canonicalS3HeaderValue(r, name) + "\n")
+	}
+
+	return strings.Join([]string{
+		r.Method,
+		s3Escape(r.URL.Path, true),
+		canonicalS3Query(r),
+		buf.String(),
+		strings.Join(sig.signedHeaders, ";"),
+		sig.payloadHash,
+	}, "\n")
+}
+
+func (sig *s3Signature) stringToSign(r *http.Request) string {
+	return strings.Join([]string{
+		s3SignAlgorithm,
+		sig.date.UTC().Format(s3TimeFormat),
+		sig.scope,
+		sha256Hex([]byte(sig.canonicalRequest(r))),
+	}, "\n")
+}
+
+// deriveSigningKey derives the key for this date, region and service.
+func (sig *s3Signature) deriveSigningKey(secret string) {
+	scope := strings.Split(sig.scope, "/")
+	key := hmacSHA256([]byte("AWS4"+secret), scope[0])
+	for _, part := range scope[1:] {
+		key = hmacSHA256(key, part)
+	}
+
+	sig.signingKey = key
+}
+
+func (sig *s3Signature) sign(stringToSign string) string {
+	return hex.EncodeToString(hmacSHA256(sig.signingKey, stringToSign))
+}
+
+// verify checks the signature of `r` with `secret`.
+func (sig *s3Signature) verify(r *http.Request, secret string) error {
+	sig.deriveSigningKey(secret)
+	if !hmac.Equal([]byte(sig.sign(sig.stringToSign(r))), []byte(sig.signature)) {
+		return s3ErrSignatureDoesNotMatch
+	}
+
+	return nil
+}
+
+// authenticateS3 checks the signature of `r` and returns the user that sent it.
+func (sh *S3Handler) authenticateS3(r *http.Request) (*db.User, error) {
+	sig, err := parseS3Signature(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sig.checkExpiry(r, time.Now()); err != nil {
+		return nil, err
+	}
+
+	user, key, err := sh.userDb.UserByAccessKey(sig.accessKey)
+	if err != nil {
+		if err == db.ErrNoSuchAccessKey {
+			return nil, s3ErrInvalidAccessKeyID
+		}
+
+		return nil, err
+	}
+
+	if err := sig.verify(r, key.Secret); err != nil {
+		return nil, err
+	}
+
+	if err := sig.wrapBody(r); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// wrapBody replaces the body of `r` by one that checks (and, for streaming
+// uploads, decodes) the payload while it is read.
+func (sig *s3Signature) wrapBody(r *http.Request) error {
+	switch sig.payloadHash {
+	case s3UnsignedPayload:
+		// Nothing to check.
+	case s3StreamingPayload:
+		r.Body = &s3ChunkedReader{
+			body:    r.Body,
+			rd:      bufio.NewReader(r.Body),
+			sig:     sig,
+			prevSig: sig.signature,
+		}
+		r.ContentLength = -1
+	case s3StreamingUnsignedTrail:
+		r.Body = &s3ChunkedReader{
+			body: r.Body,
+			rd:   bufio.NewReader(r.Body),
+		}
+		r.ContentLength = -1
+	default:
+		if _, err := hex.DecodeString(sig.payloadHash); err != nil || len(sig.payloadHash) != 64 {
+			return s3ErrNotImplemented
+		}
+
+		r.Body = &s3HashingReader{
+			body: r.Body,
+			hash: sha256.New(),
+			want: sig.payloadHash,
+		}
+	}
+
+	if decLength := r.Header.Get("X-Amz-Decoded-Content-Length"); decLength != "" && r.ContentLength < 0 {
+		var err error
+		r.ContentLength, err = strconv.ParseInt(decLength, 10, 64)
+		if err != nil {
+			return s3ErrInvalidArgument
+		}
+	}
+
+	return nil
+}
+
+// s3HashingReader fails at the end of the body if
+// its sha256 sum does not match the signed one.
+type s3HashingReader struct {
+	body io.ReadCloser
+	hash hash.Hash
+	want string
+}
+
+func (hr *s3HashingReader) Read(buf []byte) (int, error) {
+	n, err := hr.body.Read(buf)
+	hr.hash.Write(buf[:n])
+	if err == io.EOF && hex.EncodeToString(hr.hash.Sum(nil)) != hr.want {
+		return n, s3ErrContentSHA256Mismatch
+	}
+
+	return n, err
+}
+
+func (hr *s3HashingReader) Close() error {
+	return hr.body.Close()
+}
+
+// s3ChunkedReader decodes an aws-chunked body. Each chunk looks like this:
+//
+//	<hex size>;chunk-signature=<signature>\r\n<data>\r\n
+//
+// The signature of each chunk includes the one of the chunk before, so
+// chunks can't be reordered or dropped. If `sig` is nil the chunks are
+// not signed and the body may end with trailing headers, which are ignored.
+type s3ChunkedReader struct {
+	body    io.ReadCloser
+	rd      *bufio.Reader
+	sig     *s3Signature
+	prevSig string
+	chunk   []byte
+	done    bool
+}
+
+func (cr *s3ChunkedReader) readLine() (string, error) {
+	line, err := cr.rd.ReadString('\n')
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (cr *s3ChunkedReader) nextChunk() error {
+	header, err := cr.readLine()
+	if err != nil {
+		return err
+	}
+
+	sizeHex, chunkSig := header, ""
+	if idx := strings.Index(header, ";"); idx >= 0 {
+		sizeHex = header[:idx]
+		chunkSig = strings.TrimPrefix(header[idx+1:], "chunk-signature=")
+	}
+
+	size, err := strconv.ParseUint(sizeHex, 16, 64)
+	if err != nil || size > s3MaxChunkSize {
+		return s3ErrInvalidArgument
+	}
+
+	chunk := make([]byte, size)
+	if _, err := io.ReadFull(cr.rd, chunk); err != nil {
+		return err
+	}
+
+	if cr.sig != nil {
+		stringToSign := strings.Join([]string{
+			"AWS4-HMAC-SHA256-PAYLOAD",
+			cr.sig.date.UTC().Format(s3TimeFormat),
+			cr.sig.scope,
+			cr.prevSig,
+			s3EmptySHA256,
+			sha256Hex(chunk),
+		}, "\n")
+
+		expected := cr.sig.sign(stringToSign)
+		if !hmac.Equal([]byte(expected), []byte(chunkSig)) {
+			return s3ErrSignatureDoesNotMatch
+		}
+
+		cr.prevSig = expected
+	}
+
+	if size == 0 {
+		// The last chunk; skip the trailing headers (if any) up to the empty line.
+		for {
+			line, err := cr.readLine()
+			if err != nil {
+				return err
+			}
+
+			if line == "" {
+				break
+			}
+		}
+
+		cr.done = true
+		return nil
+	}
+
+	crlf := make([]byte, 2)
+	if _, err := io.ReadFull(cr.rd, crlf); err != nil {
+		return err
+	}
+
+	if !bytes.Equal(crlf, []byte("\r\n")) {
+		return s3ErrInvalidArgument
+	}
+
+	cr.chunk = chunk
+	return nil
+}
+
+func (cr *s3ChunkedReader) Read(buf []byte) (int, error) {
+	for len(cr.chunk) == 0 {
+		if cr.done {
+			return 0, io.EOF
+		}
+
+		if err := cr.nextChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(buf, cr.chunk)
+	cr.chunk = cr.chunk[n:]
+	return n, nil
+}
+
+func (cr *s3ChunkedReader) Close() error {
+	return cr.body.Close()
+}
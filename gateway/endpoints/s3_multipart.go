@@ -0,0 +1,403 @@
+package endpoints
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sahib/brig/gateway/db"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	s3MaxPartNumber = 10000
+
+	// Uploads that were neither completed nor aborted
+	// are removed after this time.
+	s3UploadExpiry = 24 * time.Hour
+)
+
+type s3Part struct {
+	md5 []byte
+}
+
+func (p s3Part) etag() string {
+	return fmt.Sprintf("\"%x\"", p.md5)
+}
+
+// s3Upload is an unfinished multipart upload. The parts are stored as
+// plain files outside of brig until the upload is completed, so that
+// nothing of it ends up in the history.
+type s3Upload struct {
+	mu sync.Mutex
+
+	id       string
+	userName string
+	nodePath string
+	meta     *s3Meta
+	dir      string
+	created  time.Time
+	parts    map[int]s3Part
+}
+
+func (up *s3Upload) partPath(partNum int) string {
+	return filepath.Join(up.dir, strconv.Itoa(partNum))
+}
+
+// s3Uploads keeps track of all unfinished multipart uploads.
+// They are kept in memory only and are lost on restart.
+type s3Uploads struct {
+	mu      sync.Mutex
+	dir     string
+	uploads map[string]*s3Upload
+}
+
+func newS3Uploads() *s3Uploads {
+	return &s3Uploads{
+		uploads: make(map[string]*s3Upload),
+	}
+}
+
+// create registers a new upload to `nodePath` for the user `userName`.
+func (su *s3Uploads) create(userName, nodePath string, meta *s3Meta) (*s3Upload, error) {
+	su.mu.Lock()
+	defer su.mu.Unlock()
+
+	su.expire(time.Now())
+
+	if su.dir == "" {
+		dir, err := ioutil.TempDir("", "brig-s3-uploads-")
+		if err != nil {
+			return nil, err
+		}
+
+		su.dir = dir
+	}
+
+	idBuf := make([]byte, 16)
+	if _, err := rand.Read(idBuf); err != nil {
+		return nil, err
+	}
+
+	id := hex.EncodeToString(idBuf)
+	upload := &s3Upload{
+		id:       id,
+		userName: userName,
+		nodePath: nodePath,
+		meta:     meta,
+		dir:      filepath.Join(su.dir, id),
+		created:  time.Now(),
+		parts:    make(map[int]s3Part),
+	}
+
+	if err := os.Mkdir(upload.dir, 0700); err != nil {
+		return nil, err
+	}
+
+	su.uploads[id] = upload
+	return upload, nil
+}
+
+// expire removes uploads that are older than s3UploadExpiry.
+func (su *s3Uploads) expire(now time.Time) {
+	for id, upload := range su.uploads {
+		if now.Sub(upload.created) < s3UploadExpiry {
+			continue
+		}
+
+		log.Infof("s3: removing stale upload to %s", upload.nodePath)
+		if err := os.RemoveAll(upload.dir); err != nil {
+			log.Warningf("s3: failed to remove upload data: %v", err)
+		}
+
+		delete(su.uploads, id)
+	}
+}
+
+// get returns the upload with `id`, if it belongs to `userName` and `nodePath`.
+// If `forget` is true, the upload is also removed from the list.
+func (su *s3Uploads) get(id, userName, nodePath string, forget bool) (*s3Upload, error) {
+	su.mu.Lock()
+	defer su.mu.Unlock()
+
+	upload, ok := su.uploads[id]
+	if !ok || upload.userName != userName || upload.nodePath != nodePath {
+		return nil, s3ErrNoSuchUpload
+	}
+
+	if forget {
+		delete(su.uploads, id)
+	}
+
+	return upload, nil
+}
+
+// putBack makes an upload that was forgotten by get() available again.
+func (su *s3Uploads) putBack(upload *s3Upload) {
+	su.mu.Lock()
+	defer su.mu.Unlock()
+
+	su.uploads[upload.id] = upload
+}
+
+func (su *s3Uploads) remove(upload *s3Upload) {
+	if err := os.RemoveAll(upload.dir); err != nil {
+		log.Warningf("s3: failed to remove upload data: %v", err)
+	}
+}
+
+// Close removes all unfinished uploads.
+func (su *s3Uploads) Close() error {
+	su.mu.Lock()
+	defer su.mu.Unlock()
+
+	su.uploads = make(map[string]*s3Upload)
+	if su.dir == "" {
+		return nil
+	}
+
+	dir := su.dir
+	su.dir = ""
+	return os.RemoveAll(dir)
+}
+
+///////
+
+func (sh *S3Handler) createMultipartUpload(w http.ResponseWriter, r *http.Request, user *db.User, bucket, key string) error {
+	if strings.HasSuffix(key, "/") {
+		return s3ErrInvalidKey
+	}
+
+	nodePath := s3NodePath(bucket, key)
+	if err := sh.checkS3PutPath(user, bucket, nodePath); err != nil {
+		return err
+	}
+
+	upload, err := sh.uploads.create(user.Name, nodePath, s3MetaFromRequest(r))
+	if err != nil {
+		return err
+	}
+
+	writeS3XML(w, http.StatusOK, struct {
+		XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+		Xmlns    string   `xml:"xmlns,attr"`
+		Bucket   string
+		Key      string
+		UploadID string `xml:"UploadId"`
+	}{
+		Xmlns:    s3XMLNamespace,
+		Bucket:   bucket,
+		Key:      key,
+		UploadID: upload.id,
+	})
+
+	return nil
+}
+
+func (sh *S3Handler) uploadPart(w http.ResponseWriter, r *http.Request, user *db.User, bucket, key, uploadID string) error {
+	// The user's rights or folders might have changed since the upload
+	// was created, so the upload id alone is not enough to write parts.
+	nodePath := s3NodePath(bucket, key)
+	if err := sh.checkS3PutPath(user, bucket, nodePath); err != nil {
+		return err
+	}
+
+	upload, err := sh.uploads.get(uploadID, user.Name, nodePath, false)
+	if err != nil {
+		return err
+	}
+
+	partNum, err := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	if err != nil || partNum < 1 || partNum > s3MaxPartNumber {
+		return &s3Error{"InvalidArgument", "Part number must be between 1 and 10000", http.StatusBadRequest}
+	}
+
+	body, err := newS3BodyReader(r)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that a failed upload of
+	// a part does not overwrite an earlier, successful one.
+	fd, err := ioutil.TempFile(upload.dir, "part-")
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(fd, body)
+	if closeErr := fd.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(fd.Name(), upload.partPath(partNum))
+	}
+
+	if err != nil {
+		os.Remove(fd.Name())
+		return err
+	}
+
+	part := s3Part{md5: body.sum()}
+
+	upload.mu.Lock()
+	upload.parts[partNum] = part
+	upload.mu.Unlock()
+
+	w.Header().Set("ETag", part.etag())
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+type s3CompletedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+// s3PartsReader reads all parts of an upload one after another.
+// Only one part is opened at a time.
+type s3PartsReader struct {
+	upload   *s3Upload
+	partNums []int
+	curr     *os.File
+}
+
+func (pr *s3PartsReader) Read(buf []byte) (int, error) {
+	for {
+		if pr.curr == nil {
+			if len(pr.partNums) == 0 {
+				return 0, io.EOF
+			}
+
+			fd, err := os.Open(pr.upload.partPath(pr.partNums[0]))
+			if err != nil {
+				return 0, err
+			}
+
+			pr.curr = fd
+			pr.partNums = pr.partNums[1:]
+		}
+
+		n, err := pr.curr.Read(buf)
+		if err == io.EOF {
+			pr.curr.Close()
+			pr.curr = nil
+			if n == 0 {
+				continue
+			}
+
+			err = nil
+		}
+
+		return n, err
+	}
+}
+
+func (pr *s3PartsReader) Close() error {
+	if pr.curr != nil {
+		return pr.curr.Close()
+	}
+
+	return nil
+}
+
+func (sh *S3Handler) completeMultipartUpload(w http.ResponseWriter, r *http.Request, user *db.User, bucket, key, uploadID string) error {
+	nodePath := s3NodePath(bucket, key)
+	if err := sh.checkS3PutPath(user, bucket, nodePath); err != nil {
+		return err
+	}
+
+	request := struct {
+		Parts []s3CompletedPart `xml:"Part"`
+	}{}
+
+	if err := xml.NewDecoder(r.Body).Decode(&request); err != nil || len(request.Parts) == 0 {
+		return s3ErrMalformedXML
+	}
+
+	upload, err := sh.uploads.get(uploadID, user.Name, nodePath, true)
+	if err != nil {
+		return err
+	}
+
+	upload.mu.Lock()
+	defer upload.mu.Unlock()
+
+	// The etag of a multipart upload is the md5 of the md5s of all parts:
+	partNums := []int{}
+	etagHash := md5.New()
+	for idx, completed := range request.Parts {
+		if idx > 0 && completed.PartNumber <= request.Parts[idx-1].PartNumber {
+			sh.uploads.putBack(upload)
+			return s3ErrInvalidPartOrder
+		}
+
+		part, ok := upload.parts[completed.PartNumber]
+		if !ok || strings.Trim(completed.ETag, "\"") != strings.Trim(part.etag(), "\"") {
+			sh.uploads.putBack(upload)
+			return s3ErrInvalidPart
+		}
+
+		partNums = append(partNums, completed.PartNumber)
+		etagHash.Write(part.md5)
+	}
+
+	parts := &s3PartsReader{upload: upload, partNums: partNums}
+	defer parts.Close()
+
+	if err := sh.fs.Stage(nodePath, parts); err != nil {
+		sh.uploads.putBack(upload)
+		return err
+	}
+
+	sh.uploads.remove(upload)
+
+	meta := upload.meta
+	meta.ETag = fmt.Sprintf("\"%x-%d\"", etagHash.Sum(nil), len(partNums))
+	if err := sh.writeS3Meta(nodePath, meta); err != nil {
+		return err
+	}
+
+	if err := sh.commit(r, user, fmt.Sprintf("uploaded »%s«", nodePath)); err != nil {
+		return err
+	}
+
+	writeS3XML(w, http.StatusOK, struct {
+		XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+		Xmlns    string   `xml:"xmlns,attr"`
+		Location string
+		Bucket   string
+		Key      string
+		ETag     string
+	}{
+		Xmlns:    s3XMLNamespace,
+		Location: "/" + bucket + "/" + key,
+		Bucket:   bucket,
+		Key:      key,
+		ETag:     meta.ETag,
+	})
+
+	return nil
+}
+
+func (sh *S3Handler) abortMultipartUpload(w http.ResponseWriter, r *http.Request, user *db.User, bucket, key, uploadID string) error {
+	upload, err := sh.uploads.get(uploadID, user.Name, s3NodePath(bucket, key), true)
+	if err != nil {
+		return err
+	}
+
+	sh.uploads.remove(upload)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,79 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sahib/brig/catfs"
+	"github.com/sahib/brig/gateway/db"
+)
+
+// SearchHandler implements http.Handler.
+// It searches nodes with the query language of »brig find«.
+type SearchHandler struct {
+	*State
+}
+
+// NewSearchHandler returns a new SearchHandler
+func NewSearchHandler(s *State) *SearchHandler {
+	return &SearchHandler{State: s}
+}
+
+// SearchRequest is the data that needs to be sent to this endpoint.
+type SearchRequest struct {
+	Root  string `json:"root"`
+	Query string `json:"query"`
+}
+
+// SearchResponse is the response sent back to the client.
+type SearchResponse struct {
+	Success bool        `json:"success"`
+	Files   []*StatInfo `json:"files"`
+}
+
+func (sh *SearchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !checkRights(w, r, db.RightFsView) {
+		return
+	}
+
+	searchReq := SearchRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&searchReq); err != nil {
+		jsonifyErrf(w, http.StatusBadRequest, "bad json")
+		return
+	}
+
+	query, err := catfs.ParseQuery(searchReq.Query)
+	if err != nil {
+		jsonifyErrf(w, http.StatusBadRequest, "bad query: %v", err)
+		return
+	}
+
+	user, ok := sh.requestUser(w, r)
+	if !ok {
+		jsonifyErrf(w, http.StatusUnauthorized, "not authorized")
+		return
+	}
+
+	// Filter before searching, so that content queries do not read
+	// files the user may not see. Unlike ls, do not show directories
+	// that only lead to a visible folder.
+	root := prefixRoot(searchReq.Root)
+	items, err := sh.fs.Find(root, query, func(info *catfs.StatInfo) bool {
+		return sh.validatePathForUser(info.Path, user, nil, nil)
+	})
+
+	if err != nil {
+		jsonifyErrf(w, http.StatusBadRequest, "failed to search: %v", err)
+		return
+	}
+
+	files := []*StatInfo{}
+	for _, item := range items {
+		files = append(files, toExternalStatInfo(item))
+	}
+
+	jsonify(w, http.StatusOK, &SearchResponse{
+		Success: true,
+		Files:   files,
+	})
+}
@@ -0,0 +1,79 @@
+package endpoints
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchEndpoint(t *testing.T) {
+	withState(t, func(s *testState) {
+		require.Nil(t, s.fs.Stage("/hello/world.png", bytes.NewReader([]byte("Hello world"))))
+		require.Nil(t, s.fs.Stage("/hello/world.txt", bytes.NewReader([]byte("Hello world"))))
+		require.Nil(t, s.fs.Stage("/secret/world.png", bytes.NewReader([]byte("Hello world"))))
+		s.mustChangeFolders(t, "/hello")
+
+		resp := s.mustRun(
+			t,
+			NewSearchHandler(s.State),
+			"POST",
+			"http://localhost:5000/api/v0/search",
+			&SearchRequest{
+				Root:  "/",
+				Query: "name:*.png",
+			},
+		)
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		searchResp := &SearchResponse{}
+		mustDecodeBody(t, resp.Body, &searchResp)
+
+		require.True(t, searchResp.Success)
+		require.Len(t, searchResp.Files, 1)
+		require.Equal(t, "/hello/world.png", searchResp.Files[0].Path)
+
+		resp = s.mustRun(
+			t,
+			NewSearchHandler(s.State),
+			"POST",
+			"http://localhost:5000/api/v0/search",
+			&SearchRequest{
+				Root:  "/",
+				Query: "size>big",
+			},
+		)
+
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestSearchEndpointContentLimitedFolders(t *testing.T) {
+	withState(t, func(s *testState) {
+		require.Nil(t, s.fs.Stage("/hello/world.txt", bytes.NewReader([]byte("Hello world"))))
+		require.Nil(t, s.fs.Stage("/secret/world.txt", bytes.NewReader([]byte("Hello world"))))
+		s.mustChangeFolders(t, "/hello")
+
+		resp := s.mustRun(
+			t,
+			NewSearchHandler(s.State),
+			"POST",
+			"http://localhost:5000/api/v0/search",
+			&SearchRequest{
+				Root:  "/",
+				Query: "mime:text/*",
+			},
+		)
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		searchResp := &SearchResponse{}
+		mustDecodeBody(t, resp.Body, &searchResp)
+
+		require.True(t, searchResp.Success)
+		require.Len(t, searchResp.Files, 1)
+		require.Equal(t, "/hello/world.txt", searchResp.Files[0].Path)
+	})
+}
@@ -0,0 +1,198 @@
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sahib/brig/gateway/db"
+	log "github.com/sirupsen/logrus"
+)
+
+// SharePrefix is the route below which share links are served.
+const SharePrefix = "/share"
+
+// ShareLink is the same as db.ShareLink, but JSON friendly
+// and without the password hash.
+type ShareLink struct {
+	Token        string `json:"token"`
+	Path         string `json:"path"`
+	Rev          string `json:"rev"`
+	CreatedAt    int64  `json:"created_at"`
+	ExpiresAt    int64  `json:"expires_at"`
+	MaxDownloads uint64 `json:"max_downloads"`
+	Downloads    uint64 `json:"downloads"`
+	HasPassword  bool   `json:"has_password"`
+	IsExpired    bool   `json:"is_expired"`
+}
+
+func toExternalShareLink(link db.ShareLink, now time.Time) ShareLink {
+	ext := ShareLink{
+		Token:        link.Token,
+		Path:         link.Path,
+		Rev:          link.Rev,
+		CreatedAt:    link.CreatedAt.Unix() * 1000,
+		MaxDownloads: link.MaxDownloads,
+		Downloads:    link.Downloads,
+		HasPassword:  link.HasPassword(),
+		IsExpired:    link.IsExpired(now),
+	}
+
+	if !link.ExpiresAt.IsZero() {
+		ext.ExpiresAt = link.ExpiresAt.Unix() * 1000
+	}
+
+	return ext
+}
+
+// CreateShare stores a new share link to `link.Path`. The revision is
+// resolved to a commit hash, so the link keeps showing the same state
+// when new commits are made.
+func (s *State) CreateShare(link db.ShareLink, password string) (db.ShareLink, error) {
+	link.Path = prefixRoot(path.Clean(link.Path))
+	if link.Rev != "" {
+		cmt, err := s.fs.CommitInfo(link.Rev)
+		if err != nil {
+			return db.ShareLink{}, err
+		}
+
+		if cmt == nil {
+			return db.ShareLink{}, fmt.Errorf("no such revision: %s", link.Rev)
+		}
+
+		link.Rev = cmt.Hash.B58String()
+	}
+
+	if _, err := s.fs.StatAt(link.Rev, link.Path); err != nil {
+		return db.ShareLink{}, err
+	}
+
+	return s.userDb.AddShare(link, password)
+}
+
+// checkShareManagement checks if the user of `r` may create, list or revoke
+// share links and returns it. The anonymous user is shared by everyone,
+// so it can't be the owner of share links.
+func (s *State) checkShareManagement(w http.ResponseWriter, r *http.Request) (db.User, bool) {
+	if !checkRights(w, r, db.RightDownload) {
+		return db.User{}, false
+	}
+
+	user, ok := userFromRequest(r)
+	if !ok {
+		jsonifyErrf(w, http.StatusInternalServerError, "could not cast user")
+		return db.User{}, false
+	}
+
+	if s.isAnonUser(user) {
+		jsonifyErrf(w, http.StatusForbidden, "anonymous users can not manage share links")
+		return db.User{}, false
+	}
+
+	return user, true
+}
+
+// ownerMayShare checks if the owner of `link` may still download
+// `nodePath`. Links stop working when their owner loses access.
+func (s *State) ownerMayShare(link db.ShareLink, nodePath string) bool {
+	if link.Owner == "" {
+		// Created via the command line.
+		return true
+	}
+
+	user, err := s.userDb.Get(link.Owner)
+	if err != nil {
+		return false
+	}
+
+	for _, right := range user.Rights {
+		if right == db.RightDownload {
+			return s.validatePathForUser(nodePath, user, nil, nil)
+		}
+	}
+
+	return false
+}
+
+// ShareHandler serves the files behind share links.
+// No login is needed, only the password of the link (if any).
+type ShareHandler struct {
+	*State
+}
+
+// NewShareHandler returns a new ShareHandler
+func NewShareHandler(s *State) *ShareHandler {
+	return &ShareHandler{State: s}
+}
+
+func (sh *ShareHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The url looks like /share/<token>[/<path below a shared directory>]
+	token := strings.TrimPrefix(r.URL.Path, SharePrefix+"/")
+	subPath := "/"
+	if idx := strings.Index(token, "/"); idx >= 0 {
+		token, subPath = token[:idx], token[idx:]
+	}
+
+	link, err := sh.userDb.GetShare(token)
+	if err != nil {
+		if err == db.ErrNoSuchShare {
+			http.Error(w, "no such share link", http.StatusNotFound)
+			return
+		}
+
+		log.Errorf("gateway: failed to get share link: %v", err)
+		http.Error(w, "failed to get share link", http.StatusInternalServerError)
+		return
+	}
+
+	if link.IsExpired(time.Now()) {
+		http.Error(w, "share link expired", http.StatusGone)
+		return
+	}
+
+	if link.HasPassword() {
+		// Like for /get, a browser will show a password form.
+		// The user name does not matter.
+		_, password, _ := r.BasicAuth()
+		isValid, err := link.CheckPassword(password)
+		if err != nil {
+			log.Warningf("share: failed to check password: %v", err)
+		}
+
+		if !isValid {
+			w.Header().Set("WWW-Authenticate", "Basic realm=\"brig share\"")
+			http.Error(w, "password required", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	// path.Clean() makes sure that subPath can't leave the shared directory.
+	nodePath := path.Join(link.Path, path.Clean(subPath))
+	if !sh.ownerMayShare(link, nodePath) {
+		http.Error(w, "share link is not valid anymore", http.StatusGone)
+		return
+	}
+
+	if _, err := sh.fs.StatAt(link.Rev, nodePath); err != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	// Only actual downloads count:
+	if r.Method != http.MethodHead {
+		if _, err := sh.userDb.UseShare(token, time.Now()); err != nil {
+			if err == db.ErrShareExpired {
+				http.Error(w, "share link expired", http.StatusGone)
+				return
+			}
+
+			log.Errorf("gateway: failed to update share link: %v", err)
+			http.Error(w, "failed to update share link", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	sh.serveNode(w, r, link.Rev, nodePath)
+}
@@ -0,0 +1,86 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sahib/brig/gateway/db"
+	log "github.com/sirupsen/logrus"
+)
+
+// ShareCreateHandler implements http.Handler
+type ShareCreateHandler struct {
+	*State
+}
+
+// NewShareCreateHandler returns a new ShareCreateHandler
+func NewShareCreateHandler(s *State) *ShareCreateHandler {
+	return &ShareCreateHandler{State: s}
+}
+
+// ShareCreateRequest is the request sent to this endpoint.
+type ShareCreateRequest struct {
+	Path string `json:"path"`
+	// Rev is optional; if empty the current state is shared.
+	Rev string `json:"rev"`
+	// ExpiresIn is the lifetime of the link in seconds; 0 means forever.
+	ExpiresIn int64 `json:"expires_in"`
+	// MaxDownloads is 0 for an unlimited number of downloads.
+	MaxDownloads uint64 `json:"max_downloads"`
+	// Password is optional.
+	Password string `json:"password"`
+}
+
+// ShareCreateResponse is the response sent back by this endpoint.
+type ShareCreateResponse struct {
+	Success bool      `json:"success"`
+	Link    ShareLink `json:"link"`
+}
+
+func (sh *ShareCreateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := sh.checkShareManagement(w, r)
+	if !ok {
+		return
+	}
+
+	shareReq := ShareCreateRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&shareReq); err != nil {
+		jsonifyErrf(w, http.StatusBadRequest, "bad json")
+		return
+	}
+
+	if shareReq.ExpiresIn < 0 {
+		jsonifyErrf(w, http.StatusBadRequest, "negative expiry")
+		return
+	}
+
+	path := prefixRoot(shareReq.Path)
+	if !sh.validatePathForUser(path, user, w, r) {
+		jsonifyErrf(w, http.StatusUnauthorized, "path forbidden")
+		return
+	}
+
+	link := db.ShareLink{
+		Path:         path,
+		Rev:          shareReq.Rev,
+		Owner:        user.Name,
+		MaxDownloads: shareReq.MaxDownloads,
+	}
+
+	if shareReq.ExpiresIn > 0 {
+		link.ExpiresAt = time.Now().Add(time.Duration(shareReq.ExpiresIn) * time.Second)
+	}
+
+	link, err := sh.CreateShare(link, shareReq.Password)
+	if err != nil {
+		log.Debugf("failed to create share link for %s: %v", path, err)
+		jsonifyErrf(w, http.StatusBadRequest, "failed to create share link")
+		return
+	}
+
+	jsonify(w, http.StatusOK, &ShareCreateResponse{
+		Success: true,
+		Link:    toExternalShareLink(link, time.Now()),
+	})
+}
@@ -0,0 +1,94 @@
+package endpoints
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareCreateEndpoint(t *testing.T) {
+	withState(t, func(s *testState) {
+		require.Nil(t, s.fs.Stage("/file", bytes.NewReader([]byte("hello"))))
+
+		resp := s.mustRun(
+			t,
+			NewShareCreateHandler(s.State),
+			"POST",
+			"http://localhost:5000/api/v0/share/create",
+			&ShareCreateRequest{
+				Path:         "/file",
+				ExpiresIn:    3600,
+				MaxDownloads: 10,
+				Password:     "pass",
+			},
+		)
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		shareResp := ShareCreateResponse{}
+		mustDecodeBody(t, resp.Body, &shareResp)
+		require.True(t, shareResp.Success)
+		require.NotEmpty(t, shareResp.Link.Token)
+		require.Equal(t, "/file", shareResp.Link.Path)
+		require.Equal(t, uint64(10), shareResp.Link.MaxDownloads)
+		require.True(t, shareResp.Link.HasPassword)
+		require.True(t, shareResp.Link.ExpiresAt > shareResp.Link.CreatedAt)
+
+		link, err := s.userDb.GetShare(shareResp.Link.Token)
+		require.Nil(t, err)
+		require.Equal(t, "ali", link.Owner)
+	})
+}
+
+func TestShareCreateEndpointForbidden(t *testing.T) {
+	withState(t, func(s *testState) {
+		require.Nil(t, s.fs.Stage("/file", bytes.NewReader([]byte("hello"))))
+		s.mustChangeFolders(t, "/public")
+
+		resp := s.mustRun(
+			t,
+			NewShareCreateHandler(s.State),
+			"POST",
+			"http://localhost:5000/api/v0/share/create",
+			&ShareCreateRequest{
+				Path: "/file",
+			},
+		)
+
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestShareCreateEndpointAnon(t *testing.T) {
+	withState(t, func(s *testState) {
+		require.Nil(t, s.fs.Stage("/file", bytes.NewReader([]byte("hello"))))
+
+		// Pretend that ali is the anonymous user:
+		s.cfg.SetBool("auth.anon_allowed", true)
+		s.cfg.SetString("auth.anon_user", "ali")
+
+		handlers := map[string]http.Handler{
+			"create": NewShareCreateHandler(s.State),
+			"list":   NewShareListHandler(s.State),
+			"revoke": NewShareRevokeHandler(s.State),
+		}
+
+		for name, hdl := range handlers {
+			resp := s.mustRun(
+				t,
+				hdl,
+				"POST",
+				"http://localhost:5000/api/v0/share/"+name,
+				&ShareCreateRequest{Path: "/file"},
+			)
+
+			require.Equal(t, http.StatusForbidden, resp.StatusCode, name)
+		}
+
+		links, err := s.userDb.ListShares()
+		require.Nil(t, err)
+		require.Empty(t, links)
+	})
+}
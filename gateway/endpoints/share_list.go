@@ -0,0 +1,56 @@
+package endpoints
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ShareListHandler implements http.Handler
+type ShareListHandler struct {
+	*State
+}
+
+// NewShareListHandler returns a new ShareListHandler
+func NewShareListHandler(s *State) *ShareListHandler {
+	return &ShareListHandler{State: s}
+}
+
+// ShareListResponse is the response sent back by this endpoint.
+type ShareListResponse struct {
+	Success bool        `json:"success"`
+	Links   []ShareLink `json:"links"`
+}
+
+func (sh *ShareListHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := sh.checkShareManagement(w, r)
+	if !ok {
+		return
+	}
+	links, err := sh.userDb.ListShares()
+	if err != nil {
+		log.Debugf("failed to list share links: %v", err)
+		jsonifyErrf(w, http.StatusInternalServerError, "failed to list share links")
+		return
+	}
+
+	// Users only see the links they created themselves.
+	now := time.Now()
+	extLinks := []ShareLink{}
+	for _, link := range links {
+		if link.Owner == user.Name {
+			extLinks = append(extLinks, toExternalShareLink(link, now))
+		}
+	}
+
+	sort.Slice(extLinks, func(i, j int) bool {
+		return extLinks[i].CreatedAt > extLinks[j].CreatedAt
+	})
+
+	jsonify(w, http.StatusOK, &ShareListResponse{
+		Success: true,
+		Links:   extLinks,
+	})
+}
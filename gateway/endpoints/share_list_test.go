@@ -0,0 +1,39 @@
+package endpoints
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/sahib/brig/gateway/db"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareListEndpoint(t *testing.T) {
+	withState(t, func(s *testState) {
+		require.Nil(t, s.fs.Stage("/file", bytes.NewReader([]byte("hello"))))
+
+		own, err := s.CreateShare(db.ShareLink{Path: "/file", Owner: "ali"}, "")
+		require.Nil(t, err)
+
+		_, err = s.CreateShare(db.ShareLink{Path: "/file", Owner: "bob"}, "")
+		require.Nil(t, err)
+
+		resp := s.mustRun(
+			t,
+			NewShareListHandler(s.State),
+			"POST",
+			"http://localhost:5000/api/v0/share/list",
+			nil,
+		)
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		listResp := ShareListResponse{}
+		mustDecodeBody(t, resp.Body, &listResp)
+		require.True(t, listResp.Success)
+		require.Len(t, listResp.Links, 1)
+		require.Equal(t, own.Token, listResp.Links[0].Token)
+		require.False(t, listResp.Links[0].IsExpired)
+	})
+}
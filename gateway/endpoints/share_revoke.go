@@ -0,0 +1,51 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ShareRevokeHandler implements http.Handler
+type ShareRevokeHandler struct {
+	*State
+}
+
+// NewShareRevokeHandler returns a new ShareRevokeHandler
+func NewShareRevokeHandler(s *State) *ShareRevokeHandler {
+	return &ShareRevokeHandler{State: s}
+}
+
+// ShareRevokeRequest is the request sent to this endpoint.
+type ShareRevokeRequest struct {
+	Token string `json:"token"`
+}
+
+func (sh *ShareRevokeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := sh.checkShareManagement(w, r)
+	if !ok {
+		return
+	}
+
+	revokeReq := ShareRevokeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&revokeReq); err != nil {
+		jsonifyErrf(w, http.StatusBadRequest, "bad json")
+		return
+	}
+
+	// Do not tell if a link exists when it is not owned by the user.
+	link, err := sh.userDb.GetShare(revokeReq.Token)
+	if err != nil || link.Owner != user.Name {
+		jsonifyErrf(w, http.StatusBadRequest, "no such share link")
+		return
+	}
+
+	if err := sh.userDb.RemoveShare(link.Token); err != nil {
+		log.Debugf("failed to revoke share link: %v", err)
+		jsonifyErrf(w, http.StatusInternalServerError, "failed to revoke share link")
+		return
+	}
+
+	jsonifySuccess(w)
+}
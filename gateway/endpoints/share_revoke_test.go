@@ -0,0 +1,49 @@
+package endpoints
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/sahib/brig/gateway/db"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareRevokeEndpoint(t *testing.T) {
+	withState(t, func(s *testState) {
+		require.Nil(t, s.fs.Stage("/file", bytes.NewReader([]byte("hello"))))
+
+		own, err := s.CreateShare(db.ShareLink{Path: "/file", Owner: "ali"}, "")
+		require.Nil(t, err)
+
+		other, err := s.CreateShare(db.ShareLink{Path: "/file", Owner: "bob"}, "")
+		require.Nil(t, err)
+
+		resp := s.mustRun(
+			t,
+			NewShareRevokeHandler(s.State),
+			"POST",
+			"http://localhost:5000/api/v0/share/revoke",
+			&ShareRevokeRequest{Token: own.Token},
+		)
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		_, err = s.userDb.GetShare(own.Token)
+		require.Equal(t, db.ErrNoSuchShare, err)
+
+		// Links of other users can't be revoked:
+		resp = s.mustRun(
+			t,
+			NewShareRevokeHandler(s.State),
+			"POST",
+			"http://localhost:5000/api/v0/share/revoke",
+			&ShareRevokeRequest{Token: other.Token},
+		)
+
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		_, err = s.userDb.GetShare(other.Token)
+		require.Nil(t, err)
+	})
+}
@@ -0,0 +1,127 @@
+package endpoints
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sahib/brig/gateway/db"
+	"github.com/stretchr/testify/require"
+)
+
+func mustRunShare(t *testing.T, hdl http.Handler, verb, url, password string) (*http.Response, []byte) {
+	req := httptest.NewRequest(verb, url, nil)
+	if password != "" {
+		req.SetBasicAuth("", password)
+	}
+
+	rsw := httptest.NewRecorder()
+	hdl.ServeHTTP(rsw, req)
+
+	resp := rsw.Result()
+	data, err := ioutil.ReadAll(resp.Body)
+	require.Nil(t, err)
+	return resp, data
+}
+
+func TestShareEndpoint(t *testing.T) {
+	withState(t, func(s *testState) {
+		require.Nil(t, s.fs.Stage("/dir/file", bytes.NewReader([]byte("v1"))))
+		require.Nil(t, s.fs.MakeCommit("v1"))
+		require.Nil(t, s.fs.Stage("/dir/file", bytes.NewReader([]byte("v2"))))
+		require.Nil(t, s.fs.Stage("/secret", bytes.NewReader([]byte("secret"))))
+
+		hdl := NewShareHandler(s.State)
+
+		link, err := s.CreateShare(db.ShareLink{Path: "/dir"}, "")
+		require.Nil(t, err)
+
+		resp, data := mustRunShare(t, hdl, "GET", "http://localhost:5000/share/"+link.Token+"/file", "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, []byte("v2"), data)
+
+		// Directories are sent as tar:
+		resp, _ = mustRunShare(t, hdl, "GET", "http://localhost:5000/share/"+link.Token, "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Contains(t, resp.Header.Get("Content-Disposition"), "dir.tar")
+
+		// It should not be possible to leave the shared directory:
+		resp, _ = mustRunShare(t, hdl, "GET", "http://localhost:5000/share/"+link.Token+"/../secret", "")
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, _ = mustRunShare(t, hdl, "GET", "http://localhost:5000/share/nope/file", "")
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		// Links to a revision keep showing the old state:
+		link, err = s.CreateShare(db.ShareLink{Path: "/dir/file", Rev: "HEAD"}, "")
+		require.Nil(t, err)
+		require.NotEqual(t, "HEAD", link.Rev)
+
+		require.Nil(t, s.fs.MakeCommit("v2"))
+		resp, data = mustRunShare(t, hdl, "GET", "http://localhost:5000/share/"+link.Token, "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, []byte("v1"), data)
+
+		_, err = s.CreateShare(db.ShareLink{Path: "/nope"}, "")
+		require.NotNil(t, err)
+	})
+}
+
+func TestShareEndpointLimits(t *testing.T) {
+	withState(t, func(s *testState) {
+		require.Nil(t, s.fs.Stage("/file", bytes.NewReader([]byte("hello"))))
+		hdl := NewShareHandler(s.State)
+
+		link, err := s.CreateShare(db.ShareLink{Path: "/file", MaxDownloads: 1}, "pass")
+		require.Nil(t, err)
+
+		url := "http://localhost:5000/share/" + link.Token
+		resp, _ := mustRunShare(t, hdl, "GET", url, "")
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		require.NotEmpty(t, resp.Header.Get("WWW-Authenticate"))
+
+		resp, _ = mustRunShare(t, hdl, "GET", url, "wrong")
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		// HEAD requests do not count as download:
+		resp, _ = mustRunShare(t, hdl, "HEAD", url, "pass")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, data := mustRunShare(t, hdl, "GET", url, "pass")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, []byte("hello"), data)
+
+		resp, _ = mustRunShare(t, hdl, "GET", url, "pass")
+		require.Equal(t, http.StatusGone, resp.StatusCode)
+
+		link, err = s.CreateShare(db.ShareLink{
+			Path:      "/file",
+			ExpiresAt: time.Now().Add(-time.Minute),
+		}, "")
+		require.Nil(t, err)
+
+		resp, _ = mustRunShare(t, hdl, "GET", "http://localhost:5000/share/"+link.Token, "")
+		require.Equal(t, http.StatusGone, resp.StatusCode)
+	})
+}
+
+func TestShareEndpointOwnerLostAccess(t *testing.T) {
+	withState(t, func(s *testState) {
+		require.Nil(t, s.fs.Stage("/file", bytes.NewReader([]byte("hello"))))
+		hdl := NewShareHandler(s.State)
+
+		link, err := s.CreateShare(db.ShareLink{Path: "/file", Owner: "ali"}, "")
+		require.Nil(t, err)
+
+		url := "http://localhost:5000/share/" + link.Token
+		resp, _ := mustRunShare(t, hdl, "GET", url, "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		s.mustChangeFolders(t, "/public")
+		resp, _ = mustRunShare(t, hdl, "GET", url, "")
+		require.Equal(t, http.StatusGone, resp.StatusCode)
+	})
+}
@@ -88,7 +88,16 @@ func mustDecodeBody(t *testing.T, body io.Reader, v interface{}) {
 }
 
 func (s *testState) mustRun(t *testing.T, hdl http.Handler, verb, url string, jsonBody interface{}) *http.Response {
+	return s.mustRunWithHeaders(t, hdl, verb, url, nil, jsonBody)
+}
+
+// mustRunWithHeaders is like mustRun, but sets `headers` on the request.
+func (s *testState) mustRunWithHeaders(t *testing.T, hdl http.Handler, verb, url string, headers map[string]string, jsonBody interface{}) *http.Response {
 	req := httptest.NewRequest(verb, url, mustEncodeBody(t, jsonBody))
+	for key, val := range headers {
+		req.Header.Set(key, val)
+	}
+
 	rsw := httptest.NewRecorder()
 
 	user, err := s.userDb.Get("ali")
@@ -100,6 +109,16 @@ func (s *testState) mustRun(t *testing.T, hdl http.Handler, verb, url string, js
 	return rsw.Result()
 }
 
+// mustRunWithToken is like mustRun, but sends `token` as bearer token
+// instead of faking a login. `hdl` needs to do the auth handling itself.
+func (s *testState) mustRunWithToken(t *testing.T, hdl http.Handler, verb, url, token string, jsonBody interface{}) *http.Response {
+	req := httptest.NewRequest(verb, url, mustEncodeBody(t, jsonBody))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rsw := httptest.NewRecorder()
+	hdl.ServeHTTP(rsw, req)
+	return rsw.Result()
+}
+
 func (s *testState) mustChangeFolders(t *testing.T, folders ...string) {
 	require.Nil(t, s.userDb.Remove("ali"))
 	require.Nil(t, s.userDb.Add("ali", "ila", folders, nil))
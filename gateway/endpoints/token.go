@@ -0,0 +1,51 @@
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/sahib/brig/gateway/db"
+)
+
+// APIToken is the same as db.APIToken, but JSON friendly
+// and without the hash.
+type APIToken struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Rights    []string `json:"rights"`
+	Folders   []string `json:"folders"`
+	CreatedAt int64    `json:"created_at"`
+}
+
+func toExternalAPIToken(token db.APIToken) APIToken {
+	return APIToken{
+		ID:        token.ID,
+		Name:      token.Name,
+		Rights:    token.Rights,
+		Folders:   token.Folders,
+		CreatedAt: token.CreatedAt.Unix() * 1000,
+	}
+}
+
+// checkTokenManagement checks if the user of the request may manage
+// api tokens and returns it. This is not possible with an api token
+// itself (or a leaked token could be used to create new ones) and not
+// as anonymous user (who is shared by everyone).
+func (s *State) checkTokenManagement(w http.ResponseWriter, r *http.Request) (db.User, bool) {
+	if _, ok := bearerToken(r); ok {
+		jsonifyErrf(w, http.StatusForbidden, "api tokens can not manage api tokens")
+		return db.User{}, false
+	}
+
+	user, ok := userFromRequest(r)
+	if !ok {
+		jsonifyErrf(w, http.StatusInternalServerError, "could not cast user")
+		return db.User{}, false
+	}
+
+	if s.isAnonUser(user) {
+		jsonifyErrf(w, http.StatusForbidden, "anonymous users can not have api tokens")
+		return db.User{}, false
+	}
+
+	return user, true
+}
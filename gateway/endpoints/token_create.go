@@ -0,0 +1,67 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// TokenCreateHandler implements http.Handler
+type TokenCreateHandler struct {
+	*State
+}
+
+// NewTokenCreateHandler returns a new TokenCreateHandler
+func NewTokenCreateHandler(s *State) *TokenCreateHandler {
+	return &TokenCreateHandler{State: s}
+}
+
+// TokenCreateRequest is the request sent to this endpoint.
+type TokenCreateRequest struct {
+	Name string `json:"name"`
+	// Rights and Folders are optional; if empty the token
+	// may do everything the user may do.
+	Rights  []string `json:"rights"`
+	Folders []string `json:"folders"`
+}
+
+// TokenCreateResponse is the response sent back by this endpoint.
+// Secret is only sent once and can not be retrieved later.
+type TokenCreateResponse struct {
+	Success bool     `json:"success"`
+	Secret  string   `json:"secret"`
+	Token   APIToken `json:"token"`
+}
+
+func (th *TokenCreateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := th.checkTokenManagement(w, r)
+	if !ok {
+		return
+	}
+
+	tokenReq := TokenCreateRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&tokenReq); err != nil {
+		jsonifyErrf(w, http.StatusBadRequest, "bad json")
+		return
+	}
+
+	secret, token, err := th.userDb.AddAPIToken(
+		user.Name,
+		tokenReq.Name,
+		tokenReq.Rights,
+		tokenReq.Folders,
+	)
+
+	if err != nil {
+		log.Debugf("failed to create api token for %s: %v", user.Name, err)
+		jsonifyErrf(w, http.StatusBadRequest, "failed to create api token")
+		return
+	}
+
+	jsonify(w, http.StatusOK, &TokenCreateResponse{
+		Success: true,
+		Secret:  secret,
+		Token:   toExternalAPIToken(token),
+	})
+}
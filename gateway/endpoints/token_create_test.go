@@ -0,0 +1,72 @@
+package endpoints
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sahib/brig/gateway/db"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenCreateEndpoint(t *testing.T) {
+	withState(t, func(s *testState) {
+		resp := s.mustRun(
+			t,
+			NewTokenCreateHandler(s.State),
+			"POST",
+			"http://localhost:5000/api/v0/token/create",
+			&TokenCreateRequest{
+				Name:    "ci",
+				Rights:  []string{db.RightFsView},
+				Folders: []string{"/public"},
+			},
+		)
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		tokenResp := TokenCreateResponse{}
+		mustDecodeBody(t, resp.Body, &tokenResp)
+		require.True(t, tokenResp.Success)
+		require.Equal(t, "ci", tokenResp.Token.Name)
+		require.Equal(t, []string{db.RightFsView}, tokenResp.Token.Rights)
+		require.Equal(t, []string{"/public"}, tokenResp.Token.Folders)
+
+		user, err := s.userDb.UserByAPIToken(tokenResp.Secret)
+		require.Nil(t, err)
+		require.Equal(t, "ali", user.Name)
+		require.Equal(t, []string{db.RightFsView}, user.Rights)
+
+		// Tokens can't be used to create more tokens:
+		resp = s.mustRunWithToken(
+			t,
+			AuthMiddleware(s.State)(NewTokenCreateHandler(s.State)),
+			"POST",
+			"http://localhost:5000/api/v0/token/create",
+			tokenResp.Secret,
+			&TokenCreateRequest{Name: "more"},
+		)
+
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
+
+func TestTokenCreateEndpointBadRight(t *testing.T) {
+	withState(t, func(s *testState) {
+		resp := s.mustRun(
+			t,
+			NewTokenCreateHandler(s.State),
+			"POST",
+			"http://localhost:5000/api/v0/token/create",
+			&TokenCreateRequest{
+				Name:   "ci",
+				Rights: []string{"fs.fly"},
+			},
+		)
+
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		user, err := s.userDb.Get("ali")
+		require.Nil(t, err)
+		require.Len(t, user.APITokens, 0)
+	})
+}
@@ -0,0 +1,48 @@
+package endpoints
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// TokenListHandler implements http.Handler
+type TokenListHandler struct {
+	*State
+}
+
+// NewTokenListHandler returns a new TokenListHandler
+func NewTokenListHandler(s *State) *TokenListHandler {
+	return &TokenListHandler{State: s}
+}
+
+// TokenListResponse is the response sent back by this endpoint.
+type TokenListResponse struct {
+	Success bool       `json:"success"`
+	Tokens  []APIToken `json:"tokens"`
+}
+
+func (th *TokenListHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reqUser, ok := th.checkTokenManagement(w, r)
+	if !ok {
+		return
+	}
+
+	// Load the user again, the one of the request might be outdated.
+	user, err := th.userDb.Get(reqUser.Name)
+	if err != nil {
+		log.Debugf("failed to get user %s: %v", reqUser.Name, err)
+		jsonifyErrf(w, http.StatusInternalServerError, "failed to list api tokens")
+		return
+	}
+
+	tokens := []APIToken{}
+	for _, token := range user.APITokens {
+		tokens = append(tokens, toExternalAPIToken(token))
+	}
+
+	jsonify(w, http.StatusOK, &TokenListResponse{
+		Success: true,
+		Tokens:  tokens,
+	})
+}
@@ -0,0 +1,36 @@
+package endpoints
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenListEndpoint(t *testing.T) {
+	withState(t, func(s *testState) {
+		_, token, err := s.userDb.AddAPIToken("ali", "ci", nil, nil)
+		require.Nil(t, err)
+
+		require.Nil(t, s.userDb.Add("bob", "bob", nil, nil))
+		_, _, err = s.userDb.AddAPIToken("bob", "backup", nil, nil)
+		require.Nil(t, err)
+
+		resp := s.mustRun(
+			t,
+			NewTokenListHandler(s.State),
+			"POST",
+			"http://localhost:5000/api/v0/token/list",
+			nil,
+		)
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		listResp := TokenListResponse{}
+		mustDecodeBody(t, resp.Body, &listResp)
+		require.True(t, listResp.Success)
+		require.Len(t, listResp.Tokens, 1)
+		require.Equal(t, token.ID, listResp.Tokens[0].ID)
+		require.Equal(t, "ci", listResp.Tokens[0].Name)
+	})
+}
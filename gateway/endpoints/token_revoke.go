@@ -0,0 +1,52 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sahib/brig/gateway/db"
+	log "github.com/sirupsen/logrus"
+)
+
+// TokenRevokeHandler implements http.Handler
+type TokenRevokeHandler struct {
+	*State
+}
+
+// NewTokenRevokeHandler returns a new TokenRevokeHandler
+func NewTokenRevokeHandler(s *State) *TokenRevokeHandler {
+	return &TokenRevokeHandler{State: s}
+}
+
+// TokenRevokeRequest is the request sent to this endpoint.
+type TokenRevokeRequest struct {
+	ID string `json:"id"`
+}
+
+func (th *TokenRevokeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := th.checkTokenManagement(w, r)
+	if !ok {
+		return
+	}
+
+	revokeReq := TokenRevokeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&revokeReq); err != nil {
+		jsonifyErrf(w, http.StatusBadRequest, "bad json")
+		return
+	}
+
+	// Users can only revoke their own tokens, since
+	// the token is only searched in the user's list.
+	if err := th.userDb.RemoveAPIToken(user.Name, revokeReq.ID); err != nil {
+		if err == db.ErrNoSuchAPIToken {
+			jsonifyErrf(w, http.StatusBadRequest, "no such api token")
+			return
+		}
+
+		log.Debugf("failed to revoke api token: %v", err)
+		jsonifyErrf(w, http.StatusInternalServerError, "failed to revoke api token")
+		return
+	}
+
+	jsonifySuccess(w)
+}
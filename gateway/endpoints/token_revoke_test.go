@@ -0,0 +1,47 @@
+package endpoints
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sahib/brig/gateway/db"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenRevokeEndpoint(t *testing.T) {
+	withState(t, func(s *testState) {
+		secret, token, err := s.userDb.AddAPIToken("ali", "ci", nil, nil)
+		require.Nil(t, err)
+
+		require.Nil(t, s.userDb.Add("bob", "bob", nil, nil))
+		otherSecret, other, err := s.userDb.AddAPIToken("bob", "backup", nil, nil)
+		require.Nil(t, err)
+
+		resp := s.mustRun(
+			t,
+			NewTokenRevokeHandler(s.State),
+			"POST",
+			"http://localhost:5000/api/v0/token/revoke",
+			&TokenRevokeRequest{ID: token.ID},
+		)
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		_, err = s.userDb.UserByAPIToken(secret)
+		require.Equal(t, db.ErrNoSuchAPIToken, err)
+
+		// Tokens of other users can't be revoked:
+		resp = s.mustRun(
+			t,
+			NewTokenRevokeHandler(s.State),
+			"POST",
+			"http://localhost:5000/api/v0/token/revoke",
+			&TokenRevokeRequest{ID: other.ID},
+		)
+
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		_, err = s.userDb.UserByAPIToken(otherSecret)
+		require.Nil(t, err)
+	})
+}
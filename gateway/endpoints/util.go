@@ -130,28 +130,46 @@ func buildFolderCache(folders []string) map[string]bool {
 	return folderCache
 }
 
-func (s *State) pathIsVisible(nodePath string, w http.ResponseWriter, r *http.Request) bool {
-	nodePath = prefixRoot(path.Clean(nodePath))
-	if s.validatePath(nodePath, w, r) {
-		return true
+// isAnonUser checks if `user` is the anonymous user,
+// which is shared by everyone that did not log in.
+func (s *State) isAnonUser(user db.User) bool {
+	return s.cfg.Bool("auth.anon_allowed") && user.Name == s.cfg.String("auth.anon_user")
+}
+
+// requestUser returns the user that sent the request. This is the user that
+// was set by the auth middleware (which also handles api tokens) or, for
+// endpoints without it, the user of the session.
+func (s *State) requestUser(w http.ResponseWriter, r *http.Request) (db.User, bool) {
+	if user, ok := userFromRequest(r); ok {
+		return user, true
 	}
 
 	name := getUserName(s.store, w, r)
 	if name == "" {
-		return false
+		return db.User{}, false
 	}
 
 	user, err := s.userDb.Get(name)
 	if err != nil {
-		return false
+		return db.User{}, false
 	}
 
-	folderCache := buildFolderCache(user.Folders)
-	if err != nil {
-		log.Debugf("failed to build folder cache: %v", err)
+	return user, true
+}
+
+func (s *State) pathIsVisible(nodePath string, w http.ResponseWriter, r *http.Request) bool {
+	nodePath = prefixRoot(path.Clean(nodePath))
+	if s.validatePath(nodePath, w, r) {
+		return true
+	}
+
+	user, ok := s.requestUser(w, r)
+	if !ok {
 		return false
 	}
 
+	folderCache := buildFolderCache(user.Folders)
+
 	// Go over all folders, and see if we have some allowed folder
 	// that we need to display "on the way". This could be probably
 	// made faster if we ever need to.
@@ -181,13 +199,8 @@ func (s *State) validatePath(nodePath string, w http.ResponseWriter, r *http.Req
 		return false
 	}
 
-	name := getUserName(s.store, w, r)
-	if name == "" {
-		return false
-	}
-
-	user, err := s.userDb.Get(name)
-	if err != nil {
+	user, ok := s.requestUser(w, r)
+	if !ok {
 		return false
 	}
 
@@ -217,6 +230,23 @@ func (s *State) validatePathForUser(nodePath string, user db.User, w http.Respon
 	return false
 }
 
+// pathIsVisibleForUser is like validatePathForUser, but also returns true
+// for all directories on the way to one of the user's folders.
+func (s *State) pathIsVisibleForUser(nodePath string, user db.User) bool {
+	if s.validatePathForUser(nodePath, user, nil, nil) {
+		return true
+	}
+
+	dirPrefix := strings.TrimSuffix(nodePath, "/") + "/"
+	for folder := range buildFolderCache(user.Folders) {
+		if strings.HasPrefix(folder, dirPrefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
 //////////////////////
 
 func jsonify(w http.ResponseWriter, statusCode int, data interface{}) {
@@ -256,22 +286,30 @@ func jsonifySuccess(w http.ResponseWriter) {
 	jsonifyErrf(w, http.StatusOK, "success")
 }
 
-func (s *State) commitChange(msg string, w http.ResponseWriter, r *http.Request) bool {
-	name := getUserName(s.store, w, r)
+// makeCommit commits all staged changes in the name of the gateway user `name`.
+func (s *State) makeCommit(ctx context.Context, name, msg string) error {
 	fullMsg := fmt.Sprintf("gateway: »%s« %s", name, msg)
-
 	if err := s.fs.MakeCommit(fullMsg); err != nil {
 		if err != ie.ErrNoChange {
-			log.Warningf("could not commit: %v", err)
-			jsonifyErrf(w, http.StatusInternalServerError, "could not commit")
-			return false
+			return err
 		}
 
 		// There was no change. No need to notify.
-		return true
+		return nil
+	}
+
+	s.evHdl.Notify(ctx, "fs")
+	return nil
+}
+
+func (s *State) commitChange(msg string, w http.ResponseWriter, r *http.Request) bool {
+	user, _ := s.requestUser(w, r)
+	if err := s.makeCommit(r.Context(), user.Name, msg); err != nil {
+		log.Warningf("could not commit: %v", err)
+		jsonifyErrf(w, http.StatusInternalServerError, "could not commit")
+		return false
 	}
 
-	s.evHdl.Notify(r.Context(), "fs")
 	return true
 }
 
@@ -0,0 +1,516 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"github.com/sahib/brig/catfs"
+	ie "github.com/sahib/brig/catfs/errors"
+	"github.com/sahib/brig/catfs/mio"
+	"github.com/sahib/brig/gateway/db"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/webdav"
+)
+
+// WebDAVPrefix is the path the WebDAV handler should be mounted at.
+const WebDAVPrefix = "/webdav"
+
+// WebDAVHandler implements http.Handler.
+// It serves the filesystem via WebDAV, so it can be mounted by file
+// managers and office suites. Clients authenticate via basic auth
+// (or an existing UI session); the user's folders and rights apply.
+type WebDAVHandler struct {
+	*State
+	lockSystem webdav.LockSystem
+}
+
+// NewWebDAVHandler returns a new WebDAVHandler.
+func NewWebDAVHandler(s *State) *WebDAVHandler {
+	return &WebDAVHandler{
+		State:      s,
+		lockSystem: webdav.NewMemLS(),
+	}
+}
+
+// webdavRights returns the rights needed for the WebDAV `method`.
+func webdavRights(method string) []string {
+	switch method {
+	case "OPTIONS", "PROPFIND":
+		return []string{db.RightFsView}
+	case "GET", "HEAD":
+		return []string{db.RightFsView, db.RightDownload}
+	default:
+		// PUT, MKCOL, MOVE, COPY, DELETE, PROPPATCH, LOCK and UNLOCK.
+		return []string{db.RightFsView, db.RightFsEdit}
+	}
+}
+
+// authenticate finds out what user sent the request. Basic auth is
+// preferred, since that's what WebDAV clients use. Without it we fall back
+// to the UI session and then to the anon user (if allowed).
+func (wh *WebDAVHandler) authenticate(w http.ResponseWriter, r *http.Request) (*db.User, bool) {
+	name, pass, ok := r.BasicAuth()
+	if ok {
+		user, err := wh.userDb.Get(name)
+		if err != nil {
+			return nil, false
+		}
+
+		isValid, err := user.CheckPassword(pass)
+		if !isValid {
+			if err != nil {
+				log.Warningf("webdav: failed to check password: %v", err)
+			}
+
+			return nil, false
+		}
+
+		return &user, true
+	}
+
+	name = getUserName(wh.store, w, r)
+	if name == "" {
+		if !wh.cfg.Bool("auth.anon_allowed") {
+			return nil, false
+		}
+
+		name = wh.cfg.String("auth.anon_user")
+	}
+
+	user, err := wh.userDb.Get(name)
+	if err != nil {
+		return nil, false
+	}
+
+	return &user, true
+}
+
+func (wh *WebDAVHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := wh.authenticate(w, r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", "Basic realm=\"brig gateway\"")
+		http.Error(w, "not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	rmap := make(map[string]bool)
+	for _, right := range user.Rights {
+		rmap[right] = true
+	}
+
+	for _, right := range webdavRights(r.Method) {
+		if !rmap[right] {
+			http.Error(w, "insufficient rights", http.StatusForbidden)
+			return
+		}
+	}
+
+	body := &webdavBody{ReadCloser: r.Body}
+	r.Body = body
+
+	hdl := &webdav.Handler{
+		Prefix: WebDAVPrefix,
+		FileSystem: &webdavFS{
+			State: wh.State,
+			user:  user,
+			body:  body,
+			w:     w,
+			r:     r,
+		},
+		LockSystem: wh.lockSystem,
+		Logger: func(r *http.Request, err error) {
+			if err != nil {
+				log.Debugf("webdav: %s %s failed: %v", r.Method, r.URL.Path, err)
+			}
+		},
+	}
+
+	hdl.ServeHTTP(w, r)
+}
+
+// webdavBody remembers if reading the request body failed,
+// so that incomplete uploads are not staged.
+type webdavBody struct {
+	io.ReadCloser
+	err error
+}
+
+func (wb *webdavBody) Read(buf []byte) (int, error) {
+	n, err := wb.ReadCloser.Read(buf)
+	if err != nil && err != io.EOF {
+		wb.err = err
+	}
+
+	return n, err
+}
+
+///////
+
+// webdavFS implements webdav.FileSystem on top of catfs.FS for a single
+// request. All paths are checked against the folders of `user`.
+type webdavFS struct {
+	*State
+	user *db.User
+	body *webdavBody
+	w    http.ResponseWriter
+	r    *http.Request
+}
+
+func toWebDAVError(err error) error {
+	if ie.IsNoSuchFileError(err) {
+		return os.ErrNotExist
+	}
+
+	return err
+}
+
+// canAccess checks if the user may read and write `nodePath`.
+func (wfs *webdavFS) canAccess(nodePath string) bool {
+	return wfs.validatePathForUser(nodePath, *wfs.user, wfs.w, wfs.r)
+}
+
+// canSee checks if the user may at least see `nodePath`, which is the case
+// for all directories on the way to one of their folders.
+func (wfs *webdavFS) canSee(nodePath string) bool {
+	return wfs.pathIsVisibleForUser(nodePath, *wfs.user)
+}
+
+func (wfs *webdavFS) commit(msg string) error {
+	return wfs.makeCommit(wfs.r.Context(), wfs.user.Name, "webdav: "+msg)
+}
+
+func (wfs *webdavFS) stat(nodePath string) (*catfs.StatInfo, error) {
+	if !wfs.canSee(nodePath) {
+		// Do not tell if there is something:
+		return nil, os.ErrNotExist
+	}
+
+	info, err := wfs.fs.Stat(nodePath)
+	return info, toWebDAVError(err)
+}
+
+func (wfs *webdavFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	nodePath := prefixRoot(path.Clean(name))
+	if !wfs.canAccess(nodePath) {
+		return os.ErrPermission
+	}
+
+	if _, err := wfs.fs.Stat(nodePath); err == nil {
+		return os.ErrExist
+	}
+
+	if err := wfs.fs.Mkdir(nodePath, false); err != nil {
+		return toWebDAVError(err)
+	}
+
+	return wfs.commit(fmt.Sprintf("mkdir'd »%s«", nodePath))
+}
+
+func (wfs *webdavFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	nodePath := prefixRoot(path.Clean(name))
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) == 0 {
+		info, err := wfs.stat(nodePath)
+		if err != nil {
+			return nil, err
+		}
+
+		return &webdavFile{wfs: wfs, info: info}, nil
+	}
+
+	if !wfs.canAccess(nodePath) {
+		return nil, os.ErrPermission
+	}
+
+	parentInfo, err := wfs.fs.Stat(path.Dir(nodePath))
+	if err != nil {
+		return nil, toWebDAVError(err)
+	}
+
+	if !parentInfo.IsDir {
+		return nil, os.ErrNotExist
+	}
+
+	if info, err := wfs.fs.Stat(nodePath); err == nil && info.IsDir {
+		return nil, os.ErrExist
+	}
+
+	// Stream the written data directly into the staging area:
+	pr, pw := io.Pipe()
+	doneCh := make(chan error, 1)
+	go func() {
+		err := wfs.fs.Stage(nodePath, pr)
+		pr.CloseWithError(err)
+		doneCh <- err
+	}()
+
+	return &webdavWriter{
+		wfs:      wfs,
+		nodePath: nodePath,
+		pw:       pw,
+		doneCh:   doneCh,
+		modTime:  time.Now(),
+	}, nil
+}
+
+func (wfs *webdavFS) RemoveAll(ctx context.Context, name string) error {
+	nodePath := prefixRoot(path.Clean(name))
+	if nodePath == "/" || !wfs.canAccess(nodePath) {
+		return os.ErrPermission
+	}
+
+	if err := wfs.fs.Remove(nodePath); err != nil {
+		return toWebDAVError(err)
+	}
+
+	return wfs.commit(fmt.Sprintf("removed »%s«", nodePath))
+}
+
+func (wfs *webdavFS) Rename(ctx context.Context, oldName, newName string) error {
+	srcPath := prefixRoot(path.Clean(oldName))
+	dstPath := prefixRoot(path.Clean(newName))
+	if srcPath == "/" || !wfs.canAccess(srcPath) || !wfs.canAccess(dstPath) {
+		return os.ErrPermission
+	}
+
+	if err := wfs.fs.Move(srcPath, dstPath); err != nil {
+		return toWebDAVError(err)
+	}
+
+	return wfs.commit(fmt.Sprintf("moved »%s« to »%s«", srcPath, dstPath))
+}
+
+func (wfs *webdavFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	info, err := wfs.stat(prefixRoot(path.Clean(name)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &webdavFileInfo{info: info}, nil
+}
+
+///////
+
+// webdavFileInfo implements os.FileInfo and the optional
+// webdav.ETager and webdav.ContentTyper interfaces.
+type webdavFileInfo struct {
+	info *catfs.StatInfo
+}
+
+func (wfi *webdavFileInfo) Name() string {
+	return path.Base(wfi.info.Path)
+}
+
+func (wfi *webdavFileInfo) Size() int64 {
+	return int64(wfi.info.Size)
+}
+
+func (wfi *webdavFileInfo) Mode() os.FileMode {
+	if wfi.info.IsDir {
+		return os.ModeDir | 0755
+	}
+
+	return 0644
+}
+
+func (wfi *webdavFileInfo) ModTime() time.Time {
+	return wfi.info.ModTime
+}
+
+func (wfi *webdavFileInfo) IsDir() bool {
+	return wfi.info.IsDir
+}
+
+func (wfi *webdavFileInfo) Sys() interface{} {
+	return nil
+}
+
+// ETag uses the content hash, like /get does.
+func (wfi *webdavFileInfo) ETag(ctx context.Context) (string, error) {
+	return fmt.Sprintf("\"%s\"", wfi.info.ContentHash.B58String()), nil
+}
+
+// ContentType guesses the mime type by the file extension only.
+// Otherwise each PROPFIND would need to fetch the content of all files.
+func (wfi *webdavFileInfo) ContentType(ctx context.Context) (string, error) {
+	if mimeType := mime.TypeByExtension(path.Ext(wfi.info.Path)); mimeType != "" {
+		return mimeType, nil
+	}
+
+	return "application/octet-stream", nil
+}
+
+///////
+
+// webdavFile is a file or directory opened for reading.
+// The content is only fetched once it is actually read.
+type webdavFile struct {
+	wfs    *webdavFS
+	info   *catfs.StatInfo
+	stream mio.Stream
+
+	children []os.FileInfo
+	childIdx int
+}
+
+func (wf *webdavFile) open() error {
+	if wf.info.IsDir {
+		return fmt.Errorf("%s is a directory", wf.info.Path)
+	}
+
+	if wf.stream != nil {
+		return nil
+	}
+
+	stream, err := wf.wfs.fs.Cat(wf.info.Path)
+	if err != nil {
+		return toWebDAVError(err)
+	}
+
+	wf.stream = stream
+	return nil
+}
+
+func (wf *webdavFile) Read(buf []byte) (int, error) {
+	if err := wf.open(); err != nil {
+		return 0, err
+	}
+
+	return wf.stream.Read(buf)
+}
+
+func (wf *webdavFile) Seek(offset int64, whence int) (int64, error) {
+	if wf.info.IsDir {
+		return 0, nil
+	}
+
+	if err := wf.open(); err != nil {
+		return 0, err
+	}
+
+	return wf.stream.Seek(offset, whence)
+}
+
+func (wf *webdavFile) Write(buf []byte) (int, error) {
+	return 0, os.ErrPermission
+}
+
+func (wf *webdavFile) Close() error {
+	if wf.stream != nil {
+		return wf.stream.Close()
+	}
+
+	return nil
+}
+
+func (wf *webdavFile) Readdir(count int) ([]os.FileInfo, error) {
+	if !wf.info.IsDir {
+		return nil, fmt.Errorf("%s is not a directory", wf.info.Path)
+	}
+
+	if wf.children == nil {
+		entries, err := wf.wfs.fs.List(wf.info.Path, 1)
+		if err != nil {
+			return nil, toWebDAVError(err)
+		}
+
+		wf.children = []os.FileInfo{}
+		for _, entry := range entries {
+			if wf.wfs.canSee(entry.Path) {
+				wf.children = append(wf.children, &webdavFileInfo{info: entry})
+			}
+		}
+	}
+
+	rest := wf.children[wf.childIdx:]
+	if count <= 0 {
+		wf.childIdx = len(wf.children)
+		return rest, nil
+	}
+
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+
+	if count > len(rest) {
+		count = len(rest)
+	}
+
+	wf.childIdx += count
+	return rest[:count], nil
+}
+
+func (wf *webdavFile) Stat() (os.FileInfo, error) {
+	return &webdavFileInfo{info: wf.info}, nil
+}
+
+///////
+
+// webdavWriter is a file opened for writing.
+// Everything written to it is staged once it is closed.
+type webdavWriter struct {
+	wfs      *webdavFS
+	nodePath string
+	pw       *io.PipeWriter
+	doneCh   chan error
+	size     int64
+	modTime  time.Time
+}
+
+func (ww *webdavWriter) Write(buf []byte) (int, error) {
+	n, err := ww.pw.Write(buf)
+	ww.size += int64(n)
+	return n, err
+}
+
+func (ww *webdavWriter) Close() error {
+	if err := ww.wfs.body.err; err != nil {
+		// The upload was aborted; make Stage() fail.
+		ww.pw.CloseWithError(err)
+		<-ww.doneCh
+		return err
+	}
+
+	if err := ww.pw.Close(); err != nil {
+		return err
+	}
+
+	if err := <-ww.doneCh; err != nil {
+		return toWebDAVError(err)
+	}
+
+	return ww.wfs.commit(fmt.Sprintf("uploaded »%s«", ww.nodePath))
+}
+
+func (ww *webdavWriter) Read(buf []byte) (int, error) {
+	return 0, os.ErrPermission
+}
+
+func (ww *webdavWriter) Seek(offset int64, whence int) (int64, error) {
+	return 0, os.ErrPermission
+}
+
+func (ww *webdavWriter) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, fmt.Errorf("%s is not a directory", ww.nodePath)
+}
+
+func (ww *webdavWriter) Stat() (os.FileInfo, error) {
+	return &webdavWriterInfo{ww: ww}, nil
+}
+
+// webdavWriterInfo describes a file that is still being written.
+type webdavWriterInfo struct {
+	ww *webdavWriter
+}
+
+func (wwi *webdavWriterInfo) Name() string       { return path.Base(wwi.ww.nodePath) }
+func (wwi *webdavWriterInfo) Size() int64        { return wwi.ww.size }
+func (wwi *webdavWriterInfo) Mode() os.FileMode  { return 0644 }
+func (wwi *webdavWriterInfo) ModTime() time.Time { return wwi.ww.modTime }
+func (wwi *webdavWriterInfo) IsDir() bool        { return false }
+func (wwi *webdavWriterInfo) Sys() interface{}   { return nil }
@@ -0,0 +1,146 @@
+package endpoints
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sahib/brig/gateway/db"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *testState) mustRunWebDAV(t *testing.T, hdl http.Handler, verb, url string, body io.Reader, hdrs map[string]string) *http.Response {
+	req := httptest.NewRequest(verb, url, body)
+	req.SetBasicAuth("ali", "ila")
+	for key, val := range hdrs {
+		req.Header.Set(key, val)
+	}
+
+	rsw := httptest.NewRecorder()
+	hdl.ServeHTTP(rsw, req)
+	return rsw.Result()
+}
+
+func TestWebDAVEndpoint(t *testing.T) {
+	withState(t, func(s *testState) {
+		hdl := NewWebDAVHandler(s.State)
+
+		resp := s.mustRunWebDAV(t, hdl, "MKCOL", "http://localhost:5000/webdav/dir", nil, nil)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		fileData := []byte("HelloWorld")
+		resp = s.mustRunWebDAV(t, hdl, "PUT", "http://localhost:5000/webdav/dir/file", bytes.NewReader(fileData), nil)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		info, err := s.fs.Stat("/dir/file")
+		require.Nil(t, err)
+		require.Equal(t, uint64(len(fileData)), info.Size)
+
+		// Each change should have been committed:
+		head, err := s.fs.CommitInfo("HEAD")
+		require.Nil(t, err)
+		require.Equal(t, "gateway: »ali« webdav: uploaded »/dir/file«", head.Msg)
+
+		resp = s.mustRunWebDAV(t, hdl, "GET", "http://localhost:5000/webdav/dir/file", nil, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		data, err := ioutil.ReadAll(resp.Body)
+		require.Nil(t, err)
+		require.Equal(t, fileData, data)
+
+		resp = s.mustRunWebDAV(t, hdl, "PROPFIND", "http://localhost:5000/webdav/dir", nil, map[string]string{
+			"Depth": "1",
+		})
+		require.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+		data, err = ioutil.ReadAll(resp.Body)
+		require.Nil(t, err)
+		require.True(t, strings.Contains(string(data), "/webdav/dir/file"))
+
+		resp = s.mustRunWebDAV(t, hdl, "COPY", "http://localhost:5000/webdav/dir/file", nil, map[string]string{
+			"Destination": "http://localhost:5000/webdav/copy",
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		resp = s.mustRunWebDAV(t, hdl, "MOVE", "http://localhost:5000/webdav/dir/file", nil, map[string]string{
+			"Destination": "http://localhost:5000/webdav/moved",
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		_, err = s.fs.Stat("/dir/file")
+		require.NotNil(t, err)
+
+		for _, path := range []string{"/copy", "/moved"} {
+			stream, err := s.fs.Cat(path)
+			require.Nil(t, err)
+			data, err := ioutil.ReadAll(stream)
+			require.Nil(t, err)
+			require.Equal(t, fileData, data)
+			require.Nil(t, stream.Close())
+		}
+
+		resp = s.mustRunWebDAV(t, hdl, "DELETE", "http://localhost:5000/webdav/dir", nil, nil)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		_, err = s.fs.Stat("/dir")
+		require.NotNil(t, err)
+	})
+}
+
+func TestWebDAVEndpointFolders(t *testing.T) {
+	withState(t, func(s *testState) {
+		require.Nil(t, s.fs.Stage("/public/file", bytes.NewReader([]byte("hello"))))
+		require.Nil(t, s.fs.Stage("/private/file", bytes.NewReader([]byte("world"))))
+		s.mustChangeFolders(t, "/public")
+
+		hdl := NewWebDAVHandler(s.State)
+
+		// The root is visible, but only shows the way to /public:
+		resp := s.mustRunWebDAV(t, hdl, "PROPFIND", "http://localhost:5000/webdav/", nil, map[string]string{
+			"Depth": "1",
+		})
+		require.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+		data, err := ioutil.ReadAll(resp.Body)
+		require.Nil(t, err)
+		require.True(t, strings.Contains(string(data), "/webdav/public"))
+		require.False(t, strings.Contains(string(data), "/webdav/private"))
+
+		resp = s.mustRunWebDAV(t, hdl, "GET", "http://localhost:5000/webdav/private/file", nil, nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp = s.mustRunWebDAV(t, hdl, "PUT", "http://localhost:5000/webdav/private/new", bytes.NewReader([]byte("x")), nil)
+		require.NotEqual(t, http.StatusCreated, resp.StatusCode)
+
+		_, err = s.fs.Stat("/private/new")
+		require.NotNil(t, err)
+
+		resp = s.mustRunWebDAV(t, hdl, "PUT", "http://localhost:5000/webdav/public/new", bytes.NewReader([]byte("x")), nil)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+}
+
+func TestWebDAVEndpointAuth(t *testing.T) {
+	withState(t, func(s *testState) {
+		hdl := NewWebDAVHandler(s.State)
+
+		// No credentials at all:
+		req := httptest.NewRequest("PROPFIND", "http://localhost:5000/webdav/", nil)
+		rsw := httptest.NewRecorder()
+		hdl.ServeHTTP(rsw, req)
+		require.Equal(t, http.StatusUnauthorized, rsw.Result().StatusCode)
+
+		// Viewers may not modify anything:
+		require.Nil(t, s.userDb.Remove("ali"))
+		require.Nil(t, s.userDb.Add("ali", "ila", nil, []string{db.RightFsView, db.RightDownload}))
+
+		resp := s.mustRunWebDAV(t, hdl, "MKCOL", "http://localhost:5000/webdav/dir", nil, nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = s.mustRunWebDAV(t, hdl, "PROPFIND", "http://localhost:5000/webdav/", nil, map[string]string{
+			"Depth": "0",
+		})
+		require.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+	})
+}
@@ -35,6 +35,15 @@ type Identity struct {
 	Fingerprint string `json:"fingerprint"`
 }
 
+// Transfer is the progress of a running metadata transfer from a remote.
+type Transfer struct {
+	Remote  string    `json:"remote"`
+	Kind    string    `json:"kind"`
+	Done    int64     `json:"done"`
+	Total   int64     `json:"total"`
+	Started time.Time `json:"started"`
+}
+
 // RemotesAPI provides a simpler interface to accessing remote information
 // from repo.Repository, net.PeerServer and events.EventListener.
 type RemotesAPI interface {
@@ -46,5 +55,7 @@ type RemotesAPI interface {
 	OnChange(fn func())
 
 	Sync(name string) error
+	SyncFromPush(name string) error
 	MakeDiff(name string) (*catfs.Diff, error)
+	Transfers() ([]Transfer, error)
 }
@@ -15,6 +15,7 @@ type Mock struct {
 	name        string
 	fingerprint string
 	remotes     map[string]*Remote
+	transfers   []Transfer
 	callbacks   []func()
 }
 
@@ -105,6 +106,12 @@ func (m *Mock) Sync(name string) error {
 	return nil
 }
 
+// SyncFromPush is like Sync, but marks the sync as triggered by a push.
+// The mock implementation does nothing currently.
+func (m *Mock) SyncFromPush(name string) error {
+	return m.Sync(name)
+}
+
 func dummyNode(path, user string, isDir bool) catfs.StatInfo {
 	return catfs.StatInfo{
 		BackendHash: h.EmptyBackendHash.Clone(),
@@ -173,3 +180,13 @@ func (m *Mock) notify() {
 func (m *Mock) OnChange(fn func()) {
 	m.callbacks = append(m.callbacks, fn)
 }
+
+// Transfers returns the transfers set by SetTransfers.
+func (m *Mock) Transfers() ([]Transfer, error) {
+	return m.transfers, nil
+}
+
+// SetTransfers sets what Transfers() will return.
+func (m *Mock) SetTransfers(transfers []Transfer) {
+	m.transfers = transfers
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/NYTimes/gziphandler"
@@ -31,6 +32,14 @@ var rate = limiter.Rate{
 	Limit:  50000,
 }
 
+// rateLimitMiddleware limits the requests per client to `rate`.
+func rateLimitMiddleware() func(http.Handler) http.Handler {
+	return stdlib.NewMiddleware(
+		limiter.New(memory.NewStore(), rate),
+		stdlib.WithForwardHeader(true),
+	).Handler
+}
+
 // Gateway is a small HTTP server that is able to serve
 // files from brig over HTTP. This can be used to share files
 // inside of brig with users that do not use brig.
@@ -40,8 +49,10 @@ type Gateway struct {
 	isReloading bool
 	state       *endpoints.State
 	evHdl       *endpoints.EventsHandler
+	s3Hdl       *endpoints.S3Handler
 
-	srv *http.Server
+	srv   *http.Server
+	s3Srv *http.Server
 }
 
 // NewGateway returns a newly built gateway.
@@ -63,6 +74,7 @@ func NewGateway(fs *catfs.FS, rapi remotesapi.RemotesAPI, cfg *config.Config, ev
 		isClosed: true,
 		cfg:      cfg,
 		evHdl:    evHdl,
+		s3Hdl:    endpoints.NewS3Handler(state),
 	}
 
 	// Restarts the gateway on the next possible idle phase:
@@ -88,6 +100,9 @@ func NewGateway(fs *catfs.FS, rapi remotesapi.RemotesAPI, cfg *config.Config, ev
 	// All other config values are read on-demand anyways.
 	cfg.AddEvent("enabled", reloader)
 	cfg.AddEvent("port", reloader)
+	cfg.AddEvent("webdav.enabled", reloader)
+	cfg.AddEvent("s3.enabled", reloader)
+	cfg.AddEvent("s3.port", reloader)
 	cfg.AddEvent("auth.session-encryption-key", reloader)
 	cfg.AddEvent("auth.session-authentication-key", reloader)
 	cfg.AddEvent("auth.session-csrf-key", reloader)
@@ -108,6 +123,14 @@ func (gw *Gateway) Stop() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 	defer cancel()
 
+	if gw.s3Srv != nil {
+		if err := gw.s3Srv.Shutdown(ctx); err != nil {
+			log.Warningf("failed to shutdown s3 server: %v", err)
+		}
+
+		gw.s3Srv = nil
+	}
+
 	if gw.srv != nil {
 		return gw.srv.Shutdown(ctx)
 	}
@@ -115,6 +138,35 @@ func (gw *Gateway) Stop() error {
 	return nil
 }
 
+// skipCsrfCheck disables the csrf check for all routes below `prefix`.
+// It has to run before the csrf middleware.
+func skipCsrfCheck(prefix string) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == prefix || strings.HasPrefix(r.URL.Path, prefix+"/") {
+				r = csrf.UnsafeSkipCheck(r)
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// skipCsrfCheckForAPITokens disables the csrf check for requests that
+// authenticate with an api token. Browsers do not add those on their own,
+// so other sites can not forge such requests.
+func skipCsrfCheckForAPITokens() mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if endpoints.HasAPIToken(r) {
+				r = csrf.UnsafeSkipCheck(r)
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
 type csrfErrorHandler struct{}
 
 func (ch *csrfErrorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -158,6 +210,13 @@ func (gw *Gateway) Start() {
 	csrfOpts = append(csrfOpts, csrf.Secure(false))
 
 	if uiEnabled {
+		// WebDAV clients can't send a csrf token.
+		// They have to authenticate with each request instead.
+		router.Use(skipCsrfCheck(endpoints.WebDAVPrefix))
+
+		// Scripts using api tokens can't send a csrf token either.
+		router.Use(skipCsrfCheckForAPITokens())
+
 		csrfKey := []byte(gw.cfg.String("auth.session-csrf-key"))
 		router.Use(csrf.Protect(csrfKey, csrfOpts...))
 
@@ -168,6 +227,7 @@ func (gw *Gateway) Start() {
 		apiRouter.Handle("/ping", endpoints.NewPingHandler(gw.state))
 		apiRouter.Handle("/logout", needsAuth(endpoints.NewLogoutHandler(gw.state)))
 		apiRouter.Handle("/ls", needsAuth(endpoints.NewLsHandler(gw.state)))
+		apiRouter.Handle("/search", needsAuth(endpoints.NewSearchHandler(gw.state)))
 		apiRouter.Handle("/upload", needsAuth(endpoints.NewUploadHandler(gw.state)))
 		apiRouter.Handle("/move", needsAuth(endpoints.NewMoveHandler(gw.state)))
 		apiRouter.Handle("/mkdir", needsAuth(endpoints.NewMkdirHandler(gw.state)))
@@ -181,6 +241,12 @@ func (gw *Gateway) Start() {
 		apiRouter.Handle("/undelete", needsAuth(endpoints.NewUndeleteHandler(gw.state)))
 		apiRouter.Handle("/pin", needsAuth(endpoints.NewPinHandler(gw.state)))
 		apiRouter.Handle("/unpin", needsAuth(endpoints.NewUnpinHandler(gw.state)))
+		apiRouter.Handle("/share/create", needsAuth(endpoints.NewShareCreateHandler(gw.state)))
+		apiRouter.Handle("/share/list", needsAuth(endpoints.NewShareListHandler(gw.state)))
+		apiRouter.Handle("/share/revoke", needsAuth(endpoints.NewShareRevokeHandler(gw.state)))
+		apiRouter.Handle("/token/create", needsAuth(endpoints.NewTokenCreateHandler(gw.state)))
+		apiRouter.Handle("/token/list", needsAuth(endpoints.NewTokenListHandler(gw.state)))
+		apiRouter.Handle("/token/revoke", needsAuth(endpoints.NewTokenRevokeHandler(gw.state)))
 
 		// Remote API:
 		apiRouter.Handle("/remotes/list", needsAuth(endpoints.NewRemotesListHandler(gw.state)))
@@ -190,6 +256,7 @@ func (gw *Gateway) Start() {
 		apiRouter.Handle("/remotes/self", needsAuth(endpoints.NewRemotesSelfHandler(gw.state)))
 		apiRouter.Handle("/remotes/sync", needsAuth(endpoints.NewRemotesSyncHandler(gw.state)))
 		apiRouter.Handle("/remotes/diff", needsAuth(endpoints.NewRemotesDiffHandler(gw.state)))
+		apiRouter.Handle("/remotes/transfers", needsAuth(endpoints.NewRemotesTransfersHandler(gw.state)))
 	}
 
 	// Add the /get endpoint. Since it might contain any path, we have to
@@ -198,6 +265,17 @@ func (gw *Gateway) Start() {
 	// since it needs to be available if somebody is not using the UI.
 	router.PathPrefix("/get").Handler(endpoints.NewGetHandler(gw.state)).Methods("GET")
 
+	// Share links need no login at all, just the token (and maybe a password).
+	router.PathPrefix(endpoints.SharePrefix+"/").Handler(endpoints.NewShareHandler(gw.state)).Methods("GET", "HEAD")
+
+	if gw.cfg.Bool("webdav.enabled") {
+		// WebDAV uses its own methods (PROPFIND, MKCOL, ...) and does its own
+		// auth handling (basic auth), just like /get.
+		webdavHdl := endpoints.NewWebDAVHandler(gw.state)
+		router.Handle(endpoints.WebDAVPrefix, webdavHdl)
+		router.PathPrefix(endpoints.WebDAVPrefix + "/").Handler(webdavHdl)
+	}
+
 	if uiEnabled {
 		// /events is a websocket that pushes events to the client.
 		// The client will probably call /ls then.
@@ -233,12 +311,7 @@ func (gw *Gateway) Start() {
 	}
 
 	// Implement rate limiting:
-	router.Use(
-		stdlib.NewMiddleware(
-			limiter.New(memory.NewStore(), rate),
-			stdlib.WithForwardHeader(true),
-		).Handler,
-	)
+	router.Use(rateLimitMiddleware())
 
 	gw.srv = &http.Server{
 		Addr:              addr,
@@ -256,6 +329,32 @@ func (gw *Gateway) Start() {
 			log.Errorf("serve failed: %v", err)
 		}
 	}()
+
+	if gw.cfg.Bool("s3.enabled") {
+		gw.startS3()
+	}
+}
+
+// startS3 starts the S3 API on its own port. S3 clients expect the
+// buckets at the root of the url, so it can't share the router above.
+func (gw *Gateway) startS3() {
+	addr := fmt.Sprintf(":%d", gw.cfg.Int("s3.port"))
+	log.Debugf("starting s3 api on %s", addr)
+
+	gw.s3Srv = &http.Server{
+		Addr: addr,
+		// The s3 api can be used to guess secrets, so it is limited too.
+		Handler:           rateLimitMiddleware()(gw.s3Hdl),
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       360 * time.Second,
+	}
+
+	go func(srv *http.Server) {
+		err := srv.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			log.Errorf("s3 serve failed: %v", err)
+		}
+	}(gw.s3Srv)
 }
 
 // UserDatabase returns the user database API.
@@ -263,7 +362,17 @@ func (gw *Gateway) UserDatabase() *db.UserDatabase {
 	return gw.state.UserDatabase()
 }
 
+// CreateShare creates a new share link.
+// See endpoints.State.CreateShare for details.
+func (gw *Gateway) CreateShare(link db.ShareLink, password string) (db.ShareLink, error) {
+	return gw.state.CreateShare(link, password)
+}
+
 // Close the gateway and clean up all open resouces.
 func (gw *Gateway) Close() error {
+	if err := gw.s3Hdl.Close(); err != nil {
+		log.Warningf("failed to remove unfinished s3 uploads: %v", err)
+	}
+
 	return gw.state.UserDatabase().Close()
 }
@@ -22,7 +22,7 @@ const (
 	nonceSize = 62
 	// MaxMessageSize is the max size of a messsage that can be send to us.
 	// The limit is arbitrary and should avoid being spammed by huge messages.
-	// (Later on we could also implement a proper streaming protocol)
+	// Bigger payloads like stores are sent in chunks (see transfer.go).
 	MaxMessageSize = 16 * 1024 * 1024
 )
 
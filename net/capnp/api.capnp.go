@@ -135,6 +135,66 @@ func (c Sync) FetchPatches(ctx context.Context, params func(Sync_fetchPatches_Pa
 	}
 	return Sync_fetchPatches_Results_Promise{Pipeline: capnp.NewPipeline(c.Client.Call(call))}
 }
+func (c Sync) OpenTransfer(ctx context.Context, params func(Sync_openTransfer_Params) error, opts ...capnp.CallOption) Sync_openTransfer_Results_Promise {
+	if c.Client == nil {
+		return Sync_openTransfer_Results_Promise{Pipeline: capnp.NewPipeline(capnp.ErrorAnswer(capnp.ErrNullClient))}
+	}
+	call := &capnp.Call{
+		Ctx: ctx,
+		Method: capnp.Method{
+			InterfaceID:   0xf5692a07c5cf7872,
+			MethodID:      6,
+			InterfaceName: "net/capnp/api.capnp:Sync",
+			MethodName:    "openTransfer",
+		},
+		Options: capnp.NewCallOptions(opts),
+	}
+	if params != nil {
+		call.ParamsSize = capnp.ObjectSize{DataSize: 8, PointerCount: 1}
+		call.ParamsFunc = func(s capnp.Struct) error { return params(Sync_openTransfer_Params{Struct: s}) }
+	}
+	return Sync_openTransfer_Results_Promise{Pipeline: capnp.NewPipeline(c.Client.Call(call))}
+}
+func (c Sync) ReadTransfer(ctx context.Context, params func(Sync_readTransfer_Params) error, opts ...capnp.CallOption) Sync_readTransfer_Results_Promise {
+	if c.Client == nil {
+		return Sync_readTransfer_Results_Promise{Pipeline: capnp.NewPipeline(capnp.ErrorAnswer(capnp.ErrNullClient))}
+	}
+	call := &capnp.Call{
+		Ctx: ctx,
+		Method: capnp.Method{
+			InterfaceID:   0xf5692a07c5cf7872,
+			MethodID:      7,
+			InterfaceName: "net/capnp/api.capnp:Sync",
+			MethodName:    "readTransfer",
+		},
+		Options: capnp.NewCallOptions(opts),
+	}
+	if params != nil {
+		call.ParamsSize = capnp.ObjectSize{DataSize: 16, PointerCount: 1}
+		call.ParamsFunc = func(s capnp.Struct) error { return params(Sync_readTransfer_Params{Struct: s}) }
+	}
+	return Sync_readTransfer_Results_Promise{Pipeline: capnp.NewPipeline(c.Client.Call(call))}
+}
+func (c Sync) CloseTransfer(ctx context.Context, params func(Sync_closeTransfer_Params) error, opts ...capnp.CallOption) Sync_closeTransfer_Results_Promise {
+	if c.Client == nil {
+		return Sync_closeTransfer_Results_Promise{Pipeline: capnp.NewPipeline(capnp.ErrorAnswer(capnp.ErrNullClient))}
+	}
+	call := &capnp.Call{
+		Ctx: ctx,
+		Method: capnp.Method{
+			InterfaceID:   0xf5692a07c5cf7872,
+			MethodID:      8,
+			InterfaceName: "net/capnp/api.capnp:Sync",
+			MethodName:    "closeTransfer",
+		},
+		Options: capnp.NewCallOptions(opts),
+	}
+	if params != nil {
+		call.ParamsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 1}
+		call.ParamsFunc = func(s capnp.Struct) error { return params(Sync_closeTransfer_Params{Struct: s}) }
+	}
+	return Sync_closeTransfer_Results_Promise{Pipeline: capnp.NewPipeline(c.Client.Call(call))}
+}
 
 type Sync_Server interface {
 	FetchStore(Sync_fetchStore) error
@@ -148,6 +208,12 @@ type Sync_Server interface {
 	Push(Sync_push) error
 
 	FetchPatches(Sync_fetchPatches) error
+
+	OpenTransfer(Sync_openTransfer) error
+
+	ReadTransfer(Sync_readTransfer) error
+
+	CloseTransfer(Sync_closeTransfer) error
 }
 
 func Sync_ServerToClient(s Sync_Server) Sync {
@@ -157,7 +223,7 @@ func Sync_ServerToClient(s Sync_Server) Sync {
 
 func Sync_Methods(methods []server.Method, s Sync_Server) []server.Method {
 	if cap(methods) == 0 {
-		methods = make([]server.Method, 0, 6)
+		methods = make([]server.Method, 0, 9)
 	}
 
 	methods = append(methods, server.Method{
@@ -244,6 +310,48 @@ func Sync_Methods(methods []server.Method, s Sync_Server) []server.Method {
 		ResultsSize: capnp.ObjectSize{DataSize: 0, PointerCount: 1},
 	})
 
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xf5692a07c5cf7872,
+			MethodID:      6,
+			InterfaceName: "net/capnp/api.capnp:Sync",
+			MethodName:    "openTransfer",
+		},
+		Impl: func(c context.Context, opts capnp.CallOptions, p, r capnp.Struct) error {
+			call := Sync_openTransfer{c, opts, Sync_openTransfer_Params{Struct: p}, Sync_openTransfer_Results{Struct: r}}
+			return s.OpenTransfer(call)
+		},
+		ResultsSize: capnp.ObjectSize{DataSize: 8, PointerCount: 1},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xf5692a07c5cf7872,
+			MethodID:      7,
+			InterfaceName: "net/capnp/api.capnp:Sync",
+			MethodName:    "readTransfer",
+		},
+		Impl: func(c context.Context, opts capnp.CallOptions, p, r capnp.Struct) error {
+			call := Sync_readTransfer{c, opts, Sync_readTransfer_Params{Struct: p}, Sync_readTransfer_Results{Struct: r}}
+			return s.ReadTransfer(call)
+		},
+		ResultsSize: capnp.ObjectSize{DataSize: 0, PointerCount: 1},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xf5692a07c5cf7872,
+			MethodID:      8,
+			InterfaceName: "net/capnp/api.capnp:Sync",
+			MethodName:    "closeTransfer",
+		},
+		Impl: func(c context.Context, opts capnp.CallOptions, p, r capnp.Struct) error {
+			call := Sync_closeTransfer{c, opts, Sync_closeTransfer_Params{Struct: p}, Sync_closeTransfer_Results{Struct: r}}
+			return s.CloseTransfer(call)
+		},
+		ResultsSize: capnp.ObjectSize{DataSize: 0, PointerCount: 0},
+	})
+
 	return methods
 }
 
@@ -295,6 +403,30 @@ type Sync_fetchPatches struct {
 	Results Sync_fetchPatches_Results
 }
 
+// Sync_openTransfer holds the arguments for a server call to Sync.openTransfer.
+type Sync_openTransfer struct {
+	Ctx     context.Context
+	Options capnp.CallOptions
+	Params  Sync_openTransfer_Params
+	Results Sync_openTransfer_Results
+}
+
+// Sync_readTransfer holds the arguments for a server call to Sync.readTransfer.
+type Sync_readTransfer struct {
+	Ctx     context.Context
+	Options capnp.CallOptions
+	Params  Sync_readTransfer_Params
+	Results Sync_readTransfer_Results
+}
+
+// Sync_closeTransfer holds the arguments for a server call to Sync.closeTransfer.
+type Sync_closeTransfer struct {
+	Ctx     context.Context
+	Options capnp.CallOptions
+	Params  Sync_closeTransfer_Params
+	Results Sync_closeTransfer_Results
+}
+
 type Sync_fetchStore_Params struct{ capnp.Struct }
 
 // Sync_fetchStore_Params_TypeID is the unique identifier for the type Sync_fetchStore_Params.
@@ -1027,6 +1159,458 @@ func (p Sync_fetchPatches_Results_Promise) Struct() (Sync_fetchPatches_Results,
 	return Sync_fetchPatches_Results{s}, err
 }
 
+type Sync_openTransfer_Params struct{ capnp.Struct }
+
+// Sync_openTransfer_Params_TypeID is the unique identifier for the type Sync_openTransfer_Params.
+const Sync_openTransfer_Params_TypeID = 0x8ca34b7330c3e9ed
+
+func NewSync_openTransfer_Params(s *capnp.Segment) (Sync_openTransfer_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return Sync_openTransfer_Params{st}, err
+}
+
+func NewRootSync_openTransfer_Params(s *capnp.Segment) (Sync_openTransfer_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return Sync_openTransfer_Params{st}, err
+}
+
+func ReadRootSync_openTransfer_Params(msg *capnp.Message) (Sync_openTransfer_Params, error) {
+	root, err := msg.RootPtr()
+	return Sync_openTransfer_Params{root.Struct()}, err
+}
+
+func (s Sync_openTransfer_Params) String() string {
+	str, _ := text.Marshal(0x8ca34b7330c3e9ed, s.Struct)
+	return str
+}
+
+func (s Sync_openTransfer_Params) Kind() (string, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.Text(), err
+}
+
+func (s Sync_openTransfer_Params) HasKind() bool {
+	p, err := s.Struct.Ptr(0)
+	return p.IsValid() || err != nil
+}
+
+func (s Sync_openTransfer_Params) KindBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s Sync_openTransfer_Params) SetKind(v string) error {
+	return s.Struct.SetText(0, v)
+}
+
+func (s Sync_openTransfer_Params) FromIndex() int64 {
+	return int64(s.Struct.Uint64(0))
+}
+
+func (s Sync_openTransfer_Params) SetFromIndex(v int64) {
+	s.Struct.SetUint64(0, uint64(v))
+}
+
+// Sync_openTransfer_Params_List is a list of Sync_openTransfer_Params.
+type Sync_openTransfer_Params_List struct{ capnp.List }
+
+// NewSync_openTransfer_Params creates a new list of Sync_openTransfer_Params.
+func NewSync_openTransfer_Params_List(s *capnp.Segment, sz int32) (Sync_openTransfer_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1}, sz)
+	return Sync_openTransfer_Params_List{l}, err
+}
+
+func (s Sync_openTransfer_Params_List) At(i int) Sync_openTransfer_Params {
+	return Sync_openTransfer_Params{s.List.Struct(i)}
+}
+
+func (s Sync_openTransfer_Params_List) Set(i int, v Sync_openTransfer_Params) error {
+	return s.List.SetStruct(i, v.Struct)
+}
+
+func (s Sync_openTransfer_Params_List) String() string {
+	str, _ := text.MarshalList(0x8ca34b7330c3e9ed, s.List)
+	return str
+}
+
+// Sync_openTransfer_Params_Promise is a wrapper for a Sync_openTransfer_Params promised by a client call.
+type Sync_openTransfer_Params_Promise struct{ *capnp.Pipeline }
+
+func (p Sync_openTransfer_Params_Promise) Struct() (Sync_openTransfer_Params, error) {
+	s, err := p.Pipeline.Struct()
+	return Sync_openTransfer_Params{s}, err
+}
+
+type Sync_openTransfer_Results struct{ capnp.Struct }
+
+// Sync_openTransfer_Results_TypeID is the unique identifier for the type Sync_openTransfer_Results.
+const Sync_openTransfer_Results_TypeID = 0xaa32afdfcc5507cc
+
+func NewSync_openTransfer_Results(s *capnp.Segment) (Sync_openTransfer_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return Sync_openTransfer_Results{st}, err
+}
+
+func NewRootSync_openTransfer_Results(s *capnp.Segment) (Sync_openTransfer_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return Sync_openTransfer_Results{st}, err
+}
+
+func ReadRootSync_openTransfer_Results(msg *capnp.Message) (Sync_openTransfer_Results, error) {
+	root, err := msg.RootPtr()
+	return Sync_openTransfer_Results{root.Struct()}, err
+}
+
+func (s Sync_openTransfer_Results) String() string {
+	str, _ := text.Marshal(0xaa32afdfcc5507cc, s.Struct)
+	return str
+}
+
+func (s Sync_openTransfer_Results) Id() (string, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.Text(), err
+}
+
+func (s Sync_openTransfer_Results) HasId() bool {
+	p, err := s.Struct.Ptr(0)
+	return p.IsValid() || err != nil
+}
+
+func (s Sync_openTransfer_Results) IdBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s Sync_openTransfer_Results) SetId(v string) error {
+	return s.Struct.SetText(0, v)
+}
+
+func (s Sync_openTransfer_Results) Size() int64 {
+	return int64(s.Struct.Uint64(0))
+}
+
+func (s Sync_openTransfer_Results) SetSize(v int64) {
+	s.Struct.SetUint64(0, uint64(v))
+}
+
+// Sync_openTransfer_Results_List is a list of Sync_openTransfer_Results.
+type Sync_openTransfer_Results_List struct{ capnp.List }
+
+// NewSync_openTransfer_Results creates a new list of Sync_openTransfer_Results.
+func NewSync_openTransfer_Results_List(s *capnp.Segment, sz int32) (Sync_openTransfer_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1}, sz)
+	return Sync_openTransfer_Results_List{l}, err
+}
+
+func (s Sync_openTransfer_Results_List) At(i int) Sync_openTransfer_Results {
+	return Sync_openTransfer_Results{s.List.Struct(i)}
+}
+
+func (s Sync_openTransfer_Results_List) Set(i int, v Sync_openTransfer_Results) error {
+	return s.List.SetStruct(i, v.Struct)
+}
+
+func (s Sync_openTransfer_Results_List) String() string {
+	str, _ := text.MarshalList(0xaa32afdfcc5507cc, s.List)
+	return str
+}
+
+// Sync_openTransfer_Results_Promise is a wrapper for a Sync_openTransfer_Results promised by a client call.
+type Sync_openTransfer_Results_Promise struct{ *capnp.Pipeline }
+
+func (p Sync_openTransfer_Results_Promise) Struct() (Sync_openTransfer_Results, error) {
+	s, err := p.Pipeline.Struct()
+	return Sync_openTransfer_Results{s}, err
+}
+
+type Sync_readTransfer_Params struct{ capnp.Struct }
+
+// Sync_readTransfer_Params_TypeID is the unique identifier for the type Sync_readTransfer_Params.
+const Sync_readTransfer_Params_TypeID = 0xa523dde9eb30e8b4
+
+func NewSync_readTransfer_Params(s *capnp.Segment) (Sync_readTransfer_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 1})
+	return Sync_readTransfer_Params{st}, err
+}
+
+func NewRootSync_readTransfer_Params(s *capnp.Segment) (Sync_readTransfer_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 1})
+	return Sync_readTransfer_Params{st}, err
+}
+
+func ReadRootSync_readTransfer_Params(msg *capnp.Message) (Sync_readTransfer_Params, error) {
+	root, err := msg.RootPtr()
+	return Sync_readTransfer_Params{root.Struct()}, err
+}
+
+func (s Sync_readTransfer_Params) String() string {
+	str, _ := text.Marshal(0xa523dde9eb30e8b4, s.Struct)
+	return str
+}
+
+func (s Sync_readTransfer_Params) Id() (string, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.Text(), err
+}
+
+func (s Sync_readTransfer_Params) HasId() bool {
+	p, err := s.Struct.Ptr(0)
+	return p.IsValid() || err != nil
+}
+
+func (s Sync_readTransfer_Params) IdBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s Sync_readTransfer_Params) SetId(v string) error {
+	return s.Struct.SetText(0, v)
+}
+
+func (s Sync_readTransfer_Params) Offset() int64 {
+	return int64(s.Struct.Uint64(0))
+}
+
+func (s Sync_readTransfer_Params) SetOffset(v int64) {
+	s.Struct.SetUint64(0, uint64(v))
+}
+
+func (s Sync_readTransfer_Params) Size() int32 {
+	return int32(s.Struct.Uint32(8))
+}
+
+func (s Sync_readTransfer_Params) SetSize(v int32) {
+	s.Struct.SetUint32(8, uint32(v))
+}
+
+// Sync_readTransfer_Params_List is a list of Sync_readTransfer_Params.
+type Sync_readTransfer_Params_List struct{ capnp.List }
+
+// NewSync_readTransfer_Params creates a new list of Sync_readTransfer_Params.
+func NewSync_readTransfer_Params_List(s *capnp.Segment, sz int32) (Sync_readTransfer_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 1}, sz)
+	return Sync_readTransfer_Params_List{l}, err
+}
+
+func (s Sync_readTransfer_Params_List) At(i int) Sync_readTransfer_Params {
+	return Sync_readTransfer_Params{s.List.Struct(i)}
+}
+
+func (s Sync_readTransfer_Params_List) Set(i int, v Sync_readTransfer_Params) error {
+	return s.List.SetStruct(i, v.Struct)
+}
+
+func (s Sync_readTransfer_Params_List) String() string {
+	str, _ := text.MarshalList(0xa523dde9eb30e8b4, s.List)
+	return str
+}
+
+// Sync_readTransfer_Params_Promise is a wrapper for a Sync_readTransfer_Params promised by a client call.
+type Sync_readTransfer_Params_Promise struct{ *capnp.Pipeline }
+
+func (p Sync_readTransfer_Params_Promise) Struct() (Sync_readTransfer_Params, error) {
+	s, err := p.Pipeline.Struct()
+	return Sync_readTransfer_Params{s}, err
+}
+
+type Sync_readTransfer_Results struct{ capnp.Struct }
+
+// Sync_readTransfer_Results_TypeID is the unique identifier for the type Sync_readTransfer_Results.
+const Sync_readTransfer_Results_TypeID = 0xfe15393095732772
+
+func NewSync_readTransfer_Results(s *capnp.Segment) (Sync_readTransfer_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return Sync_readTransfer_Results{st}, err
+}
+
+func NewRootSync_readTransfer_Results(s *capnp.Segment) (Sync_readTransfer_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return Sync_readTransfer_Results{st}, err
+}
+
+func ReadRootSync_readTransfer_Results(msg *capnp.Message) (Sync_readTransfer_Results, error) {
+	root, err := msg.RootPtr()
+	return Sync_readTransfer_Results{root.Struct()}, err
+}
+
+func (s Sync_readTransfer_Results) String() string {
+	str, _ := text.Marshal(0xfe15393095732772, s.Struct)
+	return str
+}
+
+func (s Sync_readTransfer_Results) Data() ([]byte, error) {
+	p, err := s.Struct.Ptr(0)
+	return []byte(p.Data()), err
+}
+
+func (s Sync_readTransfer_Results) HasData() bool {
+	p, err := s.Struct.Ptr(0)
+	return p.IsValid() || err != nil
+}
+
+func (s Sync_readTransfer_Results) SetData(v []byte) error {
+	return s.Struct.SetData(0, v)
+}
+
+// Sync_readTransfer_Results_List is a list of Sync_readTransfer_Results.
+type Sync_readTransfer_Results_List struct{ capnp.List }
+
+// NewSync_readTransfer_Results creates a new list of Sync_readTransfer_Results.
+func NewSync_readTransfer_Results_List(s *capnp.Segment, sz int32) (Sync_readTransfer_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return Sync_readTransfer_Results_List{l}, err
+}
+
+func (s Sync_readTransfer_Results_List) At(i int) Sync_readTransfer_Results {
+	return Sync_readTransfer_Results{s.List.Struct(i)}
+}
+
+func (s Sync_readTransfer_Results_List) Set(i int, v Sync_readTransfer_Results) error {
+	return s.List.SetStruct(i, v.Struct)
+}
+
+func (s Sync_readTransfer_Results_List) String() string {
+	str, _ := text.MarshalList(0xfe15393095732772, s.List)
+	return str
+}
+
+// Sync_readTransfer_Results_Promise is a wrapper for a Sync_readTransfer_Results promised by a client call.
+type Sync_readTransfer_Results_Promise struct{ *capnp.Pipeline }
+
+func (p Sync_readTransfer_Results_Promise) Struct() (Sync_readTransfer_Results, error) {
+	s, err := p.Pipeline.Struct()
+	return Sync_readTransfer_Results{s}, err
+}
+
+type Sync_closeTransfer_Params struct{ capnp.Struct }
+
+// Sync_closeTransfer_Params_TypeID is the unique identifier for the type Sync_closeTransfer_Params.
+const Sync_closeTransfer_Params_TypeID = 0xe0407c71e6f699e4
+
+func NewSync_closeTransfer_Params(s *capnp.Segment) (Sync_closeTransfer_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return Sync_closeTransfer_Params{st}, err
+}
+
+func NewRootSync_closeTransfer_Params(s *capnp.Segment) (Sync_closeTransfer_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return Sync_closeTransfer_Params{st}, err
+}
+
+func ReadRootSync_closeTransfer_Params(msg *capnp.Message) (Sync_closeTransfer_Params, error) {
+	root, err := msg.RootPtr()
+	return Sync_closeTransfer_Params{root.Struct()}, err
+}
+
+func (s Sync_closeTransfer_Params) String() string {
+	str, _ := text.Marshal(0xe0407c71e6f699e4, s.Struct)
+	return str
+}
+
+func (s Sync_closeTransfer_Params) Id() (string, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.Text(), err
+}
+
+func (s Sync_closeTransfer_Params) HasId() bool {
+	p, err := s.Struct.Ptr(0)
+	return p.IsValid() || err != nil
+}
+
+func (s Sync_closeTransfer_Params) IdBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s Sync_closeTransfer_Params) SetId(v string) error {
+	return s.Struct.SetText(0, v)
+}
+
+// Sync_closeTransfer_Params_List is a list of Sync_closeTransfer_Params.
+type Sync_closeTransfer_Params_List struct{ capnp.List }
+
+// NewSync_closeTransfer_Params creates a new list of Sync_closeTransfer_Params.
+func NewSync_closeTransfer_Params_List(s *capnp.Segment, sz int32) (Sync_closeTransfer_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return Sync_closeTransfer_Params_List{l}, err
+}
+
+func (s Sync_closeTransfer_Params_List) At(i int) Sync_closeTransfer_Params {
+	return Sync_closeTransfer_Params{s.List.Struct(i)}
+}
+
+func (s Sync_closeTransfer_Params_List) Set(i int, v Sync_closeTransfer_Params) error {
+	return s.List.SetStruct(i, v.Struct)
+}
+
+func (s Sync_closeTransfer_Params_List) String() string {
+	str, _ := text.MarshalList(0xe0407c71e6f699e4, s.List)
+	return str
+}
+
+// Sync_closeTransfer_Params_Promise is a wrapper for a Sync_closeTransfer_Params promised by a client call.
+type Sync_closeTransfer_Params_Promise struct{ *capnp.Pipeline }
+
+func (p Sync_closeTransfer_Params_Promise) Struct() (Sync_closeTransfer_Params, error) {
+	s, err := p.Pipeline.Struct()
+	return Sync_closeTransfer_Params{s}, err
+}
+
+type Sync_closeTransfer_Results struct{ capnp.Struct }
+
+// Sync_closeTransfer_Results_TypeID is the unique identifier for the type Sync_closeTransfer_Results.
+const Sync_closeTransfer_Results_TypeID = 0x9111634089ee1c4f
+
+func NewSync_closeTransfer_Results(s *capnp.Segment) (Sync_closeTransfer_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return Sync_closeTransfer_Results{st}, err
+}
+
+func NewRootSync_closeTransfer_Results(s *capnp.Segment) (Sync_closeTransfer_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return Sync_closeTransfer_Results{st}, err
+}
+
+func ReadRootSync_closeTransfer_Results(msg *capnp.Message) (Sync_closeTransfer_Results, error) {
+	root, err := msg.RootPtr()
+	return Sync_closeTransfer_Results{root.Struct()}, err
+}
+
+func (s Sync_closeTransfer_Results) String() string {
+	str, _ := text.Marshal(0x9111634089ee1c4f, s.Struct)
+	return str
+}
+
+// Sync_closeTransfer_Results_List is a list of Sync_closeTransfer_Results.
+type Sync_closeTransfer_Results_List struct{ capnp.List }
+
+// NewSync_closeTransfer_Results creates a new list of Sync_closeTransfer_Results.
+func NewSync_closeTransfer_Results_List(s *capnp.Segment, sz int32) (Sync_closeTransfer_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0}, sz)
+	return Sync_closeTransfer_Results_List{l}, err
+}
+
+func (s Sync_closeTransfer_Results_List) At(i int) Sync_closeTransfer_Results {
+	return Sync_closeTransfer_Results{s.List.Struct(i)}
+}
+
+func (s Sync_closeTransfer_Results_List) Set(i int, v Sync_closeTransfer_Results) error {
+	return s.List.SetStruct(i, v.Struct)
+}
+
+func (s Sync_closeTransfer_Results_List) String() string {
+	str, _ := text.MarshalList(0x9111634089ee1c4f, s.List)
+	return str
+}
+
+// Sync_closeTransfer_Results_Promise is a wrapper for a Sync_closeTransfer_Results promised by a client call.
+type Sync_closeTransfer_Results_Promise struct{ *capnp.Pipeline }
+
+func (p Sync_closeTransfer_Results_Promise) Struct() (Sync_closeTransfer_Results, error) {
+	s, err := p.Pipeline.Struct()
+	return Sync_closeTransfer_Results{s}, err
+}
+
 type Meta struct{ Client capnp.Client }
 
 // Meta_TypeID is the unique identifier for the type Meta.
@@ -1364,6 +1948,66 @@ func (c API) FetchPatches(ctx context.Context, params func(Sync_fetchPatches_Par
 	}
 	return Sync_fetchPatches_Results_Promise{Pipeline: capnp.NewPipeline(c.Client.Call(call))}
 }
+func (c API) OpenTransfer(ctx context.Context, params func(Sync_openTransfer_Params) error, opts ...capnp.CallOption) Sync_openTransfer_Results_Promise {
+	if c.Client == nil {
+		return Sync_openTransfer_Results_Promise{Pipeline: capnp.NewPipeline(capnp.ErrorAnswer(capnp.ErrNullClient))}
+	}
+	call := &capnp.Call{
+		Ctx: ctx,
+		Method: capnp.Method{
+			InterfaceID:   0xf5692a07c5cf7872,
+			MethodID:      6,
+			InterfaceName: "net/capnp/api.capnp:Sync",
+			MethodName:    "openTransfer",
+		},
+		Options: capnp.NewCallOptions(opts),
+	}
+	if params != nil {
+		call.ParamsSize = capnp.ObjectSize{DataSize: 8, PointerCount: 1}
+		call.ParamsFunc = func(s capnp.Struct) error { return params(Sync_openTransfer_Params{Struct: s}) }
+	}
+	return Sync_openTransfer_Results_Promise{Pipeline: capnp.NewPipeline(c.Client.Call(call))}
+}
+func (c API) ReadTransfer(ctx context.Context, params func(Sync_readTransfer_Params) error, opts ...capnp.CallOption) Sync_readTransfer_Results_Promise {
+	if c.Client == nil {
+		return Sync_readTransfer_Results_Promise{Pipeline: capnp.NewPipeline(capnp.ErrorAnswer(capnp.ErrNullClient))}
+	}
+	call := &capnp.Call{
+		Ctx: ctx,
+		Method: capnp.Method{
+			InterfaceID:   0xf5692a07c5cf7872,
+			MethodID:      7,
+			InterfaceName: "net/capnp/api.capnp:Sync",
+			MethodName:    "readTransfer",
+		},
+		Options: capnp.NewCallOptions(opts),
+	}
+	if params != nil {
+		call.ParamsSize = capnp.ObjectSize{DataSize: 16, PointerCount: 1}
+		call.ParamsFunc = func(s capnp.Struct) error { return params(Sync_readTransfer_Params{Struct: s}) }
+	}
+	return Sync_readTransfer_Results_Promise{Pipeline: capnp.NewPipeline(c.Client.Call(call))}
+}
+func (c API) CloseTransfer(ctx context.Context, params func(Sync_closeTransfer_Params) error, opts ...capnp.CallOption) Sync_closeTransfer_Results_Promise {
+	if c.Client == nil {
+		return Sync_closeTransfer_Results_Promise{Pipeline: capnp.NewPipeline(capnp.ErrorAnswer(capnp.ErrNullClient))}
+	}
+	call := &capnp.Call{
+		Ctx: ctx,
+		Method: capnp.Method{
+			InterfaceID:   0xf5692a07c5cf7872,
+			MethodID:      8,
+			InterfaceName: "net/capnp/api.capnp:Sync",
+			MethodName:    "closeTransfer",
+		},
+		Options: capnp.NewCallOptions(opts),
+	}
+	if params != nil {
+		call.ParamsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 1}
+		call.ParamsFunc = func(s capnp.Struct) error { return params(Sync_closeTransfer_Params{Struct: s}) }
+	}
+	return Sync_closeTransfer_Results_Promise{Pipeline: capnp.NewPipeline(c.Client.Call(call))}
+}
 func (c API) Ping(ctx context.Context, params func(Meta_ping_Params) error, opts ...capnp.CallOption) Meta_ping_Results_Promise {
 	if c.Client == nil {
 		return Meta_ping_Results_Promise{Pipeline: capnp.NewPipeline(capnp.ErrorAnswer(capnp.ErrNullClient))}
@@ -1400,6 +2044,12 @@ type API_Server interface {
 
 	FetchPatches(Sync_fetchPatches) error
 
+	OpenTransfer(Sync_openTransfer) error
+
+	ReadTransfer(Sync_readTransfer) error
+
+	CloseTransfer(Sync_closeTransfer) error
+
 	Ping(Meta_ping) error
 }
 
@@ -1410,7 +2060,7 @@ func API_ServerToClient(s API_Server) API {
 
 func API_Methods(methods []server.Method, s API_Server) []server.Method {
 	if cap(methods) == 0 {
-		methods = make([]server.Method, 0, 8)
+		methods = make([]server.Method, 0, 11)
 	}
 
 	methods = append(methods, server.Method{
@@ -1511,6 +2161,48 @@ func API_Methods(methods []server.Method, s API_Server) []server.Method {
 		ResultsSize: capnp.ObjectSize{DataSize: 0, PointerCount: 1},
 	})
 
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xf5692a07c5cf7872,
+			MethodID:      6,
+			InterfaceName: "net/capnp/api.capnp:Sync",
+			MethodName:    "openTransfer",
+		},
+		Impl: func(c context.Context, opts capnp.CallOptions, p, r capnp.Struct) error {
+			call := Sync_openTransfer{c, opts, Sync_openTransfer_Params{Struct: p}, Sync_openTransfer_Results{Struct: r}}
+			return s.OpenTransfer(call)
+		},
+		ResultsSize: capnp.ObjectSize{DataSize: 8, PointerCount: 1},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xf5692a07c5cf7872,
+			MethodID:      7,
+			InterfaceName: "net/capnp/api.capnp:Sync",
+			MethodName:    "readTransfer",
+		},
+		Impl: func(c context.Context, opts capnp.CallOptions, p, r capnp.Struct) error {
+			call := Sync_readTransfer{c, opts, Sync_readTransfer_Params{Struct: p}, Sync_readTransfer_Results{Struct: r}}
+			return s.ReadTransfer(call)
+		},
+		ResultsSize: capnp.ObjectSize{DataSize: 0, PointerCount: 1},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xf5692a07c5cf7872,
+			MethodID:      8,
+			InterfaceName: "net/capnp/api.capnp:Sync",
+			MethodName:    "closeTransfer",
+		},
+		Impl: func(c context.Context, opts capnp.CallOptions, p, r capnp.Struct) error {
+			call := Sync_closeTransfer{c, opts, Sync_closeTransfer_Params{Struct: p}, Sync_closeTransfer_Results{Struct: r}}
+			return s.CloseTransfer(call)
+		},
+		ResultsSize: capnp.ObjectSize{DataSize: 0, PointerCount: 0},
+	})
+
 	methods = append(methods, server.Method{
 		Method: capnp.Method{
 			InterfaceID:   0xb02d2ba0578cc7ff,
@@ -1654,73 +2346,98 @@ func (p API_version_Results_Promise) Struct() (API_version_Results, error) {
 	return API_version_Results{s}, err
 }
 
-const schema_9bcb07fb35756ee6 = "x\xda\xacU]h\x1cU\x14>\xe7\xde;\x99\x82\x89" +
-	"\xcbe\x8ad\x05M\x85\xb5\x85H\x936U\xa4\x05\xcd" +
-	"&\x9a\xd6\x05#3[P\x1b\x10\x1cwo\xbb[7" +
-	"\xbb\x93\x99Y\xed*E\xda\x12\xac\x12\xab\xd4\x9f\x87\xb6" +
-	"JU\xfa\xd0\x8a`\x0b\"\x14|\xa9H0\x05\xf5E" +
-	"|P\xc1Z\xfc\x01QPX\x8c\x84\xed\xc8\x9d\xd9\x99" +
-	"\xdc$\x8d]\x8bo\xcb\x9es\xbf\xf9\xbes\xbe\xfb\xdd" +
-	"M[H\x96l\xd6^\xee\x02\xb0\x1e\xd4\xba\x82\x1fn" +
-	":yq\xea\xd9\xe24XiD\x00\xa6\x03l\xd9H" +
-	"\xf7\"\xa0\xb1\x95\x0e\x03\x06\x1b.O\xe7/\xb5^9" +
-	"\x06<\x8d\x00\x1a\xca\x86\xc7\xe8\x90l\x10a\xc3\xab\xa7" +
-	"\x16\xd2\x1f\xbex\xfc\x9d\xa8!\x04\x98\xa6\xe7\x11X\xf0" +
-	"\xc0\xfc\xc1\x99?\x0fn>\xa3b\xd7\xe93\xf2\xe8\x81" +
-	"\xf0h0;\xf3\xc8\xc9;6~\x00|-\x0d~\xaa" +
-	"\xd6\xefZ\xd0/\x1e\x07@\xe3\x14\x9d3\xceR\x1d\xc0" +
-	"x\x9f\xee0\xbe\x92\xbf\x82\xe6'\x8f\x1f9\xe2\xa6\xce" +
-	"\xa9h\x1f\xd3\x09\x89\xf6Y\x88\xd6\xbart\xd0|4" +
-	"\xf7\xd1\x0a\xb4\xdf\xe8\x05\xa3\x19\xa2\xfdAw\x18i\xb6" +
-	"\x01 hd\x9a7\x1e#\x87gUY\x9c=!\xd1" +
-	"na\x12\xed\x8d\xf5\x7f\x9d[\xb7\xee\xcc\xe7\x8a\xac{" +
-	"\xd8\x90\x94\xc5_\xcb\xedy\x88\x15\xbeU*\xb7\xb3\x09" +
-	"Y9\xb4\xfe\xf0\xad7\xa7~W+\x9c\xb9\xb22\x93" +
-	"\x99\xabno\x9d\xbe\xa4TZ\xb4_V\xee\xe5\xf7\xf3" +
-	"\xfd\xdf\xbf\xfd\xb3*\xebGzA\x12i\x86\xb2n\xb8" +
-	"\xfb\xddo.\xa7\xbf\xfb\x15\xac\xde\xa4!\xcdFe\xc3" +
-	"m!Sw\xdf\x17\x9f\xea\xfd\xe5\xe6\x0a\xdd#l\xce" +
-	"\x18\x0f\xfbs\xecy4,M\x07h\x9d\xf8e\xd3[" +
-	"\xd9;\xe7\x15\xd9[\xb5P\xf6\x98&\xc1f\xf7?y" +
-	"\xe0a\xfb\xca\xbcBTh!\xd1\xafYc\xec\xe8\xa1" +
-	"\xcc\xdf\xea\xc4\xc65W\x1e\xdd\x15\x1ee\xa5\xa9/_" +
-	"\xca\xbf\xb7\x00\xbc7>\xda\xd0\xb6\xc9\xa3U\xe1\x0f\x16" +
-	"l\xa7\xca\x9cA\xdb)\x0f\xc8\x9f\xce\xb6\x9d\x8dja" +
-	"`\xb7\xf0\x0b%\xd3\xf6\x0b%\xe1eL;\xe5\xda\x93" +
-	"\x9e\xc5(\x03`\x08\xc0{\xf2\x00V7E\xab\x97`" +
-	"\xb0\xdb\xadM\xe6\xaaE\x01\xb8\x0f5 \xa8\x01&\xc8" +
-	"TE\x1e\x17\xbe=\xe0\x94\xab{2y\xd1\xe7\xd5+" +
-	"\xfe\x12\xc8!\x00k\x0dEk-\xc1>W8\x95\x06" +
-	"v\x03\xc1n\x05L[A\xb3\xec\xddW\x9bt*\xc2" +
-	"\x17\xdb%\xe1\x91J\xa5\xf6\xb4(f\x86M[\x12\xfe" +
-	"\x17}e\xcf\xac{I\x7f~X\xac\xa0\xa3*,{" +
-	"Q'`\x11\x11\x08\xa2B\x8a,W\x08`\"Z\x8c" +
-	"j\x00\x89\xbd0\xbe\xad\x9c\xf7\x03\xe1\x9a\x9e\x92c\xc8" +
-	"\xa2\x89\xd8\xd1\x122R\x10\xbd\xbe\x15,!8b\xe6" +
-	"\x14z\xb130\xf62\xe7\xa3!\xbd\xe7\x9e\x12\xaeW" +
-	"\xaeU\xb3h\xadA\xc5\xc9\x00\x8b\xd9\x00\xd0\x19\xf5\xbc" +
-	"\xf0\xea\xfa\xb2\xe1\xf6/\xee:U\xb4}\x1b{\x80`" +
-	"\xcfj\xbe\x09\x11\x9d\xbaWJ|s\xad/\xef\xf4k" +
-	"\xae\x88\x87\xd6\xb1\x0d\xcc\xbe\xa5\xb6Y\xc5\xbc\xed\xeb\x90" +
-	"\xb4uuj\xcb|\xe42\xb8\x1e\x9b\xb1e[\x1ch" +
-	"o\xe8\xaa\xa0\xa3\x8b\xe3\x8d7\x89\x0c\x08\xb2\xd5l!" +
-	"YG\xbe\xed\x0d\x8d\x11G)\x9e\x80v,\x9d\x9d\x00" +
-	"\xc2O\xeb\x88I\xdec\x1c\xd5\xfcMY{]G\x92" +
-	"\xbc9\x18\xa7'\x7f\xe1<\x10>\xad#MB\x18\xe3" +
-	"\xe7\x877\\ |JG\x96\xa4\x1b\xc6\xe9\xce\x85\xbc" +
-	"'\xbbt\xd4\x92\x97\x10\xe3\xa0\xe3\xe3{\x81\xf01=" +
-	"\x88W\x0d\xd4\x15Y\x0cb\xcf\x01-\x94\xb2r\x9a\xd1" +
-	"\x120\xde\xc2p\xb4\x86\xb0\x14\xad\x1d\xfa\xda\xff\xa4\xa4" +
-	"\xbbT\x88\x94\x0c\xbe\x8e\xeegd\xb5\xff\xd3\xe4\xcb\xfd" +
-	"u\x8dt\xbeZ\x96\xfe\xb7O\xabvjG\xe7?\x01" +
-	"\x00\x00\xff\xff\xb3\xd4\x83\x1a"
+const schema_9bcb07fb35756ee6 = "x\xda\xacV]l\x14\xd5\x17?\xe7\xce\xd7\xfe\xf3/" +
+	"nn\xb6\x1a\xda\xa8%q\x03R\xa5,\x05\xa2\xf2`" +
+	"\xb7\x08\x94\x8d\xa2;\x0b\xf8Ab\xe2\xb8;\xa5\x03\xdb" +
+	"\xd9\xed\xcc\xaePtc\x804\x01S\x88\xa0\x98\xf0\x11" +
+	"E\x09\x0f`\x0cJ$\x06\x12_ \x84\xa4\x10\x95\x07" +
+	"c\x88\x12\xa9X\xa4!\xf2`R\xadi\xda1w\xa6" +
+	"w:\xdb\xd6v\xab\xbeM\xe6\x9e\xcf\xdf\xfd\xfd\xee9" +
+	"\xb1\x16!N\x16I\x1f\x84\x00\xd4\x8c$;?\xddw" +
+	"\xf4r\xc7\xeb\x99.Pk\x10\x01D\x05`\xf1Rq" +
+	"\x13\x02F\x9a\xc5&@\xe7\xd7\xfe\x0b1\xfb\xe9c\xdd" +
+	"\xa3\x06\x122\x0b\xdd\xb3\xe8\x10\xb7\x00:\xcf\xdd\x7fw" +
+	"w<M\xf7\x01\xad\xe1\x11\xae\x8a\xdb\x10Dg\xde\xcd" +
+	"\xaeT\xef\xf0\xdb\x87\xbc\x13\xcf\xf5K\xb1\x91\xb9^t" +
+	"\x83\xbfs|\xa8\xe6\xcc[\x87?\x0a\xb8\xf6\x89\xe7\x98" +
+	"\xeb\xe7\xb7cw\xfa\xaf?t\x9c\xa5%\xdc\xf7[/" +
+	"m\xafx\x0a\xd0Y=\xb8\xa3\xfb\xb7\x1d\x8bN\x06+" +
+	"\xef\x94\xb61\x83.\x89\x05\xbf\xa2\xac\xbf\xf2\xe3\xa9\xc6" +
+	"\x93e\x95\x9f\x90,fqFb\x95;\x97\xba_8" +
+	"\xfa\xc8\x82O\x81V\x0b\xce-\xb3\xb8tH\xb9|\x18" +
+	"\x00#T\xee\x89< +\x00\x91\x1a\xb9%\xd2\xcc\xbe" +
+	"\x9c\x81\x0b\xaf\xec\xddk\x85O\x07\xf3\xcd\x977\xb0h" +
+	"Ke\x96oxd\xff\xc2\xe4\x8b\x89/&D{I" +
+	">\x1f\xd1\xdch/\xcb-\x91.y\x1e\x80\xd3\x19\x1d" +
+	"\xb8\xe7\x10\xd9u)\x08MI~\x95E\xdb\xedF{" +
+	"o\xee\x1f\xa7\xe7\xcc9\xf9U\x00\x9a\x13r#\x83\x86" +
+	"\xbe\x9b\xd8\xf8\xac\x98\xfe!p\xb2\x8f\xd5!:;\xe7" +
+	"\xeez\xb06|7xR\x92-v\xf2\xf3\xc1\xdfo" +
+	"u\xbc\x11\xbf\x11Lg\xc8.\x14E7]w\xb4\xc7" +
+	"\\5|\xa27\xe0z@\xaeg\xaeO\xd2\x15\xb4t" +
+	"\xe3\xc3_\x82}\x97\xe4\xf3\xccu\x8f\xeb\xfa\xff\xc7\x8e" +
+	"}\x7f\xb3\xe6\xfa\x1dPg\xfb\x06\x9f\xc9\xcb\x99\xc1Y" +
+	"\xd7\xc0\xda\xfa\xf5E\xa5\xde\x18\x98\x00\xcc5\xb9'\xd2" +
+	"\xc7\x80Y\xdc+\xb7\x90H{H\x01\x18>r;\xf6" +
+	"~|\xc9`\xa0\xd0\xf5!\x17\x17-\xc4\x82]*m" +
+	"\xde\xfe\xbc62\x18(t{\xc8-\xf4;\xb1s\xe5" +
+	"\xfe\x9d\xd1?\xcbz\x0cy=\xba\xaeb[\xc77{" +
+	"R\x1f\x0f\x01\x9d\xed\xf7\x18Z\xc6\\\xady\xf6\x81\xd8" +
+	"\x13\xf7\x8e\x94\xdd\x86\xe7\xba\xdbu5\xf5\xc2\xc2\xb4\x96" +
+	"7\xc5\xfcB-o4\xb0\xcf\xfc\xb2\xb5\x9df\xba\xa1" +
+	"U/\xa4\xdb\x92Z!\xdd\xa6\xdb\xd1\xa4\x16\xb6\xb4v" +
+	"[\x15\x05\x11@D\x00:+\x05\xa0V\x09\xa8\xce&" +
+	"\xe8\xb4Z\xb9\xf6\x84\x99\xd1\x01\xb7\xa2\x04\x04\xa5)#" +
+	"\xe7\xf2\xba\xb9\xce\xd2L\xbbU\xb7\xfc\xc8!?\xf2\xfc" +
+	"z\x005*\xa0\x1a#\x88X\x8d\xec\xdf\x02\x96\xedQ" +
+	"\x01\xd5\xc7\x09\x867\x1bf\x06\xab\x80`\x15\xcc4u" +
+	":\x9b\xb3u?w\xaaI\xb7\x8b\xd9\x82\xed;\x08A" +
+	"\x875zAk\xc8\x1b\xe6\xc6hJ\xafs\xed\x82\xed" +
+	"7\x02\xa8!\x01\xd5j\x82u\x96\x9e\xcfv\xfa\x15\xf1" +
+	"`\xd2\x84\xec\x86\xfdT\xae=\x9f\xd5\x0b\xfa*\x06n" +
+	"s6\x9b\xdb\xa2g\xa2MI\x8dA0E\xd9\x96\xae" +
+	"e& V\xe5\x17\xb3\xb2\x16@\x8d\x0b\xa8>3\x86" +
+	"Xb\x19\x80\xbaB@5I\x90\x12R\x8d\x04\x80\xae" +
+	"a\xd0\xae\x16P]GP0|\x10\x9br\xad\xad\xb6" +
+	"^\xe0\xf8\x85mc\x9b\x8e\"\x10\x14\xa7\x04\xd3\xb0\x93" +
+	"E\xdb\xef\x82\x83\xf9w\x1c1l\xcf\x120\x83\x08\x04" +
+	"\xb1r\x8e\xf8\xf8\x07HR;\x09IXw\x0f\x0b\xa8" +
+	".)\xeb\xcekg<7\xc8\xf8\xab\x06H\"\xaa\xa2" +
+	" \x01\xf8\x8f\x06\xf2\x87\x9e\xd2z TR\xc2\x8c\x0f" +
+	"qLbe\xca\x89\xb2\x9b\x15\xfe\x99n\xca\x0alN" +
+	"&\x02\xe5q\xbd#\x7f\xa1(]\xee\x96\xf7\xe6k\xba" +
+	"e\x1b93\x8ej\x08\x03\xef\x13\xc0\xd8H\x00\xa8\xac" +
+	"\xf4\x94n\x17\x95q\xf7Y?F\xfapF+h8" +
+	"\x0b\x08\xce\x02\x9c\\@n\xc4|\xd1n\xf3/p\xba" +
+	"\xcck\x0b9K\xe7\xa0U\xcc\xbcd\xddt\xfa)\x97" +
+	"\xfd\xa8}\xb0\xb1\xda\xb1\xc6\x02\xcc\x99\xee]\x18U\xa2" +
+	"o&W\xaa\xf8\x94'\x15\xf8\xd7ZiN&\x1aF" +
+	"\xef|\xd2\xa0\xcb\xc7\xfa\xe2\xdc\x98 l2\xbejO" +
+	"\x09Q\x97j|&\xe3\x11\xf0\xc6W\x84\xe2\x06 \x91" +
+	"\xff\xa1\x82\xe8\xaf\x0e\xc8\xa7>\x1d\xde\x00\x84\x0e(H" +
+	"\xfc\x15\x08\xf9\x9c\xa5\xfd\xe7\x80\xd0>\x05\x05\x7f\x9e#" +
+	"\xdfu\xe85\x0b\x08\xbd\xaa\xa0\xe8\xcfA\xe4\x8b\x02\xbd" +
+	"\xc8\xb4wVA\xc9_\xea\x90\x8fD\xfa\xc9& \xf4" +
+	"\xb8\x82\xb2\xbf\xcf!_\x8f\xe8Av\xb6OA\xc5_" +
+	"\xba\x90\xcfC\xda\xc5\xceJ\x0a\x86\xfc\x0d\x02\xf9\xbaG" +
+	";X-\x86\xe2pJ\x82`\xe9qt\xb86@H" +
+	"\xb7\xc5\xd1\xe1W\x8b\xfcn\x9b\xbc\xcbu\x8f<zB" +
+	"\xdd\xe8\x9f0SA0D\x98M\xd58:\xfc\x99\x83" +
+	"0cf\x1c\x1d\xfe\xd2\xfb?8u\xa1\xce\xf4\xfeT" +
+	"\xf0\xf6x2\xfa/\x05<\x9e\xe9\xd3\xac\x0b\x93\x0d\xcc" +
+	"\x99\xa5\x0e\x12{\x86\xf3q&\xc9\xff\x1a\x00\xb5\x02y" +
+	"\xea"
 
 func init() {
 	schemas.Register(schema_9bcb07fb35756ee6,
 		0x85647b71cba016e2,
+		0x8ca34b7330c3e9ed,
+		0x9111634089ee1c4f,
 		0x9a90fde15285e327,
 		0xa29b8ab519fba593,
+		0xa523dde9eb30e8b4,
 		0xaa3182f28c82f848,
+		0xaa32afdfcc5507cc,
 		0xb02d2ba0578cc7ff,
 		0xb20f728e8e60c3f5,
 		0xb74958502f92fefd,
@@ -1728,6 +2445,7 @@ func init() {
 		0xceaa2020b2f72696,
 		0xdc63044e67499411,
 		0xdcee0f1a1e882683,
+		0xe0407c71e6f699e4,
 		0xe1a9fd466eca248c,
 		0xe7a1e07d1144113e,
 		0xebdd19e3dba3370b,
@@ -1735,5 +2453,6 @@ func init() {
 		0xf834409e30e8009c,
 		0xf8fe6156816b7dc7,
 		0xf9248392457904d7,
-		0xfbab528dd0716804)
+		0xfbab528dd0716804,
+		0xfe15393095732772)
 }
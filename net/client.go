@@ -145,6 +145,12 @@ func PeekRemotePubkey(
 	return authConn.RemotePubKey(), authConn.RemoteName(), nil
 }
 
+// RemotePubKey returns the public key of the remote we're connected to.
+// It was checked against the remote's fingerprint when connecting.
+func (cl *Client) RemotePubKey() []byte {
+	return cl.authConn.RemotePubKey()
+}
+
 // Close will close the connection from the client side
 func (cl *Client) Close() error {
 	return cl.conn.Close()
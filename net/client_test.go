@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -251,6 +252,161 @@ func TestClientFetchPatch(t *testing.T) {
 	})
 }
 
+func TestClientFetchPatchesStream(t *testing.T) {
+	withNetPair(t, func(a, b testUnit) {
+		require.Nil(t, a.fs.Stage("/new_file", bytes.NewReader([]byte{1, 2, 3})))
+		require.Nil(t, a.fs.MakeCommit("add new_file"))
+
+		progresses := []TransferProgress{}
+		progressFn := func(progress TransferProgress) {
+			progresses = append(progresses, progress)
+		}
+
+		partialPath := filepath.Join(b.rp.BaseFolder, "partial")
+		data, err := b.ctl.FetchPatchesStream(0, partialPath, progressFn)
+		require.NoError(t, err)
+
+		require.NotEmpty(t, progresses)
+		last := progresses[len(progresses)-1]
+		require.Equal(t, int64(len(data)), last.Done)
+		require.Equal(t, int64(len(data)), last.Total)
+
+		// A complete transfer leaves nothing behind:
+		_, err = os.Stat(partialPath)
+		require.True(t, os.IsNotExist(err))
+
+		aliceFsAtBob, err := b.rp.FS("alice", b.bk)
+		require.NoError(t, err)
+		require.NoError(t, aliceFsAtBob.ApplyPatches(data))
+
+		info, err := aliceFsAtBob.Stat("/new_file")
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), info.Size)
+
+		// Pretend that an earlier transfer stopped in the middle:
+		id, size, err := b.ctl.openTransfer(TransferKindPatches, 0)
+		require.NoError(t, err)
+		require.Equal(t, int64(len(data)), size)
+		require.NoError(t, ioutil.WriteFile(partialPath+".id", []byte(id), 0600))
+		require.NoError(t, ioutil.WriteFile(partialPath, data[:len(data)/2], 0600))
+
+		progresses = progresses[:0]
+		resumed, err := b.ctl.FetchPatchesStream(0, partialPath, progressFn)
+		require.NoError(t, err)
+		require.Equal(t, data, resumed)
+
+		// Only the missing half had to be transferred:
+		require.Len(t, progresses, 1)
+		require.Equal(t, size, progresses[0].Done)
+	})
+}
+
+func verifyWithKeyOf(t *testing.T, u testUnit) catfs.SyncOption {
+	kr, err := u.rp.Keyring()
+	require.NoError(t, err)
+
+	pubKey, err := kr.OwnPubKey()
+	require.NoError(t, err)
+
+	return catfs.SyncOptVerifySignatures(func(data, sig []byte) error {
+		return repo.VerifySignature(pubKey, data, sig)
+	})
+}
+
+func TestClientFetchPatchesSigned(t *testing.T) {
+	withNetPair(t, func(a, b testUnit) {
+		require.Nil(t, a.fs.Stage("/new_file", bytes.NewReader([]byte{1, 2, 3})))
+
+		data, err := b.ctl.FetchPatches(0)
+		require.NoError(t, err)
+
+		aliceFsAtBob, err := b.rp.FS("alice", b.bk)
+		require.NoError(t, err)
+
+		// Alice's commits were not signed by bob:
+		require.Error(t, aliceFsAtBob.ApplyPatches(data, verifyWithKeyOf(t, b)))
+		_, err = aliceFsAtBob.Stat("/new_file")
+		require.True(t, ie.IsNoSuchFileError(err))
+
+		require.NoError(t, aliceFsAtBob.ApplyPatches(data, verifyWithKeyOf(t, a)))
+		_, err = aliceFsAtBob.Stat("/new_file")
+		require.NoError(t, err)
+
+		// The replayed commits carry the signatures of alice's patches,
+		// so bob can check them again when syncing:
+		require.NoError(t, b.fs.Sync(aliceFsAtBob, verifyWithKeyOf(t, a)))
+		_, err = b.fs.Stat("/new_file")
+		require.NoError(t, err)
+	})
+}
+
+func TestTransferCache(t *testing.T) {
+	tc := newTransferCache()
+	id := tc.add("bob", []byte("hello world"))
+
+	data, err := tc.read("bob", id, 6, 100)
+	require.NoError(t, err)
+	require.Equal(t, []byte("world"), data)
+
+	data, err = tc.read("bob", id, 11, 100)
+	require.NoError(t, err)
+	require.Empty(t, data)
+
+	_, err = tc.read("bob", id, 12, 100)
+	require.Error(t, err)
+
+	// Other remotes may not touch bob's transfers:
+	_, err = tc.read("charlie", id, 0, 100)
+	require.Error(t, err)
+
+	tc.remove("charlie", id)
+	_, err = tc.read("bob", id, 0, 100)
+	require.NoError(t, err)
+
+	tc.remove("bob", id)
+	_, err = tc.read("bob", id, 0, 100)
+	require.Error(t, err)
+}
+
+func TestTransferCacheLimits(t *testing.T) {
+	tc := newTransferCache()
+	tc.maxCount = 2
+	tc.maxBytes = 10
+
+	id1 := tc.add("bob", []byte("12345"))
+	id2 := tc.add("bob", []byte("67890"))
+	charlieID := tc.add("charlie", []byte("12345"))
+
+	// Make the order of the transfers deterministic:
+	tc.transfers["bob"][id1].lastAccess = time.Now().Add(-2 * time.Minute)
+	tc.transfers["bob"][id2].lastAccess = time.Now().Add(-time.Minute)
+
+	// Too many transfers, the oldest one is dropped:
+	id3 := tc.add("bob", []byte("abc"))
+	_, err := tc.read("bob", id1, 0, 100)
+	require.Error(t, err)
+
+	for _, id := range []string{id2, id3} {
+		_, err = tc.read("bob", id, 0, 100)
+		require.NoError(t, err)
+	}
+
+	// Too many bytes; a single big transfer is still served:
+	id4 := tc.add("bob", []byte("0123456789abcdef"))
+	for _, id := range []string{id2, id3} {
+		_, err = tc.read("bob", id, 0, 100)
+		require.Error(t, err)
+	}
+
+	data, err := tc.read("bob", id4, 0, 100)
+	require.NoError(t, err)
+	require.Equal(t, []byte("0123456789abcdef"), data)
+
+	// The limits are per remote:
+	_, err = tc.read("charlie", charlieID, 0, 100)
+	require.NoError(t, err)
+}
+
 func TestClientCompleteFetchAllowed(t *testing.T) {
 	withNetPair(t, func(a, b testUnit) {
 		isAllowed, err := b.ctl.IsCompleteFetchAllowed()
@@ -0,0 +1,281 @@
+package direct
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sahib/brig/net/peer"
+	"github.com/sahib/brig/repo"
+	log "github.com/sirupsen/logrus"
+)
+
+// All protocols share a single port. After the TLS handshake the dialing
+// side sends a small header (one length byte, followed by the protocol name)
+// which is used to hand the connection to the right listener.
+
+const (
+	dialTimeout      = 10 * time.Second
+	handshakeTimeout = 10 * time.Second
+)
+
+var (
+	errListenerClosed = errors.New("listener was closed")
+)
+
+func writeProtocolHeader(w io.Writer, protocol string) error {
+	if len(protocol) == 0 || len(protocol) > 255 {
+		return fmt.Errorf("invalid protocol name: %s", protocol)
+	}
+
+	_, err := w.Write(append([]byte{byte(len(protocol))}, protocol...))
+	return err
+}
+
+func readProtocolHeader(r io.Reader) (string, error) {
+	size := []byte{0}
+	if _, err := io.ReadFull(r, size); err != nil {
+		return "", err
+	}
+
+	protocol := make([]byte, size[0])
+	if _, err := io.ReadFull(r, protocol); err != nil {
+		return "", err
+	}
+
+	return string(protocol), nil
+}
+
+// addressesFor returns the network addresses of the remote with `peerAddr`.
+// If there is no such remote, `peerAddr` itself is tried when it looks like
+// a network address. This allows peeking at the fingerprint of a new remote.
+func (nd *Node) addressesFor(peerAddr string) ([]string, error) {
+	remote, err := nd.book.RemoteByAddr(peerAddr)
+	if err != nil && err != repo.ErrNoSuchRemote {
+		return nil, err
+	}
+
+	if err == nil && len(remote.Addresses) > 0 {
+		return remote.Addresses, nil
+	}
+
+	if _, _, err := net.SplitHostPort(peerAddr); err == nil {
+		return []string{peerAddr}, nil
+	}
+
+	return nil, fmt.Errorf("no known addresses for »%s«", peerAddr)
+}
+
+// ResolveName returns the remote named `name` from the address book.
+// There is no way to discover unknown peers with the direct transport.
+func (nd *Node) ResolveName(ctx context.Context, name string) ([]peer.Info, error) {
+	if !nd.IsOnline() {
+		return nil, ErrOffline
+	}
+
+	remote, err := nd.book.Remote(name)
+	if err == repo.ErrNoSuchRemote {
+		return []peer.Info{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []peer.Info{{
+		Name: peer.Name(name),
+		Addr: remote.Fingerprint.Addr(),
+	}}, nil
+}
+
+// PublishName does nothing, since there is no network to publish to.
+// Other peers need to add our address to their address book.
+func (nd *Node) PublishName(name string) error {
+	if !nd.IsOnline() {
+		return ErrOffline
+	}
+
+	return nil
+}
+
+// Dial will open a TLS connection to the peer identified by `peerAddr`.
+// The connection fails if the peer cannot prove that it owns the
+// public key matching `fingerprint` (unless it is empty).
+func (nd *Node) Dial(peerAddr, fingerprint, protocol string) (net.Conn, error) {
+	if !nd.IsOnline() {
+		return nil, ErrOffline
+	}
+
+	addrs, err := nd.addressesFor(peerAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := []string{}
+	for _, addr := range addrs {
+		dialer := &net.Dialer{Timeout: dialTimeout}
+		conn, err := tls.DialWithDialer(dialer, "tcp", addr, nd.clientConfig(fingerprint))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", addr, err))
+			continue
+		}
+
+		if err := writeProtocolHeader(conn, protocol); err != nil {
+			conn.Close()
+			errs = append(errs, fmt.Sprintf("%s: %v", addr, err))
+			continue
+		}
+
+		log.Debugf("dial to »%s« over %s", peerAddr, addr)
+		return conn, nil
+	}
+
+	return nil, fmt.Errorf("failed to dial »%s«: %s", peerAddr, strings.Join(errs, "; "))
+}
+
+type protoListener struct {
+	nd       *Node
+	protocol string
+	addr     net.Addr
+	conns    chan net.Conn
+	done     chan struct{}
+	once     sync.Once
+}
+
+// Accept waits for the next connection for this protocol.
+func (pl *protoListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-pl.conns:
+		return conn, nil
+	case <-pl.done:
+		return nil, errListenerClosed
+	}
+}
+
+// Close stops listening for this protocol.
+func (pl *protoListener) Close() error {
+	var err error
+	pl.once.Do(func() {
+		close(pl.done)
+		err = pl.nd.unregister(pl.protocol)
+	})
+
+	return err
+}
+
+// Addr returns the address we listen on.
+func (pl *protoListener) Addr() net.Addr {
+	return pl.addr
+}
+
+// Listen returns a listener for connections that use `protocol`.
+func (nd *Node) Listen(protocol string) (net.Listener, error) {
+	nd.mu.Lock()
+	defer nd.mu.Unlock()
+
+	if !nd.allowNetOps {
+		return nil, ErrOffline
+	}
+
+	if _, ok := nd.protos[protocol]; ok {
+		return nil, fmt.Errorf("already listening for %s", protocol)
+	}
+
+	if nd.lst == nil {
+		lst, err := tls.Listen("tcp", nd.listenAddr, nd.serverConfig())
+		if err != nil {
+			return nil, err
+		}
+
+		log.Infof("direct transport: listening on %s", lst.Addr())
+		nd.lst = lst
+		go nd.acceptLoop(lst)
+	}
+
+	pl := &protoListener{
+		nd:       nd,
+		protocol: protocol,
+		addr:     nd.lst.Addr(),
+		conns:    make(chan net.Conn),
+		done:     make(chan struct{}),
+	}
+
+	nd.protos[protocol] = pl
+	return pl, nil
+}
+
+func (nd *Node) unregister(protocol string) error {
+	nd.mu.Lock()
+	defer nd.mu.Unlock()
+
+	delete(nd.protos, protocol)
+	if len(nd.protos) > 0 || nd.lst == nil {
+		return nil
+	}
+
+	lst := nd.lst
+	nd.lst = nil
+	return lst.Close()
+}
+
+func (nd *Node) acceptLoop(lst net.Listener) {
+	for {
+		conn, err := lst.Accept()
+		if err != nil {
+			// Listener was closed.
+			return
+		}
+
+		go nd.dispatch(conn)
+	}
+}
+
+// dispatch reads the protocol header of `conn`
+// and hands it to the matching listener.
+func (nd *Node) dispatch(conn net.Conn) {
+	if !nd.IsOnline() {
+		conn.Close()
+		return
+	}
+
+	// The header is read during the handshake timeout,
+	// so stalled peers do not keep the connection open.
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		conn.Close()
+		return
+	}
+
+	protocol, err := readProtocolHeader(conn)
+	if err != nil {
+		log.Debugf("direct transport: bad connection from %s: %v", conn.RemoteAddr(), err)
+		conn.Close()
+		return
+	}
+
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return
+	}
+
+	nd.mu.Lock()
+	pl, ok := nd.protos[protocol]
+	nd.mu.Unlock()
+
+	if !ok {
+		log.Debugf("direct transport: nobody listens for %s", protocol)
+		conn.Close()
+		return
+	}
+
+	select {
+	case pl.conns <- conn:
+	case <-pl.done:
+		conn.Close()
+	}
+}
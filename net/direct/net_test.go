@@ -0,0 +1,127 @@
+package direct
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/sahib/brig/net/peer"
+	"github.com/sahib/brig/repo"
+	"github.com/stretchr/testify/require"
+)
+
+type testBook map[string]repo.Remote
+
+func (tb testBook) Remote(name string) (repo.Remote, error) {
+	remote, ok := tb[name]
+	if !ok {
+		return repo.Remote{}, repo.ErrNoSuchRemote
+	}
+
+	return remote, nil
+}
+
+func (tb testBook) RemoteByAddr(addr string) (repo.Remote, error) {
+	for _, remote := range tb {
+		if remote.Fingerprint.Addr() == addr {
+			return remote, nil
+		}
+	}
+
+	return repo.Remote{}, repo.ErrNoSuchRemote
+}
+
+func withKeyring(t *testing.T, owner string, fn func(kr *repo.Keyring, pubKey []byte)) {
+	basePath, err := ioutil.TempDir("", "brig-direct-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(basePath)
+
+	require.NoError(t, repo.Init(repo.InitOptions{
+		BaseFolder:  basePath,
+		Owner:       owner,
+		BackendName: "mock",
+		DaemonURL:   "not-relevant-here",
+	}))
+
+	rp, err := repo.Open(basePath)
+	require.NoError(t, err)
+	defer rp.Close()
+
+	kr, err := rp.Keyring()
+	require.NoError(t, err)
+
+	pubKey, err := kr.OwnPubKey()
+	require.NoError(t, err)
+
+	fn(kr, pubKey)
+}
+
+func TestDialListen(t *testing.T) {
+	withKeyring(t, "alice", func(aliKr *repo.Keyring, aliPubKey []byte) {
+		withKeyring(t, "bob", func(bobKr *repo.Keyring, bobPubKey []byte) {
+			ali, err := NewNode("127.0.0.1:0", aliKr, testBook{})
+			require.NoError(t, err)
+
+			lst, err := ali.Listen("brig/test")
+			require.NoError(t, err)
+			defer lst.Close()
+
+			aliFp := peer.BuildFingerprint("ali-addr", aliPubKey)
+			bob, err := NewNode("127.0.0.1:0", bobKr, testBook{
+				"ali": repo.Remote{
+					Name:        "ali",
+					Fingerprint: aliFp,
+					Addresses:   []string{lst.Addr().String()},
+				},
+			})
+			require.NoError(t, err)
+
+			go func() {
+				conn, err := lst.Accept()
+				if err != nil {
+					return
+				}
+
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+
+			conn, err := bob.Dial("ali-addr", aliFp.PubKeyID(), "brig/test")
+			require.NoError(t, err)
+			defer conn.Close()
+
+			_, err = conn.Write([]byte("hello"))
+			require.NoError(t, err)
+
+			buf := make([]byte, 5)
+			_, err = io.ReadFull(conn, buf)
+			require.NoError(t, err)
+			require.Equal(t, []byte("hello"), buf)
+
+			// Dialing with somebody else's fingerprint must fail:
+			bobFp := peer.BuildFingerprint("ali-addr", bobPubKey)
+			_, err = bob.Dial("ali-addr", bobFp.PubKeyID(), "brig/test")
+			require.Error(t, err)
+
+			infos, err := bob.ResolveName(context.Background(), "ali")
+			require.NoError(t, err)
+			require.Equal(t, []peer.Info{{Name: "ali", Addr: "ali-addr"}}, infos)
+		})
+	})
+}
+
+func TestDialUnknownPeer(t *testing.T) {
+	withKeyring(t, "alice", func(kr *repo.Keyring, _ []byte) {
+		nd, err := NewNode("127.0.0.1:0", kr, testBook{})
+		require.NoError(t, err)
+
+		_, err = nd.Dial("nobody", "", "brig/test")
+		require.Error(t, err)
+
+		require.NoError(t, nd.Disconnect())
+		_, err = nd.Listen("brig/test")
+		require.Equal(t, ErrOffline, err)
+	})
+}
@@ -0,0 +1,106 @@
+// Package direct implements a network backend that talks to other brig
+// daemons over plain TCP, without relying on the data backend's network
+// features (like IPFS' experimental p2p streams).
+//
+// All connections are secured by TLS. Each node generates an ephemeral TLS
+// key on startup and signs it with its brig key. The signature and the brig
+// public key travel inside the certificate, so the TLS session is bound to
+// the fingerprint of the remote. The remote's network addresses are taken
+// from the static address book in the remote list (repo.Remote.Addresses).
+//
+// QUIC is not supported (yet); only TCP is used as underlying transport.
+package direct
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/sahib/brig/repo"
+)
+
+var (
+	// ErrOffline is returned when the node was disconnected.
+	ErrOffline = errors.New("direct transport is offline")
+)
+
+// Keyring is the part of repo.Keyring needed for binding
+// our TLS certificate to our identity.
+type Keyring interface {
+	// OwnPubKey returns our own public key.
+	OwnPubKey() ([]byte, error)
+
+	// Sign creates a detached signature of `data`.
+	Sign(data []byte) ([]byte, error)
+}
+
+// AddressBook is used to find the network addresses of remotes.
+// It is usually implemented by repo.RemoteList.
+type AddressBook interface {
+	// Remote returns the remote named `name`.
+	Remote(name string) (repo.Remote, error)
+
+	// RemoteByAddr returns the remote with `addr` in its fingerprint.
+	RemoteByAddr(addr string) (repo.Remote, error)
+}
+
+// Node is the direct transport's implementation of net/backend.Backend.
+// It does not implement Identity(), since the addr part of our fingerprint
+// is still decided by the data backend.
+type Node struct {
+	mu sync.Mutex
+
+	listenAddr  string
+	book        AddressBook
+	cert        tls.Certificate
+	allowNetOps bool
+
+	// lst is the shared TCP listener for all protocols.
+	// It is only open while at least one protocol is listened to.
+	lst    net.Listener
+	protos map[string]*protoListener
+}
+
+// NewNode returns a new direct transport node that will listen on
+// `listenAddr` (host:port) once Listen() is called.
+func NewNode(listenAddr string, kr Keyring, book AddressBook) (*Node, error) {
+	cert, err := newCertificate(kr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Node{
+		listenAddr:  listenAddr,
+		book:        book,
+		cert:        cert,
+		allowNetOps: true,
+		protos:      make(map[string]*protoListener),
+	}, nil
+}
+
+// IsOnline returns true if the node allows network operations.
+func (nd *Node) IsOnline() bool {
+	nd.mu.Lock()
+	defer nd.mu.Unlock()
+
+	return nd.allowNetOps
+}
+
+// Connect allows network operations again.
+func (nd *Node) Connect() error {
+	nd.mu.Lock()
+	defer nd.mu.Unlock()
+
+	nd.allowNetOps = true
+	return nil
+}
+
+// Disconnect will reject incoming connections and disallow outgoing.
+func (nd *Node) Disconnect() error {
+	nd.mu.Lock()
+	defer nd.mu.Unlock()
+
+	nd.allowNetOps = false
+	return nil
+}
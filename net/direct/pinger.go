@@ -0,0 +1,128 @@
+package direct
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	netBackend "github.com/sahib/brig/net/backend"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrWaiting is the initial error state of a pinger.
+// The error will be unset once a successful ping was made.
+var ErrWaiting = errors.New("waiting for route")
+
+type pinger struct {
+	lastSeen  time.Time
+	roundtrip time.Duration
+	err       error
+
+	mu     sync.Mutex
+	cancel func()
+}
+
+// LastSeen returns the time we pinged the remote last time.
+func (p *pinger) LastSeen() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.lastSeen
+}
+
+// Roundtrip returns the time needed to connect to the remote.
+func (p *pinger) Roundtrip() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.roundtrip
+}
+
+// Err will return a non-nil error when the current ping did not succeed.
+func (p *pinger) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.err
+}
+
+// Close will clean up the pinger.
+func (p *pinger) Close() error {
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+
+	return nil
+}
+
+// ping checks if any address of `peerAddr` accepts TCP connections.
+func (nd *Node) ping(peerAddr string) (time.Duration, error) {
+	addrs, err := nd.addressesFor(peerAddr)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, addr := range addrs {
+		start := time.Now()
+		conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+		if err != nil {
+			continue
+		}
+
+		conn.Close()
+		return time.Since(start), nil
+	}
+
+	return 0, fmt.Errorf("no route: %s is not reachable", peerAddr)
+}
+
+func (p *pinger) update(nd *Node, addr string) {
+	roundtrip, err := nd.ping(addr)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err != nil {
+		p.err = err
+		return
+	}
+
+	p.err = nil
+	p.lastSeen = time.Now()
+	p.roundtrip = roundtrip
+}
+
+func (p *pinger) Run(ctx context.Context, nd *Node, addr string) {
+	p.update(nd, addr)
+
+	tckr := time.NewTicker(10 * time.Second)
+	defer tckr.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tckr.C:
+			p.update(nd, addr)
+		}
+	}
+}
+
+// Ping will return a pinger for `addr`.
+func (nd *Node) Ping(addr string) (netBackend.Pinger, error) {
+	if !nd.IsOnline() {
+		return nil, ErrOffline
+	}
+
+	log.Debugf("direct transport: start ping »%s«", addr)
+	p := &pinger{err: ErrWaiting}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	go p.Run(ctx, nd, addr)
+	return p, nil
+}
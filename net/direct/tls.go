@@ -0,0 +1,156 @@
+package direct
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/sahib/brig/repo"
+	h "github.com/sahib/brig/util/hashlib"
+)
+
+// certBindingOID identifies the certificate extension that carries the brig
+// public key and its signature over the certificate's public key. The OID is
+// not registered anywhere and only interpreted by brig.
+var certBindingOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 59283, 1, 1}
+
+type certBinding struct {
+	PubKey    []byte
+	Signature []byte
+}
+
+// newCertificate creates a self-signed TLS certificate with a fresh key.
+// The key is signed by our brig key, which binds it to our fingerprint.
+func newCertificate(kr Keyring) (tls.Certificate, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	spki, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	pubKey, err := kr.OwnPubKey()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	sig, err := kr.Sign(spki)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	binding, err := asn1.Marshal(certBinding{
+		PubKey:    pubKey,
+		Signature: sig,
+	})
+
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "brig"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(10 * 365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{
+			x509.ExtKeyUsageServerAuth,
+			x509.ExtKeyUsageClientAuth,
+		},
+		ExtraExtensions: []pkix.Extension{{
+			Id:    certBindingOID,
+			Value: binding,
+		}},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  priv,
+	}, nil
+}
+
+// verifyBinding checks that the peer certificate in `rawCerts` was signed
+// by a brig key. If `pubKeyID` is not empty, the key must also match it.
+// The TLS handshake itself makes sure that the peer owns the certificate's key.
+func verifyBinding(rawCerts [][]byte, pubKeyID string) error {
+	if len(rawCerts) == 0 {
+		return errors.New("peer did not send a certificate")
+	}
+
+	cert, err := x509.ParseCertificate(rawCerts[0])
+	if err != nil {
+		return err
+	}
+
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(certBindingOID) {
+			continue
+		}
+
+		binding := certBinding{}
+		if _, err := asn1.Unmarshal(ext.Value, &binding); err != nil {
+			return fmt.Errorf("bad certificate binding: %v", err)
+		}
+
+		if err := repo.VerifySignature(binding.PubKey, cert.RawSubjectPublicKeyInfo, binding.Signature); err != nil {
+			return fmt.Errorf("bad certificate signature: %v", err)
+		}
+
+		if pubKeyID != "" && h.Sum(binding.PubKey).B58String() != pubKeyID {
+			return errors.New("certificate does not belong to the expected fingerprint")
+		}
+
+		return nil
+	}
+
+	return errors.New("certificate is not bound to a brig key")
+}
+
+func (nd *Node) clientConfig(pubKeyID string) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{nd.cert},
+		MinVersion:   tls.VersionTLS12,
+		// The certificate is self-signed; verification
+		// against the fingerprint is done below instead.
+		InsecureSkipVerify: true, // #nosec
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyBinding(rawCerts, pubKeyID)
+		},
+	}
+}
+
+func (nd *Node) serverConfig() *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{nd.cert},
+		MinVersion:   tls.VersionTLS12,
+		ClientAuth:   tls.RequireAnyClientCert,
+		// We do not know who is connecting at this point.
+		// The fingerprint is checked later by the authentication layer.
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyBinding(rawCerts, "")
+		},
+	}
+}
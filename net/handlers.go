@@ -18,6 +18,7 @@ type requestHandler struct {
 	rp             *repo.Repository
 	ctx            context.Context
 	rapi           remotesapi.RemotesAPI
+	transfers      *transferCache
 	currRemoteName string
 }
 
@@ -35,30 +36,39 @@ func completeExportAllowed(folders []repo.Folder) bool {
 	return false
 }
 
-func (hdl *requestHandler) FetchStore(call capnp.Sync_fetchStore) error {
+func (hdl *requestHandler) exportStore() ([]byte, error) {
 	// We should only export our complete metadata, when the root directory
 	// was enabled or no folders were configured.
 	currRemote, err := hdl.rp.Remotes.Remote(hdl.currRemoteName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if !completeExportAllowed(currRemote.Folders) {
 		log.Warningf("Attempt to read complete store from `%v`", hdl.currRemoteName)
-		return errors.New("refusing export")
+		return nil, errors.New("refusing export")
 	}
 
 	fs, err := hdl.rp.FS(hdl.rp.Immutables.Owner(), hdl.bk)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	buf := &bytes.Buffer{}
 	if err := fs.Export(buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (hdl *requestHandler) FetchStore(call capnp.Sync_fetchStore) error {
+	data, err := hdl.exportStore()
+	if err != nil {
 		return err
 	}
 
-	return call.Results.SetData(buf.Bytes())
+	return call.Results.SetData(data)
 }
 
 func (hdl *requestHandler) FetchPatch(call capnp.Sync_fetchPatch) error {
@@ -91,15 +101,15 @@ func (hdl *requestHandler) FetchPatch(call capnp.Sync_fetchPatch) error {
 	return nil
 }
 
-func (hdl *requestHandler) FetchPatches(call capnp.Sync_fetchPatches) error {
+func (hdl *requestHandler) makePatches(fromIndex int64) ([]byte, error) {
 	currRemote, err := hdl.rp.Remotes.Remote(hdl.currRemoteName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	fs, err := hdl.rp.FS(hdl.rp.Immutables.Owner(), hdl.bk)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Apply the respective folder filter for this remote.
@@ -108,16 +118,67 @@ func (hdl *requestHandler) FetchPatches(call capnp.Sync_fetchPatches) error {
 		prefixes = append(prefixes, folder.Folder)
 	}
 
-	fromIndex := call.Params.FromIndex()
 	fromRev := fmt.Sprintf("commit[%d]", fromIndex)
 
 	log.Debugf("Bundling up all changes individually starting from: %s", fromRev)
-	patchData, err := fs.MakePatches(fromRev, prefixes, currRemote.Name)
+	return fs.MakePatches(fromRev, prefixes, currRemote.Name)
+}
+
+func (hdl *requestHandler) FetchPatches(call capnp.Sync_fetchPatches) error {
+	patchData, err := hdl.makePatches(call.Params.FromIndex())
 	if err != nil {
 		return err
 	}
 
-	call.Results.SetData(patchData)
+	return call.Results.SetData(patchData)
+}
+
+func (hdl *requestHandler) OpenTransfer(call capnp.Sync_openTransfer) error {
+	kind, err := call.Params.Kind()
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	switch kind {
+	case TransferKindStore:
+		data, err = hdl.exportStore()
+	case TransferKindPatches:
+		data, err = hdl.makePatches(call.Params.FromIndex())
+	default:
+		return fmt.Errorf("unknown transfer kind: %s", kind)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	id := hdl.transfers.add(hdl.currRemoteName, data)
+	call.Results.SetSize(int64(len(data)))
+	return call.Results.SetId(id)
+}
+
+func (hdl *requestHandler) ReadTransfer(call capnp.Sync_readTransfer) error {
+	id, err := call.Params.Id()
+	if err != nil {
+		return err
+	}
+
+	data, err := hdl.transfers.read(hdl.currRemoteName, id, call.Params.Offset(), call.Params.Size())
+	if err != nil {
+		return err
+	}
+
+	return call.Results.SetData(data)
+}
+
+func (hdl *requestHandler) CloseTransfer(call capnp.Sync_closeTransfer) error {
+	id, err := call.Params.Id()
+	if err != nil {
+		return err
+	}
+
+	hdl.transfers.remove(hdl.currRemoteName, id)
 	return nil
 }
 
@@ -164,5 +225,5 @@ func (hdl *requestHandler) Push(call capnp.Sync_push) error {
 	}
 
 	log.Infof("Syncing with »%s« because he asked us to via a push.", currRemote.Name)
-	return hdl.rapi.Sync(currRemote.Name)
+	return hdl.rapi.SyncFromPush(currRemote.Name)
 }
@@ -80,10 +80,11 @@ func NewServer(rp *repo.Repository, bk backend.Backend, rapi remotesapi.RemotesA
 	pingMap := NewPingMap(rp, bk)
 
 	hdl := &connHandler{
-		rp:      rp,
-		bk:      bk,
-		rapi:    rapi,
-		pingMap: pingMap,
+		rp:        rp,
+		bk:        bk,
+		rapi:      rapi,
+		pingMap:   pingMap,
+		transfers: newTransferCache(),
 	}
 
 	lst, err := bk.Listen("brig/caprpc")
@@ -294,10 +295,11 @@ func (sv *Server) Disconnect() error {
 /////////////////////////////////////
 
 type connHandler struct {
-	bk      backend.Backend
-	rp      *repo.Repository
-	rapi    remotesapi.RemotesAPI
-	pingMap *PingMap
+	bk        backend.Backend
+	rp        *repo.Repository
+	rapi      remotesapi.RemotesAPI
+	pingMap   *PingMap
+	transfers *transferCache
 }
 
 // Handle is called whenever we receive a new connection from another brig peer.
@@ -323,10 +325,11 @@ func (hdl *connHandler) Handle(ctx context.Context, conn net.Conn) {
 	// The respective handler should get its own context it can listen to.
 	reqCtx, reqCancel := context.WithCancel(ctx)
 	reqHdl := &requestHandler{
-		bk:   hdl.bk,
-		rp:   hdl.rp,
-		ctx:  reqCtx,
-		rapi: hdl.rapi,
+		bk:        hdl.bk,
+		rp:        hdl.rp,
+		ctx:       reqCtx,
+		rapi:      hdl.rapi,
+		transfers: hdl.transfers,
 	}
 
 	// This func will be called during the authentication process.
@@ -0,0 +1,397 @@
+package net
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	e "github.com/pkg/errors"
+	"github.com/sahib/brig/net/capnp"
+	h "github.com/sahib/brig/util/hashlib"
+	log "github.com/sirupsen/logrus"
+)
+
+// Big payloads (a complete store or a list of patches) are not sent as one
+// message. The serving side prepares the payload once and keeps it for a
+// while; the receiving side reads it in chunks and keeps what it received
+// on disk. If the connection breaks, a new connection can continue where
+// the last one stopped, as long as the payload did not change in between.
+// The id of a transfer is the hash of its payload. Transfers belong to the
+// remote that opened them; other remotes can neither read nor close them.
+
+const (
+	// TransferKindStore transfers the complete store (see FetchStore)
+	TransferKindStore = "store"
+	// TransferKindPatches transfers a list of patches (see FetchPatches)
+	TransferKindPatches = "patches"
+
+	// transferChunkSize is the size of chunks we request.
+	transferChunkSize = 1024 * 1024
+	// transferMaxChunkSize is the max size of a chunk we send.
+	transferMaxChunkSize = 4 * 1024 * 1024
+	// transferTTL is the time an unused transfer is kept by the serving side.
+	transferTTL = 30 * time.Minute
+	// transferMaxPerRemote is the number of transfers kept per remote.
+	transferMaxPerRemote = 4
+	// transferMaxBytesPerRemote is the size of all transfers kept per remote.
+	transferMaxBytesPerRemote = 256 * 1024 * 1024
+)
+
+type transfer struct {
+	data       []byte
+	lastAccess time.Time
+}
+
+// transferCache keeps the payloads of all transfers served to other peers.
+// When a remote opens more transfers than allowed, its least recently used
+// ones are dropped. The newest transfer is always kept, even if it is bigger
+// than maxBytes, since it could not be fetched at all otherwise.
+type transferCache struct {
+	mu sync.Mutex
+
+	// transfers maps the name of a remote to its transfers by id.
+	transfers map[string]map[string]*transfer
+
+	maxCount int
+	maxBytes int64
+}
+
+func newTransferCache() *transferCache {
+	return &transferCache{
+		transfers: make(map[string]map[string]*transfer),
+		maxCount:  transferMaxPerRemote,
+		maxBytes:  transferMaxBytesPerRemote,
+	}
+}
+
+func (tc *transferCache) gc(now time.Time) {
+	for remote, transfers := range tc.transfers {
+		for id, tr := range transfers {
+			if now.Sub(tr.lastAccess) > transferTTL {
+				delete(transfers, id)
+			}
+		}
+
+		if len(transfers) == 0 {
+			delete(tc.transfers, remote)
+		}
+	}
+}
+
+// shrink drops the least recently used transfers of a remote,
+// until it is within the limits again. `keep` is never dropped.
+func (tc *transferCache) shrink(transfers map[string]*transfer, keep string) {
+	for {
+		size := int64(0)
+		oldestID := ""
+		for id, tr := range transfers {
+			size += int64(len(tr.data))
+			if id == keep {
+				continue
+			}
+
+			if oldestID == "" || tr.lastAccess.Before(transfers[oldestID].lastAccess) {
+				oldestID = id
+			}
+		}
+
+		if oldestID == "" || (len(transfers) <= tc.maxCount && size <= tc.maxBytes) {
+			return
+		}
+
+		delete(transfers, oldestID)
+	}
+}
+
+// add remembers `data` for `remote` and returns the id of the transfer.
+func (tc *transferCache) add(remote string, data []byte) string {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	now := time.Now()
+	tc.gc(now)
+
+	transfers, ok := tc.transfers[remote]
+	if !ok {
+		transfers = make(map[string]*transfer)
+		tc.transfers[remote] = transfers
+	}
+
+	id := h.Sum(data).B58String()
+	transfers[id] = &transfer{
+		data:       data,
+		lastAccess: now,
+	}
+
+	tc.shrink(transfers, id)
+	return id
+}
+
+func (tc *transferCache) read(remote, id string, offset int64, size int32) ([]byte, error) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tr, ok := tc.transfers[remote][id]
+	if !ok {
+		return nil, fmt.Errorf("no such transfer: %s", id)
+	}
+
+	if offset < 0 || offset > int64(len(tr.data)) {
+		return nil, fmt.Errorf("bad transfer offset: %d", offset)
+	}
+
+	if size <= 0 || size > transferMaxChunkSize {
+		size = transferMaxChunkSize
+	}
+
+	end := offset + int64(size)
+	if end > int64(len(tr.data)) {
+		end = int64(len(tr.data))
+	}
+
+	tr.lastAccess = time.Now()
+	return tr.data[offset:end], nil
+}
+
+func (tc *transferCache) remove(remote, id string) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	transfers, ok := tc.transfers[remote]
+	if !ok {
+		return
+	}
+
+	delete(transfers, id)
+	if len(transfers) == 0 {
+		delete(tc.transfers, remote)
+	}
+}
+
+// TransferProgress describes the state of a running transfer.
+type TransferProgress struct {
+	// Remote is the name of the remote we receive data from.
+	Remote string
+	// Kind is one of the TransferKind constants.
+	Kind string
+	// Done is the number of bytes received so far (including resumed bytes).
+	Done int64
+	// Total is the size of the payload in bytes.
+	Total int64
+	// Started is the time when the transfer (or its resumption) started.
+	Started time.Time
+}
+
+// ProgressFunc is called after every received chunk of a transfer.
+type ProgressFunc func(progress TransferProgress)
+
+// TransferMonitor keeps track of the transfers that are currently running.
+type TransferMonitor struct {
+	mu      sync.Mutex
+	running map[string]TransferProgress
+}
+
+// NewTransferMonitor returns a new, empty TransferMonitor.
+func NewTransferMonitor() *TransferMonitor {
+	return &TransferMonitor{
+		running: make(map[string]TransferProgress),
+	}
+}
+
+// Update remembers `progress` as the current state of its remote's transfer.
+// It can be used as ProgressFunc.
+func (tm *TransferMonitor) Update(progress TransferProgress) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tm.running[progress.Remote] = progress
+}
+
+// Finish forgets the transfer from `remote`.
+func (tm *TransferMonitor) Finish(remote string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	delete(tm.running, remote)
+}
+
+// List returns all running transfers, sorted by remote name.
+func (tm *TransferMonitor) List() []TransferProgress {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	progresses := []TransferProgress{}
+	for _, progress := range tm.running {
+		progresses = append(progresses, progress)
+	}
+
+	sort.Slice(progresses, func(i, j int) bool {
+		return progresses[i].Remote < progresses[j].Remote
+	})
+
+	return progresses
+}
+
+// IsTransferUnsupported checks if `err` was returned because
+// the remote does not know about transfers yet.
+func IsTransferUnsupported(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unimplemented") || strings.Contains(msg, "not implemented")
+}
+
+func (cl *Client) openTransfer(kind string, fromIndex int64) (string, int64, error) {
+	call := cl.api.OpenTransfer(cl.ctx, func(p capnp.Sync_openTransfer_Params) error {
+		p.SetFromIndex(fromIndex)
+		return p.SetKind(kind)
+	})
+
+	result, err := call.Struct()
+	if err != nil {
+		return "", 0, err
+	}
+
+	id, err := result.Id()
+	if err != nil {
+		return "", 0, err
+	}
+
+	return id, result.Size(), nil
+}
+
+func (cl *Client) readTransfer(id string, offset int64, size int32) ([]byte, error) {
+	call := cl.api.ReadTransfer(cl.ctx, func(p capnp.Sync_readTransfer_Params) error {
+		p.SetOffset(offset)
+		p.SetSize(size)
+		return p.SetId(id)
+	})
+
+	result, err := call.Struct()
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Data()
+}
+
+func (cl *Client) closeTransfer(id string) error {
+	call := cl.api.CloseTransfer(cl.ctx, func(p capnp.Sync_closeTransfer_Params) error {
+		return p.SetId(id)
+	})
+
+	_, err := call.Struct()
+	return err
+}
+
+// readPartial returns the data of a previous, interrupted transfer with
+// `id` that was saved in `partialPath`. If there is none, nil is returned.
+func readPartial(partialPath, id string) []byte {
+	oldID, err := ioutil.ReadFile(partialPath + ".id") // #nosec
+	if err != nil || string(oldID) != id {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(partialPath) // #nosec
+	if err != nil {
+		return nil
+	}
+
+	return data
+}
+
+func (cl *Client) fetchTransfer(kind string, fromIndex int64, partialPath string, fn ProgressFunc) ([]byte, error) {
+	id, size, err := cl.openTransfer(kind, fromIndex)
+	if err != nil {
+		return nil, e.Wrapf(err, "open-transfer")
+	}
+
+	data := readPartial(partialPath, id)
+	if int64(len(data)) > size {
+		data = nil
+	}
+
+	if len(data) > 0 {
+		log.Infof("resuming %s transfer at %d/%d bytes", kind, len(data), size)
+	}
+
+	if err := ioutil.WriteFile(partialPath+".id", []byte(id), 0600); err != nil {
+		return nil, err
+	}
+
+	fd, err := os.OpenFile(partialPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	defer fd.Close()
+
+	if _, err := fd.Write(data); err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(data)
+	progress := TransferProgress{
+		Kind:    kind,
+		Done:    int64(buf.Len()),
+		Total:   size,
+		Started: time.Now(),
+	}
+
+	for progress.Done < size {
+		chunk, err := cl.readTransfer(id, progress.Done, transferChunkSize)
+		if err != nil {
+			return nil, e.Wrapf(err, "read-transfer")
+		}
+
+		if len(chunk) == 0 {
+			return nil, fmt.Errorf("transfer ended early at %d/%d bytes", progress.Done, size)
+		}
+
+		if _, err := fd.Write(chunk); err != nil {
+			return nil, err
+		}
+
+		buf.Write(chunk)
+		progress.Done += int64(len(chunk))
+		if fn != nil {
+			fn(progress)
+		}
+	}
+
+	if h.Sum(buf.Bytes()).B58String() != id {
+		// Do not resume from broken data next time.
+		os.Remove(partialPath + ".id")
+		return nil, fmt.Errorf("checksum of transfer %s does not match", id)
+	}
+
+	if err := cl.closeTransfer(id); err != nil {
+		log.Debugf("failed to close transfer %s: %v", id, err)
+	}
+
+	os.Remove(partialPath + ".id")
+	os.Remove(partialPath)
+	return buf.Bytes(), nil
+}
+
+// FetchStoreStream is like FetchStore, but transfers the store in chunks.
+// Received chunks are kept in `partialPath`. If an earlier call was
+// interrupted, the transfer continues where it stopped. `fn` is called
+// after each chunk and may be nil.
+func (cl *Client) FetchStoreStream(partialPath string, fn ProgressFunc) (*bytes.Buffer, error) {
+	data, err := cl.fetchTransfer(TransferKindStore, 0, partialPath, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewBuffer(data), nil
+}
+
+// FetchPatchesStream is like FetchPatches, but transfers the patches in
+// chunks. See FetchStoreStream for the meaning of `partialPath` and `fn`.
+func (cl *Client) FetchPatchesStream(fromIndex int64, partialPath string, fn ProgressFunc) ([]byte, error) {
+	return cl.fetchTransfer(TransferKindPatches, fromIndex, partialPath, fn)
+}
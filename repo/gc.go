@@ -17,6 +17,14 @@ func (rp *Repository) GC(backend Backend, aggressive bool) (map[string]map[strin
 	rp.mu.Lock()
 	defer rp.mu.Unlock()
 
+	// Give up the versions that the retention policies do not keep,
+	// so the backend can dispose them right away.
+	for owner, fs := range rp.fsMap {
+		if _, err := fs.EnforceRetention("/"); err != nil {
+			log.Warnf("failed to enforce retention policies for %s: %v", owner, err)
+		}
+	}
+
 	// `killed` are the content hashes the backend disposed.
 	killed, err := backend.GC()
 	if err != nil {
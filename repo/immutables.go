@@ -18,6 +18,7 @@ var immutableDefaultsV0 = config.DefaultMapping{
 		Docs:         "What backend type this repository uses",
 		Validator: config.EnumValidator(
 			"httpipfs",
+			"localfs",
 			"mock",
 		),
 	},
@@ -31,7 +31,7 @@ type InitOptions struct {
 // IsValidBackendName tells you if `name` is a valid backend name.
 func IsValidBackendName(name string) bool {
 	switch name {
-	case "mock", "httpipfs":
+	case "mock", "httpipfs", "localfs":
 		return true
 	default:
 		return false
@@ -53,7 +53,7 @@ func (opts InitOptions) Validate() error {
 
 // Init will create a new repository on disk at `baseFolder`.
 // `owner` will be the new owner and should be something like user@domain/resource.
-// `backendName` is the name of the backend, either "ipfs" or "mock".
+// `backendName` is the name of the backend, either "httpipfs", "localfs" or "mock".
 // `daemonPort` is the port of the local daemon.
 func Init(opts InitOptions) error {
 	if err := opts.Validate(); err != nil {
@@ -94,6 +94,51 @@ func decryptAsymetric(folder, owner string, data []byte) ([]byte, error) {
 	return ioutil.ReadAll(md.UnverifiedBody)
 }
 
+// signDetached creates a detached signature of `data`
+// with the private key found in `folder`.
+func signDetached(folder, owner string, data []byte) ([]byte, error) {
+	prvPath := filepath.Join(folder, owner, "key.prv")
+	fd, err := os.Open(prvPath) // #nosec
+	if err != nil {
+		return nil, err
+	}
+
+	defer util.Closer(fd)
+
+	ents, err := openpgp.ReadKeyRing(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ents) == 0 {
+		return nil, errors.New("no private key found")
+	}
+
+	sigBuf := &bytes.Buffer{}
+	if err := openpgp.DetachSign(sigBuf, ents[0], bytes.NewReader(data), nil); err != nil {
+		return nil, err
+	}
+
+	return sigBuf.Bytes(), nil
+}
+
+// VerifySignature checks if `sig` is a valid detached signature
+// of `data`, made by the owner of `pubKey`.
+func VerifySignature(pubKey, data, sig []byte) error {
+	ents, err := openpgp.ReadKeyRing(bytes.NewReader(pubKey))
+	if err != nil {
+		return err
+	}
+
+	_, err = openpgp.CheckDetachedSignature(
+		ents,
+		bytes.NewReader(data),
+		bytes.NewReader(sig),
+	)
+
+	return err
+}
+
 // Keyring manages our own keypair and stores the last known
 // pubkeys of other remotes.
 type Keyring struct {
@@ -124,6 +169,12 @@ func (kp *Keyring) Decrypt(data []byte) ([]byte, error) {
 	return decryptAsymetric(kp.folder, kp.owner, data)
 }
 
+// Sign creates a detached signature of `data` with our private key.
+// The signature can be checked by others with VerifySignature.
+func (kp *Keyring) Sign(data []byte) ([]byte, error) {
+	return signDetached(kp.folder, kp.owner, data)
+}
+
 // OwnPubKey returns an exported version of our own public key.
 func (kp *Keyring) OwnPubKey() ([]byte, error) {
 	pubPath := filepath.Join(kp.folder, kp.owner, "key.pub")
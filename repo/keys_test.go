@@ -34,6 +34,11 @@ func TestKeyring(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, testData, decTestData)
 
+	sig, err := kr.Sign(testData)
+	require.NoError(t, err)
+	require.NoError(t, VerifySignature(ownPubKey, testData, sig))
+	require.Error(t, VerifySignature(ownPubKey, []byte("Hello?"), sig))
+
 	require.Nil(t, kr.SavePubKey("a", []byte{1}))
 	require.Nil(t, kr.SavePubKey("a", []byte{1}))
 	remotePubKey, err := kr.PubKeyFor("a")
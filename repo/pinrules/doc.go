@@ -0,0 +1,8 @@
+// Package pinrules implements per-folder settings for the repinner.
+// A folder may have a quota, which limits how much pinned storage all
+// files below it may use, and a priority, which decides whose versions
+// are unpinned first when the global quota is exceeded.
+//
+// Like the hints, rules are stored in the repository as yaml file and
+// are kept in a trie during runtime.
+package pinrules
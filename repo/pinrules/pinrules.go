@@ -0,0 +1,345 @@
+package pinrules
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dustin/go-humanize"
+	e "github.com/pkg/errors"
+	"github.com/sahib/brig/util/trie"
+	"github.com/sahib/config"
+)
+
+var (
+	// ErrNoSuchRule is returned by Remove when there is no rule at this path.
+	ErrNoSuchRule = errors.New("no such pin rule at this path")
+
+	// ErrInvalidRule is returned upon setting an invalid rule.
+	ErrInvalidRule = errors.New("invalid pin rule")
+)
+
+// Priority decides which versions the repinner unpins first.
+type Priority string
+
+const (
+	// PriorityInherit means that the priority of the parent folder is used.
+	PriorityInherit = Priority("")
+
+	// PriorityLow folders lose their old versions first when the
+	// global quota is exceeded.
+	PriorityLow = Priority("low")
+
+	// PriorityNormal is the default priority.
+	PriorityNormal = Priority("normal")
+
+	// PriorityHigh folders are never unpinned because of a quota.
+	// Versions that are within the depth (or retention) settings are
+	// pinned, even if they were not before.
+	PriorityHigh = Priority("high")
+)
+
+// IsValid returns true if `p` is a valid priority.
+func (p Priority) IsValid() bool {
+	switch p {
+	case PriorityInherit, PriorityLow, PriorityNormal, PriorityHigh:
+		return true
+	default:
+		return false
+	}
+}
+
+// ValidPriorities returns all priorities that can be set.
+func ValidPriorities() []string {
+	return []string{
+		string(PriorityLow),
+		string(PriorityNormal),
+		string(PriorityHigh),
+	}
+}
+
+// Rule describes the repin settings of a folder.
+type Rule struct {
+	// Quota is the maximum amount of pinned storage all files below the
+	// folder may use. Zero means that there is no quota for the folder.
+	Quota uint64
+
+	// Priority is the priority of all files below the folder.
+	Priority Priority
+}
+
+// IsValid checks if the rule has a valid priority and sets anything at all.
+func (r Rule) IsValid() bool {
+	if !r.Priority.IsValid() {
+		return false
+	}
+
+	return r.Quota > 0 || r.Priority != PriorityInherit
+}
+
+func (r Rule) String() string {
+	quota := "none"
+	if r.Quota > 0 {
+		quota = humanize.Bytes(r.Quota)
+	}
+
+	priority := r.Priority
+	if priority == PriorityInherit {
+		priority = "inherit"
+	}
+
+	return fmt.Sprintf("quota:%s-priority:%s", quota, priority)
+}
+
+// ParseQuota reads a quota like "20GB". "none" or "0" mean no quota.
+func ParseQuota(s string) (uint64, error) {
+	if s == "" || s == "none" {
+		return 0, nil
+	}
+
+	return humanize.ParseBytes(s)
+}
+
+func quotaValidator(val interface{}) error {
+	s, ok := val.(string)
+	if !ok {
+		return errors.New("quota is not a string")
+	}
+
+	_, err := ParseQuota(s)
+	return err
+}
+
+func priorityValidator(val interface{}) error {
+	s, ok := val.(string)
+	if !ok {
+		return errors.New("priority is not a string")
+	}
+
+	if !Priority(s).IsValid() {
+		return fmt.Errorf("invalid priority: %s", s)
+	}
+
+	return nil
+}
+
+var (
+	defaults = config.DefaultMapping{
+		"pins": config.DefaultMapping{
+			"__many__": config.DefaultMapping{
+				"path": config.DefaultEntry{
+					Default:      "",
+					NeedsRestart: false,
+					Docs:         "The folder to apply the rule to.",
+				},
+				"quota": config.DefaultEntry{
+					Default:      "",
+					NeedsRestart: false,
+					Docs:         "Max. amount of pinned storage below the folder.",
+					Validator:    quotaValidator,
+				},
+				"priority": config.DefaultEntry{
+					Default:      "",
+					NeedsRestart: false,
+					Docs:         "Priority of the folder (low, normal, high).",
+					Validator:    priorityValidator,
+				},
+			},
+		},
+	}
+)
+
+func prefixSlash(path string) string {
+	if len(path) > 0 && path[0] != '/' {
+		path = "/" + path
+	}
+
+	return path
+}
+
+// RuleManager is a helper to store pin rules for certain folders.
+type RuleManager struct {
+	mu   sync.Mutex
+	root *trie.Node
+}
+
+// NewManager reads a YAML file from `yamlReader`.
+// If the reader is nil, then an empty file is assumed.
+//
+// All methods are safe to call from several go routines.
+func NewManager(yamlReader io.Reader) (*RuleManager, error) {
+	if yamlReader == nil {
+		return &RuleManager{root: trie.NewNode()}, nil
+	}
+
+	mgr := config.NewMigrater(1, config.StrictnessWarn)
+	mgr.Add(0, nil, defaults)
+
+	cfg, err := mgr.Migrate(config.NewYamlDecoder(yamlReader))
+	if err != nil {
+		return nil, e.Wrap(err, "failed to migrate or open")
+	}
+
+	root := trie.NewNode()
+
+	ruleMapping := cfg.Section("pins")
+	for _, key := range ruleMapping.Keys() {
+		if !strings.HasSuffix(key, ".path") {
+			continue
+		}
+
+		rulePath := ruleMapping.String(key)
+		prefixKey := strings.TrimSuffix(key, ".path")
+
+		quota, err := ParseQuota(ruleMapping.String(prefixKey + ".quota"))
+		if err != nil {
+			return nil, e.Wrapf(err, "quota of %s", rulePath)
+		}
+
+		root.InsertWithData(prefixSlash(rulePath), Rule{
+			Quota:    quota,
+			Priority: Priority(ruleMapping.String(prefixKey + ".priority")),
+		})
+	}
+
+	return &RuleManager{
+		root: root,
+	}, nil
+}
+
+// Get returns the rule that was set exactly at `path`.
+func (rm *RuleManager) Get(path string) (Rule, bool) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	nd := rm.root.Lookup(prefixSlash(path))
+	if nd == nil || nd.Data == nil {
+		return Rule{}, false
+	}
+
+	return nd.Data.(Rule), true
+}
+
+// lookup returns the nearest rule at or above `path` that matches `pred`.
+func (rm *RuleManager) lookup(path string, pred func(rule Rule) bool) (string, Rule, bool) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	for node := rm.root.LookupDeepest(prefixSlash(path)); node != nil; node = node.Parent {
+		if node.Data == nil {
+			continue
+		}
+
+		if rule := node.Data.(Rule); pred(rule) {
+			return prefixSlash(node.Path()), rule, true
+		}
+	}
+
+	return "", Rule{}, false
+}
+
+// LookupQuota returns the folder whose quota `path` counts against.
+// This is the nearest folder at or above `path` that has a quota.
+// If there is none, false is returned.
+func (rm *RuleManager) LookupQuota(path string) (string, uint64, bool) {
+	folder, rule, ok := rm.lookup(path, func(rule Rule) bool {
+		return rule.Quota > 0
+	})
+
+	return folder, rule.Quota, ok
+}
+
+// LookupPriority returns the priority of `path`, which is inherited
+// from the nearest folder that sets one. The default is PriorityNormal.
+func (rm *RuleManager) LookupPriority(path string) Priority {
+	_, rule, ok := rm.lookup(path, func(rule Rule) bool {
+		return rule.Priority != PriorityInherit
+	})
+
+	if !ok {
+		return PriorityNormal
+	}
+
+	return rule.Priority
+}
+
+// Set remembers `rule` for `path`.
+func (rm *RuleManager) Set(path string, rule Rule) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if !rule.IsValid() {
+		return ErrInvalidRule
+	}
+
+	rm.root.InsertWithData(prefixSlash(path), rule)
+	return nil
+}
+
+// Remove forgets the rule at `path`.
+// Rules set below `path` are not affected.
+func (rm *RuleManager) Remove(path string) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	nd := rm.root.Lookup(prefixSlash(path))
+	if nd == nil || nd.Data == nil {
+		return ErrNoSuchRule
+	}
+
+	if len(nd.Children) > 0 {
+		nd.Data = nil
+		return nil
+	}
+
+	nd.Remove()
+	return nil
+}
+
+// List returns a map of all paths with their corresponding rules.
+func (rm *RuleManager) List() map[string]Rule {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	return rm.list()
+}
+
+// list() is used both by Save() and List()
+func (rm *RuleManager) list() map[string]Rule {
+	rules := make(map[string]Rule)
+
+	rm.root.Walk(true, func(node *trie.Node) bool {
+		if node.Data == nil {
+			return true
+		}
+
+		path := prefixSlash(node.Path())
+		rules[path] = node.Data.(Rule)
+		return true
+	})
+
+	return rules
+}
+
+// Save writes a YAML representation of the rules to `w`.
+func (rm *RuleManager) Save(w io.Writer) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	emptyCfg, err := config.Open(nil, defaults, config.StrictnessWarn)
+	if err != nil {
+		return err
+	}
+
+	ruleMapping := emptyCfg.Section("pins")
+	for path, rule := range rm.list() {
+		ruleMapping.SetString(path+".path", path)
+		ruleMapping.SetString(path+".quota", strconv.FormatUint(rule.Quota, 10))
+		ruleMapping.SetString(path+".priority", string(rule.Priority))
+	}
+
+	return emptyCfg.Save(config.NewYamlEncoder(w))
+}
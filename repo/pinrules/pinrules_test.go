@@ -0,0 +1,59 @@
+package pinrules
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleManager(t *testing.T) {
+	mgr, err := NewManager(nil)
+	require.NoError(t, err)
+
+	_, _, ok := mgr.LookupQuota("/media/x")
+	require.False(t, ok)
+	require.Equal(t, PriorityNormal, mgr.LookupPriority("/media/x"))
+
+	require.Equal(t, ErrInvalidRule, mgr.Set("/media", Rule{}))
+	require.Equal(t, ErrInvalidRule, mgr.Set("/media", Rule{Priority: "urgent"}))
+
+	media := Rule{Quota: 20 * 1000 * 1000 * 1000, Priority: PriorityLow}
+	require.NoError(t, mgr.Set("/media", media))
+	require.NoError(t, mgr.Set("/media/best", Rule{Priority: PriorityHigh}))
+	require.NoError(t, mgr.Set("/docs", Rule{Priority: PriorityHigh}))
+
+	// The nested rule has no quota, so the one of /media is used:
+	folder, quota, ok := mgr.LookupQuota("/media/best/x")
+	require.True(t, ok)
+	require.Equal(t, "/media", folder)
+	require.Equal(t, media.Quota, quota)
+	require.Equal(t, PriorityHigh, mgr.LookupPriority("/media/best/x"))
+	require.Equal(t, PriorityLow, mgr.LookupPriority("/media/x"))
+
+	_, _, ok = mgr.LookupQuota("/docs/x")
+	require.False(t, ok)
+
+	yamlBuf := bytes.NewBuffer(nil)
+	require.NoError(t, mgr.Save(yamlBuf))
+	oldYaml := yamlBuf.String()
+
+	// Check if a freshly loaded one behaves exactly same:
+	newMgr, err := NewManager(yamlBuf)
+	require.NoError(t, err)
+	require.Equal(t, mgr.List(), newMgr.List())
+
+	newYamlBuf := bytes.NewBuffer(nil)
+	require.NoError(t, newMgr.Save(newYamlBuf))
+	require.Equal(t, oldYaml, newYamlBuf.String())
+
+	rule, ok := newMgr.Get("/media")
+	require.True(t, ok)
+	require.Equal(t, media, rule)
+
+	require.Equal(t, ErrNoSuchRule, newMgr.Remove("/media/x"))
+	require.NoError(t, newMgr.Remove("/media"))
+	_, _, ok = newMgr.LookupQuota("/media/best/x")
+	require.False(t, ok)
+	require.Equal(t, PriorityHigh, newMgr.LookupPriority("/media/best/x"))
+}
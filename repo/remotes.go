@@ -55,13 +55,18 @@ type Remote struct {
 	// updates from other peers that support this.
 	AcceptAutoUpdates bool
 
-	// ConflictStrategy sets the Either "marker", "ignore", "embrace".  If an
+	// ConflictStrategy sets the Either "marker", "ignore", "embrace", "merge".  If an
 	// empty string (default) then the config value fs.sync.conflict_strategy"
 	// is taken.
 	ConflictStrategy string
 
 	// AcceptPush will allow this remote to push data to us if true.
 	AcceptPush bool
+
+	// Addresses is a list of network addresses (host:port) where the
+	// remote can be reached directly. It is only used when the
+	// "direct" transport is configured in net.transport.
+	Addresses []string
 }
 
 // ReadOnlyFolders returns the folders that are set to read only
@@ -235,6 +240,7 @@ func (rl *RemoteList) SaveList(remotes []Remote) error {
 			Name:        remote.Name,
 			Fingerprint: remote.Fingerprint,
 			Folders:     remote.Folders,
+			Addresses:   remote.Addresses,
 		}
 	}
 
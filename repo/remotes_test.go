@@ -20,6 +20,7 @@ var (
 				Folder: "/ShowOff",
 			},
 		},
+		Addresses: []string{"192.168.1.5:6002"},
 	}
 	charlieRemote = Remote{
 		Name:        "charlie",
@@ -57,6 +58,7 @@ func TestRemotesReload(t *testing.T) {
 		t.Fatalf("Fingerprints are differing: %v", remotes[0].Fingerprint)
 	}
 	require.Equal(t, remotes[0].Folders, bobRemote.Folders)
+	require.Equal(t, remotes[0].Addresses, bobRemote.Addresses)
 }
 
 func TestRemoteOps(t *testing.T) {
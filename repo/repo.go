@@ -12,6 +12,8 @@ import (
 	"github.com/sahib/brig/catfs/mio/pagecache/mdcache"
 	"github.com/sahib/brig/defaults"
 	"github.com/sahib/brig/repo/hints"
+	"github.com/sahib/brig/repo/pinrules"
+	"github.com/sahib/brig/repo/retention"
 	"github.com/sahib/config"
 	log "github.com/sirupsen/logrus"
 )
@@ -23,6 +25,9 @@ import (
 // config.yml
 // immutables.yml
 // remotes.yml
+// hints.yml
+// retention.yml
+// pins.yml
 // keyring/
 //    <remote_name>
 //        key.prv
@@ -32,6 +37,8 @@ import (
 //        (fs-backend specific)
 // gateway/
 //    (gateway specific)
+// data/
+//    (only used by the localfs backend)
 type Repository struct {
 	mu sync.Mutex
 
@@ -54,6 +61,12 @@ type Repository struct {
 	// Hints are streaming settings
 	Hints *hints.HintManager
 
+	// Retention decides which old versions of a file are kept
+	Retention *retention.PolicyManager
+
+	// PinRules are per-folder quotas and priorities for repinning
+	PinRules *pinrules.RuleManager
+
 	// channel to control the auto gc loop
 	autoGCControl chan bool
 }
@@ -74,6 +87,38 @@ func loadHintManager(hintsPath string) (*hints.HintManager, error) {
 	return hints.NewManager(hintsFd)
 }
 
+func loadRetentionManager(retentionPath string) (*retention.PolicyManager, error) {
+	retentionFd, err := os.Open(retentionPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, e.Wrap(err, "failed to open retention.yml")
+	}
+
+	if os.IsNotExist(err) {
+		// No policies were set yet.
+		return retention.NewManager(nil)
+	}
+
+	defer retentionFd.Close()
+
+	return retention.NewManager(retentionFd)
+}
+
+func loadPinRuleManager(pinsPath string) (*pinrules.RuleManager, error) {
+	pinsFd, err := os.Open(pinsPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, e.Wrap(err, "failed to open pins.yml")
+	}
+
+	if os.IsNotExist(err) {
+		// No rules were set yet.
+		return pinrules.NewManager(nil)
+	}
+
+	defer pinsFd.Close()
+
+	return pinrules.NewManager(pinsFd)
+}
+
 // Open will open the repository at `baseFolder`
 func Open(baseFolder string) (*Repository, error) {
 	immutables, err := NewImmutables(filepath.Join(baseFolder, "immutable.yml"))
@@ -101,12 +146,24 @@ func Open(baseFolder string) (*Repository, error) {
 		return nil, err
 	}
 
+	retentionMgr, err := loadRetentionManager(filepath.Join(baseFolder, "retention.yml"))
+	if err != nil {
+		return nil, err
+	}
+
+	pinRuleMgr, err := loadPinRuleManager(filepath.Join(baseFolder, "pins.yml"))
+	if err != nil {
+		return nil, err
+	}
+
 	return &Repository{
 		BaseFolder:    baseFolder,
 		Immutables:    immutables,
 		Config:        cfg,
 		Remotes:       remotes,
 		Hints:         hintsMgr,
+		Retention:     retentionMgr,
+		PinRules:      pinRuleMgr,
 		fsMap:         make(map[string]*catfs.FS),
 		autoGCControl: make(chan bool, 1),
 	}, nil
@@ -123,6 +180,17 @@ func (rp *Repository) Close() error {
 	return nil
 }
 
+// BackendPath returns the location the backend should use to store or
+// find its data. For httpipfs this is the IPFS repository (or its API addr),
+// for localfs the data folder inside of the repository.
+func (rp *Repository) BackendPath() string {
+	if rp.Immutables.Backend() == "localfs" {
+		return filepath.Join(rp.BaseFolder, "data")
+	}
+
+	return rp.Config.String("daemon.ipfs_path_or_url")
+}
+
 // HaveFS will return true if we have data for a certain owner.
 func (rp *Repository) HaveFS(owner string) bool {
 	rp.mu.Lock()
@@ -193,6 +261,19 @@ func (rp *Repository) FS(owner string, bk catfs.FsBackend) (*catfs.FS, error) {
 		return nil, err
 	}
 
+	// Sign our own commits, so others can check that we made them.
+	if !isReadOnly {
+		kr, err := rp.Keyring()
+		if err != nil {
+			return nil, err
+		}
+
+		fs.SetSignFunc(kr.Sign)
+	}
+
+	fs.SetRetentionManager(rp.Retention)
+	fs.SetPinRuleManager(rp.PinRules)
+
 	// Create an initial commit if there was none yet:
 	if _, err := fs.Head(); fserr.IsErrNoSuchRef(err) {
 		if err := fs.MakeCommit("initial commit"); err != nil {
@@ -248,3 +329,31 @@ func (rp *Repository) SaveHints() error {
 
 	return rp.Hints.Save(fd)
 }
+
+// SaveRetention dumps the retention policies to disk.
+// You should call this whenever Retention is changed.
+func (rp *Repository) SaveRetention() error {
+	retentionPath := filepath.Join(rp.BaseFolder, "retention.yml")
+	fd, err := os.OpenFile(retentionPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	defer fd.Close()
+
+	return rp.Retention.Save(fd)
+}
+
+// SavePinRules dumps the per-folder pin rules to disk.
+// You should call this whenever PinRules are changed.
+func (rp *Repository) SavePinRules() error {
+	pinsPath := filepath.Join(rp.BaseFolder, "pins.yml")
+	fd, err := os.OpenFile(pinsPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	defer fd.Close()
+
+	return rp.PinRules.Save(fd)
+}
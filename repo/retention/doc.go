@@ -0,0 +1,9 @@
+// Package retention implements per-path rules that decide which old
+// versions of a file are worth keeping. A policy consists of rules like
+// "one version per hour for the last day" and "one version per day for the
+// last month". Versions that are not selected by any rule are given up.
+//
+// Like the hints, policies are stored in the repository as yaml file and
+// are kept in a trie during runtime. A policy applies to all files below
+// its path, unless there is another policy lower in the hierarchy.
+package retention
@@ -0,0 +1,385 @@
+package retention
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	e "github.com/pkg/errors"
+	"github.com/sahib/brig/util/trie"
+	"github.com/sahib/config"
+)
+
+var (
+	// ErrNoSuchPolicy is returned by Remove when there is no policy at this path.
+	ErrNoSuchPolicy = errors.New("no such retention policy at this path")
+
+	// ErrInvalidPolicy is returned upon setting an invalid policy.
+	ErrInvalidPolicy = errors.New("invalid retention policy")
+)
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+	year = 365 * day
+)
+
+// ParseDuration works like time.ParseDuration, but also knows about
+// days (d), weeks (w) and years (y).
+func ParseDuration(s string) (time.Duration, error) {
+	if len(s) > 1 {
+		unit := map[byte]time.Duration{
+			'd': day,
+			'w': week,
+			'y': year,
+		}[s[len(s)-1]]
+
+		if unit != 0 {
+			count, err := strconv.ParseFloat(s[:len(s)-1], 64)
+			if err != nil {
+				return 0, fmt.Errorf("bad duration »%s«", s)
+			}
+
+			return time.Duration(count * float64(unit)), nil
+		}
+	}
+
+	return time.ParseDuration(s)
+}
+
+// formatDuration is the reverse of ParseDuration.
+func formatDuration(d time.Duration) string {
+	for _, unit := range []struct {
+		size time.Duration
+		name string
+	}{
+		{year, "y"},
+		{week, "w"},
+		{day, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+		{time.Second, "s"},
+	} {
+		if d >= unit.size && d%unit.size == 0 {
+			return fmt.Sprintf("%d%s", d/unit.size, unit.name)
+		}
+	}
+
+	return d.String()
+}
+
+// Rule keeps one version per `Every` for all versions younger than `For`.
+type Rule struct {
+	// Every is the size of the time slots. From each slot only the newest
+	// version is kept. If it is zero, every version is kept.
+	Every time.Duration
+
+	// For is the maximum age of the versions this rule applies to.
+	For time.Duration
+}
+
+func (r Rule) String() string {
+	every := "all"
+	if r.Every > 0 {
+		every = formatDuration(r.Every)
+	}
+
+	return every + ":" + formatDuration(r.For)
+}
+
+// Policy is a set of rules. A version is kept if any rule selects it.
+type Policy struct {
+	Rules []Rule
+}
+
+// ParsePolicy reads a policy in the form "<every>:<for>,...".
+// For example "1h:1d,1d:30d" keeps hourly versions for a day and
+// daily versions for a month. Use "all" as <every> to keep each version.
+func ParsePolicy(s string) (Policy, error) {
+	policy := Policy{}
+	for _, spec := range strings.Split(s, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		split := strings.SplitN(spec, ":", 2)
+		if len(split) != 2 {
+			return Policy{}, fmt.Errorf("bad rule »%s«: must be <every>:<for>", spec)
+		}
+
+		rule := Rule{}
+		if everySpec := strings.TrimSpace(split[0]); everySpec != "all" {
+			every, err := ParseDuration(everySpec)
+			if err != nil {
+				return Policy{}, e.Wrapf(err, "bad rule »%s«", spec)
+			}
+
+			rule.Every = every
+		}
+
+		forDur, err := ParseDuration(strings.TrimSpace(split[1]))
+		if err != nil {
+			return Policy{}, e.Wrapf(err, "bad rule »%s«", spec)
+		}
+
+		rule.For = forDur
+		policy.Rules = append(policy.Rules, rule)
+	}
+
+	if !policy.IsValid() {
+		return Policy{}, ErrInvalidPolicy
+	}
+
+	return policy, nil
+}
+
+// IsValid checks if the policy has at least one rule and if all rules
+// have sensible durations.
+func (p Policy) IsValid() bool {
+	if len(p.Rules) == 0 {
+		return false
+	}
+
+	for _, rule := range p.Rules {
+		if rule.Every < 0 || rule.For <= 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p Policy) String() string {
+	specs := []string{}
+	for _, rule := range p.Rules {
+		specs = append(specs, rule.String())
+	}
+
+	return strings.Join(specs, ",")
+}
+
+// Keep decides which versions should be kept. `dates` are the dates of
+// each version, newest first. The returned slice has an entry for each
+// version. The newest version is always kept, no matter how old it is.
+//
+// The time slots of a rule are counted from the unix epoch, so that the
+// same versions are kept no matter when Keep() is called.
+func (p Policy) Keep(dates []time.Time, now time.Time) []bool {
+	keep := make([]bool, len(dates))
+	if len(dates) > 0 {
+		keep[0] = true
+	}
+
+	for _, rule := range p.Rules {
+		seen := make(map[int64]bool)
+		for idx, date := range dates {
+			if now.Sub(date) >= rule.For {
+				continue
+			}
+
+			if rule.Every <= 0 {
+				keep[idx] = true
+				continue
+			}
+
+			slot := date.UnixNano() / int64(rule.Every)
+			if seen[slot] {
+				continue
+			}
+
+			seen[slot] = true
+			keep[idx] = true
+		}
+	}
+
+	return keep
+}
+
+func policyValidator(val interface{}) error {
+	s, ok := val.(string)
+	if !ok {
+		return errors.New("policy is not a string")
+	}
+
+	if s == "" {
+		return nil
+	}
+
+	_, err := ParsePolicy(s)
+	return err
+}
+
+var (
+	defaults = config.DefaultMapping{
+		"retention": config.DefaultMapping{
+			"__many__": config.DefaultMapping{
+				"path": config.DefaultEntry{
+					Default:      "",
+					NeedsRestart: false,
+					Docs:         "The path to apply the policy to. Recursive if directory.",
+				},
+				"policy": config.DefaultEntry{
+					Default:      "",
+					NeedsRestart: false,
+					Docs:         "Which versions to keep, like 1h:1d,1d:30d.",
+					Validator:    policyValidator,
+				},
+			},
+		},
+	}
+)
+
+func prefixSlash(path string) string {
+	if len(path) > 0 && path[0] != '/' {
+		path = "/" + path
+	}
+
+	return path
+}
+
+// PolicyManager is a helper to store retention policies for certain paths.
+type PolicyManager struct {
+	mu   sync.Mutex
+	root *trie.Node
+}
+
+// NewManager reads a YAML file from `yamlReader`.
+// If the reader is nil, then an empty file is assumed.
+// Unlike with hints, there is no default policy; without one all versions
+// are kept.
+//
+// All methods are safe to call from several go routines.
+func NewManager(yamlReader io.Reader) (*PolicyManager, error) {
+	if yamlReader == nil {
+		return &PolicyManager{root: trie.NewNode()}, nil
+	}
+
+	mgr := config.NewMigrater(1, config.StrictnessWarn)
+	mgr.Add(0, nil, defaults)
+
+	cfg, err := mgr.Migrate(config.NewYamlDecoder(yamlReader))
+	if err != nil {
+		return nil, e.Wrap(err, "failed to migrate or open")
+	}
+
+	root := trie.NewNode()
+
+	policyMapping := cfg.Section("retention")
+	for _, key := range policyMapping.Keys() {
+		if !strings.HasSuffix(key, ".path") {
+			continue
+		}
+
+		policyPath := policyMapping.String(key)
+		prefixKey := strings.TrimSuffix(key, ".path")
+
+		policy, err := ParsePolicy(policyMapping.String(prefixKey + ".policy"))
+		if err != nil {
+			return nil, e.Wrapf(err, "policy of %s", policyPath)
+		}
+
+		root.InsertWithData(prefixSlash(policyPath), policy)
+	}
+
+	return &PolicyManager{
+		root: root,
+	}, nil
+}
+
+// Lookup returns the policy for `path`. If there is no policy for `path`
+// directly, the policy of the nearest parent is returned. If there is none
+// at all, false is returned.
+func (pm *PolicyManager) Lookup(path string) (Policy, bool) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	for node := pm.root.LookupDeepest(prefixSlash(path)); node != nil; node = node.Parent {
+		if node.Data != nil {
+			return node.Data.(Policy), true
+		}
+	}
+
+	return Policy{}, false
+}
+
+// Set remembers `policy` for `path`.
+func (pm *PolicyManager) Set(path string, policy Policy) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if !policy.IsValid() {
+		return ErrInvalidPolicy
+	}
+
+	pm.root.InsertWithData(prefixSlash(path), policy)
+	return nil
+}
+
+// Remove forgets the policy at `path`.
+// Policies set below `path` are not affected.
+func (pm *PolicyManager) Remove(path string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	nd := pm.root.Lookup(prefixSlash(path))
+	if nd == nil || nd.Data == nil {
+		return ErrNoSuchPolicy
+	}
+
+	if len(nd.Children) > 0 {
+		nd.Data = nil
+		return nil
+	}
+
+	nd.Remove()
+	return nil
+}
+
+// List returns a map of all paths with their corresponding policies.
+func (pm *PolicyManager) List() map[string]Policy {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	return pm.list()
+}
+
+// list() is used both by Save() and List()
+func (pm *PolicyManager) list() map[string]Policy {
+	policies := make(map[string]Policy)
+
+	pm.root.Walk(true, func(node *trie.Node) bool {
+		if node.Data == nil {
+			return true
+		}
+
+		path := prefixSlash(node.Path())
+		policies[path] = node.Data.(Policy)
+		return true
+	})
+
+	return policies
+}
+
+// Save writes a YAML representation of the policies to `w`.
+func (pm *PolicyManager) Save(w io.Writer) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	emptyCfg, err := config.Open(nil, defaults, config.StrictnessWarn)
+	if err != nil {
+		return err
+	}
+
+	policyMapping := emptyCfg.Section("retention")
+	for path, policy := range pm.list() {
+		policyMapping.SetString(path+".path", path)
+		policyMapping.SetString(path+".policy", policy.String())
+	}
+
+	return emptyCfg.Save(config.NewYamlEncoder(w))
+}
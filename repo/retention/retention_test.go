@@ -0,0 +1,99 @@
+package retention
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy("1h:1d, 1d:30d,all:2w")
+	require.NoError(t, err)
+	require.Equal(t, []Rule{
+		{Every: time.Hour, For: 24 * time.Hour},
+		{Every: 24 * time.Hour, For: 30 * 24 * time.Hour},
+		{Every: 0, For: 14 * 24 * time.Hour},
+	}, policy.Rules)
+	require.Equal(t, "1h:1d,1d:30d,all:2w", policy.String())
+
+	for _, bad := range []string{"", "1h", "1h:0s", "x:1d", "1h:y", "-1h:1d"} {
+		_, err := ParsePolicy(bad)
+		require.Error(t, err, bad)
+	}
+}
+
+func TestPolicyKeep(t *testing.T) {
+	now := time.Date(2020, 6, 15, 12, 30, 0, 0, time.UTC)
+	dates := []time.Time{
+		now.Add(-10 * time.Minute),    // newest, always kept
+		now.Add(-20 * time.Minute),    // same hour as the one before
+		now.Add(-2 * time.Hour),       // own hour
+		now.Add(-30 * time.Hour),      // older than a day, own day
+		now.Add(-31 * time.Hour),      // same day as the one before
+		now.Add(-40 * 24 * time.Hour), // older than the policy
+	}
+
+	policy, err := ParsePolicy("1h:1d,1d:30d")
+	require.NoError(t, err)
+	require.Equal(t, []bool{true, false, true, true, false, false}, policy.Keep(dates, now))
+
+	// The newest version survives, even if it is too old:
+	require.Equal(t, []bool{true}, policy.Keep(dates[5:], now))
+	require.Empty(t, policy.Keep(nil, now))
+}
+
+func TestPolicyManager(t *testing.T) {
+	mgr, err := NewManager(nil)
+	require.NoError(t, err)
+
+	_, ok := mgr.Lookup("/a/b/c")
+	require.False(t, ok)
+
+	expect, err := ParsePolicy("1h:1d,1d:30d")
+	require.NoError(t, err)
+	require.Equal(t, ErrInvalidPolicy, mgr.Set("/a", Policy{}))
+	require.NoError(t, mgr.Set("/a", expect))
+
+	nested, err := ParsePolicy("all:1w")
+	require.NoError(t, err)
+	require.NoError(t, mgr.Set("/a/b", nested))
+
+	policy, ok := mgr.Lookup("/a/x")
+	require.True(t, ok)
+	require.Equal(t, expect, policy)
+
+	policy, ok = mgr.Lookup("/a/b/c")
+	require.True(t, ok)
+	require.Equal(t, nested, policy)
+
+	require.Equal(t, map[string]Policy{
+		"/a":   expect,
+		"/a/b": nested,
+	}, mgr.List())
+
+	yamlBuf := bytes.NewBuffer(nil)
+	require.NoError(t, mgr.Save(yamlBuf))
+	oldYaml := yamlBuf.String()
+
+	// Check if a freshly loaded one behaves exactly same:
+	newMgr, err := NewManager(yamlBuf)
+	require.NoError(t, err)
+	require.Equal(t, mgr.List(), newMgr.List())
+
+	newYamlBuf := bytes.NewBuffer(nil)
+	require.NoError(t, newMgr.Save(newYamlBuf))
+	require.Equal(t, oldYaml, newYamlBuf.String())
+
+	// Removing a parent policy keeps the ones below:
+	require.Equal(t, ErrNoSuchPolicy, newMgr.Remove("/a/x"))
+	require.NoError(t, newMgr.Remove("/a"))
+
+	_, ok = newMgr.Lookup("/a/x")
+	require.False(t, ok)
+
+	policy, ok = newMgr.Lookup("/a/b/c")
+	require.True(t, ok)
+	require.Equal(t, nested, policy)
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
@@ -9,6 +10,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -25,6 +27,7 @@ import (
 	"github.com/sahib/brig/fuse"
 	"github.com/sahib/brig/gateway"
 	p2pnet "github.com/sahib/brig/net"
+	"github.com/sahib/brig/net/direct"
 	"github.com/sahib/brig/net/peer"
 	"github.com/sahib/brig/repo"
 	"github.com/sahib/brig/server/capnp"
@@ -64,6 +67,9 @@ type base struct {
 
 	// pprofPort is the port pprof can acquire profiling from
 	pprofPort int
+
+	// transfers tracks the progress of running fetches
+	transfers *p2pnet.TransferMonitor
 }
 
 func repoIsInitialized(path string) error {
@@ -181,7 +187,7 @@ func (b *base) loadBackend() error {
 
 	realBackend, err := backend.FromName(
 		backendName,
-		b.repo.Config.String("daemon.ipfs_path_or_url"),
+		b.repo.BackendPath(),
 		fingerprint.PubKeyID(),
 	)
 
@@ -190,6 +196,18 @@ func (b *base) loadBackend() error {
 		return err
 	}
 
+	if b.repo.Config.String("net.transport") == "direct" {
+		listenAddr := b.repo.Config.String("net.direct.listen_addr")
+		log.Infof("using direct transport on %s", listenAddr)
+
+		directNode, err := direct.NewNode(listenAddr, kr, b.repo.Remotes)
+		if err != nil {
+			return err
+		}
+
+		realBackend = backend.WithTransport(realBackend, directNode)
+	}
+
 	b.backend = realBackend
 	b.repo.StartAutoGCLoop(realBackend)
 	return nil
@@ -431,7 +449,104 @@ func newBase(
 		basePath:  basePath,
 		quitCh:    quitCh,
 		conductor: conductor.New(5*time.Minute, 100),
+		transfers: p2pnet.NewTransferMonitor(),
+	}
+}
+
+// fetchAttempts is the number of connections we try before giving up on a
+// fetch. Received data is kept between attempts, so each one continues
+// where the last one stopped.
+const fetchAttempts = 3
+
+func (b *base) transferPath(who, kind string, fromIndex int64) (string, error) {
+	dir := filepath.Join(b.basePath, "transfers")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("%s-%s-%d", kind, who, fromIndex)
+	return filepath.Join(dir, strings.Replace(name, "/", "_", -1)), nil
+}
+
+// fetchData fetches a store or patches from `who` over one connection.
+// Remotes that do not know about transfers yet are asked the old way.
+func (b *base) fetchData(ctl *p2pnet.Client, who string, isStore bool, fromIndex int64) ([]byte, error) {
+	kind := p2pnet.TransferKindPatches
+	if isStore {
+		kind = p2pnet.TransferKindStore
+	}
+
+	partialPath, err := b.transferPath(who, kind, fromIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	progressFn := func(progress p2pnet.TransferProgress) {
+		progress.Remote = who
+		b.transfers.Update(progress)
+	}
+
+	var data []byte
+	if isStore {
+		var buf *bytes.Buffer
+		if buf, err = ctl.FetchStoreStream(partialPath, progressFn); err == nil {
+			data = buf.Bytes()
+		}
+	} else {
+		data, err = ctl.FetchPatchesStream(fromIndex, partialPath, progressFn)
+	}
+
+	if err == nil || !p2pnet.IsTransferUnsupported(err) {
+		return data, err
+	}
+
+	log.Debugf("fetch: %s does not support transfers: %v", who, err)
+	if isStore {
+		buf, err := ctl.FetchStore()
+		if err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+
+	return ctl.FetchPatches(fromIndex)
+}
+
+// verifierFor returns a function that checks signatures made by `who`.
+// The public key of a remote is stored on fetch and must match
+// the fingerprint in our remote list.
+func (b *base) verifierFor(who string) (func(data, sig []byte) error, error) {
+	kr, err := b.repo.Keyring()
+	if err != nil {
+		return nil, err
+	}
+
+	var pubKey []byte
+	if who == b.repo.Immutables.Owner() {
+		pubKey, err = kr.OwnPubKey()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		rmt, err := b.repo.Remotes.Remote(who)
+		if err != nil {
+			return nil, err
+		}
+
+		pubKey, err = kr.PubKeyFor(who)
+		if err != nil {
+			return nil, e.Wrapf(err, "no public key known for %s (fetch first)", who)
+		}
+
+		if !rmt.Fingerprint.PubKeyMatches(pubKey) {
+			return nil, fmt.Errorf("public key of %s does not match its fingerprint", who)
+		}
 	}
+
+	return func(data, sig []byte) error {
+		return repo.VerifySignature(pubKey, data, sig)
+	}, nil
 }
 
 func (b *base) doFetch(who string) error {
@@ -441,39 +556,76 @@ func (b *base) doFetch(who string) error {
 		return nil
 	}
 
-	return b.withNetClient(who, func(ctl *p2pnet.Client) error {
-		return b.withRemoteFs(who, func(remoteFs *catfs.FS) error {
-			// Not all remotes might allow doing a full fetch.
-			// This is only possible when having full access to all folders.
-			if isAllowed, err := ctl.IsCompleteFetchAllowed(); isAllowed && err != nil {
-				log.Debugf("fetch: doing complete fetch for %s", who)
-				storeBuf, err := ctl.FetchStore()
-				if err != nil {
+	defer b.transfers.Finish(who)
+
+	return b.withRemoteFs(who, func(remoteFs *catfs.FS) error {
+		var data []byte
+		var isStore bool
+		var err error
+
+		for attempt := 1; attempt <= fetchAttempts; attempt++ {
+			err = b.withNetClient(who, func(ctl *p2pnet.Client) error {
+				// Remember their key, so we can check the signatures of their commits.
+				if err := b.savePubKey(who, ctl.RemotePubKey()); err != nil {
+					return err
+				}
+
+				// Not all remotes might allow doing a full fetch.
+				// This is only possible when having full access to all folders.
+				if isAllowed, err := ctl.IsCompleteFetchAllowed(); isAllowed && err != nil {
+					log.Debugf("fetch: doing complete fetch for %s", who)
+					isStore = true
+					data, err = b.fetchData(ctl, who, true, 0)
 					return e.Wrapf(err, "fetch-store")
 				}
 
-				return e.Wrapf(remoteFs.Import(storeBuf), "import")
-			}
+				// Ask our local copy of the remote what the last patch index was.
+				fromIndex, err := remoteFs.LastPatchIndex()
+				if err != nil {
+					return err
+				}
 
-			// Ask our local copy of the remote what the last patch index was.
-			fromIndex, err := remoteFs.LastPatchIndex()
-			if err != nil {
+				// Get the missing changes since then:
+				log.Infof("fetch: doing partial fetch for %s starting at %d", who, fromIndex)
+				data, err = b.fetchData(ctl, who, false, fromIndex)
 				return err
-			}
+			})
 
-			// Get the missing changes since then:
-			log.Infof("fetch: doing partial fetch for %s starting at %d", who, fromIndex)
-			patches, err := ctl.FetchPatches(fromIndex)
-			if err != nil {
-				return err
+			if err == nil {
+				break
 			}
 
-			return remoteFs.ApplyPatches(patches)
-		})
+			log.Warningf("fetch: attempt %d/%d with %s failed: %v", attempt, fetchAttempts, who, err)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		verify, err := b.verifierFor(who)
+		if err != nil {
+			return err
+		}
+
+		if isStore {
+			err := remoteFs.Import(bytes.NewReader(data), catfs.SyncOptVerifySignatures(verify))
+			return e.Wrapf(err, "import")
+		}
+
+		return remoteFs.ApplyPatches(data, catfs.SyncOptVerifySignatures(verify))
 	})
 }
 
-func (b *base) doSync(withWhom string, needFetch bool, msg string) (*catfs.Diff, error) {
+func (b *base) savePubKey(who string, pubKey []byte) error {
+	kr, err := b.repo.Keyring()
+	if err != nil {
+		return err
+	}
+
+	return kr.SavePubKey(who, pubKey)
+}
+
+func (b *base) doSync(withWhom string, needFetch bool, msg string, options ...catfs.SyncOption) (*catfs.Diff, error) {
 	if needFetch {
 		if err := b.doFetch(withWhom); err != nil {
 			return nil, e.Wrapf(err, "fetch")
@@ -503,13 +655,20 @@ func (b *base) doSync(withWhom string, needFetch bool, msg string) (*catfs.Diff,
 				return err
 			}
 
-			err = ownFs.Sync(
-				remoteFs,
+			verify, err := b.verifierFor(withWhom)
+			if err != nil {
+				return err
+			}
+
+			options = append([]catfs.SyncOption{
+				catfs.SyncOptVerifySignatures(verify),
 				catfs.SyncOptMessage(msg),
 				catfs.SyncOptConflictStrategy(rmt.ConflictStrategy),
 				catfs.SyncOptReadOnlyFolders(rmt.ReadOnlyFolders()),
 				catfs.SyncOptConflictgStrategyPerFolder(rmt.ConflictStrategyPerFolder()),
-			)
+			}, options...)
+
+			err = ownFs.Sync(remoteFs, options...)
 
 			if err != nil {
 				return err
@@ -12,6 +12,7 @@ import (
 	"github.com/sahib/brig/catfs"
 	ie "github.com/sahib/brig/catfs/errors"
 	"github.com/sahib/brig/server/capnp"
+	"github.com/sahib/brig/util/conductor"
 	log "github.com/sirupsen/logrus"
 	capnplib "zombiezen.com/go/capnproto2"
 	"zombiezen.com/go/capnproto2/server"
@@ -60,6 +61,10 @@ func statToCapnp(fs *catfs.FS, info *catfs.StatInfo, seg *capnplib.Segment) (*ca
 		return nil, err
 	}
 
+	if err := capInfo.SetTarget(info.Target); err != nil {
+		return nil, err
+	}
+
 	hint := fs.Hints().Lookup(info.Path)
 	capHint, err := hintToCapnp(seg, info.Path, hint)
 	if err != nil {
@@ -74,10 +79,15 @@ func statToCapnp(fs *catfs.FS, info *catfs.StatInfo, seg *capnplib.Segment) (*ca
 	capInfo.SetCachedSize(info.CachedSize)
 	capInfo.SetInode(info.Inode)
 	capInfo.SetIsDir(info.IsDir)
+	capInfo.SetIsSymlink(info.IsSymlink)
 	capInfo.SetIsRaw(info.IsRaw)
 	capInfo.SetDepth(int32(info.Depth))
 	capInfo.SetIsPinned(info.IsPinned)
 	capInfo.SetIsExplicit(info.IsExplicit)
+	capInfo.SetMode(uint32(info.Mode))
+	capInfo.SetHasOwner(info.HasOwner)
+	capInfo.SetUid(info.Uid)
+	capInfo.SetGid(info.Gid)
 	return &capInfo, nil
 }
 
@@ -94,10 +104,15 @@ func (fh *fsHandler) List(call capnp.FS_list) error {
 		return err
 	}
 
+	rev, err := call.Params.Rev()
+	if err != nil {
+		return err
+	}
+
 	maxDepth := call.Params.MaxDepth()
 
 	return fh.base.withFsFromPath(root, func(url *URL, fs *catfs.FS) error {
-		entries, err := fs.List(url.Path, int(maxDepth))
+		entries, err := fs.ListAt(rev, url.Path, int(maxDepth))
 		if err != nil {
 			return err
 		}
@@ -126,6 +141,204 @@ func (fh *fsHandler) List(call capnp.FS_list) error {
 	})
 }
 
+func (fh *fsHandler) PinStatus(call capnp.FS_pinStatus) error {
+	server.Ack(call.Options)
+
+	root, err := call.Params.Root()
+	if err != nil {
+		return err
+	}
+
+	return fh.base.withFsFromPath(root, func(url *URL, fs *catfs.FS) error {
+		entries, err := fs.PinStatus(url.Path, int(call.Params.Depth()))
+		if err != nil {
+			return err
+		}
+
+		seg := call.Results.Segment()
+		lst, err := capnp.NewPinStats_List(seg, int32(len(entries)))
+		if err != nil {
+			return err
+		}
+
+		for idx, entry := range entries {
+			capStats, err := capnp.NewPinStats(seg)
+			if err != nil {
+				return err
+			}
+
+			if err := capStats.SetPath(entry.Path); err != nil {
+				return err
+			}
+
+			capStats.SetIsDir(entry.IsDir)
+			capStats.SetFiles(int64(entry.Files))
+			capStats.SetVersions(int64(entry.Versions))
+			capStats.SetPinnedSize(entry.PinnedSize)
+			capStats.SetExplicitSize(entry.ExplicitSize)
+			capStats.SetUnpinnedSize(entry.UnpinnedSize)
+			capStats.SetCachedSize(entry.CachedSize)
+			capStats.SetRepinUnpinSize(entry.RepinUnpinSize)
+			capStats.SetQuotaCandidateSize(entry.QuotaCandidateSize)
+
+			if err := lst.Set(idx, capStats); err != nil {
+				return err
+			}
+		}
+
+		return call.Results.SetStats(lst)
+	})
+}
+
+func (fh *fsHandler) Prefetch(call capnp.FS_prefetch) error {
+	server.Ack(call.Options)
+
+	path, err := call.Params.Path()
+	if err != nil {
+		return err
+	}
+
+	rev, err := call.Params.Rev()
+	if err != nil {
+		return err
+	}
+
+	parallel := int(call.Params.Parallel())
+	return fh.base.withFsFromPath(path, func(url *URL, fs *catfs.FS) error {
+		// Check before going to the background;
+		// errors from there are easily lost.
+		if _, err := fs.StatAt(rev, url.Path); err != nil {
+			return err
+		}
+
+		ticket := fh.base.conductor.Exec(func(ticket uint64) error {
+			progressCh := make(chan *catfs.PrefetchProgress)
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- fs.Prefetch(url.Path, rev, parallel, func(progress *catfs.PrefetchProgress) {
+					if progress.Err != nil {
+						log.Warningf("prefetch: failed to fetch %s: %v", progress.Path, progress.Err)
+					}
+
+					progressCh <- progress
+				})
+			}()
+
+			// Fetching a single big file might take longer than the
+			// client waits for the next result. Send an empty progress
+			// from time to time to tell it that we're still alive.
+			heartbeat := time.NewTicker(time.Minute)
+			defer heartbeat.Stop()
+
+			for {
+				var progress *catfs.PrefetchProgress
+				select {
+				case progress = <-progressCh:
+				case <-heartbeat.C:
+					progress = &catfs.PrefetchProgress{}
+				case err := <-errCh:
+					return err
+				}
+
+				if err := fh.base.conductor.Push(ticket, progress); err != nil {
+					log.Warningf("prefetch: failed to report progress: %v", err)
+				}
+			}
+		})
+
+		call.Results.SetTicket(ticket)
+		return nil
+	})
+}
+
+func (fh *fsHandler) PrefetchNext(call capnp.FS_prefetchNext) error {
+	server.Ack(call.Options)
+
+	data, err := fh.base.conductor.Pop(call.Params.Ticket())
+	if conductor.IsNoDataLeft(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	progress, ok := data.(*catfs.PrefetchProgress)
+	if !ok {
+		return fmt.Errorf("internal error: wrong type for PrefetchProgress")
+	}
+
+	capProgress, err := capnp.NewPrefetchProgress(call.Results.Segment())
+	if err != nil {
+		return err
+	}
+
+	if err := capProgress.SetPath(progress.Path); err != nil {
+		return err
+	}
+
+	if progress.Err != nil {
+		if err := capProgress.SetError(progress.Err.Error()); err != nil {
+			return err
+		}
+	}
+
+	capProgress.SetSize(progress.Size)
+	capProgress.SetSkipped(progress.Skipped)
+	capProgress.SetDoneFiles(int64(progress.DoneFiles))
+	capProgress.SetDoneBytes(progress.DoneBytes)
+	capProgress.SetTotalFiles(int64(progress.TotalFiles))
+	capProgress.SetTotalBytes(progress.TotalBytes)
+	return call.Results.SetProgress(capProgress)
+}
+
+func (fh *fsHandler) Find(call capnp.FS_find) error {
+	server.Ack(call.Options)
+
+	root, err := call.Params.Root()
+	if err != nil {
+		return err
+	}
+
+	querySrc, err := call.Params.Query()
+	if err != nil {
+		return err
+	}
+
+	query, err := catfs.ParseQuery(querySrc)
+	if err != nil {
+		return err
+	}
+
+	return fh.base.withFsFromPath(root, func(url *URL, fs *catfs.FS) error {
+		entries, err := fs.Find(url.Path, query, nil)
+		if err != nil {
+			return err
+		}
+
+		lst, err := capnp.NewStatInfo_List(
+			call.Results.Segment(),
+			int32(len(entries)),
+		)
+		if err != nil {
+			return err
+		}
+
+		for idx, entry := range entries {
+			capEntry, err := statToCapnp(fs, entry, call.Results.Segment())
+			if err != nil {
+				return err
+			}
+
+			if err := lst.Set(idx, *capEntry); err != nil {
+				return err
+			}
+		}
+
+		return call.Results.SetEntries(lst)
+	})
+}
+
 func (fh *fsHandler) Stage(call capnp.FS_stage) error {
 	server.Ack(call.Options)
 
@@ -151,6 +364,15 @@ func (fh *fsHandler) Stage(call capnp.FS_stage) error {
 			return err
 		}
 
+		info, err := fd.Stat()
+		if err != nil {
+			return err
+		}
+
+		if err := fs.StageAttrs(url.Path, info); err != nil {
+			return err
+		}
+
 		fh.base.notifyFsChangeEvent()
 		return nil
 	})
@@ -185,9 +407,14 @@ func (fh *fsHandler) Cat(call capnp.FS_cat) error {
 		return err
 	}
 
+	rev, err := call.Params.Rev()
+	if err != nil {
+		return err
+	}
+
 	return fh.base.withFsFromPath(path, func(url *URL, fs *catfs.FS) error {
 		if call.Params.Offline() {
-			isCached, err := fs.IsCached(url.Path)
+			isCached, err := fs.IsCachedAt(rev, url.Path)
 			if err != nil {
 				return err
 			}
@@ -197,7 +424,7 @@ func (fh *fsHandler) Cat(call capnp.FS_cat) error {
 			}
 		}
 
-		stream, err := fs.Cat(url.Path)
+		stream, err := fs.CatAt(rev, url.Path)
 		if err != nil {
 			return err
 		}
@@ -235,9 +462,14 @@ func (fh *fsHandler) Tar(call capnp.FS_tar) error {
 		return err
 	}
 
+	rev, err := call.Params.Rev()
+	if err != nil {
+		return err
+	}
+
 	return fh.base.withFsFromPath(path, func(url *URL, fs *catfs.FS) error {
 		if call.Params.Offline() {
-			isCached, err := fs.IsCached(url.Path)
+			isCached, err := fs.IsCachedAt(rev, url.Path)
 			if err != nil {
 				return err
 			}
@@ -247,13 +479,13 @@ func (fh *fsHandler) Tar(call capnp.FS_tar) error {
 			}
 		}
 
-		if _, err := fs.Stat(path); err != nil {
+		if _, err := fs.StatAt(rev, path); err != nil {
 			return err
 		}
 
 		port, err := bootTransferServer(fs, "127.0.0.1", func(conn net.Conn) {
 			localAddr := conn.LocalAddr().String()
-			if err := fs.Tar(path, conn, nil); err != nil {
+			if err := fs.TarAt(rev, path, conn, nil); err != nil {
 				log.Warningf("tar failed for path %s on %s: %v", path, localAddr, err)
 			}
 		})
@@ -282,6 +514,67 @@ func (fh *fsHandler) Mkdir(call capnp.FS_mkdir) error {
 	})
 }
 
+func (fh *fsHandler) Symlink(call capnp.FS_symlink) error {
+	server.Ack(call.Options)
+
+	path, err := call.Params.Path()
+	if err != nil {
+		return err
+	}
+
+	target, err := call.Params.Target()
+	if err != nil {
+		return err
+	}
+
+	return fh.base.withFsFromPath(path, func(url *URL, fs *catfs.FS) error {
+		if err := fs.Symlink(url.Path, target); err != nil {
+			return err
+		}
+
+		fh.base.notifyFsChangeEvent()
+		return nil
+	})
+}
+
+func (fh *fsHandler) Chmod(call capnp.FS_chmod) error {
+	server.Ack(call.Options)
+
+	path, err := call.Params.Path()
+	if err != nil {
+		return err
+	}
+
+	mode := os.FileMode(call.Params.Mode())
+	return fh.base.withFsFromPath(path, func(url *URL, fs *catfs.FS) error {
+		if err := fs.Chmod(url.Path, mode); err != nil {
+			return err
+		}
+
+		fh.base.notifyFsChangeEvent()
+		return nil
+	})
+}
+
+func (fh *fsHandler) Chown(call capnp.FS_chown) error {
+	server.Ack(call.Options)
+
+	path, err := call.Params.Path()
+	if err != nil {
+		return err
+	}
+
+	uid, gid := call.Params.Uid(), call.Params.Gid()
+	return fh.base.withFsFromPath(path, func(url *URL, fs *catfs.FS) error {
+		if err := fs.Chown(url.Path, uid, gid); err != nil {
+			return err
+		}
+
+		fh.base.notifyFsChangeEvent()
+		return nil
+	})
+}
+
 func (fh *fsHandler) Remove(call capnp.FS_remove) error {
 	server.Ack(call.Options)
 
@@ -411,8 +704,13 @@ func (fh *fsHandler) Stat(call capnp.FS_stat) error {
 		return err
 	}
 
+	rev, err := call.Params.Rev()
+	if err != nil {
+		return err
+	}
+
 	return fh.base.withFsFromPath(path, func(url *URL, fs *catfs.FS) error {
-		info, err := fs.Stat(url.Path)
+		info, err := fs.StatAt(rev, url.Path)
 		if err != nil {
 			return err
 		}
@@ -583,8 +881,13 @@ func (fh *fsHandler) IsCached(call capnp.FS_isCached) error {
 		return err
 	}
 
+	rev, err := call.Params.Rev()
+	if err != nil {
+		return err
+	}
+
 	return fh.base.withFsFromPath(path, func(url *URL, fs *catfs.FS) error {
-		isCached, err := fs.IsCached(url.Path)
+		isCached, err := fs.IsCachedAt(rev, url.Path)
 		if err != nil {
 			return err
 		}
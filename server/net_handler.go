@@ -205,6 +205,21 @@ func capRemoteToRemote(remote capnp.Remote) (*repo.Remote, error) {
 		})
 	}
 
+	capAddrs, err := remote.Addresses()
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := []string{}
+	for idx := 0; idx < capAddrs.Len(); idx++ {
+		addr, err := capAddrs.At(idx)
+		if err != nil {
+			return nil, err
+		}
+
+		addrs = append(addrs, addr)
+	}
+
 	return &repo.Remote{
 		Name:              remoteName,
 		Fingerprint:       peer.Fingerprint(fingerprint),
@@ -212,6 +227,7 @@ func capRemoteToRemote(remote capnp.Remote) (*repo.Remote, error) {
 		AcceptAutoUpdates: remote.AcceptAutoUpdates(),
 		AcceptPush:        remote.AcceptPush(),
 		ConflictStrategy:  conflictStrategy,
+		Addresses:         addrs,
 	}, nil
 }
 
@@ -262,6 +278,21 @@ func remoteToCapRemote(remote repo.Remote, seg *capnplib.Segment) (*capnp.Remote
 		return nil, err
 	}
 
+	capAddrs, err := capnplib.NewTextList(seg, int32(len(remote.Addresses)))
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, addr := range remote.Addresses {
+		if err := capAddrs.Set(idx, addr); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := capRemote.SetAddresses(capAddrs); err != nil {
+		return nil, err
+	}
+
 	capRemote.SetAcceptAutoUpdates(remote.AcceptAutoUpdates)
 	capRemote.SetAcceptPush(remote.AcceptPush)
 	return &capRemote, nil
@@ -597,3 +628,41 @@ func (nh *netHandler) Push(call capnp.Net_push) error {
 		return ctl.Push()
 	})
 }
+
+func (nh *netHandler) TransferList(call capnp.Net_transferList) error {
+	server.Ack(call.Options)
+
+	progresses := nh.base.transfers.List()
+	seg := call.Results.Segment()
+	capTransfers, err := capnp.NewTransfer_List(seg, int32(len(progresses)))
+	if err != nil {
+		return err
+	}
+
+	for idx, progress := range progresses {
+		capTransfer, err := capnp.NewTransfer(seg)
+		if err != nil {
+			return err
+		}
+
+		if err := capTransfer.SetRemote(progress.Remote); err != nil {
+			return err
+		}
+
+		if err := capTransfer.SetKind(progress.Kind); err != nil {
+			return err
+		}
+
+		if err := capTransfer.SetStarted(progress.Started.Format(time.RFC3339)); err != nil {
+			return err
+		}
+
+		capTransfer.SetDone(progress.Done)
+		capTransfer.SetTotal(progress.Total)
+		if err := capTransfers.Set(idx, capTransfer); err != nil {
+			return err
+		}
+	}
+
+	return call.Results.SetTransfers(capTransfers)
+}
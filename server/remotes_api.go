@@ -98,6 +98,13 @@ func (a *RemotesAPI) Set(rm remotesapi.Remote) error {
 		})
 	}
 
+	// The gateway does not know about direct addresses,
+	// so keep the ones of an existing remote.
+	var addrs []string
+	if oldRmt, err := a.base.repo.Remotes.Remote(rm.Name); err == nil {
+		addrs = oldRmt.Addresses
+	}
+
 	err = a.base.repo.Remotes.AddOrUpdateRemote(repo.Remote{
 		Name:              rm.Name,
 		Fingerprint:       fp,
@@ -105,6 +112,7 @@ func (a *RemotesAPI) Set(rm remotesapi.Remote) error {
 		AcceptAutoUpdates: rm.AcceptAutoUpdates,
 		AcceptPush:        rm.AcceptPush,
 		ConflictStrategy:  rm.ConflictStrategy,
+		Addresses:         addrs,
 	})
 
 	if err != nil {
@@ -155,6 +163,14 @@ func (a *RemotesAPI) Sync(name string) error {
 	return err
 }
 
+// SyncFromPush synchronizes with `name` because it pushed to us.
+// The merge commit is marked as such, so »brig blame« can show it.
+func (a *RemotesAPI) SyncFromPush(name string) error {
+	msg := fmt.Sprintf("sync with »%s« due to push", name)
+	_, err := a.base.doSync(name, true, msg, catfs.SyncOptPush())
+	return err
+}
+
 // MakeDiff produces a diff to the remote with `name`.
 func (a *RemotesAPI) MakeDiff(name string) (*catfs.Diff, error) {
 	if err := a.base.doFetch(name); err != nil {
@@ -175,6 +191,22 @@ func (a *RemotesAPI) MakeDiff(name string) (*catfs.Diff, error) {
 	})
 }
 
+// Transfers returns the progress of all running metadata transfers.
+func (a *RemotesAPI) Transfers() ([]remotesapi.Transfer, error) {
+	transfers := []remotesapi.Transfer{}
+	for _, progress := range a.base.transfers.List() {
+		transfers = append(transfers, remotesapi.Transfer{
+			Remote:  progress.Remote,
+			Kind:    progress.Kind,
+			Done:    progress.Done,
+			Total:   progress.Total,
+			Started: progress.Started,
+		})
+	}
+
+	return transfers, nil
+}
+
 // OnChange register a callback to be called once the remote list changes.
 func (a *RemotesAPI) OnChange(fn func()) {
 	a.base.repo.Remotes.OnChange(fn)
@@ -3,12 +3,16 @@ package server
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/sahib/brig/backend"
+	"github.com/sahib/brig/catfs"
 	"github.com/sahib/brig/fuse"
 	gwdb "github.com/sahib/brig/gateway/db"
 	gwcapnp "github.com/sahib/brig/gateway/db/capnp"
 	"github.com/sahib/brig/repo/hints"
+	"github.com/sahib/brig/repo/pinrules"
+	"github.com/sahib/brig/repo/retention"
 	"github.com/sahib/brig/server/capnp"
 	"github.com/sahib/brig/version"
 	log "github.com/sirupsen/logrus"
@@ -334,8 +338,7 @@ func (rh *repoHandler) Version(call capnp.Repo_version) error {
 
 	rp := rh.base.repo
 	name := rp.Immutables.Backend()
-	ipfsPath := rp.Config.String("daemon.ipfs_path_or_url")
-	bkVersion := backend.Version(name, ipfsPath)
+	bkVersion := backend.Version(name, rp.BackendPath())
 	if bkVersion == nil {
 		return fmt.Errorf("bug: invalid backend name: %v", name)
 	}
@@ -455,6 +458,207 @@ func (rh *repoHandler) GatewayUserList(call capnp.Repo_gatewayUserList) error {
 	return call.Results.SetUsers(capUsers)
 }
 
+func (rh *repoHandler) GatewayUserKeyAdd(call capnp.Repo_gatewayUserKeyAdd) error {
+	server.Ack(call.Options)
+
+	name, err := call.Params.Name()
+	if err != nil {
+		return err
+	}
+
+	gwDb := rh.base.gateway.UserDatabase()
+	key, err := gwDb.AddAccessKey(name)
+	if err != nil {
+		return err
+	}
+
+	if err := call.Results.SetId(key.ID); err != nil {
+		return err
+	}
+
+	return call.Results.SetSecret(key.Secret)
+}
+
+func (rh *repoHandler) GatewayUserKeyRm(call capnp.Repo_gatewayUserKeyRm) error {
+	server.Ack(call.Options)
+
+	name, err := call.Params.Name()
+	if err != nil {
+		return err
+	}
+
+	id, err := call.Params.Id()
+	if err != nil {
+		return err
+	}
+
+	gwDb := rh.base.gateway.UserDatabase()
+	return gwDb.RemoveAccessKey(name, id)
+}
+
+func (rh *repoHandler) GatewayShareCreate(call capnp.Repo_gatewayShareCreate) error {
+	server.Ack(call.Options)
+
+	path, err := call.Params.Path()
+	if err != nil {
+		return err
+	}
+
+	rev, err := call.Params.Rev()
+	if err != nil {
+		return err
+	}
+
+	expiresAtStr, err := call.Params.ExpiresAt()
+	if err != nil {
+		return err
+	}
+
+	password, err := call.Params.Password()
+	if err != nil {
+		return err
+	}
+
+	link := gwdb.ShareLink{
+		Path:         path,
+		Rev:          rev,
+		MaxDownloads: call.Params.MaxDownloads(),
+	}
+
+	if expiresAtStr != "" {
+		link.ExpiresAt, err = time.Parse(time.RFC3339, expiresAtStr)
+		if err != nil {
+			return err
+		}
+	}
+
+	link, err = rh.base.gateway.CreateShare(link, password)
+	if err != nil {
+		return err
+	}
+
+	capLink, err := gwdb.ShareLinkToCapnp(&link, call.Results.Segment())
+	if err != nil {
+		return err
+	}
+
+	return call.Results.SetLink(*capLink)
+}
+
+func (rh *repoHandler) GatewayShareList(call capnp.Repo_gatewayShareList) error {
+	server.Ack(call.Options)
+
+	gwDb := rh.base.gateway.UserDatabase()
+	links, err := gwDb.ListShares()
+	if err != nil {
+		return err
+	}
+
+	seg := call.Results.Segment()
+	capLinks, err := gwcapnp.NewShareLink_List(seg, int32(len(links)))
+	if err != nil {
+		return err
+	}
+
+	for idx, link := range links {
+		capLink, err := gwdb.ShareLinkToCapnp(&link, seg)
+		if err != nil {
+			return err
+		}
+
+		if err := capLinks.Set(idx, *capLink); err != nil {
+			return err
+		}
+	}
+
+	return call.Results.SetLinks(capLinks)
+}
+
+func (rh *repoHandler) GatewayShareRevoke(call capnp.Repo_gatewayShareRevoke) error {
+	server.Ack(call.Options)
+
+	token, err := call.Params.Token()
+	if err != nil {
+		return err
+	}
+
+	gwDb := rh.base.gateway.UserDatabase()
+	return gwDb.RemoveShare(token)
+}
+
+func (rh *repoHandler) GatewayUserTokenAdd(call capnp.Repo_gatewayUserTokenAdd) error {
+	server.Ack(call.Options)
+
+	name, err := call.Params.Name()
+	if err != nil {
+		return err
+	}
+
+	tokenName, err := call.Params.TokenName()
+	if err != nil {
+		return err
+	}
+
+	rights := []string{}
+	capRights, err := call.Params.Rights()
+	if err != nil {
+		return err
+	}
+
+	for idx := 0; idx < capRights.Len(); idx++ {
+		right, err := capRights.At(idx)
+		if err != nil {
+			return err
+		}
+
+		rights = append(rights, right)
+	}
+
+	folders := []string{}
+	capFolders, err := call.Params.Folders()
+	if err != nil {
+		return err
+	}
+
+	for idx := 0; idx < capFolders.Len(); idx++ {
+		folder, err := capFolders.At(idx)
+		if err != nil {
+			return err
+		}
+
+		folders = append(folders, folder)
+	}
+
+	gwDb := rh.base.gateway.UserDatabase()
+	secret, token, err := gwDb.AddAPIToken(name, tokenName, rights, folders)
+	if err != nil {
+		return err
+	}
+
+	if err := call.Results.SetToken(secret); err != nil {
+		return err
+	}
+
+	return call.Results.SetId(token.ID)
+}
+
+func (rh *repoHandler) GatewayUserTokenRm(call capnp.Repo_gatewayUserTokenRm) error {
+	server.Ack(call.Options)
+
+	name, err := call.Params.Name()
+	if err != nil {
+		return err
+	}
+
+	id, err := call.Params.Id()
+	if err != nil {
+		return err
+	}
+
+	gwDb := rh.base.gateway.UserDatabase()
+	return gwDb.RemoveAPIToken(name, id)
+}
+
 func (rh *repoHandler) DebugProfilePort(call capnp.Repo_debugProfilePort) error {
 	server.Ack(call.Options)
 	call.Results.SetPort(int32(rh.base.pprofPort))
@@ -563,3 +767,180 @@ func (rh *repoHandler) HintList(call capnp.Repo_hintList) error {
 
 	return call.Results.SetHints(capnpHints)
 }
+
+func (rh *repoHandler) RetentionSet(call capnp.Repo_retentionSet) error {
+	server.Ack(call.Options)
+
+	path, err := call.Params.Path()
+	if err != nil {
+		return err
+	}
+
+	policySpec, err := call.Params.Policy()
+	if err != nil {
+		return err
+	}
+
+	policy, err := retention.ParsePolicy(policySpec)
+	if err != nil {
+		return err
+	}
+
+	if err := rh.base.repo.Retention.Set(path, policy); err != nil {
+		return err
+	}
+
+	return rh.base.repo.SaveRetention()
+}
+
+func (rh *repoHandler) RetentionRemove(call capnp.Repo_retentionRemove) error {
+	server.Ack(call.Options)
+
+	path, err := call.Params.Path()
+	if err != nil {
+		return err
+	}
+
+	if err := rh.base.repo.Retention.Remove(path); err != nil {
+		return err
+	}
+
+	return rh.base.repo.SaveRetention()
+}
+
+func (rh *repoHandler) RetentionList(call capnp.Repo_retentionList) error {
+	server.Ack(call.Options)
+
+	policies := rh.base.repo.Retention.List()
+
+	seg := call.Results.Segment()
+	capPolicies, err := capnp.NewRetentionPolicy_List(seg, int32(len(policies)))
+	if err != nil {
+		return err
+	}
+
+	capIdx := 0
+	for path, policy := range policies {
+		capPolicy, err := capnp.NewRetentionPolicy(seg)
+		if err != nil {
+			return err
+		}
+
+		if err := capPolicy.SetPath(path); err != nil {
+			return err
+		}
+
+		if err := capPolicy.SetPolicy(policy.String()); err != nil {
+			return err
+		}
+
+		if err := capPolicies.Set(capIdx, capPolicy); err != nil {
+			return err
+		}
+
+		capIdx++
+	}
+
+	return call.Results.SetPolicies(capPolicies)
+}
+
+func (rh *repoHandler) PinRuleSet(call capnp.Repo_pinRuleSet) error {
+	server.Ack(call.Options)
+
+	path, err := call.Params.Path()
+	if err != nil {
+		return err
+	}
+
+	rule, _ := rh.base.repo.PinRules.Get(path)
+
+	if call.Params.HasQuota() {
+		quotaSpec, err := call.Params.Quota()
+		if err != nil {
+			return err
+		}
+
+		quota, err := pinrules.ParseQuota(quotaSpec)
+		if err != nil {
+			return err
+		}
+
+		rule.Quota = quota
+	}
+
+	if call.Params.HasPriority() {
+		priority, err := call.Params.Priority()
+		if err != nil {
+			return err
+		}
+
+		rule.Priority = pinrules.Priority(priority)
+	}
+
+	if err := rh.base.repo.PinRules.Set(path, rule); err != nil {
+		return err
+	}
+
+	return rh.base.repo.SavePinRules()
+}
+
+func (rh *repoHandler) PinRuleRemove(call capnp.Repo_pinRuleRemove) error {
+	server.Ack(call.Options)
+
+	path, err := call.Params.Path()
+	if err != nil {
+		return err
+	}
+
+	if err := rh.base.repo.PinRules.Remove(path); err != nil {
+		return err
+	}
+
+	return rh.base.repo.SavePinRules()
+}
+
+func (rh *repoHandler) PinRuleList(call capnp.Repo_pinRuleList) error {
+	server.Ack(call.Options)
+
+	rules := rh.base.repo.PinRules.List()
+
+	seg := call.Results.Segment()
+	capRules, err := capnp.NewPinRule_List(seg, int32(len(rules)))
+	if err != nil {
+		return err
+	}
+
+	return rh.base.withCurrFs(func(fs *catfs.FS) error {
+		capIdx := 0
+		for path, rule := range rules {
+			used, err := fs.PinnedSize(path)
+			if err != nil {
+				return err
+			}
+
+			capRule, err := capnp.NewPinRule(seg)
+			if err != nil {
+				return err
+			}
+
+			if err := capRule.SetPath(path); err != nil {
+				return err
+			}
+
+			if err := capRule.SetPriority(string(rule.Priority)); err != nil {
+				return err
+			}
+
+			capRule.SetQuota(rule.Quota)
+			capRule.SetUsed(used)
+
+			if err := capRules.Set(capIdx, capRule); err != nil {
+				return err
+			}
+
+			capIdx++
+		}
+
+		return call.Results.SetRules(capRules)
+	})
+}
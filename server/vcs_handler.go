@@ -55,6 +55,24 @@ func commitToCap(entry *catfs.Commit, seg *cplib.Segment) (*capnp.Commit, error)
 	return &capEntry, nil
 }
 
+// verifyLog sets the signature status of all `entries`.
+// The commits of the current fs are checked with the key of its owner.
+func (vcs *vcsHandler) verifyLog(entries []*catfs.Commit, capEntries capnp.Commit_List) error {
+	verify, err := vcs.base.verifierFor(vcs.base.repo.CurrentUser())
+	if err != nil {
+		return err
+	}
+
+	for idx, entry := range entries {
+		capEntry := capEntries.At(idx)
+		if err := capEntry.SetSignature(entry.SignatureStatus(verify)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (vcs *vcsHandler) Log(call capnp.VCS_log) error {
 	server.Ack(call.Options)
 	seg := call.Results.Segment()
@@ -86,6 +104,12 @@ func (vcs *vcsHandler) Log(call capnp.VCS_log) error {
 			lst.Set(idx, *capEntry)
 		}
 
+		if call.Params.Verify() {
+			if err := vcs.verifyLog(entries, lst); err != nil {
+				return err
+			}
+		}
+
 		return call.Results.SetEntries(lst)
 	})
 }
@@ -494,3 +518,320 @@ func (vcs *vcsHandler) CommitInfo(call capnp.VCS_commitInfo) error {
 		return nil
 	})
 }
+
+func conflictToCap(fs *catfs.FS, cf catfs.Conflict, seg *cplib.Segment) (*capnp.Conflict, error) {
+	capCf, err := capnp.NewConflict(seg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := capCf.SetPath(cf.Path); err != nil {
+		return nil, err
+	}
+
+	if err := capCf.SetConflictPath(cf.ConflictPath); err != nil {
+		return nil, err
+	}
+
+	if err := capCf.SetRemote(cf.Remote); err != nil {
+		return nil, err
+	}
+
+	if cf.Ours != nil {
+		capOurs, err := statToCapnp(fs, cf.Ours.Info, seg)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := capCf.SetOurs(*capOurs); err != nil {
+			return nil, err
+		}
+
+		capOursCmt, err := commitToCap(cf.Ours.Commit, seg)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := capCf.SetOursCommit(*capOursCmt); err != nil {
+			return nil, err
+		}
+	}
+
+	capTheirs, err := statToCapnp(fs, cf.Theirs.Info, seg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := capCf.SetTheirs(*capTheirs); err != nil {
+		return nil, err
+	}
+
+	capTheirsCmt, err := commitToCap(cf.Theirs.Commit, seg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := capCf.SetTheirsCommit(*capTheirsCmt); err != nil {
+		return nil, err
+	}
+
+	return &capCf, nil
+}
+
+func (vcs *vcsHandler) ConflictList(call capnp.VCS_conflictList) error {
+	server.Ack(call.Options)
+
+	root, err := call.Params.Root()
+	if err != nil {
+		return err
+	}
+
+	seg := call.Results.Segment()
+
+	return vcs.base.withCurrFs(func(fs *catfs.FS) error {
+		conflicts, err := fs.Conflicts(root)
+		if err != nil {
+			return err
+		}
+
+		lst, err := capnp.NewConflict_List(seg, int32(len(conflicts)))
+		if err != nil {
+			return err
+		}
+
+		for idx, cf := range conflicts {
+			capCf, err := conflictToCap(fs, cf, seg)
+			if err != nil {
+				return err
+			}
+
+			if err := lst.Set(idx, *capCf); err != nil {
+				return err
+			}
+		}
+
+		return call.Results.SetConflicts(lst)
+	})
+}
+
+func (vcs *vcsHandler) ConflictResolve(call capnp.VCS_conflictResolve) error {
+	server.Ack(call.Options)
+
+	strategy, err := call.Params.Strategy()
+	if err != nil {
+		return err
+	}
+
+	capPaths, err := call.Params.Paths()
+	if err != nil {
+		return err
+	}
+
+	paths := []string{}
+	for idx := 0; idx < capPaths.Len(); idx++ {
+		path, err := capPaths.At(idx)
+		if err != nil {
+			return err
+		}
+
+		paths = append(paths, path)
+	}
+
+	return vcs.base.withCurrFs(func(fs *catfs.FS) error {
+		return fs.ResolveConflicts(paths, strategy)
+	})
+}
+
+func (vcs *vcsHandler) BranchList(call capnp.VCS_branchList) error {
+	server.Ack(call.Options)
+	seg := call.Results.Segment()
+
+	return vcs.base.withCurrFs(func(fs *catfs.FS) error {
+		branches, err := fs.Branches()
+		if err != nil {
+			return err
+		}
+
+		lst, err := capnp.NewBranch_List(seg, int32(len(branches)))
+		if err != nil {
+			return err
+		}
+
+		for idx, branch := range branches {
+			capBranch, err := capnp.NewBranch(seg)
+			if err != nil {
+				return err
+			}
+
+			if err := capBranch.SetName(branch.Name); err != nil {
+				return err
+			}
+
+			capHead, err := commitToCap(branch.Head, seg)
+			if err != nil {
+				return err
+			}
+
+			if err := capBranch.SetHead(*capHead); err != nil {
+				return err
+			}
+
+			capBranch.SetIsCurrent(branch.IsCurrent)
+			if err := lst.Set(idx, capBranch); err != nil {
+				return err
+			}
+		}
+
+		return call.Results.SetBranches(lst)
+	})
+}
+
+func (vcs *vcsHandler) BranchCreate(call capnp.VCS_branchCreate) error {
+	server.Ack(call.Options)
+
+	name, err := call.Params.Name()
+	if err != nil {
+		return err
+	}
+
+	rev, err := call.Params.Rev()
+	if err != nil {
+		return err
+	}
+
+	return vcs.base.withCurrFs(func(fs *catfs.FS) error {
+		return fs.CreateBranch(name, rev)
+	})
+}
+
+func (vcs *vcsHandler) BranchSwitch(call capnp.VCS_branchSwitch) error {
+	server.Ack(call.Options)
+
+	name, err := call.Params.Name()
+	if err != nil {
+		return err
+	}
+
+	return vcs.base.withCurrFs(func(fs *catfs.FS) error {
+		return fs.SwitchBranch(name, call.Params.Force())
+	})
+}
+
+func (vcs *vcsHandler) BranchDelete(call capnp.VCS_branchDelete) error {
+	server.Ack(call.Options)
+
+	name, err := call.Params.Name()
+	if err != nil {
+		return err
+	}
+
+	return vcs.base.withCurrFs(func(fs *catfs.FS) error {
+		return fs.DeleteBranch(name)
+	})
+}
+
+func (vcs *vcsHandler) BranchMerge(call capnp.VCS_branchMerge) error {
+	server.Ack(call.Options)
+
+	name, err := call.Params.Name()
+	if err != nil {
+		return err
+	}
+
+	return vcs.base.withCurrFs(func(fs *catfs.FS) error {
+		return fs.MergeBranch(name)
+	})
+}
+
+func (vcs *vcsHandler) Revert(call capnp.VCS_revert) error {
+	server.Ack(call.Options)
+
+	rev, err := call.Params.Rev()
+	if err != nil {
+		return err
+	}
+
+	return vcs.base.withCurrFs(func(fs *catfs.FS) error {
+		return fs.Revert(rev)
+	})
+}
+
+func (vcs *vcsHandler) Blame(call capnp.VCS_blame) error {
+	server.Ack(call.Options)
+
+	path, err := call.Params.Path()
+	if err != nil {
+		return err
+	}
+
+	seg := call.Results.Segment()
+
+	return vcs.base.withFsFromPath(path, func(url *URL, fs *catfs.FS) error {
+		entries, err := fs.Blame(url.Path)
+		if err != nil {
+			return err
+		}
+
+		lst, err := capnp.NewBlameEntry_List(seg, int32(len(entries)))
+		if err != nil {
+			return err
+		}
+
+		for idx, entry := range entries {
+			capEntry, err := capnp.NewBlameEntry(seg)
+			if err != nil {
+				return err
+			}
+
+			if err := capEntry.SetPath(entry.Path); err != nil {
+				return err
+			}
+
+			if err := capEntry.SetChange(entry.Change); err != nil {
+				return err
+			}
+
+			capCmt, err := commitToCap(entry.Commit, seg)
+			if err != nil {
+				return err
+			}
+
+			if err := capEntry.SetCommit(*capCmt); err != nil {
+				return err
+			}
+
+			if err := capEntry.SetRemote(entry.Remote); err != nil {
+				return err
+			}
+
+			if err := capEntry.SetVia(entry.Via); err != nil {
+				return err
+			}
+
+			if err := lst.Set(idx, capEntry); err != nil {
+				return err
+			}
+		}
+
+		return call.Results.SetEntries(lst)
+	})
+}
+
+func (vcs *vcsHandler) HistorySquash(call capnp.VCS_historySquash) error {
+	server.Ack(call.Options)
+
+	before, err := call.Params.Before()
+	if err != nil {
+		return err
+	}
+
+	return vcs.base.withCurrFs(func(fs *catfs.FS) error {
+		dropped, err := fs.Squash(before)
+		if err != nil {
+			return err
+		}
+
+		call.Results.SetDropped(int64(dropped))
+		return nil
+	})
+}
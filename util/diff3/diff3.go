@@ -0,0 +1,207 @@
+// Package diff3 implements a line based three-way merge.
+//
+// Both modified versions are diffed against their common ancestor ("base").
+// Regions that were changed on only one side are taken over from this side,
+// regions that were changed on both sides in the same way are taken once.
+// If both sides changed the same region differently, the merge fails.
+package diff3
+
+import (
+	"bytes"
+)
+
+// splitLines splits `data` into lines, keeping the line endings.
+func splitLines(data []byte) [][]byte {
+	lines := [][]byte{}
+	for len(data) > 0 {
+		idx := bytes.IndexByte(data, '\n')
+		if idx < 0 {
+			lines = append(lines, data)
+			break
+		}
+
+		lines = append(lines, data[:idx+1])
+		data = data[idx+1:]
+	}
+
+	return lines
+}
+
+// internLines maps each distinct line to a number,
+// so lines can be compared cheaply afterwards.
+func internLines(table map[string]int, lines [][]byte) []int {
+	ids := make([]int, len(lines))
+	for idx, line := range lines {
+		id, ok := table[string(line)]
+		if !ok {
+			id = len(table)
+			table[string(line)] = id
+		}
+
+		ids[idx] = id
+	}
+
+	return ids
+}
+
+// matchLines computes the longest common subsequence of `a` and `b` using
+// Myers' O(ND) algorithm. The result has one entry per line in `a` which is
+// the index of the matching line in `b` or -1 if the line has no partner.
+func matchLines(a, b []int) []int {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+
+	matches := make([]int, n)
+	for idx := range matches {
+		matches[idx] = -1
+	}
+
+	if max == 0 {
+		return matches
+	}
+
+	v := make([]int, 2*max+3)
+	trace := [][]int{}
+
+	for d := 0; d <= max; d++ {
+		// Remember the part of v that the next iteration is based on.
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+			if x >= n && y >= m {
+				backtrack(trace, matches, n, m)
+				return matches
+			}
+		}
+	}
+
+	return matches
+}
+
+func backtrack(trace [][]int, matches []int, x, y int) {
+	for d := len(trace) - 1; d >= 0; d-- {
+		vd := trace[d]
+		at := func(k int) int { return vd[k+d] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := 0
+		if d > 0 {
+			prevX = at(prevK)
+		}
+
+		prevY := prevX - prevK
+		if d == 0 {
+			prevY = 0
+		}
+
+		// Walk back the diagonal; those lines are equal.
+		for x > prevX && y > prevY {
+			x--
+			y--
+			matches[x] = y
+		}
+
+		x, y = prevX, prevY
+	}
+}
+
+func equalLines(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for idx := range a {
+		if !bytes.Equal(a[idx], b[idx]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Merge merges the changes from `base` to `ours` and from `base` to `theirs`.
+// If the changes overlap, the merge fails and false is returned.
+func Merge(base, ours, theirs []byte) ([]byte, bool) {
+	table := make(map[string]int)
+	baseLines := splitLines(base)
+	ourLines := splitLines(ours)
+	theirLines := splitLines(theirs)
+
+	baseIDs := internLines(table, baseLines)
+	ourMatches := matchLines(baseIDs, internLines(table, ourLines))
+	theirMatches := matchLines(baseIDs, internLines(table, theirLines))
+
+	merged := &bytes.Buffer{}
+	write := func(lines [][]byte) {
+		for _, line := range lines {
+			merged.Write(line)
+		}
+	}
+
+	i, o, t := 0, 0, 0
+	for {
+		// Find the next base line that survived on both sides.
+		// Everything before it is a region that might have been changed.
+		j := i
+		for j < len(baseLines) && (ourMatches[j] < 0 || theirMatches[j] < 0) {
+			j++
+		}
+
+		nextO, nextT := len(ourLines), len(theirLines)
+		if j < len(baseLines) {
+			nextO, nextT = ourMatches[j], theirMatches[j]
+		}
+
+		baseChunk := baseLines[i:j]
+		ourChunk := ourLines[o:nextO]
+		theirChunk := theirLines[t:nextT]
+
+		switch {
+		case equalLines(baseChunk, ourChunk):
+			// Only they changed this region (or nobody did).
+			write(theirChunk)
+		case equalLines(baseChunk, theirChunk):
+			// Only we changed this region.
+			write(ourChunk)
+		case equalLines(ourChunk, theirChunk):
+			// Both sides did the same change.
+			write(ourChunk)
+		default:
+			return nil, false
+		}
+
+		if j >= len(baseLines) {
+			break
+		}
+
+		// The line at j is the same on all sides.
+		write(baseLines[j : j+1])
+		i, o, t = j+1, nextO+1, nextT+1
+	}
+
+	return merged.Bytes(), true
+}
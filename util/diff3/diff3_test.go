@@ -0,0 +1,49 @@
+package diff3
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeDisjointChanges(t *testing.T) {
+	base := "a\nb\nc\nd\ne\n"
+	ours := "a\nB\nc\nd\ne\n"
+	theirs := "a\nb\nc\nD\ne\nf\n"
+
+	merged, ok := Merge([]byte(base), []byte(ours), []byte(theirs))
+	require.True(t, ok)
+	require.Equal(t, "a\nB\nc\nD\ne\nf\n", string(merged))
+}
+
+func TestMergeSameChange(t *testing.T) {
+	base := "a\nb\nc\n"
+	both := "a\nX\nc\n"
+
+	merged, ok := Merge([]byte(base), []byte(both), []byte(both))
+	require.True(t, ok)
+	require.Equal(t, both, string(merged))
+}
+
+func TestMergeInsertAndDelete(t *testing.T) {
+	base := "a\nb\nc\n"
+	ours := "0\na\nb\nc\n"
+	theirs := "a\nb\n"
+
+	merged, ok := Merge([]byte(base), []byte(ours), []byte(theirs))
+	require.True(t, ok)
+	require.Equal(t, "0\na\nb\n", string(merged))
+
+	merged, ok = Merge([]byte(""), []byte("x\n"), []byte(""))
+	require.True(t, ok)
+	require.Equal(t, "x\n", string(merged))
+}
+
+func TestMergeOverlap(t *testing.T) {
+	base := "a\nb\nc\n"
+	ours := "a\nX\nc\n"
+	theirs := "a\nY\nc\n"
+
+	_, ok := Merge([]byte(base), []byte(ours), []byte(theirs))
+	require.False(t, ok)
+}
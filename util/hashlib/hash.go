@@ -2,6 +2,7 @@ package hashlib
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"hash"
 	"strconv"
@@ -207,19 +208,27 @@ func TestDummy(t *testing.T, seed byte) Hash {
 // HashWriter is a io.Writer that supports being written to.
 type HashWriter struct {
 	hash hash.Hash
+	code uint64
 }
 
 // NewHashWriter returns a new HashWriter.
 // Currently it is always sha3-256.
 func NewHashWriter() *HashWriter {
 	b, _ := blake2s.New256(nil)
-	return &HashWriter{hash: b}
+	return &HashWriter{hash: b, code: internalHashAlgo}
+}
+
+// NewBackendHashWriter returns a new HashWriter that produces the same
+// hashes as SumWithBackendHash() would, but in a streaming fashion.
+func NewBackendHashWriter() *HashWriter {
+	// NOTE: goipfsutil.DefaultIpfsHash is sha2-256.
+	return &HashWriter{hash: sha256.New(), code: goipfsutil.DefaultIpfsHash}
 }
 
 // Finalize returns the final hash of the written data.
 func (hw *HashWriter) Finalize() Hash {
 	sum := hw.hash.Sum(nil)
-	hash, err := multihash.Encode(sum, internalHashAlgo)
+	hash, err := multihash.Encode(sum, hw.code)
 	if err != nil {
 		// If this does not work, there's something serious wrong.
 		panic(fmt.Sprintf("failed to encode final hash: %v", err))
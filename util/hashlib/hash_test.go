@@ -22,3 +22,15 @@ func TestHashWriter(t *testing.T) {
 		t.Fatalf("hashes differ due to different feed order")
 	}
 }
+
+func TestBackendHashWriter(t *testing.T) {
+	data := []byte{1, 2, 3, 4}
+
+	hw := NewBackendHashWriter()
+	hw.Write(data[0:2])
+	hw.Write(data[2:4])
+
+	if !hw.Finalize().Equal(SumWithBackendHash(data)) {
+		t.Fatalf("streamed backend hash differs from SumWithBackendHash")
+	}
+}